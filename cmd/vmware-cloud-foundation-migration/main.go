@@ -3,8 +3,10 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -32,6 +34,13 @@ import (
 	"github.com/openshift/library-go/pkg/operator/events"
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/state"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/health"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/metrics"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/notify"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -54,15 +63,47 @@ const (
 )
 
 var (
-	kubeconfig       string
-	masterURL        string
+	kubeconfig        string
+	masterURL         string
 	enableLeaderElect bool
+	workers           int
+	rateLimiterBase   time.Duration
+	rateLimiterMax    time.Duration
+	requeueInterval   time.Duration
+	healthProbeAddr   string
+	metricsAddr       string
+	cleanupDummyVMs   bool
+	credentialsDir    string
+	credentialsCmd    string
+	notificationURL   string
+	secretNamespace   string
+	requirePerms      bool
+	vcenterPort       int
+	vcenterProxyURL   string
+	vcenterOpTimeout  time.Duration
+	vcenterDialTime   time.Duration
 )
 
 func init() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file")
 	flag.StringVar(&masterURL, "master", "", "Kubernetes API server URL")
 	flag.BoolVar(&enableLeaderElect, "leader-elect", true, "Enable leader election for controller manager")
+	flag.IntVar(&workers, "workers", controller.DefaultWorkers, "Number of migrations reconciled concurrently")
+	flag.DurationVar(&rateLimiterBase, "rate-limiter-base-delay", controller.DefaultRateLimiterBaseDelay, "Initial retry delay after a failed reconcile")
+	flag.DurationVar(&rateLimiterMax, "rate-limiter-max-delay", controller.DefaultRateLimiterMaxDelay, "Maximum retry delay after repeated failed reconciles")
+	flag.DurationVar(&requeueInterval, "requeue-interval", state.DefaultRequeueInterval, "How often a running migration is reconciled when its phase asks for no specific delay")
+	flag.StringVar(&healthProbeAddr, "health-probe-bind-address", ":8081", "Address the /healthz and /readyz probe endpoints bind to")
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "Address the /metrics endpoint binds to, serving vCenter API call latency and error metrics; empty disables it")
+	flag.BoolVar(&cleanupDummyVMs, "cleanup-dummy-vms-on-startup", false, "Delete dummy VMs left behind by earlier migration runs when the controller starts leading")
+	flag.StringVar(&credentialsDir, "target-credentials-dir", "", "Directory holding one file per target vCenter credential key, such as a Secrets Store CSI mount, read instead of the migration's credentials secret")
+	flag.StringVar(&credentialsCmd, "target-credentials-command", "", "Executable given a target vCenter server that prints its credentials as JSON {\"username\", \"password\"}, run instead of reading the migration's credentials secret")
+	flag.StringVar(&notificationURL, "notification-webhook-url", "", "URL a JSON notification is POSTed to when a migration starts, completes a phase, waits for approval, pauses for review, completes or fails")
+	flag.StringVar(&secretNamespace, "credentials-secret-namespace", "", "Namespace of the migrations' target vCenter credentials secrets, checked for read access at startup (default: the controller's namespace)")
+	flag.BoolVar(&requirePerms, "require-permissions", false, "Refuse to start when the startup permission check finds permissions the controller lacks")
+	flag.IntVar(&vcenterPort, "vcenter-port", 0, "HTTPS port of vCenter servers given without one (default 443)")
+	flag.StringVar(&vcenterProxyURL, "vcenter-proxy-url", "", "http, https or socks5 proxy vCenter connections go through instead of the proxy environment variables")
+	flag.DurationVar(&vcenterOpTimeout, "vcenter-operation-timeout", vsphere.DefaultOperationTimeout, "Deadline of each vCenter inventory lookup, property retrieval and short task wait")
+	flag.DurationVar(&vcenterDialTime, "vcenter-dial-timeout", vsphere.DefaultDialTimeout, "Deadline for connecting to vCenter, including the TLS handshake")
 }
 
 func main() {
@@ -84,8 +125,41 @@ func main() {
 	logger := klog.NewKlogr().WithName("vmware-cloud-foundation-migration")
 	ctx = klog.NewContext(ctx, logger)
 
+	// Subcommands run once against the cluster instead of starting the controller
+	if flag.Arg(0) == previewCSIVolumesCommand {
+		if err := runPreviewCSIVolumes(ctx, flag.Args()[1:], os.Stdout); err != nil {
+			logger.Error(err, "Failed to preview CSI volume migration")
+			os.Exit(1)
+		}
+		return
+	}
+
 	logger.Info("Starting VMware Cloud Foundation Migration Controller")
 
+	// Serve liveness and readiness probes for the whole lifetime of the process
+	healthChecker := health.NewChecker()
+	go func() {
+		if err := healthChecker.Serve(ctx, healthProbeAddr); err != nil {
+			logger.Error(err, "Health probe server stopped")
+			os.Exit(1)
+		}
+	}()
+
+	// Serve metrics, including vCenter API call latency and errors, for the whole lifetime of the process
+	if metricsAddr != "" {
+		registry, err := metrics.NewRegistry()
+		if err != nil {
+			logger.Error(err, "Failed to create metrics registry")
+			os.Exit(1)
+		}
+		go func() {
+			if err := metrics.Serve(ctx, metricsAddr, registry); err != nil {
+				logger.Error(err, "Metrics server stopped")
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// Build Kubernetes config
 	config, err := buildConfig(kubeconfig, masterURL)
 	if err != nil {
@@ -153,11 +227,30 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Missing RBAC otherwise only surfaces as a permission error in the middle of a phase
+	if err := checkPermissions(ctx, kubeClient); err != nil {
+		logger.Error(err, "Startup permission check failed")
+		if requirePerms {
+			os.Exit(1)
+		}
+	}
+
 	// Create event recorder
 	eventRecorder := events.NewLoggingEventRecorder("vmware-cloud-foundation-migration", clock.RealClock{})
 
+	// Notifications are best-effort, but a malformed URL is a configuration error
+	var notifier notify.Notifier
+	if notificationURL != "" {
+		webhook, err := notify.NewWebhookNotifier(notificationURL)
+		if err != nil {
+			logger.Error(err, "Invalid notification webhook")
+			os.Exit(1)
+		}
+		notifier = webhook
+	}
+
 	// Create controller
-	migrationController, factoryController := controller.NewMigrationController(
+	migrationController, factoryController := controller.NewMigrationControllerWithOptions(
 		kubeClient,
 		configClient,
 		machineClient,
@@ -166,6 +259,23 @@ func main() {
 		runtimeClient,
 		scheme,
 		eventRecorder,
+		controller.Options{
+			Workers:              workers,
+			RateLimiterBaseDelay: rateLimiterBase,
+			RateLimiterMaxDelay:  rateLimiterMax,
+			RequeueInterval:      requeueInterval,
+			CredentialSource: phases.CredentialSource{
+				Dir:     credentialsDir,
+				Command: credentialsCmd,
+			},
+			Notifier: notifier,
+			VCenterConnection: vsphere.Config{
+				Port:             vcenterPort,
+				ProxyURL:         vcenterProxyURL,
+				OperationTimeout: vcenterOpTimeout,
+				DialTimeout:      vcenterDialTime,
+			},
+		},
 	)
 
 	// Set up informer for VmwareCloudFoundationMigration resources
@@ -185,29 +295,43 @@ func main() {
 			logger.Info("VmwareCloudFoundationMigration added", "obj", obj)
 			migrationController.EnqueueMigration(obj)
 		},
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			logger.Info("VmwareCloudFoundationMigration updated")
-			migrationController.EnqueueMigration(newObj)
-		},
+		// Spec, request annotation and deletion changes are reconciled; status writes are not
+		UpdateFunc: migrationController.UpdateMigration,
 		DeleteFunc: func(obj interface{}) {
+			// Cleanup runs before this point, while the finalizer holds the object
 			logger.Info("VmwareCloudFoundationMigration deleted")
 		},
 	})
 
+	// Informers run on every instance so standby replicas stay live; only the leader
+	// starts the queue workers that act on the events
+	logger.Info("Starting informers")
+	informerFactory.Start(ctx.Done())
+	healthChecker.SetInformersStarted()
+
 	// Define the run function that starts the controller
 	run := func(ctx context.Context) {
-		logger.Info("Starting informers")
-		informerFactory.Start(ctx.Done())
-
 		// Wait for cache sync
 		logger.Info("Waiting for informer cache sync")
 		if !cache.WaitForCacheSync(ctx.Done(), migrationInformer.Informer().HasSynced) {
 			logger.Error(nil, "Failed to sync informer cache")
 			os.Exit(1)
 		}
+		healthChecker.SetCacheSynced(true)
 		logger.Info("Informer cache synced")
 
-		logger.Info("Starting controller")
+		// A controller stopped mid-UpdateInfrastructure leaves the Infrastructure CRD without
+		// its vcenters validations
+		migrationController.RestoreInfrastructureCRDValidations(ctx)
+
+		if cleanupDummyVMs {
+			logger.Info("Cleaning up leftover dummy VMs")
+			migrationController.CleanupLeftoverDummyVMs(ctx)
+		}
+
+		// The factory worker only handles the periodic resync; migrations are reconciled
+		// by the controller's own queue workers (see --workers)
+		logger.Info("Starting controller", "workers", workers)
 		go factoryController.Run(ctx, 1)
 
 		logger.Info("Controller started, waiting for shutdown signal")
@@ -218,6 +342,7 @@ func main() {
 	// Run with or without leader election
 	if !enableLeaderElect {
 		logger.Info("Leader election disabled, running directly")
+		healthChecker.SetLeading(true)
 		run(ctx)
 		return
 	}
@@ -253,10 +378,12 @@ func main() {
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
 				logger.Info("Acquired leadership")
+				healthChecker.SetLeading(true)
 				run(ctx)
 			},
 			OnStoppedLeading: func() {
 				logger.Info("Lost leadership, attempting graceful shutdown")
+				healthChecker.SetLeading(false)
 				logger.Info("Waiting for grace period before exit", "duration", leadershipLossGracePeriod)
 				time.Sleep(leadershipLossGracePeriod)
 				logger.Info("Grace period complete, shutting down")
@@ -272,6 +399,31 @@ func main() {
 	})
 }
 
+// checkPermissions reports every permission the controller needs but lacks in a single error
+func checkPermissions(ctx context.Context, kubeClient kubernetes.Interface) error {
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		namespace = leaseLockNamespace
+	}
+	if secretNamespace == "" {
+		secretNamespace = namespace
+	}
+	leaseNamespace := ""
+	if enableLeaderElect {
+		leaseNamespace = leaseLockNamespace
+	}
+
+	missing, err := openshift.MissingPermissions(ctx, kubeClient, openshift.RequiredPermissions(secretNamespace, leaseNamespace))
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("controller is missing %d permission(s): %s", len(missing), strings.Join(missing, "; "))
+	}
+	klog.FromContext(ctx).Info("Startup permission check passed", "namespace", namespace, "credentialsSecretNamespace", secretNamespace)
+	return nil
+}
+
 // buildConfig builds a Kubernetes config from flags
 func buildConfig(kubeconfig, masterURL string) (*rest.Config, error) {
 	if kubeconfig != "" {
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+)
+
+// previewCSIVolumesCommand is the subcommand printing what the CSI volume migration would touch
+const previewCSIVolumesCommand = "preview-csi-volumes"
+
+// runPreviewCSIVolumes lists the volumes, PVCs and workloads the MigrateCSIVolumes phase of a
+// migration would touch, without changing anything
+func runPreviewCSIVolumes(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet(previewCSIVolumesCommand, flag.ContinueOnError)
+	namespace := fs.String("namespace", "", "Namespace of the VmwareCloudFoundationMigration")
+	name := fs.String("name", "", "Name of the VmwareCloudFoundationMigration")
+	output := fs.String("output", "table", "Output format: table or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *namespace == "" || *name == "" {
+		return fmt.Errorf("--namespace and --name of the migration are required")
+	}
+	if *output != "table" && *output != "json" {
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+
+	config, err := buildConfig(kubeconfig, masterURL)
+	if err != nil {
+		return fmt.Errorf("failed to build Kubernetes config: %w", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    "migration.openshift.io",
+		Version:  "v1alpha1",
+		Resource: "vmwarecloudfoundationmigrations",
+	}
+	unstructuredMigration, err := dynamicClient.Resource(gvr).Namespace(*namespace).Get(ctx, *name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get VmwareCloudFoundationMigration: %w", err)
+	}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredMigration.Object, migration); err != nil {
+		return fmt.Errorf("failed to convert unstructured to VmwareCloudFoundationMigration: %w", err)
+	}
+
+	previews, err := phases.PreviewCSIVolumes(ctx, kubeClient, migration)
+	if err != nil {
+		return err
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(previews)
+	}
+	return printCSIVolumePreviews(out, previews)
+}
+
+// printCSIVolumePreviews writes the previews as a table, one volume per row
+func printCSIVolumePreviews(out io.Writer, previews []phases.CSIVolumePreview) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PV\tPVC\tCAPACITY\tWORKLOADS\tACTION")
+	migrate := 0
+	for _, preview := range previews {
+		pvc := "<unbound>"
+		if preview.PVCName != "" {
+			pvc = preview.PVCNamespace + "/" + preview.PVCName
+		}
+		workloads := "<none>"
+		if len(preview.Workloads) > 0 {
+			workloads = strings.Join(preview.Workloads, ",")
+		}
+		action := "Migrate"
+		if preview.SkipReason != "" {
+			action = "Skip: " + preview.SkipReason
+		} else {
+			migrate++
+		}
+		capacity := resource.NewQuantity(preview.CapacityBytes, resource.BinarySI)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", preview.PVName, pvc, capacity, workloads, action)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "\n%d volumes: %d to migrate, %d to skip\n", len(previews), migrate, len(previews)-migrate)
+	return err
+}
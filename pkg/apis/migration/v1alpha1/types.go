@@ -23,8 +23,9 @@ type VmwareCloudFoundationMigration struct {
 // VmwareCloudFoundationMigrationSpec defines the desired state of VmwareCloudFoundationMigration
 // +k8s:deepcopy-gen=true
 type VmwareCloudFoundationMigrationSpec struct {
-	// State controls the workflow: Pending, Running, Paused, Rollback
-	// +kubebuilder:validation:Enum=Pending;Running;Paused;Rollback
+	// State controls the workflow: Pending, Running, Paused, Rollback, Cancelled. Cancelled stops
+	// the migration where it is without rolling it back, see MigrationStateCancelled.
+	// +kubebuilder:validation:Enum=Pending;Running;Paused;Rollback;Cancelled
 	// +kubebuilder:default=Pending
 	State MigrationState `json:"state"`
 
@@ -33,11 +34,37 @@ type VmwareCloudFoundationMigrationSpec struct {
 	// +kubebuilder:default=Automatic
 	ApprovalMode ApprovalMode `json:"approvalMode"`
 
+	// RequireApprovalBefore lists phases that wait for manual approval even when ApprovalMode
+	// is Automatic, so destructive phases such as UpdateInfrastructure, ScaleOldMachines,
+	// MigrateCSIVolumes or Cleanup can be gated while the rest of the migration runs unattended
+	// +optional
+	RequireApprovalBefore []MigrationPhase `json:"requireApprovalBefore,omitempty"`
+
+	// PauseAfterPreflight sets State to Paused once the Preflight phase completes, with its findings
+	// in the Reconciled condition, so they can be reviewed before State is set back to Running
+	// +optional
+	PauseAfterPreflight bool `json:"pauseAfterPreflight,omitempty"`
+
+	// PhaseWeights overrides the relative weight of phases in status.overallProgress. Phases not
+	// listed keep their default weight, which reflects how long the phase usually takes.
+	// +optional
+	PhaseWeights map[MigrationPhase]int32 `json:"phaseWeights,omitempty"`
+
 	// TargetVCenterCredentialsSecret references the secret containing target vCenter credentials
 	// The secret should contain keys: {target-vcenter-fqdn}.username and {target-vcenter-fqdn}.password
 	// Source vCenter configuration is read from the Infrastructure CRD
 	TargetVCenterCredentialsSecret SecretReference `json:"targetVCenterCredentialsSecret"`
 
+	// TargetVCenterCredentialKeys overrides the names of the keys holding the target vCenter
+	// credentials, in the secret or in the credentials directory the controller is started with
+	// +optional
+	TargetVCenterCredentialKeys *CredentialKeyFormat `json:"targetVCenterCredentialKeys,omitempty"`
+
+	// SourceVCenterServer names the vCenter in the Infrastructure CRD to migrate from. It is needed
+	// when the cluster already spans several vCenters; when unset the first vCenter is the source.
+	// +optional
+	SourceVCenterServer string `json:"sourceVCenterServer,omitempty"`
+
 	// FailureDomains defines failure domains for the target vCenter
 	// Use OpenShift's standard VSpherePlatformFailureDomainSpec which includes
 	// Name, Region, Zone, Server, and Topology with all necessary fields
@@ -52,6 +79,100 @@ type VmwareCloudFoundationMigrationSpec struct {
 	// RollbackOnFailure automatically triggers rollback on phase failure
 	// +kubebuilder:default=true
 	RollbackOnFailure bool `json:"rollbackOnFailure"`
+
+	// AutoReEnableCVO scales the cluster-version-operator back up when the Verify phase
+	// completes the migration. When false, it is left scaled down so the cluster can be checked
+	// first, and the CVODisabled condition reminds the operator to scale it back up.
+	// +kubebuilder:default=true
+	// +optional
+	AutoReEnableCVO *bool `json:"autoReEnableCVO,omitempty"`
+
+	// CSIVolumeMigration tunes the behaviour of the CSI volume migration phase
+	// +optional
+	CSIVolumeMigration *CSIVolumeMigrationConfig `json:"csiVolumeMigration,omitempty"`
+
+	// HealthCheck tunes the cluster health gate of the MonitorHealth phase
+	// +optional
+	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty"`
+
+	// BackupStorage selects where resource backups are kept
+	// +optional
+	BackupStorage *BackupStorageConfig `json:"backupStorage,omitempty"`
+
+	// Naming overrides the names of the dummy VMs and worker MachineSets the migration creates
+	// +optional
+	Naming *NamingTemplate `json:"naming,omitempty"`
+
+	// LogRetention caps the log entries kept in the phase history
+	// +optional
+	LogRetention *LogRetentionConfig `json:"logRetention,omitempty"`
+
+	// RequeueInterval is how often a running migration is reconciled while its phase has not
+	// asked for a specific delay, overriding the controller's --requeue-interval
+	// +optional
+	RequeueInterval *metav1.Duration `json:"requeueInterval,omitempty"`
+}
+
+// LogRetentionConfig caps the log entries kept in the status. Once a cap is exceeded the oldest
+// Debug and Info entries are dropped first, then the oldest warnings and errors.
+// +k8s:deepcopy-gen=true
+type LogRetentionConfig struct {
+	// MaxEntriesPerPhase is the most log entries kept for one phase (default 200)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	// +optional
+	MaxEntriesPerPhase int32 `json:"maxEntriesPerPhase,omitempty"`
+
+	// MaxTotalEntries is the most log entries kept across the whole phase history,
+	// trimming the oldest phases first (default 2000)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +optional
+	MaxTotalEntries int32 `json:"maxTotalEntries,omitempty"`
+}
+
+// NamingTemplate holds Go templates for the names of objects the migration creates.
+// The placeholders {{.InfraID}}, {{.PVName}} and {{.FailureDomain}} are available.
+// Names longer than the vSphere or Kubernetes limit are truncated with a hash suffix.
+// +k8s:deepcopy-gen=true
+type NamingTemplate struct {
+	// DummyVM names the dummy VMs used to vMotion volumes
+	// (default "csi-migration-{{.InfraID}}-{{.PVName}}")
+	// +optional
+	DummyVM string `json:"dummyVM,omitempty"`
+
+	// WorkerMachineSet names the worker MachineSets created in the target vCenter
+	// (default "{{.InfraID}}-worker-{{.FailureDomain}}")
+	// +optional
+	WorkerMachineSet string `json:"workerMachineSet,omitempty"`
+}
+
+// BackupStorageType selects where resource backups are kept
+type BackupStorageType string
+
+const (
+	// BackupStorageInline keeps backups in the migration status
+	BackupStorageInline BackupStorageType = "Inline"
+	// BackupStorageConfigMap keeps each backup in its own ConfigMap and only a reference in the
+	// status; backups of Secrets are still kept in Secrets
+	BackupStorageConfigMap BackupStorageType = "ConfigMap"
+	// BackupStorageSecret keeps each backup in its own Secret and only a reference in the status
+	BackupStorageSecret BackupStorageType = "Secret"
+)
+
+// BackupStorageConfig defines where resource backups are kept
+// +k8s:deepcopy-gen=true
+type BackupStorageConfig struct {
+	// Type selects the storage for backups. Inline keeps them in the status, which can
+	// approach the object size limit on large clusters.
+	// +kubebuilder:validation:Enum=Inline;ConfigMap;Secret
+	// +kubebuilder:default=Inline
+	// +optional
+	Type BackupStorageType `json:"type,omitempty"`
+
+	// Namespace holds the backup ConfigMaps or Secrets. Defaults to the migration's namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // MigrationState represents the overall state of the migration
@@ -62,6 +183,12 @@ const (
 	MigrationStateRunning  MigrationState = "Running"
 	MigrationStatePaused   MigrationState = "Paused"
 	MigrationStateRollback MigrationState = "Rollback"
+
+	// MigrationStateCancelled stops the migration for an operator to take over: no further phase
+	// runs and nothing is rolled back. Only transient artifacts are cleaned up and the workloads
+	// of volumes that were not yet touched are restored, then the migration ends in
+	// PhaseCancelled.
+	MigrationStateCancelled MigrationState = "Cancelled"
 )
 
 // ApprovalMode controls whether phases require manual approval
@@ -72,6 +199,37 @@ const (
 	ApprovalModeManual    ApprovalMode = "Manual"
 )
 
+// ApprovePhaseAnnotation approves the phase named in its value when that phase is waiting for approval
+const ApprovePhaseAnnotation = "migration.openshift.io/approve-phase"
+
+// RetryPhaseAnnotation re-runs the phase named in its value when it is the phase the migration
+// failed in, without re-running the phases completed before it. The controller removes the
+// annotation once it has acted on it.
+const RetryPhaseAnnotation = "migration.openshift.io/retry-phase"
+
+// AllowSourceVolumesAnnotation, set to "true", lets the Cleanup phase remove the source vCenter
+// even though volumes without a completed migration remain, e.g. after moving them by hand
+const AllowSourceVolumesAnnotation = "migration.openshift.io/allow-source-volumes"
+
+// SkipCleanupAnnotation, set to "true" on a deleted migration, releases it without cleaning up
+// after an interrupted volume migration, e.g. when the vCenter is gone for good. What is left
+// behind is recorded in a CleanupSkipped event.
+const SkipCleanupAnnotation = "migration.openshift.io/skip-cleanup"
+
+// RequestAnnotations are the annotations through which an operator asks the controller to act on
+// a migration. Setting one does not bump the generation, so the controller watches for changes to
+// each of them explicitly; a new request annotation must be added here to be acted on.
+var RequestAnnotations = []string{
+	ApprovePhaseAnnotation,
+	RetryPhaseAnnotation,
+	AllowSourceVolumesAnnotation,
+	SkipCleanupAnnotation,
+}
+
+// MigrationFinalizer holds a deleted migration until the dummy VMs and scaled-down workloads
+// of an interrupted volume migration have been cleaned up
+const MigrationFinalizer = "migration.openshift.io/cleanup"
+
 // VCenterConfig defines vCenter connection details
 // +k8s:deepcopy-gen=true
 type VCenterConfig struct {
@@ -108,15 +266,86 @@ type SecretReference struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+// CredentialKeyFormat holds Go templates for the names of the keys holding vCenter credentials.
+// The placeholder {{.Server}} is the vCenter server of the failure domain.
+// +k8s:deepcopy-gen=true
+type CredentialKeyFormat struct {
+	// Username names the key holding the username (default "{{.Server}}.username")
+	// +optional
+	Username string `json:"username,omitempty"`
+
+	// Password names the key holding the password (default "{{.Server}}.password")
+	// +optional
+	Password string `json:"password,omitempty"`
+}
+
 // MachineSetConfig defines worker machine configuration
 // +k8s:deepcopy-gen=true
 type MachineSetConfig struct {
-	// Replicas is the number of worker machines to create
-	// +kubebuilder:validation:Minimum=1
-	Replicas int32 `json:"replicas"`
+	// Replicas is the number of worker machines to create. When FailureDomains is set,
+	// it is the total spread evenly across the failure domains without their own count.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas,omitempty"`
 
-	// FailureDomain is the failure domain name to use
-	FailureDomain string `json:"failureDomain"`
+	// SurgeReplicas is the number of extra worker machines created on top of Replicas, so the
+	// new workers are over-provisioned while the old ones are scaled down. They are spread
+	// across the failure domains with workers like Replicas. Once the old machines are gone, or
+	// the migration is cancelled, the new MachineSets are settled back to Replicas.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	SurgeReplicas int32 `json:"surgeReplicas,omitempty"`
+
+	// FailureDomain is the failure domain name to use when FailureDomains is not set
+	// +optional
+	FailureDomain string `json:"failureDomain,omitempty"`
+
+	// FailureDomains creates one worker MachineSet per listed failure domain.
+	// Takes precedence over FailureDomain.
+	// +optional
+	FailureDomains []WorkerFailureDomain `json:"failureDomains,omitempty"`
+
+	// TagPlacement places worker machines by a vSphere tag instead of the failure domain's
+	// compute cluster and resource pool. It is resolved on the target vCenter of each worker
+	// failure domain when its MachineSet is created.
+	// +optional
+	TagPlacement *TagPlacement `json:"tagPlacement,omitempty"`
+
+	// MachineSetLabels are added to every worker MachineSet created and to its machine template,
+	// so the Machines carry them too. Labels the controller sets itself cannot be overridden.
+	// +optional
+	MachineSetLabels map[string]string `json:"machineSetLabels,omitempty"`
+
+	// MachineSetAnnotations are added to every worker MachineSet created, such as the cluster
+	// autoscaler's min and max size. Annotations the controller sets itself cannot be overridden.
+	// +optional
+	MachineSetAnnotations map[string]string `json:"machineSetAnnotations,omitempty"`
+}
+
+// TagPlacement names the vSphere tag attached to the compute cluster or resource pool that worker
+// machines are placed in. Exactly one compute cluster or resource pool in the failure domain's
+// datacenter must carry it; machines go to the tagged resource pool, or to the root resource pool
+// of the tagged compute cluster.
+// +k8s:deepcopy-gen=true
+type TagPlacement struct {
+	// Category is the tag category name
+	Category string `json:"category"`
+
+	// Tag is the tag name
+	Tag string `json:"tag"`
+}
+
+// WorkerFailureDomain places worker machines in one failure domain
+// +k8s:deepcopy-gen=true
+type WorkerFailureDomain struct {
+	// Name is the failure domain name, which must match an entry in spec.failureDomains
+	Name string `json:"name"`
+
+	// Replicas is the number of worker machines in this failure domain.
+	// When unset, the failure domain receives an even share of MachineSetConfig.Replicas.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
 }
 
 // ControlPlaneMachineSetConfig defines control plane machine configuration
@@ -124,6 +353,207 @@ type MachineSetConfig struct {
 type ControlPlaneMachineSetConfig struct {
 	// FailureDomain is the failure domain name to use
 	FailureDomain string `json:"failureDomain"`
+
+	// SettleDuration is how long to wait once the rollout reports every replica updated and
+	// ready before checking that etcd and kube-apiserver have settled (default 2m)
+	// +optional
+	SettleDuration *metav1.Duration `json:"settleDuration,omitempty"`
+}
+
+// CSIVolumeMigrationConfig defines tunables for CSI volume migration
+// +k8s:deepcopy-gen=true
+type CSIVolumeMigrationConfig struct {
+	// AttachVerificationTimeout is how long to read back the dummy VM device list
+	// after attaching an FCD before declaring the attach failed (default 30s)
+	// +optional
+	AttachVerificationTimeout *metav1.Duration `json:"attachVerificationTimeout,omitempty"`
+
+	// SnapshotBeforeMigrate takes an FCD snapshot of each volume on the source before
+	// relocation. The snapshot is deleted once the volume completes migration and is
+	// retained on failure so the data can be recovered. It is ignored in Clone mode, where
+	// the source FCD itself is kept.
+	// +optional
+	SnapshotBeforeMigrate bool `json:"snapshotBeforeMigrate,omitempty"`
+
+	// VerifyIntegrity checksums each volume on the source once it is detached and again on
+	// the target once it is registered, and fails the volume before its PV is updated if the
+	// checksums differ. The checksums come from the integrity checker the controller is built
+	// with; without one, no checksum is computed and volumes migrate unchecked.
+	// +optional
+	VerifyIntegrity bool `json:"verifyIntegrity,omitempty"`
+
+	// MigrationMode selects how volumes reach the target. Move relocates each FCD with vMotion
+	// and leaves nothing on the source. Clone copies it to the target, points the PV at the copy
+	// and keeps the source FCD for SourceRetention as a fallback.
+	// +kubebuilder:validation:Enum=Move;Clone
+	// +kubebuilder:default=Move
+	// +optional
+	MigrationMode VolumeMigrationMode `json:"migrationMode,omitempty"`
+
+	// MigrationStrategy selects what happens to the PVC of a volume. RecreatePVC deletes it once
+	// its workloads are scaled down and recreates it from a backup, bound to the migrated PV.
+	// InPlaceHandleSwap keeps every PVC bound and only swaps the volumeHandle of its PV once the
+	// volume is detached, as is always done for StatefulSet claims. It is only safe with a CSI
+	// driver that tolerates the volumeHandle of a bound PV changing while no pod uses it.
+	// +kubebuilder:validation:Enum=RecreatePVC;InPlaceHandleSwap
+	// +kubebuilder:default=RecreatePVC
+	// +optional
+	MigrationStrategy PVCMigrationStrategy `json:"migrationStrategy,omitempty"`
+
+	// SourceRetention is how long the source FCD of a cloned volume is kept after the clone
+	// (default 168h). The Cleanup phase waits for the retention of every source to expire,
+	// requeueing until then, and deletes them before it removes the source vCenter.
+	// +optional
+	SourceRetention *metav1.Duration `json:"sourceRetention,omitempty"`
+
+	// VolumeSelector restricts migration to a subset of vSphere CSI volumes.
+	// When unset, every vSphere CSI volume is migrated.
+	// +optional
+	VolumeSelector *VolumeSelector `json:"volumeSelector,omitempty"`
+
+	// FailOnInTreeVolumes fails preflight when in-tree vSphere (non-CSI) volumes are present.
+	// In-tree volumes are not migrated; by default preflight only warns about them.
+	// +optional
+	FailOnInTreeVolumes bool `json:"failOnInTreeVolumes,omitempty"`
+
+	// BatchSize is the maximum number of volumes attached to one dummy VM and relocated
+	// together in a single cross-vCenter vMotion. Batched volumes wait, quiesced, until
+	// their batch is relocated. When unset or 1, each volume is relocated on its own.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	// +optional
+	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// QuiesceExcludeNamespaces lists namespaces whose workloads are never scaled down, such as
+	// the monitoring stack or operator-managed databases with their own failover. A volume whose
+	// PVC is used by a workload in one of them is skipped and left on the source.
+	// +optional
+	QuiesceExcludeNamespaces []string `json:"quiesceExcludeNamespaces,omitempty"`
+
+	// TargetHost pins volume relocation to a host in the compute cluster of the first failure
+	// domain, given as an inventory path or name, such as a host whose vMotion or provisioning
+	// vmknic is on a dedicated network. Preflight checks the host is in that cluster and
+	// connected. When unset, DRS picks the host.
+	// +optional
+	TargetHost string `json:"targetHost,omitempty"`
+
+	// VolumeDatastoreOverrides relocates individual volumes to a target datastore other than
+	// the one of the first failure domain, such as a faster or larger tier. Keys are PV names
+	// or PVCs given as namespace/name, values are datastore paths in the first failure domain's
+	// datacenter. A PV name takes precedence over its PVC. Preflight checks every datastore.
+	// +optional
+	VolumeDatastoreOverrides map[string]string `json:"volumeDatastoreOverrides,omitempty"`
+
+	// TargetDatastoreCluster relocates volumes to a datastore cluster (Storage DRS pod) in the
+	// first failure domain's datacenter instead of its datastore, letting Storage DRS pick the
+	// member datastore of each relocation. Volumes with a datastore override still go to their
+	// override. Preflight checks Storage DRS is enabled and a member datastore is usable.
+	// +optional
+	TargetDatastoreCluster string `json:"targetDatastoreCluster,omitempty"`
+
+	// ValidateDiskBeforeMigrate briefly powers on each dummy VM once its volumes are attached,
+	// so ESXi opens every disk and a stale or corrupt backing fails the volume before a long
+	// vMotion rather than after it. The VM is powered off again before it is relocated. This
+	// adds a power cycle per batch and needs the VirtualMachine.Interact.PowerOn privilege on
+	// the source folder.
+	// +optional
+	ValidateDiskBeforeMigrate bool `json:"validateDiskBeforeMigrate,omitempty"`
+
+	// WorkloadReadinessTimeout makes each volume wait, once its workloads are restored, up to
+	// this long for them to have all their original replicas ready. The volume stays
+	// WorkloadsRestored and is checked again on each reconcile, while other volumes carry on. A
+	// volume whose workloads are still not ready, such as when the migrated volume cannot be
+	// mounted on the target, completes with a warning listing them in UnreadyWorkloads. When
+	// unset, volumes complete as soon as their workloads are scaled back up.
+	// +optional
+	WorkloadReadinessTimeout *metav1.Duration `json:"workloadReadinessTimeout,omitempty"`
+
+	// MaxRelocateTaskDuration bounds how long a single relocate or clone task may run. A task
+	// still running after this long is cancelled and its volumes fail, so they can be retried,
+	// instead of blocking the migration on a stuck vMotion. Defaults to 12h.
+	// +optional
+	MaxRelocateTaskDuration *metav1.Duration `json:"maxRelocateTaskDuration,omitempty"`
+
+	// ScaledDownAlertThreshold is how long the workloads of a failed volume may stay scaled down,
+	// counted from the start of the volume's migration, before a Warning event is emitted and the
+	// WorkloadsScaledDown condition is set. Defaults to 30m.
+	// +optional
+	ScaledDownAlertThreshold *metav1.Duration `json:"scaledDownAlertThreshold,omitempty"`
+
+	// ForceDeleteBlockingPods force-deletes, with no grace period, the pods still using a PVC
+	// the migration deleted once the kubernetes.io/pvc-protection finalizer has kept it
+	// Terminating for them, such as pods stuck on an unreachable node, and waits for the PVC
+	// again. Without it the volume fails naming those pods.
+	// +optional
+	ForceDeleteBlockingPods bool `json:"forceDeleteBlockingPods,omitempty"`
+
+	// ReservedSCSIUnits lists SCSI unit numbers, on every controller of the dummy VM, that
+	// migrated volumes are never attached at, such as units a dummy VM template keeps for its
+	// own disks. Units already in use by any device are always skipped, as is unit 7, which
+	// belongs to the controller.
+	// +kubebuilder:validation:items:Minimum=0
+	// +kubebuilder:validation:items:Maximum=15
+	// +optional
+	ReservedSCSIUnits []int32 `json:"reservedSCSIUnits,omitempty"`
+
+	// RetainDummyVMOnFailure keeps the dummy VM of a batch whose relocation failed, for
+	// inspecting why the vMotion failed, instead of destroying it. The volumes are detached from
+	// it first, and a VM they cannot be detached from is kept as it always is. Retained VMs are
+	// recorded on their volumes and deleted by the Cleanup phase or an operator; cancellation and
+	// the startup cleanup leave them.
+	// +optional
+	RetainDummyVMOnFailure bool `json:"retainDummyVMOnFailure,omitempty"`
+}
+
+// VolumeMigrationMode selects how volumes are migrated to the target
+type VolumeMigrationMode string
+
+const (
+	// VolumeMigrationModeMove relocates volumes with vMotion
+	VolumeMigrationModeMove VolumeMigrationMode = "Move"
+	// VolumeMigrationModeClone copies volumes to the target and keeps the source
+	VolumeMigrationModeClone VolumeMigrationMode = "Clone"
+)
+
+// PVCMigrationStrategy selects how the PVC of a migrated volume is handled
+type PVCMigrationStrategy string
+
+const (
+	// PVCStrategyRecreate deletes the PVC and recreates it bound to the migrated PV
+	PVCStrategyRecreate PVCMigrationStrategy = "RecreatePVC"
+	// PVCStrategyInPlaceHandleSwap keeps the PVC bound and swaps the volumeHandle of its PV
+	PVCStrategyInPlaceHandleSwap PVCMigrationStrategy = "InPlaceHandleSwap"
+)
+
+// VolumeSelector selects the PersistentVolumes to migrate.
+// A volume is selected only when it matches every criterion that is set.
+// +k8s:deepcopy-gen=true
+type VolumeSelector struct {
+	// PVCLabelSelector selects volumes whose bound PVC matches the label selector
+	// +optional
+	PVCLabelSelector *metav1.LabelSelector `json:"pvcLabelSelector,omitempty"`
+
+	// Namespaces selects volumes whose bound PVC is in one of these namespaces
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// PVNames selects volumes by PersistentVolume name
+	// +optional
+	PVNames []string `json:"pvNames,omitempty"`
+}
+
+// HealthCheckConfig defines tunables for the MonitorHealth phase
+// +k8s:deepcopy-gen=true
+type HealthCheckConfig struct {
+	// ToleratedOperators lists ClusterOperators known to be transiently degraded
+	// during migration. They are reported but do not block the phase.
+	// +optional
+	ToleratedOperators []string `json:"toleratedOperators,omitempty"`
+
+	// Timeout is the maximum time to wait for ClusterOperators to become healthy
+	// before the phase declares the cluster unhealthy (default 30m)
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
 }
 
 // VmwareCloudFoundationMigrationStatus defines the observed state of VmwareCloudFoundationMigration
@@ -132,6 +562,15 @@ type VmwareCloudFoundationMigrationStatus struct {
 	// Phase is the current migration phase
 	Phase MigrationPhase `json:"phase,omitempty"`
 
+	// OverallProgress is the completion percentage (0-100) of the whole migration, weighting each
+	// phase by how long it usually takes. It only decreases when the migration is rolled back.
+	// +optional
+	OverallProgress int32 `json:"overallProgress,omitempty"`
+
+	// ObservedGeneration is the spec generation the controller last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions represent the latest available observations of the migration state
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
@@ -144,6 +583,40 @@ type VmwareCloudFoundationMigrationStatus struct {
 	// BackupManifests stores backups for rollback
 	BackupManifests []BackupManifest `json:"backupManifests,omitempty"`
 
+	// SourceVCenter is the server of the vCenter being migrated from, recorded by Preflight so it
+	// is still known once Cleanup has removed it from the Infrastructure
+	// +optional
+	SourceVCenter string `json:"sourceVCenter,omitempty"`
+
+	// CSIDriverConfigUpdateTime is when Cleanup pointed the vSphere CSI driver configuration at
+	// the target vCenters, unset if it already listed them. Verify checks the CSI controller has
+	// restarted since.
+	// +optional
+	CSIDriverConfigUpdateTime *metav1.Time `json:"csiDriverConfigUpdateTime,omitempty"`
+
+	// ControlPlaneRolloutCompleteTime is when RecreateCPMS first saw the control plane rollout
+	// complete, the start of its settle period
+	// +optional
+	ControlPlaneRolloutCompleteTime *metav1.Time `json:"controlPlaneRolloutCompleteTime,omitempty"`
+
+	// BlockedBy is the namespace/name of the active migration this one waits for. Only one
+	// migration may be running or rolling back at a time, since each rewrites the cluster-wide
+	// Infrastructure and ControlPlaneMachineSet.
+	// +optional
+	BlockedBy string `json:"blockedBy,omitempty"`
+
+	// InfrastructureCRDValidations holds, per version of the Infrastructure CRD, the JSON of the
+	// x-kubernetes-validations rules of its vcenters field, recorded by Backup. UpdateInfrastructure
+	// removes the rules while it adds the target vCenter; the controller restores them from here
+	// at startup if it stopped before they were put back.
+	// +optional
+	InfrastructureCRDValidations map[string]string `json:"infrastructureCRDValidations,omitempty"`
+
+	// CVOReplicas is the replica count of the cluster-version-operator before DisableCVO scaled
+	// it down, recorded by Backup. Re-enabling the CVO restores it.
+	// +optional
+	CVOReplicas *int32 `json:"cvoReplicas,omitempty"`
+
 	// StartTime is when the migration started
 	StartTime *metav1.Time `json:"startTime,omitempty"`
 
@@ -152,6 +625,43 @@ type VmwareCloudFoundationMigrationStatus struct {
 
 	// CSIVolumeMigration tracks CSI volume migration progress
 	CSIVolumeMigration *CSIVolumeMigrationStatus `json:"csiVolumeMigration,omitempty"`
+
+	// Cancellation records what was and was not done when the migration was cancelled
+	// +optional
+	Cancellation *CancellationStatus `json:"cancellation,omitempty"`
+}
+
+// CancellationStatus records the outcome of cancelling a migration
+// +k8s:deepcopy-gen=true
+type CancellationStatus struct {
+	// CancelledPhase is the phase the migration was in when it was cancelled
+	CancelledPhase MigrationPhase `json:"cancelledPhase"`
+
+	// CancelTime is when the migration was cancelled
+	CancelTime metav1.Time `json:"cancelTime"`
+
+	// RemainingPhases lists the phases that did not run, including the cancelled one unless it
+	// had completed
+	// +optional
+	RemainingPhases []MigrationPhase `json:"remainingPhases,omitempty"`
+
+	// RestoredWorkloads lists, as <Kind>/<namespace>/<name>, the workloads scaled back up
+	// because their volumes had not been touched yet
+	// +optional
+	RestoredWorkloads []string `json:"restoredWorkloads,omitempty"`
+
+	// DeletedDummyVMs lists the leftover dummy VMs deleted
+	// +optional
+	DeletedDummyVMs []string `json:"deletedDummyVMs,omitempty"`
+
+	// SettledMachineSets lists the new worker MachineSets scaled back from their surge replicas
+	// +optional
+	SettledMachineSets []string `json:"settledMachineSets,omitempty"`
+
+	// ManualIntervention lists what was left for an operator, such as part-way migrated
+	// volumes whose workloads stay scaled down and dummy VMs that still hold disks
+	// +optional
+	ManualIntervention []string `json:"manualIntervention,omitempty"`
 }
 
 // CSIVolumeMigrationStatus tracks overall CSI volume migration progress
@@ -166,8 +676,78 @@ type CSIVolumeMigrationStatus struct {
 	// FailedVolumes is the number of volumes that failed migration
 	FailedVolumes int32 `json:"failedVolumes"`
 
+	// SkippedVolumes is the number of volumes left on the source, e.g. because a resize was in progress
+	SkippedVolumes int32 `json:"skippedVolumes,omitempty"`
+
 	// Volumes tracks individual volume migration states
 	Volumes []PVMigrationState `json:"volumes,omitempty"`
+
+	// ManualInterventionRequired lists the volumes an operator needs to act on, with the
+	// step each one stopped at and how to remediate it
+	// +optional
+	ManualInterventionRequired []VolumeIntervention `json:"manualInterventionRequired,omitempty"`
+
+	// MissingSourceVolumes lists the volumes whose FCD no longer existed on the source before
+	// their migration started, such as one deleted out of band. They are not migration failures:
+	// there is nothing left to migrate, but their PVs need an operator.
+	// +optional
+	MissingSourceVolumes []VolumeIntervention `json:"missingSourceVolumes,omitempty"`
+
+	// PartiallyMigratedWorkloads lists the StatefulSets kept scaled down because some of their
+	// volumes were migrated and others were not
+	// +optional
+	PartiallyMigratedWorkloads []PartiallyMigratedWorkload `json:"partiallyMigratedWorkloads,omitempty"`
+}
+
+// PartiallyMigratedWorkload describes a workload group whose volumes did not all migrate.
+// It stays scaled down so no pod starts with a volume left behind on the source.
+// +k8s:deepcopy-gen=true
+type PartiallyMigratedWorkload struct {
+	// WorkloadGroup identifies the workloads as <Kind>/<namespace>/<name>
+	WorkloadGroup string `json:"workloadGroup"`
+
+	// MigratedVolumes are the PVs of the group now on the target
+	// +optional
+	MigratedVolumes []string `json:"migratedVolumes,omitempty"`
+
+	// UnmigratedVolumes describe the PVs of the StatefulSet that failed or were skipped
+	UnmigratedVolumes []string `json:"unmigratedVolumes"`
+}
+
+// VolumeIntervention describes a volume left needing manual intervention by the CSI volume migration
+// +k8s:deepcopy-gen=true
+type VolumeIntervention struct {
+	// PVName is the PersistentVolume name
+	PVName string `json:"pvName"`
+
+	// PVCName is the PersistentVolumeClaim name
+	PVCName string `json:"pvcName,omitempty"`
+
+	// PVCNamespace is the PersistentVolumeClaim namespace
+	PVCNamespace string `json:"pvcNamespace,omitempty"`
+
+	// FailedStep is the volume status the migration could not advance from
+	FailedStep string `json:"failedStep"`
+
+	// Error is the error that stopped the volume
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// ScaledDownResources are the workloads still scaled down for the volume
+	// +optional
+	ScaledDownResources []ScaledResource `json:"scaledDownResources,omitempty"`
+
+	// Hint describes how to remediate the volume
+	Hint string `json:"hint"`
+}
+
+// VolumeTag is a vSphere tag attached to a volume, identified by the names of its category and tag
+type VolumeTag struct {
+	// Category is the name of the tag's category
+	Category string `json:"category"`
+
+	// Name is the name of the tag
+	Name string `json:"name"`
 }
 
 // PVMigrationState tracks individual PV migration
@@ -182,6 +762,11 @@ type PVMigrationState struct {
 	// PVCNamespace is the PersistentVolumeClaim namespace
 	PVCNamespace string `json:"pvcNamespace,omitempty"`
 
+	// PVPhase is the phase of the PV when it was discovered. Released, Available and Failed
+	// volumes have no claim or workloads, so they are migrated without quiesce or PVC handling.
+	// +optional
+	PVPhase string `json:"pvPhase,omitempty"`
+
 	// SourceVolumePath is the VMDK path on source vCenter
 	SourceVolumePath string `json:"sourceVolumePath"`
 
@@ -194,15 +779,70 @@ type PVMigrationState struct {
 	// TargetVolumeID is the FCD ID on target vCenter
 	TargetVolumeID string `json:"targetVolumeID,omitempty"`
 
+	// SourceRetainedUntil is when the source FCD of a volume migrated in Clone mode may be
+	// deleted. It is cleared once the source FCD has been deleted.
+	// +optional
+	SourceRetainedUntil *metav1.Time `json:"sourceRetainedUntil,omitempty"`
+
+	// SnapshotID is the FCD snapshot taken before relocation, if SnapshotBeforeMigrate is set
+	SnapshotID string `json:"snapshotID,omitempty"`
+
+	// SourceChecksum is the checksum of the volume taken on the source before relocation, if
+	// VerifyIntegrity is set
+	// +optional
+	SourceChecksum string `json:"sourceChecksum,omitempty"`
+
+	// TargetChecksum is the checksum of the volume taken on the target after registration, if
+	// VerifyIntegrity is set
+	// +optional
+	TargetChecksum string `json:"targetChecksum,omitempty"`
+
 	// DummyVMName is the name of the dummy VM used for vMotion
 	DummyVMName string `json:"dummyVMName,omitempty"`
 
-	// Status is the migration status: Pending, RetainSet, Quiesced, PVCDeleted, Relocating, Relocated, Registered, PVUpdated, Complete, Failed
+	// RetainedDummyVM is the vCenter and name, as <server>/<name>, of the dummy VM kept for
+	// inspection after the relocation of the volume failed, with the volume detached from it and
+	// renamed with a -failed-<timestamp> suffix. It is kept through retries of the volume until the
+	// Cleanup phase deletes the VM; a later failed relocation records its own VM instead.
+	// +optional
+	RetainedDummyVM string `json:"retainedDummyVM,omitempty"`
+
+	// Status is the migration status: Pending, RetainSet, Quiesced, PVCDeleted, Relocating, Relocated, Registered, PVUpdated, WorkloadsRestored, Complete, Failed, Skipped, SourceMissing
 	Status string `json:"status"`
 
 	// Message is a human-readable status message
 	Message string `json:"message,omitempty"`
 
+	// SourceCNSLabels are the labels recorded against the volume in CNS on the source vCenter,
+	// set again on the volume once it is registered on the target
+	// +optional
+	SourceCNSLabels map[string]string `json:"sourceCNSLabels,omitempty"`
+
+	// SourceTags are the vSphere tags attached to the source volume, attached again to the
+	// volume once it is registered on the target
+	// +optional
+	SourceTags []VolumeTag `json:"sourceTags,omitempty"`
+
+	// UnreadyWorkloads lists the workloads, as <Kind>/<namespace>/<name>, that were restored but
+	// not ready within the WorkloadReadinessTimeout when the volume completed
+	// +optional
+	UnreadyWorkloads []string `json:"unreadyWorkloads,omitempty"`
+
+	// WorkloadsReadyDeadline is when a WorkloadsRestored volume stops waiting for its workloads
+	// to become ready and completes, listing those still not ready in UnreadyWorkloads
+	// +optional
+	WorkloadsReadyDeadline *metav1.Time `json:"workloadsReadyDeadline,omitempty"`
+
+	// ScaledDownAlertTime is when the workloads of the failed volume were reported as scaled down
+	// beyond the ScaledDownAlertThreshold
+	// +optional
+	ScaledDownAlertTime *metav1.Time `json:"scaledDownAlertTime,omitempty"`
+
+	// ErrorClass classifies the error that failed the volume: Validation, Transient, DataSafety or
+	// Unrecoverable. DataSafety volumes are left untouched by rollback.
+	// +optional
+	ErrorClass string `json:"errorClass,omitempty"`
+
 	// ScaledDownResources tracks resources that were scaled down for this PV
 	ScaledDownResources []ScaledResource `json:"scaledDownResources,omitempty"`
 
@@ -214,6 +854,24 @@ type PVMigrationState struct {
 
 	// WorkloadType indicates primary workload type (StatefulSet, Deployment, etc.)
 	WorkloadType string `json:"workloadType,omitempty"`
+
+	// InPlaceHandleSwap records that the PVC is kept bound and only the volumeHandle of the PV
+	// is swapped, whatever the workload type
+	InPlaceHandleSwap bool `json:"inPlaceHandleSwap,omitempty"`
+
+	// WorkloadGroup identifies the workloads (<Kind>/<namespace>/<name>) whose PVCs are migrated
+	// as a set, such as the per-replica PVCs of a StatefulSet or the PVCs a Deployment's pods
+	// mount together: the workloads are scaled down once and restored once all are migrated
+	WorkloadGroup string `json:"workloadGroup,omitempty"`
+
+	// StartTime is when the volume left the Pending state
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the volume reached Complete or Failed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Duration is the time taken between StartTime and CompletionTime
+	Duration *metav1.Duration `json:"duration,omitempty"`
 }
 
 // ScaledResource tracks a resource that was scaled down during migration
@@ -230,6 +888,14 @@ type ScaledResource struct {
 
 	// OriginalReplicas is the replica count before scaling down
 	OriginalReplicas int32 `json:"originalReplicas"`
+
+	// UpdateStrategy is the update strategy type of a StatefulSet when it was scaled down
+	// +optional
+	UpdateStrategy string `json:"updateStrategy,omitempty"`
+
+	// Partition is the rolling update partition of a StatefulSet when it was scaled down
+	// +optional
+	Partition *int32 `json:"partition,omitempty"`
 }
 
 // MigrationPhase represents the current phase of migration
@@ -258,6 +924,7 @@ const (
 	PhaseFailed               MigrationPhase = "Failed"
 	PhaseRollingBack          MigrationPhase = "RollingBack"
 	PhaseRollbackCompleted    MigrationPhase = "RollbackCompleted"
+	PhaseCancelled            MigrationPhase = "Cancelled"
 )
 
 // PhaseHistoryEntry records the execution of a phase
@@ -310,6 +977,16 @@ type PhaseState struct {
 	// LastHeartbeat tracks the last time the phase was actively being processed.
 	// Used to detect stale phase execution that may need recovery.
 	LastHeartbeat *metav1.Time `json:"lastHeartbeat,omitempty"`
+
+	// TransientRetries counts the consecutive transient errors the phase has been retried after
+	// +optional
+	TransientRetries int32 `json:"transientRetries,omitempty"`
+
+	// NextReconcileTime is when the running phase asked to be executed again. Reconciles before
+	// then that come with no spec change, such as those caused by the controller's own status
+	// writes, are skipped.
+	// +optional
+	NextReconcileTime *metav1.Time `json:"nextReconcileTime,omitempty"`
 }
 
 // PhaseStatus represents the status of a phase
@@ -321,6 +998,9 @@ const (
 	PhaseStatusCompleted PhaseStatus = "Completed"
 	PhaseStatusFailed    PhaseStatus = "Failed"
 	PhaseStatusSkipped   PhaseStatus = "Skipped"
+
+	// PhaseStatusBlocked marks a phase that waits for another active migration to finish
+	PhaseStatusBlocked PhaseStatus = "Blocked"
 )
 
 // LogEntry represents a structured log entry
@@ -364,11 +1044,35 @@ type BackupManifest struct {
 	// Namespace is the resource namespace (if applicable)
 	Namespace string `json:"namespace,omitempty"`
 
-	// BackupData is the base64-encoded YAML
-	BackupData string `json:"backupData"`
+	// BackupData is the base64-encoded YAML, empty when the backup is stored externally
+	BackupData string `json:"backupData,omitempty"`
+
+	// StorageRef locates the backup when it is stored outside the migration status
+	// +optional
+	StorageRef *BackupStorageRef `json:"storageRef,omitempty"`
 
 	// BackupTime is when the backup was created
 	BackupTime metav1.Time `json:"backupTime"`
+
+	// Checksum is the hex SHA-256 of the YAML manifest, verified before the backup is restored
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// BackupStorageRef locates a backup kept in a ConfigMap or Secret
+// +k8s:deepcopy-gen=true
+type BackupStorageRef struct {
+	// Kind is ConfigMap or Secret
+	Kind string `json:"kind"`
+
+	// Name is the object name
+	Name string `json:"name"`
+
+	// Namespace is the object namespace
+	Namespace string `json:"namespace"`
+
+	// Key is the data key holding the YAML manifest
+	Key string `json:"key"`
 }
 
 // Condition types
@@ -381,6 +1085,14 @@ const (
 
 	// ConditionProgressing indicates whether the migration is progressing
 	ConditionProgressing string = "Progressing"
+
+	// ConditionWorkloadsScaledDown indicates whether workloads of failed volumes have stayed
+	// scaled down beyond the alert threshold
+	ConditionWorkloadsScaledDown string = "WorkloadsScaledDown"
+
+	// ConditionCVODisabled indicates whether the migration completed with the
+	// cluster-version-operator left scaled down
+	ConditionCVODisabled string = "CVODisabled"
 )
 
 // Condition reasons
@@ -392,6 +1104,14 @@ const (
 	ReasonProgressing        string = "Progressing"
 	ReasonCompleted          string = "Completed"
 	ReasonFailed             string = "Failed"
+	ReasonWaitingForApproval string = "WaitingForApproval"
+	ReasonBlocked            string = "Blocked"
+	ReasonPausedForReview    string = "PausedForReview"
+	ReasonScaledDownTooLong  string = "ScaledDownTooLong"
+	ReasonWorkloadsRestored  string = "WorkloadsRestored"
+	ReasonCVOKeptDisabled    string = "CVOKeptDisabled"
+	ReasonCancelled          string = "Cancelled"
+	ReasonCleanupSkipped     string = "CleanupSkipped"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
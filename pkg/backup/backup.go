@@ -2,15 +2,22 @@ package backup
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
@@ -18,16 +25,31 @@ import (
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 )
 
+const (
+	// backupManifestKey is the data key holding the YAML manifest in backup ConfigMaps and Secrets
+	backupManifestKey = "manifest.yaml"
+
+	// BackupMigrationLabel labels backup ConfigMaps and Secrets with the owning migration's name
+	BackupMigrationLabel = "migration.openshift.io/migration"
+)
+
 // BackupManager manages resource backups
 type BackupManager struct {
-	scheme *runtime.Scheme
+	scheme     *runtime.Scheme
+	kubeClient kubernetes.Interface
 }
 
-// NewBackupManager creates a new backup manager
+// NewBackupManager creates a new backup manager that keeps backups inline in the migration status
 func NewBackupManager(scheme *runtime.Scheme) *BackupManager {
 	return &BackupManager{scheme: scheme}
 }
 
+// NewBackupManagerWithClient creates a new backup manager that can also keep backups in
+// ConfigMaps or Secrets, as selected by the migration's BackupStorage
+func NewBackupManagerWithClient(scheme *runtime.Scheme, kubeClient kubernetes.Interface) *BackupManager {
+	return &BackupManager{scheme: scheme, kubeClient: kubeClient}
+}
+
 // getGVKForObject determines the GVK for a given object
 func (m *BackupManager) getGVKForObject(obj client.Object) (schema.GroupVersionKind, error) {
 	// First try to get GVK from the object itself
@@ -107,6 +129,7 @@ func (m *BackupManager) BackupResource(ctx context.Context, obj client.Object, r
 		Namespace:    obj.GetNamespace(),
 		BackupData:   encodedData,
 		BackupTime:   metav1.Now(),
+		Checksum:     manifestChecksum(yamlData),
 	}
 
 	logger.Info("Backed up resource",
@@ -119,6 +142,12 @@ func (m *BackupManager) BackupResource(ctx context.Context, obj client.Object, r
 	return backup, nil
 }
 
+// manifestChecksum returns the hex SHA-256 of a backup's YAML manifest
+func manifestChecksum(yamlData []byte) string {
+	sum := sha256.Sum256(yamlData)
+	return hex.EncodeToString(sum[:])
+}
+
 // AddBackupToMigration adds a backup manifest to the migration status
 func (m *BackupManager) AddBackupToMigration(migration *migrationv1alpha1.VmwareCloudFoundationMigration, backup *migrationv1alpha1.BackupManifest) {
 	// Check if backup already exists
@@ -136,6 +165,151 @@ func (m *BackupManager) AddBackupToMigration(migration *migrationv1alpha1.Vmware
 	migration.Status.BackupManifests = append(migration.Status.BackupManifests, *backup)
 }
 
+// StoreBackup records a backup on the migration, writing its data to a ConfigMap or Secret
+// and keeping only a reference in the status when external backup storage is configured.
+// A backup of a Secret is always written to a Secret, so ConfigMap storage does not expose
+// credentials in plaintext.
+func (m *BackupManager) StoreBackup(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, backup *migrationv1alpha1.BackupManifest) error {
+	logger := klog.FromContext(ctx)
+
+	storageType, namespace := backupStorage(migration)
+	if storageType == migrationv1alpha1.BackupStorageInline {
+		m.AddBackupToMigration(migration, backup)
+		return nil
+	}
+	if storageType == migrationv1alpha1.BackupStorageConfigMap && backup.ResourceType == "Secret" {
+		storageType = migrationv1alpha1.BackupStorageSecret
+	}
+
+	if m.kubeClient == nil {
+		return fmt.Errorf("backup storage %s requires a Kubernetes client", storageType)
+	}
+
+	yamlData, err := base64.StdEncoding.DecodeString(backup.BackupData)
+	if err != nil {
+		return fmt.Errorf("failed to decode backup data: %w", err)
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      backupObjectName(migration.Name, backup),
+		Namespace: namespace,
+		Labels: map[string]string{
+			BackupMigrationLabel: migration.Name,
+		},
+	}
+	// Backups in the migration's namespace are garbage collected with the migration
+	if namespace == migration.Namespace && migration.UID != "" {
+		objectMeta.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: migrationv1alpha1.GroupVersion.String(),
+				Kind:       "VmwareCloudFoundationMigration",
+				Name:       migration.Name,
+				UID:        migration.UID,
+			},
+		}
+	}
+
+	switch storageType {
+	case migrationv1alpha1.BackupStorageConfigMap:
+		cm := &corev1.ConfigMap{
+			ObjectMeta: objectMeta,
+			Data:       map[string]string{backupManifestKey: string(yamlData)},
+		}
+		err = m.createOrUpdateConfigMap(ctx, cm)
+	case migrationv1alpha1.BackupStorageSecret:
+		secret := &corev1.Secret{
+			ObjectMeta: objectMeta,
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{backupManifestKey: yamlData},
+		}
+		err = m.createOrUpdateSecret(ctx, secret)
+	default:
+		return fmt.Errorf("unsupported backup storage type %s", storageType)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store backup of %s/%s in %s %s/%s: %w",
+			backup.ResourceType, backup.Name, storageType, namespace, objectMeta.Name, err)
+	}
+
+	stored := *backup
+	stored.BackupData = ""
+	stored.StorageRef = &migrationv1alpha1.BackupStorageRef{
+		Kind:      string(storageType),
+		Name:      objectMeta.Name,
+		Namespace: namespace,
+		Key:       backupManifestKey,
+	}
+	m.AddBackupToMigration(migration, &stored)
+
+	logger.Info("Stored backup externally",
+		"resourceType", backup.ResourceType,
+		"name", backup.Name,
+		"storage", storageType,
+		"object", namespace+"/"+objectMeta.Name)
+	return nil
+}
+
+func (m *BackupManager) createOrUpdateConfigMap(ctx context.Context, cm *corev1.ConfigMap) error {
+	existing, err := m.kubeClient.CoreV1().ConfigMaps(cm.Namespace).Get(ctx, cm.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = m.kubeClient.CoreV1().ConfigMaps(cm.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing.Labels = cm.Labels
+	existing.Data = cm.Data
+	_, err = m.kubeClient.CoreV1().ConfigMaps(cm.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func (m *BackupManager) createOrUpdateSecret(ctx context.Context, secret *corev1.Secret) error {
+	existing, err := m.kubeClient.CoreV1().Secrets(secret.Namespace).Get(ctx, secret.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = m.kubeClient.CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	existing.Labels = secret.Labels
+	existing.Data = secret.Data
+	_, err = m.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// backupStorage returns the configured backup storage type and namespace
+func backupStorage(migration *migrationv1alpha1.VmwareCloudFoundationMigration) (migrationv1alpha1.BackupStorageType, string) {
+	storage := migration.Spec.BackupStorage
+	if storage == nil || storage.Type == "" {
+		return migrationv1alpha1.BackupStorageInline, ""
+	}
+	namespace := storage.Namespace
+	if namespace == "" {
+		namespace = migration.Namespace
+	}
+	return storage.Type, namespace
+}
+
+// invalidNameChars matches characters not allowed in a DNS subdomain name
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// backupObjectName builds a stable, valid object name for a backup
+func backupObjectName(migrationName string, backup *migrationv1alpha1.BackupManifest) string {
+	parts := []string{migrationName, "backup", backup.ResourceType}
+	if backup.Namespace != "" {
+		parts = append(parts, backup.Namespace)
+	}
+	parts = append(parts, backup.Name)
+
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(strings.Join(parts, "-")), "-")
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = name[:validation.DNS1123SubdomainMaxLength]
+	}
+	return strings.Trim(name, "-.")
+}
+
 // GetBackup retrieves a backup manifest from the migration
 func (m *BackupManager) GetBackup(migration *migrationv1alpha1.VmwareCloudFoundationMigration, resourceType, name, namespace string) (*migrationv1alpha1.BackupManifest, error) {
 	for _, backup := range migration.Status.BackupManifests {
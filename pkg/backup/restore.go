@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -40,10 +41,9 @@ func (m *RestoreManager) RestoreResource(ctx context.Context, backup *migrationv
 		return fmt.Errorf("restore manager not properly initialized: client is nil")
 	}
 
-	// Decode base64
-	yamlData, err := base64.StdEncoding.DecodeString(backup.BackupData)
+	yamlData, err := m.loadBackupData(ctx, backup)
 	if err != nil {
-		return fmt.Errorf("failed to decode backup data: %w", err)
+		return err
 	}
 
 	// Unmarshal YAML to unstructured object
@@ -97,6 +97,71 @@ func (m *RestoreManager) RestoreResource(ctx context.Context, backup *migrationv
 	return nil
 }
 
+// DecodeBackup loads the manifest of a backup and decodes it into obj, such as a typed object
+func (m *RestoreManager) DecodeBackup(ctx context.Context, backup *migrationv1alpha1.BackupManifest, obj any) error {
+	yamlData, err := m.loadBackupData(ctx, backup)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(yamlData, obj); err != nil {
+		return fmt.Errorf("failed to unmarshal backup of %s/%s: %w", backup.ResourceType, backup.Name, err)
+	}
+	return nil
+}
+
+// loadBackupData returns the YAML manifest of a backup, reading it from the status or from
+// the ConfigMap or Secret it was stored in, and checks it against the backup's checksum
+func (m *RestoreManager) loadBackupData(ctx context.Context, backup *migrationv1alpha1.BackupManifest) ([]byte, error) {
+	yamlData, err := m.readBackupData(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+	// Backups taken before checksums were recorded have none to verify
+	if backup.Checksum != "" && manifestChecksum(yamlData) != backup.Checksum {
+		return nil, fmt.Errorf("backup of %s/%s does not match its checksum, it was modified or truncated", backup.ResourceType, backup.Name)
+	}
+	return yamlData, nil
+}
+
+// readBackupData reads the YAML manifest of a backup from the status or from the ConfigMap or
+// Secret it was stored in
+func (m *RestoreManager) readBackupData(ctx context.Context, backup *migrationv1alpha1.BackupManifest) ([]byte, error) {
+	ref := backup.StorageRef
+	if ref == nil {
+		yamlData, err := base64.StdEncoding.DecodeString(backup.BackupData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode backup data: %w", err)
+		}
+		return yamlData, nil
+	}
+
+	key := client.ObjectKey{Name: ref.Name, Namespace: ref.Namespace}
+	switch migrationv1alpha1.BackupStorageType(ref.Kind) {
+	case migrationv1alpha1.BackupStorageConfigMap:
+		cm := &corev1.ConfigMap{}
+		if err := m.client.Get(ctx, key, cm); err != nil {
+			return nil, fmt.Errorf("failed to get backup ConfigMap %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		data, ok := cm.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("backup ConfigMap %s/%s has no key %s", ref.Namespace, ref.Name, ref.Key)
+		}
+		return []byte(data), nil
+	case migrationv1alpha1.BackupStorageSecret:
+		secret := &corev1.Secret{}
+		if err := m.client.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get backup Secret %s/%s: %w", ref.Namespace, ref.Name, err)
+		}
+		data, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("backup Secret %s/%s has no key %s", ref.Namespace, ref.Name, ref.Key)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported backup storage kind %s", ref.Kind)
+	}
+}
+
 // RestoreResourceWithRetry restores a resource with exponential backoff retry
 func (m *RestoreManager) RestoreResourceWithRetry(ctx context.Context, backup *migrationv1alpha1.BackupManifest) error {
 	backoff := wait.Backoff{
@@ -2,8 +2,10 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -12,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -29,6 +32,9 @@ import (
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/state"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/notify"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/report"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 // MigrationController manages vSphere migrations
@@ -42,10 +48,73 @@ type MigrationController struct {
 	backupManager  *backup.BackupManager
 	restoreManager *backup.RestoreManager
 	workqueue      workqueue.RateLimitingInterface
+	workers        int
 	gvr            schema.GroupVersionResource
+
+	// migrationLocks keeps two reconciles of one migration from interleaving their status writes
+	migrationLocks keyedMutex
+
+	// requestedMu guards requested, the keys of migrations a request annotation was set on since
+	// their last reconcile. Their running phase is executed even when it is not due yet.
+	requestedMu sync.Mutex
+	requested   map[string]bool
 }
 
-// NewMigrationController creates a new migration controller
+const (
+	// DefaultWorkers is the default number of migrations reconciled concurrently
+	DefaultWorkers = 1
+
+	// DefaultRateLimiterBaseDelay is the default initial retry delay after a failed reconcile
+	DefaultRateLimiterBaseDelay = 5 * time.Second
+
+	// DefaultRateLimiterMaxDelay is the default cap on the retry delay after repeated failed reconciles
+	DefaultRateLimiterMaxDelay = 5 * time.Minute
+)
+
+// Options tunes how the controller processes its work queue
+type Options struct {
+	// Workers is the number of migrations reconciled concurrently
+	Workers int
+
+	// RateLimiterBaseDelay is the retry delay after the first failed reconcile of a migration.
+	// The delay doubles on every consecutive failure up to RateLimiterMaxDelay.
+	RateLimiterBaseDelay time.Duration
+
+	// RateLimiterMaxDelay caps the retry delay after repeated failed reconciles
+	RateLimiterMaxDelay time.Duration
+
+	// RequeueInterval is how often a running migration is reconciled when its phase asks for no
+	// specific delay. Migrations may override it in their spec.
+	RequeueInterval time.Duration
+
+	// CredentialSource reads target vCenter credentials from a directory or command instead of
+	// the credentials secret of each migration
+	CredentialSource phases.CredentialSource
+
+	// Notifier receives a notification on each significant migration transition. Nil disables
+	// notifications.
+	Notifier notify.Notifier
+
+	// IntegrityChecker checksums volumes of migrations with csiVolumeMigration.verifyIntegrity
+	// before and after they migrate. Nil computes no checksum.
+	IntegrityChecker phases.IntegrityChecker
+
+	// VCenterConnection holds the port, proxy and timeouts used to connect to every vCenter;
+	// its server is ignored. Zero values keep the vsphere package defaults.
+	VCenterConnection vsphere.Config
+}
+
+// DefaultOptions returns the default controller options
+func DefaultOptions() Options {
+	return Options{
+		Workers:              DefaultWorkers,
+		RateLimiterBaseDelay: DefaultRateLimiterBaseDelay,
+		RateLimiterMaxDelay:  DefaultRateLimiterMaxDelay,
+		RequeueInterval:      state.DefaultRequeueInterval,
+	}
+}
+
+// NewMigrationController creates a new migration controller with the default options
 func NewMigrationController(
 	kubeClient kubernetes.Interface,
 	configClient configclient.Interface,
@@ -56,13 +125,42 @@ func NewMigrationController(
 	scheme *runtime.Scheme,
 	recorder events.Recorder,
 ) (*MigrationController, factory.Controller) {
+	return NewMigrationControllerWithOptions(kubeClient, configClient, machineClient, dynamicClient,
+		apiextensionsClient, runtimeClient, scheme, recorder, DefaultOptions())
+}
+
+// NewMigrationControllerWithOptions creates a new migration controller
+func NewMigrationControllerWithOptions(
+	kubeClient kubernetes.Interface,
+	configClient configclient.Interface,
+	machineClient machineclient.Interface,
+	dynamicClient dynamic.Interface,
+	apiextensionsClient apiextensionsclient.Interface,
+	runtimeClient client.Client,
+	scheme *runtime.Scheme,
+	recorder events.Recorder,
+	opts Options,
+) (*MigrationController, factory.Controller) {
+	if opts.Workers < 1 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.RateLimiterBaseDelay <= 0 {
+		opts.RateLimiterBaseDelay = DefaultRateLimiterBaseDelay
+	}
+	if opts.RateLimiterMaxDelay < opts.RateLimiterBaseDelay {
+		opts.RateLimiterMaxDelay = max(DefaultRateLimiterMaxDelay, opts.RateLimiterBaseDelay)
+	}
+
+	// Failed reconciles back off exponentially per migration so a persistent failure cannot hot-loop
+	rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(opts.RateLimiterBaseDelay, opts.RateLimiterMaxDelay)
 
 	c := &MigrationController{
 		kubeClient:    kubeClient,
 		configClient:  configClient,
 		dynamicClient: dynamicClient,
 		scheme:        scheme,
-		workqueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "vmwarecloudfoundationmigrations"),
+		workqueue:     workqueue.NewNamedRateLimitingQueue(rateLimiter, "vmwarecloudfoundationmigrations"),
+		workers:       opts.Workers,
 		gvr: schema.GroupVersionResource{
 			Group:    "migration.openshift.io",
 			Version:  "v1alpha1",
@@ -71,7 +169,7 @@ func NewMigrationController(
 	}
 
 	// Initialize managers
-	c.backupManager = backup.NewBackupManager(scheme)
+	c.backupManager = backup.NewBackupManagerWithClient(scheme, kubeClient)
 	c.restoreManager = backup.NewRestoreManager(runtimeClient, scheme)
 
 	// Initialize phase executor
@@ -85,12 +183,21 @@ func NewMigrationController(
 		c.restoreManager,
 	)
 
+	c.phaseExecutor.SetCredentialSource(opts.CredentialSource)
+	c.phaseExecutor.SetIntegrityChecker(opts.IntegrityChecker)
+	c.phaseExecutor.SetVCenterConnection(opts.VCenterConnection)
+
 	// Initialize state machine
 	c.stateMachine = state.NewStateMachine(c.phaseExecutor)
+	c.stateMachine.SetRequeueInterval(opts.RequeueInterval)
+	c.stateMachine.SetNotifier(opts.Notifier)
 
 	// Create factory controller
+	// The queue workers are started as a post-start hook so they run for the lifetime of the
+	// factory controller and pick up rate-limited retries and requeue-after delays as they expire.
 	factoryController := factory.New().
 		WithSync(c.sync).
+		WithPostStartHooks(c.runWorkers).
 		ResyncEvery(1*time.Minute).
 		ToController("vmware-cloud-foundation-migration", recorder)
 
@@ -111,51 +218,155 @@ func (c *MigrationController) EnqueueMigration(obj interface{}) {
 	logger.Error(fmt.Errorf("unexpected object type"), "Failed to enqueue migration", "obj", obj)
 }
 
-// sync is called by the library-go factory
-func (c *MigrationController) sync(ctx context.Context, controllerContext factory.SyncContext) error {
-	logger := klog.FromContext(ctx)
+// UpdateMigration enqueues an updated migration when its spec, a request annotation or its
+// deletion changed. Status writes do not bump the generation; requeueing on them would bypass
+// the phase requeue delays and the rate limiter. A request annotation being set is acted on
+// straight away, even by a phase that asked to wait.
+func (c *MigrationController) UpdateMigration(oldObj, newObj interface{}) {
+	oldMeta, oldOK := oldObj.(metav1.Object)
+	newMeta, newOK := newObj.(metav1.Object)
+	if !oldOK || !newOK {
+		c.EnqueueMigration(newObj)
+		return
+	}
 
-	// Process all items in the work queue
-	for c.workqueue.Len() > 0 {
-		item, shutdown := c.workqueue.Get()
-		if shutdown {
-			return nil
+	changed, set := requestAnnotationsChanged(oldMeta, newMeta)
+	if oldMeta.GetGeneration() == newMeta.GetGeneration() && !changed &&
+		oldMeta.GetDeletionTimestamp().Equal(newMeta.GetDeletionTimestamp()) {
+		return
+	}
+	if set {
+		c.requestReconcile(fmt.Sprintf("%s/%s", newMeta.GetNamespace(), newMeta.GetName()))
+	}
+	klog.Background().Info("VmwareCloudFoundationMigration updated")
+	c.EnqueueMigration(newObj)
+}
+
+// requestAnnotationsChanged reports whether any request annotation was set, changed or removed,
+// and whether one was set or changed rather than only removed, as the controller does once it
+// acted on a request
+func requestAnnotationsChanged(oldMeta, newMeta metav1.Object) (changed, set bool) {
+	for _, annotation := range migrationv1alpha1.RequestAnnotations {
+		oldValue, oldSet := oldMeta.GetAnnotations()[annotation]
+		newValue, newSet := newMeta.GetAnnotations()[annotation]
+		if oldSet != newSet || oldValue != newValue {
+			changed = true
+			set = set || newSet
 		}
+	}
+	return changed, set
+}
 
-		func() {
-			defer c.workqueue.Done(item)
+// requestReconcile lets the next reconcile of a migration execute its running phase before it is due
+func (c *MigrationController) requestReconcile(key string) {
+	c.requestedMu.Lock()
+	defer c.requestedMu.Unlock()
+	if c.requested == nil {
+		c.requested = make(map[string]bool)
+	}
+	c.requested[key] = true
+}
 
-			key, ok := item.(string)
-			if !ok {
-				c.workqueue.Forget(item)
-				logger.Error(fmt.Errorf("unexpected type in workqueue"), "Expected string", "got", item)
-				return
-			}
+// takeReconcileRequest reports whether a request annotation was set on a migration since its last
+// reconcile, clearing the request
+func (c *MigrationController) takeReconcileRequest(key string) bool {
+	c.requestedMu.Lock()
+	defer c.requestedMu.Unlock()
+	requested := c.requested[key]
+	delete(c.requested, key)
+	return requested
+}
 
-			if err := c.syncMigrationFromKey(ctx, key); err != nil {
-				// Requeue on error
-				c.workqueue.AddRateLimited(key)
-				logger.Error(err, "Failed to sync migration", "key", key)
-				return
-			}
+// QueueLen is a public wrapper for testing
+func (c *MigrationController) QueueLen() int {
+	return c.workqueue.Len()
+}
+
+// sync is called by the library-go factory on every resync.
+// Work items are processed by the queue workers, so this only reports the queue depth.
+func (c *MigrationController) sync(ctx context.Context, controllerContext factory.SyncContext) error {
+	logger := klog.FromContext(ctx)
+	logger.V(4).Info("Periodic resync", "queueDepth", c.workqueue.Len())
+	return nil
+}
 
-			c.workqueue.Forget(item)
-			logger.V(4).Info("Successfully synced migration", "key", key)
+// runWorkers processes the work queue with the configured number of workers until ctx is cancelled
+func (c *MigrationController) runWorkers(ctx context.Context, _ factory.SyncContext) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Starting migration workers", "workers", c.workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c.processNextWorkItem(ctx) {
+			}
 		}()
 	}
 
+	<-ctx.Done()
+	c.workqueue.ShutDown()
+	wg.Wait()
+	logger.Info("Migration workers stopped")
 	return nil
 }
 
-// syncMigrationFromKey fetches a migration by key and syncs it
-func (c *MigrationController) syncMigrationFromKey(ctx context.Context, key string) error {
+// ProcessNextWorkItem is a public wrapper for testing
+func (c *MigrationController) ProcessNextWorkItem(ctx context.Context) bool {
+	return c.processNextWorkItem(ctx)
+}
+
+// processNextWorkItem reconciles the next migration in the work queue.
+// Failed reconciles are retried through the rate limiter; successful ones are requeued
+// after the delay requested by the current phase, which also resets the failure backoff.
+// Returns false when the queue has been shut down.
+func (c *MigrationController) processNextWorkItem(ctx context.Context) bool {
+	logger := klog.FromContext(ctx)
+
+	item, shutdown := c.workqueue.Get()
+	if shutdown {
+		return false
+	}
+	defer c.workqueue.Done(item)
+
+	key, ok := item.(string)
+	if !ok {
+		c.workqueue.Forget(item)
+		logger.Error(fmt.Errorf("unexpected type in workqueue"), "Expected string", "got", item)
+		return true
+	}
+
+	requeueAfter, err := c.syncMigrationFromKey(ctx, key)
+	if err != nil {
+		// Requeue on error with exponential backoff
+		c.workqueue.AddRateLimited(key)
+		logger.Error(err, "Failed to sync migration", "key", key,
+			"retries", c.workqueue.NumRequeues(key))
+		return true
+	}
+
+	c.workqueue.Forget(item)
+	if requeueAfter > 0 {
+		c.workqueue.AddAfter(key, requeueAfter)
+	}
+	logger.V(4).Info("Successfully synced migration", "key", key, "requeueAfter", requeueAfter)
+	return true
+}
+
+// syncMigrationFromKey fetches a migration by key and syncs it.
+// Returns how long to wait before reconciling the migration again.
+func (c *MigrationController) syncMigrationFromKey(ctx context.Context, key string) (time.Duration, error) {
 	logger := klog.FromContext(ctx).WithValues("key", key)
 	ctx = klog.NewContext(ctx, logger)
 
+	unlock := c.migrationLocks.Lock(key)
+	defer unlock()
+
 	// Parse the key
 	namespace, name, err := migrationFromQueueKey(key)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	logger.Info("Syncing VmwareCloudFoundationMigration", "namespace", namespace, "name", name)
@@ -163,27 +374,102 @@ func (c *MigrationController) syncMigrationFromKey(ctx context.Context, key stri
 	// Fetch the migration resource using dynamic client
 	unstructuredMigration, err := c.dynamicClient.Resource(c.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get VmwareCloudFoundationMigration: %w", err)
+		if apierrors.IsNotFound(err) {
+			logger.Info("VmwareCloudFoundationMigration no longer exists, dropping from queue")
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get VmwareCloudFoundationMigration: %w", err)
 	}
 
 	// Convert unstructured to typed object
 	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstructuredMigration.Object, migration); err != nil {
-		return fmt.Errorf("failed to convert unstructured to VmwareCloudFoundationMigration: %w", err)
+		return 0, fmt.Errorf("failed to convert unstructured to VmwareCloudFoundationMigration: %w", err)
+	}
+
+	// A deleted migration is cleaned up and released; a live one is held by the finalizer
+	if migration.DeletionTimestamp != nil {
+		return 0, c.finalizeMigration(ctx, unstructuredMigration, migration)
+	}
+	if err := c.ensureFinalizer(ctx, unstructuredMigration); err != nil {
+		return 0, err
+	}
+	if err := c.handleRetryRequest(ctx, unstructuredMigration, migration); err != nil {
+		return 0, err
+	}
+	migration.Finalizers = unstructuredMigration.GetFinalizers()
+	migration.Annotations = unstructuredMigration.GetAnnotations()
+	migration.ResourceVersion = unstructuredMigration.GetResourceVersion()
+
+	// Nothing changed since the running phase asked to wait, so it is not executed early, unless
+	// an operator has since set a request annotation it may be waiting for
+	requested := c.takeReconcileRequest(key)
+	if remaining, waiting := waitingForRequeue(migration); waiting && !requested {
+		logger.V(2).Info("Phase is not due yet and the spec is unchanged, skipping reconcile",
+			"phase", migration.Status.Phase,
+			"generation", migration.Generation,
+			"requeueAfter", remaining)
+		return remaining, nil
+	}
+
+	// Only one migration may change the cluster at a time; a blocked one waits for the active one
+	blocked, err := c.checkMutualExclusion(ctx, migration)
+	if err != nil {
+		return 0, err
+	}
+	if blocked {
+		if err := c.updateMigrationStatus(ctx, migration); err != nil {
+			return 0, err
+		}
+		return c.stateMachine.RequeueInterval(migration), nil
 	}
 
 	// Sync the migration
-	if err := c.syncMigration(ctx, migration); err != nil {
-		return err
+	specState := migration.Spec.State
+	requeueAfter, err := c.syncMigration(ctx, migration)
+	if err != nil {
+		// Persist the failure so it survives restarts and can be retried or rolled back
+		if statusErr := c.updateMigrationStatus(ctx, migration); statusErr != nil {
+			logger.Error(statusErr, "Failed to record failed reconcile in status")
+		}
+		return 0, err
+	}
+
+	// Workloads left scaled down by failed volumes are escalated once they exceed the threshold,
+	// also after the migration has finished
+	if alertAfter := c.checkScaledDownWorkloads(ctx, migration); alertAfter > 0 && (requeueAfter == 0 || alertAfter < requeueAfter) {
+		requeueAfter = alertAfter
+	}
+
+	// The state is written before the status, so a failure cannot leave the next phase running
+	// where the migration paused itself
+	if migration.Spec.State != specState {
+		if err := c.updateMigrationState(ctx, migration); err != nil {
+			return 0, err
+		}
 	}
 
 	// Update the status
-	return c.updateMigrationStatus(ctx, migration)
+	if err := c.updateMigrationStatus(ctx, migration); err != nil {
+		return 0, err
+	}
+
+	// A finished migration leaves a report behind; failing to write it does not fail the reconcile
+	if report.IsTerminal(migration.Status.Phase) {
+		if err := c.writeMigrationReport(ctx, migration); err != nil {
+			logger.Error(err, "Failed to write migration report")
+		}
+	}
+
+	return requeueAfter, nil
 }
 
 // SyncMigration is a public wrapper for testing
 func (c *MigrationController) SyncMigration(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
-	return c.syncMigration(ctx, migration)
+	unlock := c.migrationLocks.Lock(migrationQueueKey(migration))
+	defer unlock()
+	_, err := c.syncMigration(ctx, migration)
+	return err
 }
 
 // migrationQueueKey generates a queue key for a migration
@@ -200,8 +486,15 @@ func migrationFromQueueKey(key string) (namespace, name string, err error) {
 	return namespace, name, nil
 }
 
+// UpdateMigrationStatus is a public wrapper for testing
+func (c *MigrationController) UpdateMigrationStatus(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	return c.updateMigrationStatus(ctx, migration)
+}
+
 // updateMigrationStatus updates the status of a migration resource with retry logic
 // to handle transient API failures during control plane rollouts.
+// Conflicts are resolved by re-reading the latest object and re-applying the computed
+// status on top of it, so phase progress is not lost when the object changed underneath us.
 func (c *MigrationController) updateMigrationStatus(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
 
@@ -216,24 +509,92 @@ func (c *MigrationController) updateMigrationStatus(ctx context.Context, migrati
 	}
 
 	return retry.OnError(backoff, isRetryableAPIError, func() error {
-		// Convert typed object to unstructured
-		unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
-		if err != nil {
-			return fmt.Errorf("failed to convert to unstructured: %w", err)
-		}
+		refresh := false
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			// After a conflict, pick up the latest resourceVersion before re-applying our status
+			if refresh {
+				latest, err := c.dynamicClient.Resource(c.gvr).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+				if err != nil {
+					return fmt.Errorf("failed to re-read migration after conflict: %w", err)
+				}
+				migration.ResourceVersion = latest.GetResourceVersion()
+			}
+			refresh = true
 
-		unstructuredMigration := &unstructured.Unstructured{Object: unstructuredObj}
+			// Convert typed object to unstructured
+			unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+			if err != nil {
+				return fmt.Errorf("failed to convert to unstructured: %w", err)
+			}
 
-		// Update the status subresource
-		_, err = c.dynamicClient.Resource(c.gvr).Namespace(migration.Namespace).UpdateStatus(ctx, unstructuredMigration, metav1.UpdateOptions{})
-		if err != nil {
-			logger.V(4).Info("Status update attempt failed, may retry", "error", err)
-			return fmt.Errorf("failed to update migration status: %w", err)
-		}
+			unstructuredMigration := &unstructured.Unstructured{Object: unstructuredObj}
+
+			// Update the status subresource
+			updated, err := c.dynamicClient.Resource(c.gvr).Namespace(migration.Namespace).UpdateStatus(ctx, unstructuredMigration, metav1.UpdateOptions{})
+			if err != nil {
+				if apierrors.IsConflict(err) {
+					logger.V(2).Info("Status update conflicted, re-reading migration and retrying", "resourceVersion", migration.ResourceVersion)
+					return err
+				}
+				logger.V(4).Info("Status update attempt failed, may retry", "error", err)
+				return fmt.Errorf("failed to update migration status: %w", err)
+			}
+
+			migration.ResourceVersion = updated.GetResourceVersion()
+			logger.Info("Updated migration status", "namespace", migration.Namespace, "name", migration.Name, "phase", migration.Status.Phase)
+			return nil
+		})
+	})
+}
+
+// UpdateMigrationState is a public wrapper for testing
+func (c *MigrationController) UpdateMigrationState(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	return c.updateMigrationState(ctx, migration)
+}
+
+// updateMigrationState writes spec.state, which the controller changes itself only to pause a
+// migration for review. The write is conditional on the resourceVersion the migration was read
+// at, so a state set by a user in the meantime, such as Rollback or Cancelled, is never
+// overwritten; the conflict fails the reconcile and the migration is reconciled again.
+func (c *MigrationController) updateMigrationState(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"resourceVersion": migration.ResourceVersion},
+		"spec":     map[string]interface{}{"state": migration.Spec.State},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build state patch: %w", err)
+	}
+
+	updated, err := c.dynamicClient.Resource(c.gvr).Namespace(migration.Namespace).Patch(ctx, migration.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to set migration state to %s: %w", migration.Spec.State, err)
+	}
+
+	migration.ResourceVersion = updated.GetResourceVersion()
+	klog.FromContext(ctx).Info("Updated migration state", "namespace", migration.Namespace, "name", migration.Name, "state", migration.Spec.State)
+	return nil
+}
 
-		logger.Info("Updated migration status", "namespace", migration.Namespace, "name", migration.Name, "phase", migration.Status.Phase)
-		return nil
+// removeMigrationAnnotation removes an annotation of a migration once the request it carries has
+// been acted on
+func (c *MigrationController) removeMigrationAnnotation(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, annotation string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{annotation: nil},
+		},
 	})
+	if err != nil {
+		return fmt.Errorf("failed to build annotation patch: %w", err)
+	}
+
+	updated, err := c.dynamicClient.Resource(c.gvr).Namespace(migration.Namespace).Patch(ctx, migration.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to remove %s annotation: %w", annotation, err)
+	}
+
+	migration.Annotations = updated.GetAnnotations()
+	migration.ResourceVersion = updated.GetResourceVersion()
+	return nil
 }
 
 // isRetryableAPIError determines if an API error should be retried.
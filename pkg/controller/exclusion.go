@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+)
+
+// IsActiveMigration reports whether a migration is running or rolling back, and so may change the
+// cluster-wide Infrastructure and ControlPlaneMachineSet. A running migration that failed stays
+// active, since it has left the cluster part-way migrated until it is retried or rolled back.
+func IsActiveMigration(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	if migration.DeletionTimestamp != nil {
+		return false
+	}
+	switch migration.Spec.State {
+	case migrationv1alpha1.MigrationStateRunning:
+		return migration.Status.Phase != migrationv1alpha1.PhaseCompleted &&
+			migration.Status.Phase != migrationv1alpha1.PhaseRollbackCompleted &&
+			migration.Status.Phase != migrationv1alpha1.PhaseCancelled
+	case migrationv1alpha1.MigrationStateRollback:
+		return migration.Status.Phase != migrationv1alpha1.PhaseRollbackCompleted
+	default:
+		return false
+	}
+}
+
+// holdsCluster reports whether an active migration has started changing the cluster and is not
+// itself waiting for another migration
+func holdsCluster(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	if !IsActiveMigration(migration) || migration.Status.BlockedBy != "" {
+		return false
+	}
+	return migration.Status.CurrentPhaseState != nil || len(migration.Status.PhaseHistory) > 0 ||
+		migration.Status.Phase == migrationv1alpha1.PhaseRollingBack
+}
+
+// BlockingMigration returns the namespace/name of the migration an active migration must wait
+// for, or an empty string when it may proceed. A migration that has started changing the cluster
+// blocks every other one, the oldest winning if two have, such as after one was paused while the
+// other ran. Otherwise the oldest of the active migrations waiting to start goes first, so two
+// migrations started together cannot both proceed or both wait.
+func BlockingMigration(migration *migrationv1alpha1.VmwareCloudFoundationMigration, migrations []migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	if !IsActiveMigration(migration) {
+		return ""
+	}
+
+	key := migrationQueueKey(migration)
+	holds := holdsCluster(migration)
+	first := migration
+	for i := range migrations {
+		other := &migrations[i]
+		if migrationQueueKey(other) == key || !IsActiveMigration(other) {
+			continue
+		}
+		if holdsCluster(other) && (!holds || startsBefore(other, migration)) {
+			return migrationQueueKey(other)
+		}
+		if startsBefore(other, first) {
+			first = other
+		}
+	}
+	if first != migration && !holds {
+		return migrationQueueKey(first)
+	}
+	return ""
+}
+
+// startsBefore orders migrations by creation time, then by namespace/name
+func startsBefore(a, b *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return strings.Compare(migrationQueueKey(a), migrationQueueKey(b)) < 0
+}
+
+// checkMutualExclusion lists every migration in the cluster and marks the given one blocked when
+// another migration is active. It reports whether the migration is blocked.
+func (c *MigrationController) checkMutualExclusion(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (bool, error) {
+	logger := klog.FromContext(ctx)
+
+	var blocker string
+	if IsActiveMigration(migration) {
+		list, err := c.dynamicClient.Resource(c.gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to list VmwareCloudFoundationMigrations: %w", err)
+		}
+		migrations := make([]migrationv1alpha1.VmwareCloudFoundationMigration, 0, len(list.Items))
+		for _, item := range list.Items {
+			other := migrationv1alpha1.VmwareCloudFoundationMigration{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &other); err != nil {
+				return false, fmt.Errorf("failed to convert VmwareCloudFoundationMigration %s/%s: %w", item.GetNamespace(), item.GetName(), err)
+			}
+			migrations = append(migrations, other)
+		}
+		blocker = BlockingMigration(migration, migrations)
+	}
+
+	if blocker == "" {
+		if migration.Status.BlockedBy != "" {
+			logger.Info("Migration is no longer blocked", "blockedBy", migration.Status.BlockedBy)
+			migration.Status.BlockedBy = ""
+			// The blocked phase never started, so it starts afresh
+			if state := migration.Status.CurrentPhaseState; state != nil && state.Status == migrationv1alpha1.PhaseStatusBlocked {
+				state.Status = migrationv1alpha1.PhaseStatusPending
+				state.Message = ""
+			}
+		}
+		return false, nil
+	}
+
+	message := fmt.Sprintf("Blocked by active migration %s - only one migration may run or roll back at a time", blocker)
+	logger.Info("Migration is blocked by another active migration", "blockedBy", blocker)
+	migration.Status.BlockedBy = blocker
+
+	// A phase that has already started keeps its state so it is resumed once unblocked
+	phase := migration.Status.Phase
+	if phase == migrationv1alpha1.PhaseNone {
+		phase = migrationv1alpha1.PhasePreflight
+	}
+	state := migration.Status.CurrentPhaseState
+	if state == nil || state.Name != phase {
+		migration.Status.CurrentPhaseState = &migrationv1alpha1.PhaseState{
+			Name:    phase,
+			Status:  migrationv1alpha1.PhaseStatusBlocked,
+			Message: message,
+		}
+	} else if slices.Contains([]migrationv1alpha1.PhaseStatus{migrationv1alpha1.PhaseStatusPending, migrationv1alpha1.PhaseStatusBlocked}, state.Status) {
+		state.Status = migrationv1alpha1.PhaseStatusBlocked
+		state.Message = message
+	}
+	util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
+		migrationv1alpha1.ReasonBlocked, message)
+	return true, nil
+}
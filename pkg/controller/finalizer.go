@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+)
+
+// ensureFinalizer adds the migration finalizer if it is missing, updating obj in place
+func (c *MigrationController) ensureFinalizer(ctx context.Context, obj *unstructured.Unstructured) error {
+	finalizers := obj.GetFinalizers()
+	if slices.Contains(finalizers, migrationv1alpha1.MigrationFinalizer) {
+		return nil
+	}
+
+	obj.SetFinalizers(append(finalizers, migrationv1alpha1.MigrationFinalizer))
+	updated, err := c.dynamicClient.Resource(c.gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to add finalizer: %w", err)
+	}
+	obj.SetResourceVersion(updated.GetResourceVersion())
+
+	klog.FromContext(ctx).V(2).Info("Added migration finalizer")
+	return nil
+}
+
+// FinalizerCleanupTimeout is how long after its deletion a migration whose cleanup keeps failing,
+// such as when the vCenter is unreachable or its credentials are gone, is held by the finalizer
+const FinalizerCleanupTimeout = 30 * time.Minute
+
+// finalizeMigration cleans up after a migration deleted mid-run and then removes the finalizer.
+// Dummy VMs are deleted and scaled-down workloads restored; volumes left part-way cannot be
+// rolled back automatically and are reported instead. A failed cleanup keeps the finalizer so
+// the deletion is retried, for up to FinalizerCleanupTimeout or until SkipCleanupAnnotation is
+// set; the migration is then released and what was left behind recorded in an event.
+func (c *MigrationController) finalizeMigration(ctx context.Context, obj *unstructured.Unstructured, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	logger := klog.FromContext(ctx)
+
+	finalizers := obj.GetFinalizers()
+	if !slices.Contains(finalizers, migrationv1alpha1.MigrationFinalizer) {
+		return nil
+	}
+
+	var abandoned []string
+	var skipped string
+	if migration.Annotations[migrationv1alpha1.SkipCleanupAnnotation] == "true" {
+		skipped = "skipped by annotation " + migrationv1alpha1.SkipCleanupAnnotation
+	} else {
+		logger.Info("Migration is being deleted, cleaning up", "phase", migration.Status.Phase)
+
+		var err error
+		csiPhase := phases.NewMigrateCSIVolumesPhase(c.phaseExecutor)
+		if abandoned, err = csiPhase.CleanupOnDeletion(ctx, migration); err != nil {
+			if time.Since(migration.DeletionTimestamp.Time) < FinalizerCleanupTimeout {
+				return fmt.Errorf("failed to clean up deleted migration: %w", err)
+			}
+			skipped = fmt.Sprintf("still failing %s after deletion: %v", FinalizerCleanupTimeout, err)
+		}
+	}
+	for _, volume := range abandoned {
+		logger.Info("Volume left part-way through migration, manual recovery may be needed", "volume", volume)
+	}
+
+	if skipped != "" {
+		left := phases.LeftOnDeletion(migration)
+		message := "Released the deleted migration without cleaning up, " + skipped
+		if len(left) > 0 {
+			message += "; check and recover by hand: " + strings.Join(left, "; ")
+		}
+		logger.Info(message)
+		if err := c.recordWarningEvent(ctx, migration, migrationv1alpha1.ReasonCleanupSkipped, message); err != nil {
+			logger.Error(err, "Failed to record what the deleted migration left behind")
+		}
+	}
+
+	obj.SetFinalizers(slices.DeleteFunc(finalizers, func(f string) bool {
+		return f == migrationv1alpha1.MigrationFinalizer
+	}))
+	if _, err := c.dynamicClient.Resource(c.gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+
+	logger.Info("Removed migration finalizer", "abandonedVolumes", len(abandoned))
+	return nil
+}
@@ -0,0 +1,43 @@
+package controller
+
+import "sync"
+
+// keyedMutex serializes work on each migration, keyed by namespace/name, while letting different
+// migrations proceed in parallel. The work queue never hands one key to two workers at once; the
+// lock also covers work done outside the queue, such as the startup dummy VM cleanup.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*refCountedMutex
+}
+
+// refCountedMutex is a mutex that is dropped from its keyedMutex once nobody holds or waits for it
+type refCountedMutex struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock locks the mutex of key and returns the function unlocking it
+func (k *keyedMutex) Lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*refCountedMutex)
+	}
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &refCountedMutex{}
+		k.locks[key] = lock
+	}
+	lock.refs++
+	k.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		k.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -2,12 +2,24 @@ package phases
 
 import (
 	"context"
+	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+const (
+	// MachineSetBackupType is the backup resource type of the MachineSets backed up before migration
+	MachineSetBackupType = "MachineSet"
+
+	// CPMSBackupType is the backup resource type of the ControlPlaneMachineSet backed up before migration
+	CPMSBackupType = "ControlPlaneMachineSet"
 )
 
 // BackupPhase backs up critical resources
@@ -56,7 +68,13 @@ func (p *BackupPhase) Execute(ctx context.Context, migration *migrationv1alpha1.
 			Logs:    logs,
 		}, err
 	}
-	p.executor.backupManager.AddBackupToMigration(migration, infraBackup)
+	if err := p.executor.backupManager.StoreBackup(ctx, migration, infraBackup); err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to store infrastructure backup: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
 
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Backed up Infrastructure CRD", string(p.Name()))
 
@@ -78,7 +96,13 @@ func (p *BackupPhase) Execute(ctx context.Context, migration *migrationv1alpha1.
 			Logs:    logs,
 		}, err
 	}
-	p.executor.backupManager.AddBackupToMigration(migration, secretBackup)
+	if err := p.executor.backupManager.StoreBackup(ctx, migration, secretBackup); err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to store secret backup: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
 
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Backed up vsphere-creds secret", string(p.Name()))
 
@@ -100,14 +124,148 @@ func (p *BackupPhase) Execute(ctx context.Context, migration *migrationv1alpha1.
 			Logs:    logs,
 		}, err
 	}
-	p.executor.backupManager.AddBackupToMigration(migration, cmBackup)
+	if err := p.executor.backupManager.StoreBackup(ctx, migration, cmBackup); err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to store ConfigMap backup: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
 
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Backed up cloud-provider-config", string(p.Name()))
 
-	// Note: CPMS is not backed up as it will be recreated with new configuration
-	// after infrastructure update. The new CPMS will use the updated failure domains.
+	// Backup the vSphere CSI driver config and credentials, which Cleanup rewrites. Clusters
+	// without the CSI driver have neither.
+	for _, name := range []string{openshift.CSIDriverConfigSecretName, openshift.CSIDriverCredentialsSecretName} {
+		csiSecret, err := p.executor.kubeClient.CoreV1().Secrets(openshift.CSIDriverNamespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Secret %s/%s not found, not backing it up", openshift.CSIDriverNamespace, name), string(p.Name()))
+			continue
+		}
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Failed to get secret %s: %v", name, err),
+				Logs:    logs,
+			}, err
+		}
 
-	// TODO: Backup machines
+		csiBackup, err := p.executor.backupManager.BackupResource(ctx, client.Object(csiSecret), "Secret")
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Failed to backup secret %s: %v", name, err),
+				Logs:    logs,
+			}, err
+		}
+		if err := p.executor.backupManager.StoreBackup(ctx, migration, csiBackup); err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Failed to store secret %s backup: %v", name, err),
+				Logs:    logs,
+			}, err
+		}
+
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Backed up "+name+" secret", string(p.Name()))
+	}
+
+	// Backup every MachineSet, including its providerSpec, so the machine phases can roll back
+	// to the exact pre-migration state
+	machineManager := p.executor.GetMachineManager()
+	machineSets, err := machineManager.BackupAllMachineSets(ctx)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to list MachineSets: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	for i := range machineSets {
+		ms := &machineSets[i]
+		msBackup, err := p.executor.backupManager.BackupResource(ctx, client.Object(ms), MachineSetBackupType)
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Failed to backup MachineSet %s: %v", ms.Name, err),
+				Logs:    logs,
+			}, err
+		}
+		if err := p.executor.backupManager.StoreBackup(ctx, migration, msBackup); err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Failed to store MachineSet %s backup: %v", ms.Name, err),
+				Logs:    logs,
+			}, err
+		}
+	}
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Backed up %d MachineSets", len(machineSets)), string(p.Name()))
+
+	// Backup the CPMS, which RecreateCPMS replaces with one for the target failure domains.
+	// Clusters without a CPMS have nothing to back up.
+	cpms, err := machineManager.GetControlPlaneMachineSet(ctx)
+	switch {
+	case apierrors.IsNotFound(err):
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "ControlPlaneMachineSet not found, not backing it up", string(p.Name()))
+	case err != nil:
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to get ControlPlaneMachineSet: " + err.Error(),
+			Logs:    logs,
+		}, err
+	default:
+		cpmsBackup, err := p.executor.backupManager.BackupResource(ctx, client.Object(cpms), CPMSBackupType)
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: "Failed to backup ControlPlaneMachineSet: " + err.Error(),
+				Logs:    logs,
+			}, err
+		}
+		if err := p.executor.backupManager.StoreBackup(ctx, migration, cpmsBackup); err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: "Failed to store ControlPlaneMachineSet backup: " + err.Error(),
+				Logs:    logs,
+			}, err
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Backed up ControlPlaneMachineSet", string(p.Name()))
+	}
+
+	// Record the vcenters validations UpdateInfrastructure removes from the Infrastructure CRD,
+	// so they can be restored if the controller stops before putting them back
+	validations, err := p.executor.infraManager.VCenterValidations(ctx)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to record Infrastructure CRD validations: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	migration.Status.InfrastructureCRDValidations = validations
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Recorded vcenters validations of %d Infrastructure CRD version(s)", len(validations)), string(p.Name()))
+
+	// Record the replica count of the CVO, which DisableCVO scales down, so re-enabling it
+	// restores the exact count
+	cvo, err := p.executor.kubeClient.AppsV1().Deployments(CVONamespace).Get(ctx, CVOName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "CVO deployment not found, not recording its replicas", string(p.Name()))
+	case err != nil:
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to get CVO deployment: " + err.Error(),
+			Logs:    logs,
+		}, err
+	default:
+		recordCVOReplicas(migration, cvo)
+		if migration.Status.CVOReplicas != nil {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Recorded CVO replicas: %d", *migration.Status.CVOReplicas), string(p.Name()))
+		}
+	}
 
 	logger.Info("Successfully backed up all critical resources")
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Successfully backed up all critical resources", string(p.Name()))
@@ -125,3 +283,34 @@ func (p *BackupPhase) Rollback(ctx context.Context, migration *migrationv1alpha1
 	// Backup phase doesn't modify resources, no rollback needed
 	return nil
 }
+
+// RestoreInfrastructureCRDValidations restores the vcenters validations of the Infrastructure CRD
+// recorded by the Backup phase of a migration if the CRD has lost them, such as when the
+// controller stopped while UpdateInfrastructure had them removed. It returns the CRD versions
+// restored.
+func (e *PhaseExecutor) RestoreInfrastructureCRDValidations(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) ([]string, error) {
+	if len(migration.Status.InfrastructureCRDValidations) == 0 {
+		return nil, nil
+	}
+	return e.infraManager.RestoreVCenterValidations(ctx, migration.Status.InfrastructureCRDValidations)
+}
+
+// machineSetBackups decodes the MachineSets backed up by the Backup phase, keyed by name
+func (e *PhaseExecutor) machineSetBackups(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (map[string]*machinev1beta1.MachineSet, error) {
+	machineSets := make(map[string]*machinev1beta1.MachineSet)
+	for i := range migration.Status.BackupManifests {
+		backup := &migration.Status.BackupManifests[i]
+		if backup.ResourceType != MachineSetBackupType {
+			continue
+		}
+		if e.restoreManager == nil {
+			return nil, fmt.Errorf("restore manager not initialized")
+		}
+		ms := &machinev1beta1.MachineSet{}
+		if err := e.restoreManager.DecodeBackup(ctx, backup, ms); err != nil {
+			return nil, err
+		}
+		machineSets[ms.Name] = ms
+	}
+	return machineSets, nil
+}
@@ -0,0 +1,172 @@
+package phases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+// untouchedVolumeStatuses are the volume states whose PVC and PV still point at the source FCD,
+// so their workloads can be scaled back up as they were
+var untouchedVolumeStatuses = []string{PVStatusPending, PVStatusRetainSet, PVStatusQuiesced}
+
+// CleanupCancelledMigration undoes what is safe to undo when a migration is cancelled, recording
+// the outcome in cancellation. Volumes that were not touched yet get their reclaim policy and
+// workloads back and are marked Skipped; part-way migrated volumes, and workloads they share, are
+// left as they are for an operator. Leftover dummy VMs that hold no customer disks are deleted,
+// and new worker MachineSets created with surge replicas are settled back to their desired size.
+// Nothing is moved back to the source.
+func (e *PhaseExecutor) CleanupCancelledMigration(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, cancellation *migrationv1alpha1.CancellationStatus) {
+	logger := klog.FromContext(ctx)
+
+	// The old machines stay, so the surge machines that were to cover their scale down go
+	settled, _, err := e.settleWorkerSurge(ctx, migration)
+	cancellation.SettledMachineSets = settled
+	if err != nil {
+		logger.Error(err, "Failed to settle surge worker machines of cancelled migration")
+		cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+			fmt.Sprintf("surge worker machines could not all be removed: %v", err))
+	}
+
+	status := migration.Status.CSIVolumeMigration
+	if status == nil {
+		return
+	}
+
+	// A workload used by a part-way migrated volume stays scaled down for all of its volumes
+	heldWorkloads := make(map[string]string)
+	for _, pvState := range status.Volumes {
+		if cancelLeavesVolume(pvState) {
+			for _, resource := range pvState.ScaledDownResources {
+				heldWorkloads[scaledResourceName(resource)] = pvState.PVName
+			}
+		}
+	}
+
+	pvManager := openshift.NewPersistentVolumeManager(e.kubeClient)
+	workloadManager := openshift.NewWorkloadManager(e.kubeClient)
+	restored := make(map[string]bool)
+
+	for i := range status.Volumes {
+		pvState := &status.Volumes[i]
+
+		if cancelLeavesVolume(*pvState) {
+			item := fmt.Sprintf("volume %s was left %s", pvState.PVName, strings.ToLower(pvState.Status))
+			if pvState.Message != "" {
+				item += ": " + pvState.Message
+			}
+			if len(pvState.ScaledDownResources) > 0 {
+				item += fmt.Sprintf("; workloads %s stay scaled down", scaledDownResourceNames(pvState.ScaledDownResources))
+			}
+			cancellation.ManualIntervention = append(cancellation.ManualIntervention, item)
+			continue
+		}
+		if !slices.Contains(untouchedVolumeStatuses, pvState.Status) {
+			continue
+		}
+
+		if held := heldWorkloadsOf(pvState.ScaledDownResources, heldWorkloads); len(held) > 0 {
+			cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+				fmt.Sprintf("volume %s was not migrated but its workloads %s stay scaled down, they also use part-way migrated volumes",
+					pvState.PVName, strings.Join(held, ", ")))
+			continue
+		}
+
+		if pvState.OriginalReclaimPolicy != "" {
+			if _, err := pvManager.UpdatePVReclaimPolicy(ctx, pvState.PVName, corev1.PersistentVolumeReclaimPolicy(pvState.OriginalReclaimPolicy)); err != nil {
+				logger.Error(err, "Failed to restore PV reclaim policy", "pv", pvState.PVName)
+				cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+					fmt.Sprintf("volume %s: failed to restore reclaim policy %s: %v", pvState.PVName, pvState.OriginalReclaimPolicy, err))
+				continue
+			}
+		}
+
+		if len(pvState.ScaledDownResources) > 0 {
+			if err := workloadManager.RestoreWorkloads(ctx, pvState.ScaledDownResources); err != nil {
+				logger.Error(err, "Failed to restore workloads of cancelled volume", "pv", pvState.PVName)
+				cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+					fmt.Sprintf("volume %s: failed to restore workloads %s: %v",
+						pvState.PVName, scaledDownResourceNames(pvState.ScaledDownResources), err))
+				continue
+			}
+			for _, resource := range pvState.ScaledDownResources {
+				restored[scaledResourceName(resource)] = true
+			}
+		}
+
+		logger.Info("Left volume on the source, migration cancelled before it was moved", "pv", pvState.PVName)
+		pvState.Status = PVStatusSkipped
+		pvState.Message = "Migration cancelled before the volume was moved"
+		pvState.ScaledDownResources = nil
+		status.SkippedVolumes++
+	}
+
+	for name := range restored {
+		cancellation.RestoredWorkloads = append(cancellation.RestoredWorkloads, name)
+	}
+	sort.Strings(cancellation.RestoredWorkloads)
+
+	// Only dummy VMs without customer disks and not used by a part-way migrated volume go
+	cleanup, err := e.CleanupLeftoverDummyVMs(ctx, migration)
+	if cleanup != nil {
+		cancellation.DeletedDummyVMs = cleanup.Deleted
+		names := make([]string, 0, len(cleanup.Retained))
+		for name := range cleanup.Retained {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+				fmt.Sprintf("dummy VM %s was kept: %s", name, cleanup.Retained[name]))
+		}
+	}
+	if err != nil {
+		logger.Error(err, "Failed to clean up leftover dummy VMs of cancelled migration")
+		cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+			fmt.Sprintf("leftover dummy VMs could not all be cleaned up: %v", err))
+	}
+}
+
+// cancelLeavesVolume reports whether cancelling leaves a volume as it is: one whose PVC was
+// deleted or whose FCD may have moved, or that failed part-way
+func cancelLeavesVolume(pvState migrationv1alpha1.PVMigrationState) bool {
+	switch pvState.Status {
+	case PVStatusPVCDeleted, PVStatusRelocating, PVStatusRelocated, PVStatusRegistered, PVStatusPVUpdated, PVStatusFailed:
+		return true
+	}
+	return false
+}
+
+// heldWorkloadsOf returns the resources that a part-way migrated volume also scaled down
+func heldWorkloadsOf(resources []migrationv1alpha1.ScaledResource, heldWorkloads map[string]string) []string {
+	var held []string
+	for _, resource := range resources {
+		name := scaledResourceName(resource)
+		if _, ok := heldWorkloads[name]; ok {
+			held = append(held, name)
+		}
+	}
+	return held
+}
+
+// scaledResourceName names a scaled resource as <Kind>/<namespace>/<name>
+func scaledResourceName(resource migrationv1alpha1.ScaledResource) string {
+	return fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Namespace, resource.Name)
+}
+
+// scaledDownResourceNames lists scaled resources as <Kind>/<namespace>/<name>
+func scaledDownResourceNames(resources []migrationv1alpha1.ScaledResource) string {
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, scaledResourceName(resource))
+	}
+	return strings.Join(names, ", ")
+}
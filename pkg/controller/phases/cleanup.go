@@ -2,13 +2,20 @@ package phases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/metadata"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 // CleanupPhase removes source vCenter configuration
@@ -48,7 +55,7 @@ func (p *CleanupPhase) Execute(ctx context.Context, migration *migrationv1alpha1
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Cleaning up source vCenter configuration", string(p.Name()))
 
 	// Get source vCenter from Infrastructure CRD
-	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx)
+	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -57,24 +64,140 @@ func (p *CleanupPhase) Execute(ctx context.Context, migration *migrationv1alpha1
 		}, err
 	}
 
+	// A source vCenter that also hosts a target failure domain is still in use after an
+	// intra-vCenter migration, so its configuration and credentials are kept
+	keepSourceFD := targetFailureDomainOn(migration, sourceVC.Server)
+
+	// Removing the source vCenter is irreversible for volumes still on it, so refuse while any remain
+	if keepSourceFD == "" {
+		var result *PhaseResult
+		if logs, result, err = p.checkSourceVolumes(ctx, migration, sourceVC.Server, logs); result != nil {
+			return result, err
+		}
+	}
+
+	// Source FCDs kept by Clone mode are deleted once their retention expires, which needs the
+	// source vCenter credentials, so the phase waits for them before removing the source
+	logs, retainedUntil := p.cleanupRetainedSourceVolumes(ctx, migration, logs)
+	if !retainedUntil.IsZero() {
+		return &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      fmt.Sprintf("Waiting until %s to delete the source FCDs retained by Clone mode", retainedUntil.UTC().Format(time.RFC3339)),
+			Logs:         logs,
+			RequeueAfter: time.Until(retainedUntil),
+		}, nil
+	}
+
+	// Dummy VMs are cleaned up while the source vCenter credentials are still available
+	logs = p.cleanupDummyVMs(ctx, migration, logs)
+
+	if keepSourceFD != "" {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Keeping source vCenter %s configuration, it hosts target failure domain %s", sourceVC.Server, keepSourceFD),
+			string(p.Name()))
+	} else {
+		var result *PhaseResult
+		if logs, result, err = p.removeSourceVCenter(ctx, sourceVC.Server, logs); result != nil {
+			return result, err
+		}
+	}
+
+	// Point the vSphere CSI driver at the target so new volumes are provisioned there
+	var result *PhaseResult
+	if logs, result, err = p.updateCSIDriverConfig(ctx, migration, logs); result != nil {
+		return result, err
+	}
+
+	// Restart vSphere pods to pick up new configuration
+	logger.Info("Restarting vSphere pods to apply cleanup")
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Restarting vSphere pods to apply cleanup",
+		string(p.Name()))
+
+	if err := p.podManager.RestartVSpherePods(ctx); err != nil {
+		logger.Error(err, "Failed to restart vSphere pods")
+		// Continue - not critical for cleanup
+	}
+
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Cleanup completed successfully",
+		string(p.Name()))
+
+	// Generate installer metadata.json
+	logger.Info("Generating installer metadata.json")
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Generating installer metadata.json",
+		string(p.Name()))
+
+	// Get infrastructure for metadata
+	infraForMeta, infraErr := p.executor.infraManager.Get(ctx)
+	if infraErr != nil {
+		logger.Error(infraErr, "Failed to get infrastructure for metadata generation")
+		// Not critical - continue without metadata
+	} else {
+		// Get credentials from target secret
+		targetSecret, targetSecretErr := p.executor.secretManager.GetTargetVCenterCredentials(ctx, migration)
+		if targetSecretErr != nil {
+			logger.Error(targetSecretErr, "Failed to get target credentials for metadata")
+		} else {
+			// Build credentials map
+			credentials := make(map[string]string)
+			for key, value := range targetSecret.Data {
+				credentials[key] = string(value)
+			}
+
+			// Generate metadata
+			meta, metaErr := p.metadataManager.GenerateMetadata(ctx, migration, infraForMeta, credentials)
+			if metaErr != nil {
+				logger.Error(metaErr, "Failed to generate metadata")
+			} else {
+				// Save to ConfigMap in the same namespace as the migration
+				configMapName := metadata.GetMetadataConfigMapName(migration.Name)
+				saveErr := p.metadataManager.SaveToConfigMap(ctx, meta, migration.Namespace, configMapName)
+				if saveErr != nil {
+					logger.Error(saveErr, "Failed to save metadata ConfigMap")
+				} else {
+					logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+						fmt.Sprintf("Generated metadata.json in ConfigMap %s/%s", migration.Namespace, configMapName),
+						string(p.Name()))
+				}
+			}
+		}
+	}
+
+	logger.Info("Successfully cleaned up source vCenter configuration")
+
+	return &PhaseResult{
+		Status:   migrationv1alpha1.PhaseStatusCompleted,
+		Message:  "Successfully cleaned up source vCenter configuration",
+		Progress: 100,
+		Logs:     logs,
+	}, nil
+}
+
+// removeSourceVCenter removes the source vCenter from the Infrastructure CRD, the
+// cloud-provider-config and the vsphere-creds secret. A non-nil result reports the failed step.
+func (p *CleanupPhase) removeSourceVCenter(ctx context.Context, server string, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	logger := klog.FromContext(ctx)
+
 	// Remove source vCenter from Infrastructure CRD
-	logger.Info("Removing source vCenter from Infrastructure CRD", "server", sourceVC.Server)
+	logger.Info("Removing source vCenter from Infrastructure CRD", "server", server)
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 		"Removing source vCenter from Infrastructure CRD",
 		string(p.Name()))
 
 	infra, err := p.executor.infraManager.Get(ctx)
 	if err != nil {
-		return &PhaseResult{
+		return logs, &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to get Infrastructure: " + err.Error(),
 			Logs:    logs,
 		}, err
 	}
 
-	_, err = p.executor.infraManager.RemoveSourceVCenter(ctx, infra, sourceVC.Server)
+	_, err = p.executor.infraManager.RemoveSourceVCenter(ctx, infra, server)
 	if err != nil {
-		return &PhaseResult{
+		return logs, &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to remove source vCenter from Infrastructure: " + err.Error(),
 			Logs:    logs,
@@ -93,16 +216,16 @@ func (p *CleanupPhase) Execute(ctx context.Context, migration *migrationv1alpha1
 
 	cm, err := p.configManager.GetCloudProviderConfig(ctx)
 	if err != nil {
-		return &PhaseResult{
+		return logs, &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to get cloud-provider-config: " + err.Error(),
 			Logs:    logs,
 		}, err
 	}
 
-	_, err = p.configManager.RemoveSourceVCenterFromConfig(ctx, cm, sourceVC.Server)
+	_, err = p.configManager.RemoveSourceVCenterFromConfig(ctx, cm, server)
 	if err != nil {
-		return &PhaseResult{
+		return logs, &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to remove source vCenter from config: " + err.Error(),
 			Logs:    logs,
@@ -121,16 +244,16 @@ func (p *CleanupPhase) Execute(ctx context.Context, migration *migrationv1alpha1
 
 	secret, err := p.executor.secretManager.GetVSphereCredsSecret(ctx)
 	if err != nil {
-		return &PhaseResult{
+		return logs, &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to get vsphere-creds secret: " + err.Error(),
 			Logs:    logs,
 		}, err
 	}
 
-	_, err = p.executor.secretManager.RemoveSourceVCenterCreds(ctx, secret, sourceVC.Server)
+	_, err = p.executor.secretManager.RemoveSourceVCenterCreds(ctx, secret, server)
 	if err != nil {
-		return &PhaseResult{
+		return logs, &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to remove source vCenter credentials: " + err.Error(),
 			Logs:    logs,
@@ -141,71 +264,281 @@ func (p *CleanupPhase) Execute(ctx context.Context, migration *migrationv1alpha1
 		"Removed source vCenter credentials",
 		string(p.Name()))
 
-	// Restart vSphere pods to pick up new configuration
-	logger.Info("Restarting vSphere pods to apply cleanup")
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		"Restarting vSphere pods to apply cleanup",
-		string(p.Name()))
+	return logs, nil, nil
+}
 
-	if err := p.podManager.RestartVSpherePods(ctx); err != nil {
-		logger.Error(err, "Failed to restart vSphere pods")
-		// Continue - not critical for cleanup
+// updateCSIDriverConfig replaces the vCenters of the vSphere CSI driver configuration and
+// credentials with the target vCenters and, if that changed them, records when, so Verify can
+// check the restarted CSI controller loaded them. A non-nil result reports the failed step.
+func (p *CleanupPhase) updateCSIDriverConfig(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	logger := klog.FromContext(ctx)
+
+	vcenters, err := p.targetCSIVCenters(ctx, migration)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to build vSphere CSI driver config: " + err.Error(),
+			Logs:    logs,
+		}, err
 	}
 
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		"Cleanup completed successfully",
-		string(p.Name()))
+	logger.Info("Updating vSphere CSI driver config")
+	changed, err := openshift.NewCSIDriverConfigManager(p.executor.kubeClient).SetVCenters(ctx, vcenters)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to update vSphere CSI driver config: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
 
-	// Generate installer metadata.json
-	logger.Info("Generating installer metadata.json")
+	servers := make([]string, 0, len(vcenters))
+	for _, vc := range vcenters {
+		servers = append(servers, vc.Server)
+	}
+	if !changed {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("vSphere CSI driver is already configured for target vCenter(s) %s", strings.Join(servers, ", ")),
+			string(p.Name()))
+		return logs, nil, nil
+	}
+
+	now := metav1.Now()
+	migration.Status.CSIDriverConfigUpdateTime = &now
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		"Generating installer metadata.json",
+		fmt.Sprintf("Pointed the vSphere CSI driver at target vCenter(s) %s", strings.Join(servers, ", ")),
 		string(p.Name()))
+	return logs, nil, nil
+}
 
-	// Get infrastructure for metadata
-	infraForMeta, infraErr := p.executor.infraManager.Get(ctx)
-	if infraErr != nil {
-		logger.Error(infraErr, "Failed to get infrastructure for metadata generation")
-		// Not critical - continue without metadata
-	} else {
-		// Get credentials from target secret
-		targetSecret, targetSecretErr := p.executor.secretManager.GetTargetVCenterCredentials(ctx, migration)
-		if targetSecretErr != nil {
-			logger.Error(targetSecretErr, "Failed to get target credentials for metadata")
-		} else {
-			// Build credentials map
-			credentials := make(map[string]string)
-			for key, value := range targetSecret.Data {
-				credentials[key] = string(value)
-			}
+// targetCSIVCenters returns the target vCenters with the datacenters of their failure domains
+// and their credentials
+func (p *CleanupPhase) targetCSIVCenters(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) ([]openshift.CSIVCenter, error) {
+	credentials, err := p.executor.TargetCredentialProvider(migration)
+	if err != nil {
+		return nil, err
+	}
 
-			// Generate metadata
-			meta, metaErr := p.metadataManager.GenerateMetadata(ctx, migration, infraForMeta, credentials)
-			if metaErr != nil {
-				logger.Error(metaErr, "Failed to generate metadata")
-			} else {
-				// Save to ConfigMap in the same namespace as the migration
-				configMapName := metadata.GetMetadataConfigMapName(migration.Name)
-				saveErr := p.metadataManager.SaveToConfigMap(ctx, meta, migration.Namespace, configMapName)
-				if saveErr != nil {
-					logger.Error(saveErr, "Failed to save metadata ConfigMap")
-				} else {
-					logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-						fmt.Sprintf("Generated metadata.json in ConfigMap %s/%s", migration.Namespace, configMapName),
-						string(p.Name()))
-				}
+	var vcenters []openshift.CSIVCenter
+	for _, fd := range migration.Spec.FailureDomains {
+		i := slices.IndexFunc(vcenters, func(vc openshift.CSIVCenter) bool { return vc.Server == fd.Server })
+		if i < 0 {
+			username, password, err := credentials.GetCredentials(ctx, fd.Server)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get credentials for %s: %w", fd.Server, err)
 			}
+			vcenters = append(vcenters, openshift.CSIVCenter{Server: fd.Server, Username: username, Password: password})
+			i = len(vcenters) - 1
+		}
+		if !slices.Contains(vcenters[i].Datacenters, fd.Topology.Datacenter) {
+			vcenters[i].Datacenters = append(vcenters[i].Datacenters, fd.Topology.Datacenter)
 		}
 	}
+	return vcenters, nil
+}
 
-	logger.Info("Successfully cleaned up source vCenter configuration")
+// checkSourceVolumes holds the phase while volumes remain on the source vCenter, unless allowed by
+// AllowSourceVolumesAnnotation. Without a CSI volume migration nothing was moved off the source,
+// so there is nothing to wait for and the check is skipped.
+func (p *CleanupPhase) checkSourceVolumes(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, server string, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	logger := klog.FromContext(ctx)
 
-	return &PhaseResult{
-		Status:   migrationv1alpha1.PhaseStatusCompleted,
-		Message:  "Successfully cleaned up source vCenter configuration",
-		Progress: 100,
-		Logs:     logs,
-	}, nil
+	if migration.Status.CSIVolumeMigration == nil {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			"CSI volume migration did not run, not checking for volumes left on the source vCenter",
+			string(p.Name()))
+		return logs, nil, nil
+	}
+
+	remaining, err := p.sourceResidentVolumes(ctx, migration, server)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to check for volumes on the source vCenter: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	if len(remaining) == 0 {
+		return logs, nil, nil
+	}
+
+	if migration.Annotations[migrationv1alpha1.AllowSourceVolumesAnnotation] != "true" {
+		msg := fmt.Sprintf("Refusing to remove the source vCenter: %d volume(s) have not been migrated and would become inaccessible: %s. "+
+			"Migrate or remove them, or set annotation %s=true to proceed anyway",
+			len(remaining), strings.Join(remaining, ", "), migrationv1alpha1.AllowSourceVolumesAnnotation)
+		logger.Info(msg)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning, msg, string(p.Name()))
+
+		return logs, &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Logs:         logs,
+			RequeueAfter: 5 * time.Minute,
+		}, nil
+	}
+
+	logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+		fmt.Sprintf("Removing the source vCenter although %d volume(s) have not been migrated (%s): allowed by annotation %s",
+			len(remaining), strings.Join(remaining, ", "), migrationv1alpha1.AllowSourceVolumesAnnotation),
+		string(p.Name()))
+	return logs, nil, nil
+}
+
+// sourceResidentVolumes returns the volumes that still live on the source vCenter: vSphere CSI
+// PVs whose FCD is found there, those whose handle names no FCD to look up, and in-tree vSphere
+// volumes, which are never migrated
+func (p *CleanupPhase) sourceResidentVolumes(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, server string) ([]string, error) {
+	client, err := p.executor.GetVSphereClientFromMigration(ctx, migration, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to source vCenter %s: %w", server, err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FCD manager: %w", err)
+	}
+
+	// vMotion keeps the FCD ID, so the lookup on the source rather than the ID tells a moved
+	// volume apart from one left behind
+	onSource := func(volumeHandle string) (bool, error) {
+		fcdID, err := vsphere.ParseCSIVolumeHandle(volumeHandle)
+		if err != nil {
+			return true, nil
+		}
+		_, err = fcdManager.GetFCDByID(ctx, fcdID)
+		if errors.Is(err, vsphere.ErrFCDNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	pvManager := openshift.NewPersistentVolumeManager(p.executor.kubeClient)
+	pvs, err := pvManager.FindSourceResidentVolumes(ctx, onSource)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make([]string, 0, len(pvs))
+	for _, pv := range pvs {
+		remaining = append(remaining, fmt.Sprintf("PV %s (%s)", pv.Name, p.volumeState(migration, pv.Name)))
+	}
+
+	inTreePVs, err := pvManager.ListInTreeVSphereVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pv := range inTreePVs {
+		remaining = append(remaining, fmt.Sprintf("PV %s (in-tree)", pv.Name))
+	}
+
+	return remaining, nil
+}
+
+// cleanupDummyVMs deletes dummy VMs left behind by earlier runs, including those retained for
+// inspecting failed relocations. It is best effort: failures and VMs kept because customer disks
+// are attached are logged as warnings for the operator.
+func (p *CleanupPhase) cleanupDummyVMs(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	logger := klog.FromContext(ctx)
+
+	cleanup, err := p.executor.CleanupAllDummyVMs(ctx, migration)
+	if err != nil {
+		logger.Error(err, "Failed to clean up leftover dummy VMs")
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Failed to clean up leftover dummy VMs: %v", err), string(p.Name()))
+	}
+	if cleanup == nil {
+		return logs
+	}
+
+	if len(cleanup.Deleted) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Deleted %d leftover dummy VM(s): %s", len(cleanup.Deleted), strings.Join(cleanup.Deleted, ", ")),
+			string(p.Name()))
+	}
+	if len(cleanup.Detached) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Detached volume(s) still attached to leftover dummy VMs: %s", strings.Join(cleanup.Detached, ", ")),
+			string(p.Name()))
+	}
+	for _, name := range slices.Sorted(maps.Keys(cleanup.Retained)) {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Kept leftover dummy VM %s, remove it manually once its disks are safe: %s", name, cleanup.Retained[name]),
+			string(p.Name()))
+	}
+	return logs
+}
+
+// cleanupRetainedSourceVolumes deletes the source FCDs of cloned volumes whose retention has
+// expired and returns when the earliest of the others expires, or the zero time when none is
+// retained any more. Deleting is best effort: failures are logged as warnings and those FCDs are
+// left for manual deletion.
+func (p *CleanupPhase) cleanupRetainedSourceVolumes(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, time.Time) {
+	logger := klog.FromContext(ctx)
+
+	expired, retained := RetainedSourceVolumes(migration.Status.CSIVolumeMigration, time.Now())
+	var retainedUntil time.Time
+	for _, pvState := range retained {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Keeping source FCD %s of PV %s until %s",
+				pvState.SourceVolumeID, pvState.PVName, pvState.SourceRetainedUntil.UTC().Format(time.RFC3339)),
+			string(p.Name()))
+		if retainedUntil.IsZero() || pvState.SourceRetainedUntil.Time.Before(retainedUntil) {
+			retainedUntil = pvState.SourceRetainedUntil.Time
+		}
+	}
+	if len(expired) == 0 {
+		return logs, retainedUntil
+	}
+
+	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Failed to get source vCenter, keeping %d source FCD(s) past their retention: %v", len(expired), err),
+			string(p.Name())), retainedUntil
+	}
+	client, err := p.executor.GetVSphereClientFromMigration(ctx, migration, sourceVC.Server)
+	if err != nil {
+		return AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Failed to connect to source vCenter, keeping %d source FCD(s) past their retention: %v", len(expired), err),
+			string(p.Name())), retainedUntil
+	}
+	defer client.Logout(ctx)
+
+	cnsManager, err := vsphere.NewCNSManager(ctx, client)
+	if err != nil {
+		return AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Failed to create CNS manager, keeping %d source FCD(s) past their retention: %v", len(expired), err),
+			string(p.Name())), retainedUntil
+	}
+
+	for _, pvState := range expired {
+		// Deleting through CNS removes the volume's registration along with the disk
+		if err := cnsManager.DeleteVolume(ctx, pvState.SourceVolumeID, true); err != nil {
+			logger.Error(err, "Failed to delete retained source FCD", "pv", pvState.PVName, "fcdID", pvState.SourceVolumeID)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("Failed to delete source FCD %s of PV %s, delete it manually: %v", pvState.SourceVolumeID, pvState.PVName, err),
+				string(p.Name()))
+			continue
+		}
+		pvState.SourceRetainedUntil = nil
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Deleted source FCD %s of PV %s, its retention has expired", pvState.SourceVolumeID, pvState.PVName),
+			string(p.Name()))
+	}
+	return logs, retainedUntil
+}
+
+// volumeState describes the CSI migration state of a PV for the Cleanup safeguard message
+func (p *CleanupPhase) volumeState(migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvName string) string {
+	if status := migration.Status.CSIVolumeMigration; status != nil {
+		for _, pvState := range status.Volumes {
+			if pvState.PVName == pvName {
+				return strings.ToLower(pvState.Status)
+			}
+		}
+	}
+	return "not migrated"
 }
 
 // Rollback reverts the phase changes
@@ -249,6 +582,19 @@ func (p *CleanupPhase) Rollback(ctx context.Context, migration *migrationv1alpha
 		return err
 	}
 
+	// Restore the vSphere CSI driver secrets, which are only backed up when the driver is installed
+	for _, name := range []string{openshift.CSIDriverConfigSecretName, openshift.CSIDriverCredentialsSecretName} {
+		csiBackup, err := p.executor.backupManager.GetBackup(migration, "Secret", name, openshift.CSIDriverNamespace)
+		if err != nil {
+			logger.Info("No backup of vSphere CSI driver secret, leaving it as is", "secret", name)
+			continue
+		}
+		if err := p.executor.restoreManager.RestoreResource(ctx, csiBackup); err != nil {
+			logger.Error(err, "Failed to restore vSphere CSI driver secret", "secret", name)
+			return err
+		}
+	}
+
 	logger.Info("Successfully restored source vCenter configuration")
 	return nil
 }
@@ -3,10 +3,17 @@ package phases
 import (
 	"context"
 	"fmt"
+	"path"
+	"slices"
+	"strings"
 
+	"github.com/vmware/govmomi/object"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 // CreateFolderPhase creates VM folder in target vCenter
@@ -58,7 +65,7 @@ func (p *CreateFolderPhase) Execute(ctx context.Context, migration *migrationv1a
 	for i := range migration.Spec.FailureDomains {
 		fd := &migration.Spec.FailureDomains[i]
 		if fd.Topology.Folder == "" {
-			fd.Topology.Folder = fmt.Sprintf("/%s/vm/%s", fd.Topology.Datacenter, infraID)
+			fd.Topology.Folder = util.FailureDomainFolder(fd.Topology, infraID)
 			logger.Info("Generated folder path", "failureDomain", fd.Name, "folder", fd.Topology.Folder)
 			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 				fmt.Sprintf("Generated folder path for %s: %s", fd.Name, fd.Topology.Folder),
@@ -66,26 +73,25 @@ func (p *CreateFolderPhase) Execute(ctx context.Context, migration *migrationv1a
 		}
 	}
 
-	// Construct folder path: /{datacenter}/vm/{infrastructure-id}
-	folderName := infraID
-
-	// Group failure domains by server and datacenter
+	// Group the folders to create by server and datacenter. Each failure domain's folder, which
+	// may be nested, holds its new machines and the dummy VMs relocated into it.
 	type ServerDC struct {
 		Server     string
 		Datacenter string
 	}
-	serverDCs := make(map[ServerDC]bool)
+	serverFolders := make(map[ServerDC][]string)
+	addFolder := func(key ServerDC, folder string) {
+		if !slices.Contains(serverFolders[key], folder) {
+			serverFolders[key] = append(serverFolders[key], folder)
+		}
+	}
 	for _, fd := range migration.Spec.FailureDomains {
-		serverDCs[ServerDC{Server: fd.Server, Datacenter: fd.Topology.Datacenter}] = true
+		key := ServerDC{Server: fd.Server, Datacenter: fd.Topology.Datacenter}
+		addFolder(key, util.FailureDomainFolder(fd.Topology, infraID))
 	}
 
-	// Create folder in each unique server/datacenter combination
-	for serverDC := range serverDCs {
-		logger.Info("Creating VM folder", "server", serverDC.Server, "datacenter", serverDC.Datacenter, "folder", folderName)
-		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-			fmt.Sprintf("Creating VM folder in %s/%s: %s", serverDC.Server, serverDC.Datacenter, folderName),
-			string(p.Name()))
-
+	// Create the folders in each unique server/datacenter combination
+	for serverDC, folderPaths := range serverFolders {
 		// Connect to target vCenter
 		targetClient, err := p.executor.GetVSphereClientFromMigration(ctx, migration, serverDC.Server)
 		if err != nil {
@@ -97,33 +103,32 @@ func (p *CreateFolderPhase) Execute(ctx context.Context, migration *migrationv1a
 		}
 		defer targetClient.Logout(ctx)
 
-		// Create folder
-		folder, err := targetClient.CreateVMFolder(ctx, serverDC.Datacenter, folderName)
-		if err != nil {
-			return &PhaseResult{
-				Status:  migrationv1alpha1.PhaseStatusFailed,
-				Message: fmt.Sprintf("Failed to create VM folder in %s: %v", serverDC.Server, err),
-				Logs:    logs,
-			}, err
-		}
-
-		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-			fmt.Sprintf("Created VM folder: %s (moref: %s)", folderName, folder.Reference()),
-			string(p.Name()))
+		for _, folderPath := range folderPaths {
+			logger.Info("Ensuring VM folder", "server", serverDC.Server, "datacenter", serverDC.Datacenter, "folder", folderPath)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Ensuring VM folder in %s/%s: %s", serverDC.Server, serverDC.Datacenter, folderPath),
+				string(p.Name()))
 
-		// Verify folder is accessible
-		_, err = targetClient.GetVMFolder(ctx, serverDC.Datacenter, folderName)
-		if err != nil {
-			return &PhaseResult{
-				Status:  migrationv1alpha1.PhaseStatusFailed,
-				Message: fmt.Sprintf("Failed to verify VM folder in %s: %v", serverDC.Server, err),
-				Logs:    logs,
-			}, err
+			// Create the folder along with any missing parents, reusing what already exists, and
+			// check that VMs can be created in it
+			folder, existed, err := PrepareVMFolder(ctx, targetClient, serverDC.Datacenter, folderPath)
+			if err != nil {
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: fmt.Sprintf("Failed to prepare VM folder %s in %s: %v", folderPath, serverDC.Server, err),
+					Logs:    logs,
+				}, err
+			}
+
+			if existed {
+				logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+					fmt.Sprintf("Reusing existing VM folder: %s (moref: %s)", folder.InventoryPath, folder.Reference()),
+					string(p.Name()))
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("VM folder is ready: %s (moref: %s)", folder.InventoryPath, folder.Reference()),
+				string(p.Name()))
 		}
-
-		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-			fmt.Sprintf("Verified VM folder is accessible in %s/%s", serverDC.Server, serverDC.Datacenter),
-			string(p.Name()))
 	}
 
 	logger.Info("Successfully created VM folder")
@@ -137,6 +142,57 @@ func (p *CreateFolderPhase) Execute(ctx context.Context, migration *migrationv1a
 	}, nil
 }
 
+// PrepareVMFolder returns the VM folder at folderPath, creating it if needed, once the logged in
+// account is known to be able to create and manage VMs in it. A missing folder is only created
+// after Folder.Create is confirmed on its nearest existing parent, so a permission problem is
+// reported by name rather than as a vCenter fault. It also reports whether the folder existed.
+func PrepareVMFolder(ctx context.Context, client *vsphere.Client, datacenter, folderPath string) (*object.Folder, bool, error) {
+	folderPath = util.NormalizeInventoryPath(datacenter, util.VMFolder, folderPath)
+
+	existing, err := client.GetFolder(ctx, folderPath)
+	if err != nil && !vsphere.IsNotFound(err) {
+		return nil, false, err
+	}
+
+	if existing == nil {
+		vmRoot := path.Join("/", datacenter, util.VMFolder)
+		parentPath := path.Dir(folderPath)
+		parent, err := client.GetFolder(ctx, parentPath)
+		for vsphere.IsNotFound(err) && parentPath != vmRoot && parentPath != "/" {
+			parentPath = path.Dir(parentPath)
+			parent, err = client.GetFolder(ctx, parentPath)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		missing, err := client.CheckPrivileges(ctx, parent.Reference(), vsphere.FolderCreatePrivileges)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(missing) > 0 {
+			return nil, false, phaseerrors.Validation(fmt.Errorf("account cannot create VM folder %s: missing %s on %s",
+				folderPath, strings.Join(missing, ", "), parentPath))
+		}
+	}
+
+	folder, err := client.EnsureFolder(ctx, datacenter, folderPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	missing, err := client.CheckPrivileges(ctx, folder.Reference(), vsphere.FolderPrivileges)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(missing) > 0 {
+		return nil, false, phaseerrors.Validation(fmt.Errorf("account cannot create VMs in folder %s: missing %s",
+			folderPath, strings.Join(missing, ", ")))
+	}
+
+	return folder, existing != nil, nil
+}
+
 // Rollback reverts the phase changes
 func (p *CreateFolderPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
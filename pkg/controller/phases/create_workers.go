@@ -2,13 +2,22 @@ package phases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 // CreateWorkersPhase creates new worker machines in target vCenter
@@ -28,13 +37,8 @@ func (p *CreateWorkersPhase) Name() migrationv1alpha1.MigrationPhase {
 
 // Validate checks if the phase can be executed
 func (p *CreateWorkersPhase) Validate(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
-	if migration.Spec.MachineSetConfig.Replicas <= 0 {
-		return fmt.Errorf("worker replicas must be greater than 0")
-	}
-	if migration.Spec.MachineSetConfig.FailureDomain == "" {
-		return fmt.Errorf("worker failure domain is empty")
-	}
-	return nil
+	_, err := openshift.SurgePlacements(migration.Spec.MachineSetConfig)
+	return err
 }
 
 // Execute runs the phase
@@ -42,48 +46,70 @@ func (p *CreateWorkersPhase) Execute(ctx context.Context, migration *migrationv1
 	logger := klog.FromContext(ctx)
 	logs := make([]migrationv1alpha1.LogEntry, 0)
 
-	logger.Info("Creating new worker machines in target vCenter",
-		"replicas", migration.Spec.MachineSetConfig.Replicas,
-		"failureDomain", migration.Spec.MachineSetConfig.FailureDomain)
-
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		fmt.Sprintf("Creating %d worker machines in failure domain %s",
-			migration.Spec.MachineSetConfig.Replicas,
-			migration.Spec.MachineSetConfig.FailureDomain),
-		string(p.Name()))
-
-	// Validate failure domain configuration early
-	targetFD := migration.Spec.MachineSetConfig.FailureDomain
-	var foundFD *configv1.VSpherePlatformFailureDomainSpec
-	for i := range migration.Spec.FailureDomains {
-		if migration.Spec.FailureDomains[i].Name == targetFD {
-			foundFD = &migration.Spec.FailureDomains[i]
-			break
-		}
-	}
-
-	if foundFD == nil {
+	// With a surge the new MachineSets are created above their desired size; ScaleOldMachines
+	// settles them back once the old machines are gone
+	placements, err := openshift.SurgePlacements(migration.Spec.MachineSetConfig)
+	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
-			Message: fmt.Sprintf("failure domain %s not found in VmwareCloudFoundationMigration CR", targetFD),
+			Message: "Invalid worker MachineSet configuration: " + err.Error(),
 			Logs:    logs,
-		}, fmt.Errorf("failure domain %s not found", targetFD)
+		}, err
 	}
-
-	if foundFD.Topology.Template == "" {
-		logger.Error(nil, "Template not configured",
-			"failureDomain", foundFD.Name,
-			"fullSpec", fmt.Sprintf("%+v", foundFD))
+	desired, err := openshift.WorkerPlacements(migration.Spec.MachineSetConfig)
+	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
-			Message: fmt.Sprintf("template not specified in failure domain %s topology", targetFD),
+			Message: "Invalid worker MachineSet configuration: " + err.Error(),
 			Logs:    logs,
-		}, fmt.Errorf("template required but not specified")
+		}, err
 	}
 
-	logger.Info("Validated failure domain configuration",
-		"name", foundFD.Name,
-		"template", foundFD.Topology.Template)
+	for i, placement := range placements {
+		surge := placement.Replicas - desired[i].Replicas
+		logger.Info("Creating new worker machines in target vCenter",
+			"replicas", placement.Replicas,
+			"surge", surge,
+			"failureDomain", placement.FailureDomain)
+
+		msg := fmt.Sprintf("Creating %d worker machines in failure domain %s", placement.Replicas, placement.FailureDomain)
+		if surge > 0 {
+			msg += fmt.Sprintf(" (%d surge, settled back to %d once the old machines are removed)", surge, desired[i].Replicas)
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+
+		// Validate failure domain configuration early
+		var foundFD *configv1.VSpherePlatformFailureDomainSpec
+		for i := range migration.Spec.FailureDomains {
+			if migration.Spec.FailureDomains[i].Name == placement.FailureDomain {
+				foundFD = &migration.Spec.FailureDomains[i]
+				break
+			}
+		}
+
+		if foundFD == nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("failure domain %s not found in VmwareCloudFoundationMigration CR", placement.FailureDomain),
+				Logs:    logs,
+			}, fmt.Errorf("failure domain %s not found", placement.FailureDomain)
+		}
+
+		if foundFD.Topology.Template == "" {
+			logger.Error(nil, "Template not configured",
+				"failureDomain", foundFD.Name,
+				"fullSpec", fmt.Sprintf("%+v", foundFD))
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("template not specified in failure domain %s topology", placement.FailureDomain),
+				Logs:    logs,
+			}, fmt.Errorf("template required but not specified")
+		}
+
+		logger.Info("Validated failure domain configuration",
+			"name", foundFD.Name,
+			"template", foundFD.Topology.Template)
+	}
 
 	// Get MachineManager
 	machineManager := p.executor.GetMachineManager()
@@ -98,142 +124,239 @@ func (p *CreateWorkersPhase) Execute(ctx context.Context, migration *migrationv1
 		}, err
 	}
 
-	// Check if MachineSet already exists (idempotency)
-	newMachineSetName := fmt.Sprintf("%s-worker-%s", infraID, migration.Spec.MachineSetConfig.FailureDomain)
-	existingMS, err := machineManager.GetMachineSet(ctx, newMachineSetName)
-
-	if err == nil && existingMS != nil {
-		logger.Info("MachineSet already exists, checking readiness",
-			"name", newMachineSetName)
-		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-			fmt.Sprintf("MachineSet %s already exists (idempotent)", newMachineSetName),
-			string(p.Name()))
+	machineSetNames, err := workerMachineSetNames(migration, infraID, placements)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error(),
+			Logs:    logs,
+		}, err
+	}
 
-		// Check machines ready (non-blocking)
-		machinesComplete, readyMachines, totalMachines, err := machineManager.CheckMachinesReady(ctx, newMachineSetName)
-		if err != nil {
-			return &PhaseResult{
-				Status:  migrationv1alpha1.PhaseStatusFailed,
-				Message: "Failed to check machines: " + err.Error(),
-				Logs:    logs,
-			}, err
+	// Create any MachineSet that does not exist yet (idempotency)
+	var template *machinev1beta1.MachineSet
+	var created []string
+	for i, placement := range placements {
+		newMachineSetName := machineSetNames[i]
+		existingMS, err := machineManager.GetMachineSet(ctx, newMachineSetName)
+		if err == nil && existingMS != nil {
+			logger.Info("MachineSet already exists", "name", newMachineSetName)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("MachineSet %s already exists (idempotent)", newMachineSetName),
+				string(p.Name()))
+			continue
 		}
 
-		if !machinesComplete {
-			msg := fmt.Sprintf("Waiting for machines: %d/%d ready", readyMachines, totalMachines)
-			logger.Info(msg)
-			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
-
-			progress := int32(0)
-			if totalMachines > 0 {
-				progress = int32(float64(readyMachines) / float64(totalMachines) * 50)
+		// Step 1: Get existing worker MachineSet as template
+		if template == nil {
+			template, err = workerMachineSetTemplate(ctx, machineManager, machineSetNames)
+			if err != nil {
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: err.Error(),
+					Logs:    logs,
+				}, err
 			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Using MachineSet %s as template", template.Name),
+				string(p.Name()))
+		}
 
-			return &PhaseResult{
-				Status:       migrationv1alpha1.PhaseStatusRunning,
-				Message:      msg,
-				Progress:     progress,
-				Logs:         logs,
-				RequeueAfter: 30 * time.Second,
-			}, nil
+		// Workers placed by tag go to the resource pool the tag resolves to on the target
+		if tagPlacement := migration.Spec.MachineSetConfig.TagPlacement; tagPlacement != nil {
+			pool, err := p.resolveTagPlacement(ctx, migration, placement.FailureDomain, tagPlacement)
+			if err != nil {
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: fmt.Sprintf("Failed to resolve tag placement for failure domain %s: %v", placement.FailureDomain, err),
+					Logs:    logs,
+				}, err
+			}
+			placement.ResourcePool = pool
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Tag %s/%s places workers of failure domain %s in resource pool %s",
+					tagPlacement.Category, tagPlacement.Tag, placement.FailureDomain, pool),
+				string(p.Name()))
 		}
 
-		// Check nodes ready (non-blocking)
-		nodesComplete, readyNodes, totalNodes, err := machineManager.CheckNodesReady(ctx, newMachineSetName)
+		// Step 2: Create new MachineSet
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Creating new MachineSet %s", newMachineSetName),
+			string(p.Name()))
+
+		newMachineSet, err := machineManager.CreateWorkerMachineSet(ctx, newMachineSetName, migration, template, infraID, placement)
 		if err != nil {
 			return &PhaseResult{
 				Status:  migrationv1alpha1.PhaseStatusFailed,
-				Message: "Failed to check nodes: " + err.Error(),
+				Message: "Failed to create MachineSet: " + err.Error(),
 				Logs:    logs,
 			}, err
 		}
 
-		if !nodesComplete {
-			msg := fmt.Sprintf("Waiting for nodes: %d/%d ready", readyNodes, totalNodes)
-			logger.Info(msg)
-			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Created MachineSet %s with %d replicas in failure domain %s",
+				newMachineSet.Name, placement.Replicas, placement.FailureDomain),
+			string(p.Name()))
+		created = append(created, newMachineSet.Name)
+	}
 
-			progress := int32(50)
-			if totalNodes > 0 {
-				progress = 50 + int32(float64(readyNodes)/float64(totalNodes)*50)
-			}
+	if len(created) > 0 {
+		// Return running status - next reconcile will check machine/node readiness
+		msg := fmt.Sprintf("Created MachineSets %s, waiting for machines to provision", strings.Join(created, ", "))
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
 
-			return &PhaseResult{
-				Status:       migrationv1alpha1.PhaseStatusRunning,
-				Message:      msg,
-				Progress:     progress,
-				Logs:         logs,
-				RequeueAfter: 30 * time.Second,
-			}, nil
-		}
-
-		// MachineSet already exists and is ready
 		return &PhaseResult{
-			Status:   migrationv1alpha1.PhaseStatusCompleted,
-			Message:  fmt.Sprintf("MachineSet already exists with %d/%d machines ready", readyMachines, totalMachines),
-			Progress: 100,
-			Logs:     logs,
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Progress:     10,
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
 		}, nil
 	}
 
-	// Step 1: Get existing worker MachineSet as template
-	existingSets, err := machineManager.GetMachineSetsByVCenter(ctx, "")
+	// Check machines ready across every MachineSet (non-blocking)
+	machines, err := workerReadiness(ctx, placements, machineSetNames, machineManager.CheckMachinesReady)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
-			Message: "Failed to get existing MachineSets: " + err.Error(),
+			Message: "Failed to check machines: " + err.Error(),
 			Logs:    logs,
 		}, err
 	}
 
-	if len(existingSets) == 0 {
+	if !machines.complete {
+		msg := fmt.Sprintf("Waiting for machines: %d/%d ready (%s)", machines.ready, machines.total, machines.breakdown())
+		logger.Info(msg)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+
 		return &PhaseResult{
-			Status:  migrationv1alpha1.PhaseStatusFailed,
-			Message: "No existing MachineSets to use as template",
-			Logs:    logs,
-		}, fmt.Errorf("no existing MachineSets found")
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Progress:     machines.progress(0, 50),
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
+		}, nil
 	}
 
-	template := existingSets[0]
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		fmt.Sprintf("Using MachineSet %s as template", template.Name),
-		string(p.Name()))
-
-	// Step 2: Create new MachineSet
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		fmt.Sprintf("Creating new MachineSet %s", newMachineSetName),
-		string(p.Name()))
-
-	newMachineSet, err := machineManager.CreateWorkerMachineSet(ctx, newMachineSetName, migration, template, infraID)
+	// Check nodes ready across every MachineSet (non-blocking)
+	nodes, err := workerReadiness(ctx, placements, machineSetNames, machineManager.CheckNodesReady)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
-			Message: "Failed to create MachineSet: " + err.Error(),
+			Message: "Failed to check nodes: " + err.Error(),
 			Logs:    logs,
 		}, err
 	}
 
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		fmt.Sprintf("Created MachineSet %s with %d replicas", newMachineSet.Name, migration.Spec.MachineSetConfig.Replicas),
-		string(p.Name()))
+	if !nodes.complete {
+		msg := fmt.Sprintf("Waiting for nodes: %d/%d ready (%s)", nodes.ready, nodes.total, nodes.breakdown())
+		logger.Info(msg)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
 
-	// Return running status - next reconcile will check machine/node readiness
-	msg := fmt.Sprintf("Created MachineSet %s, waiting for machines to provision", newMachineSet.Name)
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		return &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Progress:     nodes.progress(50, 50),
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
+		}, nil
+	}
 
+	// Every MachineSet in every failure domain exists and is ready
 	return &PhaseResult{
-		Status:       migrationv1alpha1.PhaseStatusRunning,
-		Message:      msg,
-		Progress:     10,
-		Logs:         logs,
-		RequeueAfter: 30 * time.Second,
+		Status: migrationv1alpha1.PhaseStatusCompleted,
+		Message: fmt.Sprintf("%d worker MachineSet(s) ready with %d/%d machines ready (%s)",
+			len(machineSetNames), machines.ready, machines.total, machines.breakdown()),
+		Progress: 100,
+		Logs:     logs,
 	}, nil
 }
 
+// readinessCheck reports whether the machines or nodes of one MachineSet are ready
+type readinessCheck func(ctx context.Context, machineSetName string) (complete bool, ready, total int32, err error)
+
+// failureDomainReadiness is the ready and total count of one failure domain's MachineSet
+type failureDomainReadiness struct {
+	failureDomain string
+	ready         int32
+	total         int32
+}
+
+// aggregateReadiness sums readiness across the worker MachineSets of every failure domain
+type aggregateReadiness struct {
+	complete       bool
+	ready          int32
+	total          int32
+	failureDomains []failureDomainReadiness
+}
+
+// workerReadiness runs check against the MachineSet of every placement. A MachineSet counts as
+// complete only once its ready count reaches the replicas requested for its failure domain, so a
+// set whose machines have not all been created yet does not look finished.
+func workerReadiness(ctx context.Context, placements []openshift.WorkerPlacement, machineSetNames []string, check readinessCheck) (*aggregateReadiness, error) {
+	result := &aggregateReadiness{complete: true}
+	for i, placement := range placements {
+		complete, ready, total, err := check(ctx, machineSetNames[i])
+		if err != nil {
+			return nil, fmt.Errorf("MachineSet %s in failure domain %s: %w", machineSetNames[i], placement.FailureDomain, err)
+		}
+		// A failure domain scaled to zero replicas is done once it has no machines left
+		complete = complete || (placement.Replicas == 0 && total == 0)
+		total = max(total, placement.Replicas)
+		result.complete = result.complete && complete && ready >= total
+		result.ready += ready
+		result.total += total
+		result.failureDomains = append(result.failureDomains, failureDomainReadiness{
+			failureDomain: placement.FailureDomain,
+			ready:         ready,
+			total:         total,
+		})
+	}
+	return result, nil
+}
+
+// breakdown formats the per failure domain counts, e.g. "fd-a 3/3, fd-b 1/3"
+func (r *aggregateReadiness) breakdown() string {
+	parts := make([]string, 0, len(r.failureDomains))
+	for _, fd := range r.failureDomains {
+		parts = append(parts, fmt.Sprintf("%s %d/%d", fd.failureDomain, fd.ready, fd.total))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// progress scales the ready fraction into span percentage points starting at base
+func (r *aggregateReadiness) progress(base, span int32) int32 {
+	if r.total == 0 {
+		return base
+	}
+	return base + int32(float64(r.ready)/float64(r.total)*float64(span))
+}
+
+// workerMachineSetTemplate returns an existing worker MachineSet to copy, skipping the MachineSets this phase creates
+func workerMachineSetTemplate(ctx context.Context, machineManager *openshift.MachineManager, exclude []string) (*machinev1beta1.MachineSet, error) {
+	existingSets, err := machineManager.GetMachineSetsByVCenter(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing MachineSets: %w", err)
+	}
+
+	for _, ms := range existingSets {
+		if !slices.Contains(exclude, ms.Name) {
+			return ms, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no existing MachineSets to use as template")
+}
+
 // Rollback reverts the phase changes
 func (p *CreateWorkersPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
-	logger.Info("Rolling back CreateWorkers phase - deleting new worker MachineSet")
+	logger.Info("Rolling back CreateWorkers phase - removing new worker MachineSets")
+
+	placements, err := openshift.WorkerPlacements(migration.Spec.MachineSetConfig)
+	if err != nil {
+		return fmt.Errorf("invalid worker MachineSet configuration: %w", err)
+	}
 
 	// Get infrastructure ID for naming
 	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
@@ -241,18 +364,45 @@ func (p *CreateWorkersPhase) Rollback(ctx context.Context, migration *migrationv
 		return fmt.Errorf("failed to get infrastructure ID: %w", err)
 	}
 
+	machineSetNames, err := workerMachineSetNames(migration, infraID, placements)
+	if err != nil {
+		return err
+	}
+
 	machineManager := p.executor.GetMachineManager()
-	machineSetName := fmt.Sprintf("%s-worker-%s", infraID, migration.Spec.MachineSetConfig.FailureDomain)
 
-	// Delete MachineSet
-	err = machineManager.DeleteMachineSet(ctx, machineSetName)
+	backups, err := p.executor.machineSetBackups(ctx, migration)
 	if err != nil {
-		logger.Error(err, "Failed to delete new worker MachineSet")
-		return err
+		return fmt.Errorf("failed to read MachineSet backups: %w", err)
 	}
 
-	logger.Info("Successfully deleted new worker MachineSet", "name", machineSetName)
-	return nil
+	// Delete every MachineSet, continuing past failures so one does not strand the others. A
+	// MachineSet that already existed before the migration is restored from its backup instead.
+	var errs []error
+	for _, machineSetName := range machineSetNames {
+		if ms, ok := backups[machineSetName]; ok {
+			if err := machineManager.RestoreMachineSet(ctx, ms); err != nil {
+				logger.Error(err, "Failed to restore pre-existing MachineSet", "name", machineSetName)
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		err := machineManager.DeleteMachineSet(ctx, machineSetName)
+		if err != nil {
+			if apierrors.IsNotFound(errors.Unwrap(err)) {
+				logger.Info("New worker MachineSet already deleted", "name", machineSetName)
+				continue
+			}
+			logger.Error(err, "Failed to delete new worker MachineSet", "name", machineSetName)
+			errs = append(errs, err)
+			continue
+		}
+
+		logger.Info("Successfully deleted new worker MachineSet", "name", machineSetName)
+	}
+
+	return errors.Join(errs...)
 }
 
 // Helper functions that would be implemented in pkg/openshift/machines.go
@@ -278,3 +428,97 @@ func (p *CreateWorkersPhase) Rollback(ctx context.Context, migration *migrationv
 // 	// Count nodes with Ready condition
 // 	// Return counts
 // }
+
+// resolveTagPlacement resolves a tag placement to the resource pool it names on the target vCenter
+// of a failure domain. A tag matching no placement or several is a validation error.
+func (p *CreateWorkersPhase) resolveTagPlacement(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, failureDomain string, tagPlacement *migrationv1alpha1.TagPlacement) (string, error) {
+	var fd *configv1.VSpherePlatformFailureDomainSpec
+	for i := range migration.Spec.FailureDomains {
+		if migration.Spec.FailureDomains[i].Name == failureDomain {
+			fd = &migration.Spec.FailureDomains[i]
+			break
+		}
+	}
+	if fd == nil {
+		return "", phaseerrors.Validation(fmt.Errorf("failure domain %s not found", failureDomain))
+	}
+
+	client, err := p.executor.GetVSphereClientFromMigration(ctx, migration, fd.Server)
+	if err != nil {
+		return "", vsphere.ClassifyError(fmt.Errorf("failed to connect to target vCenter %s: %w", fd.Server, err))
+	}
+	defer client.Logout(ctx)
+
+	if client.TagManager() == nil {
+		return "", fmt.Errorf("tag manager not available on target vCenter %s", fd.Server)
+	}
+	pool, err := client.FindTaggedResourcePool(ctx, fd.Topology.Datacenter, tagPlacement.Category, tagPlacement.Tag)
+	if err != nil {
+		return "", phaseerrors.Validation(err)
+	}
+	return pool, nil
+}
+
+// settleWorkerSurge scales the new worker MachineSets created with surge replicas back to their
+// desired replicas and reports which were scaled and how many surge machines are still to be
+// removed. MachineSets that do not exist, such as before CreateWorkers ran, are skipped.
+func (e *PhaseExecutor) settleWorkerSurge(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (settled []string, excess int32, err error) {
+	logger := klog.FromContext(ctx)
+
+	if migration.Spec.MachineSetConfig.SurgeReplicas == 0 {
+		return nil, 0, nil
+	}
+	placements, err := openshift.WorkerPlacements(migration.Spec.MachineSetConfig)
+	if err != nil {
+		return nil, 0, phaseerrors.Validation(fmt.Errorf("invalid worker MachineSet configuration: %w", err))
+	}
+	infraID, err := e.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+	machineSetNames, err := workerMachineSetNames(migration, infraID, placements)
+	if err != nil {
+		return nil, 0, phaseerrors.Validation(err)
+	}
+
+	machineManager := e.GetMachineManager()
+	for i, name := range machineSetNames {
+		scaled, err := machineManager.ScaleDownMachineSet(ctx, name, placements[i].Replicas)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return settled, 0, fmt.Errorf("failed to settle MachineSet %s to %d replicas: %w", name, placements[i].Replicas, err)
+		}
+		if scaled {
+			logger.Info("Settled new worker MachineSet from its surge replicas", "name", name, "replicas", placements[i].Replicas)
+			settled = append(settled, name)
+		}
+
+		_, remaining, err := machineManager.CheckMachinesScaledDown(ctx, name, placements[i].Replicas)
+		if err != nil {
+			return settled, 0, err
+		}
+		excess += remaining
+	}
+	return settled, excess, nil
+}
+
+// workerMachineSetNames renders the worker MachineSet name for each placement
+func workerMachineSetNames(migration *migrationv1alpha1.VmwareCloudFoundationMigration, infraID string, placements []openshift.WorkerPlacement) ([]string, error) {
+	names := make([]string, 0, len(placements))
+	for _, placement := range placements {
+		name, err := util.WorkerMachineSetName(migration.Spec.Naming, util.NameParams{
+			InfraID:       infraID,
+			FailureDomain: placement.FailureDomain,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to name worker MachineSet for failure domain %s: %w", placement.FailureDomain, err)
+		}
+		if slices.Contains(names, name) {
+			return nil, fmt.Errorf("worker MachineSet name %s is used by more than one failure domain", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
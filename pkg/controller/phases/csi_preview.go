@@ -0,0 +1,123 @@
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+// CSIVolumePreview describes what the MigrateCSIVolumes phase would do with one volume
+type CSIVolumePreview struct {
+	PVName        string   `json:"pvName"`
+	PVCNamespace  string   `json:"pvcNamespace,omitempty"`
+	PVCName       string   `json:"pvcName,omitempty"`
+	CapacityBytes int64    `json:"capacityBytes"`
+	StorageClass  string   `json:"storageClass,omitempty"`
+	Workloads     []string `json:"workloads,omitempty"`
+	WorkloadGroup string   `json:"workloadGroup,omitempty"`
+	// SkipReason explains why the volume would be left on the source; empty when it would be migrated
+	SkipReason string `json:"skipReason,omitempty"`
+}
+
+// PreviewCSIVolumes runs the discovery of the MigrateCSIVolumes phase for a migration without
+// changing anything, listing the volumes it would select, the workloads it would scale down and
+// the volumes it would skip. Shared disks are only detected from the PV here: a volume attached
+// to several VMs is found once the phase inspects it on the source vCenter.
+func PreviewCSIVolumes(ctx context.Context, kubeClient kubernetes.Interface, migration *migrationv1alpha1.VmwareCloudFoundationMigration) ([]CSIVolumePreview, error) {
+	logger := klog.FromContext(ctx)
+
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+	workloadManager := openshift.NewWorkloadManager(kubeClient)
+	workloadManager.SetQuiesceExcludeNamespaces(quiesceExcludeNamespaces(migration))
+
+	csiPVs, err := pvManager.ListVSphereCSIVolumes(ctx, volumeSelector(migration), migrationName(migration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vSphere CSI volumes: %w", err)
+	}
+
+	previews := make([]CSIVolumePreview, 0, len(csiPVs))
+	for _, csiPV := range csiPVs {
+		preview := CSIVolumePreview{
+			PVName:        csiPV.Name,
+			CapacityBytes: csiPV.CapacityBytes,
+			StorageClass:  csiPV.StorageClass,
+		}
+		// Like the phase, the stale claimRef of an unbound PV is not followed
+		if csiPV.ClaimRef != nil && !openshift.IsUnboundPhase(csiPV.Phase) {
+			preview.PVCNamespace = csiPV.ClaimRef.Namespace
+			preview.PVCName = csiPV.ClaimRef.Name
+		}
+
+		if err := previewVolume(ctx, pvManager, workloadManager, csiPV, &preview); err != nil {
+			return nil, fmt.Errorf("failed to preview PV %s: %w", csiPV.Name, err)
+		}
+		logger.V(2).Info("Previewed CSI volume", "pv", preview.PVName, "skipReason", preview.SkipReason)
+		previews = append(previews, preview)
+	}
+
+	// Volumes used by the same workloads are migrated together, like in the phase
+	volumeWorkloads := make(map[string][]string)
+	for _, preview := range previews {
+		volumeWorkloads[preview.PVName] = workloadKeys(preview.PVCNamespace, preview.Workloads)
+	}
+	groups := WorkloadGroups(volumeWorkloads)
+	for i := range previews {
+		if group, ok := groups[previews[i].PVName]; ok {
+			previews[i].WorkloadGroup = group
+		}
+	}
+	return previews, nil
+}
+
+// previewVolume fills in the workloads of a volume and why it would be skipped, checking in the
+// same order as the phase
+func previewVolume(ctx context.Context, pvManager *openshift.PersistentVolumeManager, workloadManager *openshift.WorkloadManager, csiPV openshift.VSphereCSIPV, preview *CSIVolumePreview) error {
+	if openshift.IsFileVolume(csiPV) {
+		preview.SkipReason = fileVolumeSkipMessage
+		return nil
+	}
+
+	pv, err := pvManager.GetPV(ctx, csiPV.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get PV: %w", err)
+	}
+	if reason := SharedVolumeReason(pv, nil); reason != "" {
+		preview.SkipReason = reason + " - shared disks are not migrated, move it manually"
+		return nil
+	}
+
+	if preview.PVCName == "" {
+		return nil
+	}
+
+	sts, err := workloadManager.FindStatefulSetForPVC(ctx, preview.PVCNamespace, preview.PVCName)
+	if err != nil {
+		return fmt.Errorf("failed to look up StatefulSet for PVC: %w", err)
+	}
+	if sts != nil {
+		preview.WorkloadGroup = fmt.Sprintf("StatefulSet/%s/%s", sts.Namespace, sts.Name)
+	}
+
+	preview.Workloads, err = workloadManager.WorkloadsUsingPVC(ctx, preview.PVCNamespace, preview.PVCName)
+	if err != nil {
+		return err
+	}
+
+	resize, err := pvManager.GetVolumeResizeInProgress(ctx, preview.PVName, preview.PVCNamespace, preview.PVCName)
+	if err != nil {
+		return fmt.Errorf("failed to check for volume resize: %w", err)
+	}
+	if resize != "" {
+		preview.SkipReason = "Volume resize in progress (" + resize + ") - migrate after the resize completes"
+		return nil
+	}
+	if err := workloadManager.QuiesceExcludedError(preview.PVCNamespace, preview.PVCName, preview.Workloads); err != nil {
+		preview.SkipReason = err.Error() + " - migrate it manually or remove the namespace from quiesceExcludeNamespaces"
+	}
+	return nil
+}
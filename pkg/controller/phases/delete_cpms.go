@@ -6,6 +6,7 @@ import (
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
 )
 
 // DeleteCPMSPhase deletes the Control Plane Machine Set before infrastructure update
@@ -70,7 +71,7 @@ func (p *DeleteCPMSPhase) Rollback(ctx context.Context, migration *migrationv1al
 	logger.Info("Rolling back DeleteCPMS phase - restoring CPMS")
 
 	// Get CPMS backup
-	backup, err := p.executor.backupManager.GetBackup(migration, "ControlPlaneMachineSet", "cluster", "openshift-machine-api")
+	backup, err := p.executor.backupManager.GetBackup(migration, CPMSBackupType, "cluster", openshift.MachineAPINamespace)
 	if err != nil {
 		logger.Info("No CPMS backup found, skipping restore", "error", err)
 		return nil
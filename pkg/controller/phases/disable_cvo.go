@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	appsv1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 )
@@ -57,6 +59,9 @@ func (p *DisableCVOPhase) Execute(ctx context.Context, migration *migrationv1alp
 		fmt.Sprintf("Current CVO replicas: %d", *deployment.Spec.Replicas),
 		string(p.Name()))
 
+	// Backup records the replica count, unless it could not find the deployment then
+	recordCVOReplicas(migration, deployment)
+
 	// Scale to 0
 	replicas := int32(0)
 	deployment.Spec.Replicas = &replicas
@@ -89,21 +94,47 @@ func (p *DisableCVOPhase) Rollback(ctx context.Context, migration *migrationv1al
 	logger := klog.FromContext(ctx)
 	logger.Info("Rolling back DisableCVO phase - re-enabling CVO")
 
-	// Get deployment
-	deployment, err := p.executor.kubeClient.AppsV1().Deployments(CVONamespace).Get(ctx, CVOName, metav1.GetOptions{})
-	if err != nil {
+	if _, err := p.executor.EnableCVO(ctx, migration); err != nil {
 		return err
 	}
 
-	// Scale back to 1
-	replicas := int32(1)
-	deployment.Spec.Replicas = &replicas
+	logger.Info("Successfully re-enabled CVO")
+	return nil
+}
 
-	_, err = p.executor.kubeClient.AppsV1().Deployments(CVONamespace).Update(ctx, deployment, metav1.UpdateOptions{})
+// AutoReEnableCVO reports whether the migration scales the cluster-version-operator back up when
+// it completes, which it does unless disabled in the spec
+func AutoReEnableCVO(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.AutoReEnableCVO == nil || *migration.Spec.AutoReEnableCVO
+}
+
+// recordCVOReplicas records the replica count of the CVO deployment on the migration, unless an
+// earlier run already did or the CVO is already scaled down
+func recordCVOReplicas(migration *migrationv1alpha1.VmwareCloudFoundationMigration, deployment *appsv1.Deployment) {
+	if migration.Status.CVOReplicas != nil || deployment.Spec.Replicas == nil || *deployment.Spec.Replicas == 0 {
+		return
+	}
+	migration.Status.CVOReplicas = ptr.To(*deployment.Spec.Replicas)
+}
+
+// EnableCVO scales the cluster-version-operator back up to the replica count recorded before it
+// was disabled, or 1 when none was recorded. A CVO that is already running is left as it is. It
+// returns the replica count the CVO was scaled to, or 0 when it was already running.
+func (e *PhaseExecutor) EnableCVO(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (int32, error) {
+	deployment, err := e.kubeClient.AppsV1().Deployments(CVONamespace).Get(ctx, CVOName, metav1.GetOptions{})
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("failed to get CVO deployment: %w", err)
+	}
+	if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas > 0 {
+		return 0, nil
 	}
 
-	logger.Info("Successfully re-enabled CVO")
-	return nil
+	replicas := ptr.Deref(migration.Status.CVOReplicas, 1)
+	deployment.Spec.Replicas = &replicas
+	if _, err := e.kubeClient.AppsV1().Deployments(CVONamespace).Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		return 0, fmt.Errorf("failed to scale up CVO: %w", err)
+	}
+
+	klog.FromContext(ctx).Info("Scaled cluster-version-operator back up", "replicas", replicas)
+	return replicas, nil
 }
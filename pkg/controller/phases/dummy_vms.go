@@ -0,0 +1,255 @@
+package phases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+// DummyVMCleanup reports what a cleanup of leftover dummy VMs did
+type DummyVMCleanup struct {
+	// Deleted lists the dummy VMs deleted
+	Deleted []string
+
+	// Detached lists the customer FCDs detached from dummy VMs
+	Detached []string
+
+	// Retained maps each dummy VM left in place to the reason it was kept
+	Retained map[string]string
+}
+
+// CleanupLeftoverDummyVMs finds dummy VMs left behind by failed or interrupted runs in the
+// migration folders of the source and target vCenters and deletes those holding no customer
+// disks. Known volumes still attached are detached first. A VM with any other disk attached, or
+// used by a volume that has not finished migrating, is kept and reported, as is a VM retained for
+// inspecting a failed relocation.
+func (e *PhaseExecutor) CleanupLeftoverDummyVMs(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*DummyVMCleanup, error) {
+	return e.cleanupLeftoverDummyVMs(ctx, migration, false)
+}
+
+// CleanupAllDummyVMs is CleanupLeftoverDummyVMs that also deletes the dummy VMs retained for
+// inspecting failed relocations, whose volumes were detached from them. The retainedDummyVM of a
+// volume is cleared once its VM is deleted or no longer found.
+func (e *PhaseExecutor) CleanupAllDummyVMs(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*DummyVMCleanup, error) {
+	return e.cleanupLeftoverDummyVMs(ctx, migration, true)
+}
+
+func (e *PhaseExecutor) cleanupLeftoverDummyVMs(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, deleteRetained bool) (*DummyVMCleanup, error) {
+	infraID, err := e.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+	prefix, err := util.DummyVMNamePrefix(migration.Spec.Naming, infraID)
+	if err != nil {
+		return nil, err
+	}
+	knownFCDs, err := e.knownVolumeIDs(ctx, migration)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceVC, err := e.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source vCenter: %w", err)
+	}
+	sourceFD, err := e.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source failure domain: %w", err)
+	}
+
+	// The dummy VMs are created on the source and vMotioned to the first target failure domain
+	type location struct {
+		server     string
+		datacenter string
+		folder     string
+	}
+	locations := []location{{sourceVC.Server, sourceFD.Topology.Datacenter, util.FailureDomainFolder(sourceFD.Topology, infraID)}}
+	if len(migration.Spec.FailureDomains) > 0 {
+		targetFD := migration.Spec.FailureDomains[0]
+		locations = append(locations, location{targetFD.Server, targetFD.Topology.Datacenter, util.FailureDomainFolder(targetFD.Topology, infraID)})
+	}
+
+	cleanup := &DummyVMCleanup{Retained: make(map[string]string)}
+	inUse := dummyVMsInUse(migration, deleteRetained)
+
+	var errs []error
+	cleaned := make(map[string]bool)
+	for _, location := range locations {
+		client, err := e.GetVSphereClientFromMigration(ctx, migration, location.server)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to connect to vCenter %s: %w", location.server, err))
+			continue
+		}
+		if err := CleanupDummyVMs(ctx, client, location.datacenter, location.folder, prefix, knownFCDs, inUse, cleanup); err != nil {
+			errs = append(errs, fmt.Errorf("failed to clean up dummy VMs on vCenter %s: %w", location.server, err))
+		} else {
+			cleaned[location.server] = true
+		}
+		client.Logout(ctx)
+	}
+
+	if deleteRetained {
+		forgetDeletedRetainedDummyVMs(migration, cleanup, cleaned)
+	}
+
+	return cleanup, errors.Join(errs...)
+}
+
+// forgetDeletedRetainedDummyVMs clears the retainedDummyVM of the volumes whose VM was deleted,
+// or was not found on a vCenter whose dummy VMs were all cleaned up
+func forgetDeletedRetainedDummyVMs(migration *migrationv1alpha1.VmwareCloudFoundationMigration, cleanup *DummyVMCleanup, cleaned map[string]bool) {
+	status := migration.Status.CSIVolumeMigration
+	if status == nil {
+		return
+	}
+	for i := range status.Volumes {
+		pvState := &status.Volumes[i]
+		server, name, ok := strings.Cut(pvState.RetainedDummyVM, "/")
+		if !ok {
+			continue
+		}
+		_, kept := cleanup.Retained[name]
+		if slices.Contains(cleanup.Deleted, name) || (cleaned[server] && !kept) {
+			pvState.RetainedDummyVM = ""
+		}
+	}
+}
+
+// CleanupDummyVMs deletes the dummy VMs in a folder that hold no customer disks, recording the
+// outcome in cleanup. Attached FCDs in knownFCDs are detached first; VMs named in inUse or with
+// any other disk attached are never deleted, since destroying a VM deletes its disks.
+func CleanupDummyVMs(ctx context.Context, client *vsphere.Client, datacenter, folder, prefix string, knownFCDs map[string]bool, inUse map[string]string, cleanup *DummyVMCleanup) error {
+	logger := klog.FromContext(ctx)
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	vms, err := relocator.ListDummyVMs(ctx, datacenter, folder, prefix)
+	if err != nil {
+		return err
+	}
+
+	var fcdManager *vsphere.FCDManager
+	var errs []error
+	for _, vm := range vms {
+		name := vm.Name()
+		if reason, ok := inUse[name]; ok {
+			cleanup.Retained[name] = reason
+			continue
+		}
+
+		disks, err := relocator.AttachedDisks(ctx, vm)
+		if err != nil {
+			cleanup.Retained[name] = err.Error()
+			continue
+		}
+		if unknown := unknownDisks(disks, knownFCDs); len(unknown) > 0 {
+			cleanup.Retained[name] = "disks that are not known volumes are attached: " + strings.Join(unknown, ", ")
+			continue
+		}
+
+		if len(disks) > 0 {
+			if fcdManager == nil {
+				if fcdManager, err = vsphere.NewFCDManager(ctx, client); err != nil {
+					return fmt.Errorf("failed to create FCD manager: %w", err)
+				}
+			}
+			detachFailed := false
+			for _, disk := range disks {
+				if err := fcdManager.DetachDisk(ctx, vm, disk.FCDID); err != nil {
+					cleanup.Retained[name] = fmt.Sprintf("failed to detach FCD %s: %v", disk.FCDID, err)
+					detachFailed = true
+					break
+				}
+				cleanup.Detached = append(cleanup.Detached, disk.FCDID)
+			}
+			if detachFailed {
+				continue
+			}
+
+			// Only destroy the VM once the read-back confirms no disk is left on it
+			if disks, err = relocator.AttachedDisks(ctx, vm); err != nil {
+				cleanup.Retained[name] = err.Error()
+				continue
+			}
+			if len(disks) > 0 {
+				cleanup.Retained[name] = fmt.Sprintf("%d disk(s) still attached after detaching", len(disks))
+				continue
+			}
+		}
+
+		logger.Info("Deleting leftover dummy VM", "vm", name, "folder", folder)
+		if err := relocator.DeleteDummyVM(ctx, vm); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete dummy VM %s: %w", name, err))
+			continue
+		}
+		cleanup.Deleted = append(cleanup.Deleted, name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// unknownDisks returns the disks not backed by one of the known FCDs
+func unknownDisks(disks []vsphere.AttachedDisk, knownFCDs map[string]bool) []string {
+	var unknown []string
+	for _, disk := range disks {
+		if disk.FCDID == "" || !knownFCDs[disk.FCDID] {
+			unknown = append(unknown, disk.FileName)
+		}
+	}
+	return unknown
+}
+
+// knownVolumeIDs returns the FCD IDs of the cluster's vSphere CSI volumes and of every volume the
+// migration has tracked
+func (e *PhaseExecutor) knownVolumeIDs(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (map[string]bool, error) {
+	pvs, err := openshift.NewPersistentVolumeManager(e.kubeClient).ListAllVSphereCSIVolumes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vSphere CSI volumes: %w", err)
+	}
+
+	known := make(map[string]bool)
+	for _, pv := range pvs {
+		if fcdID, err := vsphere.ParseCSIVolumeHandle(pv.VolumeHandle); err == nil {
+			known[fcdID] = true
+		}
+	}
+	if status := migration.Status.CSIVolumeMigration; status != nil {
+		for _, pvState := range status.Volumes {
+			for _, id := range []string{pvState.SourceVolumeID, pvState.TargetVolumeID} {
+				if id != "" {
+					known[id] = true
+				}
+			}
+		}
+	}
+	return known, nil
+}
+
+// dummyVMsInUse maps the dummy VMs of volumes that have not finished migrating to why they are
+// kept. A VM retained for inspecting a failed relocation no longer holds its volumes and is kept,
+// whatever became of the volume since, unless deleteRetained is set.
+func dummyVMsInUse(migration *migrationv1alpha1.VmwareCloudFoundationMigration, deleteRetained bool) map[string]string {
+	inUse := make(map[string]string)
+	status := migration.Status.CSIVolumeMigration
+	if status == nil {
+		return inUse
+	}
+	for _, pvState := range status.Volumes {
+		if _, name, ok := strings.Cut(pvState.RetainedDummyVM, "/"); ok && !deleteRetained {
+			inUse[name] = fmt.Sprintf("retained for inspecting the failed relocation of volume %s", pvState.PVName)
+		}
+		if pvState.DummyVMName == "" || pvState.Status == PVStatusComplete || pvState.Status == PVStatusWorkloadsRestored || pvState.Status == PVStatusSkipped || pvState.Status == PVStatusSourceMissing {
+			continue
+		}
+		inUse[pvState.DummyVMName] = fmt.Sprintf("used by volume %s (%s)", pvState.PVName, strings.ToLower(pvState.Status))
+	}
+	return inUse
+}
@@ -0,0 +1,123 @@
+package phases
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+// IntegrityStage is the point of a volume's migration a checksum is taken at
+type IntegrityStage string
+
+const (
+	// IntegrityBeforeMigration is taken on the source once the volume is detached from its
+	// workloads and before it is attached to a dummy VM
+	IntegrityBeforeMigration IntegrityStage = "BeforeMigration"
+
+	// IntegrityAfterMigration is taken on the target once the volume is registered with CNS and
+	// before its PV is pointed at it
+	IntegrityAfterMigration IntegrityStage = "AfterMigration"
+)
+
+// IntegrityVolume identifies the FCD to checksum and the vCenter holding it. The volume is
+// detached from every VM and its workloads are scaled down at both stages, so a checker may
+// attach it wherever it reads it from, such as to a short-lived VM or to a node for a pod reading
+// the block device, as long as it detaches it again before returning.
+type IntegrityVolume struct {
+	Stage        IntegrityStage
+	PVName       string
+	PVCNamespace string
+	PVCName      string
+	VolumeID     string
+	Client       *vsphere.Client
+}
+
+// IntegrityChecker computes a fingerprint of the data of a volume. The same volume must yield
+// the same checksum before and after migration when its data did not change. An empty checksum
+// means the checker cannot fingerprint the volume, and the volume migrates unchecked.
+type IntegrityChecker interface {
+	Checksum(ctx context.Context, volume IntegrityVolume) (string, error)
+}
+
+// NoopIntegrityChecker computes no checksum
+type NoopIntegrityChecker struct{}
+
+// Checksum returns an empty checksum
+func (NoopIntegrityChecker) Checksum(ctx context.Context, volume IntegrityVolume) (string, error) {
+	return "", nil
+}
+
+// SetIntegrityChecker sets the checker volumes with VerifyIntegrity are checksummed with. A nil
+// checker computes no checksum.
+func (e *PhaseExecutor) SetIntegrityChecker(checker IntegrityChecker) {
+	if checker == nil {
+		checker = NoopIntegrityChecker{}
+	}
+	e.integrityChecker = checker
+}
+
+// verifyIntegrity reports whether volumes are checksummed before and after migration
+func verifyIntegrity(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.VerifyIntegrity
+}
+
+// RecordSourceChecksum checksums a detached volume on the source, unless an earlier attempt did
+func (p *MigrateCSIVolumesPhase) RecordSourceChecksum(ctx context.Context, sourceClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	if !verifyIntegrity(migration) || pvState.SourceChecksum != "" {
+		return nil
+	}
+
+	checksum, err := p.executor.integrityChecker.Checksum(ctx, IntegrityVolume{
+		Stage:        IntegrityBeforeMigration,
+		PVName:       pvState.PVName,
+		PVCNamespace: pvState.PVCNamespace,
+		PVCName:      pvState.PVCName,
+		VolumeID:     pvState.SourceVolumeID,
+		Client:       sourceClient,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checksum volume on source: %w", err)
+	}
+	if checksum == "" {
+		klog.FromContext(ctx).Info("No integrity checksum computed for volume, it migrates unchecked", "pv", pvState.PVName)
+		return nil
+	}
+	pvState.SourceChecksum = checksum
+	klog.FromContext(ctx).Info("Recorded source checksum of volume", "pv", pvState.PVName, "checksum", checksum)
+	return nil
+}
+
+// VerifyTargetChecksum checksums a registered volume on the target and compares it with the
+// checksum taken on the source. Volumes without a source checksum are not checked.
+func (p *MigrateCSIVolumesPhase) VerifyTargetChecksum(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	if !verifyIntegrity(migration) || pvState.SourceChecksum == "" {
+		return nil
+	}
+
+	// Taken again on every attempt, so a retry after a mismatch checks the data afresh
+	checksum, err := p.executor.integrityChecker.Checksum(ctx, IntegrityVolume{
+		Stage:        IntegrityAfterMigration,
+		PVName:       pvState.PVName,
+		PVCNamespace: pvState.PVCNamespace,
+		PVCName:      pvState.PVCName,
+		VolumeID:     pvState.TargetVolumeID,
+		Client:       targetClient,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to checksum volume on target: %w", err)
+	}
+	if checksum == "" {
+		return fmt.Errorf("no checksum computed for the volume on the target to compare with source checksum %s", pvState.SourceChecksum)
+	}
+	pvState.TargetChecksum = checksum
+
+	if pvState.TargetChecksum != pvState.SourceChecksum {
+		return fmt.Errorf("volume checksum changed during migration: source %s, target %s", pvState.SourceChecksum, pvState.TargetChecksum)
+	}
+	klog.FromContext(ctx).Info("Volume checksum matches after migration", "pv", pvState.PVName, "checksum", pvState.TargetChecksum)
+	return nil
+}
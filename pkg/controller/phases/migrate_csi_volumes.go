@@ -2,14 +2,24 @@ package phases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
 	"time"
 
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/vmware/govmomi/object"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
@@ -18,15 +28,45 @@ const (
 	PVStatusPending    = "Pending"
 	PVStatusRetainSet  = "RetainSet"  // PV reclaim policy set to Retain
 	PVStatusQuiesced   = "Quiesced"   // Workloads scaled down, pods terminated
-	PVStatusPVCDeleted = "PVCDeleted" // PVC deleted after quiesce
+	PVStatusPVCDeleted = "PVCDeleted" // PVC deleted, or kept for a StatefulSet claim, after quiesce
 	PVStatusRelocating = "Relocating"
 	PVStatusRelocated  = "Relocated"
 	PVStatusRegistered = "Registered"
-	PVStatusPVUpdated  = "PVUpdated" // PV volumeHandle updated and claimRef cleared
+	PVStatusPVUpdated  = "PVUpdated" // PV volumeHandle updated and claimRef cleared unless the PVC was kept
 	PVStatusComplete   = "Complete"
 	PVStatusFailed     = "Failed"
+	PVStatusSkipped    = "Skipped" // Left on the source, e.g. while a resize is in progress
+
+	// PVStatusSourceMissing marks a volume whose FCD no longer exists on the source
+	PVStatusSourceMissing = "SourceMissing"
+
+	// PVStatusWorkloadsRestored marks a volume whose workloads were restored and are awaited
+	// until they are ready or its WorkloadsReadyDeadline passes
+	PVStatusWorkloadsRestored = "WorkloadsRestored"
 )
 
+// fileVolumeSkipMessage explains why vSAN file share volumes are skipped
+const fileVolumeSkipMessage = "Volume is a vSAN file share, which has no disk to relocate - recreate it on the target"
+
+// defaultAttachVerificationTimeout bounds the FCD attach read-back when not set in the spec
+const defaultAttachVerificationTimeout = 30 * time.Second
+
+// attachmentSettleTimeout bounds the wait for an in-flight attach of a volume before its PVC is deleted
+const attachmentSettleTimeout = 2 * time.Minute
+
+// volumeDetachTimeout bounds the wait for the VolumeAttachment of a volume to go once its PVC is deleted
+const volumeDetachTimeout = 3 * time.Minute
+
+// defaultSourceRetention is how long the source FCD of a cloned volume is kept when not set in the spec
+const defaultSourceRetention = 7 * 24 * time.Hour
+
+// defaultScaledDownAlertThreshold is how long the workloads of a failed volume may stay scaled down
+// before they are reported, when not set in the spec
+const defaultScaledDownAlertThreshold = 30 * time.Minute
+
+// maxRelocateBatchSize is the number of disks a dummy VM can hold across all its SCSI controllers
+const maxRelocateBatchSize = vsphere.MaxSCSIControllers * (vsphere.UnitsPerSCSIController - 1)
+
 // MigrateCSIVolumesPhase migrates vSphere CSI PersistentVolumes to the target vCenter
 type MigrateCSIVolumesPhase struct {
 	executor *PhaseExecutor
@@ -48,7 +88,7 @@ func (p *MigrateCSIVolumesPhase) Name() migrationv1alpha1.MigrationPhase {
 func (p *MigrateCSIVolumesPhase) Validate(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	// Ensure we have target vCenter configuration
 	if len(migration.Spec.FailureDomains) == 0 {
-		return fmt.Errorf("no failure domains configured")
+		return phaseerrors.Validation(fmt.Errorf("no failure domains configured"))
 	}
 	return nil
 }
@@ -74,14 +114,21 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 		}
 	}
 
-	// Create PV manager
+	// Create managers
 	pvManager := openshift.NewPersistentVolumeManager(p.executor.kubeClient)
+	workloadManager := openshift.NewWorkloadManager(p.executor.kubeClient)
+	workloadManager.SetQuiesceExcludeNamespaces(quiesceExcludeNamespaces(migration))
 
 	// Discover vSphere CSI volumes if not already done
 	if len(migration.Status.CSIVolumeMigration.Volumes) == 0 {
 		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Discovering vSphere CSI volumes", string(p.Name()))
 
-		csiPVs, err := pvManager.ListVSphereCSIVolumes(ctx)
+		selector := volumeSelector(migration)
+		if selector != nil {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Restricting discovery to volumes matching the volume selector", string(p.Name()))
+		}
+
+		csiPVs, err := pvManager.ListVSphereCSIVolumes(ctx, selector, migrationName(migration))
 		if err != nil {
 			return &PhaseResult{
 				Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -100,23 +147,85 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 			}, nil
 		}
 
-		// Initialize volume states
+		// Initialize volume states, noting the workloads mounting each PVC so volumes used
+		// together are grouped below
+		volumeWorkloads := make(map[string][]string)
 		for _, pv := range csiPVs {
 			pvState := migrationv1alpha1.PVMigrationState{
 				PVName:           pv.Name,
+				PVPhase:          string(pv.Phase),
 				SourceVolumePath: pv.VolumeHandle,
 				Status:           PVStatusPending,
 			}
 
-			// Add PVC info if bound
-			if pv.ClaimRef != nil {
+			// Add PVC info if bound. The claimRef of a Released or Failed PV names a claim that is
+			// gone, so it is not followed.
+			if openshift.IsUnboundPhase(pv.Phase) {
+				logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+					fmt.Sprintf("PV %s is %s and has no workloads, it is migrated without quiesce or PVC handling", pv.Name, pv.Phase),
+					string(p.Name()))
+			} else if pv.ClaimRef != nil {
 				pvState.PVCName = pv.ClaimRef.Name
 				pvState.PVCNamespace = pv.ClaimRef.Namespace
+				pvState.InPlaceHandleSwap = inPlaceHandleSwap(migration)
+
+				// Per-replica StatefulSet claims are migrated as a group
+				sts, err := workloadManager.FindStatefulSetForPVC(ctx, pvState.PVCNamespace, pvState.PVCName)
+				if err != nil {
+					return &PhaseResult{
+						Status:  migrationv1alpha1.PhaseStatusFailed,
+						Message: "Failed to look up StatefulSet for PVC: " + err.Error(),
+						Logs:    logs,
+					}, err
+				}
+				if sts != nil {
+					pvState.WorkloadGroup = fmt.Sprintf("StatefulSet/%s/%s", sts.Namespace, sts.Name)
+				}
+			}
+
+			// vSAN file shares have no disk to relocate
+			if openshift.IsFileVolume(pv) {
+				finishVolume(&pvState, PVStatusSkipped, fileVolumeSkipMessage)
+				migration.Status.CSIVolumeMigration.SkippedVolumes++
+				logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+					fmt.Sprintf("Skipped PV %s: %s", pvState.PVName, pvState.Message),
+					string(p.Name()))
+			} else if pvState.PVCName != "" {
+				workloads, err := workloadManager.WorkloadsUsingPVC(ctx, pvState.PVCNamespace, pvState.PVCName)
+				if err != nil {
+					return &PhaseResult{
+						Status:  migrationv1alpha1.PhaseStatusFailed,
+						Message: "Failed to find workloads using PVC: " + err.Error(),
+						Logs:    logs,
+					}, err
+				}
+				volumeWorkloads[pvState.PVName] = workloadKeys(pvState.PVCNamespace, workloads)
 			}
 
 			migration.Status.CSIVolumeMigration.Volumes = append(migration.Status.CSIVolumeMigration.Volumes, pvState)
 		}
 
+		// Pods mounting several of the volumes are scaled down once for all of them and only
+		// restored once every one is migrated
+		groups := WorkloadGroups(volumeWorkloads)
+		for i := range migration.Status.CSIVolumeMigration.Volumes {
+			pvState := &migration.Status.CSIVolumeMigration.Volumes[i]
+			if group, ok := groups[pvState.PVName]; ok {
+				pvState.WorkloadGroup = group
+			}
+		}
+		for _, group := range slices.Compact(slices.Sorted(maps.Values(groups))) {
+			var members []string
+			for _, pvState := range migration.Status.CSIVolumeMigration.Volumes {
+				if pvState.WorkloadGroup == group {
+					members = append(members, pvState.PVName)
+				}
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("PVs %s are used by the same workloads and are migrated together as %s", strings.Join(members, ", "), group),
+				string(p.Name()))
+		}
+
 		migration.Status.CSIVolumeMigration.TotalVolumes = int32(len(csiPVs))
 		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 			fmt.Sprintf("Discovered %d vSphere CSI volumes", len(csiPVs)),
@@ -126,7 +235,7 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 	// Get source and target vCenter clients
 	targetFailureDomain := migration.Spec.FailureDomains[0]
 
-	sourceVCenter, err := p.executor.infraManager.GetSourceVCenter(ctx)
+	sourceVCenter, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -141,7 +250,7 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to connect to source vCenter: " + err.Error(),
 			Logs:    logs,
-		}, err
+		}, vsphere.ClassifyError(err)
 	}
 	defer sourceClient.Logout(ctx)
 
@@ -151,31 +260,63 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Failed to connect to target vCenter: " + err.Error(),
 			Logs:    logs,
-		}, err
+		}, vsphere.ClassifyError(err)
 	}
 	defer targetClient.Logout(ctx)
 
-	// Create managers
-	workloadManager := openshift.NewWorkloadManager(p.executor.kubeClient)
+	batchSize := RelocateBatchSize(migration)
 
 	// Process each volume
 	for i := range migration.Status.CSIVolumeMigration.Volumes {
 		pvState := &migration.Status.CSIVolumeMigration.Volumes[i]
 
-		// Skip completed or failed volumes
-		if pvState.Status == PVStatusComplete || pvState.Status == PVStatusFailed {
+		// Skip completed, failed or skipped volumes
+		if pvState.Status == PVStatusComplete || pvState.Status == PVStatusFailed || pvState.Status == PVStatusSkipped || pvState.Status == PVStatusSourceMissing {
 			continue
 		}
 
 		logger.Info("Processing CSI volume", "pv", pvState.PVName, "status", pvState.Status)
 
+		// Volumes whose workloads were restored complete once those are ready
+		if pvState.Status == PVStatusWorkloadsRestored {
+			logs = p.awaitRestoredWorkloads(ctx, workloadManager, targetClient, migration, pvState, restoredWorkloads(migration.Status.CSIVolumeMigration, pvState), logs)
+			continue
+		}
+
 		// Step 1: Set PV reclaim policy to Retain
 		if pvState.Status == PVStatusPending {
+			if pvState.StartTime == nil {
+				now := metav1.Now()
+				pvState.StartTime = &now
+			}
+
+			// A volume whose FCD was deleted out of band is reported and left untouched. Other
+			// lookup errors are left to the steps that need the FCD.
+			if err := p.checkSourceFCD(ctx, sourceClient, pvState); errors.Is(err, vsphere.ErrFCDNotFound) {
+				logs = p.markSourceMissing(migration, pvState, err, logs)
+				continue
+			}
+
+			// Shared disks cannot be detached from one VM and moved without corrupting the
+			// others' view of them, so they are left on the source untouched
+			reason, err := p.sharedVolumeReason(ctx, pvManager, sourceClient, migration, pvState)
+			if err != nil {
+				FailVolume(migration.Status.CSIVolumeMigration, pvState, "Failed to check whether the volume is shared: "+err.Error())
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+				continue
+			}
+			if reason != "" {
+				finishVolume(pvState, PVStatusSkipped, reason+" - shared disks are not migrated, move it manually")
+				migration.Status.CSIVolumeMigration.SkippedVolumes++
+				logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+					fmt.Sprintf("Skipped PV %s: %s", pvState.PVName, pvState.Message),
+					string(p.Name()))
+				continue
+			}
+
 			originalPolicy, err := pvManager.UpdatePVReclaimPolicy(ctx, pvState.PVName, corev1.PersistentVolumeReclaimRetain)
 			if err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to set PV reclaim policy to Retain: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+				FailVolume(migration.Status.CSIVolumeMigration, pvState, "Failed to set PV reclaim policy to Retain: "+err.Error())
 				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
 				continue
 			}
@@ -186,15 +327,25 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 				string(p.Name()))
 		}
 
+		// Unbound volumes have no workloads to quiesce and no PVC to delete
+		if pvState.Status == PVStatusRetainSet && UnboundVolume(pvState) {
+			pvState.Status = PVStatusPVCDeleted
+		}
+
 		// Step 2: Quiesce workloads and backup PVC spec
 		if pvState.Status == PVStatusRetainSet {
 			if err := p.quiesceVolume(ctx, pvManager, workloadManager, pvState); err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to quiesce workloads: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+				FailVolume(migration.Status.CSIVolumeMigration, pvState, "Failed to quiesce workloads: "+err.Error())
 				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
 				continue
 			}
+			if pvState.Status == PVStatusSkipped {
+				migration.Status.CSIVolumeMigration.SkippedVolumes++
+				logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+					fmt.Sprintf("Skipped PV %s: %s", pvState.PVName, pvState.Message),
+					string(p.Name()))
+				continue
+			}
 			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 				fmt.Sprintf("Quiesced workloads for PV %s (workloadType=%s)", pvState.PVName, pvState.WorkloadType),
 				string(p.Name()))
@@ -202,10 +353,8 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 
 		// Step 3: Delete PVC (after pods terminated)
 		if pvState.Status == PVStatusQuiesced {
-			if err := p.deletePVC(ctx, pvManager, pvState); err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to delete PVC: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+			if err := p.deletePVC(ctx, pvManager, migration, pvState); err != nil {
+				FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to delete PVC", phaseerrors.DataSafety(err))
 				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
 				logger.Error(nil, "PVC deletion failed, workloads remain scaled down",
 					"pv", pvState.PVName)
@@ -214,18 +363,29 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 					string(p.Name()))
 				continue
 			}
-			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-				fmt.Sprintf("Deleted PVC for PV %s", pvState.PVName),
-				string(p.Name()))
+			message := fmt.Sprintf("Deleted PVC for PV %s", pvState.PVName)
+			if keepsPVC(pvState) {
+				message = fmt.Sprintf("Kept PVC for PV %s, volume detached", pvState.PVName)
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, message, string(p.Name()))
 		}
 
-		// Step 4: Relocate the volume
+		// Step 4: Relocate the volume. Batched volumes are relocated together below once
+		// every volume has had the chance to reach this step.
+		if pvState.Status == PVStatusPVCDeleted && batchSize > 1 {
+			continue
+		}
 		if pvState.Status == PVStatusPVCDeleted {
-			if err := p.relocateVolume(ctx, sourceClient, targetClient, migration, pvState); err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to relocate volume: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+			switch err := p.relocateVolume(ctx, sourceClient, targetClient, migration, pvState); {
+			case errors.Is(err, vsphere.ErrFCDNotFound):
+				if logs = p.resumeFromTarget(ctx, targetClient, migration, pvState, err, logs); pvState.Status != PVStatusRelocated {
+					continue
+				}
+			case err != nil:
+				FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to relocate volume", phaseerrors.DataSafety(err))
 				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+				logs = AddVSphereFaultLogs(logs, sourceClient, sourceVCenter.Server, string(p.Name()))
+				logs = AddVSphereFaultLogs(logs, targetClient, targetFailureDomain.Server, string(p.Name()))
 
 				// DO NOT restore workloads on relocation failure - volume may be in inconsistent state
 				// Workloads remain scaled down to prevent data loss
@@ -236,19 +396,19 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 					fmt.Sprintf("Workloads for PV %s remain scaled down due to migration failure - manual intervention required", pvState.PVName),
 					string(p.Name()))
 				continue
+			default:
+				logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+					fmt.Sprintf("Relocated PV %s to target vCenter", pvState.PVName),
+					string(p.Name()))
 			}
-			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-				fmt.Sprintf("Relocated PV %s to target vCenter", pvState.PVName),
-				string(p.Name()))
 		}
 
 		// Step 5: Register with CNS on target
 		if pvState.Status == PVStatusRelocated {
-			if err := p.registerVolume(ctx, targetClient, migration, pvState); err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to register volume with CNS: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+			if err := p.registerVolume(ctx, pvManager, targetClient, migration, pvState); err != nil {
+				FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to register volume with CNS", phaseerrors.DataSafety(err))
 				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+				logs = AddVSphereFaultLogs(logs, targetClient, targetFailureDomain.Server, string(p.Name()))
 				// Workloads remain scaled down - volume exists on target but not registered
 				logger.Error(nil, "CNS registration failed, workloads remain scaled down",
 					"pv", pvState.PVName)
@@ -260,14 +420,36 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 				fmt.Sprintf("Registered PV %s with target CNS", pvState.PVName),
 				string(p.Name()))
+			logs = p.restoreVolumeMetadata(ctx, targetClient, pvState, logs)
 		}
 
-		// Step 6: Update PV volumeHandle and clear claimRef
+		// Step 6: Update PV volumeHandle once the data is known to be unchanged. An unbound
+		// volume keeps its phase and claimRef and is complete once its reclaim policy is restored.
+		if pvState.Status == PVStatusRegistered {
+			if err := p.VerifyTargetChecksum(ctx, targetClient, migration, pvState); err != nil {
+				FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to verify volume integrity", phaseerrors.DataSafety(err))
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+				logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+					fmt.Sprintf("Workloads for PV %s remain scaled down - volume integrity check failed", pvState.PVName),
+					string(p.Name()))
+				continue
+			}
+		}
+		if pvState.Status == PVStatusRegistered && UnboundVolume(pvState) {
+			if err := p.updateUnboundPV(ctx, pvManager, migration, pvState); err != nil {
+				FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to update PV", phaseerrors.DataSafety(err))
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+				continue
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Updated %s PV %s volumeHandle", pvState.PVPhase, pvState.PVName),
+				string(p.Name()))
+			logs = p.completeVolume(ctx, targetClient, migration, pvState, "Volume migrated successfully", logs)
+			continue
+		}
 		if pvState.Status == PVStatusRegistered {
-			if err := p.updatePVAndClearClaimRef(ctx, pvManager, pvState); err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to update PV: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+			if err := p.updatePVAndClearClaimRef(ctx, pvManager, migration, pvState); err != nil {
+				FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to update PV", phaseerrors.DataSafety(err))
 				logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
 				// Workloads remain scaled down - PV still points to old location
 				logger.Error(nil, "PV update failed, workloads remain scaled down",
@@ -283,11 +465,12 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 		}
 
 		// Step 7: Recreate PVC (for non-StatefulSet workloads) and restore workloads
-		if pvState.Status == PVStatusPVUpdated {
-			if err := p.restorePVCAndWorkloads(ctx, pvManager, workloadManager, pvState); err != nil {
-				pvState.Status = PVStatusFailed
-				pvState.Message = "Failed to restore PVC/workloads: " + err.Error()
-				migration.Status.CSIVolumeMigration.FailedVolumes++
+		if pvState.Status == PVStatusPVUpdated && pvState.WorkloadGroup != "" {
+			// StatefulSet volumes are restored together once every replica's volume has settled
+			logs = p.restoreWorkloadGroup(ctx, pvManager, workloadManager, targetClient, migration, pvState.WorkloadGroup, logs)
+		} else if pvState.Status == PVStatusPVUpdated {
+			if err := p.restorePVCAndWorkloads(ctx, pvManager, workloadManager, migration, pvState); err != nil {
+				FailVolume(migration.Status.CSIVolumeMigration, pvState, "Failed to restore PVC/workloads: "+err.Error())
 				logger.Error(err, "Failed to restore PVC/workloads after successful migration",
 					"pv", pvState.PVName,
 					"workloadType", pvState.WorkloadType)
@@ -297,26 +480,60 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 				continue
 			}
 
-			pvState.Status = PVStatusComplete
-			pvState.Message = "Volume migrated successfully"
-			migration.Status.CSIVolumeMigration.MigratedVolumes++
-			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-				fmt.Sprintf("Successfully migrated PV %s", pvState.PVName),
-				string(p.Name()))
+			logs = p.awaitRestoredWorkloads(ctx, workloadManager, targetClient, migration, pvState, pvState.ScaledDownResources, logs)
+		}
+	}
+
+	// Relocate the next batch; its volumes are registered and restored on the following sync
+	if batchSize > 1 {
+		var batchFailed bool
+		logs, batchFailed = p.relocateNextBatch(ctx, sourceClient, targetClient, migration, batchSize, logs)
+		if batchFailed {
+			logs = AddVSphereFaultLogs(logs, sourceClient, sourceVCenter.Server, string(p.Name()))
+			logs = AddVSphereFaultLogs(logs, targetClient, targetFailureDomain.Server, string(p.Name()))
 		}
 	}
 
-	// Calculate progress
+	// Calculate progress. Volumes missing on the source are finished too, and reported with the
+	// skipped ones.
 	total := migration.Status.CSIVolumeMigration.TotalVolumes
 	migrated := migration.Status.CSIVolumeMigration.MigratedVolumes
 	failed := migration.Status.CSIVolumeMigration.FailedVolumes
+	skipped := migration.Status.CSIVolumeMigration.SkippedVolumes
+	missing := int32(len(migration.Status.CSIVolumeMigration.MissingSourceVolumes))
 	progress := int32(0)
 	if total > 0 {
-		progress = int32((migrated + failed) * 100 / total)
+		progress = int32((migrated + failed + skipped + missing) * 100 / total)
 	}
 
 	// Check if all volumes are processed
-	if migrated+failed >= total {
+	if migrated+failed+skipped+missing >= total {
+		// Volumes left on the source are reported in every summary so they are not overlooked
+		for _, pv := range migration.Status.CSIVolumeMigration.Volumes {
+			if pv.Status == PVStatusSkipped {
+				logger.Info("Skipped volume details",
+					"pv", pv.PVName,
+					"pvc", fmt.Sprintf("%s/%s", pv.PVCNamespace, pv.PVCName),
+					"reason", pv.Message)
+				logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+					fmt.Sprintf("PV %s was not migrated and remains on the source vCenter: %s", pv.PVName, pv.Message),
+					string(p.Name()))
+			}
+		}
+		for _, item := range migration.Status.CSIVolumeMigration.MissingSourceVolumes {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("PV %s was not migrated, its FCD no longer exists on the source vCenter: %s", item.PVName, item.Hint),
+				string(p.Name()))
+		}
+
+		// StatefulSets with replica volumes on both vCenters stay scaled down until resolved
+		for _, partial := range migration.Status.CSIVolumeMigration.PartiallyMigratedWorkloads {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("%s remains scaled down: %d volume(s) migrated, not migrated: %s",
+					partial.WorkloadGroup, len(partial.MigratedVolumes), strings.Join(partial.UnmigratedVolumes, "; ")),
+				string(p.Name()))
+		}
+
 		if failed > 0 {
 			// Log prominent failure message
 			logger.Info("========================================")
@@ -332,7 +549,14 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 						"pv", pv.PVName,
 						"pvc", fmt.Sprintf("%s/%s", pv.PVCNamespace, pv.PVCName),
 						"error", pv.Message,
-						"scaledDownResources", len(pv.ScaledDownResources))
+						"scaledDownResources", len(pv.ScaledDownResources),
+						"snapshotID", pv.SnapshotID,
+						"duration", pv.Duration)
+					if pv.SnapshotID != "" {
+						logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+							fmt.Sprintf("Pre-migration snapshot %s of PV %s (FCD %s) retained for recovery", pv.SnapshotID, pv.PVName, pv.SourceVolumeID),
+							string(p.Name()))
+					}
 				}
 			}
 			logger.Info("========================================")
@@ -352,6 +576,21 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 			}, nil
 		}
 
+		if skipped > 0 || missing > 0 {
+			summary := fmt.Sprintf("Migrated %d CSI volumes, skipped %d", migrated, skipped)
+			if missing > 0 {
+				summary += fmt.Sprintf(", %d missing on the source", missing)
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning, summary, string(p.Name()))
+
+			return &PhaseResult{
+				Status:   migrationv1alpha1.PhaseStatusCompleted,
+				Message:  summary + " - see volume status for details",
+				Progress: 100,
+				Logs:     logs,
+			}, nil
+		}
+
 		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 			fmt.Sprintf("Successfully migrated all %d CSI volumes", migrated),
 			string(p.Name()))
@@ -364,6 +603,17 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 		}, nil
 	}
 
+	// Estimate the remaining time from the volumes migrated so far
+	if eta, average, ok := EstimateRemainingVolumeTime(migration.Status.CSIVolumeMigration); ok {
+		logger.Info("Estimated time remaining for CSI volume migration",
+			"remaining", total-migrated-failed-skipped,
+			"averageVolumeDuration", average,
+			"eta", eta)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Estimated %s remaining for %d volumes (average %s per volume)", eta, total-migrated-failed-skipped, average),
+			string(p.Name()))
+	}
+
 	// Still processing, requeue
 	return &PhaseResult{
 		Status:       migrationv1alpha1.PhaseStatusRunning,
@@ -374,302 +624,1389 @@ func (p *MigrateCSIVolumesPhase) Execute(ctx context.Context, migration *migrati
 	}, nil
 }
 
-// quiesceVolume scales down workloads using the volume and backs up PVC spec
-func (p *MigrateCSIVolumesPhase) quiesceVolume(ctx context.Context, pvManager *openshift.PersistentVolumeManager, workloadManager *openshift.WorkloadManager, pvState *migrationv1alpha1.PVMigrationState) error {
+// completeVolume marks a volume as successfully migrated and removes its pre-migration snapshot
+func (p *MigrateCSIVolumesPhase) completeVolume(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, message string, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
 	logger := klog.FromContext(ctx)
 
-	if pvState.PVCNamespace == "" || pvState.PVCName == "" {
-		// No PVC bound, nothing to quiesce
-		pvState.Status = PVStatusQuiesced
-		return nil
+	finishVolume(pvState, PVStatusComplete, message)
+	migration.Status.CSIVolumeMigration.MigratedVolumes++
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Successfully migrated PV %s in %s", pvState.PVName, pvState.Duration.Duration.Round(time.Second)),
+		string(p.Name()))
+
+	// The pre-migration snapshot is no longer needed once the volume is in use on the target
+	if pvState.SnapshotID != "" {
+		if err := p.deleteVolumeSnapshot(ctx, targetClient, pvState); err != nil {
+			logger.Error(err, "Failed to delete pre-migration snapshot", "pv", pvState.PVName, "snapshotID", pvState.SnapshotID)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("Failed to delete pre-migration snapshot %s of PV %s: %v", pvState.SnapshotID, pvState.PVName, err),
+				string(p.Name()))
+		}
 	}
 
-	logger.Info("Quiescing workloads for PVC", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+	return logs
+}
 
-	// Scale down workloads
-	scaledResources, err := workloadManager.ScaleDownForPV(ctx, pvState.PVCNamespace, pvState.PVCName)
-	if err != nil {
-		return fmt.Errorf("failed to scale down workloads: %w", err)
-	}
+// awaitRestoredWorkloads completes a volume whose workloads were restored once they are ready.
+// Without a workload readiness timeout it completes right away; otherwise the volume is left
+// WorkloadsRestored, and checked again on the next reconcile, until they are ready or the
+// deadline recorded in its status passes.
+func (p *MigrateCSIVolumesPhase) awaitRestoredWorkloads(ctx context.Context, workloadManager *openshift.WorkloadManager, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, scaledResources []migrationv1alpha1.ScaledResource, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	timeout := workloadReadinessTimeout(migration)
+	if pvState.WorkloadsReadyDeadline == nil && (timeout == 0 || len(scaledResources) == 0) {
+		return p.completeVolume(ctx, targetClient, migration, pvState, "Volume migrated successfully", logs)
+	}
+	if pvState.WorkloadsReadyDeadline == nil {
+		deadline := metav1.NewTime(time.Now().Add(timeout))
+		pvState.WorkloadsReadyDeadline = &deadline
+		pvState.Status = PVStatusWorkloadsRestored
+		pvState.Message = "Waiting for restored workloads to become ready"
+	}
+
+	unready := workloadManager.UnreadyWorkloads(ctx, scaledResources)
+	if len(unready) > 0 && time.Now().Before(pvState.WorkloadsReadyDeadline.Time) {
+		klog.FromContext(ctx).V(2).Info("Waiting for restored workloads to become ready",
+			"pv", pvState.PVName, "unready", unready, "deadline", pvState.WorkloadsReadyDeadline.Time)
+		return logs
+	}
+	return p.completeRestoredVolume(ctx, targetClient, migration, pvState, unready, logs)
+}
 
-	pvState.ScaledDownResources = scaledResources
+// AwaitRestoredWorkloads is a public wrapper for testing
+func (p *MigrateCSIVolumesPhase) AwaitRestoredWorkloads(ctx context.Context, workloadManager *openshift.WorkloadManager, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) []migrationv1alpha1.LogEntry {
+	return p.awaitRestoredWorkloads(ctx, workloadManager, targetClient, migration, pvState, restoredWorkloads(migration.Status.CSIVolumeMigration, pvState), nil)
+}
 
-	// Identify workload type from scaled resources
-	pvState.WorkloadType = identifyWorkloadType(scaledResources)
-	logger.Info("Identified workload type", "pv", pvState.PVName, "workloadType", pvState.WorkloadType)
+// restoredWorkloads returns the workloads restored for a volume: those of its workload group, or
+// the ones it scaled down itself
+func restoredWorkloads(status *migrationv1alpha1.CSIVolumeMigrationStatus, pvState *migrationv1alpha1.PVMigrationState) []migrationv1alpha1.ScaledResource {
+	if pvState.WorkloadGroup == "" {
+		return pvState.ScaledDownResources
+	}
+	return workloadGroupResources(status, pvState.WorkloadGroup)
+}
 
-	// Backup PVC spec for non-StatefulSet workloads
-	// StatefulSet controller will recreate PVCs from volumeClaimTemplates
-	if pvState.WorkloadType != "StatefulSet" {
-		pvcSpec, err := pvManager.BackupPVCSpec(ctx, pvState.PVCNamespace, pvState.PVCName)
-		if err != nil {
-			return fmt.Errorf("failed to backup PVC spec: %w", err)
+// workloadGroupResources returns the resources scaled down for a workload group, by whichever of
+// its volumes was quiesced first
+func workloadGroupResources(status *migrationv1alpha1.CSIVolumeMigrationStatus, group string) []migrationv1alpha1.ScaledResource {
+	var scaledResources []migrationv1alpha1.ScaledResource
+	for _, member := range status.Volumes {
+		if member.WorkloadGroup != group {
+			continue
+		}
+		for _, resource := range member.ScaledDownResources {
+			if !slices.Contains(scaledResources, resource) {
+				scaledResources = append(scaledResources, resource)
+			}
 		}
-		pvState.PVCSpec = pvcSpec
-		logger.Info("Backed up PVC spec", "pv", pvState.PVName, "pvc", pvState.PVCName)
 	}
+	return scaledResources
+}
 
-	// Wait for pods to terminate
-	if len(scaledResources) > 0 {
-		if err := workloadManager.WaitForPodsTerminated(ctx, pvState.PVCNamespace, pvState.PVCName, 5*time.Minute); err != nil {
-			return fmt.Errorf("timeout waiting for pods to terminate: %w", err)
-		}
+// completeRestoredVolume marks a volume whose workloads were restored as migrated, with a warning
+// listing the workloads that did not become ready
+func (p *MigrateCSIVolumesPhase) completeRestoredVolume(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, unready []string, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	if len(unready) == 0 {
+		return p.completeVolume(ctx, targetClient, migration, pvState, "Volume migrated successfully", logs)
 	}
 
-	pvState.Status = PVStatusQuiesced
-	return nil
+	pvState.UnreadyWorkloads = unready
+	summary := strings.Join(unready, ", ")
+	logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+		fmt.Sprintf("PV %s was migrated but its workloads are not ready after %s: %s - check that the volume mounts on the target",
+			pvState.PVName, workloadReadinessTimeout(migration), summary),
+		string(p.Name()))
+	return p.completeVolume(ctx, targetClient, migration, pvState, "Volume migrated; workloads not ready: "+summary, logs)
 }
 
-// identifyWorkloadType determines the primary workload type from scaled resources
-func identifyWorkloadType(scaledResources []migrationv1alpha1.ScaledResource) string {
-	for _, r := range scaledResources {
-		// StatefulSet takes precedence as it has special PVC handling
-		if r.Kind == "StatefulSet" {
-			return "StatefulSet"
+// restoreWorkloadGroup restores the workloads shared by a group of volumes once every volume in
+// the group has settled, so they are scaled up exactly once. The group is treated as
+// all-or-nothing: if any volume in the group failed or was skipped, its workloads stay scaled
+// down for manual intervention rather than start with volumes split between the vCenters.
+func (p *MigrateCSIVolumesPhase) restoreWorkloadGroup(ctx context.Context, pvManager *openshift.PersistentVolumeManager, workloadManager *openshift.WorkloadManager, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, group string, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	logger := klog.FromContext(ctx)
+
+	settled, members, unmigrated := WorkloadGroupVolumes(migration.Status.CSIVolumeMigration, group)
+	if !settled {
+		logger.V(2).Info("Waiting for remaining volumes of workload group", "group", group)
+		return logs
+	}
+
+	scaledResources := workloadGroupResources(migration.Status.CSIVolumeMigration, group)
+
+	if len(unmigrated) > 0 {
+		partial := migrationv1alpha1.PartiallyMigratedWorkload{WorkloadGroup: group, UnmigratedVolumes: unmigrated}
+		for _, member := range members {
+			partial.MigratedVolumes = append(partial.MigratedVolumes, member.PVName)
+		}
+		recordPartiallyMigratedWorkload(migration.Status.CSIVolumeMigration, partial)
+
+		summary := strings.Join(unmigrated, "; ")
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Workloads of %s remain scaled down because %d of its volumes were not migrated: %s - manual intervention required", group, len(unmigrated), summary),
+			string(p.Name()))
+		for _, member := range members {
+			logs = p.completeVolume(ctx, targetClient, migration, member,
+				fmt.Sprintf("Volume migrated; %s remains scaled down because other volumes of it were not migrated", group), logs)
+			recordIntervention(migration.Status.CSIVolumeMigration, member, PVStatusPVUpdated, member.Message,
+				fmt.Sprintf("The volume was migrated but %s remains scaled down until its other volumes are resolved (%s); scale it back up once every volume of the group is usable", group, summary))
 		}
+		return logs
 	}
-	for _, r := range scaledResources {
-		if r.Kind == "Deployment" {
-			return "Deployment"
+
+	// Rebind the kept PVCs and recreate the others. Every PVC is attempted so a
+	// failure leaves no other migrated volume without its claim.
+	var pvcFailed bool
+	for _, member := range members {
+		restore := restorePVC
+		if keepsPVC(member) {
+			restore = rebindPVC
+		}
+		if err := restore(ctx, pvManager, migration, member); err != nil {
+			pvcFailed = true
+			FailVolume(migration.Status.CSIVolumeMigration, member, "Failed to restore PVC: "+err.Error())
+			logs = AddLog(logs, migrationv1alpha1.LogLevelError,
+				fmt.Sprintf("Failed to restore PVC for PV %s: %v - workloads of %s remain scaled down", member.PVName, err, group),
+				string(p.Name()))
 		}
 	}
-	for _, r := range scaledResources {
-		if r.Kind == "ReplicaSet" {
-			return "ReplicaSet"
+	if pvcFailed {
+		return logs
+	}
+
+	logger.Info("All volumes of workload group migrated, restoring workloads", "group", group, "volumes", len(members))
+	if err := workloadManager.RestoreWorkloads(ctx, scaledResources); err != nil {
+		logger.Error(err, "Failed to restore workloads of workload group", "group", group)
+		for _, member := range members {
+			FailVolume(migration.Status.CSIVolumeMigration, member, "Failed to restore workloads: "+err.Error())
 		}
+		return AddLog(logs, migrationv1alpha1.LogLevelError,
+			fmt.Sprintf("Failed to restore workloads of %s: %v - manual intervention required", group, err),
+			string(p.Name()))
 	}
-	if len(scaledResources) > 0 {
-		return scaledResources[0].Kind
+
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Restored workloads of %s after migrating its %d volumes", group, len(members)),
+		string(p.Name()))
+	for _, member := range members {
+		if err := RestoreReclaimPolicy(ctx, pvManager, member); err != nil {
+			logger.Error(err, "Failed to restore reclaim policy of migrated volume", "pv", member.PVName)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("PV %s was migrated but keeps reclaim policy Retain: %v", member.PVName, err),
+				string(p.Name()))
+		}
+		logs = p.awaitRestoredWorkloads(ctx, workloadManager, targetClient, migration, member, scaledResources, logs)
 	}
-	return "Unknown"
+	return logs
 }
 
-// deletePVC deletes the PVC after workloads are quiesced and waits for VolumeAttachment deletion
-// Implements automatic remediation for stuck VolumeAttachments using defense-in-depth verification
-func (p *MigrateCSIVolumesPhase) deletePVC(ctx context.Context, pvManager *openshift.PersistentVolumeManager, pvState *migrationv1alpha1.PVMigrationState) error {
-	logger := klog.FromContext(ctx)
+// WorkloadGroupVolumes reports whether every volume of a workload group has settled and, if so,
+// returns the volumes migrated and waiting for the workloads to be restored, and a description
+// of each volume that failed or was skipped
+func WorkloadGroupVolumes(status *migrationv1alpha1.CSIVolumeMigrationStatus, group string) (settled bool, migrated []*migrationv1alpha1.PVMigrationState, unmigrated []string) {
+	for i := range status.Volumes {
+		member := &status.Volumes[i]
+		if member.WorkloadGroup != group {
+			continue
+		}
 
-	if pvState.PVCNamespace == "" || pvState.PVCName == "" {
-		// No PVC bound, skip deletion
-		pvState.Status = PVStatusPVCDeleted
-		return nil
+		switch member.Status {
+		case PVStatusPVUpdated:
+			migrated = append(migrated, member)
+		case PVStatusFailed, PVStatusSkipped, PVStatusSourceMissing:
+			unmigrated = append(unmigrated, fmt.Sprintf("PV %s (PVC %s/%s) %s: %s",
+				member.PVName, member.PVCNamespace, member.PVCName, strings.ToLower(member.Status), member.Message))
+		case PVStatusWorkloadsRestored, PVStatusComplete:
+		default:
+			return false, nil, nil
+		}
 	}
+	return true, migrated, unmigrated
+}
 
-	logger.Info("Deleting PVC", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+// recordPartiallyMigratedWorkload adds or replaces the partially migrated entry of a workload group
+func recordPartiallyMigratedWorkload(status *migrationv1alpha1.CSIVolumeMigrationStatus, partial migrationv1alpha1.PartiallyMigratedWorkload) {
+	for i := range status.PartiallyMigratedWorkloads {
+		if status.PartiallyMigratedWorkloads[i].WorkloadGroup == partial.WorkloadGroup {
+			status.PartiallyMigratedWorkloads[i] = partial
+			return
+		}
+	}
+	status.PartiallyMigratedWorkloads = append(status.PartiallyMigratedWorkloads, partial)
+}
 
-	// Delete the PVC
-	if err := pvManager.DeletePVC(ctx, pvState.PVCNamespace, pvState.PVCName); err != nil {
-		return fmt.Errorf("failed to delete PVC: %w", err)
+// workloadKeys qualifies the Kind/name workloads using a PVC with its namespace
+func workloadKeys(namespace string, workloads []string) []string {
+	keys := make([]string, 0, len(workloads))
+	for _, workload := range workloads {
+		kind, name, _ := strings.Cut(workload, "/")
+		keys = append(keys, kind+"/"+namespace+"/"+name)
 	}
+	return keys
+}
 
-	// Wait for PVC to be fully deleted
-	if err := pvManager.WaitForPVCDeleted(ctx, pvState.PVCNamespace, pvState.PVCName, 2*time.Minute); err != nil {
-		return fmt.Errorf("timeout waiting for PVC deletion: %w", err)
+// WorkloadGroups groups the volumes whose PVCs are used by the same workloads, given the
+// workloads of each volume as Kind/namespace/name, and returns the group of every volume sharing
+// a workload with another volume. Workloads sharing a volume are grouped together too, so each
+// group can be scaled down once and restored once all of its volumes are migrated. A group is
+// named after its first StatefulSet, if any, so the per-replica claims of a StatefulSet keep the
+// group of their StatefulSet.
+func WorkloadGroups(volumeWorkloads map[string][]string) map[string]string {
+	parent := make(map[string]string)
+	var find func(key string) string
+	find = func(key string) string {
+		if parent[key] != key {
+			parent[key] = find(parent[key])
+		}
+		return parent[key]
+	}
+	for _, keys := range volumeWorkloads {
+		for _, key := range keys {
+			if _, ok := parent[key]; !ok {
+				parent[key] = key
+			}
+			parent[find(key)] = find(keys[0])
+		}
 	}
 
-	// Wait for VolumeAttachment to be deleted - confirms vSphere-level detachment
-	// This is critical: PVC deletion triggers async CSI ControllerUnpublishVolume which
-	// performs the actual vSphere detach. We must wait for VolumeAttachment deletion
-	// to confirm the VMDK is fully detached before attempting migration.
-	vaManager := openshift.NewVolumeAttachmentManager(p.executor.kubeClient)
-	detachErr := vaManager.WaitForVolumeDetached(ctx, pvState.PVName, 3*time.Minute)
+	// Name each set of connected workloads, preferring a StatefulSet
+	names := make(map[string]string)
+	for _, key := range slices.Sorted(maps.Keys(parent)) {
+		root := find(key)
+		name, ok := names[root]
+		if !ok || !strings.HasPrefix(name, "StatefulSet/") && strings.HasPrefix(key, "StatefulSet/") {
+			names[root] = key
+		}
+	}
 
-	if detachErr != nil {
-		// VolumeAttachment deletion timed out - this may indicate CSI driver lost internal state
-		// Perform defense-in-depth verification at vSphere level before force-detaching
-		logger.Info("========================================")
-		logger.Info("VOLUMEATTACHMENT DELETION TIMEOUT")
-		logger.Info("========================================")
-		logger.Info("VolumeAttachment deletion timed out, performing vSphere-level verification",
-			"pv", pvState.PVName,
-			"error", detachErr)
+	members := make(map[string][]string)
+	for pvName, keys := range volumeWorkloads {
+		if len(keys) > 0 {
+			root := find(keys[0])
+			members[root] = append(members[root], pvName)
+		}
+	}
 
-		// Attempt automatic remediation with vSphere-level safety verification
-		if err := p.remediateStuckVolumeAttachment(ctx, pvState, vaManager); err != nil {
-			// Remediation failed - return original timeout error
-			logger.Error(err, "Failed to remediate stuck VolumeAttachment",
-				"pv", pvState.PVName)
-			return fmt.Errorf("timeout waiting for volume detachment (VolumeAttachment deletion): %w", detachErr)
+	groups := make(map[string]string)
+	for root, pvNames := range members {
+		if len(pvNames) < 2 {
+			continue
+		}
+		for _, pvName := range pvNames {
+			groups[pvName] = names[root]
 		}
+	}
+	return groups
+}
 
-		logger.Info("Successfully remediated stuck VolumeAttachment", "pv", pvState.PVName)
+// RestoreReclaimPolicy puts back the reclaim policy a volume had before it was set to Retain for
+// the migration, so a migrated volume is deleted with its claim exactly as before
+func RestoreReclaimPolicy(ctx context.Context, pvManager *openshift.PersistentVolumeManager, pvState *migrationv1alpha1.PVMigrationState) error {
+	logger := klog.FromContext(ctx)
+
+	if pvState.OriginalReclaimPolicy == "" {
+		logger.V(2).Info("No original reclaim policy recorded, leaving it unchanged", "pv", pvState.PVName)
+		return nil
 	}
 
-	pvState.Status = PVStatusPVCDeleted
-	logger.Info("PVC deleted and volume detachment confirmed", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+	original := corev1.PersistentVolumeReclaimPolicy(pvState.OriginalReclaimPolicy)
+	previous, err := pvManager.UpdatePVReclaimPolicy(ctx, pvState.PVName, original)
+	if err != nil {
+		return fmt.Errorf("failed to restore reclaim policy %s: %w", original, err)
+	}
+
+	logger.Info("Restored original reclaim policy of migrated volume", "pv", pvState.PVName, "from", previous, "to", original)
 	return nil
 }
 
-// remediateStuckVolumeAttachment performs automatic remediation of stuck VolumeAttachment
-// Uses defense-in-depth verification at vSphere level before force-cleaning Kubernetes resource
-func (p *MigrateCSIVolumesPhase) remediateStuckVolumeAttachment(ctx context.Context, pvState *migrationv1alpha1.PVMigrationState, vaManager *openshift.VolumeAttachmentManager) error {
-	logger := klog.FromContext(ctx)
+// FailVolume marks a volume as failed at its current step and records the manual intervention
+// it needs in the migration status
+func FailVolume(status *migrationv1alpha1.CSIVolumeMigrationStatus, pvState *migrationv1alpha1.PVMigrationState, message string) {
+	step := pvState.Status
+	finishVolume(pvState, PVStatusFailed, message)
+	pvState.ErrorClass = ""
+	status.FailedVolumes++
+	hint := interventionHint(step, pvState)
+	if pvState.RetainedDummyVM != "" {
+		hint += fmt.Sprintf("; dummy VM %s was kept for inspection with the volume detached from it, the Cleanup phase deletes it", pvState.RetainedDummyVM)
+	}
+	recordIntervention(status, pvState, step, message, hint)
+}
 
-	logger.Info("Starting automatic remediation for stuck VolumeAttachment",
-		"pv", pvState.PVName)
+// FailVolumeError fails a volume with an error, recording the error's classification so rollback
+// can leave data safety failures to an operator
+func FailVolumeError(status *migrationv1alpha1.CSIVolumeMigrationStatus, pvState *migrationv1alpha1.PVMigrationState, message string, err error) {
+	FailVolume(status, pvState, message+": "+err.Error())
+	pvState.ErrorClass = phaseerrors.ClassName(err)
+}
 
-	// Parse FCD ID from volume handle
+// checkSourceFCD looks up the FCD of a volume on the source, returning vsphere.ErrFCDNotFound if
+// it no longer exists
+func (p *MigrateCSIVolumesPhase) checkSourceFCD(ctx context.Context, sourceClient *vsphere.Client, pvState *migrationv1alpha1.PVMigrationState) error {
 	fcdID, err := vsphere.ParseCSIVolumeHandle(pvState.SourceVolumePath)
 	if err != nil {
 		return fmt.Errorf("failed to parse volume handle: %w", err)
 	}
-
-	logger.Info("Parsed FCD ID from volume handle", "fcdID", fcdID)
-
-	// Get source vCenter client for verification
-	sourceVCenter, err := p.executor.infraManager.GetSourceVCenter(ctx)
+	fcdManager, err := vsphere.NewFCDManager(ctx, sourceClient)
 	if err != nil {
-		return fmt.Errorf("failed to get source vCenter: %w", err)
+		return fmt.Errorf("failed to create FCD manager: %w", err)
 	}
+	_, err = fcdManager.GetFCDByID(ctx, fcdID)
+	return err
+}
 
-	sourceClient, err := p.executor.GetVSphereClient(ctx, sourceVCenter.Server)
-	if err != nil {
-		return fmt.Errorf("failed to connect to source vCenter: %w", err)
+// markSourceMissing finishes a volume whose FCD no longer exists on the source and lists it
+// apart from the failed volumes: there is no data left to protect or migrate. It is only called
+// before anything was changed for the volume, so its PV and workloads are left untouched.
+func (p *MigrateCSIVolumesPhase) markSourceMissing(migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, err error, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	step := pvState.Status
+	hint := fmt.Sprintf("FCD %s of the volume no longer exists on the source vCenter and nothing was changed; delete PV %s, or recreate the volume, once its data is known to be gone", pvState.SourceVolumePath, pvState.PVName)
+
+	finishVolume(pvState, PVStatusSourceMissing, "Volume no longer exists on the source vCenter: "+err.Error())
+	pvState.ErrorClass = ""
+	logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+		fmt.Sprintf("PV %s: %s", pvState.PVName, pvState.Message), string(p.Name()))
+
+	status := migration.Status.CSIVolumeMigration
+	item := migrationv1alpha1.VolumeIntervention{
+		PVName:       pvState.PVName,
+		PVCName:      pvState.PVCName,
+		PVCNamespace: pvState.PVCNamespace,
+		FailedStep:   step,
+		Error:        err.Error(),
+		Hint:         hint,
+	}
+	for i := range status.MissingSourceVolumes {
+		if status.MissingSourceVolumes[i].PVName == pvState.PVName {
+			status.MissingSourceVolumes[i] = item
+			return logs
+		}
 	}
-	defer sourceClient.Logout(ctx)
+	status.MissingSourceVolumes = append(status.MissingSourceVolumes, item)
+	return logs
+}
+
+// resumeFromTarget handles a volume whose FCD was not found on the source at relocation. Its
+// workloads are already scaled down and its PVC deleted, so it is not reported missing: a
+// vMotion that succeeded but whose status was never written also leaves the FCD gone from the
+// source, and it then resumes at Relocated from the target. A volume on neither vCenter fails
+// with its workloads kept scaled down for an operator.
+func (p *MigrateCSIVolumesPhase) resumeFromTarget(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, err error, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	found, lookupErr := p.findOnTarget(ctx, targetClient, pvState)
+	switch {
+	case lookupErr != nil:
+		FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "FCD of the volume was not found on the source vCenter and looking it up on the target failed", phaseerrors.DataSafety(lookupErr))
+	case found:
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("FCD %s of PV %s is already on the target vCenter, resuming after its relocation", pvState.TargetVolumeID, pvState.PVName),
+			string(p.Name()))
+		return logs
+	default:
+		FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "FCD of the volume was found on neither the source nor the target vCenter", phaseerrors.DataSafety(err))
+	}
+
+	logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+	logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+		fmt.Sprintf("Workloads for PV %s remain scaled down - manual intervention required", pvState.PVName),
+		string(p.Name()))
+	return logs
+}
+
+// ResumeFromTarget is a public wrapper for testing
+func (p *MigrateCSIVolumesPhase) ResumeFromTarget(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, err error) []migrationv1alpha1.LogEntry {
+	return p.resumeFromTarget(ctx, targetClient, migration, pvState, err, nil)
+}
+
+// findOnTarget looks up the FCD of a volume on the target vCenter by its source ID, which a
+// vMotion keeps, and records it as relocated if it is there
+func (p *MigrateCSIVolumesPhase) findOnTarget(ctx context.Context, targetClient *vsphere.Client, pvState *migrationv1alpha1.PVMigrationState) (bool, error) {
+	fcdID, err := vsphere.ParseCSIVolumeHandle(pvState.SourceVolumePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse volume handle: %w", err)
+	}
+	fcdManager, err := vsphere.NewFCDManager(ctx, targetClient)
+	if err != nil {
+		return false, fmt.Errorf("failed to create target FCD manager: %w", err)
+	}
+	if _, err := fcdManager.GetFCDByID(ctx, fcdID); errors.Is(err, vsphere.ErrFCDNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	pvState.SourceVolumeID = fcdID
+	pvState.TargetVolumeID = fcdID
+	pvState.TargetVolumePath = vsphere.BuildCSIVolumeHandle(fcdID)
+	pvState.Status = PVStatusRelocated
+	return true, nil
+}
+
+// recordIntervention adds or replaces the manual intervention entry of a volume
+func recordIntervention(status *migrationv1alpha1.CSIVolumeMigrationStatus, pvState *migrationv1alpha1.PVMigrationState, step, message, hint string) {
+	intervention := migrationv1alpha1.VolumeIntervention{
+		PVName:              pvState.PVName,
+		PVCName:             pvState.PVCName,
+		PVCNamespace:        pvState.PVCNamespace,
+		FailedStep:          step,
+		Error:               message,
+		ScaledDownResources: slices.Clone(pvState.ScaledDownResources),
+		Hint:                hint,
+	}
+
+	for i := range status.ManualInterventionRequired {
+		if status.ManualInterventionRequired[i].PVName == pvState.PVName {
+			status.ManualInterventionRequired[i] = intervention
+			return
+		}
+	}
+	status.ManualInterventionRequired = append(status.ManualInterventionRequired, intervention)
+}
+
+// interventionHint describes how to remediate a volume that failed at the given step
+func interventionHint(step string, pvState *migrationv1alpha1.PVMigrationState) string {
+	switch step {
+	case PVStatusPending:
+		return "The PV reclaim policy could not be set to Retain and nothing was changed; fix the error and re-run the phase"
+	case PVStatusRetainSet:
+		return "Workloads using the PVC may be partially scaled down and the PV reclaim policy is Retain; scale the listed workloads back up, or fix the error and re-run the phase"
+	case PVStatusQuiesced:
+		return "The PVC could not be deleted and its workloads remain scaled down; check the PVC finalizers, the pods named in the error that still use it (or set forceDeleteBlockingPods) and VolumeAttachments, then re-run the phase or scale the workloads back up"
+	case PVStatusPVCDeleted:
+		if keepsPVC(pvState) {
+			return fmt.Sprintf("The PVC was kept but the volume (%s) was not relocated and is still on the source vCenter; re-run the phase, or scale the listed workloads back up", pvState.SourceVolumePath)
+		}
+		return fmt.Sprintf("The PVC was deleted but the volume (%s) was not relocated and is still on the source vCenter; re-run the phase, or recreate the PVC from the backup in the volume status bound to the PV and scale the workloads back up", pvState.SourceVolumePath)
+	case PVStatusRelocating:
+		return fmt.Sprintf("The vMotion did not complete; FCD %s may still be attached to dummy VM %s on either vCenter. Detach it, delete the dummy VM and check which vCenter holds the FCD before re-running the phase", pvState.SourceVolumeID, pvState.DummyVMName)
+	case PVStatusRelocated:
+		return fmt.Sprintf("The volume was relocated to the target vCenter but not registered; register FCD %s with CNS on the target manually or re-run the phase", pvState.TargetVolumeID)
+	case PVStatusRegistered:
+		if pvState.TargetChecksum != "" && pvState.TargetChecksum != pvState.SourceChecksum {
+			return fmt.Sprintf("The checksum of the volume on the target (%s) differs from the one taken on the source (%s) and the PV still refers to the source; compare the data of FCD %s on the target with the source before pointing the PV at it or re-running the phase", pvState.TargetChecksum, pvState.SourceChecksum, pvState.TargetVolumeID)
+		}
+		return fmt.Sprintf("The volume is registered on the target but the PV still refers to the source; update the PV volumeHandle to %s and clear its claimRef, or re-run the phase", pvState.TargetVolumePath)
+	case PVStatusPVUpdated:
+		if keepsPVC(pvState) {
+			return fmt.Sprintf("The volume was migrated but its kept PVC was not rebound or its workloads were not restored; make sure PVC %s/%s is bound to PV %s before scaling the listed workloads back up, so no new volume is provisioned for it", pvState.PVCNamespace, pvState.PVCName, pvState.PVName)
+		}
+		return "The volume was migrated but its PVC or workloads were not restored; recreate the PVC from the backup in the volume status if needed and scale the listed workloads back up"
+	default:
+		return "Inspect the volume status and controller logs"
+	}
+}
+
+// finishVolume moves a volume to a terminal status and records how long it took
+func finishVolume(pvState *migrationv1alpha1.PVMigrationState, status, message string) {
+	now := metav1.Now()
+	pvState.Status = status
+	pvState.Message = message
+	pvState.CompletionTime = &now
+	if pvState.StartTime != nil {
+		pvState.Duration = &metav1.Duration{Duration: now.Sub(pvState.StartTime.Time)}
+	} else {
+		pvState.Duration = &metav1.Duration{}
+	}
+}
+
+// EstimateRemainingVolumeTime projects the time left for unfinished volumes from the
+// average duration of the volumes that migrated successfully
+func EstimateRemainingVolumeTime(status *migrationv1alpha1.CSIVolumeMigrationStatus) (eta, average time.Duration, ok bool) {
+	var total time.Duration
+	completed, remaining := 0, 0
+	for _, pv := range status.Volumes {
+		switch pv.Status {
+		case PVStatusComplete:
+			if pv.Duration != nil {
+				total += pv.Duration.Duration
+				completed++
+			}
+		case PVStatusFailed, PVStatusSkipped, PVStatusSourceMissing:
+		default:
+			remaining++
+		}
+	}
+	if completed == 0 || remaining == 0 {
+		return 0, 0, false
+	}
+	average = (total / time.Duration(completed)).Round(time.Second)
+	return average * time.Duration(remaining), average, true
+}
+
+// quiesceVolume scales down workloads using the volume and backs up PVC spec
+func (p *MigrateCSIVolumesPhase) quiesceVolume(ctx context.Context, pvManager *openshift.PersistentVolumeManager, workloadManager *openshift.WorkloadManager, pvState *migrationv1alpha1.PVMigrationState) error {
+	logger := klog.FromContext(ctx)
+
+	if pvState.PVCNamespace == "" || pvState.PVCName == "" {
+		// No PVC bound, nothing to quiesce
+		pvState.Status = PVStatusQuiesced
+		return nil
+	}
+
+	// Deleting the PVC while it is being expanded can lose the resize or corrupt the filesystem,
+	// so leave the volume on the source untouched
+	resize, err := pvManager.GetVolumeResizeInProgress(ctx, pvState.PVName, pvState.PVCNamespace, pvState.PVCName)
+	if err != nil {
+		return fmt.Errorf("failed to check for volume resize: %w", err)
+	}
+	if resize != "" {
+		logger.Info("Volume resize in progress, skipping volume", "pv", pvState.PVName, "resize", resize)
+		return skipUnquiescedVolume(ctx, pvManager, pvState, "Volume resize in progress ("+resize+") - migrate after the resize completes")
+	}
+
+	logger.Info("Quiescing workloads for PVC", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+
+	// Scale down workloads. Protected workloads are never scaled down, so their volumes stay on the source.
+	scaledResources, err := workloadManager.ScaleDownForPV(ctx, pvState.PVCNamespace, pvState.PVCName)
+	if errors.Is(err, openshift.ErrQuiesceExcluded) {
+		logger.Info("Volume is used by workloads excluded from quiesce, skipping volume", "pv", pvState.PVName, "reason", err)
+		return skipUnquiescedVolume(ctx, pvManager, pvState, err.Error()+" - migrate it manually or remove the namespace from quiesceExcludeNamespaces")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scale down workloads: %w", err)
+	}
+
+	pvState.ScaledDownResources = scaledResources
+
+	// Identify workload type from scaled resources. Later volumes of a workload group find its
+	// workloads already scaled down, so their type comes from the group. Only the claims created
+	// from a StatefulSet's volumeClaimTemplates are left for the StatefulSet to recreate.
+	pvState.WorkloadType = identifyWorkloadType(scaledResources)
+	if pvState.WorkloadGroup != "" {
+		sts, err := workloadManager.FindStatefulSetForPVC(ctx, pvState.PVCNamespace, pvState.PVCName)
+		if err != nil {
+			return fmt.Errorf("failed to look up StatefulSet for PVC: %w", err)
+		}
+		groupKind, _, _ := strings.Cut(pvState.WorkloadGroup, "/")
+		switch {
+		case sts != nil:
+			pvState.WorkloadType = "StatefulSet"
+		case groupKind != "StatefulSet":
+			pvState.WorkloadType = groupKind
+		case pvState.WorkloadType == "StatefulSet" || len(scaledResources) == 0:
+			// A PVC mounted directly by the pods of a StatefulSet has to be recreated like any other
+			pvState.WorkloadType = "Pod"
+		}
+	}
+	logger.Info("Identified workload type", "pv", pvState.PVName, "workloadType", pvState.WorkloadType)
+
+	// Backup PVC spec for the PVCs that are deleted and recreated. Kept PVCs stay bound.
+	if !keepsPVC(pvState) {
+		pvcSpec, err := pvManager.BackupPVCSpec(ctx, pvState.PVCNamespace, pvState.PVCName)
+		if err != nil {
+			return fmt.Errorf("failed to backup PVC spec: %w", err)
+		}
+		pvState.PVCSpec = pvcSpec
+		logger.Info("Backed up PVC spec", "pv", pvState.PVName, "pvc", pvState.PVCName)
+	}
+
+	// Wait for pods to terminate, including pods of a workload group scaled down for an earlier volume
+	if len(scaledResources) > 0 || pvState.WorkloadGroup != "" {
+		if err := workloadManager.WaitForPodsTerminated(ctx, pvState.PVCNamespace, pvState.PVCName, 5*time.Minute); err != nil {
+			return fmt.Errorf("timeout waiting for pods to terminate: %w", err)
+		}
+	}
+
+	pvState.Status = PVStatusQuiesced
+	return nil
+}
+
+// sharedVolumeReason checks whether a volume is shared between VMs or pods, returning why it is
+// unsafe to migrate or an empty string for a volume with at most one attachment
+func (p *MigrateCSIVolumesPhase) sharedVolumeReason(ctx context.Context, pvManager *openshift.PersistentVolumeManager, sourceClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) (string, error) {
+	pv, err := pvManager.GetPV(ctx, pvState.PVName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PV: %w", err)
+	}
+
+	fcdID, err := vsphere.ParseCSIVolumeHandle(pvState.SourceVolumePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse volume handle: %w", err)
+	}
+
+	sourceFailureDomain, err := p.executor.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return "", fmt.Errorf("failed to get source failure domain: %w", err)
+	}
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, sourceClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to create FCD manager: %w", err)
+	}
+
+	folderPath := util.FailureDomainFolder(sourceFailureDomain.Topology, infraID)
+	attachments, err := fcdManager.CountFCDAttachments(ctx, sourceFailureDomain.Topology.Datacenter, folderPath, fcdID)
+	if err != nil {
+		return "", err
+	}
+
+	return SharedVolumeReason(pv, attachments), nil
+}
+
+// SharedVolumeReason describes why a volume is shared, from its PV and the VMs its FCD is attached
+// to, or returns an empty string when it has a single writer
+func SharedVolumeReason(pv *corev1.PersistentVolume, attachments []vsphere.FCDAttachment) string {
+	if openshift.IsSharedBlockVolume(pv) {
+		return "Volume is a ReadWriteMany block volume that pods on several nodes may write to"
+	}
+	if len(attachments) > 1 {
+		vms := make([]string, 0, len(attachments))
+		for _, attachment := range attachments {
+			vms = append(vms, attachment.VMName)
+		}
+		return fmt.Sprintf("Volume is attached to %d VMs (%s)", len(attachments), strings.Join(vms, ", "))
+	}
+	for _, attachment := range attachments {
+		if attachment.Shared() {
+			return fmt.Sprintf("Volume is attached to VM %s as a shared disk (sharing=%s, bus sharing=%s)",
+				attachment.VMName, attachment.Sharing, attachment.SharedBus)
+		}
+	}
+	return ""
+}
+
+// skipUnquiescedVolume skips a volume before any of its workloads were scaled down, restoring its
+// original reclaim policy
+func skipUnquiescedVolume(ctx context.Context, pvManager *openshift.PersistentVolumeManager, pvState *migrationv1alpha1.PVMigrationState, message string) error {
+	if pvState.OriginalReclaimPolicy != "" {
+		if _, err := pvManager.UpdatePVReclaimPolicy(ctx, pvState.PVName, corev1.PersistentVolumeReclaimPolicy(pvState.OriginalReclaimPolicy)); err != nil {
+			return fmt.Errorf("failed to restore reclaim policy of skipped volume: %w", err)
+		}
+	}
+	finishVolume(pvState, PVStatusSkipped, message)
+	return nil
+}
+
+// identifyWorkloadType determines the primary workload type from scaled resources
+func identifyWorkloadType(scaledResources []migrationv1alpha1.ScaledResource) string {
+	for _, r := range scaledResources {
+		// StatefulSet takes precedence as it has special PVC handling
+		if r.Kind == "StatefulSet" {
+			return "StatefulSet"
+		}
+	}
+	for _, r := range scaledResources {
+		if r.Kind == "Deployment" {
+			return "Deployment"
+		}
+	}
+	for _, r := range scaledResources {
+		if r.Kind == "ReplicaSet" {
+			return "ReplicaSet"
+		}
+	}
+	if len(scaledResources) > 0 {
+		return scaledResources[0].Kind
+	}
+	return "Unknown"
+}
+
+// deletePVC deletes the PVC after workloads are quiesced and waits for VolumeAttachment deletion
+// Implements automatic remediation for stuck VolumeAttachments using defense-in-depth verification
+func (p *MigrateCSIVolumesPhase) deletePVC(ctx context.Context, pvManager *openshift.PersistentVolumeManager, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	logger := klog.FromContext(ctx)
+
+	if pvState.PVCNamespace == "" || pvState.PVCName == "" {
+		// No PVC bound, skip deletion
+		pvState.Status = PVStatusPVCDeleted
+		return nil
+	}
+
+	// A pod rescheduled between discovery and quiesce may still have its attach in flight, and
+	// deleting the PVC under the CSI controller can leave a dangling VolumeAttachment. Detaches
+	// are waited for, and remediated when stuck, once the PVC is deleted.
+	vaManager := openshift.NewVolumeAttachmentManager(p.executor.kubeClient)
+	if err := vaManager.WaitForStableAttachmentState(ctx, pvState.PVName, attachmentSettleTimeout); err != nil {
+		return fmt.Errorf("volume attachment still changing: %w", err)
+	}
+
+	// A volume with no VolumeAttachment and no pods was never attached, or was detached
+	// already, so there is no detach to wait for once the PVC is gone
+	attached, err := vaManager.HasVolumeAttachment(ctx, pvState.PVName)
+	if err != nil {
+		return fmt.Errorf("failed to check for volume attachment: %w", err)
+	}
+	pods, err := pvManager.FindPodsUsingPVC(ctx, pvState.PVCNamespace, pvState.PVCName)
+	if err != nil {
+		return fmt.Errorf("failed to find pods using PVC: %w", err)
+	}
+	waitForDetach := attached || openshift.CountActivePods(pods) > 0
+
+	if keepsPVC(pvState) {
+		// The quiesced pods are gone, so the volume detaches without deleting the PVC
+		logger.Info("Keeping PVC, it stays bound to the migrated PV",
+			"namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+	} else {
+		logger.Info("Deleting PVC", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+
+		// Delete the PVC
+		if err := pvManager.DeletePVC(ctx, pvState.PVCNamespace, pvState.PVCName); err != nil {
+			return fmt.Errorf("failed to delete PVC: %w", err)
+		}
+
+		// Wait for PVC to be fully deleted. Pods that outlived the quiesce, such as ones on an
+		// unreachable node, keep it Terminating through its protection finalizer.
+		err := pvManager.WaitForPVCDeleted(ctx, pvState.PVCNamespace, pvState.PVCName, 2*time.Minute)
+		var blocked *openshift.PVCDeletionBlockedError
+		if errors.As(err, &blocked) && forceDeleteBlockingPods(migration) {
+			logger.Info("Force deleting pods keeping PVC Terminating",
+				"namespace", pvState.PVCNamespace, "name", pvState.PVCName, "pods", len(blocked.Pods))
+			if err := openshift.NewPodManager(p.executor.kubeClient).ForceDeletePods(ctx, blocked.Pods); err != nil {
+				return fmt.Errorf("failed to force delete pods blocking PVC deletion: %w", err)
+			}
+			err = pvManager.WaitForPVCDeleted(ctx, pvState.PVCNamespace, pvState.PVCName, 2*time.Minute)
+		}
+		if err != nil {
+			return fmt.Errorf("PVC deletion did not complete: %w", err)
+		}
+	}
+
+	if !waitForDetach {
+		pvState.Status = PVStatusPVCDeleted
+		logger.Info("PVC deleted, volume was not attached", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+		return nil
+	}
+
+	// Wait for VolumeAttachment to be deleted - confirms vSphere-level detachment
+	// This is critical: PVC deletion triggers async CSI ControllerUnpublishVolume which
+	// performs the actual vSphere detach. We must wait for VolumeAttachment deletion
+	// to confirm the VMDK is fully detached before attempting migration.
+	detachErr := vaManager.WaitForVolumeDetached(ctx, pvState.PVName, volumeDetachTimeout)
+	if detachErr != nil && !errors.Is(detachErr, context.DeadlineExceeded) {
+		// Cancelled, e.g. the controller is shutting down: an interrupted wait is no reason to
+		// force-detach the volume
+		return fmt.Errorf("waiting for volume detachment interrupted: %w", detachErr)
+	}
+
+	if detachErr != nil {
+		// VolumeAttachment deletion timed out - this may indicate CSI driver lost internal state
+		// Perform defense-in-depth verification at vSphere level before force-detaching
+		logger.Info("========================================")
+		logger.Info("VOLUMEATTACHMENT DELETION TIMEOUT")
+		logger.Info("========================================")
+		logger.Info("VolumeAttachment deletion timed out, performing vSphere-level verification",
+			"pv", pvState.PVName,
+			"error", detachErr)
+
+		// Attempt automatic remediation with vSphere-level safety verification
+		if err := p.remediateStuckVolumeAttachment(ctx, migration, pvState, vaManager); err != nil {
+			// Remediation failed - return original timeout error
+			logger.Error(err, "Failed to remediate stuck VolumeAttachment",
+				"pv", pvState.PVName)
+			return fmt.Errorf("timeout waiting for volume detachment (VolumeAttachment deletion): %w", detachErr)
+		}
+
+		logger.Info("Successfully remediated stuck VolumeAttachment", "pv", pvState.PVName)
+	}
+
+	pvState.Status = PVStatusPVCDeleted
+	logger.Info("PVC deleted and volume detachment confirmed", "namespace", pvState.PVCNamespace, "name", pvState.PVCName)
+	return nil
+}
+
+// remediateStuckVolumeAttachment performs automatic remediation of stuck VolumeAttachment
+// Uses defense-in-depth verification at vSphere level before force-cleaning Kubernetes resource
+func (p *MigrateCSIVolumesPhase) remediateStuckVolumeAttachment(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState, vaManager *openshift.VolumeAttachmentManager) error {
+	logger := klog.FromContext(ctx)
+
+	logger.Info("Starting automatic remediation for stuck VolumeAttachment",
+		"pv", pvState.PVName)
+
+	// Parse FCD ID from volume handle
+	fcdID, err := vsphere.ParseCSIVolumeHandle(pvState.SourceVolumePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse volume handle: %w", err)
+	}
+
+	logger.Info("Parsed FCD ID from volume handle", "fcdID", fcdID)
+
+	// Get source vCenter client for verification
+	sourceVCenter, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return fmt.Errorf("failed to get source vCenter: %w", err)
+	}
+
+	sourceClient, err := p.executor.GetVSphereClient(ctx, sourceVCenter.Server)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source vCenter: %w", err)
+	}
+	defer sourceClient.Logout(ctx)
 
 	// Get source failure domain for folder path
-	sourceFailureDomain, err := p.executor.infraManager.GetSourceFailureDomain(ctx)
+	sourceFailureDomain, err := p.executor.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return fmt.Errorf("failed to get source failure domain: %w", err)
+	}
+
+	// Get infrastructure ID for folder path
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+
+	// Create FCD manager for vSphere-level verification
+	sourceFCDManager, err := vsphere.NewFCDManager(ctx, sourceClient)
+	if err != nil {
+		return fmt.Errorf("failed to create FCD manager: %w", err)
+	}
+
+	// === DEFENSE-IN-DEPTH: Verify volume is truly detached at vSphere level ===
+	// This reuses the existing safety mechanism from relocateVolume()
+	logger.Info("Verifying FCD is detached at vSphere level before force-cleaning K8s resource",
+		"fcdID", fcdID, "pv", pvState.PVName)
+
+	folderPath := util.FailureDomainFolder(sourceFailureDomain.Topology, infraID)
+
+	// Wait for FCD to be detached from any worker VM (vSphere-level folder scan)
+	// This scans all VMs in the cluster folder to confirm FCD is not attached to any VM
+	if err := sourceFCDManager.WaitForFCDDetached(ctx,
+		sourceFailureDomain.Topology.Datacenter,
+		folderPath,
+		fcdID,
+		1*time.Minute); err != nil {
+
+		// FCD is still attached at vSphere level - this is a real problem, don't force
+		logger.Error(err, "ABORT: FCD is still attached at vSphere level - refusing to force-detach",
+			"fcdID", fcdID, "pv", pvState.PVName)
+		return fmt.Errorf("volume is still attached at vSphere level, refusing to force-detach: %w", err)
+	}
+
+	logger.Info("vSphere-level verification PASSED: FCD is confirmed detached from all VMs",
+		"fcdID", fcdID, "pv", pvState.PVName)
+
+	// Volume is truly detached at vSphere level but VolumeAttachment is stuck in K8s
+	// This indicates CSI driver has lost internal state - safe to force-clean K8s resource
+	logger.Info("VolumeAttachment is stuck but volume is detached at vSphere - forcing cleanup",
+		"pv", pvState.PVName)
+
+	if err := vaManager.ForceDetachVolume(ctx, pvState.PVName); err != nil {
+		return fmt.Errorf("failed to force-detach volume: %w", err)
+	}
+
+	logger.Info("========================================")
+	logger.Info("AUTOMATIC REMEDIATION SUCCESSFUL")
+	logger.Info("========================================")
+	logger.Info("Stuck VolumeAttachment remediated",
+		"pv", pvState.PVName,
+		"fcdID", fcdID,
+		"verificationMethod", "vSphere-level FCD detachment check")
+
+	return nil
+}
+
+// relocateVolume performs the cross-vCenter volume relocation using a dummy VM
+func (p *MigrateCSIVolumesPhase) relocateVolume(ctx context.Context, sourceClient, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	return p.relocateVolumes(ctx, sourceClient, targetClient, migration, []*migrationv1alpha1.PVMigrationState{pvState})[pvState.PVName]
+}
+
+// RelocateVolumes is a public wrapper for testing
+func (p *MigrateCSIVolumesPhase) RelocateVolumes(ctx context.Context, sourceClient, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, volumes []*migrationv1alpha1.PVMigrationState) map[string]error {
+	return p.relocateVolumes(ctx, sourceClient, targetClient, migration, volumes)
+}
+
+// relocateVolumes attaches a batch of volumes to one dummy VM and relocates them together with a
+// single cross-vCenter vMotion. A volume failing its own detachment checks is left out of the batch;
+// once any attach has been attempted, a failure aborts the whole batch since the disks on the dummy
+// VM are no longer known. The returned map holds the error of each failed volume by PV name.
+func (p *MigrateCSIVolumesPhase) relocateVolumes(ctx context.Context, sourceClient, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, volumes []*migrationv1alpha1.PVMigrationState) map[string]error {
+	logger := klog.FromContext(ctx)
+
+	errs := make(map[string]error)
+	failAll := func(volumes []*migrationv1alpha1.PVMigrationState, err error) map[string]error {
+		for _, pvState := range volumes {
+			errs[pvState.PVName] = err
+		}
+		return errs
+	}
+
+	// Get source failure domain from infrastructure
+	sourceFailureDomain, err := p.executor.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return failAll(volumes, fmt.Errorf("failed to get source failure domain: %w", err))
+	}
+
+	// Get target failure domain
+	targetFD := migration.Spec.FailureDomains[0]
+
+	// Create FCD manager for source
+	sourceFCDManager, err := vsphere.NewFCDManager(ctx, sourceClient)
+	if err != nil {
+		return failAll(volumes, fmt.Errorf("failed to create source FCD manager: %w", err))
+	}
+
+	// Create VM relocator
+	relocator := vsphere.NewVMRelocator(sourceClient, targetClient)
+
+	// Get infrastructure ID for naming
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return failAll(volumes, fmt.Errorf("failed to get infrastructure ID: %w", err))
+	}
+
+	// Create dummy VM on source, named after the first volume of the batch
+	dummyVMName, err := util.DummyVMName(migration.Spec.Naming, util.NameParams{
+		InfraID: infraID,
+		PVName:  volumes[0].PVName,
+	})
+	if err != nil {
+		return failAll(volumes, err)
+	}
+	for _, pvState := range volumes {
+		pvState.DummyVMName = dummyVMName
+	}
+
+	dummyConfig := vsphere.DummyVMConfig{
+		Name:         dummyVMName,
+		Datacenter:   sourceFailureDomain.Topology.Datacenter,
+		Cluster:      sourceFailureDomain.Topology.ComputeCluster,
+		Datastore:    sourceFailureDomain.Topology.Datastore,
+		Folder:       util.FailureDomainFolder(sourceFailureDomain.Topology, infraID),
+		ResourcePool: util.FailureDomainResourcePool(sourceFailureDomain.Topology),
+		NumCPUs:      1,
+		MemoryMB:     128,
+	}
+
+	dummyVM, err := relocator.CreateDummyVM(ctx, dummyConfig)
+	if err != nil {
+		return failAll(volumes, fmt.Errorf("failed to create dummy VM: %w", err))
+	}
+
+	// Attach each volume that passes its detachment checks
+	var attached []*migrationv1alpha1.PVMigrationState
+
+	// Cleanup dummy VM on exit, unless cloned source volumes could not be detached from it:
+	// destroying the VM would delete them. Every FCD an attach was attempted for is detached
+	// and verified gone first, so a failure at any step cannot destroy a volume with the VM.
+	// After the vMotion the VM lives on the target vCenter and is cleaned up there. With
+	// retainDummyVMOnFailure a failed batch keeps its VM, once its volumes are detached, for
+	// inspecting the failure, renamed so a retry can create its dummy VM under the same name.
+	keepDummyVM := false
+	cleanupRelocator, cleanupVM, cleanupServer := relocator, dummyVM, sourceFailureDomain.Server
+	defer func() {
+		if keepDummyVM {
+			logger.Info("Keeping dummy VM, source volumes are still attached to it", "name", dummyVMName)
+			return
+		}
+		trackedFCDs := make([]string, 0, len(attached))
+		for _, pvState := range attached {
+			trackedFCDs = append(trackedFCDs, pvState.SourceVolumeID)
+		}
+		if retainDummyVMOnFailure(migration) && slices.ContainsFunc(attached, func(pvState *migrationv1alpha1.PVMigrationState) bool {
+			return errs[pvState.PVName] != nil
+		}) {
+			retainedName := util.RetainedDummyVMName(dummyVMName, time.Now())
+			if retainErr := cleanupRelocator.RetainDummyVM(ctx, cleanupVM, retainedName, trackedFCDs...); retainErr != nil {
+				logger.Error(retainErr, "Keeping dummy VM of failed relocation, volumes could not be detached from it or it could not be renamed",
+					"name", dummyVMName, "vCenter", cleanupServer, "fcdIDs", trackedFCDs)
+				return
+			}
+			logger.Info("Retained dummy VM of failed relocation for inspection, volumes detached",
+				"name", retainedName, "vCenter", cleanupServer, "fcdIDs", trackedFCDs)
+			for _, pvState := range attached {
+				pvState.RetainedDummyVM = cleanupServer + "/" + retainedName
+			}
+			return
+		}
+		if cleanupErr := cleanupRelocator.DeleteDummyVM(ctx, cleanupVM, trackedFCDs...); cleanupErr != nil {
+			logger.Error(cleanupErr, "Failed to delete dummy VM", "name", dummyVMName, "fcdIDs", trackedFCDs)
+		}
+	}()
+
+	for _, pvState := range volumes {
+		datastore, err := p.prepareRelocation(ctx, sourceClient, sourceFCDManager, sourceFailureDomain, infraID, migration, pvState)
+		if err != nil {
+			errs[pvState.PVName] = err
+			continue
+		}
+		fcdID := pvState.SourceVolumeID
+
+		// Attach FCD to dummy VM
+		attached = append(attached, pvState)
+		slot, err := relocator.NextDiskSlot(ctx, dummyVM, reservedSCSIUnits(migration)...)
+		if err != nil {
+			return failAll(attached, fmt.Errorf("failed to get disk slot on dummy VM: %w", err))
+		}
+
+		if err := sourceFCDManager.AttachDisk(ctx, dummyVM, datastore, fcdID, slot.ControllerKey, slot.UnitNumber); err != nil {
+			return failAll(attached, fmt.Errorf("failed to attach FCD to dummy VM: %w", err))
+		}
+
+		// Read back the dummy VM devices - an attach task can report success without the
+		// disk persisting, and vMotion of a diskless dummy VM would silently move nothing
+		if err := sourceFCDManager.VerifyFCDAttachedToVM(ctx, dummyVM, fcdID, attachVerificationTimeout(migration)); err != nil {
+			return failAll(attached, fmt.Errorf("FCD attach to dummy VM %s did not persist: %w", dummyVMName, err))
+		}
+		logger.Info("Verified FCD is attached to dummy VM", "fcdID", fcdID, "vm", dummyVMName)
+	}
+	if len(attached) == 0 {
+		return errs
+	}
+
+	// Opening the disks on power on catches a stale or corrupt backing before the vMotion
+	if validateDiskBeforeMigrate(migration) {
+		if err := relocator.ValidateAttachedDisks(ctx, dummyVM); err != nil {
+			return failAll(attached, fmt.Errorf("disk validation on dummy VM %s failed: %w", dummyVMName, err))
+		}
+	}
+
+	fcdIDs := make([]string, 0, len(attached))
+	for _, pvState := range attached {
+		pvState.Status = PVStatusRelocating
+		fcdIDs = append(fcdIDs, pvState.SourceVolumeID)
+	}
+
+	// Build relocate config
+	relocateConfig := vsphere.RelocateConfig{
+		TargetDatacenter:   targetFD.Topology.Datacenter,
+		TargetCluster:      targetFD.Topology.ComputeCluster,
+		TargetDatastore:    VolumeTargetDatastore(migration, attached[0]),
+		TargetFolder:       util.FailureDomainFolder(targetFD.Topology, infraID),
+		TargetResourcePool: util.FailureDomainResourcePool(targetFD.Topology),
+		TargetHost:         relocateTargetHost(migration),
+		MaxTaskDuration:    maxRelocateTaskDuration(migration),
+	}
+	if TargetsDatastoreCluster(migration, attached[0]) {
+		relocateConfig.TargetDatastoreCluster = relocateConfig.TargetDatastore
+	}
+
+	// The client's SDK URL keeps any non-default port given in the failure domain server
+	targetVCenterURL := targetClient.SDKURL()
+	relocateConfig.TargetVCenterURL = targetVCenterURL
+	relocateConfig.SameVCenter = vsphere.SameServer(sourceClient.SDKURL(), targetVCenterURL)
+	if relocateConfig.SameVCenter {
+		// A move between datastores of one vCenter needs no ServiceLocator, so neither the
+		// target credentials nor its thumbprint and instance UUID are looked up
+		logger.Info("Target failure domain is on the source vCenter, relocating storage only",
+			"server", targetFD.Server)
+	} else if err := p.executor.setCrossVCenterTarget(ctx, migration, targetClient, targetFD.Server, &relocateConfig); err != nil {
+		return failAll(attached, err)
+	}
+
+	// The CreateFolder phase creates the target folder, but it may have been skipped or the
+	// folder deleted since - recreate it now rather than failing the vMotion on a finder error
+	if _, err := targetClient.EnsureFolder(ctx, relocateConfig.TargetDatacenter, relocateConfig.TargetFolder); err != nil {
+		return failAll(attached, fmt.Errorf("failed to ensure target folder %s: %w", relocateConfig.TargetFolder, err))
+	}
+
+	if cloneMode(migration) {
+		cloneErr := p.cloneAttachedVolumes(ctx, relocator, targetClient, dummyVM, relocateConfig, infraID, migration, attached)
+
+		// The source volumes are kept, so they must come off the dummy VM before it is destroyed
+		for _, pvState := range attached {
+			if err := sourceFCDManager.DetachDisk(ctx, dummyVM, pvState.SourceVolumeID); err != nil {
+				logger.Error(err, "Failed to detach source FCD from dummy VM", "fcdID", pvState.SourceVolumeID, "vm", dummyVMName)
+				keepDummyVM = true
+			}
+		}
+		if cloneErr != nil {
+			return failAll(attached, cloneErr)
+		}
+		return errs
+	}
+
+	// Log prominent start message for the vMotion
+	logger.Info("========================================")
+	logger.Info("STARTING " + strings.ToUpper(vMotionKind(relocateConfig)))
+	logger.Info("========================================")
+	thumbprintPreview := relocateConfig.TargetVCenterThumbprint
+	if len(thumbprintPreview) > 20 {
+		thumbprintPreview = thumbprintPreview[:20] + "..."
+	}
+	logger.Info("vMotion configuration",
+		"sourceDatacenter", sourceFailureDomain.Topology.Datacenter,
+		"targetVCenter", targetFD.Server,
+		"targetDatacenter", targetFD.Topology.Datacenter,
+		"targetDatastore", relocateConfig.TargetDatastore,
+		"targetFolder", relocateConfig.TargetFolder,
+		"targetInstanceUUID", relocateConfig.TargetVCenterInstanceUUID,
+		"sslThumbprint", thumbprintPreview,
+		"dummyVM", dummyVMName,
+		"fcdIDs", fcdIDs)
+
+	// Perform cross-vCenter vMotion
+	if err := relocator.RelocateVM(ctx, dummyVM, relocateConfig); err != nil {
+		logger.Info("========================================")
+		logger.Info(strings.ToUpper(vMotionKind(relocateConfig)) + " FAILED")
+		logger.Info("========================================")
+		logger.Error(err, "vMotion failure details",
+			"vm", dummyVMName,
+			"fcdIDs", fcdIDs,
+			"targetVCenter", targetFD.Server,
+			"error", err.Error())
+		return failAll(attached, fmt.Errorf("%s failed: %w", vMotionKind(relocateConfig), err))
+	}
+
+	// Detach FCDs from dummy VM on target
+	// Note: After vMotion, the VM is on target vCenter
+	targetFCDManager, err := vsphere.NewFCDManager(ctx, targetClient)
+	if err != nil {
+		return failAll(attached, fmt.Errorf("failed to create target FCD manager: %w", err))
+	}
+
+	// Get the VM reference on target
+	targetVM, err := targetClient.GetVirtualMachine(ctx, path.Join(relocateConfig.TargetFolder, dummyVMName))
+	if err != nil {
+		return failAll(attached, fmt.Errorf("failed to find dummy VM on target: %w", err))
+	}
+	cleanupRelocator, cleanupVM, cleanupServer = vsphere.NewVMRelocator(targetClient, targetClient), targetVM, targetFD.Server
+
+	for _, pvState := range attached {
+		fcdID := pvState.SourceVolumeID
+		if err := targetFCDManager.DetachDisk(ctx, targetVM, fcdID); err != nil {
+			logger.Error(err, "Failed to detach FCD from dummy VM on target", "fcdID", fcdID)
+			// Continue anyway, the disk might already be detached and the cleanup of the
+			// dummy VM retries the detach before destroying it
+		}
+
+		// Update state
+		pvState.TargetVolumeID = fcdID // FCD ID remains the same after vMotion
+		pvState.TargetVolumePath = vsphere.BuildCSIVolumeHandle(fcdID)
+		pvState.Status = PVStatusRelocated
+
+		logger.Info("Successfully relocated volume", "pv", pvState.PVName, "fcdID", fcdID)
+	}
+
+	return errs
+}
+
+// cloneAttachedVolumes copies the volumes attached to the dummy VM to the target location and
+// records each copy as the volume's target FCD, keeping the source FCD until its retention
+// expires. FCDs cannot be cloned across vCenters, so the dummy VM is cloned with its disks, the
+// copied disks are registered as FCDs on the target and the clone is destroyed without them.
+func (p *MigrateCSIVolumesPhase) cloneAttachedVolumes(ctx context.Context, relocator *vsphere.VMRelocator, targetClient *vsphere.Client, dummyVM *object.VirtualMachine, config vsphere.RelocateConfig, infraID string, migration *migrationv1alpha1.VmwareCloudFoundationMigration, volumes []*migrationv1alpha1.PVMigrationState) error {
+	logger := klog.FromContext(ctx)
+
+	// The clone keeps the device order of the dummy VM, which pairs each copy with its source
+	sourceDisks, err := relocator.AttachedDisks(ctx, dummyVM)
 	if err != nil {
-		return fmt.Errorf("failed to get source failure domain: %w", err)
+		return fmt.Errorf("failed to list disks of dummy VM: %w", err)
+	}
+	volumesByFCD := make(map[string]*migrationv1alpha1.PVMigrationState, len(volumes))
+	for _, pvState := range volumes {
+		volumesByFCD[pvState.SourceVolumeID] = pvState
 	}
 
-	// Get infrastructure ID for folder path
-	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	cloneName, err := util.DummyVMName(migration.Spec.Naming, util.NameParams{
+		InfraID: infraID,
+		PVName:  volumes[0].PVName + "-clone",
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get infrastructure ID: %w", err)
+		return err
 	}
 
-	// Create FCD manager for vSphere-level verification
-	sourceFCDManager, err := vsphere.NewFCDManager(ctx, sourceClient)
-	if err != nil {
-		return fmt.Errorf("failed to create FCD manager: %w", err)
+	logger.Info("========================================")
+	logger.Info("STARTING " + strings.ToUpper(cloneKind(config)))
+	logger.Info("========================================")
+	if err := relocator.CloneVM(ctx, dummyVM, cloneName, config); err != nil {
+		return fmt.Errorf("%s failed: %w", cloneKind(config), err)
 	}
 
-	// === DEFENSE-IN-DEPTH: Verify volume is truly detached at vSphere level ===
-	// This reuses the existing safety mechanism from relocateVolume()
-	logger.Info("Verifying FCD is detached at vSphere level before force-cleaning K8s resource",
-		"fcdID", fcdID, "pv", pvState.PVName)
+	cloneVM, err := targetClient.GetVirtualMachine(ctx, fmt.Sprintf("%s/%s", config.TargetFolder, cloneName))
+	if err != nil {
+		return fmt.Errorf("failed to find clone %s on target: %w", cloneName, err)
+	}
 
-	folderPath := fmt.Sprintf("/%s/vm/%s", sourceFailureDomain.Topology.Datacenter, infraID)
+	// Destroying the clone would delete the copies, so its disks are removed first
+	clonedDisks, err := relocator.RemoveDisks(ctx, cloneVM)
+	if err != nil {
+		return p.discardClone(ctx, targetClient, config.TargetDatacenter, cloneVM, nil,
+			fmt.Errorf("failed to remove disks from clone %s: %w", cloneName, err))
+	}
+	if len(clonedDisks) != len(sourceDisks) {
+		return p.discardClone(ctx, targetClient, config.TargetDatacenter, cloneVM, clonedDisks,
+			fmt.Errorf("clone %s has %d disk(s) but the dummy VM has %d", cloneName, len(clonedDisks), len(sourceDisks)))
+	}
 
-	// Wait for FCD to be detached from any worker VM (vSphere-level folder scan)
-	// This scans all VMs in the cluster folder to confirm FCD is not attached to any VM
-	if err := sourceFCDManager.WaitForFCDDetached(ctx,
-		sourceFailureDomain.Topology.Datacenter,
-		folderPath,
-		fcdID,
-		1*time.Minute); err != nil {
+	targetFCDManager, err := vsphere.NewFCDManager(ctx, targetClient)
+	if err != nil {
+		return p.discardClone(ctx, targetClient, config.TargetDatacenter, cloneVM, clonedDisks,
+			fmt.Errorf("failed to create target FCD manager: %w", err))
+	}
+
+	// Every copy is registered before any volume is updated, so a failure leaves no volume
+	// pointing at a copy that is discarded
+	cloned := make([]*migrationv1alpha1.PVMigrationState, len(clonedDisks))
+	for i := range clonedDisks {
+		disk := &clonedDisks[i]
+		pvState, ok := volumesByFCD[sourceDisks[i].FCDID]
+		if !ok {
+			return p.discardClone(ctx, targetClient, config.TargetDatacenter, cloneVM, clonedDisks,
+				fmt.Errorf("disk %s of clone %s does not belong to a migrated volume", disk.FileName, cloneName))
+		}
+		cloned[i] = pvState
 
-		// FCD is still attached at vSphere level - this is a real problem, don't force
-		logger.Error(err, "ABORT: FCD is still attached at vSphere level - refusing to force-detach",
-			"fcdID", fcdID, "pv", pvState.PVName)
-		return fmt.Errorf("volume is still attached at vSphere level, refusing to force-detach: %w", err)
+		if disk.FCDID == "" {
+			datastoreName, filePath, err := vsphere.ParseDatastorePath(disk.FileName)
+			if err != nil {
+				return p.discardClone(ctx, targetClient, config.TargetDatacenter, cloneVM, clonedDisks,
+					fmt.Errorf("failed to parse path of cloned disk of PV %s: %w", pvState.PVName, err))
+			}
+			info, err := targetFCDManager.RegisterDisk(ctx, datastoreName, filePath, pvState.PVName)
+			if err != nil {
+				return p.discardClone(ctx, targetClient, config.TargetDatacenter, cloneVM, clonedDisks,
+					fmt.Errorf("failed to register cloned disk %s of PV %s as FCD: %w", disk.FileName, pvState.PVName, err))
+			}
+			disk.FCDID = info.ID
+		}
 	}
 
-	logger.Info("vSphere-level verification PASSED: FCD is confirmed detached from all VMs",
-		"fcdID", fcdID, "pv", pvState.PVName)
-
-	// Volume is truly detached at vSphere level but VolumeAttachment is stuck in K8s
-	// This indicates CSI driver has lost internal state - safe to force-clean K8s resource
-	logger.Info("VolumeAttachment is stuck but volume is detached at vSphere - forcing cleanup",
-		"pv", pvState.PVName)
+	retainUntil := metav1.NewTime(time.Now().Add(sourceRetention(migration)))
+	for i, pvState := range cloned {
+		pvState.TargetVolumeID = clonedDisks[i].FCDID
+		pvState.TargetVolumePath = clonedDisks[i].FileName
+		pvState.SourceRetainedUntil = &retainUntil
+		pvState.Status = PVStatusRelocated
 
-	if err := vaManager.ForceDetachVolume(ctx, pvState.PVName); err != nil {
-		return fmt.Errorf("failed to force-detach volume: %w", err)
+		logger.Info("Successfully cloned volume",
+			"pv", pvState.PVName,
+			"sourceFCDID", pvState.SourceVolumeID,
+			"targetFCDID", pvState.TargetVolumeID,
+			"sourceRetainedUntil", retainUntil)
 	}
 
-	logger.Info("========================================")
-	logger.Info("AUTOMATIC REMEDIATION SUCCESSFUL")
-	logger.Info("========================================")
-	logger.Info("Stuck VolumeAttachment remediated",
-		"pv", pvState.PVName,
-		"fcdID", fcdID,
-		"verificationMethod", "vSphere-level FCD detachment check")
-
+	if err := vsphere.NewVMRelocator(targetClient, targetClient).DeleteDummyVM(ctx, cloneVM); err != nil {
+		logger.Error(err, "Failed to delete clone VM, it holds no disks and can be removed manually", "name", cloneName)
+	}
 	return nil
 }
 
-// relocateVolume performs the cross-vCenter volume relocation using a dummy VM
-func (p *MigrateCSIVolumesPhase) relocateVolume(ctx context.Context, sourceClient, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+// discardClone deletes a clone whose disks could not all be taken over as FCDs, along with the
+// copies, so a failed clone leaves nothing behind on the target, and returns cause. Copies still
+// on the clone are destroyed with it; copies already removed from it are deleted as FCDs, or as
+// VMDK files when they were not registered.
+func (p *MigrateCSIVolumesPhase) discardClone(ctx context.Context, targetClient *vsphere.Client, datacenter string, cloneVM *object.VirtualMachine, copies []vsphere.AttachedDisk, cause error) error {
 	logger := klog.FromContext(ctx)
+	logger.Info("Discarding failed clone", "name", cloneVM.Name(), "copies", len(copies), "cause", cause)
 
-	// Parse volume handle to get FCD ID
-	fcdID, err := vsphere.ParseCSIVolumeHandle(pvState.SourceVolumePath)
-	if err != nil {
-		return fmt.Errorf("failed to parse volume handle: %w", err)
+	var errs []error
+	if len(copies) > 0 {
+		fcdManager, err := vsphere.NewFCDManager(ctx, targetClient)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create target FCD manager: %w", err))
+		} else {
+			for _, disk := range copies {
+				if disk.FCDID == "" {
+					if err := fcdManager.DeleteVirtualDisk(ctx, datacenter, disk.FileName); err != nil {
+						errs = append(errs, err)
+					}
+					continue
+				}
+				datastoreName, _, err := vsphere.ParseDatastorePath(disk.FileName)
+				if err == nil {
+					err = fcdManager.DeleteFCD(ctx, datastoreName, disk.FCDID)
+				}
+				if err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete copy %s: %w", disk.FCDID, err))
+				}
+			}
+		}
+	}
+	if err := vsphere.NewVMRelocator(targetClient, targetClient).DeleteDummyVM(ctx, cloneVM); err != nil {
+		errs = append(errs, err)
 	}
-	pvState.SourceVolumeID = fcdID
 
-	// Get source failure domain from infrastructure
-	sourceFailureDomain, err := p.executor.infraManager.GetSourceFailureDomain(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get source failure domain: %w", err)
+	if err := errors.Join(errs...); err != nil {
+		logger.Error(err, "Failed to discard clone, remove it and its disks from the target manually", "name", cloneVM.Name())
+		return fmt.Errorf("%w; discarding clone %s also failed, remove it and its disks from the target manually: %v", cause, cloneVM.Name(), err)
 	}
+	return cause
+}
 
-	// Get target failure domain
-	targetFD := migration.Spec.FailureDomains[0]
+// DiscardClone is a public wrapper for testing
+func (p *MigrateCSIVolumesPhase) DiscardClone(ctx context.Context, targetClient *vsphere.Client, datacenter string, cloneVM *object.VirtualMachine, copies []vsphere.AttachedDisk, cause error) error {
+	return p.discardClone(ctx, targetClient, datacenter, cloneVM, copies, cause)
+}
 
-	// Create FCD manager for source
-	sourceFCDManager, err := vsphere.NewFCDManager(ctx, sourceClient)
-	if err != nil {
-		return fmt.Errorf("failed to create source FCD manager: %w", err)
+// cloneKind names the kind of clone a relocate config performs, for logs and errors
+func cloneKind(config vsphere.RelocateConfig) string {
+	if config.SameVCenter {
+		return "clone"
 	}
+	return "cross-vCenter clone"
+}
 
-	// Get FCD info
-	fcdInfo, err := sourceFCDManager.GetFCDByID(ctx, fcdID)
-	if err != nil {
-		return fmt.Errorf("failed to get FCD info: %w", err)
+// vMotionKind names the kind of vMotion a relocate config performs, for logs and errors
+func vMotionKind(config vsphere.RelocateConfig) string {
+	if config.SameVCenter {
+		return "storage vMotion"
 	}
+	return "cross-vCenter vMotion"
+}
 
-	logger.Info("Found FCD", "id", fcdInfo.ID, "name", fcdInfo.Name, "path", fcdInfo.Path)
+// setCrossVCenterTarget fills in the target credentials, SSL thumbprint and instance UUID the
+// ServiceLocator of a cross-vCenter vMotion to server needs
+func (e *PhaseExecutor) setCrossVCenterTarget(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, targetClient *vsphere.Client, server string, relocateConfig *vsphere.RelocateConfig) error {
+	logger := klog.FromContext(ctx)
 
-	// Create VM relocator
-	relocator := vsphere.NewVMRelocator(sourceClient, targetClient)
+	// Get target credentials for cross-vCenter vMotion
+	credentials, err := e.TargetCredentialProvider(migration)
+	if err != nil {
+		return fmt.Errorf("failed to get target credentials: %w", err)
+	}
+	targetUser, targetPass, err := credentials.GetCredentials(ctx, server)
+	if err != nil {
+		return fmt.Errorf("failed to get target credentials: %w", err)
+	}
+	relocateConfig.TargetVCenterUser = targetUser
+	relocateConfig.TargetVCenterPassword = targetPass
 
-	// Get infrastructure ID for naming
-	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	// Get target vCenter SSL thumbprint for cross-vCenter vMotion
+	// This is required for the ServiceLocator to verify the target server's identity
+	targetThumbprint, err := vsphere.GetServerThumbprint(ctx, relocateConfig.TargetVCenterURL, targetClient.ProxyURL(), targetClient.DialTimeout())
 	if err != nil {
-		return fmt.Errorf("failed to get infrastructure ID: %w", err)
+		return fmt.Errorf("failed to get target vCenter SSL thumbprint: %w", err)
 	}
+	logger.Info("Retrieved target vCenter SSL thumbprint",
+		"server", server,
+		"thumbprint", targetThumbprint)
+	relocateConfig.TargetVCenterThumbprint = targetThumbprint
 
-	// Create dummy VM on source
-	dummyVMName := fmt.Sprintf("csi-migration-%s-%s", infraID, pvState.PVName[:min(8, len(pvState.PVName))])
-	pvState.DummyVMName = dummyVMName
+	// Get target vCenter instance UUID for cross-vCenter vMotion
+	relocateConfig.TargetVCenterInstanceUUID = targetClient.GetInstanceUUID()
+	logger.Info("Retrieved target vCenter instance UUID",
+		"server", server,
+		"instanceUUID", relocateConfig.TargetVCenterInstanceUUID)
 
-	dummyConfig := vsphere.DummyVMConfig{
-		Name:         dummyVMName,
-		Datacenter:   sourceFailureDomain.Topology.Datacenter,
-		Cluster:      sourceFailureDomain.Topology.ComputeCluster,
-		Datastore:    sourceFailureDomain.Topology.Datastore,
-		Folder:       fmt.Sprintf("/%s/vm/%s", sourceFailureDomain.Topology.Datacenter, infraID),
-		ResourcePool: sourceFailureDomain.Topology.ResourcePool,
-		NumCPUs:      1,
-		MemoryMB:     128,
+	// Validate relocate config before attempting vMotion
+	if relocateConfig.TargetVCenterInstanceUUID == "" {
+		return fmt.Errorf("FATAL: target vCenter instance UUID is empty - cannot proceed with cross-vCenter vMotion")
+	}
+	if relocateConfig.TargetVCenterThumbprint == "" {
+		return fmt.Errorf("FATAL: target vCenter SSL thumbprint is empty - cannot proceed with cross-vCenter vMotion")
 	}
+	return nil
+}
 
-	dummyVM, err := relocator.CreateDummyVM(ctx, dummyConfig)
+// prepareRelocation verifies a volume is detached from every worker VM and takes the optional
+// pre-migration snapshot. It records the FCD ID on the volume and returns its datastore.
+func (p *MigrateCSIVolumesPhase) prepareRelocation(ctx context.Context, sourceClient *vsphere.Client, sourceFCDManager *vsphere.FCDManager, sourceFailureDomain *configv1.VSpherePlatformFailureDomainSpec, infraID string, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) (*object.Datastore, error) {
+	logger := klog.FromContext(ctx)
+
+	// Parse volume handle to get FCD ID
+	fcdID, err := vsphere.ParseCSIVolumeHandle(pvState.SourceVolumePath)
 	if err != nil {
-		return fmt.Errorf("failed to create dummy VM: %w", err)
+		return nil, fmt.Errorf("failed to parse volume handle: %w", err)
 	}
+	pvState.SourceVolumeID = fcdID
 
-	// Cleanup dummy VM on exit
-	defer func() {
-		if cleanupErr := relocator.DeleteDummyVM(ctx, dummyVM); cleanupErr != nil {
-			logger.Error(cleanupErr, "Failed to delete dummy VM", "name", dummyVMName)
-		}
-	}()
-
-	// Get SCSI controller key
-	controllerKey, err := relocator.GetVMSCSIControllerKey(ctx, dummyVM)
+	// Get FCD info
+	fcdInfo, err := sourceFCDManager.GetFCDByID(ctx, fcdID)
 	if err != nil {
-		return fmt.Errorf("failed to get SCSI controller: %w", err)
+		return nil, fmt.Errorf("failed to get FCD info: %w", err)
 	}
 
+	logger.Info("Found FCD", "id", fcdInfo.ID, "name", fcdInfo.Name, "path", fcdInfo.Path)
+
+	// Registering the volume on the target loses its CNS labels and vSphere tags
+	p.recordVolumeMetadata(ctx, sourceClient, sourceFCDManager, pvState)
+
 	// Get datastore for FCD
 	datastore, err := sourceFCDManager.GetDatastoreFromPath(ctx, fcdInfo.Path)
 	if err != nil {
-		return fmt.Errorf("failed to get datastore: %w", err)
+		return nil, fmt.Errorf("failed to get datastore: %w", err)
 	}
 
 	// === DEFENSE-IN-DEPTH: Multiple layers of detachment verification ===
@@ -683,20 +2020,20 @@ func (p *MigrateCSIVolumesPhase) relocateVolume(ctx context.Context, sourceClien
 		logger.Error(err, "Failed to check VolumeAttachment status", "pv", pvState.PVName)
 		// Continue to vSphere-level checks - VolumeAttachment API error shouldn't block if vSphere confirms detachment
 	} else if attached {
-		return fmt.Errorf("ABORT: volume still attached per VolumeAttachment (node=%s), refusing to proceed to protect data", nodeName)
+		return nil, fmt.Errorf("ABORT: volume still attached per VolumeAttachment (node=%s), refusing to proceed to protect data", nodeName)
 	}
 	logger.Info("Defense Layer 1 PASSED: VolumeAttachment confirms volume is detached", "pv", pvState.PVName)
 
 	// Defense Layer 2: Wait for FCD to be detached from any worker VM (vSphere-level folder scan)
 	// This scans all VMs in the cluster folder to confirm FCD is not attached to any VM
 	logger.Info("Defense Layer 2: Waiting for FCD to be detached from all VMs in folder", "fcdID", fcdID)
-	folderPath := fmt.Sprintf("/%s/vm/%s", sourceFailureDomain.Topology.Datacenter, infraID)
+	folderPath := util.FailureDomainFolder(sourceFailureDomain.Topology, infraID)
 	if err := sourceFCDManager.WaitForFCDDetached(ctx,
 		sourceFailureDomain.Topology.Datacenter,
 		folderPath,
 		fcdID,
 		3*time.Minute); err != nil {
-		return fmt.Errorf("timeout waiting for FCD detachment from worker VM: %w", err)
+		return nil, fmt.Errorf("timeout waiting for FCD detachment from worker VM: %w", err)
 	}
 	logger.Info("Defense Layer 2 PASSED: FCD is not attached to any VM in folder", "fcdID", fcdID)
 
@@ -713,7 +2050,7 @@ func (p *MigrateCSIVolumesPhase) relocateVolume(ctx context.Context, sourceClien
 		} else {
 			for _, vm := range vms {
 				if err := sourceFCDManager.VerifyFCDNotAttachedToVM(ctx, vm, fcdID); err != nil {
-					return fmt.Errorf("Defense Layer 3 FAILED: %w", err)
+					return nil, fmt.Errorf("Defense Layer 3 FAILED: %w", err)
 				}
 			}
 			logger.Info("Defense Layer 3 PASSED: FCD verified not attached to any worker VM devices", "fcdID", fcdID)
@@ -722,134 +2059,322 @@ func (p *MigrateCSIVolumesPhase) relocateVolume(ctx context.Context, sourceClien
 
 	logger.Info("All defense layers PASSED - safe to proceed with migration", "fcdID", fcdID, "pv", pvState.PVName)
 
-	// Attach FCD to dummy VM
-	unitNumber, err := relocator.GetNextFreeUnitNumber(ctx, dummyVM, controllerKey)
-	if err != nil {
-		return fmt.Errorf("failed to get unit number: %w", err)
+	// Fingerprint the data while nothing has the volume attached
+	if err := p.RecordSourceChecksum(ctx, sourceClient, migration, pvState); err != nil {
+		return nil, err
 	}
 
-	if err := sourceFCDManager.AttachDisk(ctx, dummyVM, datastore, fcdID, controllerKey, unitNumber); err != nil {
-		return fmt.Errorf("failed to attach FCD to dummy VM: %w", err)
+	// Take a safety-net snapshot on the source before the disk is moved
+	if snapshotBeforeMigrate(migration) && pvState.SnapshotID == "" {
+		snapshotID, err := sourceFCDManager.CreateSnapshot(ctx, fcdID,
+			fmt.Sprintf("Pre-migration snapshot of PV %s", pvState.PVName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot FCD before relocation: %w", err)
+		}
+		pvState.SnapshotID = snapshotID
+		logger.Info("Created pre-migration FCD snapshot", "pv", pvState.PVName, "fcdID", fcdID, "snapshotID", snapshotID)
 	}
 
-	pvState.Status = PVStatusRelocating
+	return datastore, nil
+}
 
-	// Get target credentials for cross-vCenter vMotion
-	targetSecretNS := migration.Spec.TargetVCenterCredentialsSecret.Namespace
-	if targetSecretNS == "" {
-		targetSecretNS = migration.Namespace
-	}
-	targetUser, targetPass, err := p.executor.secretManager.GetVCenterCredsFromSecret(
-		ctx,
-		targetSecretNS,
-		migration.Spec.TargetVCenterCredentialsSecret.Name,
-		targetFD.Server,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to get target credentials: %w", err)
+// relocateNextBatch relocates up to batchSize volumes waiting in PVCDeleted with one dummy VM and
+// records the outcome of each volume, reporting whether any volume failed
+func (p *MigrateCSIVolumesPhase) relocateNextBatch(ctx context.Context, sourceClient, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, batchSize int, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, bool) {
+	logger := klog.FromContext(ctx)
+
+	batch := NextRelocateBatch(migration, batchSize)
+	if len(batch) == 0 {
+		return logs, false
 	}
 
-	// Get target vCenter SSL thumbprint for cross-vCenter vMotion
-	// This is required for the ServiceLocator to verify the target server's identity
-	targetVCenterURL := fmt.Sprintf("https://%s/sdk", targetFD.Server)
-	targetThumbprint, err := vsphere.GetServerThumbprint(ctx, targetVCenterURL)
-	if err != nil {
-		return fmt.Errorf("failed to get target vCenter SSL thumbprint: %w", err)
+	logger.Info("Relocating volume batch", "volumes", len(batch), "batchSize", batchSize)
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Relocating %d volumes together on one dummy VM", len(batch)),
+		string(p.Name()))
+
+	errs := p.relocateVolumes(ctx, sourceClient, targetClient, migration, batch)
+	batchFailed := false
+	for _, pvState := range batch {
+		if err, failed := errs[pvState.PVName]; failed && errors.Is(err, vsphere.ErrFCDNotFound) {
+			if logs = p.resumeFromTarget(ctx, targetClient, migration, pvState, err, logs); pvState.Status != PVStatusRelocated {
+				batchFailed = true
+			}
+			continue
+		} else if failed {
+			batchFailed = true
+			FailVolumeError(migration.Status.CSIVolumeMigration, pvState, "Failed to relocate volume", phaseerrors.DataSafety(err))
+			logs = AddLog(logs, migrationv1alpha1.LogLevelError, pvState.Message, string(p.Name()))
+
+			// DO NOT restore workloads on relocation failure - volume may be in inconsistent state
+			logger.Error(nil, "PV migration failed, workloads remain scaled down to prevent data loss",
+				"pv", pvState.PVName,
+				"scaledDownResources", len(pvState.ScaledDownResources))
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("Workloads for PV %s remain scaled down due to migration failure - manual intervention required", pvState.PVName),
+				string(p.Name()))
+			continue
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Relocated PV %s to target vCenter", pvState.PVName),
+			string(p.Name()))
 	}
-	logger.Info("Retrieved target vCenter SSL thumbprint",
-		"server", targetFD.Server,
-		"thumbprint", targetThumbprint)
 
-	// Get target vCenter instance UUID for cross-vCenter vMotion
-	targetInstanceUUID := targetClient.GetInstanceUUID()
-	logger.Info("Retrieved target vCenter instance UUID",
-		"server", targetFD.Server,
-		"instanceUUID", targetInstanceUUID)
+	return logs, batchFailed
+}
 
-	// Build relocate config
-	relocateConfig := vsphere.RelocateConfig{
-		TargetVCenterURL:          targetVCenterURL,
-		TargetVCenterUser:         targetUser,
-		TargetVCenterPassword:     targetPass,
-		TargetVCenterThumbprint:   targetThumbprint,
-		TargetVCenterInstanceUUID: targetInstanceUUID,
-		TargetDatacenter:          targetFD.Topology.Datacenter,
-		TargetCluster:             targetFD.Topology.ComputeCluster,
-		TargetDatastore:           targetFD.Topology.Datastore,
-		TargetFolder:              fmt.Sprintf("/%s/vm/%s", targetFD.Topology.Datacenter, infraID),
-		TargetResourcePool:        targetFD.Topology.ResourcePool,
+// NextRelocateBatch returns up to batchSize volumes waiting for relocation, in discovery order.
+// One vMotion moves every disk of a dummy VM to one datastore, so the batch only holds volumes
+// with the same target datastore as the first waiting volume.
+func NextRelocateBatch(migration *migrationv1alpha1.VmwareCloudFoundationMigration, batchSize int) []*migrationv1alpha1.PVMigrationState {
+	status := migration.Status.CSIVolumeMigration
+	var batch []*migrationv1alpha1.PVMigrationState
+	var datastore string
+	for i := range status.Volumes {
+		if len(batch) == batchSize {
+			break
+		}
+		pvState := &status.Volumes[i]
+		if pvState.Status != PVStatusPVCDeleted {
+			continue
+		}
+		if len(batch) == 0 {
+			datastore = VolumeTargetDatastore(migration, pvState)
+		} else if VolumeTargetDatastore(migration, pvState) != datastore {
+			continue
+		}
+		batch = append(batch, pvState)
 	}
+	return batch
+}
 
-	// Validate relocate config before attempting vMotion
-	if relocateConfig.TargetVCenterInstanceUUID == "" {
-		return fmt.Errorf("FATAL: target vCenter instance UUID is empty - cannot proceed with cross-vCenter vMotion")
+// RelocateBatchSize returns how many volumes to relocate per dummy VM, capped at what one VM can hold
+func RelocateBatchSize(migration *migrationv1alpha1.VmwareCloudFoundationMigration) int {
+	if cfg := migration.Spec.CSIVolumeMigration; cfg != nil && cfg.BatchSize > 1 {
+		return min(int(cfg.BatchSize), maxRelocateBatchSize)
 	}
-	if relocateConfig.TargetVCenterThumbprint == "" {
-		return fmt.Errorf("FATAL: target vCenter SSL thumbprint is empty - cannot proceed with cross-vCenter vMotion")
+	return 1
+}
+
+// attachVerificationTimeout returns the configured attach read-back timeout or the default
+func attachVerificationTimeout(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if cfg := migration.Spec.CSIVolumeMigration; cfg != nil && cfg.AttachVerificationTimeout != nil && cfg.AttachVerificationTimeout.Duration > 0 {
+		return cfg.AttachVerificationTimeout.Duration
 	}
+	return defaultAttachVerificationTimeout
+}
 
-	// Log prominent start message for cross-vCenter vMotion
-	logger.Info("========================================")
-	logger.Info("STARTING CROSS-VCENTER VMOTION")
-	logger.Info("========================================")
-	thumbprintPreview := relocateConfig.TargetVCenterThumbprint
-	if len(thumbprintPreview) > 20 {
-		thumbprintPreview = thumbprintPreview[:20] + "..."
+// snapshotBeforeMigrate reports whether a pre-migration FCD snapshot should be taken. Clone mode
+// keeps the source FCD itself, so no snapshot is taken.
+func snapshotBeforeMigrate(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.SnapshotBeforeMigrate && !cloneMode(migration)
+}
+
+// workloadReadinessTimeout returns how long volumes wait for their restored workloads to become
+// ready, or zero when they do not wait
+func workloadReadinessTimeout(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if cfg := migration.Spec.CSIVolumeMigration; cfg != nil && cfg.WorkloadReadinessTimeout != nil && cfg.WorkloadReadinessTimeout.Duration > 0 {
+		return cfg.WorkloadReadinessTimeout.Duration
 	}
-	logger.Info("vMotion configuration",
-		"sourceDatacenter", sourceFailureDomain.Topology.Datacenter,
-		"targetVCenter", targetFD.Server,
-		"targetDatacenter", targetFD.Topology.Datacenter,
-		"targetDatastore", targetFD.Topology.Datastore,
-		"targetFolder", relocateConfig.TargetFolder,
-		"targetInstanceUUID", targetInstanceUUID,
-		"sslThumbprint", thumbprintPreview,
-		"dummyVM", dummyVMName,
-		"fcdID", fcdID)
+	return 0
+}
 
-	// Perform cross-vCenter vMotion
-	if err := relocator.RelocateVM(ctx, dummyVM, relocateConfig); err != nil {
-		logger.Info("========================================")
-		logger.Info("CROSS-VCENTER VMOTION FAILED")
-		logger.Info("========================================")
-		logger.Error(err, "vMotion failure details",
-			"vm", dummyVMName,
-			"fcdID", fcdID,
-			"targetVCenter", targetFD.Server,
-			"error", err.Error())
-		return fmt.Errorf("cross-vCenter vMotion failed: %w", err)
+// maxRelocateTaskDuration returns how long a relocate or clone task may run, or zero for the
+// vsphere package default
+func maxRelocateTaskDuration(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if cfg := migration.Spec.CSIVolumeMigration; cfg != nil && cfg.MaxRelocateTaskDuration != nil {
+		return cfg.MaxRelocateTaskDuration.Duration
+	}
+	return 0
+}
+
+// ScaledDownAlertThreshold returns how long the workloads of a failed volume may stay scaled down
+// before they are reported
+func ScaledDownAlertThreshold(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if cfg := migration.Spec.CSIVolumeMigration; cfg != nil && cfg.ScaledDownAlertThreshold != nil && cfg.ScaledDownAlertThreshold.Duration > 0 {
+		return cfg.ScaledDownAlertThreshold.Duration
+	}
+	return defaultScaledDownAlertThreshold
+}
+
+// ScaledDownVolumes returns the failed volumes whose workloads have been scaled down for longer
+// than threshold, and how long until the next of the others crosses it, or zero if none will
+func ScaledDownVolumes(status *migrationv1alpha1.CSIVolumeMigrationStatus, threshold time.Duration, now time.Time) (exceeded []*migrationv1alpha1.PVMigrationState, next time.Duration) {
+	if status == nil {
+		return nil, 0
+	}
+	for i := range status.Volumes {
+		pvState := &status.Volumes[i]
+		if pvState.Status != PVStatusFailed || len(pvState.ScaledDownResources) == 0 || pvState.StartTime == nil {
+			continue
+		}
+		remaining := pvState.StartTime.Add(threshold).Sub(now)
+		if remaining <= 0 {
+			exceeded = append(exceeded, pvState)
+		} else if next == 0 || remaining < next {
+			next = remaining
+		}
+	}
+	return exceeded, next
+}
+
+// validateDiskBeforeMigrate reports whether dummy VMs are power cycled to validate their disks
+func validateDiskBeforeMigrate(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.ValidateDiskBeforeMigrate
+}
+
+// retainDummyVMOnFailure reports whether the dummy VM of a failed relocation is kept for inspection
+func retainDummyVMOnFailure(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.RetainDummyVMOnFailure
+}
+
+// cloneMode reports whether volumes are cloned to the target and their source FCDs kept
+func cloneMode(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.MigrationMode == migrationv1alpha1.VolumeMigrationModeClone
+}
+
+// sourceRetention returns how long the source FCD of a cloned volume is kept
+func sourceRetention(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if cfg := migration.Spec.CSIVolumeMigration; cfg != nil && cfg.SourceRetention != nil && cfg.SourceRetention.Duration > 0 {
+		return cfg.SourceRetention.Duration
+	}
+	return defaultSourceRetention
+}
+
+// RetainedSourceVolumes splits the migrated volumes whose source FCD is still kept into those
+// whose retention has expired at now and those still retained
+func RetainedSourceVolumes(status *migrationv1alpha1.CSIVolumeMigrationStatus, now time.Time) (expired, retained []*migrationv1alpha1.PVMigrationState) {
+	if status == nil {
+		return nil, nil
+	}
+	for i := range status.Volumes {
+		pvState := &status.Volumes[i]
+		if pvState.Status != PVStatusComplete || pvState.SourceRetainedUntil == nil {
+			continue
+		}
+		if now.Before(pvState.SourceRetainedUntil.Time) {
+			retained = append(retained, pvState)
+		} else {
+			expired = append(expired, pvState)
+		}
+	}
+	return expired, retained
+}
+
+// deleteVolumeSnapshot deletes the pre-migration snapshot of a volume using the given client
+func (p *MigrateCSIVolumesPhase) deleteVolumeSnapshot(ctx context.Context, client *vsphere.Client, pvState *migrationv1alpha1.PVMigrationState) error {
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		return fmt.Errorf("failed to create FCD manager: %w", err)
+	}
+
+	if err := fcdManager.DeleteSnapshot(ctx, pvState.SourceVolumeID, pvState.SnapshotID); err != nil {
+		return err
+	}
+
+	pvState.SnapshotID = ""
+	return nil
+}
+
+// volumeSelector returns the configured volume selector, or nil to select every volume
+func volumeSelector(migration *migrationv1alpha1.VmwareCloudFoundationMigration) *migrationv1alpha1.VolumeSelector {
+	if migration.Spec.CSIVolumeMigration == nil {
+		return nil
+	}
+	return migration.Spec.CSIVolumeMigration.VolumeSelector
+}
+
+// VolumeTargetDatastore returns the datastore a volume is relocated to: its override, looked up
+// by PV name and then by PVC namespace/name, else the target datastore cluster, or else the
+// datastore of the first failure domain
+func VolumeTargetDatastore(migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) string {
+	if datastore := volumeDatastoreOverride(migration, pvState); datastore != "" {
+		return datastore
+	}
+	if cluster := targetDatastoreCluster(migration); cluster != "" {
+		return cluster
+	}
+	return migration.Spec.FailureDomains[0].Topology.Datastore
+}
+
+// volumeDatastoreOverride returns the datastore override of a volume, or an empty string
+func volumeDatastoreOverride(migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) string {
+	cfg := migration.Spec.CSIVolumeMigration
+	if cfg == nil {
+		return ""
 	}
+	if datastore, ok := cfg.VolumeDatastoreOverrides[pvState.PVName]; ok && datastore != "" {
+		return datastore
+	}
+	if pvState.PVCName != "" {
+		if datastore, ok := cfg.VolumeDatastoreOverrides[pvState.PVCNamespace+"/"+pvState.PVCName]; ok && datastore != "" {
+			return datastore
+		}
+	}
+	return ""
+}
 
-	// Detach FCD from dummy VM on target
-	// Note: After vMotion, the VM is on target vCenter
-	targetFCDManager, err := vsphere.NewFCDManager(ctx, targetClient)
-	if err != nil {
-		return fmt.Errorf("failed to create target FCD manager: %w", err)
+// targetDatastoreCluster returns the configured target datastore cluster, or an empty string
+func targetDatastoreCluster(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	if migration.Spec.CSIVolumeMigration == nil {
+		return ""
 	}
+	return migration.Spec.CSIVolumeMigration.TargetDatastoreCluster
+}
 
-	// Get the VM reference on target
-	targetVM, err := targetClient.GetVirtualMachine(ctx, fmt.Sprintf("/%s/vm/%s/%s",
-		targetFD.Topology.Datacenter, infraID, dummyVMName))
-	if err != nil {
-		return fmt.Errorf("failed to find dummy VM on target: %w", err)
+// TargetsDatastoreCluster reports whether a volume is relocated to the target datastore cluster,
+// so Storage DRS picks its datastore
+func TargetsDatastoreCluster(migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) bool {
+	return targetDatastoreCluster(migration) != "" && volumeDatastoreOverride(migration, pvState) == ""
+}
+
+// overrideDatastores returns the distinct datastores named in VolumeDatastoreOverrides, sorted
+func overrideDatastores(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []string {
+	if migration.Spec.CSIVolumeMigration == nil {
+		return nil
+	}
+	var datastores []string
+	for _, datastore := range migration.Spec.CSIVolumeMigration.VolumeDatastoreOverrides {
+		if datastore != "" && !slices.Contains(datastores, datastore) {
+			datastores = append(datastores, datastore)
+		}
 	}
+	slices.Sort(datastores)
+	return datastores
+}
 
-	if err := targetFCDManager.DetachDisk(ctx, targetVM, fcdID); err != nil {
-		logger.Error(err, "Failed to detach FCD from dummy VM on target", "fcdID", fcdID)
-		// Continue anyway, the disk might already be detached
+// forceDeleteBlockingPods reports whether pods keeping a deleted PVC Terminating are force-deleted
+func forceDeleteBlockingPods(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.ForceDeleteBlockingPods
+}
+
+// reservedSCSIUnits returns the SCSI units volumes are never attached at on the dummy VM
+func reservedSCSIUnits(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []int32 {
+	if migration.Spec.CSIVolumeMigration == nil {
+		return nil
 	}
+	return migration.Spec.CSIVolumeMigration.ReservedSCSIUnits
+}
 
-	// Update state
-	pvState.TargetVolumeID = fcdID // FCD ID remains the same after vMotion
-	pvState.TargetVolumePath = vsphere.BuildCSIVolumeHandle(fcdID)
-	pvState.Status = PVStatusRelocated
+// relocateTargetHost returns the host relocated volumes are pinned to, or an empty string to let
+// DRS place them
+func relocateTargetHost(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	if migration.Spec.CSIVolumeMigration == nil {
+		return ""
+	}
+	return migration.Spec.CSIVolumeMigration.TargetHost
+}
 
-	logger.Info("Successfully relocated volume", "pv", pvState.PVName, "fcdID", fcdID)
-	return nil
+// quiesceExcludeNamespaces returns the namespaces whose workloads must not be scaled down
+func quiesceExcludeNamespaces(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []string {
+	if migration.Spec.CSIVolumeMigration == nil {
+		return nil
+	}
+	return migration.Spec.CSIVolumeMigration.QuiesceExcludeNamespaces
 }
 
 // registerVolume registers the volume with CNS on the target vCenter
-func (p *MigrateCSIVolumesPhase) registerVolume(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+func (p *MigrateCSIVolumesPhase) registerVolume(ctx context.Context, pvManager *openshift.PersistentVolumeManager, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
 	logger := klog.FromContext(ctx)
 
 	// Create CNS manager
@@ -872,15 +2397,30 @@ func (p *MigrateCSIVolumesPhase) registerVolume(ctx context.Context, targetClien
 		return fmt.Errorf("failed to get infrastructure ID: %w", err)
 	}
 
-	// Get target failure domain for datastore info
-	targetFD := migration.Spec.FailureDomains[0]
-
-	// Build backing path
-	backingPath := fmt.Sprintf("[%s] fcd/%s.vmdk",
-		targetFD.Topology.Datastore, pvState.TargetVolumeID)
+	backingPath, resolved, err := p.volumeBackingPath(ctx, targetClient, migration, pvState)
+	if err != nil {
+		return err
+	}
 
-	// Register volume with CNS
-	_, err = cnsManager.RegisterVolume(ctx, backingPath, pvState.PVName, "", infraID)
+	// Register volume with CNS, keeping its association with the PV and PVC
+	entity := p.volumeEntityMetadata(ctx, pvManager, pvState)
+	_, err = cnsManager.RegisterVolume(ctx, backingPath, pvState.PVName, "", infraID, entity)
+	if err != nil && !resolved && !vsphere.IsCNSAlreadyRegistered(err) {
+		// The configured datastore was assumed; retry on the path the target reports, if it
+		// can be read by now and differs
+		if actual, pathErr := p.targetBackingPath(ctx, targetClient, pvState); pathErr == nil && actual != backingPath {
+			logger.Info("Retrying CNS registration on the volume's actual backing path",
+				"pv", pvState.PVName, "assumedPath", backingPath, "backingPath", actual, "fault", err)
+			backingPath = actual
+			_, err = cnsManager.RegisterVolume(ctx, backingPath, pvState.PVName, "", infraID, entity)
+		}
+	}
+	if vsphere.IsCNSAlreadyRegistered(err) {
+		// A previous attempt registered the volume before its status was recorded
+		logger.Info("Volume already registered with CNS", "volumeID", pvState.TargetVolumeID, "fault", err)
+		pvState.Status = PVStatusRegistered
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to register volume with CNS: %w", err)
 	}
@@ -890,16 +2430,202 @@ func (p *MigrateCSIVolumesPhase) registerVolume(ctx context.Context, targetClien
 	return nil
 }
 
-// updatePVAndClearClaimRef updates the PV's volumeHandle and clears the claimRef
-func (p *MigrateCSIVolumesPhase) updatePVAndClearClaimRef(ctx context.Context, pvManager *openshift.PersistentVolumeManager, pvState *migrationv1alpha1.PVMigrationState) error {
+// volumeBackingPath returns the VMDK path to register a migrated volume with CNS, and whether it
+// was read from the target rather than assumed. A cloned volume keeps the path its disk was copied
+// to. A relocated volume's path is read back from the target, since Storage DRS or a datastore
+// cluster can place it on another datastore than the configured one; if that fails, the path on
+// the volume's target datastore is assumed, except for a datastore cluster, which has no single
+// datastore to assume.
+func (p *MigrateCSIVolumesPhase) volumeBackingPath(ctx context.Context, targetClient *vsphere.Client, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) (string, bool, error) {
+	logger := klog.FromContext(ctx)
+
+	if _, _, err := vsphere.ParseDatastorePath(pvState.TargetVolumePath); err == nil {
+		return pvState.TargetVolumePath, true, nil
+	}
+
+	datastore := VolumeTargetDatastore(migration, pvState)
+	backingPath, err := p.targetBackingPath(ctx, targetClient, pvState)
+	if err != nil {
+		if TargetsDatastoreCluster(migration, pvState) {
+			return "", false, fmt.Errorf("failed to find relocated FCD in datastore cluster %s: %w", datastore, err)
+		}
+		assumed := fmt.Sprintf("[%s] fcd/%s.vmdk", path.Base(datastore), pvState.TargetVolumeID)
+		logger.Error(err, "Failed to read backing path of relocated FCD, assuming its target datastore",
+			"pv", pvState.PVName, "backingPath", assumed)
+		return assumed, false, nil
+	}
+
+	if name, _, _ := vsphere.ParseDatastorePath(backingPath); name != path.Base(datastore) {
+		logger.Info("Volume landed on another datastore than its target", "pv", pvState.PVName,
+			"targetDatastore", datastore, "backingPath", backingPath)
+	}
+	return backingPath, true, nil
+}
+
+// targetBackingPath reads the VMDK path of a relocated volume from the target vCenter
+func (p *MigrateCSIVolumesPhase) targetBackingPath(ctx context.Context, targetClient *vsphere.Client, pvState *migrationv1alpha1.PVMigrationState) (string, error) {
+	targetFCDManager, err := vsphere.NewFCDManager(ctx, targetClient)
+	if err != nil {
+		return "", fmt.Errorf("failed to create target FCD manager: %w", err)
+	}
+	return targetFCDManager.BackingPath(ctx, pvState.TargetVolumeID)
+}
+
+// recordVolumeMetadata records the CNS labels and vSphere tags of the source volume on its state,
+// unless an earlier attempt already did. Metadata that cannot be read is only logged: it is lost
+// on the target, but the volume itself is unaffected.
+func (p *MigrateCSIVolumesPhase) recordVolumeMetadata(ctx context.Context, sourceClient *vsphere.Client, sourceFCDManager *vsphere.FCDManager, pvState *migrationv1alpha1.PVMigrationState) {
+	logger := klog.FromContext(ctx)
+
+	if pvState.SourceCNSLabels == nil {
+		cnsManager, err := vsphere.NewCNSManager(ctx, sourceClient)
+		var labels map[string]string
+		if err == nil {
+			labels, err = cnsManager.GetVolumeLabels(ctx, pvState.SourceVolumeID)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to read CNS labels of source volume, they will not be restored", "pv", pvState.PVName)
+		} else if len(labels) > 0 {
+			pvState.SourceCNSLabels = labels
+		}
+	}
+
+	if pvState.SourceTags == nil {
+		tags, err := sourceFCDManager.ListVolumeTags(ctx, pvState.SourceVolumeID)
+		if err != nil {
+			logger.Error(err, "Failed to read vSphere tags of source volume, they will not be restored", "pv", pvState.PVName)
+		}
+		for _, tag := range tags {
+			pvState.SourceTags = append(pvState.SourceTags, migrationv1alpha1.VolumeTag{Category: tag.Category, Name: tag.Name})
+		}
+	}
+
+	if len(pvState.SourceCNSLabels) > 0 || len(pvState.SourceTags) > 0 {
+		logger.Info("Recorded source volume metadata",
+			"pv", pvState.PVName,
+			"cnsLabels", len(pvState.SourceCNSLabels),
+			"tags", len(pvState.SourceTags))
+	}
+}
+
+// restoreVolumeMetadata applies the CNS labels and vSphere tags recorded from the source volume
+// to the volume registered on the target. Metadata that cannot be restored, such as tags when
+// the target's tag API is unavailable, does not fail the volume and is logged with a warning.
+func (p *MigrateCSIVolumesPhase) restoreVolumeMetadata(ctx context.Context, targetClient *vsphere.Client, pvState *migrationv1alpha1.PVMigrationState, logs []migrationv1alpha1.LogEntry) []migrationv1alpha1.LogEntry {
+	logger := klog.FromContext(ctx)
+
+	if len(pvState.SourceCNSLabels) > 0 {
+		cnsManager, err := vsphere.NewCNSManager(ctx, targetClient)
+		if err == nil {
+			err = cnsManager.SetVolumeLabels(ctx, pvState.TargetVolumeID, pvState.SourceCNSLabels)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to restore CNS labels", "pv", pvState.PVName, "labels", pvState.SourceCNSLabels)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("Could not restore CNS labels of PV %s: %v", pvState.PVName, err),
+				string(p.Name()))
+		}
+	}
+
+	if len(pvState.SourceTags) == 0 {
+		return logs
+	}
+
+	var unrestored []string
+	fcdManager, err := vsphere.NewFCDManager(ctx, targetClient)
+	switch {
+	case targetClient.TagManager() == nil:
+		unrestored = volumeTagNames(pvState.SourceTags)
+		logger.Info("Tag API unavailable on target vCenter, volume tags not restored", "pv", pvState.PVName, "tags", unrestored)
+	case err != nil:
+		unrestored = volumeTagNames(pvState.SourceTags)
+		logger.Error(err, "Failed to create FCD manager to restore volume tags", "pv", pvState.PVName, "tags", unrestored)
+	default:
+		// A volume moved within one vCenter keeps its tags
+		attached, err := fcdManager.ListVolumeTags(ctx, pvState.TargetVolumeID)
+		if err != nil {
+			logger.Error(err, "Failed to list tags of target volume", "pv", pvState.PVName)
+		}
+		for _, tag := range pvState.SourceTags {
+			volumeTag := vsphere.VolumeTag{Category: tag.Category, Name: tag.Name}
+			if slices.Contains(attached, volumeTag) {
+				continue
+			}
+			if _, err = targetClient.EnsureTag(ctx, tag.Category, tag.Name); err == nil {
+				err = fcdManager.AttachVolumeTag(ctx, pvState.TargetVolumeID, volumeTag)
+			}
+			if err != nil {
+				logger.Error(err, "Failed to restore volume tag", "pv", pvState.PVName, "category", tag.Category, "tag", tag.Name)
+				unrestored = append(unrestored, tag.Category+"/"+tag.Name)
+			}
+		}
+	}
+
+	if len(unrestored) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Could not restore vSphere tags of PV %s: %s", pvState.PVName, strings.Join(unrestored, ", ")),
+			string(p.Name()))
+	}
+	return logs
+}
+
+// volumeTagNames returns volume tags as <category>/<name>, for logs
+func volumeTagNames(tags []migrationv1alpha1.VolumeTag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Category+"/"+tag.Name)
+	}
+	return names
+}
+
+// volumeEntityMetadata collects the PV and PVC identity recorded against the volume in CNS.
+// The PVC has already been deleted at this point, so its labels come from the PVC backup
+// when one was taken.
+func (p *MigrateCSIVolumesPhase) volumeEntityMetadata(ctx context.Context, pvManager *openshift.PersistentVolumeManager, pvState *migrationv1alpha1.PVMigrationState) *vsphere.VolumeEntityMetadata {
+	logger := klog.FromContext(ctx)
+
+	entity := &vsphere.VolumeEntityMetadata{
+		PVName:       pvState.PVName,
+		PVCName:      pvState.PVCName,
+		PVCNamespace: pvState.PVCNamespace,
+	}
+
+	pv, err := pvManager.GetPV(ctx, pvState.PVName)
+	if err != nil {
+		logger.Error(err, "Failed to get PV labels for CNS metadata", "pv", pvState.PVName)
+	} else {
+		entity.PVLabels = pv.Labels
+	}
+
+	if pvState.PVCSpec != "" {
+		backup, err := openshift.DecodePVCBackup(pvState.PVCSpec)
+		if err != nil {
+			logger.Error(err, "Failed to decode PVC backup for CNS metadata", "pv", pvState.PVName)
+		} else {
+			entity.PVCLabels = backup.Labels
+		}
+	}
+
+	return entity
+}
+
+// updatePVAndClearClaimRef updates the PV's volumeHandle and clears the claimRef. The claimRef of a
+// kept PVC is left in place so the PV stays bound to it.
+func (p *MigrateCSIVolumesPhase) updatePVAndClearClaimRef(ctx context.Context, pvManager *openshift.PersistentVolumeManager, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
 	logger := klog.FromContext(ctx)
 
-	// Update the PV's volumeHandle
+	// Update the PV's volumeHandle, recording where it was migrated from
 	newHandle := vsphere.BuildCSIVolumeHandle(pvState.TargetVolumeID)
-	if err := pvManager.UpdatePVVolumeHandle(ctx, pvState.PVName, newHandle); err != nil {
+	if err := pvManager.UpdatePVVolumeHandle(ctx, pvState.PVName, newHandle, migrationProvenance(migration, pvState)); err != nil {
 		return fmt.Errorf("failed to update volumeHandle: %w", err)
 	}
 
+	if keepsPVC(pvState) {
+		pvState.Status = PVStatusPVUpdated
+		logger.Info("Updated PV, keeping its claimRef to the kept PVC", "pv", pvState.PVName, "newHandle", newHandle)
+		return nil
+	}
+
 	// Clear claimRef to make PV Available for rebinding
 	if err := pvManager.ClearPVClaimRef(ctx, pvState.PVName); err != nil {
 		return fmt.Errorf("failed to clear claimRef: %w", err)
@@ -910,45 +2636,144 @@ func (p *MigrateCSIVolumesPhase) updatePVAndClearClaimRef(ctx context.Context, p
 	return nil
 }
 
-// restorePVCAndWorkloads recreates PVC (for non-StatefulSet) and restores workloads
-func (p *MigrateCSIVolumesPhase) restorePVCAndWorkloads(ctx context.Context, pvManager *openshift.PersistentVolumeManager, workloadManager *openshift.WorkloadManager, pvState *migrationv1alpha1.PVMigrationState) error {
+// updateUnboundPV points an unbound PV at its migrated volume and restores its reclaim policy. The
+// claimRef is left as it is, so a Released or Failed PV does not become Available for a new claim.
+func (p *MigrateCSIVolumesPhase) updateUnboundPV(ctx context.Context, pvManager *openshift.PersistentVolumeManager, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
 	logger := klog.FromContext(ctx)
 
-	// For StatefulSet workloads, the StatefulSet controller will recreate the PVC
-	// from volumeClaimTemplates when the pods are restored
-	if pvState.WorkloadType == "StatefulSet" {
-		logger.Info("StatefulSet workload - skipping PVC recreation (StatefulSet controller will handle it)",
-			"pv", pvState.PVName)
-	} else if pvState.PVCSpec != "" {
-		// Recreate PVC for non-StatefulSet workloads
-		logger.Info("Recreating PVC for non-StatefulSet workload",
-			"pv", pvState.PVName,
-			"workloadType", pvState.WorkloadType)
+	newHandle := vsphere.BuildCSIVolumeHandle(pvState.TargetVolumeID)
+	if err := pvManager.UpdatePVVolumeHandle(ctx, pvState.PVName, newHandle, migrationProvenance(migration, pvState)); err != nil {
+		return fmt.Errorf("failed to update volumeHandle: %w", err)
+	}
+	pvState.Status = PVStatusPVUpdated
 
-		if err := pvManager.RestorePVC(ctx, pvState.PVCSpec, pvState.PVName); err != nil {
-			return fmt.Errorf("failed to restore PVC: %w", err)
-		}
+	if err := RestoreReclaimPolicy(ctx, pvManager, pvState); err != nil {
+		logger.Error(err, "Failed to restore reclaim policy of migrated volume, it keeps reclaim policy Retain", "pv", pvState.PVName)
+	}
+
+	logger.Info("Updated unbound PV", "pv", pvState.PVName, "phase", pvState.PVPhase, "newHandle", newHandle)
+	return nil
+}
+
+// UnboundVolume reports whether a volume had no bound claim when it was discovered
+func UnboundVolume(pvState *migrationv1alpha1.PVMigrationState) bool {
+	return openshift.IsUnboundPhase(corev1.PersistentVolumePhase(pvState.PVPhase))
+}
 
-		// Wait for PVC to bind to the PV
-		if err := pvManager.WaitForPVCBound(ctx, pvState.PVCNamespace, pvState.PVCName, 2*time.Minute); err != nil {
-			return fmt.Errorf("timeout waiting for PVC to bind: %w", err)
+// restorePVCAndWorkloads recreates PVC (for non-StatefulSet) and restores workloads
+func (p *MigrateCSIVolumesPhase) restorePVCAndWorkloads(ctx context.Context, pvManager *openshift.PersistentVolumeManager, workloadManager *openshift.WorkloadManager, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	logger := klog.FromContext(ctx)
+
+	// The PVC of a StatefulSet claim was kept and is rebound, so the StatefulSet controller finds
+	// it bound to the migrated PV rather than provisioning a new volume on scale-up
+	if keepsPVC(pvState) {
+		if err := rebindPVC(ctx, pvManager, migration, pvState); err != nil {
+			return err
 		}
+	} else if err := restorePVC(ctx, pvManager, migration, pvState); err != nil {
+		return err
+	}
 
-		logger.Info("PVC recreated and bound", "pvc", pvState.PVCName, "pv", pvState.PVName)
+	// The volume is safely bound on the target, so it no longer needs to be retained. A failure
+	// here leaves the PV on Retain but must not keep the workloads down.
+	if err := RestoreReclaimPolicy(ctx, pvManager, pvState); err != nil {
+		logger.Error(err, "Failed to restore reclaim policy of migrated volume, it keeps reclaim policy Retain", "pv", pvState.PVName)
 	}
 
 	// Restore workloads
 	if len(pvState.ScaledDownResources) > 0 {
 		logger.Info("Restoring workloads", "pv", pvState.PVName, "count", len(pvState.ScaledDownResources))
+		restoreStart := time.Now()
 		if err := workloadManager.RestoreWorkloads(ctx, pvState.ScaledDownResources); err != nil {
 			return fmt.Errorf("failed to restore workloads: %w", err)
 		}
+
+		// Scaling up does not replace a StatefulSet's leftover pods under OnDelete or below its
+		// partition, so any that never started are deleted to be recreated on the migrated volume
+		for _, resource := range pvState.ScaledDownResources {
+			deleted, err := workloadManager.ReplacePlaceholderPods(ctx, resource, restoreStart)
+			if err != nil {
+				logger.Error(err, "Failed to replace placeholder pods of StatefulSet", "pv", pvState.PVName,
+					"statefulSet", resource.Name, "namespace", resource.Namespace)
+			}
+			if len(deleted) > 0 {
+				logger.Info("Replaced placeholder pods of StatefulSet", "pv", pvState.PVName,
+					"statefulSet", resource.Name, "pods", deleted, "updateStrategy", resource.UpdateStrategy)
+			}
+		}
 	}
 
 	logger.Info("Successfully restored PVC and workloads", "pv", pvState.PVName)
 	return nil
 }
 
+// migrationName is the namespace/name of a migration, as recorded in the provenance of the
+// volumes it moved
+func migrationName(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	return migration.Namespace + "/" + migration.Name
+}
+
+// migrationProvenance describes the migration of a volume for the annotations of its PV and PVC
+func migrationProvenance(migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) *openshift.MigrationProvenance {
+	return &openshift.MigrationProvenance{
+		SourceVolumeHandle: pvState.SourceVolumePath,
+		MigrationName:      migrationName(migration),
+		MigratedAt:         time.Now(),
+	}
+}
+
+// restorePVC recreates the backed up PVC of a migrated volume, bound to its PV
+func restorePVC(ctx context.Context, pvManager *openshift.PersistentVolumeManager, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	if pvState.PVCSpec == "" {
+		return nil
+	}
+
+	logger := klog.FromContext(ctx)
+	logger.Info("Recreating PVC for non-StatefulSet workload",
+		"pv", pvState.PVName,
+		"workloadType", pvState.WorkloadType)
+
+	if err := pvManager.RestorePVC(ctx, pvState.PVCSpec, pvState.PVName, migrationProvenance(migration, pvState)); err != nil {
+		return fmt.Errorf("failed to restore PVC: %w", err)
+	}
+
+	// Wait for PVC to bind to the PV
+	if err := pvManager.WaitForPVCBound(ctx, pvState.PVCNamespace, pvState.PVCName, 2*time.Minute); err != nil {
+		return fmt.Errorf("timeout waiting for PVC to bind: %w", err)
+	}
+
+	logger.Info("PVC recreated and bound", "pvc", pvState.PVCName, "pv", pvState.PVName)
+	return nil
+}
+
+// keepsPVC reports whether a volume's PVC is kept through the migration and rebound to its PV
+// rather than deleted and recreated. This is the case for the claims a StatefulSet created from
+// its volumeClaimTemplates, which its controller would otherwise recreate against a new volume,
+// and for every claim with the InPlaceHandleSwap strategy.
+func keepsPVC(pvState *migrationv1alpha1.PVMigrationState) bool {
+	return (pvState.WorkloadType == "StatefulSet" || pvState.InPlaceHandleSwap) && pvState.PVCName != ""
+}
+
+// inPlaceHandleSwap reports whether PVCs are kept bound while the volumeHandle of their PV is swapped
+func inPlaceHandleSwap(migration *migrationv1alpha1.VmwareCloudFoundationMigration) bool {
+	return migration.Spec.CSIVolumeMigration != nil &&
+		migration.Spec.CSIVolumeMigration.MigrationStrategy == migrationv1alpha1.PVCStrategyInPlaceHandleSwap
+}
+
+// rebindPVC binds the kept PVC of a volume to its migrated PV
+func rebindPVC(ctx context.Context, pvManager *openshift.PersistentVolumeManager, migration *migrationv1alpha1.VmwareCloudFoundationMigration, pvState *migrationv1alpha1.PVMigrationState) error {
+	if err := pvManager.RebindPVC(ctx, pvState.PVCNamespace, pvState.PVCName, pvState.PVName, migrationProvenance(migration, pvState)); err != nil {
+		return fmt.Errorf("failed to rebind PVC: %w", err)
+	}
+
+	if err := pvManager.WaitForPVCBound(ctx, pvState.PVCNamespace, pvState.PVCName, 2*time.Minute); err != nil {
+		return fmt.Errorf("timeout waiting for PVC to bind: %w", err)
+	}
+
+	klog.FromContext(ctx).Info("Kept PVC rebound", "pvc", pvState.PVCName, "pv", pvState.PVName)
+	return nil
+}
+
 // preflightCheck performs health checks before starting CSI volume migration
 // Detects stuck VolumeAttachments and logs warnings
 func (p *MigrateCSIVolumesPhase) preflightCheck(ctx context.Context, logs *[]migrationv1alpha1.LogEntry) error {
@@ -1002,6 +2827,155 @@ func (p *MigrateCSIVolumesPhase) preflightCheck(ctx context.Context, logs *[]mig
 	return nil
 }
 
+// CleanupOnDeletion releases what an interrupted volume migration holds when the migration is deleted.
+// Dummy VMs left behind by an interrupted relocation are deleted, after detaching their FCD, and
+// workloads still scaled down are restored. Volumes cannot be rolled back automatically, so the
+// ones left part-way are returned as "pv (status)" for reporting.
+func (p *MigrateCSIVolumesPhase) CleanupOnDeletion(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) ([]string, error) {
+	inFlight := inFlightVolumes(migration)
+	if len(inFlight) == 0 {
+		return nil, nil
+	}
+
+	if err := p.deleteDummyVMs(ctx, migration, inFlight); err != nil {
+		return nil, err
+	}
+
+	workloadManager := openshift.NewWorkloadManager(p.executor.kubeClient)
+	var errs []error
+	abandoned := make([]string, 0, len(inFlight))
+	for _, pvState := range inFlight {
+		if len(pvState.ScaledDownResources) > 0 {
+			if err := workloadManager.RestoreWorkloads(ctx, pvState.ScaledDownResources); err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore workloads for PV %s: %w", pvState.PVName, err))
+			}
+		}
+		abandoned = append(abandoned, fmt.Sprintf("%s (%s)", pvState.PVName, pvState.Status))
+	}
+
+	return abandoned, errors.Join(errs...)
+}
+
+// LeftOnDeletion describes what CleanupOnDeletion would release for each volume left part-way,
+// for recording when a deleted migration is released without it
+func LeftOnDeletion(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []string {
+	inFlight := inFlightVolumes(migration)
+	left := make([]string, 0, len(inFlight))
+	for _, pvState := range inFlight {
+		var details []string
+		if pvState.DummyVMName != "" {
+			details = append(details, "dummy VM "+pvState.DummyVMName)
+		}
+		for _, resource := range pvState.ScaledDownResources {
+			details = append(details, fmt.Sprintf("%s %s/%s scaled down from %d", resource.Kind, resource.Namespace, resource.Name, resource.OriginalReplicas))
+		}
+		entry := fmt.Sprintf("PV %s (%s)", pvState.PVName, pvState.Status)
+		if len(details) > 0 {
+			entry += ": " + strings.Join(details, ", ")
+		}
+		left = append(left, entry)
+	}
+	return left
+}
+
+// inFlightVolumes returns the volumes a deleted migration left part-way
+func inFlightVolumes(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []*migrationv1alpha1.PVMigrationState {
+	if migration.Status.CSIVolumeMigration == nil {
+		return nil
+	}
+	var inFlight []*migrationv1alpha1.PVMigrationState
+	for i := range migration.Status.CSIVolumeMigration.Volumes {
+		pvState := &migration.Status.CSIVolumeMigration.Volumes[i]
+		switch pvState.Status {
+		case PVStatusPending, PVStatusWorkloadsRestored, PVStatusComplete, PVStatusSkipped, PVStatusSourceMissing:
+			continue
+		}
+		inFlight = append(inFlight, pvState)
+	}
+	return inFlight
+}
+
+// deleteDummyVMs deletes the dummy VMs of the given volumes from the migration folder on the source
+// and target vCenters. Their FCDs are detached first so destroying a VM cannot delete a volume.
+func (p *MigrateCSIVolumesPhase) deleteDummyVMs(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, volumes []*migrationv1alpha1.PVMigrationState) error {
+	logger := klog.FromContext(ctx)
+
+	// Volumes relocated as a batch share a dummy VM, so collect every FCD it may hold
+	var dummyVMNames []string
+	fcdIDs := make(map[string][]string)
+	for _, pvState := range volumes {
+		if pvState.DummyVMName == "" {
+			continue
+		}
+		if !slices.Contains(dummyVMNames, pvState.DummyVMName) {
+			dummyVMNames = append(dummyVMNames, pvState.DummyVMName)
+		}
+		if pvState.SourceVolumeID != "" {
+			fcdIDs[pvState.DummyVMName] = append(fcdIDs[pvState.DummyVMName], pvState.SourceVolumeID)
+		}
+	}
+	if len(dummyVMNames) == 0 {
+		return nil
+	}
+
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return fmt.Errorf("failed to get source vCenter: %w", err)
+	}
+	sourceFD, err := p.executor.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return fmt.Errorf("failed to get source failure domain: %w", err)
+	}
+	targetFD := migration.Spec.FailureDomains[0]
+
+	sourceClient, err := p.executor.GetVSphereClientFromMigration(ctx, migration, sourceVC.Server)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source vCenter: %w", err)
+	}
+	defer sourceClient.Logout(ctx)
+
+	targetClient, err := p.executor.GetVSphereClientFromMigration(ctx, migration, targetFD.Server)
+	if err != nil {
+		return fmt.Errorf("failed to connect to target vCenter: %w", err)
+	}
+	defer targetClient.Logout(ctx)
+
+	// The dummy VM is on the source until vMotion completes and on the target afterwards
+	locations := []struct {
+		client *vsphere.Client
+		folder string
+	}{
+		{sourceClient, util.FailureDomainFolder(sourceFD.Topology, infraID)},
+		{targetClient, util.FailureDomainFolder(targetFD.Topology, infraID)},
+	}
+
+	var errs []error
+	for _, dummyVMName := range dummyVMNames {
+		for _, location := range locations {
+			vmPath := path.Join(location.folder, dummyVMName)
+			vm, err := location.client.GetVirtualMachine(ctx, vmPath)
+			if vsphere.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to look up dummy VM %s: %w", vmPath, err))
+				continue
+			}
+
+			logger.Info("Deleting orphaned dummy VM", "vm", vmPath, "fcdIDs", fcdIDs[dummyVMName])
+			if err := vsphere.NewVMRelocator(location.client, location.client).DeleteDummyVM(ctx, vm, fcdIDs[dummyVMName]...); err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete dummy VM %s: %w", vmPath, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // Rollback reverts the phase changes
 func (p *MigrateCSIVolumesPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
@@ -1014,16 +2988,56 @@ func (p *MigrateCSIVolumesPhase) Rollback(ctx context.Context, migration *migrat
 	pvManager := openshift.NewPersistentVolumeManager(p.executor.kubeClient)
 	workloadManager := openshift.NewWorkloadManager(p.executor.kubeClient)
 
+	// Completed volumes live on the target; clean up any snapshot left behind there
+	var targetClient *vsphere.Client
+	for i := range migration.Status.CSIVolumeMigration.Volumes {
+		pvState := &migration.Status.CSIVolumeMigration.Volumes[i]
+		if pvState.Status != PVStatusComplete || pvState.SnapshotID == "" {
+			continue
+		}
+
+		if targetClient == nil {
+			client, err := p.executor.GetVSphereClientFromMigration(ctx, migration, migration.Spec.FailureDomains[0].Server)
+			if err != nil {
+				logger.Error(err, "Failed to connect to target vCenter, leaving pre-migration snapshots in place")
+				break
+			}
+			defer client.Logout(ctx)
+			targetClient = client
+		}
+
+		if err := p.deleteVolumeSnapshot(ctx, targetClient, pvState); err != nil {
+			logger.Error(err, "Failed to delete pre-migration snapshot", "pv", pvState.PVName, "snapshotID", pvState.SnapshotID)
+		}
+	}
+
 	for i := range migration.Status.CSIVolumeMigration.Volumes {
 		pvState := &migration.Status.CSIVolumeMigration.Volumes[i]
 
-		// Skip completed volumes - they were successfully migrated
-		if pvState.Status == PVStatusComplete {
+		// Skip completed volumes - they were successfully migrated - and skipped volumes that were never touched
+		if pvState.Status == PVStatusComplete || pvState.Status == PVStatusWorkloadsRestored || pvState.Status == PVStatusSkipped {
+			continue
+		}
+
+		// Restoring the PVC or workloads of a volume left in an unknown state could lose data
+		if pvState.ErrorClass == phaseerrors.ClassDataSafety {
+			logger.Info("Leaving volume for manual intervention, its failure is not safe to roll back",
+				"pv", pvState.PVName,
+				"error", pvState.Message,
+				"scaledDownResources", len(pvState.ScaledDownResources))
 			continue
 		}
 
 		logger.Info("Rolling back PV", "pv", pvState.PVName, "status", pvState.Status)
 
+		// Keep the pre-migration snapshot of unfinished volumes so data can be recovered
+		if pvState.SnapshotID != "" {
+			logger.Info("Retaining pre-migration snapshot for recovery",
+				"pv", pvState.PVName,
+				"fcdID", pvState.SourceVolumeID,
+				"snapshotID", pvState.SnapshotID)
+		}
+
 		// Restore original reclaim policy if it was changed
 		if pvState.OriginalReclaimPolicy != "" {
 			originalPolicy := corev1.PersistentVolumeReclaimPolicy(pvState.OriginalReclaimPolicy)
@@ -1043,7 +3057,7 @@ func (p *MigrateCSIVolumesPhase) Rollback(ctx context.Context, migration *migrat
 			pvState.Status == PVStatusFailed) {
 
 			logger.Info("Attempting to restore PVC from backup", "pv", pvState.PVName)
-			if err := pvManager.RestorePVC(ctx, pvState.PVCSpec, pvState.PVName); err != nil {
+			if err := pvManager.RestorePVC(ctx, pvState.PVCSpec, pvState.PVName, nil); err != nil {
 				logger.Error(err, "Failed to restore PVC from backup", "pv", pvState.PVName)
 			} else {
 				logger.Info("Restored PVC from backup", "pv", pvState.PVName)
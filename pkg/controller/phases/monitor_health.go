@@ -50,7 +50,7 @@ func (p *MonitorHealthPhase) Execute(ctx context.Context, migration *migrationv1
 		"Checking cluster operators health",
 		string(p.Name()))
 
-	healthy, unhealthyList, err := p.operatorManager.CheckAllOperatorsHealthy(ctx)
+	degraded, err := p.operatorManager.GetDegradedClusterOperators(ctx, toleratedOperators(migration))
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -59,8 +59,40 @@ func (p *MonitorHealthPhase) Execute(ctx context.Context, migration *migrationv1
 		}, err
 	}
 
-	if !healthy {
-		msg := fmt.Sprintf("Waiting for operators to become healthy: %s", strings.Join(unhealthyList, ", "))
+	if len(degraded) > 0 {
+		names := make([]string, 0, len(degraded))
+		for _, operator := range degraded {
+			names = append(names, operator.Name)
+			logger.Info("ClusterOperator is unhealthy",
+				"operator", operator.Name,
+				"available", operator.Available,
+				"degraded", operator.Degraded,
+				"progressing", operator.Progressing,
+				"message", operator.Message)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("ClusterOperator %s is unhealthy (available=%v, degraded=%v): %s",
+					operator.Name, operator.Available, operator.Degraded, operator.Message),
+				string(p.Name()))
+		}
+
+		timeout := healthCheckTimeout(migration)
+		if migration.Status.CurrentPhaseState != nil && migration.Status.CurrentPhaseState.StartTime != nil {
+			elapsed := time.Since(migration.Status.CurrentPhaseState.StartTime.Time)
+			if elapsed > timeout {
+				err := fmt.Errorf("cluster is unhealthy after %s: degraded or unavailable operators: %s",
+					timeout, strings.Join(names, ", "))
+				logger.Error(err, "Cluster health check timed out")
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, err.Error(), string(p.Name()))
+
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: err.Error(),
+					Logs:    logs,
+				}, err
+			}
+		}
+
+		msg := fmt.Sprintf("Waiting for operators to become healthy: %s", strings.Join(names, ", "))
 		logger.Info(msg)
 		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
 
@@ -100,6 +132,26 @@ func (p *MonitorHealthPhase) Execute(ctx context.Context, migration *migrationv1
 	}, nil
 }
 
+// defaultHealthCheckTimeout bounds how long MonitorHealth waits for ClusterOperators to recover
+const defaultHealthCheckTimeout = 30 * time.Minute
+
+// toleratedOperators returns the ClusterOperators allowed to stay degraded during MonitorHealth
+func toleratedOperators(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []string {
+	if migration.Spec.HealthCheck == nil {
+		return nil
+	}
+	return migration.Spec.HealthCheck.ToleratedOperators
+}
+
+// healthCheckTimeout returns the configured MonitorHealth timeout, or the default
+func healthCheckTimeout(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if migration.Spec.HealthCheck != nil && migration.Spec.HealthCheck.Timeout != nil &&
+		migration.Spec.HealthCheck.Timeout.Duration > 0 {
+		return migration.Spec.HealthCheck.Timeout.Duration
+	}
+	return defaultHealthCheckTimeout
+}
+
 // Rollback reverts the phase changes
 func (p *MonitorHealthPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
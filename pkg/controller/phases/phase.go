@@ -2,6 +2,10 @@ package phases
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
@@ -14,6 +18,7 @@ import (
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
@@ -61,6 +66,9 @@ type PhaseExecutor struct {
 	restoreManager      *backup.RestoreManager
 	infraManager        *openshift.InfrastructureManager
 	secretManager       *openshift.SecretManager
+	credentialSource    CredentialSource
+	integrityChecker    IntegrityChecker
+	vcenterConnection   vsphere.Config
 	sourceClient        *vsphere.Client
 	targetClient        *vsphere.Client
 }
@@ -85,9 +93,108 @@ func NewPhaseExecutor(
 		restoreManager:      restoreManager,
 		infraManager:        openshift.NewInfrastructureManagerWithClients(configClient, kubeClient, apiextensionsClient),
 		secretManager:       openshift.NewSecretManager(kubeClient),
+		integrityChecker:    NoopIntegrityChecker{},
 	}
 }
 
+// CredentialSource selects where target vCenter credentials are read from instead of the
+// credentials secret of each migration. Command takes precedence over Dir.
+type CredentialSource struct {
+	// Dir is a directory holding one file per credential key, such as a Secrets Store CSI
+	// driver mount
+	Dir string
+
+	// Command is an executable given the vCenter server as its only argument that prints the
+	// credentials as a JSON object with username and password fields
+	Command string
+}
+
+// SetCredentialSource makes the executor read target vCenter credentials from source
+func (e *PhaseExecutor) SetCredentialSource(source CredentialSource) {
+	e.credentialSource = source
+}
+
+// externalTargetCredentials reports whether target vCenter credentials are read from outside
+// the migration's credentials secret
+func (e *PhaseExecutor) externalTargetCredentials() bool {
+	return e.credentialSource.Command != "" || e.credentialSource.Dir != ""
+}
+
+// targetCredentialSourceName describes where the target vCenter credentials of a migration are read from
+func (e *PhaseExecutor) targetCredentialSourceName(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	switch {
+	case e.credentialSource.Command != "":
+		return "command " + e.credentialSource.Command
+	case e.credentialSource.Dir != "":
+		return "directory " + e.credentialSource.Dir
+	}
+	return fmt.Sprintf("secret %s/%s", targetCredentialsSecretNamespace(migration), migration.Spec.TargetVCenterCredentialsSecret.Name)
+}
+
+// targetCredentialsSecretNamespace returns the namespace of the target credentials secret, which
+// defaults to the migration's
+func targetCredentialsSecretNamespace(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	if namespace := migration.Spec.TargetVCenterCredentialsSecret.Namespace; namespace != "" {
+		return namespace
+	}
+	return migration.Namespace
+}
+
+// TargetCredentialProvider returns the provider of the target vCenter credentials of a migration:
+// the configured command or directory, or else the migration's credentials secret, whose
+// namespace defaults to the migration's
+func (e *PhaseExecutor) TargetCredentialProvider(migration *migrationv1alpha1.VmwareCloudFoundationMigration) (openshift.CredentialProvider, error) {
+	if e.credentialSource.Command != "" {
+		return openshift.NewCommandCredentialProvider(e.credentialSource.Command), nil
+	}
+
+	keys, err := openshift.NewCredentialKeys(migration.Spec.TargetVCenterCredentialKeys)
+	if err != nil {
+		return nil, err
+	}
+	if e.credentialSource.Dir != "" {
+		return openshift.NewFileCredentialProvider(e.credentialSource.Dir, keys), nil
+	}
+
+	return openshift.NewSecretCredentialProvider(e.kubeClient, targetCredentialsSecretNamespace(migration),
+		migration.Spec.TargetVCenterCredentialsSecret.Name, keys), nil
+}
+
+// validateTargetCredentials checks credentials can be found for every target server of a
+// migration, listing each server that has none
+func (e *PhaseExecutor) validateTargetCredentials(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	var servers []string
+	for _, fd := range migration.Spec.FailureDomains {
+		if !slices.Contains(servers, fd.Server) {
+			servers = append(servers, fd.Server)
+		}
+	}
+
+	if !e.externalTargetCredentials() {
+		keys, err := openshift.NewCredentialKeys(migration.Spec.TargetVCenterCredentialKeys)
+		if err != nil {
+			return err
+		}
+		return e.secretManager.ValidateCredentialsForServers(ctx, targetCredentialsSecretNamespace(migration),
+			migration.Spec.TargetVCenterCredentialsSecret.Name, servers, keys)
+	}
+
+	provider, err := e.TargetCredentialProvider(migration)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	for _, server := range servers {
+		if _, _, err := provider.GetCredentials(ctx, server); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%v)", server, err))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("no credentials for target server(s): %s", strings.Join(missing, "; "))
+	}
+	return nil
+}
+
 // ExecutePhase executes a phase and updates the migration status
 func (e *PhaseExecutor) ExecutePhase(ctx context.Context, phase Phase, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*PhaseResult, error) {
 	// Only initialize phase state for a new phase execution.
@@ -114,8 +221,11 @@ func (e *PhaseExecutor) ExecutePhase(ctx context.Context, phase Phase, migration
 		Component: string(phase.Name()),
 	}
 
-	// Validate phase
+	// Validate phase. Errors the phase did not classify are configuration problems.
 	if err := phase.Validate(ctx, migration); err != nil {
+		if phaseerrors.Class(err) == nil {
+			err = phaseerrors.Validation(err)
+		}
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
 			Message: "Validation failed: " + err.Error(),
@@ -155,7 +265,8 @@ func (e *PhaseExecutor) ExecutePhase(ctx context.Context, phase Phase, migration
 	return result, nil
 }
 
-// AddLog adds a log entry to the phase result
+// AddLog adds a log entry to the phase result. The logs of one execution are capped at
+// MaxLogEntriesLimit; the configured cap is applied when the phase is recorded.
 func AddLog(logs []migrationv1alpha1.LogEntry, level migrationv1alpha1.LogLevel, message, component string) []migrationv1alpha1.LogEntry {
 	entry := migrationv1alpha1.LogEntry{
 		Timestamp: metav1.Now(),
@@ -163,24 +274,204 @@ func AddLog(logs []migrationv1alpha1.LogEntry, level migrationv1alpha1.LogLevel,
 		Message:   message,
 		Component: component,
 	}
-	return append(logs, entry)
+	return TrimLogs(append(logs, entry), MaxLogEntriesLimit)
+}
+
+const (
+	// defaultMaxPhaseLogEntries is how many log entries are kept per phase when not configured
+	defaultMaxPhaseLogEntries = 200
+
+	// defaultMaxTotalLogEntries is how many log entries the phase history keeps when not configured
+	defaultMaxTotalLogEntries = 2000
+
+	// MaxLogEntriesLimit is the highest per-phase cap that can be configured, and so the most
+	// entries a single phase execution ever needs to keep
+	MaxLogEntriesLimit = 1000
+)
+
+// MaxPhaseLogEntries returns how many log entries are kept for one phase
+func MaxPhaseLogEntries(migration *migrationv1alpha1.VmwareCloudFoundationMigration) int {
+	if cfg := migration.Spec.LogRetention; cfg != nil && cfg.MaxEntriesPerPhase > 0 {
+		return int(min(cfg.MaxEntriesPerPhase, MaxLogEntriesLimit))
+	}
+	return defaultMaxPhaseLogEntries
+}
+
+// MaxTotalLogEntries returns how many log entries are kept across the phase history
+func MaxTotalLogEntries(migration *migrationv1alpha1.VmwareCloudFoundationMigration) int {
+	if cfg := migration.Spec.LogRetention; cfg != nil && cfg.MaxTotalEntries > 0 {
+		return int(cfg.MaxTotalEntries)
+	}
+	return defaultMaxTotalLogEntries
+}
+
+// TrimLogs drops entries until at most maxEntries remain, keeping the order of the rest
+func TrimLogs(logs []migrationv1alpha1.LogEntry, maxEntries int) []migrationv1alpha1.LogEntry {
+	levels := make([]migrationv1alpha1.LogLevel, len(logs))
+	for i, entry := range logs {
+		levels[i] = entry.Level
+	}
+	drop := logsToDrop(levels, maxEntries)
+	if drop == nil {
+		return logs
+	}
+
+	kept := make([]migrationv1alpha1.LogEntry, 0, maxEntries)
+	for i, entry := range logs {
+		if !drop[i] {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// TrimPhaseHistoryLogs drops log entries from the phase history until at most maxEntries remain
+// across all phases, taking them from the oldest phases first
+func TrimPhaseHistoryLogs(history []migrationv1alpha1.PhaseHistoryEntry, maxEntries int) {
+	var levels []migrationv1alpha1.LogLevel
+	for _, entry := range history {
+		for _, log := range entry.Logs {
+			levels = append(levels, log.Level)
+		}
+	}
+	drop := logsToDrop(levels, maxEntries)
+	if drop == nil {
+		return
+	}
+
+	i := 0
+	for h := range history {
+		kept := make([]migrationv1alpha1.LogEntry, 0, len(history[h].Logs))
+		for _, log := range history[h].Logs {
+			if !drop[i] {
+				kept = append(kept, log)
+			}
+			i++
+		}
+		history[h].Logs = kept
+	}
+}
+
+// logsToDrop marks which of the log entries, given oldest first by level, to drop so at most
+// maxEntries remain. The oldest Debug and Info entries go first, then warnings, then errors.
+// It returns nil when nothing needs dropping.
+func logsToDrop(levels []migrationv1alpha1.LogLevel, maxEntries int) []bool {
+	excess := len(levels) - maxEntries
+	if maxEntries <= 0 || excess <= 0 {
+		return nil
+	}
+
+	drop := make([]bool, len(levels))
+	for _, tier := range [][]migrationv1alpha1.LogLevel{
+		{migrationv1alpha1.LogLevelDebug, migrationv1alpha1.LogLevelInfo, ""},
+		{migrationv1alpha1.LogLevelWarning},
+		{migrationv1alpha1.LogLevelError},
+	} {
+		for i, level := range levels {
+			if excess == 0 {
+				return drop
+			}
+			if !drop[i] && slices.Contains(tier, level) {
+				drop[i] = true
+				excess--
+			}
+		}
+	}
+	return drop
+}
+
+const (
+	// vSphereFaultLogLimit is how many recent vCenter faults are exported into the phase logs
+	vSphereFaultLogLimit = 5
+
+	// maxFaultBodyLength truncates exported request/response bodies to keep the status object small
+	maxFaultBodyLength = 4096
+)
+
+// AddVSphereFaultLogs appends the most recent SOAP faults and REST errors captured by a vSphere client
+// to the phase logs, so the vCenter request/response behind a failure survives in the migration status
+func AddVSphereFaultLogs(logs []migrationv1alpha1.LogEntry, client *vsphere.Client, server, component string) []migrationv1alpha1.LogEntry {
+	if client == nil {
+		return logs
+	}
+
+	for _, fault := range client.GetRecentSOAPFaults(vSphereFaultLogLimit) {
+		logs = append(logs, migrationv1alpha1.LogEntry{
+			Timestamp: metav1.NewTime(fault.Timestamp),
+			Level:     migrationv1alpha1.LogLevelError,
+			Message:   fmt.Sprintf("vCenter SOAP fault from %s: %v", fault.Method, fault.Error),
+			Component: component,
+			Fields: map[string]string{
+				"server":   server,
+				"api":      "SOAP",
+				"method":   fault.Method,
+				"duration": fault.Duration.String(),
+				"request":  truncateFaultBody(fault.RequestBody),
+				"response": truncateFaultBody(fault.ResponseBody),
+			},
+		})
+	}
+
+	for _, restErr := range client.GetRecentRESTErrors(vSphereFaultLogLimit) {
+		message := fmt.Sprintf("vCenter REST %s %s returned status %d", restErr.Method, restErr.URL, restErr.ResponseStatus)
+		if restErr.Error != nil {
+			message = fmt.Sprintf("vCenter REST %s %s failed: %v", restErr.Method, restErr.URL, restErr.Error)
+		}
+		logs = append(logs, migrationv1alpha1.LogEntry{
+			Timestamp: metav1.NewTime(restErr.Timestamp),
+			Level:     migrationv1alpha1.LogLevelError,
+			Message:   message,
+			Component: component,
+			Fields: map[string]string{
+				"server":   server,
+				"api":      "REST",
+				"method":   restErr.Method,
+				"url":      restErr.URL,
+				"status":   strconv.Itoa(restErr.ResponseStatus),
+				"duration": restErr.Duration.String(),
+				"request":  truncateFaultBody(restErr.RequestBody),
+				"response": truncateFaultBody(restErr.ResponseBody),
+			},
+		})
+	}
+
+	return logs
+}
+
+// truncateFaultBody shortens a request/response body for storage in the phase logs
+func truncateFaultBody(body string) string {
+	if len(body) <= maxFaultBodyLength {
+		return body
+	}
+	return body[:maxFaultBodyLength] + "... (truncated)"
+}
+
+// SetVCenterConnection sets the port, proxy and timeouts of every vCenter client the executor
+// creates. The server of connection is ignored.
+func (e *PhaseExecutor) SetVCenterConnection(connection vsphere.Config) {
+	e.vcenterConnection = connection
+}
+
+// vcenterConfig returns the client configuration for a vCenter server
+func (e *PhaseExecutor) vcenterConfig(server string) vsphere.Config {
+	config := e.vcenterConnection
+	config.Server = server
+	config.Insecure = true // TODO: make configurable
+	return config
 }
 
 // GetVSphereClient creates a vSphere client for a vCenter config
 // Uses the default vsphere-creds secret in kube-system (for source vCenter)
 func (e *PhaseExecutor) GetVSphereClient(ctx context.Context, server string) (*vsphere.Client, error) {
 	// Get credentials from secret
-	username, password, err := e.secretManager.GetCredentials(ctx, server)
+	username, password, err := e.secretManager.GetSourceVCenterCreds(ctx, server)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create client
 	client, err := vsphere.NewClient(ctx,
-		vsphere.Config{
-			Server:   server,
-			Insecure: true, // TODO: make configurable
-		},
+		e.vcenterConfig(server),
 		vsphere.Credentials{
 			Username: username,
 			Password: password,
@@ -209,20 +500,18 @@ func (e *PhaseExecutor) GetVSphereClientFromMigration(ctx context.Context, migra
 	}
 
 	if isTargetVCenter {
-		// Use the target vCenter credentials secret from migration spec
-		secretNamespace := migration.Spec.TargetVCenterCredentialsSecret.Namespace
-		if secretNamespace == "" {
-			secretNamespace = migration.Namespace
+		// Use the target vCenter credential source of the migration
+		provider, err := e.TargetCredentialProvider(migration)
+		if err != nil {
+			return nil, err
 		}
-		secretName := migration.Spec.TargetVCenterCredentialsSecret.Name
-
-		username, password, err = e.secretManager.GetVCenterCredsFromSecret(ctx, secretNamespace, secretName, server)
+		username, password, err = provider.GetCredentials(ctx, server)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// Use the default vsphere-creds secret for source vCenter
-		username, password, err = e.secretManager.GetCredentials(ctx, server)
+		username, password, err = e.secretManager.GetSourceVCenterCreds(ctx, server)
 		if err != nil {
 			return nil, err
 		}
@@ -230,10 +519,7 @@ func (e *PhaseExecutor) GetVSphereClientFromMigration(ctx context.Context, migra
 
 	// Create client
 	client, err := vsphere.NewClient(ctx,
-		vsphere.Config{
-			Server:   server,
-			Insecure: true, // TODO: make configurable
-		},
+		e.vcenterConfig(server),
 		vsphere.Credentials{
 			Username: username,
 			Password: password,
@@ -245,6 +531,17 @@ func (e *PhaseExecutor) GetVSphereClientFromMigration(ctx context.Context, migra
 	return client, nil
 }
 
+// targetFailureDomainOn returns the name of the first target failure domain on the given vCenter
+// server, or an empty string when the migration targets none there
+func targetFailureDomainOn(migration *migrationv1alpha1.VmwareCloudFoundationMigration, server string) string {
+	for _, fd := range migration.Spec.FailureDomains {
+		if vsphere.SameServer(fd.Server, server) {
+			return fd.Name
+		}
+	}
+	return ""
+}
+
 // GetMachineManager returns a machine manager for the executor
 func (e *PhaseExecutor) GetMachineManager() *openshift.MachineManager {
 	return openshift.NewMachineManagerWithClients(e.kubeClient, e.machineClient, e.dynamicClient)
@@ -3,10 +3,23 @@ package phases
 import (
 	"context"
 	"fmt"
-
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 // PreflightPhase validates prerequisites for migration
@@ -30,7 +43,7 @@ func (p *PreflightPhase) Validate(ctx context.Context, migration *migrationv1alp
 	if len(migration.Spec.FailureDomains) == 0 {
 		return fmt.Errorf("no failure domains specified")
 	}
-	if migration.Spec.TargetVCenterCredentialsSecret.Name == "" {
+	if migration.Spec.TargetVCenterCredentialsSecret.Name == "" && !p.executor.externalTargetCredentials() {
 		return fmt.Errorf("target vCenter credentials secret name is empty")
 	}
 	return nil
@@ -44,13 +57,38 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 	logger.Info("Running preflight checks")
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Running preflight checks", string(p.Name()))
 
+	// Validate the CSI volume selector matches at least one volume
+	pvManager := openshift.NewPersistentVolumeManager(p.executor.kubeClient)
+	selector := volumeSelector(migration)
+	csiPVs, err := pvManager.ListVSphereCSIVolumes(ctx, selector, migrationName(migration))
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: fmt.Sprintf("Failed to evaluate CSI volume selector: %v", err),
+			Logs:    logs,
+		}, err
+	}
+	if selector != nil {
+		if len(csiPVs) == 0 {
+			err := fmt.Errorf("CSI volume selector does not match any vSphere CSI volume")
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: err.Error(),
+				Logs:    logs,
+			}, err
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("CSI volume selector matches %d volume(s)", len(csiPVs)),
+			string(p.Name()))
+	}
+
 	// Get source vCenter from Infrastructure CRD
 	logger.Info("Reading source vCenter from Infrastructure CRD")
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 		"Reading source vCenter configuration from Infrastructure CRD",
 		string(p.Name()))
 
-	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx)
+	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -59,8 +97,29 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 		}, err
 	}
 
+	if migration.Spec.SourceVCenterServer != "" {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Found source vCenter named in the spec in Infrastructure CRD: %s", sourceVC.Server),
+			string(p.Name()))
+	} else {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Found source vCenter in Infrastructure CRD: %s", sourceVC.Server),
+			string(p.Name()))
+	}
+	migration.Status.SourceVCenter = sourceVC.Server
+
+	// Source credentials are read from the secret the cluster already uses, not from the spec
+	if _, _, err := p.executor.secretManager.GetSourceVCenterCreds(ctx, sourceVC.Server); err != nil {
+		return &PhaseResult{
+			Status: migrationv1alpha1.PhaseStatusFailed,
+			Message: fmt.Sprintf("Source vCenter credentials not found: %v; the cluster's %s/%s secret must hold %s.username and %s.password",
+				err, openshift.VSphereCredsSecretNamespace, openshift.VSphereCredsSecretName, sourceVC.Server, sourceVC.Server),
+			Logs: logs,
+		}, err
+	}
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		fmt.Sprintf("Found source vCenter in Infrastructure CRD: %s", sourceVC.Server),
+		fmt.Sprintf("Found source vCenter credentials in %s/%s (keys %s.username, %s.password)",
+			openshift.VSphereCredsSecretNamespace, openshift.VSphereCredsSecretName, sourceVC.Server, sourceVC.Server),
 		string(p.Name()))
 
 	// Test source vCenter connectivity
@@ -99,6 +158,61 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 			string(p.Name()))
 	}
 
+	// Dummy VMs are only created on the source when there are volumes to migrate
+	var encrypted bool
+	if len(csiPVs) > 0 {
+		var encryptedPVs []string
+		encryptedPVs, err = encryptedVolumes(ctx, sourceClient, csiPVs)
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: err.Error(),
+				Logs:    logs,
+			}, err
+		}
+		if encrypted = len(encryptedPVs) > 0; encrypted {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Found %d encrypted volume(s), cryptographer privileges are required: %s", len(encryptedPVs), strings.Join(encryptedPVs, ", ")),
+				string(p.Name()))
+		}
+
+		sourceFD, err := p.executor.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Failed to get source failure domain: %v", err),
+				Logs:    logs,
+			}, err
+		}
+		var sourcePrivileges []string
+		if validateDiskBeforeMigrate(migration) {
+			sourcePrivileges = vsphere.PowerOnPrivileges
+		}
+		if err := checkTopologyPrivileges(ctx, sourceClient, sourceFD.Topology, encrypted, sourcePrivileges...); err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: fmt.Sprintf("Insufficient privileges on source vCenter %s: %v", sourceVC.Server, err),
+				Logs:    logs,
+			}, err
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Validated privileges on source vCenter: %s", sourceVC.Server),
+			string(p.Name()))
+	}
+
+	// Check credentials exist for every target server before connecting to any, so all
+	// mismatches between failure domain servers and credential keys are reported at once
+	if err := p.executor.validateTargetCredentials(ctx, migration); err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: fmt.Sprintf("Target vCenter credentials are incomplete: %v", err),
+			Logs:    logs,
+		}, err
+	}
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Found credentials for every target vCenter in "+p.executor.targetCredentialSourceName(migration),
+		string(p.Name()))
+
 	// Get unique target vCenters from failure domains
 	targetVCenters := make(map[string]bool)
 	for _, fd := range migration.Spec.FailureDomains {
@@ -126,6 +240,32 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 			fmt.Sprintf("Successfully connected to target vCenter: %s", targetServer),
 			string(p.Name()))
 
+		if vsphere.SameServer(sourceVC.Server, targetServer) {
+			// Volumes move between datastores of one vCenter, so no ServiceLocator - and with it
+			// no thumbprint, instance UUID or version match - is needed
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Target vCenter %s is the source vCenter, volumes will be relocated with a storage-only vMotion", targetServer),
+				string(p.Name()))
+		} else {
+			// Validate cross-vCenter vMotion version compatibility
+			sourceAbout := sourceClient.GetAbout()
+			targetAbout := targetClient.GetAbout()
+			if err := vsphere.CheckVMotionCompatibility(sourceAbout, targetAbout); err != nil {
+				logger.Error(err, "Incompatible vCenter versions for cross-vCenter vMotion",
+					"sourceVersion", sourceAbout.Version, "sourceBuild", sourceAbout.Build,
+					"targetVersion", targetAbout.Version, "targetBuild", targetAbout.Build)
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: fmt.Sprintf("vCenter version check failed for target %s: %v", targetServer, err),
+					Logs:    logs,
+				}, err
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Validated vCenter versions for cross-vCenter vMotion: source %s (build %s), target %s (build %s)",
+					sourceAbout.Version, sourceAbout.Build, targetAbout.Version, targetAbout.Build),
+				string(p.Name()))
+		}
+
 		// Validate target vCenter topology from failure domains
 		for _, fd := range migration.Spec.FailureDomains {
 			if fd.Server == targetServer {
@@ -146,7 +286,7 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 
 				// Validate ComputeCluster
 				if fd.Topology.ComputeCluster != "" {
-					_, err = targetClient.GetCluster(ctx, fd.Topology.ComputeCluster)
+					err = resolveTopologyPath(ctx, targetClient, fd, vsphere.InventoryComputeCluster, fd.Topology.ComputeCluster)
 					if err != nil {
 						return &PhaseResult{
 							Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -161,7 +301,7 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 
 				// Validate Datastore
 				if fd.Topology.Datastore != "" {
-					_, err = targetClient.GetDatastore(ctx, fd.Topology.Datastore)
+					err = resolveTopologyPath(ctx, targetClient, fd, vsphere.InventoryDatastore, fd.Topology.Datastore)
 					if err != nil {
 						return &PhaseResult{
 							Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -189,9 +329,18 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 						string(p.Name()))
 				}
 
+				// Nested folders and child resource pools must stay within the failure domain
+				if err := util.ValidatePlacementPaths(fd.Topology); err != nil {
+					return &PhaseResult{
+						Status:  migrationv1alpha1.PhaseStatusFailed,
+						Message: fmt.Sprintf("Invalid placement in failure domain %s: %v", fd.Name, err),
+						Logs:    logs,
+					}, err
+				}
+
 				// Validate ResourcePool (if specified)
 				if fd.Topology.ResourcePool != "" {
-					_, err = targetClient.GetResourcePool(ctx, fd.Topology.ResourcePool)
+					err = resolveTopologyPath(ctx, targetClient, fd, vsphere.InventoryResourcePool, fd.Topology.ResourcePool)
 					if err != nil {
 						return &PhaseResult{
 							Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -221,9 +370,9 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 
 				// Check Folder (warning if missing - will be created by CreateFolder phase)
 				if fd.Topology.Folder != "" {
-					_, err = targetClient.GetFolder(ctx, fd.Topology.Folder)
+					err = resolveTopologyPath(ctx, targetClient, fd, vsphere.InventoryFolder, fd.Topology.Folder)
 					if err != nil {
-						logger.Info("Folder not found (will be created)", "folder", fd.Topology.Folder, "failureDomain", fd.Name)
+						logger.Info("Folder not found (will be created)", "folder", fd.Topology.Folder, "failureDomain", fd.Name, "reason", err)
 						logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
 							fmt.Sprintf("Folder %s not found in failure domain %s - will be created", fd.Topology.Folder, fd.Name),
 							string(p.Name()))
@@ -233,8 +382,213 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 							string(p.Name()))
 					}
 				}
+
+				// Volumes are relocated into the first failure domain, optionally pinned to a host
+				if host := relocateTargetHost(migration); host != "" && fd.Name == migration.Spec.FailureDomains[0].Name {
+					if err := targetClient.CheckRelocateHost(ctx, host, fd.Topology.ComputeCluster); err != nil {
+						return &PhaseResult{
+							Status:  migrationv1alpha1.PhaseStatusFailed,
+							Message: fmt.Sprintf("Target host %s cannot receive relocated volumes in failure domain %s: %v", host, fd.Name, err),
+							Logs:    logs,
+						}, err
+					}
+					logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+						fmt.Sprintf("Validated target host for volume relocation: %s", host),
+						string(p.Name()))
+				}
+
+				// Volumes with a datastore override are relocated to it instead of the first
+				// failure domain's datastore
+				if fd.Name == migration.Spec.FailureDomains[0].Name {
+					for _, datastore := range overrideDatastores(migration) {
+						err := resolveTopologyPath(ctx, targetClient, fd, vsphere.InventoryDatastore, datastore)
+						if err == nil {
+							err = targetClient.CheckDatastoreAccessible(ctx, datastore)
+						}
+						if err != nil {
+							return &PhaseResult{
+								Status:  migrationv1alpha1.PhaseStatusFailed,
+								Message: fmt.Sprintf("Volume datastore override %s cannot be used in failure domain %s: %v", datastore, fd.Name, err),
+								Logs:    logs,
+							}, err
+						}
+						logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+							fmt.Sprintf("Validated volume datastore override: %s", datastore),
+							string(p.Name()))
+					}
+
+					// Volumes without an override go to the target datastore cluster when set
+					if cluster := targetDatastoreCluster(migration); cluster != "" {
+						err := resolveTopologyPath(ctx, targetClient, fd, vsphere.InventoryDatastoreCluster, cluster)
+						if err == nil {
+							err = targetClient.CheckStoragePod(ctx, cluster)
+						}
+						if err != nil {
+							return &PhaseResult{
+								Status:  migrationv1alpha1.PhaseStatusFailed,
+								Message: fmt.Sprintf("Target datastore cluster %s cannot be used in failure domain %s: %v", cluster, fd.Name, err),
+								Logs:    logs,
+							}, err
+						}
+						logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+							fmt.Sprintf("Validated target datastore cluster: %s", cluster),
+							string(p.Name()))
+					}
+				}
+
+				// Validate the privileges of the target account on the failure domain's objects
+				if err := checkTopologyPrivileges(ctx, targetClient, fd.Topology, encrypted); err != nil {
+					return &PhaseResult{
+						Status:  migrationv1alpha1.PhaseStatusFailed,
+						Message: fmt.Sprintf("Insufficient privileges on target vCenter %s in failure domain %s: %v", targetServer, fd.Name, err),
+						Logs:    logs,
+					}, err
+				}
+				logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+					fmt.Sprintf("Validated privileges in failure domain: %s", fd.Name),
+					string(p.Name()))
 			}
 		}
+
+		// Volumes are relocated into the first failure domain. Cross-vCenter vMotion may be
+		// disabled or unable to reach the target, which must show before workloads are quiesced.
+		if len(csiPVs) > 0 && targetServer == migration.Spec.FailureDomains[0].Server && !vsphere.SameServer(sourceVC.Server, targetServer) {
+			warnings, err := p.probeCrossVCenterVMotion(ctx, migration, sourceClient, targetClient)
+			if err != nil {
+				msg := fmt.Sprintf("Cross-vCenter vMotion from %s to %s is not viable: %v. Check that vMotion is enabled and licensed "+
+					"on the hosts of both vCenters, that the source hosts reach the target hosts over the vMotion network, and that "+
+					"the source vCenter can connect to the target vCenter with the target credentials", sourceVC.Server, targetServer, err)
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, msg, string(p.Name()))
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: msg,
+					Logs:    logs,
+				}, err
+			}
+			for _, warning := range warnings {
+				logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+					fmt.Sprintf("Cross-vCenter vMotion check to %s warned: %s", targetServer, warning),
+					string(p.Name()))
+			}
+			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+				fmt.Sprintf("Validated cross-vCenter vMotion to %s", targetServer),
+				string(p.Name()))
+		}
+	}
+
+	// Restored PVCs only bind if the CSI driver and their StorageClasses are still present,
+	// so check before any workload is quiesced
+	if len(csiPVs) > 0 {
+		logs, err = p.validateCSIStorage(ctx, pvManager, csiPVs, logs)
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: err.Error(),
+				Logs:    logs,
+			}, err
+		}
+	}
+
+	// With InPlaceHandleSwap the PVs stay bound while their volumeHandle changes, which
+	// admission may forbid
+	if inPlaceHandleSwap(migration) {
+		checked := 0
+		for _, pv := range csiPVs {
+			if pv.ClaimRef == nil || openshift.IsUnboundPhase(pv.Phase) {
+				continue
+			}
+			if err := pvManager.CheckVolumeHandleSwap(ctx, pv.Name); err != nil {
+				msg := fmt.Sprintf("Migration strategy %s cannot be used: %v", migrationv1alpha1.PVCStrategyInPlaceHandleSwap, err)
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, msg, string(p.Name()))
+				return &PhaseResult{
+					Status:  migrationv1alpha1.PhaseStatusFailed,
+					Message: msg,
+					Logs:    logs,
+				}, err
+			}
+			checked++
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Migration strategy %s keeps %d PVC(s) bound and swaps the volumeHandle of their PV in place; "+
+				"this requires a CSI driver that tolerates the volumeHandle of a bound PV changing while no pod uses it",
+				migrationv1alpha1.PVCStrategyInPlaceHandleSwap, checked),
+			string(p.Name()))
+	}
+
+	// PVCs provisioned from a snapshot or clone are recreated bound to their PV without that lineage
+	dataSourcePVCs, err := p.dataSourcePVCs(ctx, pvManager, csiPVs)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	if len(dataSourcePVCs) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("%d PVC(s) were provisioned from a snapshot or clone and will be restored without their data source "+
+				"(recorded in the %s annotation): %s",
+				len(dataSourcePVCs), openshift.OriginalDataSourceAnnotation, strings.Join(dataSourcePVCs, ", ")),
+			string(p.Name()))
+	}
+
+	// Volumes used by workloads in namespaces excluded from quiesce are skipped by the CSI phase
+	logs, excludedVolumes, err := p.quiesceExcludedVolumes(ctx, migration, csiPVs, logs)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	if len(excludedVolumes) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("%d volume(s) are used by workloads in namespaces excluded from quiesce and will be skipped: %s",
+				len(excludedVolumes), strings.Join(excludedVolumes, "; ")),
+			string(p.Name()))
+	}
+
+	// Render the names of the objects the migration creates so template or length problems surface now
+	if err := p.validateNames(ctx, migration, csiPVs); err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Validated names of dummy VMs and worker MachineSets",
+		string(p.Name()))
+
+	// Detect in-tree vSphere volumes, which are not migrated and stay on the source vCenter
+	inTreePVs, err := pvManager.ListInTreeVSphereVolumes(ctx)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: fmt.Sprintf("Failed to list in-tree vSphere volumes: %v", err),
+			Logs:    logs,
+		}, err
+	}
+	if len(inTreePVs) > 0 {
+		names := make([]string, 0, len(inTreePVs))
+		for _, pv := range inTreePVs {
+			names = append(names, fmt.Sprintf("%s (%s)", pv.Name, pv.VolumePath))
+		}
+		msg := fmt.Sprintf("Found %d in-tree vSphere volume(s) that are not supported and will not be migrated: %s. "+
+			"They remain on the source vCenter and become inaccessible once it is removed",
+			len(inTreePVs), strings.Join(names, ", "))
+
+		if migration.Spec.CSIVolumeMigration != nil && migration.Spec.CSIVolumeMigration.FailOnInTreeVolumes {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelError, msg, string(p.Name()))
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: msg,
+				Logs:    logs,
+			}, fmt.Errorf("%d in-tree vSphere volume(s) present", len(inTreePVs))
+		}
+
+		logger.Info("In-tree vSphere volumes will not be migrated", "volumes", names)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning, msg, string(p.Name()))
 	}
 
 	// Validate cluster health
@@ -253,6 +607,348 @@ func (p *PreflightPhase) Execute(ctx context.Context, migration *migrationv1alph
 	}, nil
 }
 
+// validateCSIStorage checks that the vSphere CSI driver is installed and registered on the nodes,
+// and that every StorageClass referenced by the volumes to migrate exists and uses the driver
+func (p *PreflightPhase) validateCSIStorage(ctx context.Context, pvManager *openshift.PersistentVolumeManager, csiPVs []openshift.VSphereCSIPV, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, error) {
+	logger := klog.FromContext(ctx)
+
+	driverStatus, err := pvManager.GetVSphereCSIDriverStatus(ctx)
+	if err != nil {
+		return logs, fmt.Errorf("failed to check vSphere CSI driver: %w", err)
+	}
+	if !driverStatus.Installed {
+		return logs, fmt.Errorf("CSIDriver %s is not installed", openshift.VSphereCSIDriver)
+	}
+	if len(driverStatus.RegisteredNodes) == 0 {
+		return logs, fmt.Errorf("CSIDriver %s is not registered on any node", openshift.VSphereCSIDriver)
+	}
+	if len(driverStatus.UnregisteredNodes) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("CSIDriver %s is not registered on node(s): %s",
+				openshift.VSphereCSIDriver, strings.Join(driverStatus.UnregisteredNodes, ", ")),
+			string(p.Name()))
+	}
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("CSIDriver %s is registered on %d node(s)", openshift.VSphereCSIDriver, len(driverStatus.RegisteredNodes)),
+		string(p.Name()))
+
+	// Statically provisioned volumes may not reference a StorageClass
+	storageClasses := make(map[string][]string)
+	for _, pv := range csiPVs {
+		if pv.StorageClass != "" {
+			storageClasses[pv.StorageClass] = append(storageClasses[pv.StorageClass], pv.Name)
+		}
+	}
+
+	var problems []string
+	for _, name := range slices.Sorted(maps.Keys(storageClasses)) {
+		sc, err := pvManager.GetStorageClass(ctx, name)
+		if apierrors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("StorageClass %s used by %s does not exist", name, strings.Join(storageClasses[name], ", ")))
+			continue
+		}
+		if err != nil {
+			return logs, fmt.Errorf("failed to get StorageClass %s: %w", name, err)
+		}
+		if !openshift.IsVSphereStorageClass(sc) {
+			problems = append(problems, fmt.Sprintf("StorageClass %s uses provisioner %s instead of %s", name, sc.Provisioner, openshift.VSphereCSIDriver))
+			continue
+		}
+		logger.V(2).Info("Validated StorageClass", "storageClass", name, "volumes", len(storageClasses[name]))
+	}
+	if len(problems) > 0 {
+		return logs, fmt.Errorf("incompatible storage for CSI volume migration: %s", strings.Join(problems, "; "))
+	}
+
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Validated %d StorageClass(es) for %d CSI volume(s)", len(storageClasses), len(csiPVs)),
+		string(p.Name()))
+	return logs, nil
+}
+
+// dataSourcePVCs lists the PVCs of the volumes to migrate that have a data source, as namespace/name (from source)
+func (p *PreflightPhase) dataSourcePVCs(ctx context.Context, pvManager *openshift.PersistentVolumeManager, csiPVs []openshift.VSphereCSIPV) ([]string, error) {
+	var pvcs []string
+	for _, pv := range csiPVs {
+		if pv.ClaimRef == nil {
+			continue
+		}
+		pvc, err := pvManager.GetPVC(ctx, pv.ClaimRef.Namespace, pv.ClaimRef.Name)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PVC %s/%s: %w", pv.ClaimRef.Namespace, pv.ClaimRef.Name, err)
+		}
+		if source := openshift.DescribeDataSource(pvc.Spec.DataSource, pvc.Spec.DataSourceRef); source != "" {
+			pvcs = append(pvcs, fmt.Sprintf("%s/%s (from %s)", pvc.Namespace, pvc.Name, source))
+		}
+	}
+	return pvcs, nil
+}
+
+// quiesceExcludedVolumes validates the namespaces excluded from quiesce and lists the volumes the
+// CSI phase will skip because a workload in one of them uses the volume's PVC
+func (p *PreflightPhase) quiesceExcludedVolumes(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, csiPVs []openshift.VSphereCSIPV, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, []string, error) {
+	excluded := quiesceExcludeNamespaces(migration)
+	if len(excluded) == 0 {
+		return logs, nil, nil
+	}
+
+	seen := make(map[string]bool, len(excluded))
+	for _, namespace := range excluded {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return logs, nil, fmt.Errorf("invalid namespace %q in quiesceExcludeNamespaces: %s", namespace, strings.Join(errs, "; "))
+		}
+		if seen[namespace] {
+			return logs, nil, fmt.Errorf("namespace %s is listed twice in quiesceExcludeNamespaces", namespace)
+		}
+		seen[namespace] = true
+
+		_, err := p.executor.kubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+				fmt.Sprintf("Namespace %s excluded from quiesce does not exist", namespace),
+				string(p.Name()))
+			continue
+		}
+		if err != nil {
+			return logs, nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+		}
+	}
+
+	workloadManager := openshift.NewWorkloadManager(p.executor.kubeClient)
+	var volumes []string
+	for _, pv := range csiPVs {
+		if pv.ClaimRef == nil || !seen[pv.ClaimRef.Namespace] {
+			continue
+		}
+		workloads, err := workloadManager.WorkloadsUsingPVC(ctx, pv.ClaimRef.Namespace, pv.ClaimRef.Name)
+		if err != nil {
+			return logs, nil, fmt.Errorf("failed to find workloads using PVC %s/%s: %w", pv.ClaimRef.Namespace, pv.ClaimRef.Name, err)
+		}
+		if len(workloads) > 0 {
+			volumes = append(volumes, fmt.Sprintf("%s (PVC %s/%s used by %s)",
+				pv.Name, pv.ClaimRef.Namespace, pv.ClaimRef.Name, strings.Join(workloads, ", ")))
+		}
+	}
+	return logs, volumes, nil
+}
+
+// validateNames renders the worker MachineSet and dummy VM names and checks them for
+// constraint violations and collisions
+func (p *PreflightPhase) validateNames(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, csiPVs []openshift.VSphereCSIPV) error {
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+
+	placements, err := openshift.WorkerPlacements(migration.Spec.MachineSetConfig)
+	if err != nil {
+		return fmt.Errorf("invalid worker MachineSet configuration: %w", err)
+	}
+	if _, err := workerMachineSetNames(migration, infraID, placements); err != nil {
+		return err
+	}
+
+	dummyVMs := make(map[string]string, len(csiPVs))
+	for _, pv := range csiPVs {
+		name, err := util.DummyVMName(migration.Spec.Naming, util.NameParams{
+			InfraID: infraID,
+			PVName:  pv.Name,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to name dummy VM for PV %s: %w", pv.Name, err)
+		}
+		if other, ok := dummyVMs[name]; ok {
+			return fmt.Errorf("dummy VM name %s is used by both PV %s and PV %s", name, other, pv.Name)
+		}
+		dummyVMs[name] = pv.Name
+	}
+
+	return nil
+}
+
+// vMotionProbePVName stands in for the PV name in the dummy VM name of the vMotion probe, so a
+// probe VM left behind is cleaned up with the dummy VMs
+const vMotionProbePVName = "vmotion-probe"
+
+// probeCrossVCenterVMotion asks the source vCenter whether a disk-less dummy VM could be relocated
+// into the first failure domain, the way volumes are. The probe VM is created for the check and
+// destroyed afterwards; it never moves.
+func (p *PreflightPhase) probeCrossVCenterVMotion(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, sourceClient, targetClient *vsphere.Client) ([]string, error) {
+	logger := klog.FromContext(ctx)
+
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+	sourceFD, err := p.executor.infraManager.GetSourceFailureDomain(ctx, migration.Spec.SourceVCenterServer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source failure domain: %w", err)
+	}
+	name, err := util.DummyVMName(migration.Spec.Naming, util.NameParams{InfraID: infraID, PVName: vMotionProbePVName})
+	if err != nil {
+		return nil, err
+	}
+
+	targetFD := migration.Spec.FailureDomains[0]
+	relocateConfig := vsphere.RelocateConfig{
+		TargetVCenterURL:   targetClient.SDKURL(),
+		TargetDatacenter:   targetFD.Topology.Datacenter,
+		TargetCluster:      targetFD.Topology.ComputeCluster,
+		TargetDatastore:    targetFD.Topology.Datastore,
+		TargetFolder:       util.FailureDomainFolder(targetFD.Topology, infraID),
+		TargetResourcePool: util.FailureDomainResourcePool(targetFD.Topology),
+		TargetHost:         relocateTargetHost(migration),
+	}
+	if err := p.executor.setCrossVCenterTarget(ctx, migration, targetClient, targetFD.Server, &relocateConfig); err != nil {
+		return nil, err
+	}
+
+	// The CreateFolder phase has not run yet, the datacenter's VM folder is as good a target
+	// for a relocation that is only checked
+	dc, err := targetClient.GetDatacenter(ctx, relocateConfig.TargetDatacenter)
+	if err != nil {
+		return nil, err
+	}
+	targetClient.Finder().SetDatacenter(dc)
+	if _, err := targetClient.GetFolder(ctx, relocateConfig.TargetFolder); err != nil {
+		relocateConfig.TargetFolder = path.Join(dc.InventoryPath, "vm")
+	}
+
+	relocator := vsphere.NewVMRelocator(sourceClient, targetClient)
+	vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+		Name:         name,
+		Datacenter:   sourceFD.Topology.Datacenter,
+		Cluster:      sourceFD.Topology.ComputeCluster,
+		Datastore:    sourceFD.Topology.Datastore,
+		Folder:       util.FailureDomainFolder(sourceFD.Topology, infraID),
+		ResourcePool: util.FailureDomainResourcePool(sourceFD.Topology),
+		NumCPUs:      1,
+		MemoryMB:     128,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vMotion probe VM: %w", err)
+	}
+	defer func() {
+		if err := relocator.DeleteDummyVM(ctx, vm); err != nil {
+			logger.Error(err, "Failed to delete vMotion probe VM, it is removed with the dummy VMs", "vm", name)
+		}
+	}()
+
+	return relocator.CheckRelocate(ctx, vm, relocateConfig)
+}
+
+// resolveTopologyPath checks that a normalized topology path names an object in the failure domain's
+// datacenter. An object found only at another path fails too, since later phases use the path as given.
+func resolveTopologyPath(ctx context.Context, client *vsphere.Client, fd configv1.VSpherePlatformFailureDomainSpec, kind vsphere.InventoryKind, path string) error {
+	resolved, err := client.ResolveInventoryPath(ctx, fd.Topology.Datacenter, kind, path)
+	if err != nil {
+		return err
+	}
+	if resolved != path {
+		return fmt.Errorf("%s %s was found at %s; set the full inventory path in the failure domain", kind, path, resolved)
+	}
+	return nil
+}
+
+// encryptedVolumes returns the volumes whose disks on the source vCenter are protected by VM encryption
+func encryptedVolumes(ctx context.Context, client *vsphere.Client, csiPVs []openshift.VSphereCSIPV) ([]string, error) {
+	logger := klog.FromContext(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create FCD manager: %w", err)
+	}
+
+	var encrypted []string
+	for _, pv := range csiPVs {
+		info, err := fcdManager.GetFCDByID(ctx, pv.VolumeHandle)
+		if err != nil {
+			// Missing disks are reported when the volume is migrated
+			logger.V(2).Info("Failed to look up disk of volume, assuming it is not encrypted", "pv", pv.Name, "error", err)
+			continue
+		}
+		if info.Encrypted {
+			encrypted = append(encrypted, pv.Name)
+		}
+	}
+	return encrypted, nil
+}
+
+// checkTopologyPrivileges checks that the logged in account holds the privileges the migration
+// needs on the datacenter, datastore, resource pool and folder of a topology. A folder that does
+// not exist yet is checked on the datacenter's VM folder it will be created in. The error lists
+// the missing privileges per object. Extra folder privileges are checked along with the defaults.
+func checkTopologyPrivileges(ctx context.Context, client *vsphere.Client, topology configv1.VSpherePlatformTopology, encrypted bool, extraFolderPrivileges ...string) error {
+	type privilegeCheck struct {
+		kind       string
+		path       string
+		entity     types.ManagedObjectReference
+		privileges []string
+	}
+
+	dc, err := client.GetDatacenter(ctx, topology.Datacenter)
+	if err != nil {
+		return err
+	}
+	client.Finder().SetDatacenter(dc)
+	checks := []privilegeCheck{{"datacenter", dc.InventoryPath, dc.Reference(), vsphere.DatacenterPrivileges}}
+
+	if topology.Datastore != "" {
+		ds, err := client.GetDatastore(ctx, topology.Datastore)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, privilegeCheck{"datastore", ds.InventoryPath, ds.Reference(), vsphere.DatastorePrivileges})
+	}
+
+	poolPath := topology.ResourcePool
+	if poolPath == "" && topology.ComputeCluster != "" {
+		poolPath = path.Join(topology.ComputeCluster, "Resources")
+	}
+	if poolPath != "" {
+		pool, err := client.GetResourcePool(ctx, poolPath)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, privilegeCheck{"resource pool", pool.InventoryPath, pool.Reference(), vsphere.ResourcePoolPrivileges})
+	}
+
+	folderPrivileges := slices.Concat(vsphere.FolderPrivileges, extraFolderPrivileges)
+	if encrypted {
+		folderPrivileges = slices.Concat(folderPrivileges, vsphere.CryptographerPrivileges)
+	}
+	var folder *object.Folder
+	if topology.Folder != "" {
+		folder, _ = client.GetFolder(ctx, topology.Folder)
+	}
+	if folder == nil {
+		folder, err = client.GetFolder(ctx, path.Join(dc.InventoryPath, util.VMFolder))
+		if err != nil {
+			return err
+		}
+		folderPrivileges = slices.Concat(folderPrivileges, vsphere.FolderCreatePrivileges)
+	}
+	checks = append(checks, privilegeCheck{"folder", folder.InventoryPath, folder.Reference(), folderPrivileges})
+
+	var problems []string
+	for _, check := range checks {
+		missing, err := client.CheckPrivileges(ctx, check.entity, check.privileges)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			problems = append(problems, fmt.Sprintf("%s %s is missing %s", check.kind, check.path, strings.Join(missing, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("missing privileges: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 // Rollback reverts the phase changes
 func (p *PreflightPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	// Preflight has no state to rollback
@@ -3,22 +3,31 @@ package phases
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
 )
 
+// defaultControlPlaneSettleDuration is how long RecreateCPMS waits after the rollout completes
+// before checking etcd and kube-apiserver, when not set in the spec
+const defaultControlPlaneSettleDuration = 2 * time.Minute
+
 // RecreateCPMSPhase recreates the Control Plane Machine Set
 type RecreateCPMSPhase struct {
-	executor *PhaseExecutor
+	executor        *PhaseExecutor
+	operatorManager *openshift.OperatorManager
 }
 
 // NewRecreateCPMSPhase creates a new recreate CPMS phase
 func NewRecreateCPMSPhase(executor *PhaseExecutor) *RecreateCPMSPhase {
 	return &RecreateCPMSPhase{
-		executor: executor,
+		executor:        executor,
+		operatorManager: openshift.NewOperatorManager(executor.configClient),
 	}
 }
 
@@ -44,6 +53,7 @@ func (p *RecreateCPMSPhase) Execute(ctx context.Context, migration *migrationv1a
 	machineManager := p.executor.GetMachineManager()
 
 	if !isResume {
+		migration.Status.ControlPlaneRolloutCompleteTime = nil
 
 		// --- First execution: update CPMS, then requeue ---
 		logger.Info("Updating Control Plane Machine Set for new vCenter")
@@ -150,6 +160,7 @@ func (p *RecreateCPMSPhase) Execute(ctx context.Context, migration *migrationv1a
 	}
 
 	if !complete {
+		migration.Status.ControlPlaneRolloutCompleteTime = nil
 		msg := fmt.Sprintf("Waiting for control plane rollout: %d/%d updated, %d/%d ready",
 			updatedReplicas, replicas, readyReplicas, replicas)
 		logger.Info(msg)
@@ -169,7 +180,45 @@ func (p *RecreateCPMSPhase) Execute(ctx context.Context, migration *migrationv1a
 		}, nil
 	}
 
-	// Rollout complete and monitoring period elapsed
+	// Rollout complete and monitoring period elapsed: give etcd membership and the apiserver
+	// time to settle, then confirm their operators report it
+	if migration.Status.ControlPlaneRolloutCompleteTime == nil {
+		now := metav1.Now()
+		migration.Status.ControlPlaneRolloutCompleteTime = &now
+	}
+	settle := controlPlaneSettleDuration(migration)
+	if elapsed := time.Since(migration.Status.ControlPlaneRolloutCompleteTime.Time); elapsed < settle {
+		msg := fmt.Sprintf("Control plane rollout complete, settling (%s / %s elapsed)",
+			elapsed.Truncate(time.Second), settle)
+		logger.Info(msg)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		return &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Progress:     90,
+			Logs:         logs,
+			RequeueAfter: min(30*time.Second, settle-elapsed),
+		}, nil
+	}
+
+	unsettled, err := p.operatorManager.UnsettledOperators(ctx, openshift.ControlPlaneOperators)
+	if err != nil {
+		logger.V(2).Info("Unable to check control plane operators", "error", err)
+		unsettled = []string{err.Error()}
+	}
+	if len(unsettled) > 0 {
+		msg := "Waiting for control plane operators to be Available and not Progressing: " + strings.Join(unsettled, ", ")
+		logger.Info(msg)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		return &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Progress:     95,
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
+		}, nil
+	}
+
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Control plane rollout completed successfully", string(p.Name()))
 	logger.Info("Successfully updated Control Plane Machine Set")
 
@@ -181,6 +230,14 @@ func (p *RecreateCPMSPhase) Execute(ctx context.Context, migration *migrationv1a
 	}, nil
 }
 
+// controlPlaneSettleDuration returns the configured post-rollout settle period or the default
+func controlPlaneSettleDuration(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if d := migration.Spec.ControlPlaneMachineSetConfig.SettleDuration; d != nil && d.Duration >= 0 {
+		return d.Duration
+	}
+	return defaultControlPlaneSettleDuration
+}
+
 // Rollback reverts the phase changes
 func (p *RecreateCPMSPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
@@ -190,7 +247,7 @@ func (p *RecreateCPMSPhase) Rollback(ctx context.Context, migration *migrationv1
 	machineManager := p.executor.GetMachineManager()
 
 	// Get CPMS backup
-	backup, err := p.executor.backupManager.GetBackup(migration, "ControlPlaneMachineSet", "cluster", "openshift-machine-api")
+	backup, err := p.executor.backupManager.GetBackup(migration, CPMSBackupType, "cluster", openshift.MachineAPINamespace)
 	if err != nil {
 		logger.Error(err, "Failed to get CPMS backup")
 		return err
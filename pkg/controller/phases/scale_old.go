@@ -2,12 +2,20 @@ package phases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
 )
 
 const scaleOldMachinesTimeout = 45 * time.Minute
@@ -51,7 +59,7 @@ func (p *ScaleOldMachinesPhase) Execute(ctx context.Context, migration *migratio
 		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "Scaling down old worker machines", string(p.Name()))
 
 		// Get source vCenter from Infrastructure CRD
-		sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx)
+		sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
 		if err != nil {
 			return &PhaseResult{
 				Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -77,6 +85,10 @@ func (p *ScaleOldMachinesPhase) Execute(ctx context.Context, migration *migratio
 		if len(oldMachineSets) == 0 {
 			logger.Info("No old MachineSets found")
 			logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, "No old MachineSets found", string(p.Name()))
+			var result *PhaseResult
+			if logs, result, err = p.settleSurge(ctx, migration, logs); result != nil {
+				return result, err
+			}
 			return &PhaseResult{
 				Status:   migrationv1alpha1.PhaseStatusCompleted,
 				Message:  "No old MachineSets to scale down",
@@ -89,6 +101,33 @@ func (p *ScaleOldMachinesPhase) Execute(ctx context.Context, migration *migratio
 			fmt.Sprintf("Found %d old MachineSets", len(oldMachineSets)),
 			string(p.Name()))
 
+		// Removing the old workers must not strand their pods: the new workers have to be
+		// usable and the nodes that remain able to hold what runs on the old ones
+		problems, err := p.checkTargetWorkers(ctx, migration, machineManager, oldMachineSets)
+		if err != nil {
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: "Failed to check the new worker nodes: " + err.Error(),
+				Logs:    logs,
+			}, err
+		}
+		if len(problems) > 0 {
+			for _, problem := range problems {
+				logger.Info("New workers cannot replace the old machines", "reason", problem)
+				logs = AddLog(logs, migrationv1alpha1.LogLevelError, problem, string(p.Name()))
+			}
+			msg := fmt.Sprintf("New worker nodes cannot replace the old machines yet, not scaling down: %s",
+				strings.Join(problems, "; "))
+			return &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: msg,
+				Logs:    logs,
+			}, phaseerrors.Validation(errors.New(msg))
+		}
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			"New worker nodes are Ready and schedulable and can hold the pods of the old machines",
+			string(p.Name()))
+
 		for _, ms := range oldMachineSets {
 			if ms.Spec.Replicas != nil && *ms.Spec.Replicas == 0 {
 				logger.Info("MachineSet already scaled to 0, skipping", "name", ms.Name)
@@ -141,7 +180,7 @@ func (p *ScaleOldMachinesPhase) Execute(ctx context.Context, migration *migratio
 	}
 
 	// Re-fetch old MachineSets and ensure all are scaled to 0
-	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx)
+	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -225,7 +264,12 @@ func (p *ScaleOldMachinesPhase) Execute(ctx context.Context, migration *migratio
 		}, nil
 	}
 
-	// All machines and nodes are gone
+	// All machines and nodes are gone, so the surge machines are no longer needed
+	var result *PhaseResult
+	if logs, result, err = p.settleSurge(ctx, migration, logs); result != nil {
+		return result, err
+	}
+
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 		"All old machines and nodes have been deleted",
 		string(p.Name()))
@@ -239,6 +283,113 @@ func (p *ScaleOldMachinesPhase) Execute(ctx context.Context, migration *migratio
 	}, nil
 }
 
+// settleSurge scales the new worker MachineSets back from their surge replicas. It returns a
+// result while surge machines are still being removed or when settling failed, and none once
+// every new MachineSet is at its desired size.
+func (p *ScaleOldMachinesPhase) settleSurge(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	logger := klog.FromContext(ctx)
+
+	settled, excess, err := p.executor.settleWorkerSurge(ctx, migration)
+	for _, name := range settled {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			fmt.Sprintf("Scaled new worker MachineSet %s back from its surge replicas", name),
+			string(p.Name()))
+	}
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to settle the surge worker machines: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+
+	if excess > 0 {
+		msg := fmt.Sprintf("Old machines removed, waiting for %d surge machines to be removed", excess)
+		logger.Info(msg)
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		return logs, &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Progress:     80,
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
+		}, nil
+	}
+	return logs, nil, nil
+}
+
+// checkTargetWorkers describes why the worker nodes of the new MachineSets cannot take over from
+// the old MachineSets: a new node that is not Ready or not schedulable, a new Machine without a
+// node, or CPU or memory requests the nodes left after the scale down cannot hold. Old
+// MachineSets already at 0 replicas need no check.
+func (p *ScaleOldMachinesPhase) checkTargetWorkers(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, machineManager *openshift.MachineManager, oldMachineSets []*machinev1beta1.MachineSet) ([]string, error) {
+	removed := make(map[string]bool)
+	for _, ms := range oldMachineSets {
+		if ms.Spec.Replicas != nil && *ms.Spec.Replicas == 0 {
+			continue
+		}
+		nodes, _, err := machineManager.MachineSetNodes(ctx, ms.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			removed[node.Name] = true
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	placements, err := openshift.WorkerPlacements(migration.Spec.MachineSetConfig)
+	if err != nil {
+		return nil, phaseerrors.Validation(fmt.Errorf("invalid worker MachineSet configuration: %w", err))
+	}
+	infraID, err := p.executor.infraManager.GetInfrastructureID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get infrastructure ID: %w", err)
+	}
+	machineSetNames, err := workerMachineSetNames(migration, infraID, placements)
+	if err != nil {
+		return nil, phaseerrors.Validation(err)
+	}
+
+	var problems []string
+	for _, name := range machineSetNames {
+		ms, err := machineManager.GetMachineSet(ctx, name)
+		if apierrors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("new worker MachineSet %s not found", name))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get new worker MachineSet %s: %w", name, err)
+		}
+		nodes, missing, err := machineManager.MachineSetNodes(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, machine := range missing {
+			problems = append(problems, fmt.Sprintf("MachineSet %s: %s", name, machine))
+		}
+		for i := range nodes {
+			if reason := openshift.NodeUnschedulableReason(&nodes[i], ms.Spec.Template.Spec.Taints); reason != "" {
+				problems = append(problems, fmt.Sprintf("MachineSet %s: node %s is unschedulable: %s", name, nodes[i].Name, reason))
+			}
+		}
+		if len(nodes) == 0 && len(missing) == 0 {
+			problems = append(problems, fmt.Sprintf("MachineSet %s has no nodes", name))
+		}
+	}
+
+	capacity, err := machineManager.SchedulingCapacityWithout(ctx, removed)
+	if err != nil {
+		return nil, err
+	}
+	if shortfall := capacity.Shortfall(); shortfall != "" {
+		problems = append(problems, fmt.Sprintf("insufficient capacity without the %d old worker nodes: %s", len(removed), shortfall))
+	}
+	return problems, nil
+}
+
 // Rollback reverts the phase changes
 func (p *ScaleOldMachinesPhase) Rollback(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
 	logger := klog.FromContext(ctx)
@@ -248,27 +399,38 @@ func (p *ScaleOldMachinesPhase) Rollback(ctx context.Context, migration *migrati
 	machineManager := p.executor.GetMachineManager()
 
 	// Get source vCenter from Infrastructure CRD
-	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx)
+	sourceVC, err := p.executor.infraManager.GetSourceVCenter(ctx, migration.Spec.SourceVCenterServer)
 	if err != nil {
 		logger.Error(err, "Failed to get source vCenter from Infrastructure")
 		return err
 	}
 
-	// Get old MachineSets
-	oldMachineSets, err := machineManager.GetMachineSetsByVCenter(ctx, sourceVC.Server)
+	// Restore the source MachineSets from their pre-migration backups, which carry the original
+	// replica counts
+	backups, err := p.executor.machineSetBackups(ctx, migration)
 	if err != nil {
-		logger.Error(err, "Failed to get old MachineSets")
+		logger.Error(err, "Failed to read MachineSet backups")
 		return err
 	}
+	if len(backups) == 0 {
+		return fmt.Errorf("no MachineSet backups found, cannot restore old MachineSets")
+	}
 
-	// Scale back to original replicas
-	// TODO: Restore original replica count from backup
-	for _, ms := range oldMachineSets {
-		logger.Info("Restoring MachineSet", "name", ms.Name)
-		if err := machineManager.ScaleMachineSet(ctx, ms.Name, 3); err != nil {
-			logger.Error(err, "Failed to restore MachineSet", "name", ms.Name)
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(backups)) {
+		ms := backups[name]
+		server, _, err := openshift.MachineSetWorkspace(ms)
+		if err != nil || server != sourceVC.Server {
+			continue
+		}
+		if err := machineManager.RestoreMachineSet(ctx, ms); err != nil {
+			logger.Error(err, "Failed to restore MachineSet", "name", name)
+			errs = append(errs, err)
 		}
 	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
 
 	logger.Info("Successfully restored old MachineSets")
 	return nil
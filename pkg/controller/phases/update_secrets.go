@@ -26,7 +26,7 @@ func (p *UpdateSecretsPhase) Name() migrationv1alpha1.MigrationPhase {
 
 // Validate checks if the phase can be executed
 func (p *UpdateSecretsPhase) Validate(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
-	if migration.Spec.TargetVCenterCredentialsSecret.Name == "" {
+	if migration.Spec.TargetVCenterCredentialsSecret.Name == "" && !p.executor.externalTargetCredentials() {
 		return fmt.Errorf("target vCenter credentials secret name is empty")
 	}
 	if len(migration.Spec.FailureDomains) == 0 {
@@ -57,16 +57,20 @@ func (p *UpdateSecretsPhase) Execute(ctx context.Context, migration *migrationv1
 		"Retrieved vsphere-creds secret",
 		string(p.Name()))
 
-	// Get target vCenter credentials from the specified credentials secret
-	credSecretName := migration.Spec.TargetVCenterCredentialsSecret.Name
-	credSecretNamespace := migration.Spec.TargetVCenterCredentialsSecret.Namespace
-	if credSecretNamespace == "" {
-		credSecretNamespace = "kube-system" // Default namespace
+	// Get target vCenter credentials from the migration's credential source
+	credentials, err := p.executor.TargetCredentialProvider(migration)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to set up target vCenter credential source: " + err.Error(),
+			Logs:    logs,
+		}, err
 	}
 
-	logger.Info("Reading target vCenter credentials", "secret", credSecretName, "namespace", credSecretNamespace)
+	source := p.executor.targetCredentialSourceName(migration)
+	logger.Info("Reading target vCenter credentials", "source", source)
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		fmt.Sprintf("Reading target vCenter credentials from secret %s/%s", credSecretNamespace, credSecretName),
+		"Reading target vCenter credentials from "+source,
 		string(p.Name()))
 
 	// Get unique target vCenter servers from failure domains
@@ -77,9 +81,9 @@ func (p *UpdateSecretsPhase) Execute(ctx context.Context, migration *migrationv1
 
 	// Add credentials for each target vCenter
 	for targetServer := range targetVCenters {
-		// Get credentials from the target credentials secret
-		// The secret should have keys: {vcenter-fqdn}.username and {vcenter-fqdn}.password
-		username, password, err := p.executor.secretManager.GetVCenterCredsFromSecret(ctx, credSecretNamespace, credSecretName, targetServer)
+		// Get credentials from the credential source, by default the target credentials secret
+		// with keys {vcenter-fqdn}.username and {vcenter-fqdn}.password
+		username, password, err := credentials.GetCredentials(ctx, targetServer)
 		if err != nil {
 			return &PhaseResult{
 				Status:  migrationv1alpha1.PhaseStatusFailed,
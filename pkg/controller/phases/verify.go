@@ -3,13 +3,18 @@ package phases
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
+	configv1 "github.com/openshift/api/config/v1"
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 // VerifyPhase performs final verification and re-enables CVO
@@ -77,6 +82,329 @@ func (p *VerifyPhase) waitForCVOReady(ctx context.Context, timeout time.Duration
 	}
 }
 
+// VerifyMachines compares the machines and nodes against the migration's intent and describes
+// every discrepancy: machines or scaled-up MachineSets still on the source vCenter, worker
+// MachineSets on the target without all their nodes Ready, an unfinished control plane rollout
+// and control plane machines outside the target failure domain.
+func (p *VerifyPhase) VerifyMachines(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) ([]string, error) {
+	machineManager := p.executor.GetMachineManager()
+	var discrepancies []string
+
+	sourceServer := migration.Status.SourceVCenter
+	if sourceServer != "" {
+		sourceSets, err := machineManager.GetMachineSetsByVCenter(ctx, sourceServer)
+		if err != nil {
+			return nil, err
+		}
+		for _, ms := range sourceSets {
+			if ms.Spec.Replicas != nil && *ms.Spec.Replicas > 0 {
+				discrepancies = append(discrepancies, fmt.Sprintf("MachineSet %s on source vCenter %s still has %d replicas",
+					ms.Name, sourceServer, *ms.Spec.Replicas))
+			}
+		}
+	}
+
+	machines, err := machineManager.ListMachines(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range machines {
+		server, _, err := openshift.MachineWorkspace(&machines[i])
+		if err != nil {
+			continue
+		}
+		if sourceServer != "" && server == sourceServer {
+			discrepancies = append(discrepancies, fmt.Sprintf("Machine %s still references source vCenter %s", machines[i].Name, sourceServer))
+		}
+	}
+
+	targetServers := make([]string, 0, len(migration.Spec.FailureDomains))
+	for _, fd := range migration.Spec.FailureDomains {
+		if !slices.Contains(targetServers, fd.Server) {
+			targetServers = append(targetServers, fd.Server)
+		}
+	}
+	for _, server := range targetServers {
+		targetSets, err := machineManager.GetMachineSetsByVCenter(ctx, server)
+		if err != nil {
+			return nil, err
+		}
+		for _, ms := range targetSets {
+			desired := int32(0)
+			if ms.Spec.Replicas != nil {
+				desired = *ms.Spec.Replicas
+			}
+			_, ready, _, err := machineManager.CheckNodesReady(ctx, ms.Name)
+			if err != nil {
+				return nil, err
+			}
+			if ready >= desired {
+				continue
+			}
+			unready, err := machineManager.UnreadyMachines(ctx, ms.Name)
+			if err != nil {
+				return nil, err
+			}
+			msg := fmt.Sprintf("MachineSet %s has %d of %d worker nodes Ready", ms.Name, ready, desired)
+			if len(unready) > 0 {
+				msg += ": " + strings.Join(unready, ", ")
+			}
+			discrepancies = append(discrepancies, msg)
+		}
+	}
+
+	complete, replicas, updated, ready, err := machineManager.CheckControlPlaneRolloutStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !complete {
+		discrepancies = append(discrepancies, fmt.Sprintf("Control plane rollout incomplete: %d replicas, %d updated, %d ready",
+			replicas, updated, ready))
+	}
+
+	var controlPlaneFD *configv1.VSpherePlatformFailureDomainSpec
+	for i := range migration.Spec.FailureDomains {
+		if migration.Spec.FailureDomains[i].Name == migration.Spec.ControlPlaneMachineSetConfig.FailureDomain {
+			controlPlaneFD = &migration.Spec.FailureDomains[i]
+			break
+		}
+	}
+	if controlPlaneFD != nil {
+		masters, err := machineManager.ListMachines(ctx, "master")
+		if err != nil {
+			return nil, err
+		}
+		for i := range masters {
+			server, datacenter, err := openshift.MachineWorkspace(&masters[i])
+			if err != nil {
+				discrepancies = append(discrepancies, fmt.Sprintf("Control plane machine %s has no readable placement: %v", masters[i].Name, err))
+				continue
+			}
+			if server != controlPlaneFD.Server || datacenter != controlPlaneFD.Topology.Datacenter {
+				discrepancies = append(discrepancies, fmt.Sprintf("Control plane machine %s is in %s/%s, not failure domain %s (%s/%s)",
+					masters[i].Name, server, datacenter, controlPlaneFD.Name, controlPlaneFD.Server, controlPlaneFD.Topology.Datacenter))
+			}
+		}
+	}
+
+	return discrepancies, nil
+}
+
+// CSIControllerRestartTimeout is how long after Cleanup wrote the vSphere CSI driver
+// configuration Verify waits for the CSI controller pods to restart with it
+const CSIControllerRestartTimeout = 15 * time.Minute
+
+// verifyCSIDriverConfig checks the vSphere CSI driver configuration Cleanup wrote still lists
+// exactly the target vCenters and that the CSI controller has restarted with it. The phase waits
+// while the controller pods restart, up to CSIControllerRestartTimeout. A non-nil result stops
+// the phase.
+func (p *VerifyPhase) verifyCSIDriverConfig(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	updated := migration.Status.CSIDriverConfigUpdateTime
+	if updated == nil {
+		return logs, nil, nil
+	}
+	csiManager := openshift.NewCSIDriverConfigManager(p.executor.kubeClient)
+
+	servers, err := csiManager.ConfiguredVCenters(ctx)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to read vSphere CSI driver config: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	var problems []string
+	for _, server := range servers {
+		if targetFailureDomainOn(migration, server) == "" {
+			problems = append(problems, fmt.Sprintf("vCenter %s is not a target vCenter", server))
+		}
+	}
+	var missing []string
+	for _, fd := range migration.Spec.FailureDomains {
+		if !slices.Contains(servers, fd.Server) && !slices.Contains(missing, fd.Server) {
+			missing = append(missing, fd.Server)
+			problems = append(problems, fmt.Sprintf("target vCenter %s is missing", fd.Server))
+		}
+	}
+	if len(problems) > 0 {
+		err := fmt.Errorf("vSphere CSI driver config does not match the target: %s", strings.Join(problems, "; "))
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error() + " - it may have been overwritten since Cleanup, retry the Cleanup phase",
+			Logs:    logs,
+		}, err
+	}
+
+	restarted, reason, err := csiManager.ControllerRestartedSince(ctx, updated.Time)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to check vSphere CSI driver controller: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	if !restarted && time.Since(updated.Time) > CSIControllerRestartTimeout {
+		err := fmt.Errorf("vSphere CSI driver controller did not load the target config within %s of Cleanup writing it: %s", CSIControllerRestartTimeout, reason)
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error() + " - delete the vmware-vsphere-csi-driver-controller pods in " + openshift.CSIDriverNamespace + " and retry the Verify phase",
+			Logs:    logs,
+		}, err
+	}
+	if !restarted {
+		msg := "Waiting for the vSphere CSI driver controller to load the target config: " + reason
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		return logs, &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
+		}, nil
+	}
+
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		fmt.Sprintf("Verified the vSphere CSI driver is configured for target vCenter(s) %s", strings.Join(servers, ", ")),
+		string(p.Name()))
+	return logs, nil, nil
+}
+
+// VerifyCSIDriverConfig is a public wrapper for testing
+func (p *VerifyPhase) VerifyCSIDriverConfig(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*PhaseResult, error) {
+	_, result, err := p.verifyCSIDriverConfig(ctx, migration, nil)
+	return result, err
+}
+
+// verifyInfrastructureCRDValidations checks that the vcenters validations UpdateInfrastructure
+// removed from the Infrastructure CRD are back, restoring them from those recorded by Backup if
+// they are not. It returns a failed result if the CRD is still left without them.
+func (p *VerifyPhase) verifyInfrastructureCRDValidations(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	recorded := migration.Status.InfrastructureCRDValidations
+	if len(recorded) == 0 {
+		return AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			"No Infrastructure CRD validations were recorded, not verifying them",
+			string(p.Name())), nil, nil
+	}
+
+	missing, err := p.executor.infraManager.MissingVCenterValidations(ctx, recorded)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to check Infrastructure CRD validations: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	if len(missing) > 0 {
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning,
+			fmt.Sprintf("Infrastructure CRD version(s) %s are missing their vcenters validations, restoring them", strings.Join(missing, ", ")),
+			string(p.Name()))
+		if _, err := p.executor.RestoreInfrastructureCRDValidations(ctx, migration); err != nil {
+			return logs, &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: "Failed to restore Infrastructure CRD validations: " + err.Error(),
+				Logs:    logs,
+			}, err
+		}
+		if missing, err = p.executor.infraManager.MissingVCenterValidations(ctx, recorded); err != nil {
+			return logs, &PhaseResult{
+				Status:  migrationv1alpha1.PhaseStatusFailed,
+				Message: "Failed to check Infrastructure CRD validations: " + err.Error(),
+				Logs:    logs,
+			}, err
+		}
+	}
+	if len(missing) > 0 {
+		err := fmt.Errorf("infrastructure CRD version(s) %s are still missing their vcenters validations", strings.Join(missing, ", "))
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: err.Error(),
+			Logs:    logs,
+		}, err
+	}
+
+	return AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Verified the Infrastructure CRD has its vcenters validations",
+		string(p.Name())), nil, nil
+}
+
+// PendingPodGracePeriod is how long a pod may be unschedulable for a volume reason before it
+// counts as stuck. Workloads just restored or rescheduled onto new workers briefly report
+// unbound PVCs while their volumes bind.
+const PendingPodGracePeriod = 2 * time.Minute
+
+// MigratedVolumePods returns the pods Pending because the scheduler cannot place them with a
+// volume migrated by this migration, split into those stuck for longer than
+// PendingPodGracePeriod and those that may still be scheduled
+func (p *VerifyPhase) MigratedVolumePods(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (stuck, settling []openshift.PendingVolumePod, err error) {
+	podManager := openshift.NewPodManager(p.executor.kubeClient)
+	pending, err := podManager.PendingPodsOnMigratedVolumes(ctx, migration.Namespace+"/"+migration.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, pod := range pending {
+		if time.Since(pod.Since.Time) < PendingPodGracePeriod {
+			settling = append(settling, pod)
+		} else {
+			stuck = append(stuck, pod)
+		}
+	}
+	return stuck, settling, nil
+}
+
+// verifyMigratedVolumePods fails the phase when pods are stuck Pending because the scheduler
+// cannot place them with a migrated volume, which is how a PVC that did not rebind to its
+// migrated PV shows up. Pods that only just became unschedulable are waited for.
+func (p *VerifyPhase) verifyMigratedVolumePods(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, logs []migrationv1alpha1.LogEntry) ([]migrationv1alpha1.LogEntry, *PhaseResult, error) {
+	logger := klog.FromContext(ctx)
+
+	logger.Info("Verifying no pods are stuck Pending on migrated volumes")
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"Verifying no pods are stuck Pending on migrated volumes",
+		string(p.Name()))
+
+	stuck, settling, err := p.MigratedVolumePods(ctx, migration)
+	if err != nil {
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to check pods using migrated volumes: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+
+	if len(stuck) > 0 {
+		descriptions := make([]string, 0, len(stuck))
+		for _, pod := range stuck {
+			logger.Info("Pod stuck Pending on a migrated volume", "pod", pod.Namespace+"/"+pod.Name, "reason", pod.Reason, "volumes", pod.Volumes)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelError, pod.String(), string(p.Name()))
+			descriptions = append(descriptions, pod.String())
+		}
+		msg := fmt.Sprintf("%d pod(s) stuck Pending on migrated volumes: %s", len(stuck), strings.Join(descriptions, "; "))
+		return logs, &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: msg,
+			Logs:    logs,
+		}, fmt.Errorf("pods stuck pending on migrated volumes: %s", strings.Join(descriptions, "; "))
+	}
+
+	if len(settling) > 0 {
+		names := make([]string, 0, len(settling))
+		for _, pod := range settling {
+			names = append(names, pod.Namespace+"/"+pod.Name)
+		}
+		msg := fmt.Sprintf("Waiting for %d pod(s) Pending on migrated volumes to be scheduled: %s", len(settling), strings.Join(names, ", "))
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, msg, string(p.Name()))
+		return logs, &PhaseResult{
+			Status:       migrationv1alpha1.PhaseStatusRunning,
+			Message:      msg,
+			Logs:         logs,
+			RequeueAfter: 30 * time.Second,
+		}, nil
+	}
+
+	return AddLog(logs, migrationv1alpha1.LogLevelInfo,
+		"No pods are stuck Pending on migrated volumes",
+		string(p.Name())), nil, nil
+}
+
 // Execute runs the phase
 func (p *VerifyPhase) Execute(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*PhaseResult, error) {
 	logger := klog.FromContext(ctx)
@@ -128,10 +456,8 @@ func (p *VerifyPhase) Execute(ctx context.Context, migration *migrationv1alpha1.
 		}, err
 	}
 
-	// Get source vCenter server to verify it's been removed
-	// We can't use GetSourceVCenter here because it should have been removed
-	// Instead, get the first vCenter before migration started from backup
-	sourceVCServer := ""
+	// Cleanup has removed the source vCenter from the Infrastructure, so use the one Preflight recorded
+	sourceVCServer := migration.Status.SourceVCenter
 
 	// Get expected target vCenter servers from failure domains
 	targetVCServers := make(map[string]bool)
@@ -176,38 +502,76 @@ func (p *VerifyPhase) Execute(ctx context.Context, migration *migrationv1alpha1.
 		"Infrastructure configuration verified",
 		string(p.Name()))
 
+	var result *PhaseResult
+	if logs, result, err = p.verifyInfrastructureCRDValidations(ctx, migration, logs); result != nil {
+		return result, err
+	}
+
+	if logs, result, err = p.verifyCSIDriverConfig(ctx, migration, logs); result != nil {
+		return result, err
+	}
+
+	if logs, result, err = p.verifyMigratedVolumePods(ctx, migration, logs); result != nil {
+		return result, err
+	}
+
 	// Verify all machines reference target vCenter
 	logger.Info("Verifying all machines reference target vCenter")
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 		"Verifying all machines reference target vCenter",
 		string(p.Name()))
 
-	// TODO: Verify machines are using target vCenter
-	// This would check the providerSpec of all machines
+	discrepancies, err := p.VerifyMachines(ctx, migration)
+	if err != nil {
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: "Failed to verify machines: " + err.Error(),
+			Logs:    logs,
+		}, err
+	}
+	if len(discrepancies) > 0 {
+		for _, d := range discrepancies {
+			logger.Info("Machine verification discrepancy", "discrepancy", d)
+			logs = AddLog(logs, migrationv1alpha1.LogLevelWarning, d, string(p.Name()))
+		}
+		return &PhaseResult{
+			Status:  migrationv1alpha1.PhaseStatusFailed,
+			Message: fmt.Sprintf("Machine verification found %d discrepancies: %s", len(discrepancies), strings.Join(discrepancies, "; ")),
+			Logs:    logs,
+		}, fmt.Errorf("machine verification failed: %s", strings.Join(discrepancies, "; "))
+	}
 
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 		"All machines verified",
 		string(p.Name()))
 
+	// The operator may keep the CVO disabled until they have checked the migrated cluster
+	if !AutoReEnableCVO(migration) {
+		message := fmt.Sprintf("cluster-version-operator left scaled down as autoReEnableCVO is false; scale deployment %s/%s back to %d replica(s) once the cluster is verified",
+			CVONamespace, CVOName, ptr.Deref(migration.Status.CVOReplicas, 1))
+		logger.Info("Leaving cluster-version-operator disabled", "reason", "autoReEnableCVO is false")
+		logs = AddLog(logs, migrationv1alpha1.LogLevelWarning, message, string(p.Name()))
+		util.SetCondition(migration, migrationv1alpha1.ConditionCVODisabled, metav1.ConditionTrue,
+			migrationv1alpha1.ReasonCVOKeptDisabled, message)
+
+		logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
+			"Final verification completed - migration successful, CVO still disabled",
+			string(p.Name()))
+		return &PhaseResult{
+			Status:   migrationv1alpha1.PhaseStatusCompleted,
+			Message:  "Migration completed successfully; the cluster-version-operator is still disabled and must be re-enabled manually",
+			Progress: 100,
+			Logs:     logs,
+		}, nil
+	}
+
 	// Re-enable CVO
 	logger.Info("Re-enabling cluster-version-operator")
 	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
 		"Re-enabling cluster-version-operator",
 		string(p.Name()))
 
-	deployment, err := p.executor.kubeClient.AppsV1().Deployments("openshift-cluster-version").Get(ctx, "cluster-version-operator", metav1.GetOptions{})
-	if err != nil {
-		return &PhaseResult{
-			Status:  migrationv1alpha1.PhaseStatusFailed,
-			Message: "Failed to get CVO deployment: " + err.Error(),
-			Logs:    logs,
-		}, err
-	}
-
-	replicas := int32(1)
-	deployment.Spec.Replicas = &replicas
-
-	_, err = p.executor.kubeClient.AppsV1().Deployments("openshift-cluster-version").Update(ctx, deployment, metav1.UpdateOptions{})
+	replicas, err := p.executor.EnableCVO(ctx, migration)
 	if err != nil {
 		return &PhaseResult{
 			Status:  migrationv1alpha1.PhaseStatusFailed,
@@ -216,9 +580,11 @@ func (p *VerifyPhase) Execute(ctx context.Context, migration *migrationv1alpha1.
 		}, err
 	}
 
-	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo,
-		"Re-enabled cluster-version-operator",
-		string(p.Name()))
+	message := "cluster-version-operator is already running"
+	if replicas > 0 {
+		message = fmt.Sprintf("Re-enabled cluster-version-operator with %d replica(s)", replicas)
+	}
+	logs = AddLog(logs, migrationv1alpha1.LogLevelInfo, message, string(p.Name()))
 
 	// Wait for CVO to become ready (not just scaled to 1)
 	logger.Info("Waiting for CVO deployment to become ready")
@@ -257,23 +623,11 @@ func (p *VerifyPhase) Rollback(ctx context.Context, migration *migrationv1alpha1
 	logger.Info("Rollback for Verify phase - re-enabling CVO if needed")
 
 	// Ensure CVO is running
-	deployment, err := p.executor.kubeClient.AppsV1().Deployments("openshift-cluster-version").Get(ctx, "cluster-version-operator", metav1.GetOptions{})
-	if err != nil {
-		logger.Error(err, "Failed to get CVO deployment")
+	if _, err := p.executor.EnableCVO(ctx, migration); err != nil {
+		logger.Error(err, "Failed to re-enable CVO")
 		return err
 	}
 
-	if *deployment.Spec.Replicas == 0 {
-		replicas := int32(1)
-		deployment.Spec.Replicas = &replicas
-
-		_, err = p.executor.kubeClient.AppsV1().Deployments("openshift-cluster-version").Update(ctx, deployment, metav1.UpdateOptions{})
-		if err != nil {
-			logger.Error(err, "Failed to re-enable CVO")
-			return err
-		}
-	}
-
 	logger.Info("CVO is running")
 	return nil
 }
@@ -3,21 +3,36 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
-// syncMigration is the main reconciliation loop
-func (c *MigrationController) syncMigration(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+// maxTransientRetries is how many consecutive transient errors a phase is retried after before it fails
+const maxTransientRetries = 5
+
+// requeueTolerance is how early a reconcile may come before a running phase's NextReconcileTime
+// and still execute it, covering the second precision the time is stored with
+const requeueTolerance = time.Second
+
+// syncMigration is the main reconciliation loop.
+// It returns how long to wait before reconciling the migration again (0 means wait for the next event).
+func (c *MigrationController) syncMigration(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (time.Duration, error) {
 	logger := klog.FromContext(ctx).WithValues("migration", migration.Name, "namespace", migration.Namespace)
 	ctx = klog.NewContext(ctx, logger)
 
 	logger.Info("Reconciling migration", "phase", migration.Status.Phase, "state", migration.Spec.State)
+	migration.Status.ObservedGeneration = migration.Generation
+
+	// Topology paths may be given as bare names or inventory paths; phases use the canonical form
+	util.NormalizeFailureDomains(migration.Spec.FailureDomains)
 
 	// Initialize status if needed
 	if migration.Status.Phase == migrationv1alpha1.PhaseNone {
@@ -34,51 +49,113 @@ func (c *MigrationController) syncMigration(ctx context.Context, migration *migr
 		logger.Info("Migration is pending, waiting for state to be set to Running")
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
 			migrationv1alpha1.ReasonReconcileSucceeded, "Migration is pending")
-		return nil
+		return 0, nil
 
 	case migrationv1alpha1.MigrationStatePaused:
 		logger.Info("Migration is paused")
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
 			migrationv1alpha1.ReasonReconcileSucceeded, "Migration is paused")
-		return nil
+		return 0, nil
 
 	case migrationv1alpha1.MigrationStateRollback:
 		logger.Info("Initiating rollback")
 		if err := c.stateMachine.InitiateRollback(ctx, migration, c.getAllPhases()); err != nil {
 			util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionFalse,
 				migrationv1alpha1.ReasonReconcileFailed, fmt.Sprintf("Rollback failed: %v", err))
-			return err
+			return 0, err
 		}
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
 			migrationv1alpha1.ReasonReconcileSucceeded, "Rollback completed")
-		return nil
+		return 0, nil
+
+	case migrationv1alpha1.MigrationStateCancelled:
+		switch migration.Status.Phase {
+		case migrationv1alpha1.PhaseCancelled:
+			logger.Info("Migration is cancelled")
+		case migrationv1alpha1.PhaseCompleted, migrationv1alpha1.PhaseRollbackCompleted:
+			logger.Info("Migration already finished, nothing to cancel", "phase", migration.Status.Phase)
+			return 0, nil
+		default:
+			c.stateMachine.CancelMigration(ctx, migration)
+		}
+		cancellation := migration.Status.Cancellation
+		message := fmt.Sprintf("Migration cancelled in phase %s", migration.Status.Phase)
+		if cancellation != nil {
+			message = fmt.Sprintf("Migration cancelled in phase %s", cancellation.CancelledPhase)
+			if len(cancellation.ManualIntervention) > 0 {
+				message += fmt.Sprintf(", %d item(s) need manual intervention: %s",
+					len(cancellation.ManualIntervention), strings.Join(cancellation.ManualIntervention, "; "))
+			}
+		}
+		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
+			migrationv1alpha1.ReasonCancelled, message)
+		util.SetCondition(migration, migrationv1alpha1.ConditionProgressing, metav1.ConditionFalse,
+			migrationv1alpha1.ReasonCancelled, "Migration cancelled")
+		return 0, nil
 
 	case migrationv1alpha1.MigrationStateRunning:
 		// Continue with migration execution
 	}
 
+	// A cancelled migration stays cancelled; it can still be rolled back
+	if migration.Status.Phase == migrationv1alpha1.PhaseCancelled {
+		logger.Info("Migration was cancelled, set state to Rollback to revert it")
+		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
+			migrationv1alpha1.ReasonCancelled, "Migration was cancelled - set state to Rollback to revert it")
+		return 0, nil
+	}
+
+	// A failed migration waits for the failed phase to be retried or for a rollback
+	if migration.Status.Phase == migrationv1alpha1.PhaseFailed {
+		failed := c.stateMachine.FailedPhase(migration)
+		message := fmt.Sprintf("Phase %s failed - annotate with %s=%s to retry it, or set state to Rollback",
+			failed, migrationv1alpha1.RetryPhaseAnnotation, failed)
+		logger.Info("Migration has failed, waiting for a retry or rollback", "failedPhase", failed)
+		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionFalse,
+			migrationv1alpha1.ReasonFailed, message)
+		return 0, nil
+	}
+
 	// Check if migration is already completed
 	if migration.Status.Phase == migrationv1alpha1.PhaseCompleted {
 		logger.Info("Migration already completed")
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
 			migrationv1alpha1.ReasonCompleted, "Migration completed successfully")
-		return nil
+		return 0, nil
 	}
 
 	// Get current phase
 	currentPhase := migration.Status.Phase
 	phase := c.getPhaseImplementation(currentPhase)
 	if phase == nil {
-		return fmt.Errorf("no implementation found for phase %s", currentPhase)
+		return 0, fmt.Errorf("no implementation found for phase %s", currentPhase)
+	}
+
+	// Approve a phase waiting at its approval gate when requested through the annotation.
+	// Approval is read from metadata so it cannot be overwritten by the controller's status writes.
+	// The annotation is removed either way, so an approval given before the phase was waiting, or
+	// left over from an earlier run, cannot approve the gate when the phase reaches it again.
+	if migration.Annotations[migrationv1alpha1.ApprovePhaseAnnotation] == string(currentPhase) {
+		if err := c.stateMachine.ApprovePhase(migration, currentPhase); err != nil {
+			logger.Info("Ignoring phase approval", "phase", currentPhase, "reason", err.Error())
+		} else {
+			logger.Info("Phase approved", "phase", currentPhase)
+		}
+		if err := c.removeMigrationAnnotation(ctx, migration, migrationv1alpha1.ApprovePhaseAnnotation); err != nil {
+			return 0, err
+		}
 	}
 
 	// Check if phase should be executed
 	if !c.stateMachine.ShouldExecutePhase(migration, currentPhase) {
-		logger.Info("Phase should not be executed yet", "phase", currentPhase)
-		c.stateMachine.MarkPhaseForApproval(migration, currentPhase, "Waiting for approval")
+		message := fmt.Sprintf("Waiting for approval to start phase %s - annotate with %s=%s to approve",
+			currentPhase, migrationv1alpha1.ApprovePhaseAnnotation, currentPhase)
+		logger.Info("Phase is waiting for approval", "phase", currentPhase)
+		c.stateMachine.MarkPhaseForApproval(migration, currentPhase, message)
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
-			migrationv1alpha1.ReasonReconcileSucceeded, "Waiting for phase approval")
-		return nil
+			migrationv1alpha1.ReasonWaitingForApproval, message)
+		_, requeueAfter := c.stateMachine.ShouldRequeue(migration, nil)
+		return requeueAfter, nil
 	}
 
 	// Check for interrupted phase execution (e.g., controller crash/restart)
@@ -94,17 +171,41 @@ func (c *MigrationController) syncMigration(ctx context.Context, migration *migr
 
 	// Execute phase
 	logger.Info("Executing phase", "phase", currentPhase)
+	c.stateMachine.RecordPhaseStart(migration, currentPhase)
 	util.SetCondition(migration, migrationv1alpha1.ConditionProgressing, metav1.ConditionTrue,
 		migrationv1alpha1.ReasonProgressing, fmt.Sprintf("Executing phase %s", currentPhase))
 
 	result, err := c.phaseExecutor.ExecutePhase(ctx, phase, migration)
 	if err != nil {
-		logger.Error(err, "Phase execution failed", "phase", currentPhase)
+		errClass := phaseerrors.Class(err)
+
+		// Transient errors keep the phase running and are retried with backoff
+		if errClass == phaseerrors.ErrTransient && retryTransientError(migration, currentPhase, err) {
+			logger.Info("Phase hit a transient error, retrying",
+				"phase", currentPhase,
+				"retries", migration.Status.CurrentPhaseState.TransientRetries,
+				"error", err)
+			util.SetCondition(migration, migrationv1alpha1.ConditionProgressing, metav1.ConditionTrue,
+				migrationv1alpha1.ReasonProgressing, migration.Status.CurrentPhaseState.Message)
+			return 0, err
+		}
+
+		logger.Error(err, "Phase execution failed", "phase", currentPhase, "errorClass", phaseerrors.ClassName(err))
 
 		// Record failure
 		c.stateMachine.RecordPhaseCompletion(migration, currentPhase, result)
 		migration.Status.Phase = migrationv1alpha1.PhaseFailed
 
+		// A data safety failure left state only an operator should touch, so it is never rolled back
+		if errClass == phaseerrors.ErrDataSafety {
+			logger.Info("Phase failed without a safe automatic rollback, manual intervention required",
+				"failedPhase", currentPhase)
+			util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionFalse,
+				migrationv1alpha1.ReasonReconcileFailed,
+				fmt.Sprintf("Phase %s failed and needs manual intervention, automatic rollback skipped: %v", currentPhase, err))
+			return 0, err
+		}
+
 		// Check if should rollback automatically
 		if migration.Spec.RollbackOnFailure {
 			logger.Info("========================================")
@@ -121,7 +222,12 @@ func (c *MigrationController) syncMigration(ctx context.Context, migration *migr
 
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionFalse,
 			migrationv1alpha1.ReasonReconcileFailed, fmt.Sprintf("Phase %s failed: %v", currentPhase, err))
-		return err
+
+		// Retrying cannot fix validation or unrecoverable errors, so they are not requeued
+		if errClass == phaseerrors.ErrValidation || errClass == phaseerrors.ErrUnrecoverable {
+			return 0, nil
+		}
+		return 0, err
 	}
 
 	// Check if phase is still running (e.g., waiting for pods, operators)
@@ -134,30 +240,43 @@ func (c *MigrationController) syncMigration(ctx context.Context, migration *migr
 		// Update current phase state to reflect running status
 		now := metav1.Now()
 
-		// Preserve existing StartTime if phase was already running
+		// Preserve existing StartTime if phase was already running, and the approval so a
+		// gated phase is not stopped again on its next reconcile
 		var startTime *metav1.Time
-		if migration.Status.CurrentPhaseState != nil &&
-			migration.Status.CurrentPhaseState.Name == currentPhase &&
-			migration.Status.CurrentPhaseState.StartTime != nil {
-			startTime = migration.Status.CurrentPhaseState.StartTime
-		} else {
+		var requiresApproval, approved bool
+		if existing := migration.Status.CurrentPhaseState; existing != nil && existing.Name == currentPhase {
+			startTime = existing.StartTime
+			requiresApproval = existing.RequiresApproval
+			approved = existing.Approved
+		}
+		if startTime == nil {
 			startTime = &now
 		}
 
+		// Don't advance to next phase - requeue after the delay requested by the phase
+		_, requeueAfter := c.stateMachine.ShouldRequeue(migration, result)
+		var nextReconcile *metav1.Time
+		if requeueAfter > 0 {
+			nextReconcile = &metav1.Time{Time: now.Add(requeueAfter)}
+		}
+
 		migration.Status.CurrentPhaseState = &migrationv1alpha1.PhaseState{
-			Name:          currentPhase,
-			Status:        migrationv1alpha1.PhaseStatusRunning,
-			Progress:      result.Progress,
-			Message:       result.Message,
-			StartTime:     startTime,
-			LastHeartbeat: &now,
+			Name:              currentPhase,
+			Status:            migrationv1alpha1.PhaseStatusRunning,
+			Progress:          result.Progress,
+			Message:           result.Message,
+			StartTime:         startTime,
+			LastHeartbeat:     &now,
+			RequiresApproval:  requiresApproval,
+			Approved:          approved,
+			NextReconcileTime: nextReconcile,
 		}
 
+		c.stateMachine.UpdateOverallProgress(migration)
 		util.SetCondition(migration, migrationv1alpha1.ConditionProgressing, metav1.ConditionTrue,
 			migrationv1alpha1.ReasonProgressing, result.Message)
 
-		// Don't advance to next phase - status update will trigger requeue
-		return nil
+		return requeueAfter, nil
 	}
 
 	// Only record completion and advance if status is Completed
@@ -167,14 +286,12 @@ func (c *MigrationController) syncMigration(ctx context.Context, migration *migr
 	// Move to next phase
 	nextPhase, err := c.stateMachine.GetNextPhase(migration)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if nextPhase == migrationv1alpha1.PhaseCompleted {
 		logger.Info("All phases completed successfully")
-		migration.Status.Phase = migrationv1alpha1.PhaseCompleted
-		now := metav1.Now()
-		migration.Status.CompletionTime = &now
+		c.stateMachine.CompleteMigration(migration)
 		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
 			migrationv1alpha1.ReasonCompleted, "Migration completed successfully")
 		util.SetCondition(migration, migrationv1alpha1.ConditionProgressing, metav1.ConditionFalse,
@@ -186,12 +303,20 @@ func (c *MigrationController) syncMigration(ctx context.Context, migration *migr
 			migrationv1alpha1.ReasonReconcileSucceeded, fmt.Sprintf("Moved to phase %s", nextPhase))
 	}
 
-	// Note: Requeue logic is handled by the controller-runtime framework
-	// The controller will automatically requeue based on the result from syncMigration
-	// Removing the blocking time.Sleep to allow proper workqueue-based requeuing
-	_, _ = c.stateMachine.ShouldRequeue(migration, result)
+	c.stateMachine.UpdateOverallProgress(migration)
 
-	return nil
+	// Stop for review before the next phase when asked to; the operator resumes by setting Running
+	if summary, paused := c.stateMachine.PauseAfterPhase(migration, currentPhase, result); paused {
+		logger.Info("Migration paused for review", "completedPhase", currentPhase, "nextPhase", nextPhase)
+		util.SetCondition(migration, migrationv1alpha1.ConditionReconciled, metav1.ConditionTrue,
+			migrationv1alpha1.ReasonPausedForReview, summary)
+		return 0, nil
+	}
+
+	// Requeue so the next phase starts without waiting for an external event
+	_, requeueAfter := c.stateMachine.ShouldRequeue(migration, result)
+
+	return requeueAfter, nil
 }
 
 // getPhaseImplementation returns the phase implementation for a given phase
@@ -265,3 +390,47 @@ func (c *MigrationController) getAllPhases() []phases.Phase {
 		phases.NewVerifyPhase(c.phaseExecutor),
 	}
 }
+
+// retryTransientError records a transient error against the running phase and reports whether
+// the phase should be retried; after maxTransientRetries consecutive errors it is failed instead
+func retryTransientError(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase, err error) bool {
+	state := migration.Status.CurrentPhaseState
+	if state == nil || state.Name != phase {
+		return false
+	}
+	if state.TransientRetries >= maxTransientRetries {
+		return false
+	}
+
+	now := metav1.Now()
+	if state.StartTime == nil {
+		state.StartTime = &now
+	}
+	state.Status = migrationv1alpha1.PhaseStatusRunning
+	// The retry comes through the rate limiter, not after the phase's requested delay
+	state.NextReconcileTime = nil
+	state.TransientRetries++
+	state.LastHeartbeat = &now
+	state.Message = fmt.Sprintf("Retrying after transient error (%d/%d): %v", state.TransientRetries, maxTransientRetries, err)
+	return true
+}
+
+// waitingForRequeue reports whether a migration is reconciled before its running phase asked to
+// be executed again, with no spec change since it was last reconciled, and how long remains until
+// the phase is due. Such reconciles, mostly caused by the controller's own status writes, are
+// skipped so phases do not repeat vCenter calls they only guard with existence checks.
+func waitingForRequeue(migration *migrationv1alpha1.VmwareCloudFoundationMigration) (time.Duration, bool) {
+	if migration.Generation != migration.Status.ObservedGeneration {
+		return 0, false
+	}
+	state := migration.Status.CurrentPhaseState
+	if state == nil || state.Name != migration.Status.Phase ||
+		state.Status != migrationv1alpha1.PhaseStatusRunning || state.NextReconcileTime == nil {
+		return 0, false
+	}
+	remaining := time.Until(state.NextReconcileTime.Time)
+	if remaining <= requeueTolerance {
+		return 0, false
+	}
+	return remaining, true
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/report"
+)
+
+// writeMigrationReport writes the report of a migration that reached a terminal phase to a
+// ConfigMap in its namespace. The ConfigMap has no owner reference so it survives the migration's
+// deletion; it is rewritten whenever a retried migration finishes again.
+func (c *MigrationController) writeMigrationReport(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      report.ConfigMapName(migration.Name),
+			Namespace: migration.Namespace,
+			Labels: map[string]string{
+				backup.BackupMigrationLabel: migration.Name,
+			},
+		},
+		Data: report.Generate(migration),
+	}
+
+	configMaps := c.kubeClient.CoreV1().ConfigMaps(migration.Namespace)
+	existing, err := configMaps.Get(ctx, cm.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = configMaps.Create(ctx, cm, metav1.CreateOptions{})
+	case err == nil:
+		existing.Labels = cm.Labels
+		existing.Data = cm.Data
+		_, err = configMaps.Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write migration report ConfigMap %s/%s: %w", cm.Namespace, cm.Name, err)
+	}
+
+	klog.FromContext(ctx).Info("Wrote migration report", "configMap", cm.Namespace+"/"+cm.Name, "result", migration.Status.Phase)
+	return nil
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+)
+
+// handleRetryRequest acts on the retry-phase annotation. A failed migration re-enters the state
+// machine at the requested phase when it is the phase that failed; any other request is ignored.
+// The annotation is removed either way so a stale request cannot rewind a later failure.
+func (c *MigrationController) handleRetryRequest(ctx context.Context, obj *unstructured.Unstructured, migration *migrationv1alpha1.VmwareCloudFoundationMigration) error {
+	logger := klog.FromContext(ctx)
+
+	annotations := obj.GetAnnotations()
+	requested, ok := annotations[migrationv1alpha1.RetryPhaseAnnotation]
+	if !ok {
+		return nil
+	}
+
+	phase := migrationv1alpha1.MigrationPhase(requested)
+	if err := c.stateMachine.RetryFailedPhase(migration, phase); err != nil {
+		logger.Info("Ignoring phase retry request", "phase", phase, "reason", err.Error())
+	} else {
+		logger.Info("Retrying failed phase", "phase", phase)
+	}
+
+	delete(annotations, migrationv1alpha1.RetryPhaseAnnotation)
+	obj.SetAnnotations(annotations)
+	updated, err := c.dynamicClient.Resource(c.gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to remove %s annotation: %w", migrationv1alpha1.RetryPhaseAnnotation, err)
+	}
+	obj.SetResourceVersion(updated.GetResourceVersion())
+	return nil
+}
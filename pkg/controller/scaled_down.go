@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+)
+
+// checkScaledDownWorkloads escalates failed volumes whose workloads have stayed scaled down beyond
+// the alert threshold: each is reported once with a Warning event, and the WorkloadsScaledDown
+// condition lists all of them. It returns how long until the next volume crosses the threshold,
+// or zero if none will.
+func (c *MigrationController) checkScaledDownWorkloads(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	logger := klog.FromContext(ctx)
+
+	threshold := phases.ScaledDownAlertThreshold(migration)
+	exceeded, next := phases.ScaledDownVolumes(migration.Status.CSIVolumeMigration, threshold, time.Now())
+	if len(exceeded) == 0 {
+		if util.GetCondition(migration, migrationv1alpha1.ConditionWorkloadsScaledDown) != nil {
+			util.SetCondition(migration, migrationv1alpha1.ConditionWorkloadsScaledDown, metav1.ConditionFalse,
+				migrationv1alpha1.ReasonWorkloadsRestored, "No failed volume has workloads scaled down beyond the alert threshold")
+		}
+		return next
+	}
+
+	volumes := make([]string, 0, len(exceeded))
+	for _, pvState := range exceeded {
+		workloads := scaledDownWorkloads(pvState)
+		volumes = append(volumes, fmt.Sprintf("%s (%s)", pvState.PVName, workloads))
+		if pvState.ScaledDownAlertTime != nil {
+			continue
+		}
+
+		message := fmt.Sprintf("Workloads %s of failed volume %s have been scaled down for more than %s and need manual intervention: %s",
+			workloads, pvState.PVName, threshold, pvState.Message)
+		if err := c.recordWarningEvent(ctx, migration, migrationv1alpha1.ReasonScaledDownTooLong, message); err != nil {
+			// The event is retried on the next reconcile
+			logger.Error(err, "Failed to record scaled down workloads event", "pv", pvState.PVName)
+			continue
+		}
+		now := metav1.Now()
+		pvState.ScaledDownAlertTime = &now
+		logger.Info("Workloads of failed volume scaled down beyond the alert threshold",
+			"pv", pvState.PVName, "workloads", workloads, "threshold", threshold)
+	}
+
+	util.SetCondition(migration, migrationv1alpha1.ConditionWorkloadsScaledDown, metav1.ConditionTrue,
+		migrationv1alpha1.ReasonScaledDownTooLong,
+		fmt.Sprintf("Workloads of %d failed volume(s) have been scaled down for more than %s: %s",
+			len(exceeded), threshold, strings.Join(volumes, "; ")))
+	return next
+}
+
+// scaledDownWorkloads lists the workloads scaled down for a volume as <Kind>/<namespace>/<name>
+func scaledDownWorkloads(pvState *migrationv1alpha1.PVMigrationState) string {
+	workloads := make([]string, 0, len(pvState.ScaledDownResources))
+	for _, resource := range pvState.ScaledDownResources {
+		workloads = append(workloads, fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Namespace, resource.Name))
+	}
+	return strings.Join(workloads, ", ")
+}
+
+// recordWarningEvent records a Warning event on a migration, so it can be picked up by alerting
+func (c *MigrationController) recordWarningEvent(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration, reason, message string) error {
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: migration.Name + ".",
+			Namespace:    migration.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      c.gvr.GroupVersion().String(),
+			Kind:            "VmwareCloudFoundationMigration",
+			Namespace:       migration.Namespace,
+			Name:            migration.Name,
+			UID:             migration.UID,
+			ResourceVersion: migration.ResourceVersion,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: "vmware-cloud-foundation-migration"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	if _, err := c.kubeClient.CoreV1().Events(migration.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create %s event: %w", reason, err)
+	}
+	return nil
+}
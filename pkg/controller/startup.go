@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+)
+
+// CleanupLeftoverDummyVMs deletes the dummy VMs left behind by earlier runs of each migration
+// that has reached the vCenters and is not migrating volumes right now. It is meant to run once
+// at startup, before any migration is reconciled; failures are logged and do not stop the controller.
+func (c *MigrationController) CleanupLeftoverDummyVMs(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+
+	list, err := c.dynamicClient.Resource(c.gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Error(err, "Failed to list migrations for dummy VM cleanup")
+		return
+	}
+
+	for i := range list.Items {
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, migration); err != nil {
+			logger.Error(err, "Failed to convert migration for dummy VM cleanup", "migration", list.Items[i].GetName())
+			continue
+		}
+		if migration.Status.SourceVCenter == "" || migration.Status.Phase == migrationv1alpha1.PhaseMigrateCSIVolumes {
+			continue
+		}
+
+		migrationLogger := logger.WithValues("migration", migration.Name, "namespace", migration.Namespace)
+		unlock := c.migrationLocks.Lock(migrationQueueKey(migration))
+		cleanup, err := c.phaseExecutor.CleanupLeftoverDummyVMs(ctx, migration)
+		unlock()
+		if err != nil {
+			migrationLogger.Error(err, "Failed to clean up leftover dummy VMs")
+		}
+		if cleanup == nil {
+			continue
+		}
+		migrationLogger.Info("Cleaned up leftover dummy VMs",
+			"deleted", cleanup.Deleted,
+			"detachedVolumes", cleanup.Detached)
+		for name, reason := range cleanup.Retained {
+			migrationLogger.Info("Kept leftover dummy VM, remove it manually once its disks are safe", "vm", name, "reason", reason)
+		}
+	}
+}
+
+// RestoreInfrastructureCRDValidations restores the vcenters validations of the Infrastructure CRD
+// from those recorded by each migration, in case the controller stopped while UpdateInfrastructure
+// had them removed and left cluster configuration validation weakened. It is meant to run once at
+// startup, before any migration is reconciled; failures are logged and do not stop the controller.
+func (c *MigrationController) RestoreInfrastructureCRDValidations(ctx context.Context) {
+	logger := klog.FromContext(ctx)
+
+	list, err := c.dynamicClient.Resource(c.gvr).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logger.Error(err, "Failed to list migrations for Infrastructure CRD validation check")
+		return
+	}
+
+	for i := range list.Items {
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[i].Object, migration); err != nil {
+			logger.Error(err, "Failed to convert migration for Infrastructure CRD validation check", "migration", list.Items[i].GetName())
+			continue
+		}
+
+		migrationLogger := logger.WithValues("migration", migration.Name, "namespace", migration.Namespace)
+		restored, err := c.phaseExecutor.RestoreInfrastructureCRDValidations(ctx, migration)
+		if err != nil {
+			migrationLogger.Error(err, "Failed to restore Infrastructure CRD validations")
+			continue
+		}
+		if len(restored) > 0 {
+			migrationLogger.Info("Restored Infrastructure CRD validations left removed by an interrupted UpdateInfrastructure", "versions", restored)
+		}
+	}
+}
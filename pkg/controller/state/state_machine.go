@@ -3,6 +3,8 @@ package state
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -10,18 +12,48 @@ import (
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/notify"
 )
 
+// DefaultPhaseWeights are the relative durations of the phases used to compute overall progress.
+// Phases not listed weigh 1.
+var DefaultPhaseWeights = map[migrationv1alpha1.MigrationPhase]int32{
+	migrationv1alpha1.PhasePreflight:            2,
+	migrationv1alpha1.PhaseBackup:               2,
+	migrationv1alpha1.PhaseDisableCVO:           1,
+	migrationv1alpha1.PhaseUpdateSecrets:        1,
+	migrationv1alpha1.PhaseCreateTags:           1,
+	migrationv1alpha1.PhaseCreateFolder:         1,
+	migrationv1alpha1.PhaseDeleteCPMS:           2,
+	migrationv1alpha1.PhaseUpdateInfrastructure: 2,
+	migrationv1alpha1.PhaseUpdateConfig:         2,
+	migrationv1alpha1.PhaseRestartPods:          5,
+	migrationv1alpha1.PhaseMonitorHealth:        10,
+	migrationv1alpha1.PhaseCreateWorkers:        15,
+	migrationv1alpha1.PhaseRecreateCPMS:         20,
+	migrationv1alpha1.PhaseMigrateCSIVolumes:    30,
+	migrationv1alpha1.PhaseScaleOldMachines:     10,
+	migrationv1alpha1.PhaseCleanup:              3,
+	migrationv1alpha1.PhaseVerify:               3,
+}
+
+// DefaultRequeueInterval is how often a running migration is reconciled when neither its phase
+// nor its spec asks for another delay
+const DefaultRequeueInterval = 10 * time.Second
+
 // StateMachine manages migration state transitions
 type StateMachine struct {
-	phaseExecutor *phases.PhaseExecutor
-	phaseOrder    []migrationv1alpha1.MigrationPhase
+	phaseExecutor   *phases.PhaseExecutor
+	phaseOrder      []migrationv1alpha1.MigrationPhase
+	requeueInterval time.Duration
+	notifier        notify.Notifier
 }
 
 // NewStateMachine creates a new state machine
 func NewStateMachine(executor *phases.PhaseExecutor) *StateMachine {
 	return &StateMachine{
-		phaseExecutor: executor,
+		phaseExecutor:   executor,
+		requeueInterval: DefaultRequeueInterval,
 		phaseOrder: []migrationv1alpha1.MigrationPhase{
 			migrationv1alpha1.PhasePreflight,
 			migrationv1alpha1.PhaseBackup,
@@ -80,21 +112,82 @@ func (s *StateMachine) ShouldExecutePhase(migration *migrationv1alpha1.VmwareClo
 		return false
 	}
 
-	// Check if phase requires approval
+	if !s.PhaseRequiresApproval(migration, phase) {
+		return true
+	}
+
+	// Gated phases only run once they have been approved
+	return migration.Status.CurrentPhaseState != nil &&
+		migration.Status.CurrentPhaseState.Name == phase &&
+		migration.Status.CurrentPhaseState.Approved
+}
+
+// PhaseRequiresApproval reports whether a phase must be approved before it runs, either because
+// every phase is gated in Manual approval mode or because it is listed in RequireApprovalBefore
+func (s *StateMachine) PhaseRequiresApproval(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase) bool {
 	if migration.Spec.ApprovalMode == migrationv1alpha1.ApprovalModeManual {
-		// Check if current phase state requires approval
-		if migration.Status.CurrentPhaseState != nil &&
-			migration.Status.CurrentPhaseState.Name == phase &&
-			migration.Status.CurrentPhaseState.RequiresApproval &&
-			!migration.Status.CurrentPhaseState.Approved {
-			return false
+		return true
+	}
+	return slices.Contains(migration.Spec.RequireApprovalBefore, phase)
+}
+
+// PauseAfterPhase pauses a migration that asked to stop for review once the given phase completed,
+// before the next phase starts. It sets the state to Paused and returns a summary of the phase's
+// findings; it returns false when the migration should carry on.
+func (s *StateMachine) PauseAfterPhase(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase, result *phases.PhaseResult) (string, bool) {
+	if phase != migrationv1alpha1.PhasePreflight || !migration.Spec.PauseAfterPreflight {
+		return "", false
+	}
+
+	var findings []string
+	if result != nil {
+		for _, entry := range result.Logs {
+			if entry.Level == migrationv1alpha1.LogLevelWarning || entry.Level == migrationv1alpha1.LogLevelError {
+				findings = append(findings, entry.Message)
+			}
 		}
 	}
 
-	return true
+	summary := fmt.Sprintf("Phase %s completed", phase)
+	if result != nil && result.Message != "" {
+		summary += ": " + result.Message
+	}
+	if len(findings) == 0 {
+		summary += ", no warnings"
+	} else {
+		summary += fmt.Sprintf(", %d warning(s): %s", len(findings), strings.Join(findings, "; "))
+	}
+
+	migration.Spec.State = migrationv1alpha1.MigrationStatePaused
+	summary += fmt.Sprintf(" - paused for review, set state to %s to continue", migrationv1alpha1.MigrationStateRunning)
+	s.notify(notify.EventPausedForReview, migration, phase, migrationv1alpha1.PhaseStatusCompleted, summary)
+	return summary, true
+}
+
+// RecordPhaseStart notifies that the migration started when the given phase is the first
+// execution of its first phase. Later executions of a running phase are not reported.
+func (s *StateMachine) RecordPhaseStart(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase) {
+	if len(s.phaseOrder) == 0 || phase != s.phaseOrder[0] || len(migration.Status.PhaseHistory) > 0 {
+		return
+	}
+	if current := migration.Status.CurrentPhaseState; current != nil && current.Name == phase && current.Status == migrationv1alpha1.PhaseStatusRunning {
+		return
+	}
+	s.notify(notify.EventStarted, migration, phase, migrationv1alpha1.PhaseStatusRunning,
+		fmt.Sprintf("Migration started with phase %s", phase))
+}
+
+// CompleteMigration marks a migration whose phases all completed as Completed
+func (s *StateMachine) CompleteMigration(migration *migrationv1alpha1.VmwareCloudFoundationMigration) {
+	migration.Status.Phase = migrationv1alpha1.PhaseCompleted
+	now := metav1.Now()
+	migration.Status.CompletionTime = &now
+	s.notify(notify.EventCompleted, migration, migrationv1alpha1.PhaseCompleted, migrationv1alpha1.PhaseStatusCompleted,
+		"Migration completed successfully")
 }
 
-// RecordPhaseCompletion records a completed phase in history
+// RecordPhaseCompletion records a completed phase in history, trimming its logs and those of the
+// whole history to the configured caps
 func (s *StateMachine) RecordPhaseCompletion(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase, result *phases.PhaseResult) {
 	now := metav1.Now()
 
@@ -121,7 +214,7 @@ func (s *StateMachine) RecordPhaseCompletion(migration *migrationv1alpha1.Vmware
 		StartTime:      startTime,
 		CompletionTime: &now,
 		Message:        result.Message,
-		Logs:           result.Logs,
+		Logs:           phases.TrimLogs(result.Logs, phases.MaxPhaseLogEntries(migration)),
 	}
 
 	// Update or add to history
@@ -137,9 +230,93 @@ func (s *StateMachine) RecordPhaseCompletion(migration *migrationv1alpha1.Vmware
 	if !updated {
 		migration.Status.PhaseHistory = append(migration.Status.PhaseHistory, historyEntry)
 	}
+	phases.TrimPhaseHistoryLogs(migration.Status.PhaseHistory, phases.MaxTotalLogEntries(migration))
 
 	// Clear current phase state
 	migration.Status.CurrentPhaseState = nil
+
+	if result.Status == migrationv1alpha1.PhaseStatusFailed {
+		s.notify(notify.EventFailed, migration, phase, result.Status, result.Message)
+	} else {
+		s.notify(notify.EventPhaseCompleted, migration, phase, result.Status, result.Message)
+	}
+}
+
+// FailedPhase returns the phase a failed migration stopped in, or PhaseNone if it has not failed
+func (s *StateMachine) FailedPhase(migration *migrationv1alpha1.VmwareCloudFoundationMigration) migrationv1alpha1.MigrationPhase {
+	if migration.Status.Phase != migrationv1alpha1.PhaseFailed || len(migration.Status.PhaseHistory) == 0 {
+		return migrationv1alpha1.PhaseNone
+	}
+
+	last := migration.Status.PhaseHistory[len(migration.Status.PhaseHistory)-1]
+	if last.Status != migrationv1alpha1.PhaseStatusFailed {
+		return migrationv1alpha1.PhaseNone
+	}
+	return last.Phase
+}
+
+// RetryFailedPhase re-enters the state machine at the phase a failed migration stopped in. Only
+// that phase may be retried, so completed phases are neither re-run nor skipped.
+func (s *StateMachine) RetryFailedPhase(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase) error {
+	failed := s.FailedPhase(migration)
+	if failed == migrationv1alpha1.PhaseNone {
+		return fmt.Errorf("migration is in phase %s, not failed", migration.Status.Phase)
+	}
+	if phase != failed {
+		return fmt.Errorf("migration failed in phase %s, not %s", failed, phase)
+	}
+
+	migration.Status.Phase = phase
+	migration.Status.CurrentPhaseState = nil
+	return nil
+}
+
+// phaseWeight returns the weight of a phase in overall progress, honoring the migration's overrides
+func phaseWeight(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase) int32 {
+	if weight, ok := migration.Spec.PhaseWeights[phase]; ok && weight >= 0 {
+		return weight
+	}
+	if weight, ok := DefaultPhaseWeights[phase]; ok {
+		return weight
+	}
+	return 1
+}
+
+// UpdateOverallProgress recomputes status.overallProgress from the phases completed so far and the
+// progress of the current one. Progress never goes backwards, e.g. when a phase is retried; it is
+// reset by a rollback instead.
+func (s *StateMachine) UpdateOverallProgress(migration *migrationv1alpha1.VmwareCloudFoundationMigration) {
+	current := migration.Status.Phase
+	if current == migrationv1alpha1.PhaseCompleted {
+		migration.Status.OverallProgress = 100
+		return
+	}
+
+	index := slices.Index(s.phaseOrder, current)
+	if index < 0 {
+		// Failed or rolling back - leave progress where it stopped
+		return
+	}
+
+	var total, done int64
+	for i, phase := range s.phaseOrder {
+		weight := int64(phaseWeight(migration, phase))
+		total += weight
+		if i < index {
+			done += weight
+		}
+	}
+	if state := migration.Status.CurrentPhaseState; state != nil && state.Name == current {
+		done += int64(phaseWeight(migration, current)) * int64(min(max(state.Progress, 0), 100)) / 100
+	}
+	if total == 0 {
+		return
+	}
+
+	progress := int32(done * 100 / total)
+	if progress > migration.Status.OverallProgress {
+		migration.Status.OverallProgress = progress
+	}
 }
 
 // InitiateRollback initiates a rollback
@@ -167,6 +344,7 @@ func (s *StateMachine) InitiateRollback(ctx context.Context, migration *migratio
 
 	// Update phase to rolling back
 	migration.Status.Phase = migrationv1alpha1.PhaseRollingBack
+	migration.Status.OverallProgress = 0
 
 	// Iterate through completed phases in reverse order
 	for i := len(migration.Status.PhaseHistory) - 1; i >= 0; i-- {
@@ -203,23 +381,10 @@ func (s *StateMachine) InitiateRollback(ctx context.Context, migration *migratio
 
 	// Re-enable CVO as final step in rollback
 	logger.Info("Re-enabling CVO as final rollback step")
-	kubeClient := s.phaseExecutor.GetKubeClient()
-	deployment, err := kubeClient.AppsV1().
-		Deployments("openshift-cluster-version").
-		Get(ctx, "cluster-version-operator", metav1.GetOptions{})
-	if err != nil {
-		logger.Error(err, "Failed to get CVO deployment during rollback")
-	} else if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0 {
-		replicas := int32(1)
-		deployment.Spec.Replicas = &replicas
-		_, err = kubeClient.AppsV1().
-			Deployments("openshift-cluster-version").
-			Update(ctx, deployment, metav1.UpdateOptions{})
-		if err != nil {
-			logger.Error(err, "Failed to re-enable CVO during rollback")
-		} else {
-			logger.Info("Successfully re-enabled CVO in rollback")
-		}
+	if replicas, err := s.phaseExecutor.EnableCVO(ctx, migration); err != nil {
+		logger.Error(err, "Failed to re-enable CVO during rollback")
+	} else if replicas > 0 {
+		logger.Info("Successfully re-enabled CVO in rollback", "replicas", replicas)
 	}
 
 	// Update phase to rollback completed
@@ -237,8 +402,72 @@ func (s *StateMachine) InitiateRollback(ctx context.Context, migration *migratio
 	return nil
 }
 
-// MarkPhaseForApproval marks a phase as requiring approval
+// CancelMigration stops a migration for an operator to take over. Unlike a rollback no phase is
+// reverted, so nothing that was migrated is moved back: only leftover dummy VMs are cleaned up and
+// the workloads of volumes that were not touched yet restored. What was and was not done is
+// recorded in the status before the migration ends in PhaseCancelled.
+func (s *StateMachine) CancelMigration(ctx context.Context, migration *migrationv1alpha1.VmwareCloudFoundationMigration) {
+	logger := klog.FromContext(ctx)
+
+	cancelledPhase := migration.Status.Phase
+	if failed := s.FailedPhase(migration); failed != migrationv1alpha1.PhaseNone {
+		cancelledPhase = failed
+	}
+	logger.Info("Cancelling migration", "phase", cancelledPhase)
+
+	cancellation := &migrationv1alpha1.CancellationStatus{
+		CancelledPhase:  cancelledPhase,
+		CancelTime:      metav1.Now(),
+		RemainingPhases: s.remainingPhases(migration),
+	}
+	s.phaseExecutor.CleanupCancelledMigration(ctx, migration, cancellation)
+
+	// Verify re-enables the CVO; a migration cancelled between DisableCVO and Verify leaves it
+	// scaled down for the operator to re-enable
+	if !slices.Contains(cancellation.RemainingPhases, migrationv1alpha1.PhaseDisableCVO) &&
+		slices.Contains(cancellation.RemainingPhases, migrationv1alpha1.PhaseVerify) {
+		cancellation.ManualIntervention = append(cancellation.ManualIntervention,
+			"the cluster-version-operator is still scaled down, scale it back up once the cluster configuration is consistent")
+	}
+
+	migration.Status.Cancellation = cancellation
+	migration.Status.Phase = migrationv1alpha1.PhaseCancelled
+	migration.Status.CompletionTime = &cancellation.CancelTime
+
+	message := fmt.Sprintf("Migration cancelled in phase %s, %d phase(s) not run, %d workload(s) restored, %d item(s) need manual intervention",
+		cancelledPhase, len(cancellation.RemainingPhases), len(cancellation.RestoredWorkloads), len(cancellation.ManualIntervention))
+	logger.Info(message,
+		"remainingPhases", cancellation.RemainingPhases,
+		"deletedDummyVMs", cancellation.DeletedDummyVMs,
+		"settledMachineSets", cancellation.SettledMachineSets,
+		"manualIntervention", cancellation.ManualIntervention)
+	s.notify(notify.EventCancelled, migration, cancelledPhase, migrationv1alpha1.PhaseStatusSkipped, message)
+}
+
+// remainingPhases returns the phases, in order, that have not completed or been skipped
+func (s *StateMachine) remainingPhases(migration *migrationv1alpha1.VmwareCloudFoundationMigration) []migrationv1alpha1.MigrationPhase {
+	done := make(map[migrationv1alpha1.MigrationPhase]bool)
+	for _, entry := range migration.Status.PhaseHistory {
+		if entry.Status == migrationv1alpha1.PhaseStatusCompleted || entry.Status == migrationv1alpha1.PhaseStatusSkipped {
+			done[entry.Phase] = true
+		}
+	}
+
+	var remaining []migrationv1alpha1.MigrationPhase
+	for _, phase := range s.phaseOrder {
+		if !done[phase] {
+			remaining = append(remaining, phase)
+		}
+	}
+	return remaining
+}
+
+// MarkPhaseForApproval marks a phase as requiring approval, notifying when the phase first
+// reaches its approval gate
 func (s *StateMachine) MarkPhaseForApproval(migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase, message string) {
+	current := migration.Status.CurrentPhaseState
+	waiting := current != nil && current.Name == phase && current.RequiresApproval && !current.Approved
+
 	phaseState := &migrationv1alpha1.PhaseState{
 		Name:             phase,
 		Status:           migrationv1alpha1.PhaseStatusPending,
@@ -248,6 +477,10 @@ func (s *StateMachine) MarkPhaseForApproval(migration *migrationv1alpha1.VmwareC
 		Approved:         false,
 	}
 	migration.Status.CurrentPhaseState = phaseState
+
+	if !waiting {
+		s.notify(notify.EventApprovalRequired, migration, phase, migrationv1alpha1.PhaseStatusPending, message)
+	}
 }
 
 // ApprovePhase approves a phase for execution
@@ -276,6 +509,28 @@ func (s *StateMachine) UpdatePhaseProgress(migration *migrationv1alpha1.VmwareCl
 	}
 }
 
+// SetRequeueInterval sets how often running migrations are reconciled when neither their phase
+// nor their spec asks for another delay. Non-positive intervals are ignored.
+func (s *StateMachine) SetRequeueInterval(interval time.Duration) {
+	if interval > 0 {
+		s.requeueInterval = interval
+	}
+}
+
+// SetNotifier sets where notifications of migration transitions are sent. A nil notifier
+// disables them.
+func (s *StateMachine) SetNotifier(notifier notify.Notifier) {
+	s.notifier = notifier
+}
+
+// notify sends a notification of a migration transition when a notifier is set
+func (s *StateMachine) notify(event notify.Event, migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase, status migrationv1alpha1.PhaseStatus, message string) {
+	if s.notifier == nil {
+		return
+	}
+	s.notifier.Notify(notify.NewNotification(event, migration, phase, status, message))
+}
+
 // ShouldRequeue determines if the migration should be requeued
 func (s *StateMachine) ShouldRequeue(migration *migrationv1alpha1.VmwareCloudFoundationMigration, result *phases.PhaseResult) (bool, time.Duration) {
 	// Requeue if phase wants to be requeued
@@ -294,8 +549,17 @@ func (s *StateMachine) ShouldRequeue(migration *migrationv1alpha1.VmwareCloudFou
 	if migration.Spec.State == migrationv1alpha1.MigrationStateRunning &&
 		migration.Status.Phase != migrationv1alpha1.PhaseCompleted &&
 		migration.Status.Phase != migrationv1alpha1.PhaseFailed {
-		return true, 10 * time.Second
+		return true, s.RequeueInterval(migration)
 	}
 
 	return false, 0
 }
+
+// RequeueInterval returns how often the migration is reconciled while it waits, from its spec or
+// else the controller's interval
+func (s *StateMachine) RequeueInterval(migration *migrationv1alpha1.VmwareCloudFoundationMigration) time.Duration {
+	if migration.Spec.RequeueInterval != nil && migration.Spec.RequeueInterval.Duration > 0 {
+		return migration.Spec.RequeueInterval.Duration
+	}
+	return s.requeueInterval
+}
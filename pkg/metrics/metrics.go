@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+// NewRegistry creates the registry served on /metrics, holding the Go runtime and process
+// metrics and the vCenter API call metrics
+func NewRegistry() (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if err := vsphere.RegisterMetrics(registry); err != nil {
+		return nil, fmt.Errorf("failed to register vCenter API metrics: %w", err)
+	}
+	return registry, nil
+}
+
+// Handler serves /metrics from the registry
+func Handler(registry *prometheus.Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+	return mux
+}
+
+// Serve runs the metrics server on addr until the context is cancelled
+func Serve(ctx context.Context, addr string, registry *prometheus.Registry) error {
+	logger := klog.FromContext(ctx)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(registry),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "Failed to shut down metrics server")
+		}
+	}()
+
+	logger.Info("Starting metrics server", "address", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
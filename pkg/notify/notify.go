@@ -0,0 +1,84 @@
+// Package notify delivers notifications of migration transitions, such as a migration starting,
+// failing or waiting for approval, to systems outside the cluster like chat or paging webhooks.
+package notify
+
+import (
+	"time"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+)
+
+// Event is the kind of transition a notification reports
+type Event string
+
+const (
+	// EventStarted is sent when the first phase of a migration starts executing
+	EventStarted Event = "MigrationStarted"
+
+	// EventPhaseCompleted is sent when a phase completes and the migration moves on
+	EventPhaseCompleted Event = "PhaseCompleted"
+
+	// EventApprovalRequired is sent when a phase stops at its approval gate
+	EventApprovalRequired Event = "ApprovalRequired"
+
+	// EventPausedForReview is sent when a migration pauses for review after a phase
+	EventPausedForReview Event = "PausedForReview"
+
+	// EventCompleted is sent when all phases of a migration completed
+	EventCompleted Event = "MigrationCompleted"
+
+	// EventFailed is sent when a phase fails and stops the migration
+	EventFailed Event = "MigrationFailed"
+
+	// EventCancelled is sent when a migration is cancelled
+	EventCancelled Event = "MigrationCancelled"
+)
+
+// VolumeSummary counts the CSI volumes of a migration by outcome
+type VolumeSummary struct {
+	Total    int32 `json:"total"`
+	Migrated int32 `json:"migrated"`
+	Failed   int32 `json:"failed"`
+	Skipped  int32 `json:"skipped"`
+}
+
+// Notification is the payload sent for a migration transition
+type Notification struct {
+	Event     Event          `json:"event"`
+	Migration string         `json:"migration"`
+	Namespace string         `json:"namespace"`
+	Phase     string         `json:"phase"`
+	Status    string         `json:"status,omitempty"`
+	Message   string         `json:"message,omitempty"`
+	Time      time.Time      `json:"time"`
+	Volumes   *VolumeSummary `json:"volumes,omitempty"`
+}
+
+// Notifier delivers notifications. Delivery is best-effort: Notify must not block the caller
+// and failures are only logged, so an unavailable backend never stalls a migration.
+type Notifier interface {
+	Notify(notification Notification)
+}
+
+// NewNotification builds the notification of an event in the given phase of a migration,
+// including its CSI volume counts once volume migration has started
+func NewNotification(event Event, migration *migrationv1alpha1.VmwareCloudFoundationMigration, phase migrationv1alpha1.MigrationPhase, status migrationv1alpha1.PhaseStatus, message string) Notification {
+	notification := Notification{
+		Event:     event,
+		Migration: migration.Name,
+		Namespace: migration.Namespace,
+		Phase:     string(phase),
+		Status:    string(status),
+		Message:   message,
+		Time:      time.Now().UTC(),
+	}
+	if csi := migration.Status.CSIVolumeMigration; csi != nil {
+		notification.Volumes = &VolumeSummary{
+			Total:    csi.TotalVolumes,
+			Migrated: csi.MigratedVolumes,
+			Failed:   csi.FailedVolumes,
+			Skipped:  csi.SkippedVolumes,
+		}
+	}
+	return notification
+}
@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultWebhookTimeout bounds each POST to a notification webhook
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// defaultWebhookRetryDelay is how long a failed POST waits before its single retry
+	defaultWebhookRetryDelay = 5 * time.Second
+)
+
+// WebhookNotifier POSTs each notification as JSON to a webhook URL
+type WebhookNotifier struct {
+	url        string
+	client     *http.Client
+	retryDelay time.Duration
+}
+
+// NewWebhookNotifier creates a notifier posting to the given http or https URL
+func NewWebhookNotifier(webhookURL string) (*WebhookNotifier, error) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification webhook URL: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid notification webhook URL %q: must be an http or https URL", parsed.Redacted())
+	}
+
+	return &WebhookNotifier{
+		url: webhookURL,
+		client: &http.Client{
+			Timeout: DefaultWebhookTimeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+			},
+		},
+		retryDelay: defaultWebhookRetryDelay,
+	}, nil
+}
+
+// SetRetryDelay sets how long a failed POST waits before it is retried
+func (w *WebhookNotifier) SetRetryDelay(delay time.Duration) {
+	w.retryDelay = delay
+}
+
+// Notify sends the notification in the background, logging it if delivery fails
+func (w *WebhookNotifier) Notify(notification Notification) {
+	go func() {
+		if err := w.Send(context.Background(), notification); err != nil {
+			klog.Background().Error(err, "Failed to deliver migration notification",
+				"event", notification.Event,
+				"migration", notification.Migration,
+				"phase", notification.Phase)
+		}
+	}()
+}
+
+// Send POSTs the notification, retrying once after a failure. Any 2xx response is a success.
+func (w *WebhookNotifier) Send(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	err = w.post(ctx, body)
+	if err == nil {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return err
+	case <-time.After(w.retryDelay):
+	}
+	if retryErr := w.post(ctx, body); retryErr != nil {
+		return fmt.Errorf("notification webhook failed after retry: %w", retryErr)
+	}
+	return nil
+}
+
+func (w *WebhookNotifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// Webhook URLs often embed a token, so the URL is left out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package openshift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+)
+
+const (
+	// DefaultUsernameKeyTemplate names the key holding the username of a vCenter
+	DefaultUsernameKeyTemplate = "{{.Server}}.username"
+
+	// DefaultPasswordKeyTemplate names the key holding the password of a vCenter
+	DefaultPasswordKeyTemplate = "{{.Server}}.password"
+
+	// credentialCommandTimeout bounds a run of an external credential command
+	credentialCommandTimeout = 30 * time.Second
+)
+
+// CredentialProvider looks up the credentials of a vCenter
+type CredentialProvider interface {
+	// GetCredentials returns the username and password for a vCenter server
+	GetCredentials(ctx context.Context, server string) (username, password string, err error)
+}
+
+// CredentialKeys renders the names of the keys holding the credentials of a vCenter
+type CredentialKeys struct {
+	usernameTemplate *template.Template
+	passwordTemplate *template.Template
+}
+
+// NewCredentialKeys parses the key naming templates of a migration. Unset templates default to
+// {{.Server}}.username and {{.Server}}.password.
+func NewCredentialKeys(format *migrationv1alpha1.CredentialKeyFormat) (*CredentialKeys, error) {
+	usernameKey, passwordKey := DefaultUsernameKeyTemplate, DefaultPasswordKeyTemplate
+	if format != nil {
+		if format.Username != "" {
+			usernameKey = format.Username
+		}
+		if format.Password != "" {
+			passwordKey = format.Password
+		}
+	}
+
+	usernameTemplate, err := template.New("username").Option("missingkey=error").Parse(usernameKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid username key template %q: %w", usernameKey, err)
+	}
+	passwordTemplate, err := template.New("password").Option("missingkey=error").Parse(passwordKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid password key template %q: %w", passwordKey, err)
+	}
+	return &CredentialKeys{usernameTemplate: usernameTemplate, passwordTemplate: passwordTemplate}, nil
+}
+
+// For returns the username and password keys of a vCenter server
+func (k *CredentialKeys) For(server string) (usernameKey, passwordKey string, err error) {
+	params := struct{ Server string }{Server: server}
+
+	var username, password bytes.Buffer
+	if err := k.usernameTemplate.Execute(&username, params); err != nil {
+		return "", "", fmt.Errorf("failed to render username key for server %s: %w", server, err)
+	}
+	if err := k.passwordTemplate.Execute(&password, params); err != nil {
+		return "", "", fmt.Errorf("failed to render password key for server %s: %w", server, err)
+	}
+	if username.Len() == 0 || password.Len() == 0 {
+		return "", "", fmt.Errorf("credential key templates rendered an empty key for server %s", server)
+	}
+	return username.String(), password.String(), nil
+}
+
+// SecretCredentialProvider reads credentials from the keys of a Kubernetes Secret
+type SecretCredentialProvider struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	keys      *CredentialKeys
+}
+
+// NewSecretCredentialProvider creates a provider reading the named Secret
+func NewSecretCredentialProvider(client kubernetes.Interface, namespace, name string, keys *CredentialKeys) *SecretCredentialProvider {
+	return &SecretCredentialProvider{client: client, namespace: namespace, name: name, keys: keys}
+}
+
+// GetCredentials returns the credentials of a vCenter from the Secret
+func (p *SecretCredentialProvider) GetCredentials(ctx context.Context, server string) (string, string, error) {
+	usernameKey, passwordKey, err := p.keys.For(server)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := p.client.CoreV1().Secrets(p.namespace).Get(ctx, p.name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get secret %s/%s: %w", p.namespace, p.name, err)
+	}
+
+	usernameBytes, ok := secret.Data[usernameKey]
+	if !ok {
+		return "", "", fmt.Errorf("username not found for server %s in secret %s/%s (expected key: %s)", server, p.namespace, p.name, usernameKey)
+	}
+	passwordBytes, ok := secret.Data[passwordKey]
+	if !ok {
+		return "", "", fmt.Errorf("password not found for server %s in secret %s/%s (expected key: %s)", server, p.namespace, p.name, passwordKey)
+	}
+	return string(usernameBytes), string(passwordBytes), nil
+}
+
+// FileCredentialProvider reads credentials from one file per key in a directory, such as a
+// Secrets Store CSI driver mount or files rendered by a Vault agent
+type FileCredentialProvider struct {
+	dir  string
+	keys *CredentialKeys
+}
+
+// NewFileCredentialProvider creates a provider reading the files in dir
+func NewFileCredentialProvider(dir string, keys *CredentialKeys) *FileCredentialProvider {
+	return &FileCredentialProvider{dir: dir, keys: keys}
+}
+
+// GetCredentials returns the credentials of a vCenter from the files named after its keys.
+// Trailing newlines are stripped, as most tools writing secrets to files add one.
+func (p *FileCredentialProvider) GetCredentials(ctx context.Context, server string) (string, string, error) {
+	usernameKey, passwordKey, err := p.keys.For(server)
+	if err != nil {
+		return "", "", err
+	}
+
+	username, err := p.readKey(usernameKey)
+	if err != nil {
+		return "", "", fmt.Errorf("username not found for server %s: %w", server, err)
+	}
+	password, err := p.readKey(passwordKey)
+	if err != nil {
+		return "", "", fmt.Errorf("password not found for server %s: %w", server, err)
+	}
+	return username, password, nil
+}
+
+// readKey reads the file of a key, refusing keys that would escape the directory
+func (p *FileCredentialProvider) readKey(key string) (string, error) {
+	if key != filepath.Base(key) || key == "." || key == ".." {
+		return "", fmt.Errorf("key %q is not a valid file name", key)
+	}
+	data, err := os.ReadFile(filepath.Join(p.dir, key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// CommandCredentialProvider runs an external command to look up credentials. The command is
+// given the vCenter server as its only argument and must print a JSON object with "username"
+// and "password" fields.
+type CommandCredentialProvider struct {
+	command string
+}
+
+// NewCommandCredentialProvider creates a provider running the executable at command
+func NewCommandCredentialProvider(command string) *CommandCredentialProvider {
+	return &CommandCredentialProvider{command: command}
+}
+
+// GetCredentials runs the command for a vCenter and parses the credentials it prints. The
+// output is never included in errors since it holds the password.
+func (p *CommandCredentialProvider) GetCredentials(ctx context.Context, server string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialCommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.command, server)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("credential command %s failed for server %s: %w: %s", p.command, server, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var creds struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return "", "", fmt.Errorf("credential command %s printed invalid JSON for server %s", p.command, server)
+	}
+	if creds.Username == "" || creds.Password == "" {
+		return "", "", fmt.Errorf("credential command %s returned no username or password for server %s", p.command, server)
+	}
+	return creds.Username, creds.Password, nil
+}
@@ -0,0 +1,277 @@
+package openshift
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// CSIDriverNamespace is the namespace of the vSphere CSI driver
+	CSIDriverNamespace = "openshift-cluster-csi-drivers"
+
+	// CSIDriverConfigSecretName is the secret holding the vSphere CSI driver configuration
+	CSIDriverConfigSecretName = "vsphere-csi-config-secret"
+
+	// CSIDriverConfigKey is the key of the INI configuration in the config secret
+	CSIDriverConfigKey = "cloud.conf"
+
+	// CSIDriverCredentialsSecretName is the secret holding the vCenter credentials of the CSI driver
+	CSIDriverCredentialsSecretName = "vmware-vsphere-cloud-credentials"
+
+	// csiControllerApp is the app label of the vSphere CSI driver controller pods
+	csiControllerApp = "vmware-vsphere-csi-driver-controller"
+)
+
+// CSIVCenter is a vCenter the vSphere CSI driver provisions volumes on
+type CSIVCenter struct {
+	Server      string
+	Datacenters []string
+	Username    string
+	Password    string
+}
+
+// CSIDriverConfigManager manages the configuration of the vSphere CSI driver
+type CSIDriverConfigManager struct {
+	client kubernetes.Interface
+}
+
+// NewCSIDriverConfigManager creates a new CSI driver config manager
+func NewCSIDriverConfigManager(client kubernetes.Interface) *CSIDriverConfigManager {
+	return &CSIDriverConfigManager{client: client}
+}
+
+// SetVCenters points the vSphere CSI driver at exactly the given vCenters: the VirtualCenter
+// sections of its configuration and the keys of its credentials secret are replaced, while the
+// Global section and every other setting are kept. A configuration that already lists exactly
+// these vCenters, such as one the CSI driver operator regenerated from the Infrastructure, and
+// credentials that already match are left to their operators. It reports whether either secret
+// was written, which is when the CSI controller has to restart to load them.
+func (m *CSIDriverConfigManager) SetVCenters(ctx context.Context, vcenters []CSIVCenter) (bool, error) {
+	logger := klog.FromContext(ctx)
+
+	secret, err := m.client.CoreV1().Secrets(CSIDriverNamespace).Get(ctx, CSIDriverConfigSecretName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get CSI driver config secret: %w", err)
+	}
+	if len(secret.Data[CSIDriverConfigKey]) == 0 {
+		return false, fmt.Errorf("CSI driver config secret has no %s key", CSIDriverConfigKey)
+	}
+	changed := false
+	current := slices.Sorted(slices.Values(configVCenters(string(secret.Data[CSIDriverConfigKey]))))
+	if !slices.Equal(current, slices.Sorted(slices.Values(csiVCenterServers(vcenters)))) {
+		config, err := SetCSIConfigVCenters(string(secret.Data[CSIDriverConfigKey]), vcenters)
+		if err != nil {
+			return false, err
+		}
+		secret.Data[CSIDriverConfigKey] = []byte(config)
+		if _, err := m.client.CoreV1().Secrets(CSIDriverNamespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return false, fmt.Errorf("failed to update CSI driver config secret: %w", err)
+		}
+		logger.Info("Updated vSphere CSI driver config", "vcenters", csiVCenterServers(vcenters))
+		changed = true
+	}
+
+	creds, err := m.client.CoreV1().Secrets(CSIDriverNamespace).Get(ctx, CSIDriverCredentialsSecretName, metav1.GetOptions{})
+	if err != nil {
+		return changed, fmt.Errorf("failed to get CSI driver credentials secret: %w", err)
+	}
+	data := make(map[string][]byte, 2*len(vcenters))
+	for key, value := range creds.Data {
+		if !strings.HasSuffix(key, ".username") && !strings.HasSuffix(key, ".password") {
+			data[key] = value
+		}
+	}
+	for _, vc := range vcenters {
+		data[vc.Server+".username"] = []byte(vc.Username)
+		data[vc.Server+".password"] = []byte(vc.Password)
+	}
+	if maps.EqualFunc(data, creds.Data, bytes.Equal) {
+		return changed, nil
+	}
+	creds.Data = data
+	if _, err := m.client.CoreV1().Secrets(CSIDriverNamespace).Update(ctx, creds, metav1.UpdateOptions{}); err != nil {
+		return changed, fmt.Errorf("failed to update CSI driver credentials secret: %w", err)
+	}
+	logger.Info("Updated vSphere CSI driver credentials", "vcenters", csiVCenterServers(vcenters))
+
+	return true, nil
+}
+
+// ConfiguredVCenters returns the vCenter servers in the vSphere CSI driver configuration
+func (m *CSIDriverConfigManager) ConfiguredVCenters(ctx context.Context) ([]string, error) {
+	secret, err := m.client.CoreV1().Secrets(CSIDriverNamespace).Get(ctx, CSIDriverConfigSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSI driver config secret: %w", err)
+	}
+	return configVCenters(string(secret.Data[CSIDriverConfigKey])), nil
+}
+
+// configVCenters returns the vCenter servers of the VirtualCenter sections of an INI configuration
+func configVCenters(config string) []string {
+	var servers []string
+	for _, section := range parseINISections(config) {
+		if server, ok := vCenterSectionServer(section.header); ok {
+			servers = append(servers, server)
+		}
+	}
+	return servers
+}
+
+// ControllerRestartedSince reports whether every vSphere CSI driver controller pod was started
+// after since and is Ready, which is when the controller has loaded configuration written
+// before since. The reason describes what is still pending.
+func (m *CSIDriverConfigManager) ControllerRestartedSince(ctx context.Context, since time.Time) (bool, string, error) {
+	pods, err := m.client.CoreV1().Pods(CSIDriverNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{"app": csiControllerApp}).String(),
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to list CSI driver controller pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return false, "no CSI driver controller pods are running", nil
+	}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.CreationTimestamp.Time.Before(since) {
+			return false, fmt.Sprintf("CSI driver controller pod %s predates the config update", pod.Name), nil
+		}
+		if !isPodReady(pod) {
+			return false, fmt.Sprintf("CSI driver controller pod %s is not ready", pod.Name), nil
+		}
+	}
+	return true, "", nil
+}
+
+// SetCSIConfigVCenters rewrites the VirtualCenter sections of a vSphere CSI driver INI
+// configuration to the given vCenters. A vCenter already configured keeps its other settings;
+// a new one copies those of the first existing section, except its datacenters and the in-tree
+// migration datastore, which belong to the old vCenter.
+func SetCSIConfigVCenters(config string, vcenters []CSIVCenter) (string, error) {
+	if len(vcenters) == 0 {
+		return "", fmt.Errorf("no vCenters given for the CSI driver config")
+	}
+
+	sections := parseINISections(config)
+	existing := make(map[string]iniSection)
+	var base *iniSection
+	var kept []iniSection
+	for i, section := range sections {
+		server, ok := vCenterSectionServer(section.header)
+		if !ok {
+			kept = append(kept, section)
+			continue
+		}
+		existing[server] = section
+		if base == nil {
+			base = &sections[i]
+		}
+	}
+
+	for _, vc := range vcenters {
+		section, ok := existing[vc.Server]
+		if !ok {
+			section = iniSection{header: fmt.Sprintf("VirtualCenter %q", vc.Server)}
+			if base != nil {
+				for _, line := range base.lines {
+					if key := iniKey(line); key != "datacenters" && key != "migration-datastore-url" {
+						section.lines = append(section.lines, line)
+					}
+				}
+			} else {
+				section.lines = []string{`insecure-flag = "true"`}
+			}
+		}
+		section.lines = slices.DeleteFunc(slices.Clone(section.lines), func(line string) bool {
+			return iniKey(line) == "datacenters"
+		})
+		section.lines = append(section.lines, fmt.Sprintf("datacenters = %q", strings.Join(vc.Datacenters, ",")))
+		kept = append(kept, section)
+	}
+
+	return renderINISections(kept), nil
+}
+
+// iniSection is a section of an INI file. The lines before the first section header have an
+// empty header.
+type iniSection struct {
+	header string
+	lines  []string
+}
+
+// parseINISections splits an INI file into its sections, dropping blank lines
+func parseINISections(config string) []iniSection {
+	var sections []iniSection
+	current := iniSection{}
+	for _, line := range strings.Split(config, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			if current.header != "" || len(current.lines) > 0 {
+				sections = append(sections, current)
+			}
+			current = iniSection{header: strings.TrimSpace(trimmed[1 : len(trimmed)-1])}
+			continue
+		}
+		current.lines = append(current.lines, trimmed)
+	}
+	if current.header != "" || len(current.lines) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+// renderINISections writes sections back as an INI file, separated by blank lines
+func renderINISections(sections []iniSection) string {
+	var b strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if section.header != "" {
+			fmt.Fprintf(&b, "[%s]\n", section.header)
+		}
+		for _, line := range section.lines {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// vCenterSectionServer returns the server of a `VirtualCenter "server"` section header
+func vCenterSectionServer(header string) (string, bool) {
+	name, ok := strings.CutPrefix(header, "VirtualCenter")
+	if !ok {
+		return "", false
+	}
+	server := strings.Trim(strings.TrimSpace(name), `"`)
+	return server, server != ""
+}
+
+// iniKey returns the lower-cased key of a key = value line
+func iniKey(line string) string {
+	key, _, _ := strings.Cut(line, "=")
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// csiVCenterServers lists the servers of vCenters for logging
+func csiVCenterServers(vcenters []CSIVCenter) []string {
+	servers := make([]string, 0, len(vcenters))
+	for _, vc := range vcenters {
+		servers = append(servers, vc.Server)
+	}
+	return servers
+}
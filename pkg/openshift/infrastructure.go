@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 
 	configv1 "github.com/openshift/api/config/v1"
 	configclient "github.com/openshift/client-go/config/clientset/versioned"
@@ -14,10 +16,14 @@ import (
 	"k8s.io/klog/v2"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 const (
 	InfrastructureName = "cluster"
+
+	// InfrastructureCRDName is the name of the Infrastructure CustomResourceDefinition
+	InfrastructureCRDName = "infrastructures.config.openshift.io"
 )
 
 // InfrastructureManager manages Infrastructure CRD operations
@@ -46,9 +52,10 @@ func (m *InfrastructureManager) Get(ctx context.Context) (*configv1.Infrastructu
 	return m.client.ConfigV1().Infrastructures().Get(ctx, InfrastructureName, metav1.GetOptions{})
 }
 
-// GetSourceVCenter returns the source vCenter from the Infrastructure CRD
+// GetSourceVCenter returns the source vCenter from the Infrastructure CRD. When server is set
+// it names the source, otherwise the first vCenter is the source.
 // The source vCenter is the first vCenter in the infrastructure spec
-func (m *InfrastructureManager) GetSourceVCenter(ctx context.Context) (*configv1.VSpherePlatformVCenterSpec, error) {
+func (m *InfrastructureManager) GetSourceVCenter(ctx context.Context, server string) (*configv1.VSpherePlatformVCenterSpec, error) {
 	infra, err := m.Get(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get infrastructure: %w", err)
@@ -62,8 +69,20 @@ func (m *InfrastructureManager) GetSourceVCenter(ctx context.Context) (*configv1
 		return nil, fmt.Errorf("no vCenters configured in infrastructure")
 	}
 
-	// The first vCenter is the source
-	return &infra.Spec.PlatformSpec.VSphere.VCenters[0], nil
+	vCenters := infra.Spec.PlatformSpec.VSphere.VCenters
+	if server == "" {
+		return &vCenters[0], nil
+	}
+
+	configured := make([]string, 0, len(vCenters))
+	for i := range vCenters {
+		if vCenters[i].Server == server {
+			return &vCenters[i], nil
+		}
+		configured = append(configured, vCenters[i].Server)
+	}
+	return nil, fmt.Errorf("source vCenter %s is not configured in infrastructure (configured: %s)",
+		server, strings.Join(configured, ", "))
 }
 
 // AddTargetVCenter adds the target vCenter to the infrastructure spec
@@ -115,7 +134,8 @@ func (m *InfrastructureManager) AddTargetVCenter(ctx context.Context, infra *con
 		}
 	}
 
-	// Add failure domains
+	// Add failure domains, replacing any with the same name so a re-run after a partial update
+	// does not leave duplicate entries behind
 	for _, fd := range migration.Spec.FailureDomains {
 		failureDomain := configv1.VSpherePlatformFailureDomainSpec{
 			Name:   fd.Name,
@@ -132,6 +152,15 @@ func (m *InfrastructureManager) AddTargetVCenter(ctx context.Context, infra *con
 				Folder:         fd.Topology.Folder,
 			},
 		}
+
+		existing := slices.IndexFunc(infra.Spec.PlatformSpec.VSphere.FailureDomains, func(f configv1.VSpherePlatformFailureDomainSpec) bool {
+			return f.Name == fd.Name
+		})
+		if existing >= 0 {
+			logger.Info("Failure domain already exists in infrastructure, updating it", "name", fd.Name)
+			infra.Spec.PlatformSpec.VSphere.FailureDomains[existing] = failureDomain
+			continue
+		}
 		infra.Spec.PlatformSpec.VSphere.FailureDomains = append(infra.Spec.PlatformSpec.VSphere.FailureDomains, failureDomain)
 	}
 
@@ -202,8 +231,9 @@ func (m *InfrastructureManager) GetInfrastructureID(ctx context.Context) (string
 	return infra.Status.InfrastructureName, nil
 }
 
-// GetSourceFailureDomain returns the first (source) failure domain from the Infrastructure CRD
-func (m *InfrastructureManager) GetSourceFailureDomain(ctx context.Context) (*configv1.VSpherePlatformFailureDomainSpec, error) {
+// GetSourceFailureDomain returns the source failure domain from the Infrastructure CRD. When
+// server is set it is the first failure domain on that vCenter, otherwise the first failure domain.
+func (m *InfrastructureManager) GetSourceFailureDomain(ctx context.Context, server string) (*configv1.VSpherePlatformFailureDomainSpec, error) {
 	infra, err := m.Get(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get infrastructure: %w", err)
@@ -217,8 +247,14 @@ func (m *InfrastructureManager) GetSourceFailureDomain(ctx context.Context) (*co
 		return nil, fmt.Errorf("no failure domains configured in infrastructure")
 	}
 
-	// The first failure domain is the source
-	return &infra.Spec.PlatformSpec.VSphere.FailureDomains[0], nil
+	for _, fd := range infra.Spec.PlatformSpec.VSphere.FailureDomains {
+		if server != "" && fd.Server != server {
+			continue
+		}
+		fd.Topology = util.NormalizeTopology(fd.Topology)
+		return &fd, nil
+	}
+	return nil, fmt.Errorf("no failure domain for source vCenter %s configured in infrastructure", server)
 }
 
 // BackupInfrastructureCRD backs up the Infrastructure CRD definition
@@ -228,7 +264,7 @@ func (m *InfrastructureManager) BackupInfrastructureCRD(ctx context.Context) ([]
 	}
 
 	logger := klog.FromContext(ctx)
-	crdName := "infrastructures.config.openshift.io"
+	crdName := InfrastructureCRDName
 
 	logger.Info("Backing up Infrastructure CRD", "crd", crdName)
 
@@ -255,7 +291,7 @@ func (m *InfrastructureManager) ModifyInfrastructureCRDToAllowVCenterChanges(ctx
 	}
 
 	logger := klog.FromContext(ctx)
-	crdName := "infrastructures.config.openshift.io"
+	crdName := InfrastructureCRDName
 
 	logger.Info("Modifying Infrastructure CRD to allow vCenter changes", "crd", crdName)
 
@@ -313,7 +349,7 @@ func (m *InfrastructureManager) RestoreInfrastructureCRD(ctx context.Context, ba
 	}
 
 	logger := klog.FromContext(ctx)
-	crdName := "infrastructures.config.openshift.io"
+	crdName := InfrastructureCRDName
 
 	logger.Info("Restoring Infrastructure CRD from backup", "crd", crdName)
 
@@ -333,6 +369,116 @@ func (m *InfrastructureManager) RestoreInfrastructureCRD(ctx context.Context, ba
 	return nil
 }
 
+// vcentersProperties returns the properties of the vsphere platform spec in a version of the
+// Infrastructure CRD, which hold the vcenters field, or nil if the version has none
+func vcentersProperties(version *apiextensionsv1.CustomResourceDefinitionVersion) map[string]apiextensionsv1.JSONSchemaProps {
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return nil
+	}
+	spec, ok := version.Schema.OpenAPIV3Schema.Properties["spec"]
+	if !ok {
+		return nil
+	}
+	platformSpec, ok := spec.Properties["platformSpec"]
+	if !ok {
+		return nil
+	}
+	vsphere, ok := platformSpec.Properties["vsphere"]
+	if !ok {
+		return nil
+	}
+	if _, ok := vsphere.Properties["vcenters"]; !ok {
+		return nil
+	}
+	return vsphere.Properties
+}
+
+// VCenterValidations returns, per version of the Infrastructure CRD, the JSON of the
+// x-kubernetes-validations rules of the vcenters field. Versions without rules are omitted.
+func (m *InfrastructureManager) VCenterValidations(ctx context.Context) (map[string]string, error) {
+	if m.apiextensionsClient == nil {
+		return nil, fmt.Errorf("apiextensionsClient not set - use NewInfrastructureManagerWithClients")
+	}
+
+	crd, err := m.apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, InfrastructureCRDName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Infrastructure CRD: %w", err)
+	}
+
+	validations := make(map[string]string)
+	for i := range crd.Spec.Versions {
+		props := vcentersProperties(&crd.Spec.Versions[i])
+		if props == nil || len(props["vcenters"].XValidations) == 0 {
+			continue
+		}
+		rules, err := json.Marshal(props["vcenters"].XValidations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal vcenters validations: %w", err)
+		}
+		validations[crd.Spec.Versions[i].Name] = string(rules)
+	}
+	return validations, nil
+}
+
+// MissingVCenterValidations returns the versions of the Infrastructure CRD that have recorded
+// vcenters validations but whose vcenters field has none now
+func (m *InfrastructureManager) MissingVCenterValidations(ctx context.Context, recorded map[string]string) ([]string, error) {
+	current, err := m.VCenterValidations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for version := range recorded {
+		if _, ok := current[version]; !ok {
+			missing = append(missing, version)
+		}
+	}
+	slices.Sort(missing)
+	return missing, nil
+}
+
+// RestoreVCenterValidations puts the recorded x-kubernetes-validations rules back on the vcenters
+// field of each Infrastructure CRD version that has lost them, and returns the versions restored.
+// Versions whose field still has rules are left alone, since the CVO may have updated them.
+func (m *InfrastructureManager) RestoreVCenterValidations(ctx context.Context, recorded map[string]string) ([]string, error) {
+	if m.apiextensionsClient == nil {
+		return nil, fmt.Errorf("apiextensionsClient not set - use NewInfrastructureManagerWithClients")
+	}
+	logger := klog.FromContext(ctx)
+
+	crd, err := m.apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, InfrastructureCRDName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Infrastructure CRD: %w", err)
+	}
+
+	var restored []string
+	for i := range crd.Spec.Versions {
+		version := &crd.Spec.Versions[i]
+		rules, ok := recorded[version.Name]
+		props := vcentersProperties(version)
+		if !ok || props == nil || len(props["vcenters"].XValidations) > 0 {
+			continue
+		}
+
+		vcenters := props["vcenters"]
+		if err := json.Unmarshal([]byte(rules), &vcenters.XValidations); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal recorded vcenters validations of version %s: %w", version.Name, err)
+		}
+		props["vcenters"] = vcenters
+		restored = append(restored, version.Name)
+	}
+	if len(restored) == 0 {
+		return nil, nil
+	}
+
+	if _, err := m.apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to restore vcenters validations of Infrastructure CRD: %w", err)
+	}
+	logger.Info("Restored vcenters validations of Infrastructure CRD", "versions", restored)
+	return restored, nil
+}
+
 // AddTargetVCenterWithCRDModification adds the target vCenter by modifying the CRD
 // The CRD is backed up, modified, Infrastructure is updated, then CRD is immediately restored
 func (m *InfrastructureManager) AddTargetVCenterWithCRDModification(ctx context.Context, infra *configv1.Infrastructure, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*configv1.Infrastructure, error) {
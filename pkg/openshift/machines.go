@@ -3,30 +3,57 @@ package openshift
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 
 	configv1 "github.com/openshift/api/config/v1"
 	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	machineclient "github.com/openshift/client-go/machine/clientset/versioned"
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 const (
 	MachineAPINamespace = "openshift-machine-api"
+
+	// MachineRoleLabel carries the role of a Machine, e.g. master or worker
+	MachineRoleLabel = "machine.openshift.io/cluster-api-machine-role"
+
+	// MachineSetLabel names the MachineSet that owns a Machine
+	MachineSetLabel = "machine.openshift.io/cluster-api-machineset"
+
+	// FailureDomainLabel names the failure domain of a worker MachineSet, as label and annotation
+	FailureDomainLabel = "machine.openshift.io/failure-domain"
 )
 
+// reservedMachineSetKeys are the cluster-api labels identifying a MachineSet and its Machines, and
+// the failure domain the controller sets on worker MachineSets. The cluster autoscaler's
+// machine.openshift.io/cluster-api-autoscaler-* annotations are deliberately not among them.
+var reservedMachineSetKeys = map[string]bool{
+	MachineSetLabel:  true,
+	MachineRoleLabel: true,
+	"machine.openshift.io/cluster-api-machine-type": true,
+	"machine.openshift.io/cluster-api-cluster":      true,
+	FailureDomainLabel:                              true,
+}
+
 // cpmsGVR is the GroupVersionResource for ControlPlaneMachineSet
 var cpmsGVR = schema.GroupVersionResource{
 	Group:    "machine.openshift.io",
@@ -55,8 +82,146 @@ func NewMachineManagerWithClients(kubeClient kubernetes.Interface, machineClient
 	}
 }
 
-// CreateWorkerMachineSet creates a new worker MachineSet in the target vCenter
-func (m *MachineManager) CreateWorkerMachineSet(ctx context.Context, name string, migration *migrationv1alpha1.VmwareCloudFoundationMigration, template *machinev1beta1.MachineSet, infraID string) (*machinev1beta1.MachineSet, error) {
+// WorkerPlacement is the number of worker machines to create in one failure domain
+type WorkerPlacement struct {
+	FailureDomain string
+	Replicas      int32
+	// ResourcePool overrides the failure domain's resource pool, e.g. one resolved from a tag
+	ResourcePool string
+}
+
+// WorkerPlacements resolves the worker MachineSet configuration into one placement per failure domain.
+// Failure domains without their own replica count share the remainder of config.Replicas evenly,
+// with any leftover going to the first of them.
+func WorkerPlacements(config migrationv1alpha1.MachineSetConfig) ([]WorkerPlacement, error) {
+	if err := ValidateMachineSetMetadata(config); err != nil {
+		return nil, err
+	}
+	if config.SurgeReplicas < 0 {
+		return nil, fmt.Errorf("worker surge replicas must not be negative")
+	}
+
+	if len(config.FailureDomains) == 0 {
+		if config.FailureDomain == "" {
+			return nil, fmt.Errorf("worker failure domain is empty")
+		}
+		if config.Replicas <= 0 {
+			return nil, fmt.Errorf("worker replicas must be greater than 0")
+		}
+		return []WorkerPlacement{{FailureDomain: config.FailureDomain, Replicas: config.Replicas}}, nil
+	}
+
+	placements := make([]WorkerPlacement, 0, len(config.FailureDomains))
+	seen := make(map[string]bool, len(config.FailureDomains))
+	var explicit, unset int32
+	for _, fd := range config.FailureDomains {
+		if fd.Name == "" {
+			return nil, fmt.Errorf("worker failure domain name is empty")
+		}
+		if seen[fd.Name] {
+			return nil, fmt.Errorf("worker failure domain %s is listed more than once", fd.Name)
+		}
+		seen[fd.Name] = true
+
+		placement := WorkerPlacement{FailureDomain: fd.Name}
+		if fd.Replicas != nil {
+			if *fd.Replicas < 0 {
+				return nil, fmt.Errorf("worker replicas for failure domain %s must not be negative", fd.Name)
+			}
+			placement.Replicas = *fd.Replicas
+			explicit += *fd.Replicas
+		} else {
+			unset++
+		}
+		placements = append(placements, placement)
+	}
+
+	if unset > 0 {
+		remaining := config.Replicas - explicit
+		if remaining < unset {
+			return nil, fmt.Errorf("worker replicas %d leave fewer than one machine for each of the %d failure domains without a replica count",
+				config.Replicas, unset)
+		}
+		share, extra := remaining/unset, remaining%unset
+		for i := range placements {
+			if config.FailureDomains[i].Replicas != nil {
+				continue
+			}
+			placements[i].Replicas = share
+			if extra > 0 {
+				placements[i].Replicas++
+				extra--
+			}
+		}
+	} else if config.Replicas > 0 && config.Replicas != explicit {
+		return nil, fmt.Errorf("worker replicas %d do not match the sum of the per failure domain replicas %d",
+			config.Replicas, explicit)
+	}
+
+	if explicit == 0 && unset == 0 {
+		return nil, fmt.Errorf("worker replicas must be greater than 0")
+	}
+
+	return placements, nil
+}
+
+// SurgePlacements adds config.SurgeReplicas to the worker placements, spread evenly across the
+// failure domains that get workers with any leftover going to the first of them, so the new
+// MachineSets are over-provisioned while the old machines are scaled down
+func SurgePlacements(config migrationv1alpha1.MachineSetConfig) ([]WorkerPlacement, error) {
+	placements, err := WorkerPlacements(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []int
+	for i, placement := range placements {
+		if placement.Replicas > 0 {
+			targets = append(targets, i)
+		}
+	}
+	if config.SurgeReplicas == 0 || len(targets) == 0 {
+		return placements, nil
+	}
+
+	share, extra := config.SurgeReplicas/int32(len(targets)), config.SurgeReplicas%int32(len(targets))
+	for _, i := range targets {
+		placements[i].Replicas += share
+		if extra > 0 {
+			placements[i].Replicas++
+			extra--
+		}
+	}
+	return placements, nil
+}
+
+// ValidateMachineSetMetadata checks the custom labels and annotations of worker MachineSets are
+// well formed and do not collide with the cluster-api labels and failure domain the controller sets
+func ValidateMachineSetMetadata(config migrationv1alpha1.MachineSetConfig) error {
+	for _, key := range slices.Sorted(maps.Keys(config.MachineSetLabels)) {
+		if reservedMachineSetKeys[key] {
+			return fmt.Errorf("worker MachineSet label %s is reserved for the controller", key)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid worker MachineSet label key %s: %s", key, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(config.MachineSetLabels[key]); len(errs) > 0 {
+			return fmt.Errorf("invalid value for worker MachineSet label %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(config.MachineSetAnnotations)) {
+		if reservedMachineSetKeys[key] {
+			return fmt.Errorf("worker MachineSet annotation %s is reserved for the controller", key)
+		}
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			return fmt.Errorf("invalid worker MachineSet annotation key %s: %s", key, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// CreateWorkerMachineSet creates a new worker MachineSet in the target vCenter for one failure domain
+func (m *MachineManager) CreateWorkerMachineSet(ctx context.Context, name string, migration *migrationv1alpha1.VmwareCloudFoundationMigration, template *machinev1beta1.MachineSet, infraID string, placement WorkerPlacement) (*machinev1beta1.MachineSet, error) {
 	logger := klog.FromContext(ctx)
 
 	if m.machineClient == nil {
@@ -71,20 +236,26 @@ func (m *MachineManager) CreateWorkerMachineSet(ctx context.Context, name string
 	newMachineSet.CreationTimestamp = metav1.Time{}
 
 	// Update replicas
-	replicas := migration.Spec.MachineSetConfig.Replicas
+	replicas := placement.Replicas
 	newMachineSet.Spec.Replicas = &replicas
 
 	// Update failure domain in annotations
 	if newMachineSet.Annotations == nil {
 		newMachineSet.Annotations = make(map[string]string)
 	}
-	newMachineSet.Annotations["machine.openshift.io/failure-domain"] = migration.Spec.MachineSetConfig.FailureDomain
+	for key, value := range migration.Spec.MachineSetConfig.MachineSetAnnotations {
+		newMachineSet.Annotations[key] = value
+	}
+	newMachineSet.Annotations[FailureDomainLabel] = placement.FailureDomain
 
 	// Update failure domain in labels
 	if newMachineSet.Labels == nil {
 		newMachineSet.Labels = make(map[string]string)
 	}
-	newMachineSet.Labels["machine.openshift.io/failure-domain"] = migration.Spec.MachineSetConfig.FailureDomain
+	for key, value := range migration.Spec.MachineSetConfig.MachineSetLabels {
+		newMachineSet.Labels[key] = value
+	}
+	newMachineSet.Labels[FailureDomainLabel] = placement.FailureDomain
 
 	// Update selector to use new MachineSet name
 	if newMachineSet.Spec.Selector.MatchLabels == nil {
@@ -96,18 +267,21 @@ func (m *MachineManager) CreateWorkerMachineSet(ctx context.Context, name string
 	if newMachineSet.Spec.Template.ObjectMeta.Labels == nil {
 		newMachineSet.Spec.Template.ObjectMeta.Labels = make(map[string]string)
 	}
+	for key, value := range migration.Spec.MachineSetConfig.MachineSetLabels {
+		newMachineSet.Spec.Template.ObjectMeta.Labels[key] = value
+	}
 	newMachineSet.Spec.Template.ObjectMeta.Labels["machine.openshift.io/cluster-api-machineset"] = name
 
 	// Find target failure domain
 	var targetFailureDomain *configv1.VSpherePlatformFailureDomainSpec
 	for i := range migration.Spec.FailureDomains {
-		if migration.Spec.FailureDomains[i].Name == migration.Spec.MachineSetConfig.FailureDomain {
+		if migration.Spec.FailureDomains[i].Name == placement.FailureDomain {
 			targetFailureDomain = &migration.Spec.FailureDomains[i]
 			break
 		}
 	}
 	if targetFailureDomain == nil {
-		return nil, fmt.Errorf("failure domain %s not found", migration.Spec.MachineSetConfig.FailureDomain)
+		return nil, fmt.Errorf("failure domain %s not found", placement.FailureDomain)
 	}
 
 	// Validate template field is set
@@ -126,14 +300,14 @@ func (m *MachineManager) CreateWorkerMachineSet(ctx context.Context, name string
 		"datacenter", targetFailureDomain.Topology.Datacenter)
 
 	// Update providerSpec with target vCenter configuration
-	if err := updateMachineSetProviderSpec(newMachineSet, targetFailureDomain, infraID); err != nil {
+	if err := updateMachineSetProviderSpec(newMachineSet, targetFailureDomain, infraID, placement.ResourcePool); err != nil {
 		return nil, fmt.Errorf("failed to update providerSpec: %w", err)
 	}
 
 	logger.Info("Creating new worker MachineSet",
 		"name", name,
 		"replicas", replicas,
-		"failureDomain", migration.Spec.MachineSetConfig.FailureDomain,
+		"failureDomain", placement.FailureDomain,
 		"server", targetFailureDomain.Server,
 		"datacenter", targetFailureDomain.Topology.Datacenter,
 		"template", targetFailureDomain.Topology.Template)
@@ -148,11 +322,13 @@ func (m *MachineManager) CreateWorkerMachineSet(ctx context.Context, name string
 	return created, nil
 }
 
-// updateMachineSetProviderSpec updates the vSphere providerSpec with target vCenter configuration
+// updateMachineSetProviderSpec updates the vSphere providerSpec with target vCenter configuration.
+// A non-empty resourcePool replaces the failure domain's.
 func updateMachineSetProviderSpec(
 	machineSet *machinev1beta1.MachineSet,
 	failureDomain *configv1.VSpherePlatformFailureDomainSpec,
 	infraID string,
+	resourcePool string,
 ) error {
 	// Get providerSpec from MachineSet
 	providerSpecValue := machineSet.Spec.Template.Spec.ProviderSpec.Value
@@ -167,12 +343,15 @@ func updateMachineSetProviderSpec(
 	}
 
 	// Update workspace fields
+	if resourcePool == "" {
+		resourcePool = util.FailureDomainResourcePool(failureDomain.Topology)
+	}
 	workspace := map[string]interface{}{
 		"server":       failureDomain.Server,
 		"datacenter":   failureDomain.Topology.Datacenter,
 		"datastore":    failureDomain.Topology.Datastore,
-		"folder":       fmt.Sprintf("/%s/vm/%s", failureDomain.Topology.Datacenter, infraID),
-		"resourcePool": failureDomain.Topology.ResourcePool,
+		"folder":       util.FailureDomainFolder(failureDomain.Topology, infraID),
+		"resourcePool": resourcePool,
 	}
 	providerSpec["workspace"] = workspace
 
@@ -241,8 +420,8 @@ func updateCPMSProviderSpec(
 		"server":       failureDomain.Server,
 		"datacenter":   failureDomain.Topology.Datacenter,
 		"datastore":    failureDomain.Topology.Datastore,
-		"folder":       fmt.Sprintf("/%s/vm/%s", failureDomain.Topology.Datacenter, infraID),
-		"resourcePool": failureDomain.Topology.ResourcePool,
+		"folder":       util.FailureDomainFolder(failureDomain.Topology, infraID),
+		"resourcePool": util.FailureDomainResourcePool(failureDomain.Topology),
 	}
 	providerSpecValue["workspace"] = workspace
 
@@ -331,27 +510,104 @@ func (m *MachineManager) GetMachineSetsByVCenter(ctx context.Context, vcenterSer
 
 // getVCenterServerFromMachineSet extracts the vCenter server from the MachineSet's providerSpec
 func getVCenterServerFromMachineSet(ms *machinev1beta1.MachineSet) (string, error) {
-	providerSpecValue := ms.Spec.Template.Spec.ProviderSpec.Value
-	if providerSpecValue == nil || providerSpecValue.Raw == nil {
-		return "", fmt.Errorf("providerSpec.value is nil")
+	server, _, err := MachineSetWorkspace(ms)
+	return server, err
+}
+
+// MachineSetWorkspace returns the vCenter server and datacenter a MachineSet places its Machines in
+func MachineSetWorkspace(ms *machinev1beta1.MachineSet) (server, datacenter string, err error) {
+	return providerSpecWorkspace(ms.Spec.Template.Spec.ProviderSpec)
+}
+
+// MachineWorkspace returns the vCenter server and datacenter a Machine is placed in
+func MachineWorkspace(machine *machinev1beta1.Machine) (server, datacenter string, err error) {
+	return providerSpecWorkspace(machine.Spec.ProviderSpec)
+}
+
+// providerSpecWorkspace extracts the vCenter server and datacenter from a vSphere providerSpec
+func providerSpecWorkspace(spec machinev1beta1.ProviderSpec) (server, datacenter string, err error) {
+	if spec.Value == nil || spec.Value.Raw == nil {
+		return "", "", fmt.Errorf("providerSpec.value is nil")
 	}
 
 	var providerSpec map[string]interface{}
-	if err := json.Unmarshal(providerSpecValue.Raw, &providerSpec); err != nil {
-		return "", fmt.Errorf("failed to unmarshal providerSpec: %w", err)
+	if err := json.Unmarshal(spec.Value.Raw, &providerSpec); err != nil {
+		return "", "", fmt.Errorf("failed to unmarshal providerSpec: %w", err)
 	}
 
 	workspace, ok := providerSpec["workspace"].(map[string]interface{})
 	if !ok {
-		return "", fmt.Errorf("workspace not found in providerSpec")
+		return "", "", fmt.Errorf("workspace not found in providerSpec")
 	}
 
-	server, ok := workspace["server"].(string)
+	server, ok = workspace["server"].(string)
 	if !ok {
-		return "", fmt.Errorf("server not found in workspace")
+		return "", "", fmt.Errorf("server not found in workspace")
 	}
+	datacenter, _ = workspace["datacenter"].(string)
 
-	return server, nil
+	return server, datacenter, nil
+}
+
+// ListMachines lists the Machines with the given role, or every Machine when role is empty
+func (m *MachineManager) ListMachines(ctx context.Context, role string) ([]machinev1beta1.Machine, error) {
+	if m.machineClient == nil {
+		return nil, fmt.Errorf("machine client not initialized")
+	}
+
+	opts := metav1.ListOptions{}
+	if role != "" {
+		opts.LabelSelector = labels.SelectorFromSet(labels.Set{MachineRoleLabel: role}).String()
+	}
+	machines, err := m.machineClient.MachineV1beta1().Machines(MachineAPINamespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines: %w", err)
+	}
+	return machines.Items, nil
+}
+
+// UnreadyMachines describes the Machines of a MachineSet without a Ready node
+func (m *MachineManager) UnreadyMachines(ctx context.Context, machineSetName string) ([]string, error) {
+	if m.machineClient == nil {
+		return nil, fmt.Errorf("machine client not initialized")
+	}
+
+	machines, err := m.machineClient.MachineV1beta1().Machines(MachineAPINamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{MachineSetLabel: machineSetName}).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list machines for MachineSet %s: %w", machineSetName, err)
+	}
+
+	var unready []string
+	for _, machine := range machines.Items {
+		if machine.Status.NodeRef == nil {
+			unready = append(unready, fmt.Sprintf("%s (no node)", machine.Name))
+			continue
+		}
+		node, err := m.kubeClient.CoreV1().Nodes().Get(ctx, machine.Status.NodeRef.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				unready = append(unready, fmt.Sprintf("%s (node %s not found)", machine.Name, machine.Status.NodeRef.Name))
+				continue
+			}
+			return nil, fmt.Errorf("failed to get node %s: %w", machine.Status.NodeRef.Name, err)
+		}
+		if !isNodeReady(node) {
+			unready = append(unready, fmt.Sprintf("%s (node %s not Ready)", machine.Name, node.Name))
+		}
+	}
+	return unready, nil
+}
+
+// isNodeReady reports whether a node has the Ready condition
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
 }
 
 // DeleteMachineSet deletes a MachineSet
@@ -372,70 +628,131 @@ func (m *MachineManager) DeleteMachineSet(ctx context.Context, name string) erro
 	return nil
 }
 
-// WaitForMachinesReady waits for all machines in a MachineSet to be ready
-func (m *MachineManager) WaitForMachinesReady(ctx context.Context, machineSetName string, timeout time.Duration) (int32, int32, error) {
-	logger := klog.FromContext(ctx)
+// BackupAllMachineSets returns every MachineSet in the machine API namespace, with its full spec
+// including the providerSpec, ready to be serialized as a backup
+func (m *MachineManager) BackupAllMachineSets(ctx context.Context) ([]machinev1beta1.MachineSet, error) {
+	if m.machineClient == nil {
+		return nil, fmt.Errorf("machine client not initialized")
+	}
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	machineSetList, err := m.machineClient.MachineV1beta1().MachineSets(MachineAPINamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MachineSets: %w", err)
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return 0, 0, ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return 0, 0, fmt.Errorf("timeout waiting for machines to be ready")
-			}
+	machineSets := machineSetList.Items
+	for i := range machineSets {
+		// Typed list items carry no TypeMeta, which the backup manifest needs to be restorable
+		machineSets[i].TypeMeta = metav1.TypeMeta{
+			APIVersion: machinev1beta1.GroupVersion.String(),
+			Kind:       "MachineSet",
+		}
+	}
+	return machineSets, nil
+}
 
-			ready, total, err := m.getMachineStatus(ctx, machineSetName)
-			if err != nil {
-				logger.V(2).Info("Error getting machine status", "error", err)
-				continue
-			}
+// RestoreMachineSet restores a MachineSet from a backup, recreating it when it was deleted and
+// otherwise putting back its backed up spec, labels and annotations
+func (m *MachineManager) RestoreMachineSet(ctx context.Context, backup *machinev1beta1.MachineSet) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Restoring MachineSet from backup", "name", backup.Name, "replicas", ptr.Deref(backup.Spec.Replicas, 0))
 
-			if ready == total && total > 0 {
-				logger.Info("All machines ready", "ready", ready, "total", total)
-				return ready, total, nil
-			}
-			logger.V(2).Info("Waiting for machines", "ready", ready, "total", total)
+	if m.machineClient == nil {
+		return fmt.Errorf("machine client not initialized")
+	}
+
+	machineSets := m.machineClient.MachineV1beta1().MachineSets(MachineAPINamespace)
+	current, err := machineSets.Get(ctx, backup.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ms := backup.DeepCopy()
+		ms.Namespace = MachineAPINamespace
+		ms.ResourceVersion = ""
+		ms.UID = ""
+		ms.Generation = 0
+		ms.CreationTimestamp = metav1.Time{}
+		ms.DeletionTimestamp = nil
+		ms.ManagedFields = nil
+		ms.Status = machinev1beta1.MachineSetStatus{}
+		if _, err := machineSets.Create(ctx, ms, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to recreate MachineSet %s: %w", backup.Name, err)
 		}
+		logger.Info("Recreated MachineSet from backup", "name", backup.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get MachineSet %s: %w", backup.Name, err)
 	}
+
+	current.Labels = backup.Labels
+	current.Annotations = backup.Annotations
+	current.Spec = *backup.Spec.DeepCopy()
+	if _, err := machineSets.Update(ctx, current, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to restore MachineSet %s: %w", backup.Name, err)
+	}
+	logger.Info("Restored MachineSet from backup", "name", backup.Name)
+	return nil
 }
 
-// WaitForNodesReady waits for nodes corresponding to machines to be ready
-func (m *MachineManager) WaitForNodesReady(ctx context.Context, machineSetName string, timeout time.Duration) (int32, int32, error) {
+// WaitForMachinesReady waits for all machines in a MachineSet to be ready
+func (m *MachineManager) WaitForMachinesReady(ctx context.Context, machineSetName string, timeout time.Duration) (int32, int32, error) {
 	logger := klog.FromContext(ctx)
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	var ready, total int32
+	err := util.PollUntil(ctx, util.SlowBackoff, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		ready, total, err = m.getMachineStatus(ctx, machineSetName)
+		if err != nil {
+			logger.V(2).Info("Error getting machine status", "error", err)
+			return false, nil
+		}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return 0, 0, ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return 0, 0, fmt.Errorf("timeout waiting for nodes to be ready")
-			}
+		if ready == total && total > 0 {
+			logger.Info("All machines ready", "ready", ready, "total", total)
+			return true, nil
+		}
+		logger.V(2).Info("Waiting for machines", "ready", ready, "total", total)
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 0, 0, fmt.Errorf("timeout waiting for machines to be ready")
+	}
+	if err != nil {
+		return 0, 0, err
+	}
 
-			// Get nodes for this MachineSet
-			ready, total, err := m.getNodeStatus(ctx, machineSetName)
-			if err != nil {
-				logger.V(2).Info("Error getting node status", "error", err)
-				continue
-			}
+	return ready, total, nil
+}
 
-			if ready == total {
-				logger.Info("All nodes ready", "ready", ready, "total", total)
-				return ready, total, nil
-			}
+// WaitForNodesReady waits for nodes corresponding to machines to be ready
+func (m *MachineManager) WaitForNodesReady(ctx context.Context, machineSetName string, timeout time.Duration) (int32, int32, error) {
+	logger := klog.FromContext(ctx)
+
+	var ready, total int32
+	err := util.PollUntil(ctx, util.SlowBackoff, timeout, func(ctx context.Context) (bool, error) {
+		// Get nodes for this MachineSet
+		var err error
+		ready, total, err = m.getNodeStatus(ctx, machineSetName)
+		if err != nil {
+			logger.V(2).Info("Error getting node status", "error", err)
+			return false, nil
+		}
 
-			logger.V(2).Info("Waiting for nodes", "ready", ready, "total", total)
+		if ready == total {
+			logger.Info("All nodes ready", "ready", ready, "total", total)
+			return true, nil
 		}
+
+		logger.V(2).Info("Waiting for nodes", "ready", ready, "total", total)
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 0, 0, fmt.Errorf("timeout waiting for nodes to be ready")
 	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return ready, total, nil
 }
 
 // getMachineStatus returns ready and total machine counts for a MachineSet
@@ -530,6 +847,33 @@ func (m *MachineManager) ScaleMachineSet(ctx context.Context, name string, repli
 	return nil
 }
 
+// ScaleDownMachineSet lowers a MachineSet to replicas when it has more, leaving one at or below
+// replicas untouched. It reports whether the MachineSet was scaled.
+func (m *MachineManager) ScaleDownMachineSet(ctx context.Context, name string, replicas int32) (bool, error) {
+	ms, err := m.GetMachineSet(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	if ms.Spec.Replicas == nil || *ms.Spec.Replicas <= replicas {
+		return false, nil
+	}
+	if err := m.ScaleMachineSet(ctx, name, replicas); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckMachinesScaledDown checks a MachineSet has no more than replicas Machine objects left,
+// reporting how many are still to be removed
+func (m *MachineManager) CheckMachinesScaledDown(ctx context.Context, machineSetName string, replicas int32) (done bool, excess int32, err error) {
+	_, total, err := m.getMachineStatus(ctx, machineSetName)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to list machines for MachineSet %s: %w", machineSetName, err)
+	}
+	excess = max(total-replicas, 0)
+	return excess == 0, excess, nil
+}
+
 // GetControlPlaneMachineSet gets the Control Plane Machine Set as an unstructured object for backup
 func (m *MachineManager) GetControlPlaneMachineSet(ctx context.Context) (*unstructured.Unstructured, error) {
 	logger := klog.FromContext(ctx)
@@ -573,64 +917,48 @@ func (m *MachineManager) DeleteControlPlaneMachineSet(ctx context.Context) error
 func (m *MachineManager) WaitForCPMSDeletion(ctx context.Context, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return fmt.Errorf("timeout waiting for CPMS deletion")
-			}
-
-			_, err := m.dynamicClient.Resource(cpmsGVR).Namespace(MachineAPINamespace).Get(ctx, "cluster", metav1.GetOptions{})
-			if errors.IsNotFound(err) {
-				logger.Info("CPMS successfully deleted")
-				return nil
-			}
+	err := util.PollUntil(ctx, util.FastBackoff, timeout, func(ctx context.Context) (bool, error) {
+		_, err := m.dynamicClient.Resource(cpmsGVR).Namespace(MachineAPINamespace).Get(ctx, "cluster", metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			logger.Info("CPMS successfully deleted")
+			return true, nil
 		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for CPMS deletion")
 	}
+	return err
 }
 
 // WaitForCPMSInactive waits for CPMS to become Inactive state
 func (m *MachineManager) WaitForCPMSInactive(ctx context.Context, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return fmt.Errorf("timeout waiting for CPMS to become Inactive")
-			}
-
-			cpms, err := m.dynamicClient.Resource(cpmsGVR).Namespace(MachineAPINamespace).Get(ctx, "cluster", metav1.GetOptions{})
-			if err != nil {
-				logger.V(2).Info("Error getting CPMS", "error", err)
-				continue
-			}
+	err := util.PollUntil(ctx, util.FastBackoff, timeout, func(ctx context.Context) (bool, error) {
+		cpms, err := m.dynamicClient.Resource(cpmsGVR).Namespace(MachineAPINamespace).Get(ctx, "cluster", metav1.GetOptions{})
+		if err != nil {
+			logger.V(2).Info("Error getting CPMS", "error", err)
+			return false, nil
+		}
 
-			state, found, err := unstructured.NestedString(cpms.Object, "spec", "state")
-			if err != nil || !found {
-				logger.V(2).Info("CPMS state not found yet")
-				continue
-			}
+		state, found, err := unstructured.NestedString(cpms.Object, "spec", "state")
+		if err != nil || !found {
+			logger.V(2).Info("CPMS state not found yet")
+			return false, nil
+		}
 
-			logger.V(2).Info("CPMS state", "state", state)
-			if state == "Inactive" {
-				logger.Info("CPMS is now Inactive")
-				return nil
-			}
+		logger.V(2).Info("CPMS state", "state", state)
+		if state == "Inactive" {
+			logger.Info("CPMS is now Inactive")
+			return true, nil
 		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for CPMS to become Inactive")
 	}
+	return err
 }
 
 // UpdateCPMSFailureDomain updates an existing CPMS with new failure domain and sets it to Active
@@ -804,8 +1132,10 @@ func (m *MachineManager) IsCPMSGenerationObserved(ctx context.Context) (bool, er
 	return observedGeneration >= generation, nil
 }
 
-// WaitForControlPlaneRollout waits for the control plane rollout to complete
-func (m *MachineManager) WaitForControlPlaneRollout(ctx context.Context, timeout time.Duration) error {
+// WaitForControlPlaneRollout waits for the control plane rollout to complete. Once every replica
+// is updated and ready it waits out the settle period and then, when operators is set, for the
+// ControlPlaneOperators to be Available and not Progressing, all within timeout.
+func (m *MachineManager) WaitForControlPlaneRollout(ctx context.Context, timeout, settle time.Duration, operators *OperatorManager) error {
 	logger := klog.FromContext(ctx)
 
 	if m.dynamicClient == nil {
@@ -813,38 +1143,64 @@ func (m *MachineManager) WaitForControlPlaneRollout(ctx context.Context, timeout
 	}
 
 	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+	err := util.PollUntil(ctx, util.SlowBackoff, timeout, func(ctx context.Context) (bool, error) {
+		complete, replicas, updatedReplicas, readyReplicas, err := m.CheckControlPlaneRolloutStatus(ctx)
+		if err != nil {
+			logger.V(2).Info("Error checking CPMS status", "error", err)
+			return false, nil
+		}
+
+		if complete {
+			logger.Info("Control plane rollout complete",
+				"replicas", replicas,
+				"updatedReplicas", updatedReplicas,
+				"readyReplicas", readyReplicas)
+			return true, nil
+		}
+
+		logger.V(2).Info("Waiting for control plane rollout",
+			"replicas", replicas,
+			"updatedReplicas", updatedReplicas,
+			"readyReplicas", readyReplicas)
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for control plane rollout")
+	}
+	if err != nil {
+		return err
+	}
 
-	for {
+	if settle > 0 {
+		logger.Info("Waiting for control plane to settle", "settle", settle)
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return fmt.Errorf("timeout waiting for control plane rollout")
-			}
-
-			complete, replicas, updatedReplicas, readyReplicas, err := m.CheckControlPlaneRolloutStatus(ctx)
-			if err != nil {
-				logger.V(2).Info("Error checking CPMS status", "error", err)
-				continue
-			}
-
-			if complete {
-				logger.Info("Control plane rollout complete",
-					"replicas", replicas,
-					"updatedReplicas", updatedReplicas,
-					"readyReplicas", readyReplicas)
-				return nil
-			}
+		case <-time.After(settle):
+		}
+	}
+	if operators == nil {
+		return nil
+	}
 
-			logger.V(2).Info("Waiting for control plane rollout",
-				"replicas", replicas,
-				"updatedReplicas", updatedReplicas,
-				"readyReplicas", readyReplicas)
+	var unsettled []string
+	err = util.PollUntil(ctx, util.SlowBackoff, time.Until(deadline), func(ctx context.Context) (bool, error) {
+		var checkErr error
+		unsettled, checkErr = operators.UnsettledOperators(ctx, ControlPlaneOperators)
+		if checkErr != nil {
+			logger.V(2).Info("Error checking control plane operators", "error", checkErr)
+			return false, nil
+		}
+		if len(unsettled) > 0 {
+			logger.V(2).Info("Waiting for control plane operators to settle", "unsettled", unsettled)
+			return false, nil
 		}
+		return true, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for control plane operators to settle: %s", strings.Join(unsettled, ", "))
 	}
+	return err
 }
 
 // CheckMachinesReady checks if all machines in a MachineSet are ready without blocking
@@ -944,7 +1300,7 @@ func (m *MachineManager) CheckNodesDeletedForMachines(ctx context.Context, machi
 		}
 		_, err := m.kubeClient.CoreV1().Nodes().Get(ctx, machine.Status.NodeRef.Name, metav1.GetOptions{})
 		if err != nil {
-			if errors.IsNotFound(err) {
+			if apierrors.IsNotFound(err) {
 				continue // Node is gone
 			}
 			logger.V(2).Info("Error checking node existence", "node", machine.Status.NodeRef.Name, "error", err)
@@ -0,0 +1,172 @@
+package openshift
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// NodeUnschedulableReason returns why new pods cannot be scheduled on a node, or "" when the node
+// is usable. Taints matching allowedTaints, such as those its MachineSet applies on purpose, are
+// expected and ignored.
+func NodeUnschedulableReason(node *corev1.Node, allowedTaints []corev1.Taint) string {
+	if !isNodeReady(node) {
+		return "node is not Ready"
+	}
+	if node.Spec.Unschedulable {
+		return "node is cordoned"
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeNetworkUnavailable && condition.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("node network is unavailable: %s", condition.Message)
+		}
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		allowed := false
+		for j := range allowedTaints {
+			if allowedTaints[j].MatchTaint(taint) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("node has taint %s", taint.ToString())
+		}
+	}
+	return ""
+}
+
+// MachineSetNodes returns the nodes of the Machines of a MachineSet and describes the Machines
+// that have no node yet
+func (m *MachineManager) MachineSetNodes(ctx context.Context, machineSetName string) ([]corev1.Node, []string, error) {
+	if m.machineClient == nil {
+		return nil, nil, fmt.Errorf("machine client not initialized")
+	}
+
+	machines, err := m.machineClient.MachineV1beta1().Machines(MachineAPINamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{MachineSetLabel: machineSetName}).String(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list machines for MachineSet %s: %w", machineSetName, err)
+	}
+
+	var nodes []corev1.Node
+	var missing []string
+	for _, machine := range machines.Items {
+		if machine.Status.NodeRef == nil {
+			missing = append(missing, fmt.Sprintf("machine %s has no node", machine.Name))
+			continue
+		}
+		node, err := m.kubeClient.CoreV1().Nodes().Get(ctx, machine.Status.NodeRef.Name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, fmt.Sprintf("machine %s: node %s not found", machine.Name, machine.Status.NodeRef.Name))
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get node %s: %w", machine.Status.NodeRef.Name, err)
+		}
+		nodes = append(nodes, *node)
+	}
+	return nodes, missing, nil
+}
+
+// SchedulingCapacity compares the CPU and memory requested by pods with what schedulable nodes
+// can allocate
+type SchedulingCapacity struct {
+	AllocatableCPU    resource.Quantity
+	AllocatableMemory resource.Quantity
+	RequestedCPU      resource.Quantity
+	RequestedMemory   resource.Quantity
+}
+
+// Shortfall describes the resources requested beyond what the nodes can allocate, or "" when the
+// nodes can hold every pod
+func (c *SchedulingCapacity) Shortfall() string {
+	var short []string
+	if c.RequestedCPU.Cmp(c.AllocatableCPU) > 0 {
+		short = append(short, fmt.Sprintf("pods request %s CPU but schedulable nodes allocate %s",
+			c.RequestedCPU.String(), c.AllocatableCPU.String()))
+	}
+	if c.RequestedMemory.Cmp(c.AllocatableMemory) > 0 {
+		short = append(short, fmt.Sprintf("pods request %s memory but schedulable nodes allocate %s",
+			c.RequestedMemory.String(), c.AllocatableMemory.String()))
+	}
+	return strings.Join(short, ", ")
+}
+
+// SchedulingCapacityWithout computes the scheduling capacity left once the named nodes are
+// removed. The capacity is that of the schedulable nodes that remain; the demand is the requests
+// of the pods running on them and on the removed nodes, except the DaemonSet pods of the removed
+// nodes, which go away with their node.
+func (m *MachineManager) SchedulingCapacityWithout(ctx context.Context, removed map[string]bool) (*SchedulingCapacity, error) {
+	nodes, err := m.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	capacity := &SchedulingCapacity{}
+	remaining := make(map[string]bool)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if removed[node.Name] || NodeUnschedulableReason(node, nil) != "" {
+			continue
+		}
+		remaining[node.Name] = true
+		capacity.AllocatableCPU.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		capacity.AllocatableMemory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+
+	pods, err := m.kubeClient.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		switch {
+		case remaining[pod.Spec.NodeName]:
+		case removed[pod.Spec.NodeName]:
+			if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "DaemonSet" {
+				continue
+			}
+		default:
+			continue
+		}
+		requests := podRequests(pod)
+		capacity.RequestedCPU.Add(requests[corev1.ResourceCPU])
+		capacity.RequestedMemory.Add(requests[corev1.ResourceMemory])
+	}
+	return capacity, nil
+}
+
+// podRequests returns the resources the scheduler reserves for a pod: the larger of the sum of its
+// containers' requests and the largest init container request, plus the pod overhead
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		var total resource.Quantity
+		for _, container := range pod.Spec.Containers {
+			total.Add(container.Resources.Requests[name])
+		}
+		for _, container := range pod.Spec.InitContainers {
+			if request := container.Resources.Requests[name]; request.Cmp(total) > 0 {
+				total = request.DeepCopy()
+			}
+		}
+		total.Add(pod.Spec.Overhead[name])
+		requests[name] = total
+	}
+	return requests
+}
@@ -3,10 +3,13 @@ package openshift
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
 	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 )
@@ -18,6 +21,19 @@ var (
 	}
 )
 
+// ControlPlaneOperators are the ClusterOperators whose health confirms etcd quorum and
+// apiserver availability after a control plane rollout
+var ControlPlaneOperators = []string{"etcd", "kube-apiserver"}
+
+// DegradedOperator describes a ClusterOperator that is Degraded=True or Available=False
+type DegradedOperator struct {
+	Name        string
+	Available   bool
+	Degraded    bool
+	Progressing bool
+	Message     string
+}
+
 // OperatorManager manages cluster operator operations
 type OperatorManager struct {
 	client configclient.Interface
@@ -87,6 +103,90 @@ func (m *OperatorManager) CheckAllOperatorsHealthy(ctx context.Context) (bool, [
 	return true, nil, nil
 }
 
+// GetDegradedClusterOperators returns the ClusterOperators that are Degraded=True or Available=False,
+// along with their condition messages. Operators in ExcludedOperators or tolerated are skipped.
+func (m *OperatorManager) GetDegradedClusterOperators(ctx context.Context, tolerated []string) ([]DegradedOperator, error) {
+	logger := klog.FromContext(ctx)
+
+	operators, err := m.client.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster operators: %w", err)
+	}
+
+	var degradedOperators []DegradedOperator
+
+	for _, operator := range operators.Items {
+		// Operators that never report Available are treated as unavailable
+		state := DegradedOperator{Name: operator.Name}
+		var messages []string
+
+		for _, condition := range operator.Status.Conditions {
+			switch condition.Type {
+			case configv1.OperatorAvailable:
+				state.Available = condition.Status == configv1.ConditionTrue
+				if !state.Available && condition.Message != "" {
+					messages = append(messages, "Available=False: "+condition.Message)
+				}
+			case configv1.OperatorDegraded:
+				state.Degraded = condition.Status == configv1.ConditionTrue
+				if state.Degraded && condition.Message != "" {
+					messages = append(messages, "Degraded=True: "+condition.Message)
+				}
+			case configv1.OperatorProgressing:
+				state.Progressing = condition.Status == configv1.ConditionTrue
+			}
+		}
+
+		if state.Available && !state.Degraded {
+			continue
+		}
+		state.Message = strings.Join(messages, "; ")
+
+		if ExcludedOperators[operator.Name] || slices.Contains(tolerated, operator.Name) {
+			logger.V(1).Info("Tolerated operator is unhealthy (expected during migration)",
+				"operator", operator.Name,
+				"available", state.Available,
+				"degraded", state.Degraded,
+				"message", state.Message)
+			continue
+		}
+
+		degradedOperators = append(degradedOperators, state)
+	}
+
+	return degradedOperators, nil
+}
+
+// UnsettledOperators returns the named ClusterOperators that are not Available=True and
+// Progressing=False, each described with its conditions. A missing operator is unsettled.
+func (m *OperatorManager) UnsettledOperators(ctx context.Context, names []string) ([]string, error) {
+	var unsettled []string
+	for _, name := range names {
+		operator, err := m.GetOperator(ctx, name)
+		if errors.IsNotFound(err) {
+			unsettled = append(unsettled, fmt.Sprintf("%s (not found)", name))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster operator %s: %w", name, err)
+		}
+
+		available, progressing := false, false
+		for _, condition := range operator.Status.Conditions {
+			switch condition.Type {
+			case configv1.OperatorAvailable:
+				available = condition.Status == configv1.ConditionTrue
+			case configv1.OperatorProgressing:
+				progressing = condition.Status == configv1.ConditionTrue
+			}
+		}
+		if !available || progressing {
+			unsettled = append(unsettled, fmt.Sprintf("%s (available=%v, progressing=%v)", name, available, progressing))
+		}
+	}
+	return unsettled, nil
+}
+
 // WaitForOperatorsHealthy waits for all operators to become healthy
 func (m *OperatorManager) WaitForOperatorsHealthy(ctx context.Context, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
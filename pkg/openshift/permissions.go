@@ -0,0 +1,88 @@
+package openshift
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Permission is an API access the controller needs; an empty namespace means cluster-wide
+type Permission struct {
+	Verb      string
+	Group     string
+	Resource  string
+	Namespace string
+}
+
+// String describes the permission as "verb group/resource in namespace"
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = p.Group + "/" + p.Resource
+	}
+	if p.Namespace == "" {
+		return p.Verb + " " + resource
+	}
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// RequiredPermissions returns the permissions whose absence would only show up as failures in the
+// middle of a migration: those on the Infrastructure CRD and its definition, the Control Plane
+// Machine Set, MachineSets, PersistentVolumes, the vCenter credentials and the cloud provider
+// config. The leader election lease is included when leaseNamespace is set.
+func RequiredPermissions(secretNamespace, leaseNamespace string) []Permission {
+	permissions := []Permission{
+		{Verb: "update", Group: "config.openshift.io", Resource: "infrastructures"},
+		{Verb: "update", Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions"},
+		{Verb: "delete", Group: "machine.openshift.io", Resource: "controlplanemachinesets", Namespace: MachineAPINamespace},
+		{Verb: "create", Group: "machine.openshift.io", Resource: "controlplanemachinesets", Namespace: MachineAPINamespace},
+		{Verb: "create", Group: "machine.openshift.io", Resource: "machinesets", Namespace: MachineAPINamespace},
+		{Verb: "update", Group: "machine.openshift.io", Resource: "machinesets", Namespace: MachineAPINamespace},
+		{Verb: "update", Resource: "persistentvolumes"},
+		{Verb: "delete", Resource: "persistentvolumes"},
+		{Verb: "get", Resource: "secrets", Namespace: secretNamespace},
+		{Verb: "update", Resource: "secrets", Namespace: VSphereCredsSecretNamespace},
+		{Verb: "update", Resource: "configmaps", Namespace: CloudProviderConfigMapNamespace},
+	}
+	if leaseNamespace != "" {
+		for _, verb := range []string{"get", "create", "update"} {
+			permissions = append(permissions, Permission{Verb: verb, Group: "coordination.k8s.io", Resource: "leases", Namespace: leaseNamespace})
+		}
+	}
+	return permissions
+}
+
+// MissingPermissions asks the API server, through SelfSubjectAccessReviews, which of the given
+// permissions the controller's own identity lacks, and describes each one
+func MissingPermissions(ctx context.Context, kubeClient kubernetes.Interface, permissions []Permission) ([]string, error) {
+	var missing []string
+	for _, permission := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:      permission.Verb,
+					Group:     permission.Group,
+					Resource:  permission.Resource,
+					Namespace: permission.Namespace,
+				},
+			},
+		}
+		result, err := kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to review permission to %s: %w", permission, err)
+		}
+		if result.Status.Allowed {
+			continue
+		}
+		description := permission.String()
+		if reason := strings.TrimSpace(result.Status.Reason); reason != "" {
+			description += " (" + reason + ")"
+		}
+		missing = append(missing, description)
+	}
+	return missing, nil
+}
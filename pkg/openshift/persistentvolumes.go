@@ -4,23 +4,111 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 const (
 	// VSphereCSIDriver is the driver name for vSphere CSI
 	VSphereCSIDriver = "csi.vsphere.vmware.com"
+
+	// VSphereInTreeProvisioner is the legacy in-tree provisioner, which CSI migration redirects to VSphereCSIDriver
+	VSphereInTreeProvisioner = "kubernetes.io/vsphere-volume"
+
+	// OriginalDataSourceAnnotation records the snapshot or PVC a restored PVC was originally provisioned from
+	OriginalDataSourceAnnotation = "migration.openshift.io/original-data-source"
+
+	// MigratedFromHandleAnnotation records the volumeHandle a migrated PV had on the source vCenter
+	MigratedFromHandleAnnotation = "migration.openshift.io/migrated-from-handle"
+
+	// MigratedAtAnnotation records when a PV or PVC was migrated, in RFC 3339 format
+	MigratedAtAnnotation = "migration.openshift.io/migrated-at"
+
+	// MigrationNameAnnotation records the namespace/name of the migration that moved a PV or PVC
+	MigrationNameAnnotation = "migration.openshift.io/migration-name"
+
+	// PVCProtectionFinalizer keeps a deleted PVC Terminating while pods still use it
+	PVCProtectionFinalizer = "kubernetes.io/pvc-protection"
 )
 
+// PVCDeletionBlockedError reports a deleted PVC kept Terminating by PVCProtectionFinalizer
+// because pods that have not terminated still use it
+type PVCDeletionBlockedError struct {
+	Namespace string
+	Name      string
+	// Pods are the pods still using the PVC
+	Pods []corev1.Pod
+}
+
+func (e *PVCDeletionBlockedError) Error() string {
+	pods := make([]string, 0, len(e.Pods))
+	for _, pod := range e.Pods {
+		description := fmt.Sprintf("%s (%s", pod.Name, pod.Status.Phase)
+		if pod.Spec.NodeName != "" {
+			description += " on node " + pod.Spec.NodeName
+		}
+		if pod.DeletionTimestamp != nil {
+			description += ", terminating"
+		}
+		pods = append(pods, description+")")
+	}
+	return fmt.Sprintf("PVC %s/%s is kept Terminating by the %s finalizer, still used by pod(s): %s",
+		e.Namespace, e.Name, PVCProtectionFinalizer, strings.Join(pods, ", "))
+}
+
+// MigrationProvenance describes the migration of a volume, stamped as annotations on its PV and
+// restored PVC so the move can be audited and the volume is not migrated again
+type MigrationProvenance struct {
+	// SourceVolumeHandle is the volumeHandle of the PV before the migration
+	SourceVolumeHandle string
+	// MigrationName is the namespace/name of the migration
+	MigrationName string
+	// MigratedAt is when the volume was migrated
+	MigratedAt time.Time
+}
+
+// annotate returns a copy of annotations with the provenance added, keeping the source handle
+// of an earlier stamp so a retried update does not record the target handle as the source
+func (p *MigrationProvenance) annotate(annotations map[string]string) map[string]string {
+	if p == nil {
+		return annotations
+	}
+	annotated := make(map[string]string, len(annotations)+3)
+	maps.Copy(annotated, annotations)
+	if _, ok := annotated[MigratedFromHandleAnnotation]; !ok {
+		annotated[MigratedFromHandleAnnotation] = p.SourceVolumeHandle
+	}
+	annotated[MigratedAtAnnotation] = p.MigratedAt.UTC().Format(time.RFC3339)
+	annotated[MigrationNameAnnotation] = p.MigrationName
+	return annotated
+}
+
+// IsMigratedBy reports whether a PV carries the provenance of a completed volume migration by the
+// migration named migrationName, as namespace/name. A PV moved by another migration may since
+// have become the source of this one, so it is not treated as migrated.
+func IsMigratedBy(pv *corev1.PersistentVolume, migrationName string) bool {
+	if _, ok := pv.Annotations[MigratedFromHandleAnnotation]; !ok {
+		return false
+	}
+	return pv.Annotations[MigrationNameAnnotation] == migrationName
+}
+
 // PersistentVolumeManager manages PV operations
 type PersistentVolumeManager struct {
 	kubeClient kubernetes.Interface
@@ -36,6 +124,25 @@ type VSphereCSIPV struct {
 	ReclaimPolicy   corev1.PersistentVolumeReclaimPolicy
 	ClaimRef        *corev1.ObjectReference
 	Attributes      map[string]string
+	Phase           corev1.PersistentVolumePhase
+}
+
+// InTreeVSpherePV represents a PersistentVolume using the in-tree vSphere volume plugin
+type InTreeVSpherePV struct {
+	Name         string
+	VolumePath   string
+	StorageClass string
+	ClaimRef     *corev1.ObjectReference
+}
+
+// CSIDriverStatus describes the installation state of the vSphere CSI driver
+type CSIDriverStatus struct {
+	// Installed is true when the CSIDriver object exists
+	Installed bool
+	// RegisteredNodes lists the nodes whose CSINode has the driver registered
+	RegisteredNodes []string
+	// UnregisteredNodes lists the nodes whose CSINode is missing or lacks the driver
+	UnregisteredNodes []string
 }
 
 // NewPersistentVolumeManager creates a new PV manager
@@ -45,17 +152,41 @@ func NewPersistentVolumeManager(kubeClient kubernetes.Interface) *PersistentVolu
 	}
 }
 
-// ListVSphereCSIVolumes lists all PVs using the vSphere CSI driver
-func (m *PersistentVolumeManager) ListVSphereCSIVolumes(ctx context.Context) ([]VSphereCSIPV, error) {
+// ListVSphereCSIVolumes lists PVs using the vSphere CSI driver.
+// If selector is non-nil, only volumes matching the selector are returned;
+// the number of unselected volumes is logged but they are otherwise left untouched.
+// Volumes the migration named migrationName, as namespace/name, already moved point at its
+// target and are left out too; volumes moved by other migrations are listed.
+func (m *PersistentVolumeManager) ListVSphereCSIVolumes(ctx context.Context, selector *migrationv1alpha1.VolumeSelector, migrationName string) ([]VSphereCSIPV, error) {
+	return m.listVSphereCSIVolumes(ctx, selector, migrationName, false)
+}
+
+// ListAllVSphereCSIVolumes lists every PV using the vSphere CSI driver, including those already migrated
+func (m *PersistentVolumeManager) ListAllVSphereCSIVolumes(ctx context.Context) ([]VSphereCSIPV, error) {
+	return m.listVSphereCSIVolumes(ctx, nil, "", true)
+}
+
+func (m *PersistentVolumeManager) listVSphereCSIVolumes(ctx context.Context, selector *migrationv1alpha1.VolumeSelector, migrationName string, includeMigrated bool) ([]VSphereCSIPV, error) {
 	logger := klog.FromContext(ctx)
 	logger.V(2).Info("Listing vSphere CSI PersistentVolumes")
 
+	var pvcSelector labels.Selector
+	if selector != nil && selector.PVCLabelSelector != nil {
+		var err error
+		pvcSelector, err = metav1.LabelSelectorAsSelector(selector.PVCLabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PVC label selector: %w", err)
+		}
+	}
+
 	pvList, err := m.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
 	}
 
 	var csiPVs []VSphereCSIPV
+	unselected := 0
+	migrated := 0
 	for _, pv := range pvList.Items {
 		// Skip if not a CSI volume
 		if pv.Spec.CSI == nil {
@@ -72,6 +203,28 @@ func (m *PersistentVolumeManager) ListVSphereCSIVolumes(ctx context.Context) ([]
 			continue
 		}
 
+		// Skip volumes this migration already moved
+		if !includeMigrated && IsMigratedBy(&pv, migrationName) {
+			logger.V(4).Info("Skipping already migrated PV", "pv", pv.Name,
+				"migratedFrom", pv.Annotations[MigratedFromHandleAnnotation],
+				"migration", pv.Annotations[MigrationNameAnnotation])
+			migrated++
+			continue
+		}
+
+		// Skip volumes not matched by the volume selector
+		if selector != nil {
+			selected, err := m.matchesVolumeSelector(ctx, &pv, selector, pvcSelector)
+			if err != nil {
+				return nil, err
+			}
+			if !selected {
+				logger.V(4).Info("Skipping PV not matched by volume selector", "pv", pv.Name)
+				unselected++
+				continue
+			}
+		}
+
 		// Extract capacity
 		var capacityBytes int64
 		if qty, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
@@ -87,15 +240,78 @@ func (m *PersistentVolumeManager) ListVSphereCSIVolumes(ctx context.Context) ([]
 			ReclaimPolicy:   pv.Spec.PersistentVolumeReclaimPolicy,
 			ClaimRef:        pv.Spec.ClaimRef,
 			Attributes:      pv.Spec.CSI.VolumeAttributes,
+			Phase:           pv.Status.Phase,
 		}
 
 		csiPVs = append(csiPVs, csiPV)
 	}
 
-	logger.Info("Found vSphere CSI PersistentVolumes", "count", len(csiPVs))
+	logger.Info("Found vSphere CSI PersistentVolumes", "count", len(csiPVs), "unselected", unselected, "alreadyMigrated", migrated)
 	return csiPVs, nil
 }
 
+// ListInTreeVSphereVolumes lists PVs using the in-tree vSphere volume plugin (spec.vsphereVolume).
+// These are not handled by CSI volume migration and reference a VMDK on the source vCenter.
+func (m *PersistentVolumeManager) ListInTreeVSphereVolumes(ctx context.Context) ([]InTreeVSpherePV, error) {
+	logger := klog.FromContext(ctx)
+
+	pvList, err := m.kubeClient.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
+	}
+
+	var inTreePVs []InTreeVSpherePV
+	for _, pv := range pvList.Items {
+		if pv.Spec.VsphereVolume == nil {
+			continue
+		}
+
+		inTreePVs = append(inTreePVs, InTreeVSpherePV{
+			Name:         pv.Name,
+			VolumePath:   pv.Spec.VsphereVolume.VolumePath,
+			StorageClass: pv.Spec.StorageClassName,
+			ClaimRef:     pv.Spec.ClaimRef,
+		})
+	}
+
+	logger.V(2).Info("Found in-tree vSphere PersistentVolumes", "count", len(inTreePVs))
+	return inTreePVs, nil
+}
+
+// matchesVolumeSelector reports whether a PV matches every criterion set in the selector
+func (m *PersistentVolumeManager) matchesVolumeSelector(ctx context.Context, pv *corev1.PersistentVolume, selector *migrationv1alpha1.VolumeSelector, pvcSelector labels.Selector) (bool, error) {
+	if len(selector.PVNames) > 0 && !slices.Contains(selector.PVNames, pv.Name) {
+		return false, nil
+	}
+
+	// Namespace and label criteria apply to the bound PVC
+	if len(selector.Namespaces) == 0 && pvcSelector == nil {
+		return true, nil
+	}
+	if pv.Spec.ClaimRef == nil {
+		return false, nil
+	}
+
+	if len(selector.Namespaces) > 0 && !slices.Contains(selector.Namespaces, pv.Spec.ClaimRef.Namespace) {
+		return false, nil
+	}
+
+	if pvcSelector != nil {
+		pvc, err := m.GetPVC(ctx, pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to get PVC %s/%s: %w", pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name, err)
+		}
+		if !pvcSelector.Matches(labels.Set(pvc.Labels)) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
 // GetPV retrieves a PersistentVolume by name
 func (m *PersistentVolumeManager) GetPV(ctx context.Context, name string) (*corev1.PersistentVolume, error) {
 	return m.kubeClient.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
@@ -107,8 +323,9 @@ func (m *PersistentVolumeManager) GetPVC(ctx context.Context, namespace, name st
 }
 
 // UpdatePVVolumeHandle updates the volumeHandle in a PV's CSI spec
-// This is used after migrating the underlying FCD to update the PV to point to the new volume ID
-func (m *PersistentVolumeManager) UpdatePVVolumeHandle(ctx context.Context, pvName string, newVolumeHandle string) error {
+// This is used after migrating the underlying FCD to update the PV to point to the new volume ID.
+// A non-nil provenance is stamped on the PV, its source handle taken from the PV.
+func (m *PersistentVolumeManager) UpdatePVVolumeHandle(ctx context.Context, pvName string, newVolumeHandle string, provenance *MigrationProvenance) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Updating PV volumeHandle", "pv", pvName, "newVolumeHandle", newVolumeHandle)
 
@@ -126,6 +343,11 @@ func (m *PersistentVolumeManager) UpdatePVVolumeHandle(ctx context.Context, pvNa
 
 	// Update the volume handle
 	pv.Spec.CSI.VolumeHandle = newVolumeHandle
+	if provenance != nil {
+		stamp := *provenance
+		stamp.SourceVolumeHandle = oldHandle
+		pv.Annotations = stamp.annotate(pv.Annotations)
+	}
 
 	// Update the PV
 	_, err = m.kubeClient.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{})
@@ -140,6 +362,25 @@ func (m *PersistentVolumeManager) UpdatePVVolumeHandle(ctx context.Context, pvNa
 	return nil
 }
 
+// CheckVolumeHandleSwap asks the API server, in a dry run, whether the volumeHandle of a bound PV
+// may be changed, so admission that forbids it is found before any volume is touched
+func (m *PersistentVolumeManager) CheckVolumeHandleSwap(ctx context.Context, pvName string) error {
+	pv, err := m.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PV %s: %w", pvName, err)
+	}
+	if pv.Spec.CSI == nil {
+		return fmt.Errorf("PV %s is not a CSI volume", pvName)
+	}
+
+	pv.Spec.CSI.VolumeHandle += "-handle-swap-check"
+	_, err = m.kubeClient.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		return fmt.Errorf("the volumeHandle of PV %s cannot be changed: %w", pvName, err)
+	}
+	return nil
+}
+
 // FindPodsUsingPVC finds all pods that are using a specific PVC
 func (m *PersistentVolumeManager) FindPodsUsingPVC(ctx context.Context, pvcNamespace, pvcName string) ([]corev1.Pod, error) {
 	logger := klog.FromContext(ctx)
@@ -193,6 +434,28 @@ func BuildVSphereVolumeHandle(fcdID string) string {
 	return fmt.Sprintf("file://%s", fcdID)
 }
 
+// FindSourceResidentVolumes returns the vSphere CSI PVs, including those moved by any migration,
+// whose volume handle onSource reports as living on the source vCenter.
+func (m *PersistentVolumeManager) FindSourceResidentVolumes(ctx context.Context, onSource func(volumeHandle string) (bool, error)) ([]VSphereCSIPV, error) {
+	pvs, err := m.ListAllVSphereCSIVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resident []VSphereCSIPV
+	for _, pv := range pvs {
+		found, err := onSource(pv.VolumeHandle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up volume of PV %s on the source vCenter: %w", pv.Name, err)
+		}
+		if found {
+			resident = append(resident, pv)
+		}
+	}
+
+	return resident, nil
+}
+
 // GetPVsByStorageClass lists all PVs for a specific storage class
 func (m *PersistentVolumeManager) GetPVsByStorageClass(ctx context.Context, storageClassName string) ([]corev1.PersistentVolume, error) {
 	logger := klog.FromContext(ctx)
@@ -214,11 +477,140 @@ func (m *PersistentVolumeManager) GetPVsByStorageClass(ctx context.Context, stor
 	return pvs, nil
 }
 
+// GetStorageClass retrieves a StorageClass by name
+func (m *PersistentVolumeManager) GetStorageClass(ctx context.Context, name string) (*storagev1.StorageClass, error) {
+	return m.kubeClient.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{})
+}
+
+// IsVSphereStorageClass reports whether a StorageClass provisions volumes through the vSphere CSI driver,
+// either directly or through CSI migration of the in-tree provisioner
+func IsVSphereStorageClass(sc *storagev1.StorageClass) bool {
+	return sc.Provisioner == VSphereCSIDriver || sc.Provisioner == VSphereInTreeProvisioner
+}
+
+// GetVSphereCSIDriverStatus checks that the vSphere CSIDriver is installed and which nodes have registered it
+func (m *PersistentVolumeManager) GetVSphereCSIDriverStatus(ctx context.Context) (*CSIDriverStatus, error) {
+	logger := klog.FromContext(ctx)
+
+	status := &CSIDriverStatus{}
+	_, err := m.kubeClient.StorageV1().CSIDrivers().Get(ctx, VSphereCSIDriver, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("failed to get CSIDriver %s: %w", VSphereCSIDriver, err)
+	}
+	status.Installed = true
+
+	nodes, err := m.kubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	csiNodes, err := m.kubeClient.StorageV1().CSINodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CSINodes: %w", err)
+	}
+
+	registered := make(map[string]bool)
+	for _, csiNode := range csiNodes.Items {
+		for _, driver := range csiNode.Spec.Drivers {
+			if driver.Name == VSphereCSIDriver {
+				registered[csiNode.Name] = true
+			}
+		}
+	}
+
+	for _, node := range nodes.Items {
+		if registered[node.Name] {
+			status.RegisteredNodes = append(status.RegisteredNodes, node.Name)
+		} else {
+			status.UnregisteredNodes = append(status.UnregisteredNodes, node.Name)
+		}
+	}
+
+	logger.V(2).Info("vSphere CSI driver status",
+		"registeredNodes", len(status.RegisteredNodes),
+		"unregisteredNodes", len(status.UnregisteredNodes))
+	return status, nil
+}
+
 // IsPVBound checks if a PV is bound to a PVC
 func IsPVBound(pv *corev1.PersistentVolume) bool {
 	return pv.Status.Phase == corev1.VolumeBound && pv.Spec.ClaimRef != nil
 }
 
+// IsSharedBlockVolume reports whether a PV is a raw block volume that may be written from several
+// nodes at once (ReadWriteMany block), as used by clustered workloads sharing a disk
+func IsSharedBlockVolume(pv *corev1.PersistentVolume) bool {
+	return pv.Spec.VolumeMode != nil && *pv.Spec.VolumeMode == corev1.PersistentVolumeBlock &&
+		slices.Contains(pv.Spec.AccessModes, corev1.ReadWriteMany)
+}
+
+// IsUnboundPhase reports whether a PV in the given phase has no bound claim: it is Available,
+// Released after its claim was deleted, or Failed reclamation. Its claimRef, if any, is stale.
+func IsUnboundPhase(phase corev1.PersistentVolumePhase) bool {
+	return phase == corev1.VolumeAvailable || phase == corev1.VolumeReleased || phase == corev1.VolumeFailed
+}
+
+// IsFileVolume reports whether a vSphere CSI volume is a vSAN file share rather than an FCD
+// backed block volume. File shares are NFS exports with no disk to relocate.
+func IsFileVolume(pv VSphereCSIPV) bool {
+	if pv.Attributes["type"] == "vSphere CNS File Volume" {
+		return true
+	}
+	return strings.HasPrefix(pv.VolumeHandle, "file:") && !strings.HasPrefix(pv.VolumeHandle, "file://")
+}
+
+// GetVolumeResizeInProgress reports whether the PVC bound to a PV is being expanded, returning a
+// description of the in-progress resize. Moving the volume mid-resize can lose the expansion or
+// leave the filesystem inconsistent.
+func (m *PersistentVolumeManager) GetVolumeResizeInProgress(ctx context.Context, pvName, pvcNamespace, pvcName string) (string, error) {
+	pvc, err := m.GetPVC(ctx, pvcNamespace, pvcName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PVC %s/%s: %w", pvcNamespace, pvcName, err)
+	}
+
+	pv, err := m.GetPV(ctx, pvName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get PV %s: %w", pvName, err)
+	}
+
+	return VolumeResizeInProgress(pv, pvc), nil
+}
+
+// VolumeResizeInProgress returns a description of an in-progress expansion of the PVC, or an
+// empty string when no resize is pending
+func VolumeResizeInProgress(pv *corev1.PersistentVolume, pvc *corev1.PersistentVolumeClaim) string {
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case corev1.PersistentVolumeClaimResizing, corev1.PersistentVolumeClaimFileSystemResizePending,
+			corev1.PersistentVolumeClaimControllerResizeError, corev1.PersistentVolumeClaimNodeResizeError:
+			return fmt.Sprintf("PVC condition %s is set", condition.Type)
+		}
+	}
+
+	for resource, status := range pvc.Status.AllocatedResourceStatuses {
+		return fmt.Sprintf("PVC %s resize status is %s", resource, status)
+	}
+
+	// An expansion that no controller has picked up yet only shows as a larger request
+	requested, hasRequest := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !hasRequest {
+		return ""
+	}
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && requested.Cmp(capacity) > 0 {
+		return fmt.Sprintf("PVC requests %s but has capacity %s", requested.String(), capacity.String())
+	}
+	if capacity, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok && requested.Cmp(capacity) > 0 {
+		return fmt.Sprintf("PVC requests %s but PV has capacity %s", requested.String(), capacity.String())
+	}
+
+	return ""
+}
+
 // GetPVCFromPV returns the PVC reference from a bound PV
 func GetPVCFromPV(pv *corev1.PersistentVolume) (namespace, name string, ok bool) {
 	if pv.Spec.ClaimRef == nil {
@@ -227,29 +619,38 @@ func GetPVCFromPV(pv *corev1.PersistentVolume) (namespace, name string, ok bool)
 	return pv.Spec.ClaimRef.Namespace, pv.Spec.ClaimRef.Name, true
 }
 
-// WaitForPVAvailable waits for a PV to become Available
-func (m *PersistentVolumeManager) WaitForPVAvailable(ctx context.Context, pvName string) error {
+// WaitForPVAvailable waits up to timeout for a PV to become Available, getting it every interval;
+// a zero interval uses util.FastBackoff
+func (m *PersistentVolumeManager) WaitForPVAvailable(ctx context.Context, pvName string, interval, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
-	logger.V(2).Info("Waiting for PV to become available", "pv", pvName)
-
-	// Simple polling - in production would use informers
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-			pv, err := m.GetPV(ctx, pvName)
-			if err != nil {
-				return err
-			}
+	logger.V(2).Info("Waiting for PV to become available", "pv", pvName, "timeout", timeout)
 
-			if pv.Status.Phase == corev1.VolumeAvailable {
-				return nil
-			}
+	var phase corev1.PersistentVolumePhase
+	condition := func(ctx context.Context) (bool, error) {
+		pv, err := m.GetPV(ctx, pvName)
+		if err != nil {
+			return false, err
+		}
 
-			logger.V(2).Info("PV not yet available", "pv", pvName, "phase", pv.Status.Phase)
+		phase = pv.Status.Phase
+		if phase == corev1.VolumeAvailable {
+			return true, nil
 		}
+
+		logger.V(2).Info("PV not yet available", "pv", pvName, "phase", phase)
+		return false, nil
 	}
+
+	var err error
+	if interval > 0 {
+		err = wait.PollUntilContextTimeout(ctx, interval, timeout, true, condition)
+	} else {
+		err = util.PollUntil(ctx, util.FastBackoff, timeout, condition)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout after %s waiting for PV %s to become Available, phase is %q", timeout, pvName, phase)
+	}
+	return err
 }
 
 // UpdatePVReclaimPolicy updates the reclaim policy of a PV and returns the original policy
@@ -292,7 +693,7 @@ func (m *PersistentVolumeManager) DeletePVC(ctx context.Context, namespace, name
 
 	err := m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			logger.Info("PVC already deleted", "namespace", namespace, "name", name)
 			return nil
 		}
@@ -303,15 +704,18 @@ func (m *PersistentVolumeManager) DeletePVC(ctx context.Context, namespace, name
 	return nil
 }
 
-// WaitForPVCDeleted waits for a PVC to be fully deleted
+// WaitForPVCDeleted waits for a PVC to be fully deleted. A PVC still held by its protection
+// finalizer when the wait times out returns a *PVCDeletionBlockedError naming the pods using it.
 func (m *PersistentVolumeManager) WaitForPVCDeleted(ctx context.Context, namespace, name string, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Waiting for PVC to be deleted", "namespace", namespace, "name", name, "timeout", timeout)
 
-	return wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
-		_, err := m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	var pvc *corev1.PersistentVolumeClaim
+	err := util.PollUntil(ctx, util.FastBackoff, timeout, func(ctx context.Context) (bool, error) {
+		var err error
+		pvc, err = m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
-			if errors.IsNotFound(err) {
+			if apierrors.IsNotFound(err) {
 				logger.Info("PVC deleted", "namespace", namespace, "name", name)
 				return true, nil
 			}
@@ -320,6 +724,26 @@ func (m *PersistentVolumeManager) WaitForPVCDeleted(ctx context.Context, namespa
 		logger.V(2).Info("PVC still exists, waiting...", "namespace", namespace, "name", name)
 		return false, nil
 	})
+	if !errors.Is(err, context.DeadlineExceeded) || pvc == nil {
+		return err
+	}
+
+	if pvc.DeletionTimestamp != nil && slices.Contains(pvc.Finalizers, PVCProtectionFinalizer) {
+		pods, err := m.FindPodsUsingPVC(ctx, namespace, name)
+		if err != nil {
+			return fmt.Errorf("timeout after %s waiting for PVC %s/%s to be deleted: %w", timeout, namespace, name, err)
+		}
+		var blocking []corev1.Pod
+		for _, pod := range pods {
+			if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+				blocking = append(blocking, pod)
+			}
+		}
+		if len(blocking) > 0 {
+			return &PVCDeletionBlockedError{Namespace: namespace, Name: name, Pods: blocking}
+		}
+	}
+	return fmt.Errorf("timeout after %s waiting for PVC %s/%s to be deleted, finalizers %v", timeout, namespace, name, pvc.Finalizers)
 }
 
 // ClearPVClaimRef clears the claimRef on a PV to make it Available for rebinding
@@ -358,6 +782,23 @@ type PVCBackup struct {
 	Resources        corev1.VolumeResourceRequirements   `json:"resources"`
 	Labels           map[string]string                `json:"labels,omitempty"`
 	Annotations      map[string]string                `json:"annotations,omitempty"`
+	DataSource       *corev1.TypedLocalObjectReference `json:"dataSource,omitempty"`
+	DataSourceRef    *corev1.TypedObjectReference      `json:"dataSourceRef,omitempty"`
+}
+
+// DescribeDataSource returns the snapshot or PVC a claim was provisioned from as Kind/[namespace/]name,
+// or an empty string when it has no data source
+func DescribeDataSource(dataSource *corev1.TypedLocalObjectReference, dataSourceRef *corev1.TypedObjectReference) string {
+	if dataSourceRef != nil {
+		if dataSourceRef.Namespace != nil && *dataSourceRef.Namespace != "" {
+			return fmt.Sprintf("%s/%s/%s", dataSourceRef.Kind, *dataSourceRef.Namespace, dataSourceRef.Name)
+		}
+		return fmt.Sprintf("%s/%s", dataSourceRef.Kind, dataSourceRef.Name)
+	}
+	if dataSource != nil {
+		return fmt.Sprintf("%s/%s", dataSource.Kind, dataSource.Name)
+	}
+	return ""
 }
 
 // BackupPVCSpec captures a PVC spec as base64-encoded JSON for later restoration
@@ -377,6 +818,13 @@ func (m *PersistentVolumeManager) BackupPVCSpec(ctx context.Context, namespace,
 		Resources:        pvc.Spec.Resources,
 		Labels:           pvc.Labels,
 		Annotations:      pvc.Annotations,
+		DataSource:       pvc.Spec.DataSource,
+		DataSourceRef:    pvc.Spec.DataSourceRef,
+	}
+
+	if source := DescribeDataSource(pvc.Spec.DataSource, pvc.Spec.DataSourceRef); source != "" {
+		logger.Info("PVC was provisioned from a data source, which is not preserved on restore",
+			"namespace", namespace, "name", name, "dataSource", source)
 	}
 
 	if pvc.Spec.StorageClassName != nil {
@@ -393,20 +841,29 @@ func (m *PersistentVolumeManager) BackupPVCSpec(ctx context.Context, namespace,
 	return encoded, nil
 }
 
-// RestorePVC recreates a PVC from a backup with explicit binding to a specific PV
-func (m *PersistentVolumeManager) RestorePVC(ctx context.Context, pvcSpecBase64 string, targetPVName string) error {
-	logger := klog.FromContext(ctx)
-	logger.Info("Restoring PVC", "targetPV", targetPVName)
-
-	// Decode the backup
+// DecodePVCBackup decodes a PVC backup produced by BackupPVCSpec
+func DecodePVCBackup(pvcSpecBase64 string) (*PVCBackup, error) {
 	jsonData, err := base64.StdEncoding.DecodeString(pvcSpecBase64)
 	if err != nil {
-		return fmt.Errorf("failed to decode PVC backup: %w", err)
+		return nil, fmt.Errorf("failed to decode PVC backup: %w", err)
 	}
 
 	var backup PVCBackup
 	if err := json.Unmarshal(jsonData, &backup); err != nil {
-		return fmt.Errorf("failed to unmarshal PVC backup: %w", err)
+		return nil, fmt.Errorf("failed to unmarshal PVC backup: %w", err)
+	}
+	return &backup, nil
+}
+
+// RestorePVC recreates a PVC from a backup with explicit binding to a specific PV. A non-nil
+// provenance is stamped on the PVC of a migrated volume.
+func (m *PersistentVolumeManager) RestorePVC(ctx context.Context, pvcSpecBase64 string, targetPVName string, provenance *MigrationProvenance) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Restoring PVC", "targetPV", targetPVName)
+
+	backup, err := DecodePVCBackup(pvcSpecBase64)
+	if err != nil {
+		return err
 	}
 
 	// Create new PVC with explicit binding to the target PV
@@ -428,9 +885,22 @@ func (m *PersistentVolumeManager) RestorePVC(ctx context.Context, pvcSpecBase64
 		pvc.Spec.StorageClassName = &backup.StorageClassName
 	}
 
+	// The PVC binds directly to the migrated PV, so its data source is dropped rather than
+	// left to trigger a clone or snapshot restore that no longer applies. The lineage is kept
+	// in an annotation.
+	if source := DescribeDataSource(backup.DataSource, backup.DataSourceRef); source != "" {
+		annotations := make(map[string]string, len(backup.Annotations)+1)
+		maps.Copy(annotations, backup.Annotations)
+		annotations[OriginalDataSourceAnnotation] = source
+		pvc.Annotations = annotations
+		logger.Info("Dropping PVC data source, binding directly to the migrated PV",
+			"namespace", backup.Namespace, "name", backup.Name, "dataSource", source)
+	}
+	pvc.Annotations = provenance.annotate(pvc.Annotations)
+
 	_, err = m.kubeClient.CoreV1().PersistentVolumeClaims(backup.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
 	if err != nil {
-		if errors.IsAlreadyExists(err) {
+		if apierrors.IsAlreadyExists(err) {
 			logger.Info("PVC already exists", "namespace", backup.Namespace, "name", backup.Name)
 			return nil
 		}
@@ -441,12 +911,86 @@ func (m *PersistentVolumeManager) RestorePVC(ctx context.Context, pvcSpecBase64
 	return nil
 }
 
+// RebindPVC binds an existing PVC back to its migrated PV instead of recreating it, for the claims
+// a StatefulSet created from its volumeClaimTemplates: a recreated claim may be taken over by the
+// StatefulSet controller and provisioned against a new, empty volume. The PV's claimRef is reset
+// to the PVC when it was cleared or refers to an earlier claim of the same name. A PVC that no
+// longer exists is created bound to the PV from the PV's spec, and the StatefulSet adopts it by
+// name. A non-nil provenance is stamped on the PVC.
+func (m *PersistentVolumeManager) RebindPVC(ctx context.Context, namespace, name, pvName string, provenance *MigrationProvenance) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Rebinding PVC to migrated PV", "namespace", namespace, "name", name, "pv", pvName)
+
+	pv, err := m.kubeClient.CoreV1().PersistentVolumes().Get(ctx, pvName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PV %s: %w", pvName, err)
+	}
+
+	pvc, err := m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		logger.Info("PVC no longer exists, creating it bound to the migrated PV", "namespace", namespace, "name", name, "pv", pvName)
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   namespace,
+				Annotations: provenance.annotate(nil),
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: pv.Spec.AccessModes,
+				Resources: corev1.VolumeResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: pv.Spec.Capacity[corev1.ResourceStorage]},
+				},
+				VolumeName: pvName,
+				VolumeMode: pv.Spec.VolumeMode,
+			},
+		}
+		if pv.Spec.StorageClassName != "" {
+			pvc.Spec.StorageClassName = &pv.Spec.StorageClassName
+		}
+		pvc, err = m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create PVC %s/%s: %w", namespace, name, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get PVC %s/%s: %w", namespace, name, err)
+	case pvc.Spec.VolumeName != "" && pvc.Spec.VolumeName != pvName:
+		return fmt.Errorf("PVC %s/%s is bound to PV %s, not the migrated PV %s", namespace, name, pvc.Spec.VolumeName, pvName)
+	case provenance != nil:
+		pvc.Annotations = provenance.annotate(pvc.Annotations)
+		pvc, err = m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to update PVC %s/%s: %w", namespace, name, err)
+		}
+	}
+
+	claimRef := pv.Spec.ClaimRef
+	if claimRef != nil && claimRef.Namespace == namespace && claimRef.Name == name && claimRef.UID == pvc.UID {
+		logger.Info("PV is still bound to PVC", "pv", pvName, "namespace", namespace, "name", name)
+		return nil
+	}
+
+	pv.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:       "PersistentVolumeClaim",
+		APIVersion: "v1",
+		Namespace:  namespace,
+		Name:       name,
+		UID:        pvc.UID,
+	}
+	if _, err := m.kubeClient.CoreV1().PersistentVolumes().Update(ctx, pv, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to set claimRef on PV %s: %w", pvName, err)
+	}
+
+	logger.Info("Successfully rebound PVC", "namespace", namespace, "name", name, "pv", pvName)
+	return nil
+}
+
 // WaitForPVCBound waits for a PVC to become Bound
 func (m *PersistentVolumeManager) WaitForPVCBound(ctx context.Context, namespace, name string, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Waiting for PVC to become bound", "namespace", namespace, "name", name, "timeout", timeout)
 
-	return wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+	return util.PollUntil(ctx, util.FastBackoff, timeout, func(ctx context.Context) (bool, error) {
 		pvc, err := m.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			return false, err
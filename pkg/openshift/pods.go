@@ -2,14 +2,20 @@ package openshift
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 // PodManager manages pod operations
@@ -54,45 +60,54 @@ func (m *PodManager) DeletePodsByLabel(ctx context.Context, namespace string, la
 	return count, nil
 }
 
+// ForceDeletePods deletes pods with no grace period, so they are removed from the API without
+// waiting for their kubelet to confirm the containers stopped
+func (m *PodManager) ForceDeletePods(ctx context.Context, pods []corev1.Pod) error {
+	logger := klog.FromContext(ctx)
+
+	for _, pod := range pods {
+		logger.Info("Force deleting pod", "namespace", pod.Namespace, "pod", pod.Name, "node", pod.Spec.NodeName)
+		err := m.client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: ptr.To[int64](0),
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to force delete pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
 // WaitForPodsReady waits for all pods with matching labels to be ready
 func (m *PodManager) WaitForPodsReady(ctx context.Context, namespace string, labelSelector map[string]string, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 
 	selector := labels.SelectorFromSet(labelSelector).String()
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return fmt.Errorf("timeout waiting for pods to be ready")
-			}
-
-			ready, total, err := m.getPodReadyCount(ctx, namespace, selector)
-			if err != nil {
-				logger.V(2).Info("Error getting pod status", "error", err)
-				continue
-			}
-
-			if ready == total && total > 0 {
-				logger.Info("All pods are ready",
-					"namespace", namespace,
-					"ready", ready,
-					"total", total)
-				return nil
-			}
+	err := util.PollUntil(ctx, util.DefaultBackoff, timeout, func(ctx context.Context) (bool, error) {
+		ready, total, err := m.getPodReadyCount(ctx, namespace, selector)
+		if err != nil {
+			logger.V(2).Info("Error getting pod status", "error", err)
+			return false, nil
+		}
 
-			logger.V(2).Info("Waiting for pods to be ready",
+		if ready == total && total > 0 {
+			logger.Info("All pods are ready",
 				"namespace", namespace,
 				"ready", ready,
 				"total", total)
+			return true, nil
 		}
+
+		logger.V(2).Info("Waiting for pods to be ready",
+			"namespace", namespace,
+			"ready", ready,
+			"total", total)
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for pods to be ready")
 	}
+	return err
 }
 
 // getPodReadyCount returns the number of ready and total pods
@@ -305,3 +320,148 @@ func (m *PodManager) WaitForVSpherePodsReady(ctx context.Context, timeout time.D
 	logger.Info("All vSphere pods are ready")
 	return nil
 }
+
+// PendingVolumePod is a pod stuck Pending because the scheduler rejected it for a reason
+// involving a migrated volume
+type PendingVolumePod struct {
+	Namespace string
+	Name      string
+	// Reason is the scheduler's message from the PodScheduled condition
+	Reason string
+	// Since is when the scheduler started rejecting the pod, the condition's last transition
+	Since metav1.Time
+	// Volumes describes the binding state of each migrated PVC and PV the pod uses
+	Volumes []string
+}
+
+// String describes the pod, why it is not scheduled and the state of its migrated volumes
+func (p PendingVolumePod) String() string {
+	return fmt.Sprintf("pod %s/%s: %s [%s]", p.Namespace, p.Name, p.Reason, strings.Join(p.Volumes, "; "))
+}
+
+// PendingPodsOnMigratedVolumes finds Pending pods that the scheduler rejected for a volume reason
+// and that use a PVC migrated by migrationName (namespace/name). A PVC counts as migrated when
+// it, the PV it is bound to, or a PV claiming it carries the migration's provenance annotations,
+// so a PVC that did not rebind to its migrated PV is caught too.
+func (m *PodManager) PendingPodsOnMigratedVolumes(ctx context.Context, migrationName string) ([]PendingVolumePod, error) {
+	pvList, err := m.client.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVs: %w", err)
+	}
+	migratedPVs := make(map[string]*corev1.PersistentVolume)
+	claimedBy := make(map[string]*corev1.PersistentVolume)
+	for i := range pvList.Items {
+		pv := &pvList.Items[i]
+		if pv.Annotations[MigrationNameAnnotation] != migrationName {
+			continue
+		}
+		migratedPVs[pv.Name] = pv
+		if pv.Spec.ClaimRef != nil {
+			claimedBy[pv.Spec.ClaimRef.Namespace+"/"+pv.Spec.ClaimRef.Name] = pv
+		}
+	}
+
+	pods, err := m.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase=" + string(corev1.PodPending),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending pods: %w", err)
+	}
+
+	var pending []PendingVolumePod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		condition := volumeUnschedulableCondition(&pod)
+		if condition == nil {
+			continue
+		}
+
+		var volumes []string
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			key := pod.Namespace + "/" + volume.PersistentVolumeClaim.ClaimName
+			pvc, err := m.client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(ctx, volume.PersistentVolumeClaim.ClaimName, metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get PVC %s: %w", key, err)
+			}
+			if err != nil {
+				pvc = nil
+			}
+
+			var boundPV *corev1.PersistentVolume
+			if pvc != nil && pvc.Spec.VolumeName != "" {
+				boundPV = migratedPVs[pvc.Spec.VolumeName]
+			}
+			claimingPV := claimedBy[key]
+			migrated := boundPV != nil || claimingPV != nil ||
+				(pvc != nil && pvc.Annotations[MigrationNameAnnotation] == migrationName)
+			if !migrated {
+				continue
+			}
+			volumes = append(volumes, describeVolumeBinding(key, pvc, boundPV, claimingPV))
+		}
+		if len(volumes) == 0 {
+			continue
+		}
+
+		pending = append(pending, PendingVolumePod{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Reason:    condition.Message,
+			Since:     condition.LastTransitionTime,
+			Volumes:   volumes,
+		})
+	}
+	return pending, nil
+}
+
+// volumeUnschedulableCondition returns the PodScheduled condition of a pod the scheduler could
+// not place for a volume reason, such as an unbound or missing PVC or a volume node affinity
+// conflict, or nil
+func volumeUnschedulableCondition(pod *corev1.Pod) *corev1.PodCondition {
+	for i, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled || condition.Status != corev1.ConditionFalse ||
+			condition.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		message := strings.ToLower(condition.Message)
+		if strings.Contains(message, "volume") || strings.Contains(message, "persistentvolumeclaim") {
+			return &pod.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// describeVolumeBinding describes the binding state of a migrated PVC and its PV, e.g.
+// "PVC app/data Pending with no volume, PV pvc-1 Available claiming it"
+func describeVolumeBinding(key string, pvc *corev1.PersistentVolumeClaim, boundPV, claimingPV *corev1.PersistentVolume) string {
+	var description string
+	switch {
+	case pvc == nil:
+		description = fmt.Sprintf("PVC %s not found", key)
+	case pvc.Spec.VolumeName == "":
+		description = fmt.Sprintf("PVC %s %s with no volume", key, pvc.Status.Phase)
+	default:
+		description = fmt.Sprintf("PVC %s %s on PV %s", key, pvc.Status.Phase, pvc.Spec.VolumeName)
+	}
+
+	if boundPV != nil {
+		description += fmt.Sprintf(", PV %s %s", boundPV.Name, boundPV.Status.Phase)
+		if ref := boundPV.Spec.ClaimRef; ref == nil {
+			description += " with no claim"
+		} else if ref.Namespace+"/"+ref.Name != key || (pvc != nil && ref.UID != "" && ref.UID != pvc.UID) {
+			description += fmt.Sprintf(" claimed by %s/%s (uid %s)", ref.Namespace, ref.Name, ref.UID)
+		}
+	}
+	if claimingPV != nil && claimingPV != boundPV {
+		description += fmt.Sprintf(", PV %s %s claiming it", claimingPV.Name, claimingPV.Status.Phase)
+		if ref := claimingPV.Spec.ClaimRef; pvc != nil && ref.UID != "" && ref.UID != pvc.UID {
+			description += fmt.Sprintf(" for the earlier PVC uid %s", ref.UID)
+		}
+	}
+	return description
+}
@@ -3,6 +3,8 @@ package openshift
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -115,27 +117,71 @@ func (m *SecretManager) GetCredentials(ctx context.Context, server string) (user
 	return string(usernameBytes), string(passwordBytes), nil
 }
 
-// GetVCenterCredsFromSecret retrieves vCenter credentials from a specific secret
-func (m *SecretManager) GetVCenterCredsFromSecret(ctx context.Context, namespace, name, server string) (username, password string, err error) {
-	secret, err := m.client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+// GetSourceVCenterCreds retrieves the source vCenter credentials the cluster already uses, from
+// the <server>.username and <server>.password keys of the kube-system/vsphere-creds secret.
+// Missing or empty keys are reported by name so the secret can be fixed before anything changes.
+func (m *SecretManager) GetSourceVCenterCreds(ctx context.Context, server string) (username, password string, err error) {
+	secret, err := m.GetVSphereCredsSecret(ctx)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+		return "", "", fmt.Errorf("failed to get secret %s/%s: %w", VSphereCredsSecretNamespace, VSphereCredsSecretName, err)
 	}
 
 	usernameKey := fmt.Sprintf("%s.username", server)
 	passwordKey := fmt.Sprintf("%s.password", server)
 
-	usernameBytes, ok := secret.Data[usernameKey]
-	if !ok {
-		return "", "", fmt.Errorf("username not found for server %s in secret %s/%s (expected key: %s)", server, namespace, name, usernameKey)
+	var missingKeys []string
+	for _, key := range []string{usernameKey, passwordKey} {
+		if len(secret.Data[key]) == 0 {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		return "", "", fmt.Errorf("secret %s/%s has no credentials for source vCenter %s (missing or empty %s)",
+			VSphereCredsSecretNamespace, VSphereCredsSecretName, server, strings.Join(missingKeys, ", "))
 	}
 
-	passwordBytes, ok := secret.Data[passwordKey]
-	if !ok {
-		return "", "", fmt.Errorf("password not found for server %s in secret %s/%s (expected key: %s)", server, namespace, name, passwordKey)
+	return string(secret.Data[usernameKey]), string(secret.Data[passwordKey]), nil
+}
+
+// GetVCenterCredsFromSecret retrieves vCenter credentials from a specific secret using the
+// default {fqdn}.username and {fqdn}.password keys
+func (m *SecretManager) GetVCenterCredsFromSecret(ctx context.Context, namespace, name, server string) (username, password string, err error) {
+	keys, err := NewCredentialKeys(nil)
+	if err != nil {
+		return "", "", err
 	}
+	return NewSecretCredentialProvider(m.client, namespace, name, keys).GetCredentials(ctx, server)
+}
 
-	return string(usernameBytes), string(passwordBytes), nil
+// ValidateCredentialsForServers checks a credentials secret holds a username and password key
+// for every server, so a typo between a failure domain server and the key prefix is caught
+// before anything is changed. The error lists every server missing credentials.
+func (m *SecretManager) ValidateCredentialsForServers(ctx context.Context, namespace, name string, servers []string, keys *CredentialKeys) error {
+	secret, err := m.client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+
+	var missing []string
+	for _, server := range slices.Compact(slices.Sorted(slices.Values(servers))) {
+		usernameKey, passwordKey, err := keys.For(server)
+		if err != nil {
+			return err
+		}
+		var missingKeys []string
+		for _, key := range []string{usernameKey, passwordKey} {
+			if _, ok := secret.Data[key]; !ok {
+				missingKeys = append(missingKeys, key)
+			}
+		}
+		if len(missingKeys) > 0 {
+			missing = append(missing, fmt.Sprintf("%s (missing %s)", server, strings.Join(missingKeys, ", ")))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("secret %s/%s has no credentials for target server(s): %s", namespace, name, strings.Join(missing, "; "))
+	}
+	return nil
 }
 
 // GetTargetVCenterCredentials retrieves the target vCenter credentials secret from the migration spec
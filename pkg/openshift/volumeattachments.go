@@ -2,15 +2,18 @@ package openshift
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	storagev1 "k8s.io/api/storage/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 // VolumeAttachmentManager manages VolumeAttachment operations for CSI volume migration
@@ -70,14 +73,26 @@ func (m *VolumeAttachmentManager) IsVolumeAttached(ctx context.Context, pvName s
 	return true, va.Spec.NodeName, nil
 }
 
+// HasVolumeAttachment reports whether any VolumeAttachment references a PV. Unlike the detach
+// wait, a failure to list VolumeAttachments is returned rather than retried.
+func (m *VolumeAttachmentManager) HasVolumeAttachment(ctx context.Context, pvName string) (bool, error) {
+	va, err := m.GetVolumeAttachmentForPV(ctx, pvName)
+	if err != nil {
+		return false, err
+	}
+	return va != nil, nil
+}
+
 // WaitForVolumeDetached waits for the VolumeAttachment for a PV to be deleted
-// This confirms that the CSI driver has completed the vSphere-level detachment
+// This confirms that the CSI driver has completed the vSphere-level detachment. It returns
+// context.DeadlineExceeded wrapped with the PV once the timeout passes, and the context's
+// error if the wait is cancelled.
 func (m *VolumeAttachmentManager) WaitForVolumeDetached(ctx context.Context, pvName string, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Waiting for VolumeAttachment deletion (confirms vSphere-level detachment)",
 		"pv", pvName, "timeout", timeout)
 
-	return wait.PollUntilContextTimeout(ctx, 3*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+	err := util.PollUntil(ctx, util.FastBackoff, timeout, func(ctx context.Context) (bool, error) {
 		va, err := m.GetVolumeAttachmentForPV(ctx, pvName)
 		if err != nil {
 			// Transient errors should retry
@@ -100,6 +115,78 @@ func (m *VolumeAttachmentManager) WaitForVolumeDetached(ctx context.Context, pvN
 
 		return false, nil
 	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("VolumeAttachment of PV %s still present after %s: %w", pvName, timeout, err)
+	}
+	return err
+}
+
+// WaitForStableAttachmentState waits until no attach of a PV is in flight: apart from those being
+// deleted, the PV has no VolumeAttachment or a single one that is attached. Deleting the PVC while
+// the CSI controller is attaching the volume to a new node races the controller and can leave a
+// dangling VolumeAttachment. Detaches are left to WaitForVolumeDetached, whose callers remediate
+// a detach that is stuck.
+func (m *VolumeAttachmentManager) WaitForStableAttachmentState(ctx context.Context, pvName string, timeout time.Duration) error {
+	logger := klog.FromContext(ctx)
+
+	var transition string
+	err := util.PollUntil(ctx, util.FastBackoff, timeout, func(ctx context.Context) (bool, error) {
+		vaList, err := m.kubeClient.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			// Transient errors should retry
+			logger.V(2).Info("Error listing VolumeAttachments, retrying", "pv", pvName, "error", err)
+			return false, nil
+		}
+
+		var attachments []storagev1.VolumeAttachment
+		for _, va := range vaList.Items {
+			if va.Spec.Source.PersistentVolumeName != nil && *va.Spec.Source.PersistentVolumeName == pvName {
+				attachments = append(attachments, va)
+			}
+		}
+
+		transition = AttachmentTransition(attachments)
+		if transition == "" {
+			return true, nil
+		}
+		logger.V(2).Info("Volume attachment in flight, waiting", "pv", pvName, "transition", transition)
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("volume attachment of PV %s did not settle within %s: %s", pvName, timeout, transition)
+	}
+	return err
+}
+
+// AttachmentTransition describes the attach in flight among the VolumeAttachments of a PV, or
+// returns an empty string when, apart from those being deleted, there are none or a single one
+// that is fully attached. A detaching VolumeAttachment is not an attach in flight: quiesced
+// volumes normally are detaching, and a detach is waited for once the PVC is deleted.
+func AttachmentTransition(attachments []storagev1.VolumeAttachment) string {
+	live := make([]storagev1.VolumeAttachment, 0, len(attachments))
+	for _, va := range attachments {
+		if va.DeletionTimestamp == nil {
+			live = append(live, va)
+		}
+	}
+
+	if len(live) > 1 {
+		nodes := make([]string, 0, len(live))
+		for _, va := range live {
+			nodes = append(nodes, va.Spec.NodeName)
+		}
+		return fmt.Sprintf("%d VolumeAttachments exist, for nodes %s", len(live), strings.Join(nodes, ", "))
+	}
+
+	for _, va := range live {
+		switch {
+		case va.Status.AttachError != nil:
+			return fmt.Sprintf("VolumeAttachment %s is retrying attach to node %s: %s", va.Name, va.Spec.NodeName, va.Status.AttachError.Message)
+		case !va.Status.Attached:
+			return fmt.Sprintf("VolumeAttachment %s is attaching to node %s", va.Name, va.Spec.NodeName)
+		}
+	}
+	return ""
 }
 
 // ListVolumeAttachments lists all VolumeAttachments in the cluster
@@ -115,7 +202,7 @@ func (m *VolumeAttachmentManager) ListVolumeAttachments(ctx context.Context) ([]
 func (m *VolumeAttachmentManager) GetVolumeAttachment(ctx context.Context, name string) (*storagev1.VolumeAttachment, error) {
 	va, err := m.kubeClient.StorageV1().VolumeAttachments().Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("failed to get VolumeAttachment %s: %w", name, err)
@@ -219,7 +306,7 @@ func (m *VolumeAttachmentManager) ForceDetachVolume(ctx context.Context, pvName
 	// Update the VolumeAttachment
 	_, err = m.kubeClient.StorageV1().VolumeAttachments().Update(ctx, va, metav1.UpdateOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			logger.Info("VolumeAttachment already deleted during force-detach", "pv", pvName)
 			return nil
 		}
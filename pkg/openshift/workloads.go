@@ -2,12 +2,16 @@ package openshift
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
@@ -15,11 +19,19 @@ import (
 	"k8s.io/utils/ptr"
 
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
+// ErrQuiesceExcluded is returned by ScaleDownForPV when the PVC is used by a workload in a
+// namespace excluded from quiesce
+var ErrQuiesceExcluded = errors.New("PVC is used by workloads in a namespace excluded from quiesce")
+
 // WorkloadManager manages workload scaling operations for CSI volume migration
 type WorkloadManager struct {
 	kubeClient kubernetes.Interface
+
+	// excludedNamespaces are namespaces whose workloads are never scaled down
+	excludedNamespaces []string
 }
 
 // NewWorkloadManager creates a new workload manager
@@ -29,21 +41,87 @@ func NewWorkloadManager(kubeClient kubernetes.Interface) *WorkloadManager {
 	}
 }
 
+// SetQuiesceExcludeNamespaces sets the namespaces whose workloads ScaleDownForPV refuses to scale down
+func (m *WorkloadManager) SetQuiesceExcludeNamespaces(namespaces []string) {
+	m.excludedNamespaces = namespaces
+}
+
+// pvcWorkloads are the workloads using a PVC
+type pvcWorkloads struct {
+	deployments  []appsv1.Deployment
+	statefulSets []appsv1.StatefulSet
+	replicaSets  []appsv1.ReplicaSet
+}
+
+// names describes the workloads as Kind/name
+func (w *pvcWorkloads) names() []string {
+	var names []string
+	for _, deploy := range w.deployments {
+		names = append(names, "Deployment/"+deploy.Name)
+	}
+	for _, sts := range w.statefulSets {
+		names = append(names, "StatefulSet/"+sts.Name)
+	}
+	for _, rs := range w.replicaSets {
+		names = append(names, "ReplicaSet/"+rs.Name)
+	}
+	return names
+}
+
+// findWorkloadsUsingPVC finds the Deployments, StatefulSets and standalone ReplicaSets using a PVC
+func (m *WorkloadManager) findWorkloadsUsingPVC(ctx context.Context, pvcNamespace, pvcName string) (*pvcWorkloads, error) {
+	deployments, err := m.findDeploymentsUsingPVC(ctx, pvcNamespace, pvcName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deployments: %w", err)
+	}
+	statefulSets, err := m.findStatefulSetsUsingPVC(ctx, pvcNamespace, pvcName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find statefulsets: %w", err)
+	}
+	replicaSets, err := m.findStandaloneReplicaSetsUsingPVC(ctx, pvcNamespace, pvcName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find replicasets: %w", err)
+	}
+	return &pvcWorkloads{deployments: deployments, statefulSets: statefulSets, replicaSets: replicaSets}, nil
+}
+
+// WorkloadsUsingPVC lists the workloads using a PVC as Kind/name
+func (m *WorkloadManager) WorkloadsUsingPVC(ctx context.Context, pvcNamespace, pvcName string) ([]string, error) {
+	workloads, err := m.findWorkloadsUsingPVC(ctx, pvcNamespace, pvcName)
+	if err != nil {
+		return nil, err
+	}
+	return workloads.names(), nil
+}
+
+// QuiesceExcludedError returns an ErrQuiesceExcluded error when a PVC is used by the given
+// workloads in an excluded namespace, or nil when ScaleDownForPV may scale them down
+func (m *WorkloadManager) QuiesceExcludedError(pvcNamespace, pvcName string, workloads []string) error {
+	if len(workloads) > 0 && slices.Contains(m.excludedNamespaces, pvcNamespace) {
+		return fmt.Errorf("%w: PVC %s/%s is used by %s", ErrQuiesceExcluded, pvcNamespace, pvcName, strings.Join(workloads, ", "))
+	}
+	return nil
+}
+
 // ScaleDownForPV scales down all workloads using a specific PVC
-// Returns the list of scaled down resources for later restoration
+// Returns the list of scaled down resources for later restoration. A PVC used by workloads in an
+// excluded namespace is refused with ErrQuiesceExcluded before anything is scaled down.
 func (m *WorkloadManager) ScaleDownForPV(ctx context.Context, pvcNamespace, pvcName string) ([]migrationv1alpha1.ScaledResource, error) {
 	logger := klog.FromContext(ctx)
 	logger.Info("Scaling down workloads for PVC", "namespace", pvcNamespace, "pvc", pvcName)
 
-	var scaledResources []migrationv1alpha1.ScaledResource
-
-	// Find and scale down Deployments
-	deployments, err := m.findDeploymentsUsingPVC(ctx, pvcNamespace, pvcName)
+	workloads, err := m.findWorkloadsUsingPVC(ctx, pvcNamespace, pvcName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find deployments: %w", err)
+		return nil, err
 	}
+	if err := m.QuiesceExcludedError(pvcNamespace, pvcName, workloads.names()); err != nil {
+		return nil, err
+	}
+
+	var scaledResources []migrationv1alpha1.ScaledResource
 
-	for _, deploy := range deployments {
+	// Scale down Deployments
+	for _, deploy := range workloads.deployments {
 		if deploy.Spec.Replicas != nil && *deploy.Spec.Replicas > 0 {
 			originalReplicas := *deploy.Spec.Replicas
 			logger.Info("Scaling down Deployment", "name", deploy.Name, "namespace", deploy.Namespace, "replicas", originalReplicas)
@@ -61,13 +139,8 @@ func (m *WorkloadManager) ScaleDownForPV(ctx context.Context, pvcNamespace, pvcN
 		}
 	}
 
-	// Find and scale down StatefulSets
-	statefulSets, err := m.findStatefulSetsUsingPVC(ctx, pvcNamespace, pvcName)
-	if err != nil {
-		return scaledResources, fmt.Errorf("failed to find statefulsets: %w", err)
-	}
-
-	for _, sts := range statefulSets {
+	// Scale down StatefulSets
+	for _, sts := range workloads.statefulSets {
 		if sts.Spec.Replicas != nil && *sts.Spec.Replicas > 0 {
 			originalReplicas := *sts.Spec.Replicas
 			logger.Info("Scaling down StatefulSet", "name", sts.Name, "namespace", sts.Namespace, "replicas", originalReplicas)
@@ -76,22 +149,24 @@ func (m *WorkloadManager) ScaleDownForPV(ctx context.Context, pvcNamespace, pvcN
 				return scaledResources, fmt.Errorf("failed to scale statefulset %s: %w", sts.Name, err)
 			}
 
-			scaledResources = append(scaledResources, migrationv1alpha1.ScaledResource{
+			// The update strategy decides whether the StatefulSet controller replaces the
+			// pods left behind once it is scaled back up
+			scaled := migrationv1alpha1.ScaledResource{
 				Kind:             "StatefulSet",
 				Name:             sts.Name,
 				Namespace:        sts.Namespace,
 				OriginalReplicas: originalReplicas,
-			})
+				UpdateStrategy:   string(sts.Spec.UpdateStrategy.Type),
+			}
+			if rollingUpdate := sts.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil {
+				scaled.Partition = ptr.To(*rollingUpdate.Partition)
+			}
+			scaledResources = append(scaledResources, scaled)
 		}
 	}
 
-	// Find and scale down ReplicaSets (standalone, not owned by Deployments)
-	replicaSets, err := m.findStandaloneReplicaSetsUsingPVC(ctx, pvcNamespace, pvcName)
-	if err != nil {
-		return scaledResources, fmt.Errorf("failed to find replicasets: %w", err)
-	}
-
-	for _, rs := range replicaSets {
+	// Scale down ReplicaSets (standalone, not owned by Deployments)
+	for _, rs := range workloads.replicaSets {
 		if rs.Spec.Replicas != nil && *rs.Spec.Replicas > 0 {
 			originalReplicas := *rs.Spec.Replicas
 			logger.Info("Scaling down ReplicaSet", "name", rs.Name, "namespace", rs.Namespace, "replicas", originalReplicas)
@@ -152,48 +227,97 @@ func (m *WorkloadManager) RestoreWorkloads(ctx context.Context, scaledResources
 	return nil
 }
 
+// ReplacePlaceholderPods deletes the pods of a restored StatefulSet that were created before the
+// given time and never started, such as a pod left Pending on a claim that was being migrated.
+// The StatefulSet controller does not replace an existing pod under the OnDelete update strategy
+// or below the partition of a rolling update, so without this such a pod would never mount the
+// migrated volume. It returns the names of the deleted pods.
+func (m *WorkloadManager) ReplacePlaceholderPods(ctx context.Context, resource migrationv1alpha1.ScaledResource, createdBefore time.Time) ([]string, error) {
+	if resource.Kind != "StatefulSet" || !keepsExistingPods(resource, 0) {
+		return nil, nil
+	}
+	logger := klog.FromContext(ctx)
+
+	pods, err := m.kubeClient.CoreV1().Pods(resource.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", resource.Namespace, err)
+	}
+
+	var deleted []string
+	for _, pod := range pods.Items {
+		owner := metav1.GetControllerOf(&pod)
+		if owner == nil || owner.Kind != "StatefulSet" || owner.Name != resource.Name {
+			continue
+		}
+		if pod.Status.Phase != corev1.PodPending || pod.DeletionTimestamp != nil || !pod.CreationTimestamp.Time.Before(createdBefore) {
+			continue
+		}
+		ordinal, err := strconv.Atoi(strings.TrimPrefix(pod.Name, resource.Name+"-"))
+		if err != nil || !keepsExistingPods(resource, ordinal) {
+			continue
+		}
+
+		logger.Info("Deleting placeholder pod of StatefulSet so it is recreated against the migrated volume",
+			"pod", pod.Name,
+			"statefulSet", resource.Name,
+			"namespace", resource.Namespace,
+			"updateStrategy", resource.UpdateStrategy)
+		if err := m.kubeClient.CoreV1().Pods(resource.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return deleted, fmt.Errorf("failed to delete placeholder pod %s/%s: %w", resource.Namespace, pod.Name, err)
+		}
+		deleted = append(deleted, pod.Name)
+	}
+	return deleted, nil
+}
+
+// keepsExistingPods reports whether the StatefulSet controller leaves the existing pod of a
+// replica in place rather than replacing it: always under OnDelete, and below the partition of a
+// rolling update. An ordinal of 0 asks whether it keeps any pod at all.
+func keepsExistingPods(resource migrationv1alpha1.ScaledResource, ordinal int) bool {
+	if resource.UpdateStrategy == string(appsv1.OnDeleteStatefulSetStrategyType) {
+		return true
+	}
+	return resource.Partition != nil && int(*resource.Partition) > ordinal
+}
+
 // WaitForPodsTerminated waits for all pods using a PVC to terminate
 func (m *WorkloadManager) WaitForPodsTerminated(ctx context.Context, pvcNamespace, pvcName string, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Waiting for pods to terminate", "namespace", pvcNamespace, "pvc", pvcName)
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
 	pvManager := NewPersistentVolumeManager(m.kubeClient)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return fmt.Errorf("timeout waiting for pods to terminate")
-			}
-
-			pods, err := pvManager.FindPodsUsingPVC(ctx, pvcNamespace, pvcName)
-			if err != nil {
-				logger.V(2).Info("Error listing pods", "error", err)
-				continue
-			}
+	err := util.PollUntil(ctx, util.DefaultBackoff, timeout, func(ctx context.Context) (bool, error) {
+		pods, err := pvManager.FindPodsUsingPVC(ctx, pvcNamespace, pvcName)
+		if err != nil {
+			logger.V(2).Info("Error listing pods", "error", err)
+			return false, nil
+		}
 
-			// Filter out terminated pods
-			activePods := 0
-			for _, pod := range pods {
-				if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
-					activePods++
-				}
-			}
+		activePods := CountActivePods(pods)
+		if activePods == 0 {
+			logger.Info("All pods using PVC have terminated", "namespace", pvcNamespace, "pvc", pvcName)
+			return true, nil
+		}
 
-			if activePods == 0 {
-				logger.Info("All pods using PVC have terminated", "namespace", pvcNamespace, "pvc", pvcName)
-				return nil
-			}
+		logger.V(2).Info("Waiting for pods to terminate", "activePods", activePods)
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for pods to terminate")
+	}
+	return err
+}
 
-			logger.V(2).Info("Waiting for pods to terminate", "activePods", activePods)
+// CountActivePods counts the pods that have not terminated
+func CountActivePods(pods []corev1.Pod) int {
+	active := 0
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			active++
 		}
 	}
+	return active
 }
 
 // WaitForWorkloadsReady waits for restored workloads to become ready
@@ -201,38 +325,41 @@ func (m *WorkloadManager) WaitForWorkloadsReady(ctx context.Context, scaledResou
 	logger := klog.FromContext(ctx)
 	logger.Info("Waiting for workloads to become ready", "count", len(scaledResources))
 
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) {
-				return fmt.Errorf("timeout waiting for workloads to become ready")
+	err := util.PollUntil(ctx, util.DefaultBackoff, timeout, func(ctx context.Context) (bool, error) {
+		allReady := true
+		for _, resource := range scaledResources {
+			ready, err := m.isWorkloadReady(ctx, resource)
+			if err != nil {
+				logger.V(2).Info("Error checking workload readiness", "error", err)
+				allReady = false
+				continue
 			}
-
-			allReady := true
-			for _, resource := range scaledResources {
-				ready, err := m.isWorkloadReady(ctx, resource)
-				if err != nil {
-					logger.V(2).Info("Error checking workload readiness", "error", err)
-					allReady = false
-					continue
-				}
-				if !ready {
-					allReady = false
-				}
+			if !ready {
+				allReady = false
 			}
+		}
 
-			if allReady {
-				logger.Info("All workloads are ready")
-				return nil
-			}
+		if allReady {
+			logger.Info("All workloads are ready")
 		}
+		return allReady, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for workloads to become ready")
 	}
+	return err
+}
+
+// UnreadyWorkloads returns the restored workloads, as <Kind>/<namespace>/<name>, that do not have
+// all of their original replicas ready. A workload whose readiness cannot be read is unready.
+func (m *WorkloadManager) UnreadyWorkloads(ctx context.Context, scaledResources []migrationv1alpha1.ScaledResource) []string {
+	var unready []string
+	for _, resource := range scaledResources {
+		if ready, err := m.isWorkloadReady(ctx, resource); err != nil || !ready {
+			unready = append(unready, fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Namespace, resource.Name))
+		}
+	}
+	return unready
 }
 
 // findDeploymentsUsingPVC finds all Deployments using a specific PVC
@@ -261,21 +388,45 @@ func (m *WorkloadManager) findStatefulSetsUsingPVC(ctx context.Context, namespac
 
 	var usingSTS []appsv1.StatefulSet
 	for _, sts := range stsList.Items {
-		if m.podTemplateUsesPVC(&sts.Spec.Template, pvcName) {
+		if m.podTemplateUsesPVC(&sts.Spec.Template, pvcName) || statefulSetClaimsPVC(&sts, pvcName) {
 			usingSTS = append(usingSTS, sts)
 		}
-		// Also check volumeClaimTemplates for StatefulSets
-		for _, vct := range sts.Spec.VolumeClaimTemplates {
-			if vct.Name == pvcName {
-				usingSTS = append(usingSTS, sts)
-				break
-			}
-		}
 	}
 
 	return usingSTS, nil
 }
 
+// FindStatefulSetForPVC returns the StatefulSet whose volumeClaimTemplates created a PVC,
+// or nil if the PVC is not a per-replica StatefulSet claim
+func (m *WorkloadManager) FindStatefulSetForPVC(ctx context.Context, namespace, pvcName string) (*appsv1.StatefulSet, error) {
+	stsList, err := m.kubeClient.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets in %s: %w", namespace, err)
+	}
+
+	for i := range stsList.Items {
+		if statefulSetClaimsPVC(&stsList.Items[i], pvcName) {
+			return &stsList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// statefulSetClaimsPVC checks if a PVC is one of the per-replica claims a StatefulSet creates
+// from its volumeClaimTemplates, which are named <template>-<statefulset>-<ordinal>
+func statefulSetClaimsPVC(sts *appsv1.StatefulSet, pvcName string) bool {
+	for _, vct := range sts.Spec.VolumeClaimTemplates {
+		ordinal, found := strings.CutPrefix(pvcName, vct.Name+"-"+sts.Name+"-")
+		if !found {
+			continue
+		}
+		if _, err := strconv.ParseUint(ordinal, 10, 32); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
 // findStandaloneReplicaSetsUsingPVC finds ReplicaSets not owned by Deployments
 func (m *WorkloadManager) findStandaloneReplicaSetsUsingPVC(ctx context.Context, namespace, pvcName string) ([]appsv1.ReplicaSet, error) {
 	rsList, err := m.kubeClient.AppsV1().ReplicaSets(namespace).List(ctx, metav1.ListOptions{})
@@ -322,7 +473,7 @@ func hasOwnerOfKind(owners []metav1.OwnerReference, kind string) bool {
 func (m *WorkloadManager) scaleDeployment(ctx context.Context, namespace, name string, replicas int32) error {
 	deploy, err := m.kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			return nil // Deployment was deleted, nothing to scale
 		}
 		return err
@@ -337,7 +488,7 @@ func (m *WorkloadManager) scaleDeployment(ctx context.Context, namespace, name s
 func (m *WorkloadManager) scaleStatefulSet(ctx context.Context, namespace, name string, replicas int32) error {
 	sts, err := m.kubeClient.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			return nil
 		}
 		return err
@@ -352,7 +503,7 @@ func (m *WorkloadManager) scaleStatefulSet(ctx context.Context, namespace, name
 func (m *WorkloadManager) scaleReplicaSet(ctx context.Context, namespace, name string, replicas int32) error {
 	rs, err := m.kubeClient.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
+		if apierrors.IsNotFound(err) {
 			return nil
 		}
 		return err
@@ -406,7 +557,7 @@ func (m *WorkloadManager) DeletePodsUsingPVC(ctx context.Context, pvcNamespace,
 	for _, pod := range pods {
 		logger.Info("Deleting pod", "name", pod.Name, "namespace", pod.Namespace)
 		err := m.kubeClient.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
-		if err != nil && !errors.IsNotFound(err) {
+		if err != nil && !apierrors.IsNotFound(err) {
 			return fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
 		}
 	}
@@ -0,0 +1,99 @@
+// Package phaseerrors classifies phase failures so the controller can decide how to handle them:
+// transient failures are retried, data safety failures are never rolled back automatically, and
+// validation and unrecoverable failures fail the phase without retrying.
+package phaseerrors
+
+import "errors"
+
+var (
+	// ErrValidation marks a failure caused by the migration spec or cluster configuration; retrying
+	// does not help until the configuration changes
+	ErrValidation = errors.New("validation error")
+
+	// ErrTransient marks a failure expected to clear on its own, such as a dropped vCenter connection
+	ErrTransient = errors.New("transient error")
+
+	// ErrDataSafety marks a failure that left volumes or workloads in a state only an operator
+	// should act on, such as workloads kept scaled down after a failed vMotion
+	ErrDataSafety = errors.New("data safety error")
+
+	// ErrUnrecoverable marks a failure retrying cannot fix
+	ErrUnrecoverable = errors.New("unrecoverable error")
+)
+
+// Class names, as recorded in status
+const (
+	ClassValidation    = "Validation"
+	ClassTransient     = "Transient"
+	ClassDataSafety    = "DataSafety"
+	ClassUnrecoverable = "Unrecoverable"
+)
+
+// classifiedError attaches a classification to an error without changing its message
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.class, e.err}
+}
+
+// Validation classifies err as a validation error
+func Validation(err error) error {
+	return classify(ErrValidation, err)
+}
+
+// Transient classifies err as a transient error
+func Transient(err error) error {
+	return classify(ErrTransient, err)
+}
+
+// DataSafety classifies err as a data safety error
+func DataSafety(err error) error {
+	return classify(ErrDataSafety, err)
+}
+
+// Unrecoverable classifies err as an unrecoverable error
+func Unrecoverable(err error) error {
+	return classify(ErrUnrecoverable, err)
+}
+
+func classify(class, err error) error {
+	if err == nil || errors.Is(err, class) {
+		return err
+	}
+	return &classifiedError{class: class, err: err}
+}
+
+// Class returns the classification of err, or nil if it has none. An error carrying several
+// classifications reports the most cautious one: data safety, then unrecoverable, then
+// validation, then transient.
+func Class(err error) error {
+	for _, class := range []error{ErrDataSafety, ErrUnrecoverable, ErrValidation, ErrTransient} {
+		if errors.Is(err, class) {
+			return class
+		}
+	}
+	return nil
+}
+
+// ClassName returns the status name of the classification of err, or "" if it has none
+func ClassName(err error) string {
+	switch Class(err) {
+	case ErrDataSafety:
+		return ClassDataSafety
+	case ErrUnrecoverable:
+		return ClassUnrecoverable
+	case ErrValidation:
+		return ClassValidation
+	case ErrTransient:
+		return ClassTransient
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,226 @@
+// Package report renders the status of a finished migration into a summary stored in a ConfigMap,
+// which outlives the migration resource for audit and handoff.
+package report
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+)
+
+// Keys of the report ConfigMap. Each holds one record per line as space separated key=value
+// fields, so a report can be searched with grep.
+const (
+	KeySummary            = "summary"
+	KeyVCenters           = "vcenters"
+	KeyPhases             = "phases"
+	KeyVolumes            = "volumes"
+	KeyManualIntervention = "manualIntervention"
+)
+
+// ConfigMapName returns the name of the report ConfigMap of a migration
+func ConfigMapName(migrationName string) string {
+	return migrationName + "-report"
+}
+
+// IsTerminal reports whether a migration phase ends the migration, so its report is final
+func IsTerminal(phase migrationv1alpha1.MigrationPhase) bool {
+	return phase == migrationv1alpha1.PhaseCompleted || phase == migrationv1alpha1.PhaseFailed ||
+		phase == migrationv1alpha1.PhaseCancelled
+}
+
+// Generate renders the status of a migration into the data of its report ConfigMap: the outcome,
+// the vCenters involved, the phases executed, the result of each CSI volume and the items that
+// need manual intervention
+func Generate(migration *migrationv1alpha1.VmwareCloudFoundationMigration) map[string]string {
+	return map[string]string{
+		KeySummary:            summary(migration),
+		KeyVCenters:           vCenters(migration),
+		KeyPhases:             phases(migration),
+		KeyVolumes:            volumes(migration),
+		KeyManualIntervention: manualIntervention(migration),
+	}
+}
+
+func summary(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	status := migration.Status
+	fields := []string{
+		"migration", migration.Namespace + "/" + migration.Name,
+		"result", string(status.Phase),
+		"start", formatTime(status.StartTime),
+		"completion", formatTime(status.CompletionTime),
+		"duration", formatDuration(status.StartTime, status.CompletionTime),
+	}
+	if status.Phase == migrationv1alpha1.PhaseFailed {
+		for i := len(status.PhaseHistory) - 1; i >= 0; i-- {
+			if entry := status.PhaseHistory[i]; entry.Status == migrationv1alpha1.PhaseStatusFailed {
+				fields = append(fields, "failedPhase", string(entry.Phase), "message", entry.Message)
+				break
+			}
+		}
+	}
+	if cancellation := status.Cancellation; cancellation != nil {
+		remaining := make([]string, 0, len(cancellation.RemainingPhases))
+		for _, phase := range cancellation.RemainingPhases {
+			remaining = append(remaining, string(phase))
+		}
+		fields = append(fields,
+			"cancelledPhase", string(cancellation.CancelledPhase),
+			"remainingPhases", strings.Join(remaining, ","),
+			"restoredWorkloads", strings.Join(cancellation.RestoredWorkloads, ","),
+			"deletedDummyVMs", strings.Join(cancellation.DeletedDummyVMs, ","),
+			"settledMachineSets", strings.Join(cancellation.SettledMachineSets, ","))
+	}
+	if csi := status.CSIVolumeMigration; csi != nil {
+		fields = append(fields,
+			"totalVolumes", strconv.Itoa(int(csi.TotalVolumes)),
+			"migratedVolumes", strconv.Itoa(int(csi.MigratedVolumes)),
+			"failedVolumes", strconv.Itoa(int(csi.FailedVolumes)),
+			"skippedVolumes", strconv.Itoa(int(csi.SkippedVolumes)))
+	}
+	return line(fields...)
+}
+
+func vCenters(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	source := migration.Status.SourceVCenter
+	if source == "" {
+		source = migration.Spec.SourceVCenterServer
+	}
+
+	lines := []string{line("role", "source", "server", source)}
+	for _, fd := range migration.Spec.FailureDomains {
+		lines = append(lines, line(
+			"role", "target",
+			"failureDomain", fd.Name,
+			"server", fd.Server,
+			"region", fd.Region,
+			"zone", fd.Zone,
+			"datacenter", fd.Topology.Datacenter,
+			"cluster", fd.Topology.ComputeCluster,
+			"datastore", fd.Topology.Datastore,
+			"folder", fd.Topology.Folder))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func phases(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	lines := make([]string, 0, len(migration.Status.PhaseHistory))
+	for _, entry := range migration.Status.PhaseHistory {
+		lines = append(lines, line(
+			"phase", string(entry.Phase),
+			"status", string(entry.Status),
+			"start", formatTime(&entry.StartTime),
+			"completion", formatTime(entry.CompletionTime),
+			"duration", formatDuration(&entry.StartTime, entry.CompletionTime),
+			"message", entry.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func volumes(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	csi := migration.Status.CSIVolumeMigration
+	if csi == nil {
+		return ""
+	}
+
+	lines := make([]string, 0, len(csi.Volumes))
+	for _, vol := range csi.Volumes {
+		duration := ""
+		if vol.Duration != nil {
+			duration = vol.Duration.Duration.String()
+		}
+		pvc := ""
+		if vol.PVCName != "" {
+			pvc = vol.PVCNamespace + "/" + vol.PVCName
+		}
+		lines = append(lines, line(
+			"pv", vol.PVName,
+			"pvc", pvc,
+			"status", vol.Status,
+			"sourceVolumeHandle", vol.SourceVolumePath,
+			"targetVolumeID", vol.TargetVolumeID,
+			"targetVolumePath", vol.TargetVolumePath,
+			"duration", duration,
+			"message", vol.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func manualIntervention(migration *migrationv1alpha1.VmwareCloudFoundationMigration) string {
+	var lines []string
+	if cancellation := migration.Status.Cancellation; cancellation != nil {
+		for _, item := range cancellation.ManualIntervention {
+			lines = append(lines, line("cancellation", item))
+		}
+	}
+
+	csi := migration.Status.CSIVolumeMigration
+	if csi == nil {
+		return strings.Join(lines, "\n")
+	}
+
+	for _, item := range csi.ManualInterventionRequired {
+		scaledDown := make([]string, 0, len(item.ScaledDownResources))
+		for _, resource := range item.ScaledDownResources {
+			scaledDown = append(scaledDown, fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Namespace, resource.Name))
+		}
+		lines = append(lines, line(
+			"pv", item.PVName,
+			"failedStep", item.FailedStep,
+			"error", item.Error,
+			"scaledDown", strings.Join(scaledDown, ","),
+			"hint", item.Hint))
+	}
+	for _, item := range csi.MissingSourceVolumes {
+		scaledDown := make([]string, 0, len(item.ScaledDownResources))
+		for _, resource := range item.ScaledDownResources {
+			scaledDown = append(scaledDown, fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Namespace, resource.Name))
+		}
+		lines = append(lines, line(
+			"pv", item.PVName,
+			"sourceMissing", "true",
+			"foundAtStep", item.FailedStep,
+			"error", item.Error,
+			"scaledDown", strings.Join(scaledDown, ","),
+			"hint", item.Hint))
+	}
+	for _, workload := range csi.PartiallyMigratedWorkloads {
+		lines = append(lines, line(
+			"workloadGroup", workload.WorkloadGroup,
+			"migratedVolumes", strings.Join(workload.MigratedVolumes, ","),
+			"unmigratedVolumes", strings.Join(workload.UnmigratedVolumes, ",")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// line joins key/value pairs into one record, quoting values that are empty or contain spaces
+func line(pairs ...string) string {
+	fields := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := pairs[i+1]
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		fields = append(fields, pairs[i]+"="+value)
+	}
+	return strings.Join(fields, " ")
+}
+
+func formatTime(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatDuration(start, end *metav1.Time) string {
+	if start == nil || end == nil || start.IsZero() || end.IsZero() {
+		return ""
+	}
+	return end.Sub(start.Time).Round(time.Second).String()
+}
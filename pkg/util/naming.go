@@ -0,0 +1,161 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"text/template"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+)
+
+const (
+	// DefaultDummyVMNameTemplate names the dummy VMs used to vMotion volumes
+	DefaultDummyVMNameTemplate = "csi-migration-{{.InfraID}}-{{.PVName}}"
+
+	// DefaultWorkerMachineSetNameTemplate names the worker MachineSets created in the target vCenter
+	DefaultWorkerMachineSetNameTemplate = "{{.InfraID}}-worker-{{.FailureDomain}}"
+
+	// MaxVSphereVMNameLength is the longest VM name vCenter accepts
+	MaxVSphereVMNameLength = 80
+
+	// MaxMachineSetNameLength keeps MachineSet names usable as label values on their Machines
+	MaxMachineSetNameLength = validation.LabelValueMaxLength
+
+	// nameHashLength is the number of hex characters of the hash appended to truncated names
+	nameHashLength = 8
+)
+
+// NameParams are the placeholders available to naming templates
+type NameParams struct {
+	InfraID       string
+	PVName        string
+	FailureDomain string
+}
+
+// DummyVMName renders the dummy VM name for a volume from the naming template or its default
+func DummyVMName(naming *migrationv1alpha1.NamingTemplate, params NameParams) (string, error) {
+	tmpl := DefaultDummyVMNameTemplate
+	if naming != nil && naming.DummyVM != "" {
+		tmpl = naming.DummyVM
+	}
+
+	name, err := RenderName(tmpl, params, MaxVSphereVMNameLength)
+	if err != nil {
+		return "", fmt.Errorf("invalid dummy VM naming template: %w", err)
+	}
+	if err := ValidateVSphereVMName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// DummyVMNamePrefix returns the part of the dummy VM names of a cluster that precedes the PV
+// name, used to find dummy VMs left behind by earlier runs. Templates that do not start with a
+// fixed prefix ahead of the PV name are rejected, as every VM would match.
+func DummyVMNamePrefix(naming *migrationv1alpha1.NamingTemplate, infraID string) (string, error) {
+	tmpl := DefaultDummyVMNameTemplate
+	if naming != nil && naming.DummyVM != "" {
+		tmpl = naming.DummyVM
+	}
+
+	// The marker cannot appear in a rendered name since it is not a valid VM name character.
+	// The name is rendered untruncated so the marker is kept.
+	const marker = "/"
+	name, err := RenderName(tmpl, NameParams{InfraID: infraID, PVName: marker}, math.MaxInt)
+	if err != nil {
+		return "", fmt.Errorf("invalid dummy VM naming template: %w", err)
+	}
+	prefix, _, found := strings.Cut(name, marker)
+	if !found || prefix == "" {
+		return "", fmt.Errorf("dummy VM naming template %q has no fixed prefix before {{.PVName}}", tmpl)
+	}
+
+	// Truncated names keep only the start of the prefix, see TruncateName
+	if keep := MaxVSphereVMNameLength - nameHashLength - 1; len(prefix) > keep {
+		prefix = strings.TrimRight(prefix[:keep], "-.")
+	}
+	return prefix, nil
+}
+
+// RetainedDummyVMName returns the name a dummy VM kept for inspecting a failed relocation is
+// renamed to, so a retry of its volumes can create their dummy VM under the original name. The
+// start of the original name is kept, so the VM is still found by the dummy VM name prefix.
+func RetainedDummyVMName(name string, failedAt time.Time) string {
+	return TruncateName(name+"-failed-"+failedAt.UTC().Format("20060102-150405"), MaxVSphereVMNameLength)
+}
+
+// WorkerMachineSetName renders the worker MachineSet name for a failure domain from the naming template or its default
+func WorkerMachineSetName(naming *migrationv1alpha1.NamingTemplate, params NameParams) (string, error) {
+	tmpl := DefaultWorkerMachineSetNameTemplate
+	if naming != nil && naming.WorkerMachineSet != "" {
+		tmpl = naming.WorkerMachineSet
+	}
+
+	name, err := RenderName(tmpl, params, MaxMachineSetNameLength)
+	if err != nil {
+		return "", fmt.Errorf("invalid worker MachineSet naming template: %w", err)
+	}
+	if err := ValidateMachineSetName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// RenderName executes a naming template and truncates the result to maxLen
+func RenderName(tmpl string, params NameParams, maxLen int) (string, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %q: %w", tmpl, err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, params); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", tmpl, err)
+	}
+
+	return TruncateName(b.String(), maxLen), nil
+}
+
+// TruncateName shortens a name to maxLen, replacing the tail with a hash of the full name
+// so distinct long names sharing a prefix do not collide
+func TruncateName(name string, maxLen int) string {
+	if len(name) <= maxLen {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:nameHashLength]
+	prefix := strings.TrimRight(name[:maxLen-nameHashLength-1], "-.")
+	return prefix + "-" + hash
+}
+
+// ValidateVSphereVMName checks a name against the vCenter inventory name constraints
+func ValidateVSphereVMName(name string) error {
+	if name == "" {
+		return fmt.Errorf("VM name must not be empty")
+	}
+	if len(name) > MaxVSphereVMNameLength {
+		return fmt.Errorf("VM name %q is longer than %d characters", name, MaxVSphereVMNameLength)
+	}
+	if strings.ContainsAny(name, `/\%`) {
+		return fmt.Errorf("VM name %q must not contain '/', '\\' or '%%'", name)
+	}
+	return nil
+}
+
+// ValidateMachineSetName checks a name against the Kubernetes object name and label value constraints
+func ValidateMachineSetName(name string) error {
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("MachineSet name %q is invalid: %s", name, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(name); len(errs) > 0 {
+		return fmt.Errorf("MachineSet name %q is invalid: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
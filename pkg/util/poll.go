@@ -0,0 +1,34 @@
+package util
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Backoffs for the polling loops, keyed by how quickly the awaited operation usually completes.
+// Each wait is Factor times the previous one up to Cap, extended by a random fraction of up to
+// Jitter of itself so concurrent pollers spread out. Steps is unbounded; the wait only ends with
+// the timeout given to PollUntil.
+var (
+	// FastBackoff suits API object transitions that usually settle within seconds
+	FastBackoff = wait.Backoff{Duration: time.Second, Factor: 1.5, Jitter: 0.2, Steps: math.MaxInt32, Cap: 10 * time.Second}
+
+	// DefaultBackoff suits vCenter tasks and pod or workload transitions
+	DefaultBackoff = wait.Backoff{Duration: 2 * time.Second, Factor: 1.5, Jitter: 0.2, Steps: math.MaxInt32, Cap: 30 * time.Second}
+
+	// SlowBackoff suits machine provisioning and control plane rollouts that take minutes
+	SlowBackoff = wait.Backoff{Duration: 5 * time.Second, Factor: 2, Jitter: 0.2, Steps: math.MaxInt32, Cap: 60 * time.Second}
+)
+
+// PollUntil calls condition immediately and then after each backoff interval until it reports
+// done or returns an error. Like wait.PollUntilContextTimeout, it returns
+// context.DeadlineExceeded once the timeout has elapsed and the context's error when ctx is
+// cancelled.
+func PollUntil(ctx context.Context, backoff wait.Backoff, timeout time.Duration, condition wait.ConditionWithContextFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return backoff.DelayFunc().Until(ctx, true, true, condition)
+}
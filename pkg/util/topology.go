@@ -0,0 +1,131 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// Datacenter sub-folders that failure domain topology paths live under
+const (
+	HostFolder      = "host"
+	DatastoreFolder = "datastore"
+	VMFolder        = "vm"
+)
+
+// resourcePoolRoot is the root resource pool of every compute cluster
+const resourcePoolRoot = "Resources"
+
+// NormalizeInventoryPath canonicalizes a topology path to the absolute inventory path the finder
+// expects. Absolute paths are kept as given. Relative paths are expanded under the datacenter,
+// so cluster1 becomes /DC/host/cluster1, host/cluster1 becomes /DC/host/cluster1 and
+// DC/host/cluster1 becomes /DC/host/cluster1.
+func NormalizeInventoryPath(datacenter, folder, p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" || datacenter == "" {
+		return p
+	}
+	if strings.HasPrefix(p, "/") {
+		return path.Clean(p)
+	}
+
+	p = strings.TrimSuffix(p, "/")
+	switch first, _, _ := strings.Cut(p, "/"); first {
+	case datacenter:
+		return path.Clean("/" + p)
+	case folder:
+		return path.Clean("/" + datacenter + "/" + p)
+	default:
+		return path.Clean("/" + datacenter + "/" + folder + "/" + p)
+	}
+}
+
+// NormalizeResourcePoolPath canonicalizes a resource pool path. A relative pool is taken to be
+// under the root resource pool of the compute cluster, so pool1 becomes
+// /DC/host/cluster1/Resources/pool1.
+func NormalizeResourcePoolPath(datacenter, clusterPath, p string) string {
+	p = strings.TrimSpace(p)
+	if p == "" || datacenter == "" || clusterPath == "" || strings.HasPrefix(p, "/") {
+		return NormalizeInventoryPath(datacenter, HostFolder, p)
+	}
+
+	p = strings.TrimSuffix(p, "/")
+	first, _, _ := strings.Cut(p, "/")
+	switch first {
+	case datacenter, HostFolder:
+		return NormalizeInventoryPath(datacenter, HostFolder, p)
+	case resourcePoolRoot:
+		return path.Clean(clusterPath + "/" + p)
+	default:
+		return path.Clean(clusterPath + "/" + resourcePoolRoot + "/" + p)
+	}
+}
+
+// FailureDomainFolder returns the absolute VM folder of a failure domain topology, which holds its
+// machines and the dummy VMs relocated into it. The folder may be nested, such as
+// /DC/vm/org/team/cluster1. A topology without one uses /<datacenter>/vm/<infraID>, the folder
+// the installer creates.
+func FailureDomainFolder(topology configv1.VSpherePlatformTopology, infraID string) string {
+	if folder := NormalizeInventoryPath(topology.Datacenter, VMFolder, topology.Folder); folder != "" {
+		return folder
+	}
+	return path.Join("/", topology.Datacenter, VMFolder, infraID)
+}
+
+// FailureDomainResourcePool returns the absolute resource pool of a failure domain topology,
+// which may be a child pool nested at any depth. A topology without one uses the root resource
+// pool of its compute cluster.
+func FailureDomainResourcePool(topology configv1.VSpherePlatformTopology) string {
+	cluster := NormalizeInventoryPath(topology.Datacenter, HostFolder, topology.ComputeCluster)
+	if pool := NormalizeResourcePoolPath(topology.Datacenter, cluster, topology.ResourcePool); pool != "" {
+		return pool
+	}
+	if cluster == "" {
+		return ""
+	}
+	return path.Join(cluster, resourcePoolRoot)
+}
+
+// ValidatePlacementPaths checks that the folder of a topology is under its datacenter's VM folder
+// and its resource pool under the root resource pool of its compute cluster, so a nested path
+// cannot place VMs outside the failure domain
+func ValidatePlacementPaths(topology configv1.VSpherePlatformTopology) error {
+	dc := topology.Datacenter
+	if folder := NormalizeInventoryPath(dc, VMFolder, topology.Folder); folder != "" {
+		vmRoot := path.Join("/", dc, VMFolder)
+		if !strings.HasPrefix(folder, vmRoot+"/") {
+			return fmt.Errorf("folder %s is not under the VM folder %s of datacenter %s", folder, vmRoot, dc)
+		}
+	}
+
+	cluster := NormalizeInventoryPath(dc, HostFolder, topology.ComputeCluster)
+	pool := NormalizeResourcePoolPath(dc, cluster, topology.ResourcePool)
+	if pool != "" && cluster != "" {
+		poolRoot := path.Join(cluster, resourcePoolRoot)
+		if pool != poolRoot && !strings.HasPrefix(pool, poolRoot+"/") {
+			return fmt.Errorf("resource pool %s is not in the resource pool hierarchy %s of compute cluster %s", pool, poolRoot, cluster)
+		}
+	}
+	return nil
+}
+
+// NormalizeTopology returns the topology with its compute cluster, datastore, resource pool and
+// folder paths canonicalized. Networks and the template are left as given since they are
+// commonly referenced by name.
+func NormalizeTopology(topology configv1.VSpherePlatformTopology) configv1.VSpherePlatformTopology {
+	dc := topology.Datacenter
+	topology.ComputeCluster = NormalizeInventoryPath(dc, HostFolder, topology.ComputeCluster)
+	topology.Datastore = NormalizeInventoryPath(dc, DatastoreFolder, topology.Datastore)
+	topology.ResourcePool = NormalizeResourcePoolPath(dc, topology.ComputeCluster, topology.ResourcePool)
+	topology.Folder = NormalizeInventoryPath(dc, VMFolder, topology.Folder)
+	return topology
+}
+
+// NormalizeFailureDomains canonicalizes the topology paths of each failure domain in place
+func NormalizeFailureDomains(failureDomains []configv1.VSpherePlatformFailureDomainSpec) {
+	for i := range failureDomains {
+		failureDomains[i].Topology = NormalizeTopology(failureDomains[i].Topology)
+	}
+}
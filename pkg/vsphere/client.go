@@ -5,9 +5,16 @@ import (
 	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/find"
@@ -29,6 +36,15 @@ type Client struct {
 	finder        *find.Finder
 	soapLogger    *SOAPLogger
 	restLogger    *RESTLogger
+	proxyURL      string
+
+	operationTimeout time.Duration
+	dialTimeout      time.Duration
+
+	// serverURL carries the credentials used to log in again after the session is lost
+	serverURL         *url.URL
+	reconnectMu       sync.Mutex
+	sessionGeneration atomic.Uint64
 }
 
 // Credentials holds vCenter credentials
@@ -39,49 +55,81 @@ type Credentials struct {
 
 // Config holds vCenter connection configuration
 type Config struct {
-	Server   string
+	// Server is the vCenter host name, optionally with a port (vcenter.example.com:8443) or as a URL
+	Server string
+	// Port is the vCenter HTTPS port, for servers listening elsewhere than 443 that are
+	// given without one. It must agree with a port already present in Server.
+	Port     int
 	Insecure bool
+	// ProxyURL is an explicit http, https or socks5 proxy used to reach vCenter.
+	// When empty, HTTPS_PROXY and NO_PROXY from the environment are honored.
+	ProxyURL string
+	// OperationTimeout bounds each inventory lookup, property retrieval and short task wait.
+	// Defaults to DefaultOperationTimeout. Cross-vCenter vMotion tasks are bounded separately.
+	OperationTimeout time.Duration
+	// DialTimeout bounds establishing the TCP connection and the TLS handshake with vCenter.
+	// Defaults to DefaultDialTimeout.
+	DialTimeout time.Duration
 }
 
+const (
+	// DefaultOperationTimeout is the default deadline of a single vCenter API call
+	DefaultOperationTimeout = 5 * time.Minute
+
+	// DefaultDialTimeout is the default deadline for connecting to vCenter
+	DefaultDialTimeout = 30 * time.Second
+)
+
 // NewClient creates a new vSphere client with logging
 func NewClient(ctx context.Context, config Config, creds Credentials) (*Client, error) {
 	logger := klog.FromContext(ctx)
 
-	// Parse server URL
-	var serverURL *url.URL
-	var err error
-	// If server already has a scheme, use it as-is
-	if strings.HasPrefix(config.Server, "http://") || strings.HasPrefix(config.Server, "https://") {
-		serverURL, err = url.Parse(config.Server)
-		// Only append /sdk if not already present
-		if err == nil && !strings.HasSuffix(serverURL.Path, "/sdk") {
-			serverURL.Path = serverURL.Path + "/sdk"
-		}
-	} else {
-		serverURL, err = url.Parse(fmt.Sprintf("https://%s/sdk", config.Server))
-	}
+	serverURL, err := ParseServerURL(config.Server, config.Port)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse server URL: %w", err)
+		return nil, err
 	}
 
 	// Set credentials
 	serverURL.User = url.UserPassword(creds.Username, creds.Password)
 
+	operationTimeout := config.OperationTimeout
+	if operationTimeout <= 0 {
+		operationTimeout = DefaultOperationTimeout
+	}
+	dialTimeout := config.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = DefaultDialTimeout
+	}
+
 	// Create SOAP logger
-	soapLogger := NewSOAPLogger()
+	soapLogger := NewSOAPLogger(config.Server)
 
 	// Create SOAP client
 	soapClient := soap.NewClient(serverURL, config.Insecure)
 
+	// Route SOAP (and the REST client, which shares this transport) through the proxy
+	if err := configureProxy(ctx, soapClient.DefaultTransport(), serverURL, config.ProxyURL); err != nil {
+		return nil, err
+	}
+
+	// Bound connecting to vCenter so an unresponsive server cannot hang the caller
+	configureDialTimeout(soapClient.DefaultTransport(), dialTimeout)
+
+	loginCtx, cancel := context.WithTimeout(ctx, operationTimeout)
+	defer cancel()
+
 	// Create vim25 client
-	vimClient, err := vim25.NewClient(ctx, soapClient)
+	vimClient, err := vim25.NewClient(loginCtx, soapClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create vim25 client: %w", err)
 	}
 
+	// Record every SOAP call so faults can be surfaced when an operation fails
+	vimClient.RoundTripper = soapLogger.RoundTripper(vimClient.RoundTripper)
+
 	// Create session manager and login
 	sessionManager := session.NewManager(vimClient)
-	err = sessionManager.Login(ctx, serverURL.User)
+	err = sessionManager.Login(loginCtx, serverURL.User)
 	if err != nil {
 		return nil, fmt.Errorf("failed to login to vCenter: %w", err)
 	}
@@ -95,7 +143,7 @@ func NewClient(ctx context.Context, config Config, creds Credentials) (*Client,
 	}
 
 	// Create REST logger
-	restLogger := NewRESTLogger()
+	restLogger := NewRESTLogger(config.Server)
 
 	// Create REST client
 	restClient := rest.NewClient(vimClient)
@@ -107,7 +155,7 @@ func NewClient(ctx context.Context, config Config, creds Credentials) (*Client,
 
 	// Login to REST API (non-fatal for testing with vcsim)
 	var tagManager *tags.Manager
-	err = restClient.Login(ctx, serverURL.User)
+	err = restClient.Login(loginCtx, serverURL.User)
 	if err != nil {
 		logger.V(2).Info("REST API login failed (continuing without tags support)", "error", err)
 		// Don't create tag manager if REST login failed
@@ -127,9 +175,141 @@ func NewClient(ctx context.Context, config Config, creds Credentials) (*Client,
 		finder:        finder,
 		soapLogger:    soapLogger,
 		restLogger:    restLogger,
+		proxyURL:      config.ProxyURL,
+		serverURL:     serverURL,
+
+		operationTimeout: operationTimeout,
+		dialTimeout:      dialTimeout,
 	}, nil
 }
 
+// ParseServerURL builds the SDK endpoint URL of a vCenter from a host name, a host:port pair or a
+// URL. A server without a scheme is reached over https, and port, when non-zero, is applied to a
+// server given without one.
+func ParseServerURL(server string, port int) (*url.URL, error) {
+	server = strings.TrimSpace(server)
+	raw := server
+	if !strings.HasPrefix(raw, "http://") && !strings.HasPrefix(raw, "https://") {
+		raw = "https://" + raw
+	}
+	serverURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse server URL %q: %w", server, err)
+	}
+	if serverURL.Hostname() == "" {
+		return nil, fmt.Errorf("server URL %q has no host", server)
+	}
+
+	if port != 0 {
+		if port < 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %d for server %q", port, server)
+		}
+		if existing := serverURL.Port(); existing != "" && existing != strconv.Itoa(port) {
+			return nil, fmt.Errorf("server %q specifies port %s but port %d was configured", server, existing, port)
+		}
+		serverURL.Host = net.JoinHostPort(serverURL.Hostname(), strconv.Itoa(port))
+	}
+
+	// Only append /sdk if not already present
+	if !strings.HasSuffix(serverURL.Path, "/sdk") {
+		serverURL.Path = strings.TrimSuffix(serverURL.Path, "/") + "/sdk"
+	}
+	return serverURL, nil
+}
+
+// SameServer reports whether two server names, as accepted by ParseServerURL, refer to the same
+// vCenter endpoint. Host names are compared case-insensitively and a missing port is taken to
+// be the default port of the scheme.
+func SameServer(a, b string) bool {
+	aURL, err := ParseServerURL(a, 0)
+	if err != nil {
+		return false
+	}
+	bURL, err := ParseServerURL(b, 0)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(aURL.Hostname(), bURL.Hostname()) && serverPort(aURL) == serverPort(bURL)
+}
+
+// serverPort returns the port of a server URL, defaulting to the port of its scheme
+func serverPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "http" {
+		return "80"
+	}
+	return "443"
+}
+
+// configureDialTimeout bounds the TCP connect and TLS handshake of a vCenter transport. The SOAP
+// client's own TLS dialer ignores the context, so it is replaced by one honoring the timeout.
+func configureDialTimeout(transport *http.Transport, timeout time.Duration) {
+	dialer := &net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: transport.TLSClientConfig}
+		return tlsDialer.DialContext(ctx, network, addr)
+	}
+}
+
+// operationContext bounds a single vCenter call by the operation timeout
+func (c *Client) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.operationTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.operationTimeout)
+}
+
+// proxyFunc returns the proxy selection function for vCenter connections.
+// An explicit proxy URL is used for every request, otherwise the environment decides.
+func proxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (expected http, https or socks5)", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", proxyURL)
+	}
+
+	return http.ProxyURL(parsed), nil
+}
+
+// configureProxy sets the proxy on a vCenter transport and logs when one applies to the server
+func configureProxy(ctx context.Context, transport *http.Transport, serverURL *url.URL, proxyURL string) error {
+	logger := klog.FromContext(ctx)
+
+	proxy, err := proxyFunc(proxyURL)
+	if err != nil {
+		return err
+	}
+	transport.Proxy = proxy
+
+	resolved, err := proxy(&http.Request{URL: &url.URL{Scheme: serverURL.Scheme, Host: serverURL.Host}})
+	if err != nil {
+		return fmt.Errorf("failed to resolve proxy for %s: %w", serverURL.Host, err)
+	}
+	if resolved != nil {
+		logger.Info("Connecting to vCenter through proxy", "server", serverURL.Host, "proxy", resolved.Redacted())
+	}
+
+	return nil
+}
+
 // Logout logs out from vCenter
 func (c *Client) Logout(ctx context.Context) error {
 	logger := klog.FromContext(ctx)
@@ -153,7 +333,12 @@ func (c *Client) Logout(ctx context.Context) error {
 
 // GetDatacenter returns a datacenter object
 func (c *Client) GetDatacenter(ctx context.Context, name string) (*object.Datacenter, error) {
-	dc, err := c.finder.Datacenter(ctx, name)
+	var dc *object.Datacenter
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		dc, err = c.finder.Datacenter(ctx, name)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find datacenter %s: %w", name, err)
 	}
@@ -162,16 +347,40 @@ func (c *Client) GetDatacenter(ctx context.Context, name string) (*object.Datace
 
 // GetCluster returns a cluster object
 func (c *Client) GetCluster(ctx context.Context, path string) (*object.ClusterComputeResource, error) {
-	cluster, err := c.finder.ClusterComputeResource(ctx, path)
+	var cluster *object.ClusterComputeResource
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		cluster, err = c.finder.ClusterComputeResource(ctx, path)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find cluster %s: %w", path, err)
 	}
 	return cluster, nil
 }
 
+// GetHost returns a host system object
+func (c *Client) GetHost(ctx context.Context, path string) (*object.HostSystem, error) {
+	var host *object.HostSystem
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		host, err = c.finder.HostSystem(ctx, path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find host %s: %w", path, err)
+	}
+	return host, nil
+}
+
 // GetFolder returns a folder object
 func (c *Client) GetFolder(ctx context.Context, path string) (*object.Folder, error) {
-	folder, err := c.finder.Folder(ctx, path)
+	var folder *object.Folder
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		folder, err = c.finder.Folder(ctx, path)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find folder %s: %w", path, err)
 	}
@@ -180,16 +389,40 @@ func (c *Client) GetFolder(ctx context.Context, path string) (*object.Folder, er
 
 // GetDatastore returns a datastore object
 func (c *Client) GetDatastore(ctx context.Context, path string) (*object.Datastore, error) {
-	ds, err := c.finder.Datastore(ctx, path)
+	var ds *object.Datastore
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		ds, err = c.finder.Datastore(ctx, path)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find datastore %s: %w", path, err)
 	}
 	return ds, nil
 }
 
+// GetStoragePod returns a datastore cluster (Storage DRS pod) object
+func (c *Client) GetStoragePod(ctx context.Context, path string) (*object.StoragePod, error) {
+	var pod *object.StoragePod
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		pod, err = c.finder.DatastoreCluster(ctx, path)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find datastore cluster %s: %w", path, err)
+	}
+	return pod, nil
+}
+
 // GetNetwork returns a network object
 func (c *Client) GetNetwork(ctx context.Context, path string) (object.NetworkReference, error) {
-	network, err := c.finder.Network(ctx, path)
+	var network object.NetworkReference
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		network, err = c.finder.Network(ctx, path)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find network %s: %w", path, err)
 	}
@@ -198,7 +431,12 @@ func (c *Client) GetNetwork(ctx context.Context, path string) (object.NetworkRef
 
 // GetResourcePool returns a resource pool object
 func (c *Client) GetResourcePool(ctx context.Context, path string) (*object.ResourcePool, error) {
-	rp, err := c.finder.ResourcePool(ctx, path)
+	var rp *object.ResourcePool
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		rp, err = c.finder.ResourcePool(ctx, path)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find resource pool %s: %w", path, err)
 	}
@@ -207,13 +445,24 @@ func (c *Client) GetResourcePool(ctx context.Context, path string) (*object.Reso
 
 // GetVirtualMachine returns a virtual machine (template) object
 func (c *Client) GetVirtualMachine(ctx context.Context, path string) (*object.VirtualMachine, error) {
-	vm, err := c.finder.VirtualMachine(ctx, path)
+	var vm *object.VirtualMachine
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		vm, err = c.finder.VirtualMachine(ctx, path)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find virtual machine %s: %w", path, err)
 	}
 	return vm, nil
 }
 
+// IsNotFound reports whether err is an inventory lookup that found no object at the path
+func IsNotFound(err error) bool {
+	var notFound *find.NotFoundError
+	return errors.As(err, &notFound)
+}
+
 // ListVirtualMachinesInFolder returns all VMs in a folder path
 func (c *Client) ListVirtualMachinesInFolder(ctx context.Context, datacenter string, folderPath string) ([]*object.VirtualMachine, error) {
 	logger := klog.FromContext(ctx)
@@ -227,7 +476,12 @@ func (c *Client) ListVirtualMachinesInFolder(ctx context.Context, datacenter str
 
 	// List VMs in folder using glob pattern
 	vmPath := fmt.Sprintf("%s/*", folderPath)
-	vms, err := c.finder.VirtualMachineList(ctx, vmPath)
+	var vms []*object.VirtualMachine
+	err = c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		vms, err = c.finder.VirtualMachineList(ctx, vmPath)
+		return err
+	})
 	if err != nil {
 		// Check if it's a "not found" error which is acceptable (empty folder)
 		if strings.Contains(err.Error(), "not found") {
@@ -271,46 +525,104 @@ func (c *Client) GetRESTLogs() []RESTLogEntry {
 	return c.restLogger.GetEntries()
 }
 
+// GetRecentSOAPFaults returns up to the last n SOAP calls that returned a fault or error
+func (c *Client) GetRecentSOAPFaults(n int) []SOAPLogEntry {
+	return c.soapLogger.GetFaults(n)
+}
+
+// GetRecentRESTErrors returns up to the last n REST calls that failed or returned an HTTP error status
+func (c *Client) GetRecentRESTErrors(n int) []RESTLogEntry {
+	return c.restLogger.GetErrors(n)
+}
+
 // ClearLogs clears all logged entries
 func (c *Client) ClearLogs() {
 	c.soapLogger.Clear()
 	c.restLogger.Clear()
 }
 
+// ProxyURL returns the explicit proxy this client was configured with, if any
+func (c *Client) ProxyURL() string {
+	return c.proxyURL
+}
+
+// SDKURL returns the SDK endpoint URL of the vCenter this client is connected to, including any
+// non-default port and without credentials
+func (c *Client) SDKURL() string {
+	sdkURL := *c.serverURL
+	sdkURL.User = nil
+	return sdkURL.String()
+}
+
+// DialTimeout returns the deadline for connecting to vCenter this client was configured with
+func (c *Client) DialTimeout() time.Duration {
+	return c.dialTimeout
+}
+
 // GetServerThumbprint fetches the SSL certificate thumbprint from a vCenter server
-// This is required for cross-vCenter vMotion operations to verify the target server's identity
-func GetServerThumbprint(ctx context.Context, serverURL string) (string, error) {
+// serverURL may be a URL or a host name with an optional port, as accepted by ParseServerURL.
+// This is required for cross-vCenter vMotion operations to verify the target server's identity.
+// The connection honors proxyURL (or the environment when empty) the same way NewClient does;
+// a CONNECT or SOCKS tunnel leaves the server certificate intact, so the thumbprint still
+// matches what the source vCenter sees when it contacts the target via the ServiceLocator.
+// dialTimeout bounds the whole exchange; zero uses DefaultDialTimeout.
+func GetServerThumbprint(ctx context.Context, serverURL string, proxyURL string, dialTimeout time.Duration) (string, error) {
 	logger := klog.FromContext(ctx)
 
 	// Parse the server URL to extract host
-	parsedURL, err := url.Parse(serverURL)
+	parsedURL, err := ParseServerURL(serverURL, 0)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse server URL: %w", err)
+		return "", err
 	}
 
 	host := parsedURL.Host
 	// If no port specified, default to 443
-	if !strings.Contains(host, ":") {
-		host = host + ":443"
+	if parsedURL.Port() == "" {
+		host = net.JoinHostPort(parsedURL.Hostname(), "443")
 	}
 
 	logger.V(2).Info("Fetching SSL thumbprint from server", "host", host)
 
-	// Connect with TLS to get the certificate
+	proxy, err := proxyFunc(proxyURL)
+	if err != nil {
+		return "", err
+	}
+
+	// Connect with TLS to get the certificate, tunneling through the proxy when one applies
 	// We need to skip verification to get the cert for thumbprint calculation
-	conn, err := tls.Dial("tcp", host, &tls.Config{
-		InsecureSkipVerify: true,
-	})
+	if dialTimeout <= 0 {
+		dialTimeout = DefaultDialTimeout
+	}
+	transport := &http.Transport{
+		Proxy:               proxy,
+		DialContext:         (&net.Dialer{Timeout: dialTimeout}).DialContext,
+		TLSHandshakeTimeout: dialTimeout,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	// The whole exchange, including a proxy tunnel, is only a handshake and a HEAD request
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, (&url.URL{Scheme: "https", Host: host, Path: "/"}).String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request for server %s: %w", host, err)
+	}
+
+	resp, err := transport.RoundTrip(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to server %s: %w", host, err)
 	}
-	defer conn.Close()
+	defer resp.Body.Close()
 
 	// Get the server's certificate
-	certs := conn.ConnectionState().PeerCertificates
-	if len(certs) == 0 {
+	if resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0 {
 		return "", fmt.Errorf("no certificates returned from server %s", host)
 	}
+	certs := resp.TLS.PeerCertificates
 
 	// Calculate SHA-256 thumbprint of the first (leaf) certificate
 	thumbprint := calculateThumbprint(certs[0])
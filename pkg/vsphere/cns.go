@@ -2,10 +2,15 @@ package vsphere
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"sort"
 
 	"github.com/vmware/govmomi/cns"
 	cnstypes "github.com/vmware/govmomi/cns/types"
+	vimtask "github.com/vmware/govmomi/task"
 	"github.com/vmware/govmomi/vim25/types"
 	"k8s.io/klog/v2"
 )
@@ -25,6 +30,24 @@ type CNSVolumeInfo struct {
 	BackingPath  string
 	CapacityMB   int64
 	HealthStatus string
+	Entities     []CNSEntityInfo
+}
+
+// CNSEntityInfo describes a Kubernetes entity associated with a CNS volume
+type CNSEntityInfo struct {
+	EntityType string
+	EntityName string
+	Namespace  string
+	Labels     map[string]string
+}
+
+// VolumeEntityMetadata identifies the Kubernetes PV and PVC that own a CNS volume
+type VolumeEntityMetadata struct {
+	PVName       string
+	PVLabels     map[string]string
+	PVCName      string
+	PVCNamespace string
+	PVCLabels    map[string]string
 }
 
 // NewCNSManager creates a new CNS manager
@@ -91,6 +114,8 @@ func (m *CNSManager) QueryVolume(ctx context.Context, volumeID string) (*CNSVolu
 		info.HealthStatus = vol.HealthStatus
 	}
 
+	info.Entities = entitiesFromMetadata(vol.Metadata)
+
 	logger.V(2).Info("Retrieved CNS volume info", "volumeID", info.VolumeID, "name", info.Name)
 	return info, nil
 }
@@ -119,6 +144,7 @@ func (m *CNSManager) QueryVolumeByPath(ctx context.Context, backingPath string)
 						DatastoreURL: vol.DatastoreUrl,
 						CapacityMB:   vol.BackingObjectDetails.GetCnsBackingObjectDetails().CapacityInMb,
 						HealthStatus: vol.HealthStatus,
+						Entities:     entitiesFromMetadata(vol.Metadata),
 					}, nil
 				}
 			}
@@ -128,8 +154,9 @@ func (m *CNSManager) QueryVolumeByPath(ctx context.Context, backingPath string)
 	return nil, fmt.Errorf("volume with backing path %s not found", backingPath)
 }
 
-// RegisterVolume registers a VMDK as a CNS volume
-func (m *CNSManager) RegisterVolume(ctx context.Context, backingPath string, name string, datastoreURL string, containerClusterID string) (*CNSVolumeInfo, error) {
+// RegisterVolume registers a VMDK as a CNS volume. When entity is set, the volume is
+// associated with its PV and PVC so the CSI driver on the target treats it as in use.
+func (m *CNSManager) RegisterVolume(ctx context.Context, backingPath string, name string, datastoreURL string, containerClusterID string, entity *VolumeEntityMetadata) (*CNSVolumeInfo, error) {
 	logger := klog.FromContext(ctx)
 	logger.Info("Registering CNS volume", "path", backingPath, "name", name)
 
@@ -160,6 +187,7 @@ func (m *CNSManager) RegisterVolume(ctx context.Context, backingPath string, nam
 				ClusterId:     containerClusterID,
 				ClusterFlavor: string(cnstypes.CnsClusterFlavorVanilla),
 			},
+			EntityMetadata: buildEntityMetadata(entity, containerClusterID),
 		},
 	}
 
@@ -169,42 +197,205 @@ func (m *CNSManager) RegisterVolume(ctx context.Context, backingPath string, nam
 		return nil, fmt.Errorf("failed to create CNS volume: %w", err)
 	}
 
-	taskInfo, err := task.WaitForResult(ctx, nil)
+	waitCtx, cancel := m.client.operationContext(ctx)
+	defer cancel()
+	taskInfo, err := task.WaitForResult(waitCtx, nil)
 	if err != nil {
+		var taskErr vimtask.Error
+		if errors.As(err, &taskErr) && taskErr.LocalizedMethodFault != nil {
+			return nil, cnsFaultToError(taskErr.LocalizedMethodFault, "")
+		}
 		return nil, fmt.Errorf("failed to wait for CNS volume creation: %w", err)
 	}
 
-	// Extract volume ID from result
-	operationResult, ok := taskInfo.Result.(cnstypes.CnsVolumeOperationBatchResult)
+	volumeID, err := ParseCreateVolumeResult(taskInfo.Result)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &CNSVolumeInfo{
+		VolumeID:    volumeID,
+		Name:        name,
+		VolumeType:  string(cnstypes.CnsVolumeTypeBlock),
+		BackingPath: backingPath,
+	}
+
+	logger.Info("Successfully registered CNS volume", "volumeID", info.VolumeID, "name", info.Name)
+	return info, nil
+}
+
+// ParseCreateVolumeResult extracts the volume ID from the result of a single-volume CNS create task.
+// A fault recorded against the volume is returned as a *CNSFaultError.
+func ParseCreateVolumeResult(result types.AnyType) (string, error) {
+	operationResult, ok := result.(cnstypes.CnsVolumeOperationBatchResult)
 	if !ok {
-		return nil, fmt.Errorf("unexpected result type from CNS create volume")
+		return "", fmt.Errorf("unexpected result type %T from CNS create volume", result)
 	}
 
 	if len(operationResult.VolumeResults) == 0 {
-		return nil, fmt.Errorf("no volume results returned")
+		return "", fmt.Errorf("no volume results returned")
 	}
 
 	volResult := operationResult.VolumeResults[0]
-	if volResult.GetCnsVolumeOperationResult().Fault != nil {
-		return nil, fmt.Errorf("CNS volume creation failed: %s",
-			volResult.GetCnsVolumeOperationResult().Fault.LocalizedMessage)
+	if fault := volResult.GetCnsVolumeOperationResult().Fault; fault != nil {
+		return "", cnsFaultToError(fault, volResult.GetCnsVolumeOperationResult().VolumeId.Id)
 	}
 
-	// Get the volume ID from the result
 	createResult, ok := volResult.(*cnstypes.CnsVolumeCreateResult)
 	if !ok {
-		return nil, fmt.Errorf("unexpected volume result type")
+		return "", fmt.Errorf("unexpected volume result type %T", volResult)
 	}
 
-	info := &CNSVolumeInfo{
-		VolumeID:    createResult.VolumeId.Id,
-		Name:        name,
-		VolumeType:  string(cnstypes.CnsVolumeTypeBlock),
-		BackingPath: backingPath,
+	return createResult.VolumeId.Id, nil
+}
+
+// CNSFaultError describes a fault CNS reported for a volume operation
+type CNSFaultError struct {
+	// FaultType is the vSphere fault type, such as CnsAlreadyRegisteredFault
+	FaultType string
+	// Message is the localized message reported by vCenter
+	Message string
+	// VolumeID is the volume the fault refers to, when known
+	VolumeID string
+	// Hint suggests how to resolve the fault, when it is a known one
+	Hint string
+}
+
+func (e *CNSFaultError) Error() string {
+	msg := fmt.Sprintf("CNS volume operation failed with %s", e.FaultType)
+	if e.VolumeID != "" {
+		msg += fmt.Sprintf(" for volume %s", e.VolumeID)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Hint != "" {
+		msg += " (" + e.Hint + ")"
 	}
+	return msg
+}
 
-	logger.Info("Successfully registered CNS volume", "volumeID", info.VolumeID, "name", info.Name)
-	return info, nil
+// IsCNSAlreadyRegistered reports whether err is a CNS fault for a volume that is already registered
+func IsCNSAlreadyRegistered(err error) bool {
+	var faultErr *CNSFaultError
+	if !errors.As(err, &faultErr) {
+		return false
+	}
+	return faultErr.FaultType == "CnsAlreadyRegisteredFault" || faultErr.FaultType == "CnsVolumeAlreadyExistsFault"
+}
+
+// cnsFaultToError maps a CNS fault to a *CNSFaultError with a hint for the common failures
+func cnsFaultToError(fault *types.LocalizedMethodFault, volumeID string) error {
+	faultErr := &CNSFaultError{
+		Message:  fault.LocalizedMessage,
+		VolumeID: volumeID,
+	}
+	if fault.Fault == nil {
+		faultErr.FaultType = "UnknownFault"
+		return faultErr
+	}
+	faultErr.FaultType = reflect.TypeOf(fault.Fault).Elem().Name()
+
+	switch f := fault.Fault.(type) {
+	case *cnstypes.CnsAlreadyRegisteredFault:
+		if faultErr.VolumeID == "" {
+			faultErr.VolumeID = f.VolumeId.Id
+		}
+		faultErr.Hint = "the disk is already registered with CNS; query the existing volume instead of registering it again"
+	case *cnstypes.CnsVolumeAlreadyExistsFault:
+		if faultErr.VolumeID == "" {
+			faultErr.VolumeID = f.VolumeId.Id
+		}
+		faultErr.Hint = "a CNS volume already exists for the disk; query the existing volume instead of registering it again"
+	case *cnstypes.CnsVolumeNotFoundFault, *types.NotFound, *types.FileNotFound:
+		faultErr.Hint = "the backing disk was not found; check the FCD was relocated to the target datastore"
+	case *types.InaccessibleDatastore, *types.InvalidDatastore, *types.DatastoreNotWritableOnHost:
+		faultErr.Hint = "the datastore is not accessible from the target vCenter; check it is mounted on the target cluster hosts"
+	case *cnstypes.CnsFault:
+		faultErr.Hint = f.Reason
+	}
+
+	return faultErr
+}
+
+// buildEntityMetadata builds the PV and PVC entity metadata the vSphere CSI driver records
+// for a volume, with the PVC referring to the PV it is bound to
+func buildEntityMetadata(entity *VolumeEntityMetadata, clusterID string) []cnstypes.BaseCnsEntityMetadata {
+	if entity == nil || entity.PVName == "" {
+		return nil
+	}
+
+	metadata := []cnstypes.BaseCnsEntityMetadata{
+		&cnstypes.CnsKubernetesEntityMetadata{
+			CnsEntityMetadata: cnstypes.CnsEntityMetadata{
+				EntityName: entity.PVName,
+				Labels:     toKeyValues(entity.PVLabels),
+				ClusterID:  clusterID,
+			},
+			EntityType: string(cnstypes.CnsKubernetesEntityTypePV),
+		},
+	}
+
+	if entity.PVCName != "" {
+		metadata = append(metadata, &cnstypes.CnsKubernetesEntityMetadata{
+			CnsEntityMetadata: cnstypes.CnsEntityMetadata{
+				EntityName: entity.PVCName,
+				Labels:     toKeyValues(entity.PVCLabels),
+				ClusterID:  clusterID,
+			},
+			EntityType: string(cnstypes.CnsKubernetesEntityTypePVC),
+			Namespace:  entity.PVCNamespace,
+			ReferredEntity: []cnstypes.CnsKubernetesEntityReference{
+				{
+					EntityType: string(cnstypes.CnsKubernetesEntityTypePV),
+					EntityName: entity.PVName,
+					ClusterID:  clusterID,
+				},
+			},
+		})
+	}
+
+	return metadata
+}
+
+// toKeyValues converts labels to CNS key/value pairs in a stable order
+func toKeyValues(labels map[string]string) []types.KeyValue {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]types.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, types.KeyValue{Key: key, Value: labels[key]})
+	}
+	return kvs
+}
+
+// entitiesFromMetadata extracts the Kubernetes entities recorded against a CNS volume
+func entitiesFromMetadata(metadata cnstypes.CnsVolumeMetadata) []CNSEntityInfo {
+	var entities []CNSEntityInfo
+	for _, base := range metadata.EntityMetadata {
+		k8sEntity, ok := base.(*cnstypes.CnsKubernetesEntityMetadata)
+		if !ok {
+			continue
+		}
+
+		entity := CNSEntityInfo{
+			EntityType: k8sEntity.EntityType,
+			EntityName: k8sEntity.EntityName,
+			Namespace:  k8sEntity.Namespace,
+		}
+		if len(k8sEntity.Labels) > 0 {
+			entity.Labels = make(map[string]string, len(k8sEntity.Labels))
+			for _, kv := range k8sEntity.Labels {
+				entity.Labels[kv.Key] = kv.Value
+			}
+		}
+		entities = append(entities, entity)
+	}
+	return entities
 }
 
 // DeleteVolume deletes a CNS volume
@@ -221,7 +412,9 @@ func (m *CNSManager) DeleteVolume(ctx context.Context, volumeID string, deleteDi
 		return fmt.Errorf("failed to delete CNS volume: %w", err)
 	}
 
-	if err := task.Wait(ctx); err != nil {
+	waitCtx, cancel := m.client.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
 		return fmt.Errorf("failed to wait for CNS volume deletion: %w", err)
 	}
 
@@ -249,6 +442,7 @@ func (m *CNSManager) ListVolumes(ctx context.Context) ([]CNSVolumeInfo, error) {
 			DatastoreURL: vol.DatastoreUrl,
 			CapacityMB:   vol.BackingObjectDetails.GetCnsBackingObjectDetails().CapacityInMb,
 			HealthStatus: vol.HealthStatus,
+			Entities:     entitiesFromMetadata(vol.Metadata),
 		}
 
 		if backingDetails := vol.BackingObjectDetails; backingDetails != nil {
@@ -293,7 +487,9 @@ func (m *CNSManager) UpdateVolumeMetadata(ctx context.Context, volumeID string,
 		return fmt.Errorf("failed to update CNS volume metadata: %w", err)
 	}
 
-	if err := task.Wait(ctx); err != nil {
+	waitCtx, cancel := m.client.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
 		return fmt.Errorf("failed to wait for metadata update: %w", err)
 	}
 
@@ -301,6 +497,78 @@ func (m *CNSManager) UpdateVolumeMetadata(ctx context.Context, volumeID string,
 	return nil
 }
 
+// GetVolumeLabels returns the labels recorded against the Kubernetes entities of a CNS volume,
+// such as team or cost-center labels, merged into one set
+func (m *CNSManager) GetVolumeLabels(ctx context.Context, volumeID string) (map[string]string, error) {
+	info, err := m.QueryVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	for _, entity := range info.Entities {
+		maps.Copy(labels, entity.Labels)
+	}
+	return labels, nil
+}
+
+// SetVolumeLabels sets labels on the PV entity recorded against a CNS volume, keeping its other
+// labels and the volume's other entities
+func (m *CNSManager) SetVolumeLabels(ctx context.Context, volumeID string, labels map[string]string) error {
+	logger := klog.FromContext(ctx)
+	if len(labels) == 0 {
+		return nil
+	}
+
+	result, err := m.cnsClient.QueryVolume(ctx, &cnstypes.CnsQueryFilter{
+		VolumeIds: []cnstypes.CnsVolumeId{{Id: volumeID}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to query CNS volume: %w", err)
+	}
+	if len(result.Volumes) == 0 {
+		return fmt.Errorf("volume %s not found", volumeID)
+	}
+
+	metadata := result.Volumes[0].Metadata
+	var labeled bool
+	for _, base := range metadata.EntityMetadata {
+		k8sEntity, ok := base.(*cnstypes.CnsKubernetesEntityMetadata)
+		if !ok || k8sEntity.EntityType != string(cnstypes.CnsKubernetesEntityTypePV) {
+			continue
+		}
+		merged := make(map[string]string, len(k8sEntity.Labels)+len(labels))
+		for _, kv := range k8sEntity.Labels {
+			merged[kv.Key] = kv.Value
+		}
+		maps.Copy(merged, labels)
+		k8sEntity.Labels = toKeyValues(merged)
+		labeled = true
+	}
+	if !labeled {
+		return fmt.Errorf("volume %s has no PV entity to label", volumeID)
+	}
+
+	task, err := m.cnsClient.UpdateVolumeMetadata(ctx, []cnstypes.CnsVolumeMetadataUpdateSpec{
+		{
+			VolumeId: cnstypes.CnsVolumeId{Id: volumeID},
+			Metadata: metadata,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update CNS volume labels: %w", err)
+	}
+
+	waitCtx, cancel := m.client.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
+		return fmt.Errorf("failed to wait for CNS volume label update: %w", err)
+	}
+
+	logger.Info("Set CNS volume labels", "volumeID", volumeID, "labels", len(labels))
+	return nil
+}
+
 // Close closes the CNS manager (no-op as it shares the vim25 session)
 func (m *CNSManager) Close(ctx context.Context) error {
 	return nil
@@ -0,0 +1,107 @@
+package vsphere
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// crossVCenterVMotionTargets maps a source vCenter major.minor version to the target
+// major.minor versions supported for cross-vCenter vMotion.
+// vMotion to an older vCenter is not supported, so every entry only lists the same or newer releases.
+var crossVCenterVMotionTargets = map[string][]string{
+	"6.5": {"6.5", "6.7", "7.0"},
+	"6.7": {"6.7", "7.0", "8.0"},
+	"7.0": {"7.0", "8.0"},
+	"8.0": {"8.0", "9.0"},
+	"9.0": {"9.0"},
+}
+
+// GetAbout returns the vCenter product information (version, build, instance UUID)
+func (c *Client) GetAbout() types.AboutInfo {
+	return c.vimClient.ServiceContent.About
+}
+
+// CheckVMotionCompatibility verifies that a VM can be relocated from the source to the target vCenter.
+// The target major.minor must be in the compatibility window of the source, and within the same
+// major.minor the target must not be an older update or build than the source.
+func CheckVMotionCompatibility(source, target types.AboutInfo) error {
+	sourceRelease, err := majorMinor(source.Version)
+	if err != nil {
+		return fmt.Errorf("failed to parse source vCenter version: %w", err)
+	}
+	targetRelease, err := majorMinor(target.Version)
+	if err != nil {
+		return fmt.Errorf("failed to parse target vCenter version: %w", err)
+	}
+
+	supported, ok := crossVCenterVMotionTargets[sourceRelease]
+	if !ok {
+		return fmt.Errorf("source vCenter %s (build %s) is not a supported version for cross-vCenter vMotion",
+			source.Version, source.Build)
+	}
+	if !slices.Contains(supported, targetRelease) {
+		return fmt.Errorf("cross-vCenter vMotion from vCenter %s (build %s) to vCenter %s (build %s) is not supported; supported target releases: %s",
+			source.Version, source.Build, target.Version, target.Build, strings.Join(supported, ", "))
+	}
+
+	if sourceRelease == targetRelease && compareVersions(target, source) < 0 {
+		return fmt.Errorf("cross-vCenter vMotion from vCenter %s (build %s) to older vCenter %s (build %s) is not supported",
+			source.Version, source.Build, target.Version, target.Build)
+	}
+
+	return nil
+}
+
+// majorMinor returns the major.minor release of a vCenter version string such as 8.0.3
+func majorMinor(version string) (string, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("invalid version %q", version)
+	}
+	for _, part := range parts[:2] {
+		if _, err := strconv.Atoi(part); err != nil {
+			return "", fmt.Errorf("invalid version %q", version)
+		}
+	}
+	return parts[0] + "." + parts[1], nil
+}
+
+// compareVersions compares two vCenter releases by version components and then build number.
+// Components that are not numeric are ignored.
+func compareVersions(a, b types.AboutInfo) int {
+	aParts := strings.Split(a.Version, ".")
+	bParts := strings.Split(b.Version, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		if c := compareNumeric(componentAt(aParts, i), componentAt(bParts, i)); c != 0 {
+			return c
+		}
+	}
+	return compareNumeric(a.Build, b.Build)
+}
+
+func componentAt(parts []string, i int) string {
+	if i < len(parts) {
+		return parts[i]
+	}
+	return "0"
+}
+
+func compareNumeric(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	if aErr != nil || bErr != nil {
+		return 0
+	}
+	switch {
+	case aNum < bNum:
+		return -1
+	case aNum > bNum:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -2,16 +2,23 @@ package vsphere
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/vmware/govmomi/fault"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	"github.com/vmware/govmomi/vslm"
 	vslmtypes "github.com/vmware/govmomi/vslm/types"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 // FCDManager manages First Class Disk (FCD) operations
@@ -19,6 +26,10 @@ type FCDManager struct {
 	client         *Client
 	vslmClient     *vslm.Client
 	globalObjMgr   *vslm.GlobalObjectManager
+
+	// vslmMu guards the vslm client, which is recreated after the vCenter session is re-established
+	vslmMu            sync.Mutex
+	sessionGeneration uint64
 }
 
 // FCDInfo contains information about a First Class Disk
@@ -28,8 +39,20 @@ type FCDInfo struct {
 	Path         string
 	DatastoreMoRef string
 	CapacityMB   int64
+	// Encrypted is set for disks protected by VM encryption
+	Encrypted bool
+}
+
+// FCDSnapshotInfo contains information about a snapshot of a First Class Disk
+type FCDSnapshotInfo struct {
+	ID          string
+	Description string
+	CreateTime  time.Time
 }
 
+// fcdTaskTimeout bounds how long vslm snapshot tasks are waited on
+const fcdTaskTimeout = 10 * time.Minute
+
 // NewFCDManager creates a new FCD manager
 func NewFCDManager(ctx context.Context, client *Client) (*FCDManager, error) {
 	if client == nil || client.vimClient == nil {
@@ -46,20 +69,64 @@ func NewFCDManager(ctx context.Context, client *Client) (*FCDManager, error) {
 	globalObjMgr := vslm.NewGlobalObjectManager(vslmClient)
 
 	return &FCDManager{
-		client:       client,
-		vslmClient:   vslmClient,
-		globalObjMgr: globalObjMgr,
+		client:            client,
+		vslmClient:        vslmClient,
+		globalObjMgr:      globalObjMgr,
+		sessionGeneration: client.sessionGeneration.Load(),
 	}, nil
 }
 
+// globalObjectManager returns the vslm global object manager, recreating the vslm client when the
+// vCenter session was re-established since it was created: the vslm client keeps its own copy of
+// the session cookie
+func (m *FCDManager) globalObjectManager(ctx context.Context) (*vslm.GlobalObjectManager, error) {
+	m.vslmMu.Lock()
+	defer m.vslmMu.Unlock()
+
+	generation := m.client.sessionGeneration.Load()
+	if generation == m.sessionGeneration {
+		return m.globalObjMgr, nil
+	}
+
+	vslmClient, err := vslm.NewClient(ctx, m.client.vimClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recreate vslm client: %w", err)
+	}
+	m.vslmClient = vslmClient
+	m.globalObjMgr = vslm.NewGlobalObjectManager(vslmClient)
+	m.sessionGeneration = generation
+
+	return m.globalObjMgr, nil
+}
+
+// withGlobalObjectManager runs fn against the vslm global object manager, logging in again and
+// retrying once when the vCenter session was lost
+func (m *FCDManager) withGlobalObjectManager(ctx context.Context, mutating bool, fn func(*vslm.GlobalObjectManager) error) error {
+	return m.client.withReconnect(ctx, mutating, func(ctx context.Context) error {
+		globalObjMgr, err := m.globalObjectManager(ctx)
+		if err != nil {
+			return err
+		}
+		return fn(globalObjMgr)
+	})
+}
+
 // GetFCDByID retrieves a First Class Disk by its ID
 func (m *FCDManager) GetFCDByID(ctx context.Context, fcdID string) (*FCDInfo, error) {
 	logger := klog.FromContext(ctx)
 	logger.V(2).Info("Getting FCD by ID", "fcdID", fcdID)
 
 	id := types.ID{Id: fcdID}
-	vStorageObject, err := m.globalObjMgr.Retrieve(ctx, id)
+	var vStorageObject *types.VStorageObject
+	err := m.withGlobalObjectManager(ctx, false, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		vStorageObject, err = globalObjMgr.Retrieve(ctx, id)
+		return err
+	})
 	if err != nil {
+		if isVStorageObjectNotFound(err) {
+			return nil, fmt.Errorf("failed to retrieve FCD %s: %w: %v", fcdID, ErrFCDNotFound, err)
+		}
 		return nil, fmt.Errorf("failed to retrieve FCD %s: %w", fcdID, err)
 	}
 
@@ -67,6 +134,7 @@ func (m *FCDManager) GetFCDByID(ctx context.Context, fcdID string) (*FCDInfo, er
 		ID:         vStorageObject.Config.Id.Id,
 		Name:       vStorageObject.Config.Name,
 		CapacityMB: vStorageObject.Config.CapacityInMB,
+		Encrypted:  slices.ContainsFunc(vStorageObject.Config.Iofilter, isEncryptionFilter),
 	}
 
 	// Extract backing info
@@ -79,13 +147,73 @@ func (m *FCDManager) GetFCDByID(ctx context.Context, fcdID string) (*FCDInfo, er
 	return info, nil
 }
 
+// BackingPath returns the datastore path of the VMDK backing an FCD as the vCenter holding it
+// reports it, which after a vMotion may be on another datastore than the one it was sent to
+func (m *FCDManager) BackingPath(ctx context.Context, fcdID string) (string, error) {
+	info, err := m.GetFCDByID(ctx, fcdID)
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := ParseDatastorePath(info.Path); err != nil {
+		return "", fmt.Errorf("unexpected backing path %q of FCD %s: %w", info.Path, fcdID, err)
+	}
+	return info.Path, nil
+}
+
+// VolumeTag is a vSphere tag attached to a First Class Disk, identified by the names of its
+// category and tag
+type VolumeTag struct {
+	Category string
+	Name     string
+}
+
+// ListVolumeTags returns the vSphere tags attached to an FCD
+func (m *FCDManager) ListVolumeTags(ctx context.Context, fcdID string) ([]VolumeTag, error) {
+	var entries []types.VslmTagEntry
+	err := m.withGlobalObjectManager(ctx, false, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		entries, err = globalObjMgr.ListAttachedTags(ctx, types.ID{Id: fcdID})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags of FCD %s: %w", fcdID, err)
+	}
+
+	tags := make([]VolumeTag, 0, len(entries))
+	for _, entry := range entries {
+		tags = append(tags, VolumeTag{Category: entry.ParentCategoryName, Name: entry.TagName})
+	}
+	return tags, nil
+}
+
+// AttachVolumeTag attaches an existing vSphere tag to an FCD
+func (m *FCDManager) AttachVolumeTag(ctx context.Context, fcdID string, tag VolumeTag) error {
+	err := m.withGlobalObjectManager(ctx, true, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		return globalObjMgr.AttachTag(ctx, types.ID{Id: fcdID}, tag.Category, tag.Name)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach tag %s/%s to FCD %s: %w", tag.Category, tag.Name, fcdID, err)
+	}
+	return nil
+}
+
+// isEncryptionFilter reports whether an I/O filter is the one VM encryption applies to disks
+func isEncryptionFilter(id string) bool {
+	return strings.Contains(strings.ToLower(id), "vmcrypt")
+}
+
 // ListFCDs lists all First Class Disks using the global object manager
 func (m *FCDManager) ListFCDs(ctx context.Context) ([]FCDInfo, error) {
 	logger := klog.FromContext(ctx)
 	logger.V(2).Info("Listing all FCDs")
 
 	// Query all FCDs without filter
-	result, err := m.globalObjMgr.List(ctx)
+	var result *vslmtypes.VslmVsoVStorageObjectQueryResult
+	err := m.withGlobalObjectManager(ctx, false, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		result, err = globalObjMgr.List(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list FCDs: %w", err)
 	}
@@ -125,7 +253,12 @@ func (m *FCDManager) ListFCDsOnDatastore(ctx context.Context, datastoreName stri
 		QueryValue:    []string{ds.Reference().Value},
 	}
 
-	result, err := m.globalObjMgr.List(ctx, querySpec)
+	var result *vslmtypes.VslmVsoVStorageObjectQueryResult
+	err = m.withGlobalObjectManager(ctx, false, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		result, err = globalObjMgr.List(ctx, querySpec)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list FCDs on datastore: %w", err)
 	}
@@ -165,7 +298,12 @@ func (m *FCDManager) RegisterDisk(ctx context.Context, datastoreName string, pat
 	fullPath := fmt.Sprintf("[%s] %s", datastoreName, path)
 
 	// Register the disk
-	vStorageObject, err := objMgr.RegisterDisk(ctx, fullPath, name)
+	var vStorageObject *types.VStorageObject
+	err = m.client.withReconnect(ctx, true, func(ctx context.Context) error {
+		var err error
+		vStorageObject, err = objMgr.RegisterDisk(ctx, fullPath, name)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to register disk: %w", err)
 	}
@@ -187,7 +325,9 @@ func (m *FCDManager) AttachDisk(ctx context.Context, vm *object.VirtualMachine,
 	logger := klog.FromContext(ctx)
 	logger.Info("Attaching FCD to VM", "fcdID", fcdID, "vm", vm.Name())
 
-	err := vm.AttachDisk(ctx, fcdID, datastore, controllerKey, &unitNumber)
+	err := m.client.withReconnect(ctx, true, func(ctx context.Context) error {
+		return vm.AttachDisk(ctx, fcdID, datastore, controllerKey, &unitNumber)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to attach disk: %w", err)
 	}
@@ -201,7 +341,9 @@ func (m *FCDManager) DetachDisk(ctx context.Context, vm *object.VirtualMachine,
 	logger := klog.FromContext(ctx)
 	logger.Info("Detaching FCD from VM", "fcdID", fcdID, "vm", vm.Name())
 
-	err := vm.DetachDisk(ctx, fcdID)
+	err := m.client.withReconnect(ctx, true, func(ctx context.Context) error {
+		return vm.DetachDisk(ctx, fcdID)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to detach disk: %w", err)
 	}
@@ -210,6 +352,31 @@ func (m *FCDManager) DetachDisk(ctx context.Context, vm *object.VirtualMachine,
 	return nil
 }
 
+// DetachDisks detaches each of the given FCDs that is attached to a virtual machine and reads
+// back the VM devices to confirm it is gone. Every FCD is tried, so one failure does not leave
+// the others attached; an error means at least one FCD may still be on the VM.
+func (m *FCDManager) DetachDisks(ctx context.Context, vm *object.VirtualMachine, fcdIDs []string) error {
+	var errs []error
+	for _, fcdID := range fcdIDs {
+		attached, err := m.IsFCDAttachedToVM(ctx, vm, fcdID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to check attachment of FCD %s: %w", fcdID, err))
+			continue
+		}
+		if !attached {
+			continue
+		}
+		if err := m.DetachDisk(ctx, vm, fcdID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to detach FCD %s: %w", fcdID, err))
+			continue
+		}
+		if err := m.VerifyFCDNotAttachedToVM(ctx, vm, fcdID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // DeleteFCD deletes a First Class Disk
 func (m *FCDManager) DeleteFCD(ctx context.Context, datastoreName string, fcdID string) error {
 	logger := klog.FromContext(ctx)
@@ -224,12 +391,19 @@ func (m *FCDManager) DeleteFCD(ctx context.Context, datastoreName string, fcdID
 	// Create object manager
 	objMgr := vslm.NewObjectManager(m.client.vimClient)
 
-	task, err := objMgr.Delete(ctx, ds, fcdID)
+	var task *object.Task
+	err = m.client.withReconnect(ctx, true, func(ctx context.Context) error {
+		var err error
+		task, err = objMgr.Delete(ctx, ds, fcdID)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete FCD: %w", err)
 	}
 
-	if err := task.Wait(ctx); err != nil {
+	waitCtx, cancel := m.client.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
 		return fmt.Errorf("failed to wait for delete FCD task: %w", err)
 	}
 
@@ -237,6 +411,120 @@ func (m *FCDManager) DeleteFCD(ctx context.Context, datastoreName string, fcdID
 	return nil
 }
 
+// DeleteVirtualDisk deletes a VMDK that is not registered as a First Class Disk
+func (m *FCDManager) DeleteVirtualDisk(ctx context.Context, datacenter string, path string) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Deleting virtual disk", "path", path)
+
+	dc, err := m.client.GetDatacenter(ctx, datacenter)
+	if err != nil {
+		return fmt.Errorf("failed to get datacenter %s: %w", datacenter, err)
+	}
+
+	diskManager := object.NewVirtualDiskManager(m.client.vimClient)
+
+	var task *object.Task
+	err = m.client.withReconnect(ctx, true, func(ctx context.Context) error {
+		var err error
+		task, err = diskManager.DeleteVirtualDisk(ctx, path, dc)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete virtual disk %s: %w", path, err)
+	}
+
+	waitCtx, cancel := m.client.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
+		return fmt.Errorf("failed to wait for delete virtual disk task: %w", err)
+	}
+
+	logger.Info("Successfully deleted virtual disk", "path", path)
+	return nil
+}
+
+// CreateSnapshot takes a snapshot of a First Class Disk and returns the snapshot ID
+func (m *FCDManager) CreateSnapshot(ctx context.Context, fcdID string, description string) (string, error) {
+	logger := klog.FromContext(ctx)
+	logger.Info("Creating FCD snapshot", "fcdID", fcdID, "description", description)
+
+	var task *vslm.Task
+	err := m.withGlobalObjectManager(ctx, true, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		task, err = globalObjMgr.CreateSnapshot(ctx, types.ID{Id: fcdID}, description)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create snapshot of FCD %s: %w", fcdID, err)
+	}
+
+	result, err := task.Wait(ctx, fcdTaskTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to wait for snapshot task of FCD %s: %w", fcdID, err)
+	}
+
+	var snapshotID string
+	switch id := result.(type) {
+	case types.ID:
+		snapshotID = id.Id
+	case *types.ID:
+		snapshotID = id.Id
+	}
+	if snapshotID == "" {
+		return "", fmt.Errorf("snapshot task of FCD %s returned no snapshot ID (result type %T)", fcdID, result)
+	}
+
+	logger.Info("Successfully created FCD snapshot", "fcdID", fcdID, "snapshotID", snapshotID)
+	return snapshotID, nil
+}
+
+// DeleteSnapshot deletes a snapshot of a First Class Disk
+func (m *FCDManager) DeleteSnapshot(ctx context.Context, fcdID string, snapshotID string) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Deleting FCD snapshot", "fcdID", fcdID, "snapshotID", snapshotID)
+
+	var task *vslm.Task
+	err := m.withGlobalObjectManager(ctx, true, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		task, err = globalObjMgr.DeleteSnapshot(ctx, types.ID{Id: fcdID}, types.ID{Id: snapshotID})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot %s of FCD %s: %w", snapshotID, fcdID, err)
+	}
+
+	if _, err := task.Wait(ctx, fcdTaskTimeout); err != nil {
+		return fmt.Errorf("failed to wait for snapshot delete task of FCD %s: %w", fcdID, err)
+	}
+
+	logger.Info("Successfully deleted FCD snapshot", "fcdID", fcdID, "snapshotID", snapshotID)
+	return nil
+}
+
+// ListSnapshots lists the snapshots of a First Class Disk
+func (m *FCDManager) ListSnapshots(ctx context.Context, fcdID string) ([]FCDSnapshotInfo, error) {
+	var snapshots []types.VStorageObjectSnapshotInfoVStorageObjectSnapshot
+	err := m.withGlobalObjectManager(ctx, false, func(globalObjMgr *vslm.GlobalObjectManager) error {
+		var err error
+		snapshots, err = globalObjMgr.RetrieveSnapshotInfo(ctx, types.ID{Id: fcdID})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots of FCD %s: %w", fcdID, err)
+	}
+
+	var result []FCDSnapshotInfo
+	for _, snapshot := range snapshots {
+		result = append(result, FCDSnapshotInfo{
+			ID:          snapshot.Id.Id,
+			Description: snapshot.Description,
+			CreateTime:  snapshot.CreateTime,
+		})
+	}
+
+	return result, nil
+}
+
 // ParseDatastorePath parses a datastore path in the format [datastore] path/to/file.vmdk
 func ParseDatastorePath(path string) (datastoreName, filePath string, err error) {
 	// Remove leading bracket
@@ -271,15 +559,73 @@ func (m *FCDManager) GetDatastoreFromPath(ctx context.Context, path string) (*ob
 	return ds, nil
 }
 
-// ParseCSIVolumeHandle parses a vSphere CSI volume handle
-// Format: file://<uuid> or just <uuid>
-// Returns the FCD ID
+// ErrFCDNotFound is returned when vCenter reports that no FCD has the requested ID, such as one
+// deleted out of band, as opposed to failing to look it up
+var ErrFCDNotFound = errors.New("FCD not found")
+
+// isVStorageObjectNotFound reports whether a vslm retrieval failed because the ID is unknown.
+// vCenter raises NotFound; vcsim raises InvalidArgument on the VolumeId.
+func isVStorageObjectNotFound(err error) bool {
+	if fault.Is(err, &types.NotFound{}) {
+		return true
+	}
+	var invalid *types.InvalidArgument
+	if _, ok := fault.As(err, &invalid); ok {
+		return invalid.InvalidProperty == "VolumeId"
+	}
+	return false
+}
+
+// ErrUnrecognizedVolumeHandle is returned for a volume handle that does not name an FCD in any
+// of the formats ParseCSIVolumeHandle recognizes
+var ErrUnrecognizedVolumeHandle = errors.New("unrecognized vSphere CSI volume handle")
+
+// fcdIDPattern matches an FCD ID, which vCenter issues as a UUID
+var fcdIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ParseCSIVolumeHandle parses a vSphere CSI volume handle and returns the FCD ID it names.
+// Recognized formats:
+//   - <uuid>, as the vSphere CSI driver writes for block volumes
+//   - file://<uuid>, as written by BuildCSIVolumeHandle
+//   - <qualifier>/<uuid> or <qualifier>:<uuid>, where newer drivers qualify the ID with a
+//     datastore URL or namespace, such as ds:///vmfs/volumes/<datastore>/<uuid>
+//
+// vSAN file share handles (file:<uuid>) and paravirtual CSI handles naming a Supervisor PVC
+// (<cluster-uuid>-<pvc-uuid>) are not FCDs and are rejected, as is any other handle whose ID is
+// not a UUID.
 func ParseCSIVolumeHandle(volumeHandle string) (fcdID string, err error) {
-	if strings.HasPrefix(volumeHandle, "file://") {
-		return strings.TrimPrefix(volumeHandle, "file://"), nil
+	handle := strings.TrimSpace(volumeHandle)
+	switch {
+	case handle == "":
+		return "", fmt.Errorf("%w: handle is empty", ErrUnrecognizedVolumeHandle)
+	case strings.HasPrefix(handle, "file://"):
+		handle = strings.TrimPrefix(handle, "file://")
+	case strings.HasPrefix(handle, "file:"):
+		return "", fmt.Errorf("%w %q: vSAN file share volumes are not FCDs", ErrUnrecognizedVolumeHandle, volumeHandle)
+	}
+
+	if fcdIDPattern.MatchString(handle) {
+		return handle, nil
+	}
+	if isSupervisorPVCHandle(handle) {
+		return "", fmt.Errorf("%w %q: paravirtual CSI handles name a Supervisor PVC, not an FCD", ErrUnrecognizedVolumeHandle, volumeHandle)
 	}
-	// Some formats may just be the UUID
-	return volumeHandle, nil
+
+	// A qualified handle carries the FCD ID as its last segment
+	if i := strings.LastIndexAny(handle, "/:"); i >= 0 {
+		if id := handle[i+1:]; fcdIDPattern.MatchString(id) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q: expected an FCD UUID, optionally prefixed with file:// or a datastore or namespace qualifier", ErrUnrecognizedVolumeHandle, volumeHandle)
+}
+
+// isSupervisorPVCHandle reports whether a handle is two UUIDs joined by a dash, the name of the
+// Supervisor PVC backing a paravirtual CSI volume
+func isSupervisorPVCHandle(handle string) bool {
+	const uuidLen = 36
+	return len(handle) == 2*uuidLen+1 && handle[uuidLen] == '-' &&
+		fcdIDPattern.MatchString(handle[:uuidLen]) && fcdIDPattern.MatchString(handle[uuidLen+1:])
 }
 
 // BuildCSIVolumeHandle builds a vSphere CSI volume handle from an FCD ID
@@ -311,26 +657,75 @@ func extractBackingObjectId(backing types.BaseVirtualDeviceBackingInfo) string {
 	}
 }
 
-// IsFCDAttachedToVM checks if an FCD is attached to a specific VM
-// Returns: attached bool, error
-func (m *FCDManager) IsFCDAttachedToVM(ctx context.Context, vm *object.VirtualMachine, fcdID string) (bool, error) {
+// extractDiskSharing returns the sharing mode of a virtual disk backing, empty when it has none
+func extractDiskSharing(backing types.BaseVirtualDeviceBackingInfo) string {
+	switch b := backing.(type) {
+	case *types.VirtualDiskFlatVer2BackingInfo:
+		return b.Sharing
+	case *types.VirtualDiskRawDiskMappingVer1BackingInfo:
+		return b.Sharing
+	case *types.VirtualDiskRawDiskVer2BackingInfo:
+		return b.Sharing
+	default:
+		return ""
+	}
+}
+
+// FCDAttachment describes an FCD attached to a VM
+type FCDAttachment struct {
+	// VMName is the VM the FCD is attached to
+	VMName string
+	// Sharing is the disk sharing mode, e.g. sharingMultiWriter
+	Sharing string
+	// SharedBus is the bus sharing of the SCSI controller the disk is on, e.g. physicalSharing
+	SharedBus string
+}
+
+// Shared reports whether the disk is attached in a mode that lets other VMs write to it
+func (a FCDAttachment) Shared() bool {
+	return a.Sharing == string(types.VirtualDiskSharingSharingMultiWriter) ||
+		(a.SharedBus != "" && a.SharedBus != string(types.VirtualSCSISharingNoSharing))
+}
+
+// fcdAttachmentOnVM returns how an FCD is attached to a VM, or nil when it is not attached
+func (m *FCDManager) fcdAttachmentOnVM(ctx context.Context, vm *object.VirtualMachine, fcdID string) (*FCDAttachment, error) {
 	var vmMo mo.VirtualMachine
-	err := vm.Properties(ctx, vm.Reference(), []string{"config.hardware.device"}, &vmMo)
+	err := m.client.withReconnect(ctx, false, func(ctx context.Context) error {
+		return vm.Properties(ctx, vm.Reference(), []string{"name", "config.hardware.device"}, &vmMo)
+	})
 	if err != nil {
-		return false, fmt.Errorf("failed to get VM properties: %w", err)
+		return nil, fmt.Errorf("failed to get VM properties: %w", err)
+	}
+	if vmMo.Config == nil {
+		return nil, nil
 	}
 
-	for _, device := range vmMo.Config.Hardware.Device {
-		if disk, ok := device.(*types.VirtualDisk); ok {
-			// Check if this disk has FCD backing using multiple backing types
-			backingObjectId := extractBackingObjectId(disk.Backing)
-			if backingObjectId == fcdID {
-				return true, nil
-			}
+	devices := object.VirtualDeviceList(vmMo.Config.Hardware.Device)
+	for _, device := range devices {
+		disk, ok := device.(*types.VirtualDisk)
+		// Check if this disk has FCD backing using multiple backing types
+		if !ok || extractBackingObjectId(disk.Backing) != fcdID {
+			continue
+		}
+
+		attachment := &FCDAttachment{VMName: vmMo.Name, Sharing: extractDiskSharing(disk.Backing)}
+		if controller, ok := devices.FindByKey(disk.ControllerKey).(types.BaseVirtualSCSIController); ok {
+			attachment.SharedBus = string(controller.GetVirtualSCSIController().SharedBus)
 		}
+		return attachment, nil
 	}
 
-	return false, nil
+	return nil, nil
+}
+
+// IsFCDAttachedToVM checks if an FCD is attached to a specific VM
+// Returns: attached bool, error
+func (m *FCDManager) IsFCDAttachedToVM(ctx context.Context, vm *object.VirtualMachine, fcdID string) (bool, error) {
+	attachment, err := m.fcdAttachmentOnVM(ctx, vm, fcdID)
+	if err != nil {
+		return false, err
+	}
+	return attachment != nil, nil
 }
 
 // VerifyFCDNotAttachedToVM directly checks VM hardware config to confirm VMDK is detached
@@ -353,6 +748,35 @@ func (m *FCDManager) VerifyFCDNotAttachedToVM(ctx context.Context, vm *object.Vi
 	return nil
 }
 
+// VerifyFCDAttachedToVM reads back the VM hardware config to confirm an attach actually persisted.
+// Some vCenter versions report AttachDisk success without the disk appearing on the VM, so
+// the device list is polled until the FCD shows up or the timeout is exceeded.
+// Returns nil if the FCD is present on the VM, error otherwise
+func (m *FCDManager) VerifyFCDAttachedToVM(ctx context.Context, vm *object.VirtualMachine, fcdID string, timeout time.Duration) error {
+	logger := klog.FromContext(ctx)
+	logger.V(2).Info("Verifying FCD attach via VM device read-back",
+		"fcdID", fcdID, "vm", vm.Name(), "timeout", timeout)
+
+	err := util.PollUntil(ctx, util.DefaultBackoff, timeout, func(ctx context.Context) (bool, error) {
+		attached, err := m.IsFCDAttachedToVM(ctx, vm, fcdID)
+		if err != nil {
+			return false, fmt.Errorf("failed to read back FCD attachment on VM %s: %w", vm.Name(), err)
+		}
+
+		if attached {
+			logger.V(2).Info("Verified FCD is attached to VM", "fcdID", fcdID, "vm", vm.Name())
+			return true, nil
+		}
+
+		logger.V(2).Info("FCD not yet visible on VM, waiting", "fcdID", fcdID, "vm", vm.Name())
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("attach verification failed: FCD %s is not present in the device list of VM %s after attach", fcdID, vm.Name())
+	}
+	return err
+}
+
 // IsFCDAttached checks if an FCD is attached to any VM in the specified folder
 // Returns: attached bool, vmName string (if attached), error
 func (m *FCDManager) IsFCDAttached(ctx context.Context, datacenter string, folderPath string, fcdID string) (bool, string, error) {
@@ -381,36 +805,54 @@ func (m *FCDManager) IsFCDAttached(ctx context.Context, datacenter string, folde
 	return false, "", nil
 }
 
+// CountFCDAttachments returns every VM in the specified folder an FCD is attached to. Unlike
+// IsFCDAttached it does not stop at the first VM and fails when any VM cannot be checked, so a
+// shared disk is never mistaken for one with a single attachment.
+func (m *FCDManager) CountFCDAttachments(ctx context.Context, datacenter string, folderPath string, fcdID string) ([]FCDAttachment, error) {
+	vms, err := m.client.ListVirtualMachinesInFolder(ctx, datacenter, folderPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VMs in folder: %w", err)
+	}
+
+	var attachments []FCDAttachment
+	for _, vm := range vms {
+		attachment, err := m.fcdAttachmentOnVM(ctx, vm, fcdID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check FCD attachment on VM %s: %w", vm.Name(), err)
+		}
+		if attachment != nil {
+			attachments = append(attachments, *attachment)
+		}
+	}
+
+	return attachments, nil
+}
+
 // WaitForFCDDetached polls until the FCD is no longer attached to any VM
 // Returns error if timeout is exceeded
 func (m *FCDManager) WaitForFCDDetached(ctx context.Context, datacenter string, folderPath string, fcdID string, timeout time.Duration) error {
 	logger := klog.FromContext(ctx)
 
-	const pollInterval = 5 * time.Second
-	deadline := time.Now().Add(timeout)
-
-	for {
-		attached, vmName, err := m.IsFCDAttached(ctx, datacenter, folderPath, fcdID)
+	var vmName string
+	err := util.PollUntil(ctx, util.DefaultBackoff, timeout, func(ctx context.Context) (bool, error) {
+		var attached bool
+		var err error
+		attached, vmName, err = m.IsFCDAttached(ctx, datacenter, folderPath, fcdID)
 		if err != nil {
 			// Return immediately on finder/configuration errors
-			return fmt.Errorf("failed to check FCD attachment: %w", err)
+			return false, fmt.Errorf("failed to check FCD attachment: %w", err)
 		}
 
 		if !attached {
 			logger.V(2).Info("FCD is not attached to any VM", "fcdID", fcdID)
-			return nil
-		}
-
-		if time.Now().After(deadline) {
-			return fmt.Errorf("timeout waiting for FCD %s to be detached from VM %s", fcdID, vmName)
+			return true, nil
 		}
 
 		logger.V(2).Info("FCD still attached, waiting", "fcdID", fcdID, "vm", vmName)
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(pollInterval):
-			// Continue polling
-		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout waiting for FCD %s to be detached from VM %s", fcdID, vmName)
 	}
+	return err
 }
@@ -6,9 +6,13 @@ import (
 	"path"
 	"strings"
 
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
 )
 
 // CreateVMFolder creates a VM folder if it doesn't exist
@@ -61,6 +65,66 @@ func (c *Client) CreateVMFolder(ctx context.Context, datacenterName, folderPath
 	return newFolder, nil
 }
 
+// EnsureFolder returns the VM folder at folderPath, creating it and any missing parent folders
+// under the datacenter's VM folder. The path may be absolute (/{datacenter}/vm/a/b) or relative
+// to the VM folder (a/b). Folders created concurrently by someone else are reused.
+func (c *Client) EnsureFolder(ctx context.Context, datacenterName, folderPath string) (*object.Folder, error) {
+	logger := klog.FromContext(ctx)
+
+	dc, err := c.GetDatacenter(ctx, datacenterName)
+	if err != nil {
+		return nil, err
+	}
+	folders, err := dc.Folders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get datacenter folders: %w", err)
+	}
+
+	rel := util.NormalizeInventoryPath(datacenterName, util.VMFolder, folderPath) + "/"
+	if _, after, found := strings.Cut(rel, "/"+util.VMFolder+"/"); found {
+		rel = after
+	}
+	rel = strings.Trim(rel, "/")
+	if rel == "" {
+		return nil, fmt.Errorf("folder path %q does not name a folder under the VM folder of datacenter %s", folderPath, datacenterName)
+	}
+
+	finder := find.NewFinder(c.vimClient, false)
+	finder.SetDatacenter(dc)
+
+	parent := folders.VmFolder
+	current := path.Join(dc.InventoryPath, util.VMFolder)
+	for _, name := range strings.Split(rel, "/") {
+		current = path.Join(current, name)
+
+		existing, err := finder.Folder(ctx, current)
+		if err == nil {
+			parent = existing
+			continue
+		}
+		if !IsNotFound(err) {
+			return nil, fmt.Errorf("failed to look up VM folder %s: %w", current, err)
+		}
+
+		created, err := parent.CreateFolder(ctx, name)
+		if err != nil {
+			if !fault.Is(err, &types.DuplicateName{}) {
+				return nil, fmt.Errorf("failed to create VM folder %s: %w", current, err)
+			}
+			// Created by someone else since the lookup
+			if created, err = finder.Folder(ctx, current); err != nil {
+				return nil, fmt.Errorf("failed to look up VM folder %s: %w", current, err)
+			}
+		} else {
+			logger.Info("Created VM folder", "path", current, "moref", created.Reference())
+		}
+		created.InventoryPath = current
+		parent = created
+	}
+
+	return parent, nil
+}
+
 // GetVMFolder gets a VM folder by path
 func (c *Client) GetVMFolder(ctx context.Context, datacenterName, folderPath string) (*object.Folder, error) {
 	// Get datacenter
@@ -103,7 +167,9 @@ func (c *Client) DeleteVMFolder(ctx context.Context, folder *object.Folder) erro
 		return fmt.Errorf("failed to delete VM folder: %w", err)
 	}
 
-	if err := task.Wait(ctx); err != nil {
+	waitCtx, cancel := c.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
 		return fmt.Errorf("failed to wait for folder deletion: %w", err)
 	}
 
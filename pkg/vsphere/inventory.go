@@ -0,0 +1,126 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/vmware/govmomi/find"
+)
+
+// InventoryKind is the type of inventory object a failure domain topology path refers to
+type InventoryKind string
+
+const (
+	InventoryComputeCluster   InventoryKind = "compute cluster"
+	InventoryDatastore        InventoryKind = "datastore"
+	InventoryResourcePool     InventoryKind = "resource pool"
+	InventoryFolder           InventoryKind = "folder"
+	InventoryDatastoreCluster InventoryKind = "datastore cluster"
+)
+
+// inventoryRoots is the datacenter sub-folder searched for each kind of object
+var inventoryRoots = map[InventoryKind]string{
+	InventoryComputeCluster:   "host",
+	InventoryDatastore:        "datastore",
+	InventoryResourcePool:     "host",
+	InventoryFolder:           "vm",
+	InventoryDatastoreCluster: "datastore",
+}
+
+// ResolveInventoryPath looks up an object of the given kind by path within a datacenter and returns
+// its inventory path. When nothing is found at the path, the datacenter is searched recursively for
+// a single object with the same name, so a bare name nested in a sub-folder still resolves. The
+// error names every path tried.
+func (c *Client) ResolveInventoryPath(ctx context.Context, datacenter string, kind InventoryKind, p string) (string, error) {
+	root, ok := inventoryRoots[kind]
+	if !ok {
+		return "", fmt.Errorf("unsupported inventory kind %q", kind)
+	}
+
+	dc, err := c.GetDatacenter(ctx, datacenter)
+	if err != nil {
+		return "", err
+	}
+	finder := find.NewFinder(c.vimClient, false)
+	finder.SetDatacenter(dc)
+
+	found, err := findInventoryPaths(ctx, finder, kind, p)
+	if err == nil && len(found) == 1 {
+		return found[0], nil
+	}
+	if err != nil && !IsNotFound(err) {
+		return "", fmt.Errorf("failed to find %s %s: %w", kind, p, err)
+	}
+
+	search := fmt.Sprintf("%s/%s/...", dc.InventoryPath, root)
+	all, err := findInventoryPaths(ctx, finder, kind, search)
+	if err != nil && !IsNotFound(err) {
+		return "", fmt.Errorf("failed to search %s for %s %s: %w", search, kind, p, err)
+	}
+
+	var matches []string
+	for _, candidate := range all {
+		if path.Base(candidate) == path.Base(p) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return "", fmt.Errorf("%s %s not found in datacenter %s (tried %s and a search of %s)", kind, p, datacenter, p, search)
+	default:
+		return "", fmt.Errorf("%s %s not found in datacenter %s (tried %s); a search of %s found several with that name: %s",
+			kind, p, datacenter, p, search, strings.Join(matches, ", "))
+	}
+}
+
+// findInventoryPaths lists the inventory paths of the objects of the given kind matching a path
+func findInventoryPaths(ctx context.Context, finder *find.Finder, kind InventoryKind, p string) ([]string, error) {
+	var paths []string
+	switch kind {
+	case InventoryComputeCluster:
+		clusters, err := finder.ClusterComputeResourceList(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters {
+			paths = append(paths, cluster.InventoryPath)
+		}
+	case InventoryDatastore:
+		datastores, err := finder.DatastoreList(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, ds := range datastores {
+			paths = append(paths, ds.InventoryPath)
+		}
+	case InventoryDatastoreCluster:
+		pods, err := finder.DatastoreClusterList(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			paths = append(paths, pod.InventoryPath)
+		}
+	case InventoryResourcePool:
+		pools, err := finder.ResourcePoolList(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range pools {
+			paths = append(paths, pool.InventoryPath)
+		}
+	case InventoryFolder:
+		folders, err := finder.FolderList(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range folders {
+			paths = append(paths, folder.InventoryPath)
+		}
+	}
+	return paths, nil
+}
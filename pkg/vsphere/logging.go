@@ -7,11 +7,28 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/vmware/govmomi/vim25/soap"
 	"k8s.io/klog/v2"
 )
 
+// maxLogEntries bounds the number of SOAP/REST entries kept in memory per client.
+// Long migrations poll vCenter continuously, so the oldest entries are dropped first.
+const maxLogEntries = 1000
+
+// passwordElement matches password elements in SOAP bodies (Login, ServiceLocator credentials)
+var passwordElement = regexp.MustCompile(`(?is)(<(?:\w+:)?password(?:\s[^>]*)?>).*?(</(?:\w+:)?password>)`)
+
+// redactSOAPBody masks credentials so SOAP bodies can be logged and exported safely
+func redactSOAPBody(body string) string {
+	return passwordElement.ReplaceAllString(body, "${1}REDACTED${2}")
+}
+
 // SOAPLogEntry represents a SOAP API call log entry
 type SOAPLogEntry struct {
 	Timestamp    time.Time
@@ -36,21 +53,24 @@ type RESTLogEntry struct {
 
 // SOAPLogger logs SOAP calls
 type SOAPLogger struct {
+	mu      sync.Mutex
 	entries []SOAPLogEntry
+	server  string
 }
 
-// NewSOAPLogger creates a new SOAP logger
-func NewSOAPLogger() *SOAPLogger {
+// NewSOAPLogger creates a new SOAP logger for calls to server
+func NewSOAPLogger(server string) *SOAPLogger {
 	return &SOAPLogger{
 		entries: make([]SOAPLogEntry, 0),
+		server:  server,
 	}
 }
 
 // LogSOAPCall logs a SOAP API call
 func (l *SOAPLogger) LogSOAPCall(ctx context.Context, method string, req, res interface{}, duration time.Duration, err error) {
 	// Marshal request and response for logging
-	reqBody := l.marshalSOAPBody(req)
-	resBody := l.marshalSOAPBody(res)
+	reqBody := redactSOAPBody(l.marshalSOAPBody(req))
+	resBody := redactSOAPBody(l.marshalSOAPBody(res))
 
 	// If method is empty, extract from request
 	if method == "" {
@@ -66,7 +86,11 @@ func (l *SOAPLogger) LogSOAPCall(ctx context.Context, method string, req, res in
 		Error:        err,
 	}
 
-	l.entries = append(l.entries, entry)
+	l.mu.Lock()
+	l.entries = appendBounded(l.entries, entry)
+	l.mu.Unlock()
+
+	l.ObserveCall(method, duration, err)
 
 	// Log to klog
 	logger := klog.FromContext(ctx)
@@ -87,6 +111,12 @@ func (l *SOAPLogger) LogSOAPCall(ctx context.Context, method string, req, res in
 		"response", resBody)
 }
 
+// ObserveCall records the latency of a SOAP call, and an error when it failed, in the vCenter
+// API metrics
+func (l *SOAPLogger) ObserveCall(method string, duration time.Duration, err error) {
+	observeAPICall(l.server, apiSOAP, method, duration, err != nil)
+}
+
 // marshalSOAPBody marshals a SOAP body to string
 func (l *SOAPLogger) marshalSOAPBody(body interface{}) string {
 	if body == nil {
@@ -102,33 +132,85 @@ func (l *SOAPLogger) marshalSOAPBody(body interface{}) string {
 }
 
 // extractSOAPMethod extracts the method name from a SOAP request
+// Request bodies are generated as *methods.<Method>Body, e.g. *methods.RelocateVM_TaskBody
 func (l *SOAPLogger) extractSOAPMethod(req interface{}) string {
-	// Try to get type name
-	return fmt.Sprintf("%T", req)
+	name := fmt.Sprintf("%T", req)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.TrimSuffix(name, "Body")
+}
+
+// RoundTripper wraps a SOAP round tripper so every call is recorded
+func (l *SOAPLogger) RoundTripper(rt soap.RoundTripper) soap.RoundTripper {
+	return &soapLoggerRoundTripper{
+		base:   rt,
+		logger: l,
+	}
+}
+
+type soapLoggerRoundTripper struct {
+	base   soap.RoundTripper
+	logger *SOAPLogger
+}
+
+func (t *soapLoggerRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	start := time.Now()
+	err := t.base.RoundTrip(ctx, req, res)
+	t.logger.LogSOAPCall(ctx, "", req, res, time.Since(start), err)
+	return err
 }
 
 // GetEntries returns all logged entries
 func (l *SOAPLogger) GetEntries() []SOAPLogEntry {
-	return l.entries
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]SOAPLogEntry(nil), l.entries...)
+}
+
+// GetFaults returns up to the last n entries whose call returned a fault or error, oldest first
+func (l *SOAPLogger) GetFaults(n int) []SOAPLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var faults []SOAPLogEntry
+	for i := len(l.entries) - 1; i >= 0 && len(faults) < n; i-- {
+		if l.entries[i].Error != nil {
+			faults = append(faults, l.entries[i])
+		}
+	}
+	slices.Reverse(faults)
+	return faults
 }
 
 // Clear clears all logged entries
 func (l *SOAPLogger) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.entries = make([]SOAPLogEntry, 0)
 }
 
 // RESTLogger logs REST API calls
 type RESTLogger struct {
+	mu      sync.Mutex
 	entries []RESTLogEntry
+	server  string
 }
 
-// NewRESTLogger creates a new REST logger
-func NewRESTLogger() *RESTLogger {
+// NewRESTLogger creates a new REST logger for calls to server
+func NewRESTLogger(server string) *RESTLogger {
 	return &RESTLogger{
 		entries: make([]RESTLogEntry, 0),
+		server:  server,
 	}
 }
 
+// ObserveCall records the latency of a REST call, and an error when it failed or returned an
+// HTTP error status, in the vCenter API metrics
+func (l *RESTLogger) ObserveCall(req *http.Request, statusCode int, duration time.Duration, err error) {
+	observeAPICall(l.server, apiREST, restOperation(req), duration, err != nil || statusCode >= http.StatusBadRequest)
+}
+
 // RoundTrip implements http.RoundTripper
 func (l *RESTLogger) RoundTrip(rt http.RoundTripper) http.RoundTripper {
 	return &restLoggerTransport{
@@ -187,7 +269,11 @@ func (t *restLoggerTransport) RoundTrip(req *http.Request) (*http.Response, erro
 		Error:          err,
 	}
 
-	t.logger.entries = append(t.logger.entries, entry)
+	t.logger.mu.Lock()
+	t.logger.entries = appendBounded(t.logger.entries, entry)
+	t.logger.mu.Unlock()
+
+	t.logger.ObserveCall(req, statusCode, duration, err)
 
 	// Log to klog
 	ctx := req.Context()
@@ -218,10 +304,38 @@ func (t *restLoggerTransport) RoundTrip(req *http.Request) (*http.Response, erro
 
 // GetEntries returns all logged entries
 func (l *RESTLogger) GetEntries() []RESTLogEntry {
-	return l.entries
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]RESTLogEntry(nil), l.entries...)
+}
+
+// GetErrors returns up to the last n entries that failed or returned an HTTP error status, oldest first
+func (l *RESTLogger) GetErrors(n int) []RESTLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []RESTLogEntry
+	for i := len(l.entries) - 1; i >= 0 && len(errs) < n; i-- {
+		if l.entries[i].Error != nil || l.entries[i].ResponseStatus >= http.StatusBadRequest {
+			errs = append(errs, l.entries[i])
+		}
+	}
+	slices.Reverse(errs)
+	return errs
 }
 
 // Clear clears all logged entries
 func (l *RESTLogger) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	l.entries = make([]RESTLogEntry, 0)
 }
+
+// appendBounded appends an entry, dropping the oldest entries beyond maxLogEntries
+func appendBounded[T any](entries []T, entry T) []T {
+	entries = append(entries, entry)
+	if len(entries) > maxLogEntries {
+		entries = entries[len(entries)-maxLogEntries:]
+	}
+	return entries
+}
@@ -0,0 +1,76 @@
+package vsphere
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	apiSOAP = "soap"
+	apiREST = "rest"
+)
+
+var (
+	// apiCallDuration tracks how long vCenter takes to answer, so a slow migration can be told
+	// apart from a slow vCenter
+	apiCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "vcf_migration",
+		Subsystem: "vcenter",
+		Name:      "api_call_duration_seconds",
+		Help:      "Latency of vCenter API calls by vCenter, API (soap or rest) and operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	}, []string{"vcenter", "api", "operation"})
+
+	// apiCallErrors counts calls that returned a fault, a transport error or an HTTP error status
+	apiCallErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "vcf_migration",
+		Subsystem: "vcenter",
+		Name:      "api_call_errors_total",
+		Help:      "vCenter API calls that failed, by vCenter, API (soap or rest) and operation.",
+	}, []string{"vcenter", "api", "operation"})
+)
+
+// RegisterMetrics registers the vCenter API call metrics recorded by every client's SOAP and
+// REST loggers
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{apiCallDuration, apiCallErrors} {
+		if err := registerer.Register(collector); err != nil {
+			var already prometheus.AlreadyRegisteredError
+			if errors.As(err, &already) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// observeAPICall records the latency of a vCenter call and whether it failed
+func observeAPICall(server, api, operation string, duration time.Duration, failed bool) {
+	apiCallDuration.WithLabelValues(server, api, operation).Observe(duration.Seconds())
+	if failed {
+		apiCallErrors.WithLabelValues(server, api, operation).Inc()
+	}
+}
+
+// restOperation names a REST call by its method and path with object IDs replaced, so metrics
+// are kept per operation and not per object. vAPI actions such as ?~action=attach are kept.
+func restOperation(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "id:") || strings.HasPrefix(segment, "urn:") {
+			segments[i] = "{id}"
+		}
+	}
+	operation := req.Method + " /" + strings.Join(segments, "/")
+	if action := req.URL.Query().Get("~action"); action != "" {
+		operation += "?~action=" + action
+	} else if action := req.URL.Query().Get("action"); action != "" {
+		operation += "?action=" + action
+	}
+	return operation
+}
@@ -0,0 +1,106 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Privileges the migration service account needs on each kind of inventory object. They are
+// checked on both vCenters: dummy VMs are created on the source and vMotioned into the same
+// kinds of objects on the target.
+var (
+	// DatacenterPrivileges are needed to query volumes and their storage
+	DatacenterPrivileges = []string{
+		"StorageViews.View",
+	}
+
+	// DatastorePrivileges are needed to place VM files and snapshot or register disks
+	DatastorePrivileges = []string{
+		"Datastore.AllocateSpace",
+		"Datastore.FileManagement",
+	}
+
+	// ResourcePoolPrivileges are needed to place dummy VMs and worker nodes
+	ResourcePoolPrivileges = []string{
+		"Resource.AssignVMToPool",
+	}
+
+	// FolderPrivileges are needed to create, reconfigure, migrate and delete dummy VMs
+	FolderPrivileges = []string{
+		"VirtualMachine.Inventory.Create",
+		"VirtualMachine.Inventory.Delete",
+		"VirtualMachine.Config.AddExistingDisk",
+		"VirtualMachine.Config.RemoveDisk",
+		"VirtualMachine.Interact.PowerOff",
+		"Resource.ColdMigrate",
+	}
+
+	// PowerOnPrivileges are additionally needed on the folder to validate disks by powering on
+	// the dummy VM before relocating it
+	PowerOnPrivileges = []string{
+		"VirtualMachine.Interact.PowerOn",
+	}
+
+	// FolderCreatePrivileges are needed on the parent of a VM folder the migration creates
+	FolderCreatePrivileges = []string{
+		"Folder.Create",
+	}
+
+	// CryptographerPrivileges are additionally needed on the folder to move encrypted disks
+	CryptographerPrivileges = []string{
+		"Cryptographer.Access",
+		"Cryptographer.AddDisk",
+		"Cryptographer.Migrate",
+	}
+)
+
+// CheckPrivileges reports which of the given privileges the logged in user lacks on an entity,
+// taking privileges inherited from parent objects into account
+func (c *Client) CheckPrivileges(ctx context.Context, entity types.ManagedObjectReference, privileges []string) ([]string, error) {
+	if len(privileges) == 0 {
+		return nil, nil
+	}
+
+	var result []types.EntityPrivilege
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		userSession, err := c.govmomiClient.SessionManager.UserSession(ctx)
+		if err != nil {
+			return err
+		}
+		if userSession == nil {
+			return fmt.Errorf("not logged in")
+		}
+		res, err := methods.HasPrivilegeOnEntities(ctx, c.vimClient, &types.HasPrivilegeOnEntities{
+			This:      *c.vimClient.ServiceContent.AuthorizationManager,
+			Entity:    []types.ManagedObjectReference{entity},
+			SessionId: userSession.Key,
+			PrivId:    privileges,
+		})
+		if err != nil {
+			return err
+		}
+		result = res.Returnval
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check privileges on %s: %w", entity, err)
+	}
+
+	granted := make(map[string]bool, len(privileges))
+	for _, entityPrivilege := range result {
+		for _, availability := range entityPrivilege.PrivAvailability {
+			granted[availability.PrivId] = availability.IsGranted
+		}
+	}
+
+	var missing []string
+	for _, privilege := range privileges {
+		if !granted[privilege] {
+			missing = append(missing, privilege)
+		}
+	}
+	return missing, nil
+}
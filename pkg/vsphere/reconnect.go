@@ -0,0 +1,109 @@
+package vsphere
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+)
+
+// isNotAuthenticated reports whether vCenter rejected a call because the session is no longer valid
+func isNotAuthenticated(err error) bool {
+	return fault.Is(err, &types.NotAuthenticated{})
+}
+
+// isConnectionError reports whether a call failed because the connection to vCenter dropped
+func isConnectionError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ClassifyError marks errors caused by a lost session, a dropped connection or an unresponsive
+// vCenter as transient so the phase is retried rather than failed
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if isNotAuthenticated(err) || isConnectionError(err) || errors.Is(err, context.DeadlineExceeded) {
+		return phaseerrors.Transient(err)
+	}
+	return err
+}
+
+// lookupError classifies a failed inventory lookup: an object missing from the inventory is a
+// configuration problem, anything else is classified by ClassifyError
+func lookupError(err error) error {
+	if IsNotFound(err) {
+		return phaseerrors.Validation(err)
+	}
+	return ClassifyError(err)
+}
+
+// reconnect logs in to vCenter again with the stored credentials. If another caller already
+// re-established the session since generation was observed, the login is skipped.
+func (c *Client) reconnect(ctx context.Context, generation uint64) error {
+	logger := klog.FromContext(ctx)
+
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	if c.sessionGeneration.Load() != generation {
+		return nil
+	}
+
+	logger.Info("vCenter session lost, logging in again", "server", c.serverURL.Host)
+	if err := c.govmomiClient.SessionManager.Login(ctx, c.serverURL.User); err != nil {
+		return fmt.Errorf("failed to log in to vCenter again: %w", err)
+	}
+	c.sessionGeneration.Add(1)
+
+	logger.Info("Re-established vCenter session", "server", c.serverURL.Host)
+	return nil
+}
+
+// withReconnect runs fn and, when it fails because the vCenter session or connection was lost,
+// logs in again and retries it once. Calls that change state are only retried after vCenter
+// rejected them as unauthenticated: a dropped connection may have left them applied. Each
+// attempt is bounded by the operation timeout through the context passed to fn; a call that
+// times out is not retried.
+func (c *Client) withReconnect(ctx context.Context, mutating bool, fn func(ctx context.Context) error) error {
+	generation := c.sessionGeneration.Load()
+
+	attempt := func() error {
+		opCtx, cancel := c.operationContext(ctx)
+		defer cancel()
+		err := fn(opCtx)
+		if err != nil && ctx.Err() == nil && errors.Is(opCtx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("vCenter did not respond within %s: %w", c.operationTimeout, err)
+		}
+		return err
+	}
+
+	err := attempt()
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if !isNotAuthenticated(err) && (mutating || !isConnectionError(err)) {
+		return err
+	}
+
+	klog.FromContext(ctx).V(2).Info("vCenter call failed, reconnecting before retry", "error", err)
+	if reconnectErr := c.reconnect(ctx, generation); reconnectErr != nil {
+		return errors.Join(err, reconnectErr)
+	}
+
+	return attempt()
+}
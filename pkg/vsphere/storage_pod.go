@@ -0,0 +1,121 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/klog/v2"
+)
+
+// CheckStoragePod checks that a datastore cluster can receive relocated disks: Storage DRS must be
+// enabled on it and at least one member datastore must be accessible and not in maintenance mode
+func (c *Client) CheckStoragePod(ctx context.Context, path string) error {
+	pod, err := c.GetStoragePod(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	var props mo.StoragePod
+	err = c.withReconnect(ctx, false, func(ctx context.Context) error {
+		return pod.Properties(ctx, pod.Reference(), []string{"childEntity", "podStorageDrsEntry"}, &props)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get properties of datastore cluster %s: %w", path, err)
+	}
+
+	if props.PodStorageDrsEntry == nil || !props.PodStorageDrsEntry.StorageDrsConfig.PodConfig.Enabled {
+		return fmt.Errorf("storage DRS is not enabled on datastore cluster %s", path)
+	}
+	if len(props.ChildEntity) == 0 {
+		return fmt.Errorf("datastore cluster %s has no datastores", path)
+	}
+
+	var members []mo.Datastore
+	err = c.withReconnect(ctx, false, func(ctx context.Context) error {
+		return property.DefaultCollector(c.vimClient).Retrieve(ctx, props.ChildEntity, []string{"summary"}, &members)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get datastores of datastore cluster %s: %w", path, err)
+	}
+	for _, member := range members {
+		mode := member.Summary.MaintenanceMode
+		if member.Summary.Accessible && (mode == "" || mode == string(types.DatastoreSummaryMaintenanceModeStateNormal)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("datastore cluster %s has no accessible datastore outside maintenance mode", path)
+}
+
+// RecommendPodDatastore asks Storage DRS for the member datastore of a datastore cluster to place
+// the given disks of a VM on, in the given resource pool and folder. The placement is requested as
+// a VM creation, which the target vCenter can answer for a VM that is still on another vCenter.
+func (c *Client) RecommendPodDatastore(ctx context.Context, pod *object.StoragePod, pool, folder types.ManagedObjectReference, vmName string, disks []*types.VirtualDisk) (*object.Datastore, error) {
+	logger := klog.FromContext(ctx)
+
+	podRef := pod.Reference()
+	configSpec := &types.VirtualMachineConfigSpec{Name: vmName}
+	diskLocators := make([]types.PodDiskLocator, 0, len(disks))
+	for i, disk := range disks {
+		// Only the capacity of each disk matters to the placement
+		key := int32(-(i + 1))
+		configSpec.DeviceChange = append(configSpec.DeviceChange, &types.VirtualDeviceConfigSpec{
+			Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+			FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+			Device: &types.VirtualDisk{
+				VirtualDevice: types.VirtualDevice{
+					Key: key,
+					Backing: &types.VirtualDiskFlatVer2BackingInfo{
+						DiskMode:        string(types.VirtualDiskModePersistent),
+						ThinProvisioned: types.NewBool(true),
+					},
+				},
+				CapacityInKB: disk.CapacityInKB,
+			},
+		})
+		diskLocators = append(diskLocators, types.PodDiskLocator{DiskId: key})
+	}
+
+	spec := types.StoragePlacementSpec{
+		Type:         string(types.StoragePlacementSpecPlacementTypeCreate),
+		ResourcePool: &pool,
+		Folder:       &folder,
+		ConfigSpec:   configSpec,
+		PodSelectionSpec: types.StorageDrsPodSelectionSpec{
+			StoragePod: &podRef,
+			InitialVmConfig: []types.VmPodConfigForPlacement{{
+				StoragePod: podRef,
+				Disk:       diskLocators,
+			}},
+		},
+	}
+
+	var result *types.StoragePlacementResult
+	err := c.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		result, err = object.NewStorageResourceManager(c.vimClient).RecommendDatastores(ctx, spec)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage DRS recommendation for datastore cluster %s: %w", pod.InventoryPath, err)
+	}
+
+	for _, recommendation := range result.Recommendations {
+		for _, action := range recommendation.Action {
+			placement, ok := action.(*types.StoragePlacementAction)
+			if !ok {
+				continue
+			}
+			ds := object.NewDatastore(c.vimClient, placement.Destination)
+			logger.Info("Storage DRS recommended datastore",
+				"datastoreCluster", pod.InventoryPath,
+				"datastore", placement.Destination.Value,
+				"reason", recommendation.ReasonText)
+			return ds, nil
+		}
+	}
+	return nil, fmt.Errorf("storage DRS made no placement recommendation for datastore cluster %s", pod.InventoryPath)
+}
@@ -3,7 +3,10 @@ package vsphere
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
 
+	"github.com/vmware/govmomi/find"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/vapi/tags"
@@ -117,6 +120,41 @@ func (c *Client) AttachTag(ctx context.Context, tagID string, obj object.Referen
 	return nil
 }
 
+// EnsureTag returns the ID of the tag name in the category named category, creating either when
+// missing. A category created here allows several of its tags on an object of any type.
+func (c *Client) EnsureTag(ctx context.Context, category, name string) (string, error) {
+	logger := klog.FromContext(ctx)
+
+	if c.tagManager == nil {
+		return "", fmt.Errorf("tag manager not available (REST API not initialized)")
+	}
+
+	categories, err := c.tagManager.GetCategories(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tag categories: %w", err)
+	}
+
+	var categoryID string
+	for _, cat := range categories {
+		if cat.Name == category {
+			categoryID = cat.ID
+			break
+		}
+	}
+	if categoryID == "" {
+		categoryID, err = c.tagManager.CreateCategory(ctx, &tags.Category{
+			Name:        category,
+			Cardinality: "MULTIPLE",
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create tag category %s: %w", category, err)
+		}
+		logger.Info("Created tag category", "category", category, "id", categoryID)
+	}
+
+	return c.CreateTag(ctx, categoryID, name, "")
+}
+
 // CreateRegionAndZoneTags creates region and zone tag categories and tags
 func (c *Client) CreateRegionAndZoneTags(ctx context.Context, region, zone string) (regionTagID, zoneTagID string, err error) {
 	logger := klog.FromContext(ctx)
@@ -172,3 +210,54 @@ func (c *Client) AttachFailureDomainTags(ctx context.Context, regionTagID, zoneT
 	logger.Info("Successfully attached failure domain tags")
 	return nil
 }
+
+// FindTaggedResourcePool returns the inventory path of the one resource pool in the datacenter
+// carrying the tag name of the category, or of the root resource pool of the one compute cluster
+// carrying it. It fails when no compute cluster or resource pool there has the tag, or several do.
+func (c *Client) FindTaggedResourcePool(ctx context.Context, datacenter, category, name string) (string, error) {
+	logger := klog.FromContext(ctx)
+
+	if c.tagManager == nil {
+		return "", fmt.Errorf("tag manager not available (REST API not initialized)")
+	}
+
+	tag, err := c.tagManager.GetTagForCategory(ctx, name, category)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tag %s/%s: %w", category, name, err)
+	}
+
+	refs, err := c.tagManager.ListAttachedObjects(ctx, tag.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list objects tagged %s/%s: %w", category, name, err)
+	}
+
+	var pools []string
+	for _, ref := range refs {
+		moref := ref.Reference()
+		if moref.Type != "ClusterComputeResource" && moref.Type != "ResourcePool" {
+			continue
+		}
+		inventoryPath, err := find.InventoryPath(ctx, c.vimClient, moref)
+		if err != nil {
+			return "", fmt.Errorf("failed to get inventory path of %s: %w", moref, err)
+		}
+		if !strings.HasPrefix(inventoryPath, "/"+datacenter+"/") {
+			continue
+		}
+		if moref.Type == "ClusterComputeResource" {
+			inventoryPath = path.Join(inventoryPath, "Resources")
+		}
+		pools = append(pools, inventoryPath)
+	}
+
+	switch len(pools) {
+	case 0:
+		return "", fmt.Errorf("no compute cluster or resource pool in datacenter %s has tag %s/%s", datacenter, category, name)
+	case 1:
+		logger.Info("Resolved tagged placement", "category", category, "tag", name, "resourcePool", pools[0])
+		return pools[0], nil
+	default:
+		return "", fmt.Errorf("tag %s/%s matches %d placements in datacenter %s, expected one: %s",
+			category, name, len(pools), datacenter, strings.Join(pools, ", "))
+	}
+}
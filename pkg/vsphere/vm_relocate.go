@@ -1,8 +1,12 @@
 package vsphere
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,8 +14,40 @@ import (
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
 	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
 )
 
+const (
+	// MaxSCSIControllers is the number of SCSI controllers a VM supports
+	MaxSCSIControllers = 4
+
+	// UnitsPerSCSIController is the number of unit numbers on a SCSI controller, including
+	// the one reserved for the controller itself
+	UnitsPerSCSIController = 16
+
+	// scsiControllerUnit is the unit number reserved for the SCSI controller
+	scsiControllerUnit = 7
+
+	// RelocateTaskTimeout bounds the wait for a cross-vCenter vMotion, which copies the disk
+	// contents and so runs far longer than the per-call operation timeout
+	RelocateTaskTimeout = 12 * time.Hour
+
+	// relocateCancelTimeout bounds the wait for vCenter to confirm a relocate task was cancelled
+	relocateCancelTimeout = 5 * time.Minute
+)
+
+// errRelocateTaskFailed is returned when vCenter reports the relocate task as failed, in which
+// case the vMotion was rolled back and the disks stayed on the source
+var errRelocateTaskFailed = errors.New("VM relocation task failed")
+
+// ErrRelocateTaskTimedOut is returned when a relocate or clone task runs longer than its
+// maximum duration and is cancelled
+var ErrRelocateTaskTimedOut = errors.New("VM relocation task exceeded its maximum duration")
+
+// ErrRelocateNotViable is returned when vCenter reports a VM could not be relocated as configured
+var ErrRelocateNotViable = errors.New("VM relocation is not viable")
+
 // VMRelocator handles cross-vCenter VM relocation operations
 type VMRelocator struct {
 	sourceClient *Client
@@ -27,6 +63,10 @@ type RelocateConfig struct {
 	TargetVCenterThumbprint   string
 	TargetVCenterInstanceUUID string
 
+	// SameVCenter makes the relocate a storage-only move within the source vCenter. No
+	// ServiceLocator is built, so the target connection info above is not needed.
+	SameVCenter bool
+
 	// Target location
 	TargetDatacenter   string
 	TargetCluster      string
@@ -34,6 +74,26 @@ type RelocateConfig struct {
 	TargetFolder       string
 	TargetResourcePool string
 	TargetNetwork      string
+
+	// TargetDatastoreCluster places the relocated VM on the member datastore of this datastore
+	// cluster that Storage DRS recommends, instead of on TargetDatastore
+	TargetDatastoreCluster string
+
+	// TargetHost pins the relocated VM to a host of the target cluster, such as one with a
+	// vMotion or provisioning vmknic on a dedicated network. When empty, DRS places the VM.
+	TargetHost string
+
+	// MaxTaskDuration bounds how long the relocate or clone task may run before it is
+	// cancelled. When zero, RelocateTaskTimeout applies.
+	MaxTaskDuration time.Duration
+}
+
+// maxTaskDuration returns how long the relocate or clone task may run
+func (c RelocateConfig) maxTaskDuration() time.Duration {
+	if c.MaxTaskDuration > 0 {
+		return c.MaxTaskDuration
+	}
+	return RelocateTaskTimeout
 }
 
 // DummyVMConfig holds configuration for creating a dummy VM
@@ -134,10 +194,12 @@ func (r *VMRelocator) CreateDummyVM(ctx context.Context, config DummyVMConfig) (
 	// Create VM
 	task, err := folder.CreateVM(ctx, vmConfigSpec, resourcePool, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create VM: %w", err)
+		return nil, fmt.Errorf("failed to create VM: %w", ClassifyError(err))
 	}
 
-	taskInfo, err := task.WaitForResult(ctx, nil)
+	waitCtx, cancel := r.sourceClient.operationContext(ctx)
+	defer cancel()
+	taskInfo, err := task.WaitForResult(waitCtx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to wait for VM creation: %w", err)
 	}
@@ -149,11 +211,64 @@ func (r *VMRelocator) CreateDummyVM(ctx context.Context, config DummyVMConfig) (
 	return vm, nil
 }
 
-// DeleteDummyVM deletes a dummy VM used for migration
-func (r *VMRelocator) DeleteDummyVM(ctx context.Context, vm *object.VirtualMachine) error {
+// DeleteDummyVM deletes a dummy VM used for migration. The given FCDs are detached from the VM
+// first and the VM is only destroyed once none of them is left on it: destroying a VM with an
+// FCD attached can delete the disk along with it.
+func (r *VMRelocator) DeleteDummyVM(ctx context.Context, vm *object.VirtualMachine, fcdIDs ...string) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Deleting dummy VM", "name", vm.Name())
 
+	if err := r.releaseDummyVM(ctx, vm, fcdIDs); err != nil {
+		return fmt.Errorf("refusing to destroy VM %s: %w", vm.Name(), err)
+	}
+
+	// Delete VM
+	task, err := vm.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to destroy VM: %w", err)
+	}
+
+	waitCtx, cancel := r.sourceClient.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
+		return fmt.Errorf("failed to wait for VM destruction: %w", err)
+	}
+
+	logger.Info("Successfully deleted dummy VM", "name", vm.Name())
+	return nil
+}
+
+// RetainDummyVM powers off a dummy VM and detaches the given FCDs from it, verifying they are
+// gone, but keeps the VM so a failed relocation can be inspected. The VM is renamed to name,
+// freeing its original name for the dummy VM of a retry.
+func (r *VMRelocator) RetainDummyVM(ctx context.Context, vm *object.VirtualMachine, name string, fcdIDs ...string) error {
+	logger := klog.FromContext(ctx)
+	original := vm.Name()
+	logger.Info("Detaching volumes from dummy VM kept for inspection", "name", original, "fcdIDs", fcdIDs)
+
+	if err := r.releaseDummyVM(ctx, vm, fcdIDs); err != nil {
+		return err
+	}
+
+	task, err := vm.Rename(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to rename VM to %s: %w", name, err)
+	}
+	waitCtx, cancel := r.sourceClient.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
+		return fmt.Errorf("failed to wait for rename to %s: %w", name, err)
+	}
+
+	logger.Info("Volumes detached from dummy VM kept for inspection", "name", name, "originalName", original)
+	return nil
+}
+
+// releaseDummyVM powers off a dummy VM and detaches the given FCDs from it, so it can be
+// destroyed or kept without holding the volumes
+func (r *VMRelocator) releaseDummyVM(ctx context.Context, vm *object.VirtualMachine, fcdIDs []string) error {
+	logger := klog.FromContext(ctx)
+
 	// Power off if running
 	powerState, err := vm.PowerState(ctx)
 	if err != nil {
@@ -163,67 +278,378 @@ func (r *VMRelocator) DeleteDummyVM(ctx context.Context, vm *object.VirtualMachi
 		if err != nil {
 			return fmt.Errorf("failed to power off VM: %w", err)
 		}
-		if err := task.Wait(ctx); err != nil {
+		waitCtx, cancel := r.sourceClient.operationContext(ctx)
+		defer cancel()
+		if err := task.Wait(waitCtx); err != nil {
 			return fmt.Errorf("failed to wait for power off: %w", err)
 		}
 	}
 
-	// Delete VM
-	task, err := vm.Destroy(ctx)
+	if len(fcdIDs) > 0 {
+		fcdManager, err := NewFCDManager(ctx, r.sourceClient)
+		if err != nil {
+			return fmt.Errorf("failed to create FCD manager: %w", err)
+		}
+		if err := fcdManager.DetachDisks(ctx, vm, fcdIDs); err != nil {
+			return fmt.Errorf("volumes may still be attached: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// PowerOnVM powers on a VM and waits for the power on task to complete
+func (r *VMRelocator) PowerOnVM(ctx context.Context, vm *object.VirtualMachine) error {
+	task, err := vm.PowerOn(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to destroy VM: %w", err)
+		return fmt.Errorf("failed to power on VM: %w", ClassifyError(err))
 	}
+	waitCtx, cancel := r.sourceClient.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
+		return fmt.Errorf("failed to wait for power on: %w", ClassifyError(err))
+	}
+	return nil
+}
 
-	if err := task.Wait(ctx); err != nil {
-		return fmt.Errorf("failed to wait for VM destruction: %w", err)
+// PowerOffVM powers off a VM, if it is running, and waits for the power off task to complete
+func (r *VMRelocator) PowerOffVM(ctx context.Context, vm *object.VirtualMachine) error {
+	powerState, err := vm.PowerState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get power state: %w", ClassifyError(err))
 	}
+	if powerState == types.VirtualMachinePowerStatePoweredOff {
+		return nil
+	}
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to power off VM: %w", ClassifyError(err))
+	}
+	waitCtx, cancel := r.sourceClient.operationContext(ctx)
+	defer cancel()
+	if err := task.Wait(waitCtx); err != nil {
+		return fmt.Errorf("failed to wait for power off: %w", ClassifyError(err))
+	}
+	return nil
+}
 
-	logger.Info("Successfully deleted dummy VM", "name", vm.Name())
+// WaitForPowerState waits until a VM reaches a power state or the operation timeout expires
+func (r *VMRelocator) WaitForPowerState(ctx context.Context, vm *object.VirtualMachine, state types.VirtualMachinePowerState) error {
+	waitCtx, cancel := r.sourceClient.operationContext(ctx)
+	defer cancel()
+	if err := vm.WaitForPowerState(waitCtx, state); err != nil {
+		return fmt.Errorf("VM did not reach power state %s: %w", state, err)
+	}
 	return nil
 }
 
-// RelocateVM performs a cross-vCenter vMotion of a VM to the target vCenter
+// ValidateAttachedDisks briefly powers on a dummy VM so ESXi opens every attached disk, which
+// fails on a stale or corrupt backing that an attach alone does not detect, and powers it off
+// again so it can be relocated cold. The VM is powered off even when the power on fails.
+func (r *VMRelocator) ValidateAttachedDisks(ctx context.Context, vm *object.VirtualMachine) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Powering on dummy VM to validate its disks", "name", vm.Name())
+
+	validateErr := r.PowerOnVM(ctx, vm)
+	if validateErr == nil {
+		validateErr = r.WaitForPowerState(ctx, vm, types.VirtualMachinePowerStatePoweredOn)
+	}
+	if err := r.PowerOffVM(ctx, vm); err != nil {
+		return errors.Join(validateErr, err)
+	}
+	if err := r.WaitForPowerState(ctx, vm, types.VirtualMachinePowerStatePoweredOff); err != nil {
+		return errors.Join(validateErr, err)
+	}
+	if validateErr != nil {
+		return fmt.Errorf("attached disks could not be opened: %w", validateErr)
+	}
+
+	logger.Info("Validated dummy VM disks", "name", vm.Name())
+	return nil
+}
+
+// ListDummyVMs returns the VMs in a folder of the source vCenter whose names start with the dummy
+// VM name prefix, such as those left behind by a failed or interrupted migration
+func (r *VMRelocator) ListDummyVMs(ctx context.Context, datacenter, folderPath, prefix string) ([]*object.VirtualMachine, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("dummy VM name prefix must not be empty")
+	}
+
+	vms, err := r.sourceClient.ListVirtualMachinesInFolder(ctx, datacenter, folderPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var dummyVMs []*object.VirtualMachine
+	for _, vm := range vms {
+		if strings.HasPrefix(vm.Name(), prefix) {
+			dummyVMs = append(dummyVMs, vm)
+		}
+	}
+	return dummyVMs, nil
+}
+
+// AttachedDisk describes a virtual disk attached to a VM
+type AttachedDisk struct {
+	// FCDID is the ID of the FCD backing the disk, empty for a disk that is not an FCD
+	FCDID string
+	// FileName is the datastore path of the disk's VMDK
+	FileName string
+}
+
+// AttachedDisks lists the virtual disks attached to a VM of the source vCenter
+func (r *VMRelocator) AttachedDisks(ctx context.Context, vm *object.VirtualMachine) ([]AttachedDisk, error) {
+	disks, err := virtualDisks(ctx, r.sourceClient, vm)
+	if err != nil {
+		return nil, err
+	}
+
+	attached := make([]AttachedDisk, 0, len(disks))
+	for _, disk := range disks {
+		attached = append(attached, toAttachedDisk(disk))
+	}
+	return attached, nil
+}
+
+// RemoveDisks removes every virtual disk from a VM of the target vCenter while keeping the disk
+// files, so that destroying the VM afterwards leaves them in place. The removed disks are
+// returned in device order.
+func (r *VMRelocator) RemoveDisks(ctx context.Context, vm *object.VirtualMachine) ([]AttachedDisk, error) {
+	disks, err := virtualDisks(ctx, r.targetClient, vm)
+	if err != nil {
+		return nil, err
+	}
+	if len(disks) == 0 {
+		return nil, nil
+	}
+
+	removed := make([]AttachedDisk, 0, len(disks))
+	devices := make([]types.BaseVirtualDevice, 0, len(disks))
+	for _, disk := range disks {
+		removed = append(removed, toAttachedDisk(disk))
+		devices = append(devices, disk)
+	}
+
+	err = r.targetClient.withReconnect(ctx, true, func(ctx context.Context) error {
+		return vm.RemoveDevice(ctx, true, devices...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove disks from VM %s: %w", vm.Name(), err)
+	}
+	return removed, nil
+}
+
+// virtualDisks returns the virtual disks of a VM, in device order
+func virtualDisks(ctx context.Context, client *Client, vm *object.VirtualMachine) ([]*types.VirtualDisk, error) {
+	var vmMo mo.VirtualMachine
+	err := client.withReconnect(ctx, false, func(ctx context.Context) error {
+		return vm.Properties(ctx, vm.Reference(), []string{"config.hardware.device"}, &vmMo)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VM devices: %w", err)
+	}
+	if vmMo.Config == nil {
+		return nil, nil
+	}
+
+	var disks []*types.VirtualDisk
+	for _, device := range vmMo.Config.Hardware.Device {
+		if disk, ok := device.(*types.VirtualDisk); ok {
+			disks = append(disks, disk)
+		}
+	}
+	return disks, nil
+}
+
+// toAttachedDisk describes a virtual disk by its FCD ID and VMDK path
+func toAttachedDisk(disk *types.VirtualDisk) AttachedDisk {
+	attached := AttachedDisk{FCDID: extractBackingObjectId(disk.Backing)}
+	if backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+		attached.FileName = backing.GetVirtualDeviceFileBackingInfo().FileName
+	}
+	if attached.FCDID == "" && disk.VDiskId != nil {
+		attached.FCDID = disk.VDiskId.Id
+	}
+	return attached
+}
+
+// RelocateVM performs a cross-vCenter vMotion of a VM to the target vCenter. With
+// config.SameVCenter set it instead moves the VM and its disks to the target location within
+// the same vCenter.
 func (r *VMRelocator) RelocateVM(ctx context.Context, vm *object.VirtualMachine, config RelocateConfig) error {
 	logger := klog.FromContext(ctx)
 	logger.Info("Relocating VM to target vCenter",
 		"vm", vm.Name(),
 		"targetVCenter", config.TargetVCenterURL,
-		"targetDatacenter", config.TargetDatacenter)
+		"targetDatacenter", config.TargetDatacenter,
+		"sameVCenter", config.SameVCenter)
 
-	// Build service locator for target vCenter
-	serviceLocator, err := r.buildServiceLocator(config)
+	relocateSpec, _, err := r.buildRelocateSpec(ctx, vm, config)
 	if err != nil {
-		return fmt.Errorf("failed to build service locator: %w", err)
+		return err
+	}
+
+	// Relocate the VM
+	logger.Info("Starting VM relocation task")
+	task, err := vm.Relocate(ctx, relocateSpec, types.VirtualMachineMovePriorityDefaultPriority)
+	if err != nil {
+		r.logRecentFaults(ctx, vm.Name())
+		return fmt.Errorf("failed to start relocate task: %w", ClassifyError(err))
+	}
+
+	// Wait for relocation with progress logging. A task that could not be followed to the end
+	// may still move the disks, so the volumes must not be touched until someone has checked.
+	// A task cancelled for running too long was rolled back and can simply be retried.
+	if err := r.waitForRelocateTask(ctx, task, vm.Name(), config.maxTaskDuration()); err != nil {
+		r.logRecentFaults(ctx, vm.Name())
+		switch {
+		case !errors.Is(err, errRelocateTaskFailed):
+			err = phaseerrors.DataSafety(err)
+		case errors.Is(err, ErrRelocateTaskTimedOut):
+			err = phaseerrors.Transient(err)
+		}
+		return fmt.Errorf("relocation failed: %w", err)
+	}
+
+	logger.Info("Successfully relocated VM to target vCenter", "vm", vm.Name())
+	return nil
+}
+
+// CloneVM clones a VM, with full copies of its disks, to the target location as a powered-off VM
+// named name. Unlike RelocateVM the source VM and its disks are left untouched, so a clone that
+// fails or cannot be followed to the end never puts the source data at risk.
+func (r *VMRelocator) CloneVM(ctx context.Context, vm *object.VirtualMachine, name string, config RelocateConfig) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Cloning VM to target vCenter",
+		"vm", vm.Name(),
+		"clone", name,
+		"targetVCenter", config.TargetVCenterURL,
+		"targetDatacenter", config.TargetDatacenter,
+		"sameVCenter", config.SameVCenter)
+
+	relocateSpec, targetFolder, err := r.buildRelocateSpec(ctx, vm, config)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Starting VM clone task")
+	task, err := vm.Clone(ctx, targetFolder, name, types.VirtualMachineCloneSpec{
+		Location: relocateSpec,
+		PowerOn:  false,
+		Template: false,
+	})
+	if err != nil {
+		r.logRecentFaults(ctx, vm.Name())
+		return fmt.Errorf("failed to start clone task: %w", ClassifyError(err))
+	}
+
+	if err := r.waitForRelocateTask(ctx, task, vm.Name(), config.maxTaskDuration()); err != nil {
+		r.logRecentFaults(ctx, vm.Name())
+		return fmt.Errorf("clone failed: %w", err)
+	}
+
+	logger.Info("Successfully cloned VM to target vCenter", "vm", vm.Name(), "clone", name)
+	return nil
+}
+
+// CheckRelocate asks the source vCenter whether a VM could be relocated as configured, without
+// moving it. vCenter runs the checks of a real relocation against the ServiceLocator and target
+// placement, so cross-vCenter vMotion that is disabled, unlicensed or unable to reach the target
+// vCenter fails here. Warnings that do not prevent the relocation are returned.
+func (r *VMRelocator) CheckRelocate(ctx context.Context, vm *object.VirtualMachine, config RelocateConfig) ([]string, error) {
+	relocateSpec, _, err := r.buildRelocateSpec(ctx, vm, config)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := object.NewVmProvisioningChecker(r.sourceClient.vimClient)
+	var results []types.CheckResult
+	err = r.sourceClient.withReconnect(ctx, false, func(ctx context.Context) error {
+		var err error
+		results, err = checker.CheckRelocate(ctx, vm.Reference(), relocateSpec)
+		return err
+	})
+	if err != nil {
+		r.logRecentFaults(ctx, vm.Name())
+		return nil, fmt.Errorf("failed to check relocation of VM %s: %w", vm.Name(), ClassifyError(err))
+	}
+
+	var problems, warnings []string
+	for _, result := range results {
+		for _, fault := range result.Error {
+			problems = append(problems, checkFaultMessage(fault))
+		}
+		for _, fault := range result.Warning {
+			warnings = append(warnings, checkFaultMessage(fault))
+		}
+	}
+	if len(problems) > 0 {
+		return warnings, fmt.Errorf("%w: %s", ErrRelocateNotViable, strings.Join(problems, "; "))
+	}
+	return warnings, nil
+}
+
+// checkFaultMessage describes a fault of a relocation check by its message, or by its type
+// when vCenter sent none
+func checkFaultMessage(fault types.LocalizedMethodFault) string {
+	if fault.LocalizedMessage != "" {
+		return fault.LocalizedMessage
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", fault.Fault), "*types.")
+}
+
+// buildRelocateSpec looks up the target location of a relocate config and builds the placement
+// of a VM shared by relocation and cloning, returning it with the target folder
+func (r *VMRelocator) buildRelocateSpec(ctx context.Context, vm *object.VirtualMachine, config RelocateConfig) (types.VirtualMachineRelocateSpec, *object.Folder, error) {
+	logger := klog.FromContext(ctx)
+
+	// Build service locator for target vCenter, a move within one vCenter needs none
+	var serviceLocator *types.ServiceLocator
+	if !config.SameVCenter {
+		var err error
+		serviceLocator, err = r.buildServiceLocator(config)
+		if err != nil {
+			return types.VirtualMachineRelocateSpec{}, nil, phaseerrors.Validation(fmt.Errorf("failed to build service locator: %w", err))
+		}
 	}
 
 	// Get target datacenter
 	targetDC, err := r.targetClient.GetDatacenter(ctx, config.TargetDatacenter)
 	if err != nil {
-		return fmt.Errorf("failed to get target datacenter %s: %w", config.TargetDatacenter, err)
+		return types.VirtualMachineRelocateSpec{}, nil, fmt.Errorf("failed to get target datacenter %s: %w", config.TargetDatacenter, lookupError(err))
 	}
 	r.targetClient.finder.SetDatacenter(targetDC)
 
 	// Get target folder
 	targetFolder, err := r.targetClient.GetFolder(ctx, config.TargetFolder)
 	if err != nil {
-		return fmt.Errorf("failed to get target folder %s: %w", config.TargetFolder, err)
+		return types.VirtualMachineRelocateSpec{}, nil, fmt.Errorf("failed to get target folder %s: %w", config.TargetFolder, lookupError(err))
 	}
 
 	// Get target resource pool
 	targetResourcePool, err := r.targetClient.GetResourcePool(ctx, config.TargetResourcePool)
 	if err != nil {
-		return fmt.Errorf("failed to get target resource pool %s: %w", config.TargetResourcePool, err)
+		return types.VirtualMachineRelocateSpec{}, nil, fmt.Errorf("failed to get target resource pool %s: %w", config.TargetResourcePool, lookupError(err))
 	}
 
-	// Get target datastore
-	targetDatastore, err := r.targetClient.GetDatastore(ctx, config.TargetDatastore)
-	if err != nil {
-		return fmt.Errorf("failed to get target datastore %s: %w", config.TargetDatastore, err)
+	folderRef := targetFolder.Reference()
+	poolRef := targetResourcePool.Reference()
+
+	// Get target datastore, or the member of the target datastore cluster Storage DRS picks
+	var targetDatastore *object.Datastore
+	if config.TargetDatastoreCluster != "" {
+		targetDatastore, err = r.recommendPodDatastore(ctx, vm, config, poolRef, folderRef)
+		if err != nil {
+			return types.VirtualMachineRelocateSpec{}, nil, err
+		}
+	} else {
+		targetDatastore, err = r.targetClient.GetDatastore(ctx, config.TargetDatastore)
+		if err != nil {
+			return types.VirtualMachineRelocateSpec{}, nil, fmt.Errorf("failed to get target datastore %s: %w", config.TargetDatastore, lookupError(err))
+		}
 	}
 
 	// Build relocate spec
-	folderRef := targetFolder.Reference()
-	poolRef := targetResourcePool.Reference()
 	dsRef := targetDatastore.Reference()
 
 	relocateSpec := types.VirtualMachineRelocateSpec{
@@ -233,30 +659,127 @@ func (r *VMRelocator) RelocateVM(ctx context.Context, vm *object.VirtualMachine,
 		Datastore: &dsRef,
 	}
 
+	var hostRef types.ManagedObjectReference
+	if config.TargetHost != "" {
+		targetHost, err := r.targetClient.GetHost(ctx, config.TargetHost)
+		if err != nil {
+			return types.VirtualMachineRelocateSpec{}, nil, fmt.Errorf("failed to get target host %s: %w", config.TargetHost, lookupError(err))
+		}
+		hostRef = targetHost.Reference()
+		relocateSpec.Host = &hostRef
+	}
+
 	// Log relocate spec details for debugging
+	var serviceLocatorURL, serviceLocatorInstanceUUID string
+	if serviceLocator != nil {
+		serviceLocatorURL, serviceLocatorInstanceUUID = serviceLocator.Url, serviceLocator.InstanceUuid
+	}
 	logger.Info("Relocate spec details",
-		"serviceLocatorURL", serviceLocator.Url,
-		"serviceLocatorInstanceUUID", serviceLocator.InstanceUuid,
+		"serviceLocatorURL", serviceLocatorURL,
+		"serviceLocatorInstanceUUID", serviceLocatorInstanceUUID,
 		"targetFolder", folderRef.Value,
 		"targetPool", poolRef.Value,
-		"targetDatastore", dsRef.Value)
+		"targetDatastore", dsRef.Value,
+		"targetHost", hostRef.Value)
 
-	// Relocate the VM
-	logger.Info("Starting VM relocation task")
-	task, err := vm.Relocate(ctx, relocateSpec, types.VirtualMachineMovePriorityDefaultPriority)
+	return relocateSpec, targetFolder, nil
+}
+
+// recommendPodDatastore picks the member of the target datastore cluster to place a VM's disks on
+func (r *VMRelocator) recommendPodDatastore(ctx context.Context, vm *object.VirtualMachine, config RelocateConfig, pool, folder types.ManagedObjectReference) (*object.Datastore, error) {
+	pod, err := r.targetClient.GetStoragePod(ctx, config.TargetDatastoreCluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target datastore cluster %s: %w", config.TargetDatastoreCluster, lookupError(err))
+	}
+	disks, err := virtualDisks(ctx, r.sourceClient, vm)
 	if err != nil {
-		return fmt.Errorf("failed to start relocate task: %w", err)
+		return nil, err
 	}
+	return r.targetClient.RecommendPodDatastore(ctx, pod, pool, folder, vm.Name(), disks)
+}
 
-	// Wait for relocation with progress logging
-	if err := r.waitForRelocateTask(ctx, task, vm.Name()); err != nil {
-		return fmt.Errorf("relocation failed: %w", err)
+// CheckRelocateHost checks that a host can receive relocated VMs: it must belong to the given
+// compute cluster, be connected and not be in maintenance mode
+func (c *Client) CheckRelocateHost(ctx context.Context, hostPath, clusterPath string) error {
+	host, err := c.GetHost(ctx, hostPath)
+	if err != nil {
+		return err
+	}
+	cluster, err := c.GetCluster(ctx, clusterPath)
+	if err != nil {
+		return err
 	}
 
-	logger.Info("Successfully relocated VM to target vCenter", "vm", vm.Name())
+	var props mo.HostSystem
+	err = c.withReconnect(ctx, false, func(ctx context.Context) error {
+		return host.Properties(ctx, host.Reference(), []string{"parent", "runtime"}, &props)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get properties of host %s: %w", hostPath, err)
+	}
+
+	if props.Parent == nil || *props.Parent != cluster.Reference() {
+		return fmt.Errorf("host %s is not in compute cluster %s", hostPath, clusterPath)
+	}
+	if props.Runtime.ConnectionState != types.HostSystemConnectionStateConnected {
+		return fmt.Errorf("host %s is %s", hostPath, props.Runtime.ConnectionState)
+	}
+	if props.Runtime.InMaintenanceMode {
+		return fmt.Errorf("host %s is in maintenance mode", hostPath)
+	}
+	return nil
+}
+
+// CheckDatastoreAccessible checks that a datastore can receive relocated disks: it must be
+// accessible and not be in maintenance mode
+func (c *Client) CheckDatastoreAccessible(ctx context.Context, path string) error {
+	ds, err := c.GetDatastore(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	var props mo.Datastore
+	err = c.withReconnect(ctx, false, func(ctx context.Context) error {
+		return ds.Properties(ctx, ds.Reference(), []string{"summary"}, &props)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get properties of datastore %s: %w", path, err)
+	}
+
+	if !props.Summary.Accessible {
+		return fmt.Errorf("datastore %s is not accessible", path)
+	}
+	if mode := props.Summary.MaintenanceMode; mode != "" && mode != string(types.DatastoreSummaryMaintenanceModeStateNormal) {
+		return fmt.Errorf("datastore %s is in maintenance mode %s", path, mode)
+	}
 	return nil
 }
 
+// logRecentFaults logs the last SOAP faults seen on both vCenters so a failed vMotion
+// can be diagnosed from the controller logs without reproducing it
+func (r *VMRelocator) logRecentFaults(ctx context.Context, vmName string) {
+	logger := klog.FromContext(ctx)
+
+	const faultLimit = 5
+	for _, c := range []struct {
+		role   string
+		client *Client
+	}{{"source", r.sourceClient}, {"target", r.targetClient}} {
+		if c.client == nil {
+			continue
+		}
+		for _, fault := range c.client.GetRecentSOAPFaults(faultLimit) {
+			logger.Error(fault.Error, "vCenter SOAP fault during VM relocation",
+				"vm", vmName,
+				"vcenter", c.role,
+				"method", fault.Method,
+				"timestamp", fault.Timestamp,
+				"request", fault.RequestBody,
+				"response", fault.ResponseBody)
+		}
+	}
+}
+
 // buildServiceLocator creates a ServiceLocator for cross-vCenter operations
 func (r *VMRelocator) buildServiceLocator(config RelocateConfig) (*types.ServiceLocator, error) {
 	logger := klog.Background()
@@ -284,12 +807,15 @@ func (r *VMRelocator) buildServiceLocator(config RelocateConfig) (*types.Service
 	}, nil
 }
 
-// waitForRelocateTask waits for a relocate task with progress logging
-func (r *VMRelocator) waitForRelocateTask(ctx context.Context, task *object.Task, vmName string) error {
+// waitForRelocateTask waits for a relocate task with progress logging. A task still running
+// after maxDuration, or when ctx is done, is cancelled.
+func (r *VMRelocator) waitForRelocateTask(ctx context.Context, task *object.Task, vmName string, maxDuration time.Duration) error {
 	logger := klog.FromContext(ctx)
 
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
+	deadline := time.NewTimer(maxDuration)
+	defer deadline.Stop()
 
 	const maxConsecutiveErrors = 3
 	var consecutiveErrors int
@@ -297,11 +823,15 @@ func (r *VMRelocator) waitForRelocateTask(ctx context.Context, task *object.Task
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return r.cancelRelocateTask(ctx, task, vmName, ctx.Err())
+		case <-deadline.C:
+			return r.cancelRelocateTask(ctx, task, vmName, fmt.Errorf("%w of %s", ErrRelocateTaskTimedOut, maxDuration))
 		case <-ticker.C:
 			// Get task progress
 			var taskMo mo.Task
-			err := task.Properties(ctx, task.Reference(), []string{"info"}, &taskMo)
+			pollCtx, cancel := r.sourceClient.operationContext(ctx)
+			err := task.Properties(pollCtx, task.Reference(), []string{"info"}, &taskMo)
+			cancel()
 			if err != nil {
 				consecutiveErrors++
 				if consecutiveErrors >= maxConsecutiveErrors {
@@ -324,9 +854,9 @@ func (r *VMRelocator) waitForRelocateTask(ctx context.Context, task *object.Task
 
 			case types.TaskInfoStateError:
 				if taskMo.Info.Error != nil {
-					return fmt.Errorf("VM relocation task failed: %s", taskMo.Info.Error.LocalizedMessage)
+					return fmt.Errorf("%w: %s", errRelocateTaskFailed, taskMo.Info.Error.LocalizedMessage)
 				}
-				return fmt.Errorf("VM relocation task failed with unknown error")
+				return fmt.Errorf("%w with unknown error", errRelocateTaskFailed)
 
 			case types.TaskInfoStateRunning, types.TaskInfoStateQueued:
 				progress := taskMo.Info.Progress
@@ -368,34 +898,137 @@ func (r *VMRelocator) GetVMSCSIControllerKey(ctx context.Context, vm *object.Vir
 	return 0, fmt.Errorf("no SCSI controller found on VM")
 }
 
-// GetNextFreeUnitNumber finds the next free unit number on a SCSI controller
-func (r *VMRelocator) GetNextFreeUnitNumber(ctx context.Context, vm *object.VirtualMachine, controllerKey int32) (int32, error) {
+// GetNextFreeUnitNumber finds the next free unit number on a SCSI controller, skipping units
+// used by any device, such as a template's boot disk, and the reserved units
+func (r *VMRelocator) GetNextFreeUnitNumber(ctx context.Context, vm *object.VirtualMachine, controllerKey int32, reservedUnits ...int32) (int32, error) {
 	var vmMo mo.VirtualMachine
 	err := vm.Properties(ctx, vm.Reference(), []string{"config.hardware.device"}, &vmMo)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get VM properties: %w", err)
 	}
 
+	if unit, ok := freeUnit(object.VirtualDeviceList(vmMo.Config.Hardware.Device), controllerKey, reservedUnits); ok {
+		return unit, nil
+	}
+	return 0, fmt.Errorf("no free unit numbers available on controller")
+}
+
+// freeUnit returns the first unit on a controller that no device uses and that is neither the
+// controller's own unit nor reserved
+func freeUnit(devices object.VirtualDeviceList, controllerKey int32, reservedUnits []int32) (int32, bool) {
 	usedUnits := make(map[int32]bool)
-	for _, device := range vmMo.Config.Hardware.Device {
-		if disk, ok := device.(*types.VirtualDisk); ok {
-			if disk.ControllerKey == controllerKey && disk.UnitNumber != nil {
-				usedUnits[*disk.UnitNumber] = true
-			}
+	for _, device := range devices {
+		d := device.GetVirtualDevice()
+		if d.ControllerKey == controllerKey && d.UnitNumber != nil {
+			usedUnits[*d.UnitNumber] = true
 		}
 	}
 
-	// Find first free unit (skip 7 which is reserved for the controller)
-	for i := int32(0); i < 16; i++ {
-		if i == 7 {
-			continue // Reserved for SCSI controller
+	for unit := int32(0); unit < UnitsPerSCSIController; unit++ {
+		if unit == scsiControllerUnit || usedUnits[unit] || slices.Contains(reservedUnits, unit) {
+			continue
 		}
-		if !usedUnits[i] {
-			return i, nil
+		return unit, true
+	}
+	return 0, false
+}
+
+// DiskSlot is a controller and unit number a disk can be attached at
+type DiskSlot struct {
+	ControllerKey int32
+	UnitNumber    int32
+}
+
+// FindFreeDiskSlot returns the first free unit across the VM's SCSI controllers, filling
+// controllers in bus order and skipping the reserved units on each. It returns nil when every
+// controller is full.
+func FindFreeDiskSlot(devices object.VirtualDeviceList, reservedUnits ...int32) *DiskSlot {
+	controllers := scsiControllers(devices)
+	slices.SortFunc(controllers, func(a, b *types.VirtualSCSIController) int {
+		return cmp.Compare(a.BusNumber, b.BusNumber)
+	})
+
+	for _, controller := range controllers {
+		if unit, ok := freeUnit(devices, controller.Key, reservedUnits); ok {
+			return &DiskSlot{ControllerKey: controller.Key, UnitNumber: unit}
 		}
 	}
 
-	return 0, fmt.Errorf("no free unit numbers available on controller")
+	return nil
+}
+
+// NextSCSIBusNumber returns the lowest free SCSI bus number, or an error when the VM already
+// has MaxSCSIControllers controllers
+func NextSCSIBusNumber(devices object.VirtualDeviceList) (int32, error) {
+	usedBuses := make(map[int32]bool)
+	for _, controller := range scsiControllers(devices) {
+		usedBuses[controller.BusNumber] = true
+	}
+
+	for bus := int32(0); bus < MaxSCSIControllers; bus++ {
+		if !usedBuses[bus] {
+			return bus, nil
+		}
+	}
+	return 0, fmt.Errorf("all %d SCSI controllers are full", MaxSCSIControllers)
+}
+
+// scsiControllers returns the SCSI controllers in a device list
+func scsiControllers(devices object.VirtualDeviceList) []*types.VirtualSCSIController {
+	var controllers []*types.VirtualSCSIController
+	for _, device := range devices {
+		if controller, ok := device.(types.BaseVirtualSCSIController); ok {
+			controllers = append(controllers, controller.GetVirtualSCSIController())
+		}
+	}
+	return controllers
+}
+
+// NextDiskSlot picks the controller and unit for the next disk on a VM, never one of the
+// reserved units. When every SCSI controller is full a PVSCSI controller is added, up to
+// MaxSCSIControllers.
+func (r *VMRelocator) NextDiskSlot(ctx context.Context, vm *object.VirtualMachine, reservedUnits ...int32) (DiskSlot, error) {
+	logger := klog.FromContext(ctx)
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return DiskSlot{}, fmt.Errorf("failed to get VM devices: %w", err)
+	}
+	if slot := FindFreeDiskSlot(devices, reservedUnits...); slot != nil {
+		return *slot, nil
+	}
+
+	busNumber, err := NextSCSIBusNumber(devices)
+	if err != nil {
+		return DiskSlot{}, fmt.Errorf("no free unit numbers available: %w", err)
+	}
+
+	controller := &types.ParaVirtualSCSIController{
+		VirtualSCSIController: types.VirtualSCSIController{
+			SharedBus: types.VirtualSCSISharingNoSharing,
+			VirtualController: types.VirtualController{
+				BusNumber: busNumber,
+				VirtualDevice: types.VirtualDevice{
+					Key: devices.NewKey(),
+				},
+			},
+		},
+	}
+
+	logger.Info("Adding SCSI controller to VM", "bus", busNumber)
+	if err := vm.AddDevice(ctx, controller); err != nil {
+		return DiskSlot{}, fmt.Errorf("failed to add SCSI controller on bus %d: %w", busNumber, err)
+	}
+
+	devices, err = vm.Device(ctx)
+	if err != nil {
+		return DiskSlot{}, fmt.Errorf("failed to get VM devices: %w", err)
+	}
+	slot := FindFreeDiskSlot(devices, reservedUnits...)
+	if slot == nil {
+		return DiskSlot{}, fmt.Errorf("no free unit after adding SCSI controller on bus %d", busNumber)
+	}
+	return *slot, nil
 }
 
 // GetVMFromMoRef gets a VirtualMachine object from a ManagedObjectReference
@@ -405,3 +1038,30 @@ func (r *VMRelocator) GetVMFromMoRef(ctx context.Context, moRef types.ManagedObj
 	}
 	return object.NewVirtualMachine(r.sourceClient.vimClient, moRef)
 }
+
+// cancelRelocateTask cancels a relocate task that is no longer waited for and waits for its
+// final state. A task confirmed cancelled was rolled back, so the returned error then wraps
+// errRelocateTaskFailed as well as cause; a task that finished in the meantime returns nil.
+func (r *VMRelocator) cancelRelocateTask(ctx context.Context, task *object.Task, vmName string, cause error) error {
+	logger := klog.FromContext(ctx)
+	logger.Info("Cancelling VM relocation task", "vm", vmName, "task", task.Reference().Value, "reason", cause.Error())
+
+	// ctx may already be done, the cancellation must still reach vCenter
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), relocateCancelTimeout)
+	defer cancel()
+	if err := task.Cancel(cancelCtx); err != nil {
+		// The task may have just completed, its final state below tells
+		logger.Error(err, "Failed to cancel VM relocation task", "vm", vmName)
+	}
+
+	info, err := task.WaitForResult(cancelCtx)
+	switch {
+	case err == nil:
+		logger.Info("VM relocation task completed before it was cancelled", "vm", vmName)
+		return nil
+	case info != nil && info.State == types.TaskInfoStateError:
+		return fmt.Errorf("%w: %w", errRelocateTaskFailed, cause)
+	default:
+		return fmt.Errorf("%w; cancellation could not be confirmed: %v", cause, err)
+	}
+}
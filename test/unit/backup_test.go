@@ -0,0 +1,300 @@
+package unit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
+	machinefake "github.com/openshift/client-go/machine/clientset/versioned/fake"
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+func TestStoreBackup_ExternalStorage(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to add core types to scheme: %v", err)
+	}
+
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cloud-provider-config",
+			Namespace: "openshift-config",
+		},
+		Data: map[string]string{"config": "original"},
+	}
+
+	tests := []struct {
+		name        string
+		storageType migrationv1alpha1.BackupStorageType
+	}{
+		{name: "inline", storageType: migrationv1alpha1.BackupStorageInline},
+		{name: "configmap", storageType: migrationv1alpha1.BackupStorageConfigMap},
+		{name: "secret", storageType: migrationv1alpha1.BackupStorageSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset()
+			backupManager := backup.NewBackupManagerWithClient(scheme, kubeClient)
+
+			migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-migration",
+					Namespace: "vmware-cloud-foundation-migration",
+					UID:       "test-uid",
+				},
+				Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+					BackupStorage: &migrationv1alpha1.BackupStorageConfig{Type: tt.storageType},
+				},
+			}
+
+			manifest, err := backupManager.BackupResource(ctx, source, "ConfigMap")
+			if err != nil {
+				t.Fatalf("BackupResource failed: %v", err)
+			}
+			if err := backupManager.StoreBackup(ctx, migration, manifest); err != nil {
+				t.Fatalf("StoreBackup failed: %v", err)
+			}
+
+			if len(migration.Status.BackupManifests) != 1 {
+				t.Fatalf("Expected 1 backup in status, got %d", len(migration.Status.BackupManifests))
+			}
+			stored := migration.Status.BackupManifests[0]
+
+			// Restore through a client that sees whatever the backup manager wrote
+			objects := []client.Object{source.DeepCopy()}
+			if tt.storageType == migrationv1alpha1.BackupStorageInline {
+				if stored.StorageRef != nil || stored.BackupData == "" {
+					t.Fatalf("Expected inline backup data without a storage reference, got %+v", stored)
+				}
+			} else {
+				if stored.BackupData != "" {
+					t.Error("Expected backup data to be omitted from status")
+				}
+				ref := stored.StorageRef
+				if ref == nil || ref.Kind != string(tt.storageType) || ref.Namespace != migration.Namespace {
+					t.Fatalf("Unexpected storage reference: %+v", ref)
+				}
+
+				switch tt.storageType {
+				case migrationv1alpha1.BackupStorageConfigMap:
+					cm, err := kubeClient.CoreV1().ConfigMaps(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+					if err != nil {
+						t.Fatalf("Expected backup ConfigMap %s: %v", ref.Name, err)
+					}
+					if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].UID != migration.UID {
+						t.Errorf("Expected backup ConfigMap to be owned by the migration, got %+v", cm.OwnerReferences)
+					}
+					objects = append(objects, cm)
+				case migrationv1alpha1.BackupStorageSecret:
+					secret, err := kubeClient.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+					if err != nil {
+						t.Fatalf("Expected backup Secret %s: %v", ref.Name, err)
+					}
+					objects = append(objects, secret)
+				}
+			}
+
+			// Change the live object, then roll it back from the backup
+			modified := source.DeepCopy()
+			modified.Data["config"] = "modified"
+			objects[0] = modified
+			ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+			restoreManager := backup.NewRestoreManager(ctrlClient, scheme)
+			if err := restoreManager.RestoreResource(ctx, &stored); err != nil {
+				t.Fatalf("RestoreResource failed: %v", err)
+			}
+
+			restored := &corev1.ConfigMap{}
+			if err := ctrlClient.Get(ctx, client.ObjectKeyFromObject(source), restored); err != nil {
+				t.Fatalf("Failed to get restored ConfigMap: %v", err)
+			}
+			if restored.Data["config"] != "original" {
+				t.Errorf("Expected restored config %q, got %q", "original", restored.Data["config"])
+			}
+		})
+	}
+}
+
+func TestStoreBackup_SecretsNeverInConfigMaps(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("Failed to add core types to scheme: %v", err)
+	}
+
+	kubeClient := kubefake.NewSimpleClientset()
+	backupManager := backup.NewBackupManagerWithClient(scheme, kubeClient)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			BackupStorage: &migrationv1alpha1.BackupStorageConfig{Type: migrationv1alpha1.BackupStorageConfigMap},
+		},
+	}
+
+	creds := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "vsphere-creds", Namespace: "kube-system"},
+		Data:       map[string][]byte{"vcenter.example.com.password": []byte("secret-password")},
+	}
+	manifest, err := backupManager.BackupResource(ctx, creds, "Secret")
+	if err != nil {
+		t.Fatalf("BackupResource failed: %v", err)
+	}
+	if err := backupManager.StoreBackup(ctx, migration, manifest); err != nil {
+		t.Fatalf("StoreBackup failed: %v", err)
+	}
+
+	ref := migration.Status.BackupManifests[0].StorageRef
+	if ref == nil || ref.Kind != string(migrationv1alpha1.BackupStorageSecret) {
+		t.Fatalf("Expected the Secret backup to be stored in a Secret, got %+v", ref)
+	}
+	if _, err := kubeClient.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{}); err != nil {
+		t.Errorf("Expected backup Secret %s: %v", ref.Name, err)
+	}
+	configMaps, err := kubeClient.CoreV1().ConfigMaps(ref.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list ConfigMaps: %v", err)
+	}
+	if len(configMaps.Items) != 0 {
+		t.Errorf("Expected no backup ConfigMap, got %d", len(configMaps.Items))
+	}
+}
+
+func TestMachineSetBackupRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	providerSpec := `{"workspace":{"server":"old-vcenter.example.com","datacenter":"dc1"},"network":{"devices":[{"networkName":"old"}]}}`
+	replicas := int32(2)
+	original := &machinev1beta1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-worker",
+			Namespace: openshift.MachineAPINamespace,
+			Labels:    map[string]string{"machine.openshift.io/cluster-api-cluster": "test"},
+		},
+		Spec: machinev1beta1.MachineSetSpec{
+			Replicas: &replicas,
+			Template: machinev1beta1.MachineTemplateSpec{
+				Spec: machinev1beta1.MachineSpec{
+					ProviderSpec: machinev1beta1.ProviderSpec{
+						Value: &runtime.RawExtension{Raw: []byte(providerSpec)},
+					},
+				},
+			},
+		},
+	}
+
+	machineClient := machinefake.NewSimpleClientset(original)
+	machineManager := openshift.NewMachineManagerWithClients(kubefake.NewSimpleClientset(), machineClient, nil)
+	backupManager := backup.NewBackupManager(runtime.NewScheme())
+	restoreManager := backup.NewRestoreManager(nil, runtime.NewScheme())
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "default"},
+	}
+
+	machineSets, err := machineManager.BackupAllMachineSets(ctx)
+	if err != nil {
+		t.Fatalf("BackupAllMachineSets failed: %v", err)
+	}
+	if len(machineSets) != 1 {
+		t.Fatalf("expected 1 MachineSet, got %d", len(machineSets))
+	}
+	manifest, err := backupManager.BackupResource(ctx, client.Object(&machineSets[0]), "MachineSet")
+	if err != nil {
+		t.Fatalf("BackupResource failed: %v", err)
+	}
+	if manifest.Checksum == "" {
+		t.Error("expected the backup to record a checksum")
+	}
+	if err := backupManager.StoreBackup(ctx, migration, manifest); err != nil {
+		t.Fatalf("StoreBackup failed: %v", err)
+	}
+
+	machineSetClient := machineClient.MachineV1beta1().MachineSets(openshift.MachineAPINamespace)
+	if err := machineSetClient.Delete(ctx, "test-worker", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete MachineSet: %v", err)
+	}
+
+	stored, err := backupManager.GetBackup(migration, "MachineSet", "test-worker", openshift.MachineAPINamespace)
+	if err != nil {
+		t.Fatalf("GetBackup failed: %v", err)
+	}
+	decoded := &machinev1beta1.MachineSet{}
+	if err := restoreManager.DecodeBackup(ctx, stored, decoded); err != nil {
+		t.Fatalf("DecodeBackup failed: %v", err)
+	}
+	if err := machineManager.RestoreMachineSet(ctx, decoded); err != nil {
+		t.Fatalf("RestoreMachineSet failed to recreate the MachineSet: %v", err)
+	}
+
+	restored, err := machineSetClient.Get(ctx, "test-worker", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("MachineSet was not recreated: %v", err)
+	}
+	if restored.Spec.Replicas == nil || *restored.Spec.Replicas != replicas {
+		t.Errorf("expected %d replicas, got %v", replicas, restored.Spec.Replicas)
+	}
+	var want, got map[string]any
+	if err := json.Unmarshal([]byte(providerSpec), &want); err != nil {
+		t.Fatalf("failed to unmarshal providerSpec: %v", err)
+	}
+	if err := json.Unmarshal(restored.Spec.Template.Spec.ProviderSpec.Value.Raw, &got); err != nil {
+		t.Fatalf("failed to unmarshal restored providerSpec: %v", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("providerSpec not restored, got %v", got)
+	}
+	if restored.Labels["machine.openshift.io/cluster-api-cluster"] != "test" {
+		t.Errorf("labels not restored, got %v", restored.Labels)
+	}
+
+	// Restoring over an existing MachineSet puts back the backed up spec
+	scaled := int32(0)
+	restored.Spec.Replicas = &scaled
+	if _, err := machineSetClient.Update(ctx, restored, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to scale MachineSet: %v", err)
+	}
+	if err := machineManager.RestoreMachineSet(ctx, decoded); err != nil {
+		t.Fatalf("RestoreMachineSet failed to update the MachineSet: %v", err)
+	}
+	restored, err = machineSetClient.Get(ctx, "test-worker", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get MachineSet: %v", err)
+	}
+	if restored.Spec.Replicas == nil || *restored.Spec.Replicas != replicas {
+		t.Errorf("expected %d replicas after restore, got %v", replicas, restored.Spec.Replicas)
+	}
+
+	// A backup that no longer matches its checksum is refused
+	tampered := *stored
+	tampered.BackupData = base64.StdEncoding.EncodeToString([]byte(strings.Replace(
+		decodeBackupData(t, stored), "replicas: 2", "replicas: 5", 1)))
+	if err := restoreManager.DecodeBackup(ctx, &tampered, &machinev1beta1.MachineSet{}); err == nil {
+		t.Error("expected DecodeBackup to reject a backup that does not match its checksum")
+	}
+}
+
+func decodeBackupData(t *testing.T, manifest *migrationv1alpha1.BackupManifest) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(manifest.BackupData)
+	if err != nil {
+		t.Fatalf("failed to decode backup data: %v", err)
+	}
+	if !strings.Contains(string(data), "replicas: 2") {
+		t.Fatalf("backup does not contain the replica count:\n%s", data)
+	}
+	return string(data)
+}
@@ -0,0 +1,311 @@
+package unit
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vslm"
+	_ "github.com/vmware/govmomi/vslm/simulator"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	machinefake "github.com/openshift/client-go/machine/clientset/versioned/fake"
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+func cleanupTestCSIPV(name, volumeHandle string) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{
+					Driver:       openshift.VSphereCSIDriver,
+					VolumeHandle: volumeHandle,
+				},
+			},
+		},
+	}
+}
+
+func cleanupTestInfrastructure(server string) *configv1.Infrastructure {
+	return &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			PlatformSpec: configv1.PlatformSpec{
+				Type: configv1.VSpherePlatformType,
+				VSphere: &configv1.VSpherePlatformSpec{
+					VCenters: []configv1.VSpherePlatformVCenterSpec{{Server: server, Datacenters: []string{"DC0"}}},
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{
+						Name:   "source-fd",
+						Server: server,
+						Topology: configv1.VSpherePlatformTopology{
+							Datacenter:     "DC0",
+							ComputeCluster: "/DC0/host/DC0_C0",
+							Datastore:      "/DC0/datastore/LocalDS_0",
+						},
+					}},
+				},
+			},
+		},
+		Status: configv1.InfrastructureStatus{InfrastructureName: "test-cluster"},
+	}
+}
+
+func TestCleanupPhase_RefusesWhileVolumesRemainOnSource(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+	model.Service.RegisterEndpoints = true
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	username := simulator.DefaultLogin.Username()
+	password, _ := simulator.DefaultLogin.Password()
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{Server: server.URL.String(), Insecure: true},
+		vsphere.Credentials{Username: username, Password: password})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	createFCD := func(name string) string {
+		task, err := vslm.NewObjectManager(client.VimClient()).CreateDisk(ctx, types.VslmCreateSpec{
+			Name:         name,
+			CapacityInMB: 10,
+			BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+				VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{Datastore: ds.Reference()},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create FCD: %v", err)
+		}
+		result, err := task.WaitForResult(ctx)
+		if err != nil {
+			t.Fatalf("Failed to wait for FCD creation: %v", err)
+		}
+		return result.Result.(types.VStorageObject).Config.Id.Id
+	}
+
+	// The failed and untracked volumes are still on the source; the migrated volume and the one
+	// provisioned on the target after UpdateInfrastructure are not
+	failedID := createFCD("failed")
+	untrackedID := createFCD("untracked")
+	const migratedID = "3c1b6a2e-7f4d-4b8e-9a21-5d6e7f8a9b0c"
+	const provisionedID = "8e2f4a6c-1b3d-4e5f-8a7b-9c0d1e2f3a4b"
+
+	host := server.URL.Host
+	creds := map[string][]byte{host + ".username": []byte(username), host + ".password": []byte(password)}
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubefake.NewSimpleClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: openshift.VSphereCredsSecretName, Namespace: openshift.VSphereCredsSecretNamespace}, Data: creds},
+			cleanupTestCSIPV("pv-migrated", vsphere.BuildCSIVolumeHandle(migratedID)),
+			cleanupTestCSIPV("pv-failed", vsphere.BuildCSIVolumeHandle(failedID)),
+			cleanupTestCSIPV("pv-untracked", vsphere.BuildCSIVolumeHandle(untrackedID)),
+			cleanupTestCSIPV("pv-provisioned", vsphere.BuildCSIVolumeHandle(provisionedID)),
+			&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-in-tree"},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						VsphereVolume: &corev1.VsphereVirtualDiskVolumeSource{VolumePath: "[ds1] kubevols/disk.vmdk"},
+					},
+				},
+			},
+		),
+		configfake.NewSimpleClientset(cleanupTestInfrastructure(host)),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-migration",
+			Namespace: "vmware-cloud-foundation-migration",
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{Name: "target-fd", Server: "vcenter-new.example.com"}},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				TotalVolumes:    2,
+				MigratedVolumes: 1,
+				FailedVolumes:   1,
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{PVName: "pv-migrated", SourceVolumeID: migratedID, TargetVolumeID: migratedID, Status: phases.PVStatusComplete},
+					{PVName: "pv-failed", SourceVolumeID: failedID, Status: phases.PVStatusFailed},
+				},
+			},
+		},
+	}
+
+	result, err := phases.NewCleanupPhase(executor).Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusRunning {
+		t.Fatalf("expected status %s, got %s (%s)", migrationv1alpha1.PhaseStatusRunning, result.Status, result.Message)
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("expected the blocked cleanup to requeue")
+	}
+
+	for _, name := range []string{"pv-failed", "pv-untracked", "pv-in-tree"} {
+		if !strings.Contains(result.Message, name) {
+			t.Errorf("expected message to name %s, got: %s", name, result.Message)
+		}
+	}
+	for _, name := range []string{"pv-migrated", "pv-provisioned"} {
+		if strings.Contains(result.Message, name) {
+			t.Errorf("expected message not to name %s, which is not on the source, got: %s", name, result.Message)
+		}
+	}
+}
+
+func TestCleanupPhase_SkipsVolumeCheck(t *testing.T) {
+	const sourceServer = "vcenter.example.com"
+
+	tests := []struct {
+		name    string
+		status  *migrationv1alpha1.CSIVolumeMigrationStatus
+		target  string
+		wantLog string
+	}{
+		{
+			name:    "CSI volume migration never ran",
+			target:  "vcenter-new.example.com",
+			wantLog: "CSI volume migration did not run",
+		},
+		{
+			name: "intra-vCenter migration",
+			status: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				Volumes: []migrationv1alpha1.PVMigrationState{{PVName: "pv-data", Status: phases.PVStatusFailed}},
+			},
+			target:  sourceServer,
+			wantLog: "Keeping source vCenter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// No vCenter is reachable, so the check must not look the volume up
+			scheme := runtime.NewScheme()
+			executor := phases.NewPhaseExecutor(
+				kubefake.NewSimpleClientset(cleanupTestCSIPV("pv-data", vsphere.BuildCSIVolumeHandle("3c1b6a2e-7f4d-4b8e-9a21-5d6e7f8a9b0c"))),
+				configfake.NewSimpleClientset(cleanupTestInfrastructure(sourceServer)),
+				apiextensionsfake.NewSimpleClientset(),
+				machinefake.NewSimpleClientset(),
+				dynamicfake.NewSimpleDynamicClient(scheme),
+				backup.NewBackupManager(scheme),
+				nil)
+
+			migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+				Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{Name: "target-fd", Server: tt.target}},
+				},
+				Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{CSIVolumeMigration: tt.status},
+			}
+
+			result, _ := phases.NewCleanupPhase(executor).Execute(ctx, migration)
+			if result == nil {
+				t.Fatal("expected a phase result")
+			}
+			if strings.Contains(result.Message, "volumes on the source vCenter") || strings.Contains(result.Message, "Refusing") {
+				t.Fatalf("expected the volume check to be skipped, got %s: %s", result.Status, result.Message)
+			}
+			found := false
+			for _, entry := range result.Logs {
+				if strings.Contains(entry.Message, tt.wantLog) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a log containing %q, got %v", tt.wantLog, result.Logs)
+			}
+		})
+	}
+}
+
+func TestCleanupPhase_WaitsForSourceRetention(t *testing.T) {
+	const server = "vcenter.example.com"
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(cleanupTestInfrastructure(server)),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+
+	// Only sources still within their retention remain, so no vCenter is contacted
+	soon := metav1.NewTime(time.Now().Add(time.Hour))
+	later := metav1.NewTime(time.Now().Add(2 * time.Hour))
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{Name: "target-fd", Server: server}},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{PVName: "pv-later", SourceVolumeID: "fcd-later", Status: phases.PVStatusComplete, SourceRetainedUntil: &later},
+					{PVName: "pv-soon", SourceVolumeID: "fcd-soon", Status: phases.PVStatusComplete, SourceRetainedUntil: &soon},
+				},
+			},
+		},
+	}
+
+	result, err := phases.NewCleanupPhase(executor).Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusRunning {
+		t.Fatalf("Expected Cleanup to keep running until the retention expires, got %s: %s", result.Status, result.Message)
+	}
+	if result.RequeueAfter <= 59*time.Minute || result.RequeueAfter > time.Hour {
+		t.Errorf("Expected a requeue when the earliest retention expires in 1h, got %s", result.RequeueAfter)
+	}
+	if !strings.Contains(result.Message, soon.UTC().Format(time.RFC3339)) {
+		t.Errorf("Expected the message to name the earliest expiry, got %s", result.Message)
+	}
+
+	// Nothing past the wait runs before the sources are gone
+	for _, entry := range result.Logs {
+		if strings.Contains(entry.Message, "Cleanup completed") || strings.Contains(entry.Message, "Keeping source vCenter") {
+			t.Errorf("Expected Cleanup to stop at the retention wait, got log %q", entry.Message)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	_ "github.com/vmware/govmomi/cns/simulator"
+	cnstypes "github.com/vmware/govmomi/cns/types"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+func TestRegisterVolume_EntityMetadata(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the CNS endpoint
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	cnsManager, err := vsphere.NewCNSManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create CNS manager: %v", err)
+	}
+
+	entity := &vsphere.VolumeEntityMetadata{
+		PVName:       "pvc-1234",
+		PVLabels:     map[string]string{"tier": "gold"},
+		PVCName:      "data",
+		PVCNamespace: "app",
+		PVCLabels:    map[string]string{"app": "db"},
+	}
+
+	registered, err := cnsManager.RegisterVolume(ctx, "[LocalDS_0] fcd/test.vmdk", "pvc-1234", "", "test-cluster", entity)
+	if err != nil {
+		t.Fatalf("RegisterVolume failed: %v", err)
+	}
+
+	info, err := cnsManager.QueryVolume(ctx, registered.VolumeID)
+	if err != nil {
+		t.Fatalf("QueryVolume failed: %v", err)
+	}
+
+	if len(info.Entities) != 2 {
+		t.Fatalf("Expected PV and PVC entities, got %+v", info.Entities)
+	}
+
+	pv, pvc := info.Entities[0], info.Entities[1]
+	if pv.EntityType != string(cnstypes.CnsKubernetesEntityTypePV) || pv.EntityName != "pvc-1234" {
+		t.Errorf("Unexpected PV entity: %+v", pv)
+	}
+	if pv.Labels["tier"] != "gold" {
+		t.Errorf("Expected PV labels to be recorded, got %v", pv.Labels)
+	}
+	if pvc.EntityType != string(cnstypes.CnsKubernetesEntityTypePVC) || pvc.EntityName != "data" || pvc.Namespace != "app" {
+		t.Errorf("Unexpected PVC entity: %+v", pvc)
+	}
+	if pvc.Labels["app"] != "db" {
+		t.Errorf("Expected PVC labels to be recorded, got %v", pvc.Labels)
+	}
+}
+
+func TestSetVolumeLabels(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the CNS endpoint
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	cnsManager, err := vsphere.NewCNSManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create CNS manager: %v", err)
+	}
+
+	registered, err := cnsManager.RegisterVolume(ctx, "[LocalDS_0] fcd/labels.vmdk", "pvc-5678", "", "test-cluster",
+		&vsphere.VolumeEntityMetadata{
+			PVName:       "pvc-5678",
+			PVLabels:     map[string]string{"tier": "gold"},
+			PVCName:      "data",
+			PVCNamespace: "app",
+			PVCLabels:    map[string]string{"app": "db"},
+		})
+	if err != nil {
+		t.Fatalf("RegisterVolume failed: %v", err)
+	}
+
+	labels, err := cnsManager.GetVolumeLabels(ctx, registered.VolumeID)
+	if err != nil {
+		t.Fatalf("GetVolumeLabels failed: %v", err)
+	}
+	if labels["tier"] != "gold" || labels["app"] != "db" {
+		t.Errorf("Expected the PV and PVC labels, got %v", labels)
+	}
+
+	if err := cnsManager.SetVolumeLabels(ctx, registered.VolumeID, map[string]string{"team": "storage", "tier": "silver"}); err != nil {
+		t.Fatalf("SetVolumeLabels failed: %v", err)
+	}
+
+	info, err := cnsManager.QueryVolume(ctx, registered.VolumeID)
+	if err != nil {
+		t.Fatalf("QueryVolume failed: %v", err)
+	}
+	if len(info.Entities) != 2 {
+		t.Fatalf("Expected the PV and PVC entities to be kept, got %+v", info.Entities)
+	}
+	for _, entity := range info.Entities {
+		switch entity.EntityType {
+		case string(cnstypes.CnsKubernetesEntityTypePV):
+			if entity.Labels["team"] != "storage" || entity.Labels["tier"] != "silver" {
+				t.Errorf("Expected the labels to be set on the PV entity, got %v", entity.Labels)
+			}
+		case string(cnstypes.CnsKubernetesEntityTypePVC):
+			if entity.Labels["app"] != "db" || entity.Labels["team"] != "" {
+				t.Errorf("Expected the PVC entity labels to be unchanged, got %v", entity.Labels)
+			}
+		}
+	}
+}
+
+func TestParseCreateVolumeResult(t *testing.T) {
+	faulted := func(fault types.BaseMethodFault, message string) cnstypes.CnsVolumeOperationBatchResult {
+		return cnstypes.CnsVolumeOperationBatchResult{
+			VolumeResults: []cnstypes.BaseCnsVolumeOperationResult{
+				&cnstypes.CnsVolumeCreateResult{
+					CnsVolumeOperationResult: cnstypes.CnsVolumeOperationResult{
+						Fault: &types.LocalizedMethodFault{Fault: fault, LocalizedMessage: message},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		result := cnstypes.CnsVolumeOperationBatchResult{
+			VolumeResults: []cnstypes.BaseCnsVolumeOperationResult{
+				&cnstypes.CnsVolumeCreateResult{
+					CnsVolumeOperationResult: cnstypes.CnsVolumeOperationResult{
+						VolumeId: cnstypes.CnsVolumeId{Id: "fcd-1"},
+					},
+				},
+			},
+		}
+		volumeID, err := vsphere.ParseCreateVolumeResult(result)
+		if err != nil {
+			t.Fatalf("ParseCreateVolumeResult failed: %v", err)
+		}
+		if volumeID != "fcd-1" {
+			t.Errorf("Expected volume ID fcd-1, got %s", volumeID)
+		}
+	})
+
+	tests := []struct {
+		name              string
+		fault             types.BaseMethodFault
+		expectedType      string
+		expectedVolumeID  string
+		expectedHint      string
+		alreadyRegistered bool
+	}{
+		{
+			name:              "already registered",
+			fault:             &cnstypes.CnsAlreadyRegisteredFault{VolumeId: cnstypes.CnsVolumeId{Id: "fcd-1"}},
+			expectedType:      "CnsAlreadyRegisteredFault",
+			expectedVolumeID:  "fcd-1",
+			expectedHint:      "already registered",
+			alreadyRegistered: true,
+		},
+		{
+			name:         "datastore not accessible",
+			fault:        &types.InaccessibleDatastore{},
+			expectedType: "InaccessibleDatastore",
+			expectedHint: "not accessible",
+		},
+		{
+			name:         "backing disk not found",
+			fault:        &types.NotFound{},
+			expectedType: "NotFound",
+			expectedHint: "not found",
+		},
+		{
+			name:         "generic CNS fault carries its reason",
+			fault:        &cnstypes.CnsFault{Reason: "container cluster mismatch"},
+			expectedType: "CnsFault",
+			expectedHint: "container cluster mismatch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := vsphere.ParseCreateVolumeResult(faulted(tt.fault, "vCenter says no"))
+			var faultErr *vsphere.CNSFaultError
+			if !errors.As(err, &faultErr) {
+				t.Fatalf("Expected a CNSFaultError, got: %v", err)
+			}
+			if faultErr.FaultType != tt.expectedType {
+				t.Errorf("Expected fault type %s, got %s", tt.expectedType, faultErr.FaultType)
+			}
+			if faultErr.VolumeID != tt.expectedVolumeID {
+				t.Errorf("Expected volume ID %q, got %q", tt.expectedVolumeID, faultErr.VolumeID)
+			}
+			if faultErr.Message != "vCenter says no" {
+				t.Errorf("Expected localized message to be preserved, got %q", faultErr.Message)
+			}
+			if !strings.Contains(faultErr.Hint, tt.expectedHint) {
+				t.Errorf("Expected hint containing %q, got %q", tt.expectedHint, faultErr.Hint)
+			}
+			if got := vsphere.IsCNSAlreadyRegistered(err); got != tt.alreadyRegistered {
+				t.Errorf("IsCNSAlreadyRegistered = %v, expected %v", got, tt.alreadyRegistered)
+			}
+		})
+	}
+
+	t.Run("empty batch", func(t *testing.T) {
+		if _, err := vsphere.ParseCreateVolumeResult(cnstypes.CnsVolumeOperationBatchResult{}); err == nil {
+			t.Error("Expected an error for an empty batch result")
+		}
+	})
+}
@@ -0,0 +1,1409 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	machinefake "github.com/openshift/client-go/machine/clientset/versioned/fake"
+	"github.com/openshift/library-go/pkg/operator/events"
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+)
+
+var migrationGVR = schema.GroupVersionResource{
+	Group:    "migration.openshift.io",
+	Version:  "v1alpha1",
+	Resource: "vmwarecloudfoundationmigrations",
+}
+
+func TestUpdateMigrationStatus_RetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-migration",
+			Namespace:       "vmware-cloud-foundation-migration",
+			ResourceVersion: "1",
+		},
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+
+	// Fail the first status write with a conflict, as if another writer bumped the object
+	statusWrites := 0
+	dynamicClient.PrependReactor("update", "vmwarecloudfoundationmigrations", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "status" {
+			return false, nil, nil
+		}
+		statusWrites++
+		if statusWrites == 1 {
+			return true, nil, apierrors.NewConflict(migrationGVR.GroupResource(), migration.Name,
+				fmt.Errorf("the object has been modified"))
+		}
+		return false, nil, nil
+	})
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	migration.Status.Phase = migrationv1alpha1.PhaseBackup
+	if err := c.UpdateMigrationStatus(ctx, migration); err != nil {
+		t.Fatalf("Expected status update to succeed after conflict retry, got: %v", err)
+	}
+
+	if statusWrites != 2 {
+		t.Errorf("Expected 2 status write attempts, got %d", statusWrites)
+	}
+
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+
+	phase, _, _ := unstructured.NestedString(stored.Object, "status", "phase")
+	if phase != string(migrationv1alpha1.PhaseBackup) {
+		t.Errorf("Expected stored phase %s, got %s", migrationv1alpha1.PhaseBackup, phase)
+	}
+}
+
+func TestProcessNextWorkItem_BacksOffOnError(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"})
+
+	// Every fetch fails with a transient error so each reconcile is retried through the rate limiter
+	fetches := 0
+	dynamicClient.PrependReactor("get", "vmwarecloudfoundationmigrations", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		fetches++
+		return true, nil, apierrors.NewServiceUnavailable("apiserver unavailable")
+	})
+
+	const baseDelay = 200 * time.Millisecond
+	c, _ := controller.NewMigrationControllerWithOptions(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+		controller.Options{
+			Workers:              1,
+			RateLimiterBaseDelay: baseDelay,
+			RateLimiterMaxDelay:  time.Second,
+		},
+	)
+
+	migration := &unstructured.Unstructured{}
+	migration.SetNamespace("vmware-cloud-foundation-migration")
+	migration.SetName("test-migration")
+	c.EnqueueMigration(migration)
+
+	// First attempt runs immediately
+	if !c.ProcessNextWorkItem(ctx) {
+		t.Fatal("Expected the work queue to be running")
+	}
+
+	// Each retry waits twice as long as the previous one
+	for attempt, minDelay := range []time.Duration{baseDelay, 2 * baseDelay} {
+		start := time.Now()
+		if !c.ProcessNextWorkItem(ctx) {
+			t.Fatal("Expected the work queue to be running")
+		}
+		// Allow for timer granularity
+		if elapsed := time.Since(start); elapsed < minDelay-50*time.Millisecond {
+			t.Errorf("Retry %d ran after %s, expected at least %s of backoff", attempt+1, elapsed, minDelay)
+		}
+	}
+
+	if fetches != 3 {
+		t.Errorf("Expected 3 reconcile attempts, got %d", fetches)
+	}
+}
+
+func TestSyncMigration_Finalizer(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	newController := func(kubeClient *kubefake.Clientset, migration *migrationv1alpha1.VmwareCloudFoundationMigration) (*controller.MigrationController, *dynamicfake.FakeDynamicClient) {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+		if err != nil {
+			t.Fatalf("Failed to convert migration: %v", err)
+		}
+		dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+			map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+			&unstructured.Unstructured{Object: obj})
+
+		c, _ := controller.NewMigrationController(
+			kubeClient,
+			configfake.NewSimpleClientset(),
+			machinefake.NewSimpleClientset(),
+			dynamicClient,
+			apiextensionsfake.NewSimpleClientset(),
+			nil,
+			scheme,
+			events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+		)
+		c.EnqueueMigration(&unstructured.Unstructured{Object: obj})
+		return c, dynamicClient
+	}
+
+	getFinalizers := func(dynamicClient *dynamicfake.FakeDynamicClient) []string {
+		stored, err := dynamicClient.Resource(migrationGVR).Namespace("vmware-cloud-foundation-migration").Get(ctx, "test-migration", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get stored migration: %v", err)
+		}
+		return stored.GetFinalizers()
+	}
+
+	t.Run("added on first reconcile", func(t *testing.T) {
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "migration.openshift.io/v1alpha1",
+				Kind:       "VmwareCloudFoundationMigration",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-migration",
+				Namespace: "vmware-cloud-foundation-migration",
+			},
+			Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+				State: migrationv1alpha1.MigrationStatePending,
+			},
+		}
+
+		c, dynamicClient := newController(kubefake.NewSimpleClientset(), migration)
+		c.ProcessNextWorkItem(ctx)
+
+		if finalizers := getFinalizers(dynamicClient); len(finalizers) != 1 || finalizers[0] != migrationv1alpha1.MigrationFinalizer {
+			t.Errorf("Expected finalizer %s, got %v", migrationv1alpha1.MigrationFinalizer, finalizers)
+		}
+	})
+
+	t.Run("deletion restores workloads and removes finalizer", func(t *testing.T) {
+		now := metav1.Now()
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "migration.openshift.io/v1alpha1",
+				Kind:       "VmwareCloudFoundationMigration",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-migration",
+				Namespace:         "vmware-cloud-foundation-migration",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{migrationv1alpha1.MigrationFinalizer},
+			},
+			Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+				State: migrationv1alpha1.MigrationStateRunning,
+			},
+			Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+				Phase: migrationv1alpha1.PhaseMigrateCSIVolumes,
+				CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+					Volumes: []migrationv1alpha1.PVMigrationState{
+						{
+							PVName: "pv-quiesced",
+							Status: phases.PVStatusQuiesced,
+							ScaledDownResources: []migrationv1alpha1.ScaledResource{
+								{Kind: "Deployment", Name: "db", Namespace: "app", OriginalReplicas: 2},
+							},
+						},
+						{PVName: "pv-done", Status: phases.PVStatusComplete},
+					},
+				},
+			},
+		}
+
+		kubeClient := kubefake.NewSimpleClientset(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](0)},
+		})
+
+		c, dynamicClient := newController(kubeClient, migration)
+		c.ProcessNextWorkItem(ctx)
+
+		deployment, err := kubeClient.AppsV1().Deployments("app").Get(ctx, "db", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment: %v", err)
+		}
+		if *deployment.Spec.Replicas != 2 {
+			t.Errorf("Expected deployment to be scaled back to 2 replicas, got %d", *deployment.Spec.Replicas)
+		}
+
+		if finalizers := getFinalizers(dynamicClient); len(finalizers) != 0 {
+			t.Errorf("Expected finalizer to be removed, got %v", finalizers)
+		}
+	})
+
+	// The dummy VM cannot be cleaned up without an Infrastructure to find the vCenter in
+	deletedMigration := func(deletedAgo time.Duration, annotations map[string]string) *migrationv1alpha1.VmwareCloudFoundationMigration {
+		deleted := metav1.NewTime(time.Now().Add(-deletedAgo))
+		return &migrationv1alpha1.VmwareCloudFoundationMigration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "migration.openshift.io/v1alpha1",
+				Kind:       "VmwareCloudFoundationMigration",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-migration",
+				Namespace:         "vmware-cloud-foundation-migration",
+				DeletionTimestamp: &deleted,
+				Finalizers:        []string{migrationv1alpha1.MigrationFinalizer},
+				Annotations:       annotations,
+			},
+			Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+				Phase: migrationv1alpha1.PhaseMigrateCSIVolumes,
+				CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+					Volumes: []migrationv1alpha1.PVMigrationState{{
+						PVName:      "pv-relocating",
+						Status:      phases.PVStatusRelocating,
+						DummyVMName: "csi-migration-test-pv-relocating",
+						ScaledDownResources: []migrationv1alpha1.ScaledResource{
+							{Kind: "Deployment", Name: "db", Namespace: "app", OriginalReplicas: 2},
+						},
+					}},
+				},
+			},
+		}
+	}
+	skippedEvents := func(kubeClient *kubefake.Clientset) []corev1.Event {
+		events, err := kubeClient.CoreV1().Events("vmware-cloud-foundation-migration").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("Failed to list events: %v", err)
+		}
+		var skipped []corev1.Event
+		for _, event := range events.Items {
+			if event.Reason == migrationv1alpha1.ReasonCleanupSkipped {
+				skipped = append(skipped, event)
+			}
+		}
+		return skipped
+	}
+
+	t.Run("failed cleanup keeps finalizer for a retry", func(t *testing.T) {
+		kubeClient := kubefake.NewSimpleClientset()
+		c, dynamicClient := newController(kubeClient, deletedMigration(time.Minute, nil))
+		c.ProcessNextWorkItem(ctx)
+
+		if finalizers := getFinalizers(dynamicClient); len(finalizers) != 1 {
+			t.Errorf("Expected the finalizer to be kept while cleanup is retried, got %v", finalizers)
+		}
+		if events := skippedEvents(kubeClient); len(events) != 0 {
+			t.Errorf("Expected no %s event yet, got %v", migrationv1alpha1.ReasonCleanupSkipped, events)
+		}
+	})
+
+	for name, migration := range map[string]*migrationv1alpha1.VmwareCloudFoundationMigration{
+		"failed cleanup releases after timeout": deletedMigration(controller.FinalizerCleanupTimeout+time.Minute, nil),
+		"skip annotation releases without cleanup": deletedMigration(time.Minute,
+			map[string]string{migrationv1alpha1.SkipCleanupAnnotation: "true"}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			kubeClient := kubefake.NewSimpleClientset()
+			c, dynamicClient := newController(kubeClient, migration)
+			c.ProcessNextWorkItem(ctx)
+
+			if finalizers := getFinalizers(dynamicClient); len(finalizers) != 0 {
+				t.Errorf("Expected finalizer to be removed, got %v", finalizers)
+			}
+			events := skippedEvents(kubeClient)
+			if len(events) != 1 {
+				t.Fatalf("Expected one %s event, got %v", migrationv1alpha1.ReasonCleanupSkipped, events)
+			}
+			for _, left := range []string{"pv-relocating", "csi-migration-test-pv-relocating", "Deployment app/db"} {
+				if !strings.Contains(events[0].Message, left) {
+					t.Errorf("Expected the event to record %q, got: %s", left, events[0].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessNextWorkItem_ConcurrentMigrations(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	var objects []runtime.Object
+	states := map[string]migrationv1alpha1.MigrationState{
+		"pending-migration": migrationv1alpha1.MigrationStatePending,
+		"paused-migration":  migrationv1alpha1.MigrationStatePaused,
+	}
+	for name, migrationState := range states {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&migrationv1alpha1.VmwareCloudFoundationMigration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "migration.openshift.io/v1alpha1",
+				Kind:       "VmwareCloudFoundationMigration",
+			},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "vmware-cloud-foundation-migration"},
+			Spec:       migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationState},
+		})
+		if err != nil {
+			t.Fatalf("Failed to convert migration: %v", err)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		objects...)
+
+	// Hold every fetch until both migrations are being reconciled, so the reconciles overlap
+	barrier := &fetchBarrier{Interface: dynamicClient, parties: 2, released: make(chan struct{})}
+
+	c, _ := controller.NewMigrationControllerWithOptions(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		barrier,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+		controller.Options{Workers: 2},
+	)
+	for _, obj := range objects {
+		c.EnqueueMigration(obj)
+	}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.ProcessNextWorkItem(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if barrier.timedOut.Load() {
+		t.Fatal("Expected the two migrations to be reconciled concurrently")
+	}
+
+	// Each migration's status reflects its own state only
+	for name, migrationState := range states {
+		stored, err := dynamicClient.Resource(migrationGVR).Namespace("vmware-cloud-foundation-migration").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get stored migration: %v", err)
+		}
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.Object, migration); err != nil {
+			t.Fatalf("Failed to convert stored migration: %v", err)
+		}
+		want := "Migration is " + strings.ToLower(string(migrationState))
+		if len(migration.Status.Conditions) != 1 || migration.Status.Conditions[0].Message != want {
+			t.Errorf("Expected %s to only have condition %q, got %+v", name, want, migration.Status.Conditions)
+		}
+		if len(migration.Finalizers) != 1 {
+			t.Errorf("Expected %s to have its finalizer, got %v", name, migration.Finalizers)
+		}
+	}
+}
+
+// fetchBarrier is a dynamic client whose Gets block until parties Gets are in flight at once.
+// Reactors of the fake client run under its lock, so the wait has to happen outside of it.
+type fetchBarrier struct {
+	dynamic.Interface
+	parties  int32
+	fetching atomic.Int32
+	timedOut atomic.Bool
+	released chan struct{}
+}
+
+func (b *fetchBarrier) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &barrierResource{NamespaceableResourceInterface: b.Interface.Resource(gvr), barrier: b}
+}
+
+func (b *fetchBarrier) wait() {
+	if b.fetching.Add(1) == b.parties {
+		close(b.released)
+	}
+	select {
+	case <-b.released:
+	case <-time.After(5 * time.Second):
+		b.timedOut.Store(true)
+	}
+}
+
+type barrierResource struct {
+	dynamic.NamespaceableResourceInterface
+	barrier *fetchBarrier
+}
+
+func (r *barrierResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &barrierNamespacedResource{ResourceInterface: r.NamespaceableResourceInterface.Namespace(namespace), barrier: r.barrier}
+}
+
+type barrierNamespacedResource struct {
+	dynamic.ResourceInterface
+	barrier *fetchBarrier
+}
+
+func (r *barrierNamespacedResource) Get(ctx context.Context, name string, options metav1.GetOptions, subresources ...string) (*unstructured.Unstructured, error) {
+	r.barrier.wait()
+	return r.ResourceInterface.Get(ctx, name, options, subresources...)
+}
+
+func TestBlockingMigration(t *testing.T) {
+	older := metav1.NewTime(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(older.Add(time.Hour))
+	migration := func(name string, created metav1.Time, migrationState migrationv1alpha1.MigrationState, status migrationv1alpha1.VmwareCloudFoundationMigrationStatus) migrationv1alpha1.VmwareCloudFoundationMigration {
+		return migrationv1alpha1.VmwareCloudFoundationMigration{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", CreationTimestamp: created},
+			Spec:       migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationState},
+			Status:     status,
+		}
+	}
+	started := migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+		Phase: migrationv1alpha1.PhaseBackup,
+		PhaseHistory: []migrationv1alpha1.PhaseHistoryEntry{
+			{Phase: migrationv1alpha1.PhasePreflight, Status: migrationv1alpha1.PhaseStatusCompleted},
+		},
+	}
+	running := migrationv1alpha1.MigrationStateRunning
+
+	tests := []struct {
+		name      string
+		migration migrationv1alpha1.VmwareCloudFoundationMigration
+		others    []migrationv1alpha1.VmwareCloudFoundationMigration
+		want      string
+	}{
+		{
+			name:      "no other migration",
+			migration: migration("a", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+		},
+		{
+			name:      "pending migration is never blocked",
+			migration: migration("a", newer, migrationv1alpha1.MigrationStatePending, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others:    []migrationv1alpha1.VmwareCloudFoundationMigration{migration("b", older, running, started)},
+		},
+		{
+			name:      "started migration blocks a new one",
+			migration: migration("a", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others:    []migrationv1alpha1.VmwareCloudFoundationMigration{migration("b", newer, running, started)},
+			want:      "ns/b",
+		},
+		{
+			name:      "rolling back migration blocks a new one",
+			migration: migration("a", newer, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others: []migrationv1alpha1.VmwareCloudFoundationMigration{migration("b", older, migrationv1alpha1.MigrationStateRollback,
+				migrationv1alpha1.VmwareCloudFoundationMigrationStatus{Phase: migrationv1alpha1.PhaseRollingBack})},
+			want: "ns/b",
+		},
+		{
+			name:      "failed migration still blocks",
+			migration: migration("a", newer, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others: []migrationv1alpha1.VmwareCloudFoundationMigration{migration("b", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+				Phase:        migrationv1alpha1.PhaseFailed,
+				PhaseHistory: started.PhaseHistory,
+			})},
+			want: "ns/b",
+		},
+		{
+			name:      "completed, rolled back and paused migrations do not block",
+			migration: migration("a", newer, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others: []migrationv1alpha1.VmwareCloudFoundationMigration{
+				migration("b", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{Phase: migrationv1alpha1.PhaseCompleted}),
+				migration("c", older, migrationv1alpha1.MigrationStateRollback, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{Phase: migrationv1alpha1.PhaseRollbackCompleted}),
+				migration("d", older, migrationv1alpha1.MigrationStatePaused, started),
+			},
+		},
+		{
+			name:      "oldest of two new migrations goes first",
+			migration: migration("a", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others:    []migrationv1alpha1.VmwareCloudFoundationMigration{migration("b", newer, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{})},
+		},
+		{
+			name:      "newest of two new migrations waits",
+			migration: migration("b", newer, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{}),
+			others:    []migrationv1alpha1.VmwareCloudFoundationMigration{migration("a", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{})},
+			want:      "ns/a",
+		},
+		{
+			name:      "blocked migration does not block",
+			migration: migration("a", newer, running, started),
+			others: []migrationv1alpha1.VmwareCloudFoundationMigration{migration("b", older, running, migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+				Phase:        started.Phase,
+				PhaseHistory: started.PhaseHistory,
+				BlockedBy:    "ns/a",
+			})},
+		},
+		{
+			name:      "oldest of two started migrations wins",
+			migration: migration("b", newer, running, started),
+			others:    []migrationv1alpha1.VmwareCloudFoundationMigration{migration("a", older, running, started)},
+			want:      "ns/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The listed migrations include the one being reconciled
+			all := append([]migrationv1alpha1.VmwareCloudFoundationMigration{tt.migration}, tt.others...)
+			if got := controller.BlockingMigration(&tt.migration, all); got != tt.want {
+				t.Errorf("expected blocking migration %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestProcessNextWorkItem_SkipsPhaseNotDue(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	// The running phase asked to be executed again in an hour, and the spec is unchanged since
+	nextReconcile := metav1.NewTime(time.Now().Add(time.Hour))
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-migration",
+			Namespace:  "vmware-cloud-foundation-migration",
+			Generation: 3,
+			Finalizers: []string{migrationv1alpha1.MigrationFinalizer},
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase:              migrationv1alpha1.PhaseMonitorHealth,
+			ObservedGeneration: 3,
+			CurrentPhaseState: &migrationv1alpha1.PhaseState{
+				Name:              migrationv1alpha1.PhaseMonitorHealth,
+				Status:            migrationv1alpha1.PhaseStatusRunning,
+				NextReconcileTime: &nextReconcile,
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+
+	statusWrites := 0
+	dynamicClient.PrependReactor("update", "vmwarecloudfoundationmigrations", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "status" {
+			statusWrites++
+		}
+		return false, nil, nil
+	})
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	c.EnqueueMigration(&unstructured.Unstructured{Object: obj})
+	c.ProcessNextWorkItem(ctx)
+	if statusWrites != 0 {
+		t.Fatalf("Expected a reconcile before the phase is due to be skipped, got %d status writes", statusWrites)
+	}
+
+	// A spec change is reconciled straight away
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	stored.SetGeneration(4)
+	if _, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Update(ctx, stored, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update migration: %v", err)
+	}
+
+	c.EnqueueMigration(stored)
+	c.ProcessNextWorkItem(ctx)
+	if statusWrites == 0 {
+		t.Fatal("Expected the phase to be executed after a spec change")
+	}
+
+	stored, err = dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	observed, _, _ := unstructured.NestedInt64(stored.Object, "status", "observedGeneration")
+	if observed != 4 {
+		t.Errorf("Expected observedGeneration 4, got %d", observed)
+	}
+}
+
+func TestProcessNextWorkItem_BlockedByActiveMigration(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	active := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "active-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec:       migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhaseUpdateInfrastructure,
+			PhaseHistory: []migrationv1alpha1.PhaseHistoryEntry{
+				{Phase: migrationv1alpha1.PhasePreflight, Status: migrationv1alpha1.PhaseStatusCompleted},
+			},
+		},
+	}
+	waiting := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta:   active.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{Name: "new-migration", Namespace: "other-namespace"},
+		Spec:       migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+	}
+
+	var objects []runtime.Object
+	for _, m := range []*migrationv1alpha1.VmwareCloudFoundationMigration{active, waiting} {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(m)
+		if err != nil {
+			t.Fatalf("Failed to convert migration: %v", err)
+		}
+		objects = append(objects, &unstructured.Unstructured{Object: obj})
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		objects...)
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+	c.EnqueueMigration(objects[1])
+	c.ProcessNextWorkItem(ctx)
+
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(waiting.Namespace).Get(ctx, waiting.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.Object, migration); err != nil {
+		t.Fatalf("Failed to convert stored migration: %v", err)
+	}
+
+	if migration.Status.BlockedBy != "vmware-cloud-foundation-migration/active-migration" {
+		t.Errorf("Expected the migration to be blocked by the active one, got %q", migration.Status.BlockedBy)
+	}
+	state := migration.Status.CurrentPhaseState
+	if state == nil || state.Name != migrationv1alpha1.PhasePreflight || state.Status != migrationv1alpha1.PhaseStatusBlocked {
+		t.Fatalf("Expected Preflight to be blocked, got %+v", state)
+	}
+	if !strings.Contains(state.Message, "active-migration") {
+		t.Errorf("Expected the message to name the active migration, got %q", state.Message)
+	}
+	if len(migration.Status.PhaseHistory) != 0 {
+		t.Errorf("Expected no phase to run while blocked, got %+v", migration.Status.PhaseHistory)
+	}
+	if len(migration.Status.Conditions) != 1 || migration.Status.Conditions[0].Reason != migrationv1alpha1.ReasonBlocked {
+		t.Errorf("Expected a Blocked condition, got %+v", migration.Status.Conditions)
+	}
+}
+
+func TestProcessNextWorkItem_AlertsOnScaledDownWorkloads(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	// One failed volume has kept its Deployment scaled down for an hour, another for five minutes
+	longAgo := metav1.NewTime(time.Now().Add(-time.Hour))
+	recently := metav1.NewTime(time.Now().Add(-5 * time.Minute))
+	scaledDown := []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Namespace: "app", Name: "db", OriginalReplicas: 1}}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-migration",
+			Namespace:  "vmware-cloud-foundation-migration",
+			Finalizers: []string{migrationv1alpha1.MigrationFinalizer},
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhaseCompleted,
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				TotalVolumes:  2,
+				FailedVolumes: 2,
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{PVName: "pv-old", Status: phases.PVStatusFailed, StartTime: &longAgo, ScaledDownResources: scaledDown, Message: "Failed to relocate volume"},
+					{PVName: "pv-new", Status: phases.PVStatusFailed, StartTime: &recently, ScaledDownResources: scaledDown},
+				},
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+	kubeClient := kubefake.NewSimpleClientset()
+
+	c, _ := controller.NewMigrationController(
+		kubeClient,
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	// The event is emitted once, not on every reconcile
+	for range 2 {
+		c.EnqueueMigration(&unstructured.Unstructured{Object: obj})
+		c.ProcessNextWorkItem(ctx)
+	}
+
+	eventList, err := kubeClient.CoreV1().Events(migration.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(eventList.Items) != 1 {
+		t.Fatalf("Expected one event for the volume past the threshold, got %d", len(eventList.Items))
+	}
+	event := eventList.Items[0]
+	if event.Type != "Warning" || event.Reason != migrationv1alpha1.ReasonScaledDownTooLong ||
+		event.InvolvedObject.Name != migration.Name || !strings.Contains(event.Message, "pv-old") {
+		t.Errorf("Unexpected event %+v", event)
+	}
+
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	updated := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.Object, updated); err != nil {
+		t.Fatalf("Failed to convert stored migration: %v", err)
+	}
+	var condition *metav1.Condition
+	for i := range updated.Status.Conditions {
+		if updated.Status.Conditions[i].Type == migrationv1alpha1.ConditionWorkloadsScaledDown {
+			condition = &updated.Status.Conditions[i]
+		}
+	}
+	if condition == nil || condition.Status != metav1.ConditionTrue ||
+		!strings.Contains(condition.Message, "pv-old (Deployment/app/db)") || strings.Contains(condition.Message, "pv-new") {
+		t.Errorf("Expected the WorkloadsScaledDown condition to list only pv-old, got %+v", condition)
+	}
+	volumes := updated.Status.CSIVolumeMigration.Volumes
+	if volumes[0].ScaledDownAlertTime == nil || volumes[1].ScaledDownAlertTime != nil {
+		t.Errorf("Expected only pv-old to be marked as reported, got %v and %v", volumes[0].ScaledDownAlertTime, volumes[1].ScaledDownAlertTime)
+	}
+}
+
+func TestProcessNextWorkItem_CancelsMigration(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	// web only uses an untouched volume; db also uses one that has already been relocated
+	web := []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Namespace: "app", Name: "web", OriginalReplicas: 2}}
+	db := []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Namespace: "app", Name: "db", OriginalReplicas: 1}}
+	completed := func(phase migrationv1alpha1.MigrationPhase) migrationv1alpha1.PhaseHistoryEntry {
+		return migrationv1alpha1.PhaseHistoryEntry{Phase: phase, Status: migrationv1alpha1.PhaseStatusCompleted, StartTime: metav1.Now()}
+	}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-migration",
+			Namespace:  "vmware-cloud-foundation-migration",
+			Finalizers: []string{migrationv1alpha1.MigrationFinalizer},
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateCancelled},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhaseUpdateSecrets,
+			PhaseHistory: []migrationv1alpha1.PhaseHistoryEntry{
+				completed(migrationv1alpha1.PhasePreflight),
+				completed(migrationv1alpha1.PhaseBackup),
+				completed(migrationv1alpha1.PhaseDisableCVO),
+			},
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				TotalVolumes: 3,
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{PVName: "pv-web", Status: phases.PVStatusQuiesced, ScaledDownResources: web, OriginalReclaimPolicy: "Delete"},
+					{PVName: "pv-db-0", Status: phases.PVStatusRelocated, ScaledDownResources: db},
+					{PVName: "pv-db-1", Status: phases.PVStatusQuiesced, ScaledDownResources: db},
+				},
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](0)},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(deployment("web"), deployment("db"),
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-web"},
+			Spec:       corev1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain},
+		})
+
+	c, _ := controller.NewMigrationController(
+		kubeClient,
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	// Cancelling is done once; later reconciles leave the cancelled migration alone
+	for range 2 {
+		c.EnqueueMigration(&unstructured.Unstructured{Object: obj})
+		c.ProcessNextWorkItem(ctx)
+	}
+
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	updated := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.Object, updated); err != nil {
+		t.Fatalf("Failed to convert stored migration: %v", err)
+	}
+
+	if updated.Status.Phase != migrationv1alpha1.PhaseCancelled {
+		t.Fatalf("Expected phase %s, got %s", migrationv1alpha1.PhaseCancelled, updated.Status.Phase)
+	}
+	cancellation := updated.Status.Cancellation
+	if cancellation == nil {
+		t.Fatal("Expected the cancellation to be recorded")
+	}
+	if cancellation.CancelledPhase != migrationv1alpha1.PhaseUpdateSecrets {
+		t.Errorf("Expected cancelled phase %s, got %s", migrationv1alpha1.PhaseUpdateSecrets, cancellation.CancelledPhase)
+	}
+	if len(cancellation.RemainingPhases) == 0 || cancellation.RemainingPhases[0] != migrationv1alpha1.PhaseUpdateSecrets ||
+		slices.Contains(cancellation.RemainingPhases, migrationv1alpha1.PhaseDisableCVO) {
+		t.Errorf("Expected the remaining phases to start at %s, got %v", migrationv1alpha1.PhaseUpdateSecrets, cancellation.RemainingPhases)
+	}
+	if !slices.Equal(cancellation.RestoredWorkloads, []string{"Deployment/app/web"}) {
+		t.Errorf("Expected only Deployment/app/web to be restored, got %v", cancellation.RestoredWorkloads)
+	}
+	intervention := strings.Join(cancellation.ManualIntervention, "\n")
+	for _, expected := range []string{"volume pv-db-0 was left relocated", "volume pv-db-1 was not migrated", "cluster-version-operator"} {
+		if !strings.Contains(intervention, expected) {
+			t.Errorf("Expected manual intervention to mention %q, got %v", expected, cancellation.ManualIntervention)
+		}
+	}
+
+	volumes := updated.Status.CSIVolumeMigration.Volumes
+	if volumes[0].Status != phases.PVStatusSkipped || volumes[1].Status != phases.PVStatusRelocated || volumes[2].Status != phases.PVStatusQuiesced {
+		t.Errorf("Expected only the untouched volume to be skipped, got %s, %s, %s", volumes[0].Status, volumes[1].Status, volumes[2].Status)
+	}
+
+	for name, replicas := range map[string]int32{"web": 2, "db": 0} {
+		d, err := kubeClient.AppsV1().Deployments("app").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment %s: %v", name, err)
+		}
+		if *d.Spec.Replicas != replicas {
+			t.Errorf("Expected deployment %s to have %d replicas, got %d", name, replicas, *d.Spec.Replicas)
+		}
+	}
+	pv, err := kubeClient.CoreV1().PersistentVolumes().Get(ctx, "pv-web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PV: %v", err)
+	}
+	if pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+		t.Errorf("Expected the reclaim policy of pv-web to be restored, got %s", pv.Spec.PersistentVolumeReclaimPolicy)
+	}
+
+	cm, err := kubeClient.CoreV1().ConfigMaps(migration.Namespace).Get(ctx, "test-migration-report", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the report to be written: %v", err)
+	}
+	if !strings.Contains(cm.Data["summary"], "result=Cancelled") || !strings.Contains(cm.Data["summary"], "cancelledPhase=UpdateSecrets") {
+		t.Errorf("Expected the report summary to record the cancellation, got %q", cm.Data["summary"])
+	}
+}
+
+func TestUpdateMigration_EnqueuesRequests(t *testing.T) {
+	base := &unstructured.Unstructured{}
+	base.SetName("test-migration")
+	base.SetNamespace("vmware-cloud-foundation-migration")
+	base.SetGeneration(1)
+	base.SetAnnotations(map[string]string{"example.com/owner": "team-a"})
+
+	type updateCase struct {
+		name    string
+		update  func(obj *unstructured.Unstructured)
+		enqueue bool
+	}
+	tests := []updateCase{
+		{
+			name: "status write",
+			update: func(obj *unstructured.Unstructured) {
+				_ = unstructured.SetNestedField(obj.Object, "Running", "status", "phase")
+			},
+		},
+		{
+			name: "unrelated annotation",
+			update: func(obj *unstructured.Unstructured) {
+				obj.SetAnnotations(map[string]string{"example.com/owner": "team-b"})
+			},
+		},
+		{
+			name:    "spec change",
+			update:  func(obj *unstructured.Unstructured) { obj.SetGeneration(2) },
+			enqueue: true,
+		},
+		{
+			name: "deletion",
+			update: func(obj *unstructured.Unstructured) {
+				now := metav1.Now()
+				obj.SetDeletionTimestamp(&now)
+			},
+			enqueue: true,
+		},
+	}
+	for _, annotation := range migrationv1alpha1.RequestAnnotations {
+		tests = append(tests, updateCase{
+			name: annotation,
+			update: func(obj *unstructured.Unstructured) {
+				obj.SetAnnotations(map[string]string{"example.com/owner": "team-a", annotation: "true"})
+			},
+			enqueue: true,
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			c, _ := controller.NewMigrationController(
+				kubefake.NewSimpleClientset(),
+				configfake.NewSimpleClientset(),
+				machinefake.NewSimpleClientset(),
+				dynamicfake.NewSimpleDynamicClient(scheme),
+				apiextensionsfake.NewSimpleClientset(),
+				nil,
+				scheme,
+				events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+			)
+
+			updated := base.DeepCopy()
+			tt.update(updated)
+			c.UpdateMigration(base, updated)
+			if enqueued := c.QueueLen() == 1; enqueued != tt.enqueue {
+				t.Errorf("Expected enqueued=%v, queue has %d items", tt.enqueue, c.QueueLen())
+			}
+		})
+	}
+}
+
+func TestUpdateMigration_RetriesFailedPhase(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	// UpdateInfrastructure is gated, so the retried phase stops at its approval gate
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-migration",
+			Namespace:  "vmware-cloud-foundation-migration",
+			Generation: 1,
+			Finalizers: []string{migrationv1alpha1.MigrationFinalizer},
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			State:                 migrationv1alpha1.MigrationStateRunning,
+			RequireApprovalBefore: []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseUpdateInfrastructure},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase:              migrationv1alpha1.PhaseFailed,
+			ObservedGeneration: 1,
+			CurrentPhaseState: &migrationv1alpha1.PhaseState{
+				Name:   migrationv1alpha1.PhaseUpdateInfrastructure,
+				Status: migrationv1alpha1.PhaseStatusFailed,
+			},
+			PhaseHistory: []migrationv1alpha1.PhaseHistoryEntry{
+				{Phase: migrationv1alpha1.PhaseUpdateInfrastructure, Status: migrationv1alpha1.PhaseStatusFailed, StartTime: metav1.Now()},
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	// A status write is not reconciled
+	old := &unstructured.Unstructured{Object: obj}
+	statusOnly := old.DeepCopy()
+	if err := unstructured.SetNestedField(statusOnly.Object, "Phase UpdateInfrastructure failed", "status", "currentPhaseState", "message"); err != nil {
+		t.Fatalf("Failed to set status message: %v", err)
+	}
+	c.UpdateMigration(old, statusOnly)
+	if c.QueueLen() != 0 {
+		t.Fatalf("Expected a status-only update not to be enqueued, queue has %d items", c.QueueLen())
+	}
+
+	// The retry annotation is a metadata-only change but is reconciled straight away
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	annotated := stored.DeepCopy()
+	annotated.SetAnnotations(map[string]string{
+		migrationv1alpha1.RetryPhaseAnnotation: string(migrationv1alpha1.PhaseUpdateInfrastructure),
+	})
+	annotated, err = dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Update(ctx, annotated, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("Failed to annotate migration: %v", err)
+	}
+	c.UpdateMigration(stored, annotated)
+	if c.QueueLen() != 1 {
+		t.Fatalf("Expected the retry annotation to enqueue the migration, queue has %d items", c.QueueLen())
+	}
+	c.ProcessNextWorkItem(ctx)
+
+	stored, err = dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	updated := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.Object, updated); err != nil {
+		t.Fatalf("Failed to convert stored migration: %v", err)
+	}
+	if updated.Status.Phase != migrationv1alpha1.PhaseUpdateInfrastructure {
+		t.Errorf("Expected the failed phase %s to be re-entered, got %s", migrationv1alpha1.PhaseUpdateInfrastructure, updated.Status.Phase)
+	}
+	if state := updated.Status.CurrentPhaseState; state == nil || !state.RequiresApproval || state.Approved {
+		t.Errorf("Expected the retried phase to wait at its approval gate, got %+v", state)
+	}
+	if _, ok := updated.Annotations[migrationv1alpha1.RetryPhaseAnnotation]; ok {
+		t.Error("Expected the retry annotation to be removed")
+	}
+}
+
+func TestSyncMigration_LeftoverApprovalDoesNotApproveGate(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	// The gated phase was just retried, leaving the approval given on its first run behind
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-migration",
+			Namespace:  "vmware-cloud-foundation-migration",
+			Finalizers: []string{migrationv1alpha1.MigrationFinalizer},
+			Annotations: map[string]string{
+				migrationv1alpha1.ApprovePhaseAnnotation: string(migrationv1alpha1.PhaseUpdateInfrastructure),
+			},
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			State:                 migrationv1alpha1.MigrationStateRunning,
+			RequireApprovalBefore: []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseUpdateInfrastructure},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhaseUpdateInfrastructure,
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	// The gate is reached again and keeps waiting on later reconciles
+	for range 2 {
+		c.EnqueueMigration(&unstructured.Unstructured{Object: obj})
+		c.ProcessNextWorkItem(ctx)
+	}
+
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	updated := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(stored.Object, updated); err != nil {
+		t.Fatalf("Failed to convert stored migration: %v", err)
+	}
+	if _, ok := updated.Annotations[migrationv1alpha1.ApprovePhaseAnnotation]; ok {
+		t.Error("Expected the leftover approval annotation to be removed")
+	}
+	if state := updated.Status.CurrentPhaseState; state == nil || !state.RequiresApproval || state.Approved {
+		t.Errorf("Expected the phase to wait for a new approval, got %+v", state)
+	}
+	if updated.Status.Phase != migrationv1alpha1.PhaseUpdateInfrastructure {
+		t.Errorf("Expected phase %s, got %s", migrationv1alpha1.PhaseUpdateInfrastructure, updated.Status.Phase)
+	}
+}
+
+func TestUpdateMigration_AllowSourceVolumesSkipsWait(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	// Cleanup refused to remove the source vCenter and asked to be checked again in 5 minutes
+	nextReconcile := metav1.NewTime(time.Now().Add(5 * time.Minute))
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test-migration",
+			Namespace:  "vmware-cloud-foundation-migration",
+			Generation: 2,
+			Finalizers: []string{migrationv1alpha1.MigrationFinalizer},
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase:              migrationv1alpha1.PhaseCleanup,
+			ObservedGeneration: 2,
+			CurrentPhaseState: &migrationv1alpha1.PhaseState{
+				Name:              migrationv1alpha1.PhaseCleanup,
+				Status:            migrationv1alpha1.PhaseStatusRunning,
+				NextReconcileTime: &nextReconcile,
+			},
+		},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+
+	statusWrites := 0
+	dynamicClient.PrependReactor("update", "vmwarecloudfoundationmigrations", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "status" {
+			statusWrites++
+		}
+		return false, nil, nil
+	})
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	old := &unstructured.Unstructured{Object: obj}
+	annotated := old.DeepCopy()
+	annotated.SetAnnotations(map[string]string{migrationv1alpha1.AllowSourceVolumesAnnotation: "true"})
+	if _, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Update(ctx, annotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to annotate migration: %v", err)
+	}
+
+	// The annotation is acted on straight away rather than when the phase is next due
+	c.UpdateMigration(old, annotated)
+	if c.QueueLen() != 1 {
+		t.Fatalf("Expected the annotation to enqueue the migration, queue has %d items", c.QueueLen())
+	}
+	c.ProcessNextWorkItem(ctx)
+	if statusWrites == 0 {
+		t.Fatal("Expected the phase to be executed after the annotation was set")
+	}
+
+	// The request is used up, so a later reconcile waits for the phase again
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	stored.Object["status"] = obj["status"]
+	if _, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).UpdateStatus(ctx, stored, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to reset migration status: %v", err)
+	}
+	statusWrites = 0
+	c.EnqueueMigration(stored)
+	c.ProcessNextWorkItem(ctx)
+	if statusWrites != 0 {
+		t.Errorf("Expected a reconcile without a new request to wait for the phase, got %d status writes", statusWrites)
+	}
+}
+
+func TestUpdateMigrationState_KeepsUserState(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "migration.openshift.io/v1alpha1",
+			Kind:       "VmwareCloudFoundationMigration",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test-migration",
+			Namespace:       "vmware-cloud-foundation-migration",
+			ResourceVersion: "1",
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+	}
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(migration)
+	if err != nil {
+		t.Fatalf("Failed to convert migration: %v", err)
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{migrationGVR: "VmwareCloudFoundationMigrationList"},
+		&unstructured.Unstructured{Object: obj})
+
+	// The fake tracker ignores resourceVersion preconditions, so patches check them as the API
+	// server does
+	dynamicClient.PrependReactor("patch", "vmwarecloudfoundationmigrations", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(clienttesting.PatchAction)
+		var patch struct {
+			Metadata struct {
+				ResourceVersion string `json:"resourceVersion"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(patchAction.GetPatch(), &patch); err != nil {
+			return true, nil, err
+		}
+		current, err := dynamicClient.Tracker().Get(migrationGVR, patchAction.GetNamespace(), patchAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		if rv := current.(metav1.Object).GetResourceVersion(); patch.Metadata.ResourceVersion != "" && patch.Metadata.ResourceVersion != rv {
+			return true, nil, apierrors.NewConflict(migrationGVR.GroupResource(), patchAction.GetName(),
+				fmt.Errorf("resourceVersion %s does not match %s", patch.Metadata.ResourceVersion, rv))
+		}
+		return false, nil, nil
+	})
+
+	c, _ := controller.NewMigrationController(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicClient,
+		apiextensionsfake.NewSimpleClientset(),
+		nil,
+		scheme,
+		events.NewInMemoryRecorder("test", clocktesting.NewFakePassiveClock(metav1.Now().Time)),
+	)
+
+	// The user rolls the migration back after the controller read it
+	stored, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	if err := unstructured.SetNestedField(stored.Object, string(migrationv1alpha1.MigrationStateRollback), "spec", "state"); err != nil {
+		t.Fatalf("Failed to set state: %v", err)
+	}
+	stored.SetResourceVersion("2")
+	if _, err := dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Update(ctx, stored, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update migration: %v", err)
+	}
+
+	paused := migration.DeepCopy()
+	paused.Spec.State = migrationv1alpha1.MigrationStatePaused
+	if err := c.UpdateMigrationState(ctx, paused); !apierrors.IsConflict(err) {
+		t.Fatalf("Expected pausing a migration changed since it was read to conflict, got %v", err)
+	}
+
+	stored, err = dynamicClient.Resource(migrationGVR).Namespace(migration.Namespace).Get(ctx, migration.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get stored migration: %v", err)
+	}
+	if state, _, _ := unstructured.NestedString(stored.Object, "spec", "state"); state != string(migrationv1alpha1.MigrationStateRollback) {
+		t.Errorf("Expected the user's state %s to be kept, got %s", migrationv1alpha1.MigrationStateRollback, state)
+	}
+
+	// Pausing the migration as it was last read succeeds
+	paused.ResourceVersion = stored.GetResourceVersion()
+	if err := c.UpdateMigrationState(ctx, paused); err != nil {
+		t.Fatalf("UpdateMigrationState failed: %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+func TestCredentialKeys(t *testing.T) {
+	keys, err := openshift.NewCredentialKeys(nil)
+	if err != nil {
+		t.Fatalf("NewCredentialKeys failed: %v", err)
+	}
+	usernameKey, passwordKey, err := keys.For("vcenter.example.com")
+	if err != nil {
+		t.Fatalf("For failed: %v", err)
+	}
+	if usernameKey != "vcenter.example.com.username" || passwordKey != "vcenter.example.com.password" {
+		t.Errorf("Unexpected default keys %s and %s", usernameKey, passwordKey)
+	}
+
+	keys, err = openshift.NewCredentialKeys(&migrationv1alpha1.CredentialKeyFormat{Username: "{{.Server}}_user"})
+	if err != nil {
+		t.Fatalf("NewCredentialKeys failed: %v", err)
+	}
+	usernameKey, passwordKey, err = keys.For("vcenter.example.com")
+	if err != nil {
+		t.Fatalf("For failed: %v", err)
+	}
+	if usernameKey != "vcenter.example.com_user" || passwordKey != "vcenter.example.com.password" {
+		t.Errorf("Unexpected keys %s and %s", usernameKey, passwordKey)
+	}
+
+	for _, tmpl := range []string{"{{.Server", "{{.Unknown}}"} {
+		keys, err := openshift.NewCredentialKeys(&migrationv1alpha1.CredentialKeyFormat{Password: tmpl})
+		if err == nil {
+			_, _, err = keys.For("vcenter.example.com")
+		}
+		if err == nil {
+			t.Errorf("Expected template %q to be rejected", tmpl)
+		}
+	}
+}
+
+func TestSecretCredentialProvider(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "target-creds", Namespace: "migration"},
+		Data: map[string][]byte{
+			"user-vcenter.example.com": []byte("admin"),
+			"pass-vcenter.example.com": []byte("secret"),
+		},
+	})
+	keys, err := openshift.NewCredentialKeys(&migrationv1alpha1.CredentialKeyFormat{
+		Username: "user-{{.Server}}",
+		Password: "pass-{{.Server}}",
+	})
+	if err != nil {
+		t.Fatalf("NewCredentialKeys failed: %v", err)
+	}
+
+	provider := openshift.NewSecretCredentialProvider(client, "migration", "target-creds", keys)
+	username, password, err := provider.GetCredentials(ctx, "vcenter.example.com")
+	if err != nil {
+		t.Fatalf("GetCredentials failed: %v", err)
+	}
+	if username != "admin" || password != "secret" {
+		t.Errorf("Unexpected credentials %s/%s", username, password)
+	}
+
+	if _, _, err := provider.GetCredentials(ctx, "other.example.com"); err == nil {
+		t.Error("Expected missing keys to fail")
+	}
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		"vcenter.example.com.username": "admin\n",
+		"vcenter.example.com.password": "secret",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	keys, err := openshift.NewCredentialKeys(nil)
+	if err != nil {
+		t.Fatalf("NewCredentialKeys failed: %v", err)
+	}
+
+	provider := openshift.NewFileCredentialProvider(dir, keys)
+	username, password, err := provider.GetCredentials(ctx, "vcenter.example.com")
+	if err != nil {
+		t.Fatalf("GetCredentials failed: %v", err)
+	}
+	if username != "admin" || password != "secret" {
+		t.Errorf("Unexpected credentials %q/%q", username, password)
+	}
+
+	if _, _, err := provider.GetCredentials(ctx, "other.example.com"); err == nil {
+		t.Error("Expected missing files to fail")
+	}
+
+	// Keys must name a file inside the directory
+	escaping, err := openshift.NewCredentialKeys(&migrationv1alpha1.CredentialKeyFormat{Username: "../{{.Server}}"})
+	if err != nil {
+		t.Fatalf("NewCredentialKeys failed: %v", err)
+	}
+	if _, _, err := openshift.NewFileCredentialProvider(dir, escaping).GetCredentials(ctx, "vcenter.example.com"); err == nil {
+		t.Error("Expected a key outside the directory to be rejected")
+	}
+}
+
+func TestCommandCredentialProvider(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "creds.sh")
+	content := `#!/bin/sh
+if [ "$1" = "vcenter.example.com" ]; then
+  echo '{"username": "admin", "password": "secret"}'
+  exit 0
+fi
+echo "unknown server $1" >&2
+exit 1
+`
+	if err := os.WriteFile(script, []byte(content), 0o700); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	provider := openshift.NewCommandCredentialProvider(script)
+	username, password, err := provider.GetCredentials(ctx, "vcenter.example.com")
+	if err != nil {
+		t.Fatalf("GetCredentials failed: %v", err)
+	}
+	if username != "admin" || password != "secret" {
+		t.Errorf("Unexpected credentials %s/%s", username, password)
+	}
+
+	if _, _, err := provider.GetCredentials(ctx, "other.example.com"); err == nil {
+		t.Error("Expected a failing command to fail")
+	}
+}
+
+func TestValidateCredentialsForServers(t *testing.T) {
+	ctx := context.Background()
+	client := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "target-creds", Namespace: "migration"},
+		Data: map[string][]byte{
+			"vcenter1.example.com.username": []byte("admin"),
+			"vcenter1.example.com.password": []byte("secret"),
+			"vcenter2.example.com.username": []byte("admin"),
+		},
+	})
+	keys, err := openshift.NewCredentialKeys(nil)
+	if err != nil {
+		t.Fatalf("NewCredentialKeys failed: %v", err)
+	}
+	manager := openshift.NewSecretManager(client)
+
+	if err := manager.ValidateCredentialsForServers(ctx, "migration", "target-creds", []string{"vcenter1.example.com", "vcenter1.example.com"}, keys); err != nil {
+		t.Errorf("Expected credentials to be complete, got %v", err)
+	}
+
+	err = manager.ValidateCredentialsForServers(ctx, "migration", "target-creds",
+		[]string{"vcenter1.example.com", "vcenter2.example.com", "vcenter3.example.com"}, keys)
+	if err == nil {
+		t.Fatal("Expected missing credentials to be reported")
+	}
+	for _, want := range []string{"vcenter2.example.com (missing vcenter2.example.com.password)", "vcenter3.example.com (missing vcenter3.example.com.username, vcenter3.example.com.password)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "vcenter1.example.com") {
+		t.Errorf("Expected complete server not to be reported, got %v", err)
+	}
+
+	if err := manager.ValidateCredentialsForServers(ctx, "migration", "missing", []string{"vcenter1.example.com"}, keys); err == nil {
+		t.Error("Expected a missing secret to fail")
+	}
+}
+
+func TestGetSourceVCenterCreds(t *testing.T) {
+	ctx := context.Background()
+
+	manager := openshift.NewSecretManager(kubefake.NewSimpleClientset())
+	if _, _, err := manager.GetSourceVCenterCreds(ctx, "source.example.com"); err == nil || !strings.Contains(err.Error(), "kube-system/vsphere-creds") {
+		t.Errorf("Expected a missing secret to be reported by name, got %v", err)
+	}
+
+	manager = openshift.NewSecretManager(kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: openshift.VSphereCredsSecretName, Namespace: openshift.VSphereCredsSecretNamespace},
+		Data: map[string][]byte{
+			"source.example.com.username": []byte("admin"),
+			"source.example.com.password": []byte("secret"),
+			"other.example.com.username":  []byte(""),
+		},
+	}))
+
+	username, password, err := manager.GetSourceVCenterCreds(ctx, "source.example.com")
+	if err != nil {
+		t.Fatalf("GetSourceVCenterCreds failed: %v", err)
+	}
+	if username != "admin" || password != "secret" {
+		t.Errorf("Unexpected credentials %s/%s", username, password)
+	}
+
+	_, _, err = manager.GetSourceVCenterCreds(ctx, "other.example.com")
+	if err == nil {
+		t.Fatal("Expected empty and missing keys to be reported")
+	}
+	if !strings.Contains(err.Error(), "other.example.com.username, other.example.com.password") {
+		t.Errorf("Expected both keys to be named, got %v", err)
+	}
+}
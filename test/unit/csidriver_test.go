@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+const sourceCSIConfig = `[Global]
+cluster-id = "cluster-x7x2g"
+cluster-distribution = "OpenShift"
+
+[VirtualCenter "source.example.com"]
+insecure-flag = "true"
+datacenters = "DC0"
+migration-datastore-url = "ds:///vmfs/volumes/source/"
+`
+
+func TestSetCSIConfigVCenters(t *testing.T) {
+	config, err := openshift.SetCSIConfigVCenters(sourceCSIConfig, []openshift.CSIVCenter{
+		{Server: "target.example.com", Datacenters: []string{"DC1", "DC2"}},
+	})
+	if err != nil {
+		t.Fatalf("SetCSIConfigVCenters failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`cluster-id = "cluster-x7x2g"`,
+		`[VirtualCenter "target.example.com"]`,
+		`insecure-flag = "true"`,
+		`datacenters = "DC1,DC2"`,
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("Expected config to contain %q, got:\n%s", want, config)
+		}
+	}
+	for _, unwanted := range []string{"source.example.com", "migration-datastore-url", `"DC0"`} {
+		if strings.Contains(config, unwanted) {
+			t.Errorf("Expected config not to contain %q, got:\n%s", unwanted, config)
+		}
+	}
+
+	// A vCenter hosting both the source and a target keeps its own settings
+	config, err = openshift.SetCSIConfigVCenters(sourceCSIConfig, []openshift.CSIVCenter{
+		{Server: "source.example.com", Datacenters: []string{"DC3"}},
+	})
+	if err != nil {
+		t.Fatalf("SetCSIConfigVCenters failed: %v", err)
+	}
+	if !strings.Contains(config, "migration-datastore-url") || !strings.Contains(config, `datacenters = "DC3"`) ||
+		strings.Count(config, "datacenters") != 1 {
+		t.Errorf("Expected the existing section to be updated in place, got:\n%s", config)
+	}
+
+	if _, err := openshift.SetCSIConfigVCenters(sourceCSIConfig, nil); err == nil {
+		t.Error("Expected an empty vCenter list to be rejected")
+	}
+}
+
+func TestCSIDriverConfigManager(t *testing.T) {
+	ctx := context.Background()
+	updateTime := time.Now().Add(-time.Minute)
+	client := kubefake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: openshift.CSIDriverConfigSecretName, Namespace: openshift.CSIDriverNamespace},
+			Data:       map[string][]byte{openshift.CSIDriverConfigKey: []byte(sourceCSIConfig)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: openshift.CSIDriverCredentialsSecretName, Namespace: openshift.CSIDriverNamespace},
+			Data: map[string][]byte{
+				"source.example.com.username": []byte("old"),
+				"source.example.com.password": []byte("old"),
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "vmware-vsphere-csi-driver-controller-1",
+				Namespace:         openshift.CSIDriverNamespace,
+				Labels:            map[string]string{"app": "vmware-vsphere-csi-driver-controller"},
+				CreationTimestamp: metav1.NewTime(updateTime.Add(-time.Hour)),
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+	)
+	manager := openshift.NewCSIDriverConfigManager(client)
+
+	targets := []openshift.CSIVCenter{
+		{Server: "target.example.com", Datacenters: []string{"DC1"}, Username: "admin", Password: "secret"},
+	}
+	changed, err := manager.SetVCenters(ctx, targets)
+	if err != nil {
+		t.Fatalf("SetVCenters failed: %v", err)
+	}
+	if !changed {
+		t.Error("Expected pointing the driver at the target to change its config")
+	}
+
+	// A second run, or a config the operator already regenerated, writes nothing
+	changed, err = manager.SetVCenters(ctx, targets)
+	if err != nil {
+		t.Fatalf("SetVCenters failed: %v", err)
+	}
+	if changed {
+		t.Error("Expected a config already listing the target to be left unchanged")
+	}
+
+	servers, err := manager.ConfiguredVCenters(ctx)
+	if err != nil {
+		t.Fatalf("ConfiguredVCenters failed: %v", err)
+	}
+	if len(servers) != 1 || servers[0] != "target.example.com" {
+		t.Errorf("Expected only the target vCenter, got %v", servers)
+	}
+
+	creds, err := client.CoreV1().Secrets(openshift.CSIDriverNamespace).Get(ctx, openshift.CSIDriverCredentialsSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get credentials secret: %v", err)
+	}
+	if string(creds.Data["target.example.com.username"]) != "admin" || string(creds.Data["target.example.com.password"]) != "secret" {
+		t.Errorf("Expected target credentials, got %v", creds.Data)
+	}
+	if _, ok := creds.Data["source.example.com.username"]; ok {
+		t.Error("Expected the source credentials to be removed")
+	}
+
+	// The controller pod predates the update, so it still runs with the old config
+	restarted, reason, err := manager.ControllerRestartedSince(ctx, updateTime)
+	if err != nil {
+		t.Fatalf("ControllerRestartedSince failed: %v", err)
+	}
+	if restarted || !strings.Contains(reason, "predates") {
+		t.Errorf("Expected the old controller pod to be reported, got restarted=%v reason=%q", restarted, reason)
+	}
+
+	restarted, _, err = manager.ControllerRestartedSince(ctx, updateTime.Add(-2*time.Hour))
+	if err != nil {
+		t.Fatalf("ControllerRestartedSince failed: %v", err)
+	}
+	if !restarted {
+		t.Error("Expected a ready controller pod started after the update to count as restarted")
+	}
+}
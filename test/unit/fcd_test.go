@@ -0,0 +1,621 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/simulator"
+	_ "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vslm"
+	_ "github.com/vmware/govmomi/vslm/simulator"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+func TestVerifyFCDAttachedToVM(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm endpoint used by the FCD manager
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+
+	vm, err := client.GetVirtualMachine(ctx, "/DC0/vm/DC0_H0_VM0")
+	if err != nil {
+		t.Fatalf("Failed to get VM: %v", err)
+	}
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	// Create an FCD to attach
+	objMgr := vslm.NewObjectManager(client.VimClient())
+	task, err := objMgr.CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "attach-verify-test",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdObject := result.Result.(types.VStorageObject)
+	fcdID := fcdObject.Config.Id.Id
+	fcdPath := fcdObject.Config.Backing.(*types.BaseConfigInfoDiskFileBackingInfo).FilePath
+
+	t.Run("attach reported success but disk not present", func(t *testing.T) {
+		// vcsim completes AttachDisk tasks without adding the disk device to the VM,
+		// which is the silent non-persisting attach the read-back must catch
+		if err := vm.AttachDisk(ctx, fcdID, ds, 0, nil); err != nil {
+			t.Fatalf("Failed to attach FCD: %v", err)
+		}
+
+		err := fcdManager.VerifyFCDAttachedToVM(ctx, vm, fcdID, 0)
+		if err == nil {
+			t.Fatal("Expected read-back to fail for an FCD missing from the VM")
+		}
+		if !strings.Contains(err.Error(), "not present") {
+			t.Errorf("Expected 'not present' error, got: %v", err)
+		}
+	})
+
+	t.Run("unknown FCD ID", func(t *testing.T) {
+		err := fcdManager.VerifyFCDAttachedToVM(ctx, vm, "00000000-0000-0000-0000-000000000000", 0)
+		if err == nil {
+			t.Fatal("Expected read-back to fail for an unknown FCD ID")
+		}
+	})
+
+	t.Run("disk present on VM", func(t *testing.T) {
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get VM devices: %v", err)
+		}
+		controller, err := devices.FindDiskController("")
+		if err != nil {
+			t.Fatalf("Failed to find disk controller: %v", err)
+		}
+
+		disk := devices.CreateDisk(controller, ds.Reference(), fcdPath)
+		disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).BackingObjectId = fcdID
+		if err := vm.AddDevice(ctx, disk); err != nil {
+			t.Fatalf("Failed to add disk device: %v", err)
+		}
+
+		if err := fcdManager.VerifyFCDAttachedToVM(ctx, vm, fcdID, 5*time.Second); err != nil {
+			t.Errorf("Expected read-back to confirm attach, got: %v", err)
+		}
+	})
+}
+
+func TestCountFCDAttachments(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	objMgr := vslm.NewObjectManager(client.VimClient())
+	task, err := objMgr.CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "shared-disk-test",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdObject := result.Result.(types.VStorageObject)
+	fcdID := fcdObject.Config.Id.Id
+	fcdPath := fcdObject.Config.Backing.(*types.BaseConfigInfoDiskFileBackingInfo).FilePath
+
+	// addDisk attaches the FCD to a VM the way a multi-writer cluster shares a disk
+	addDisk := func(vmPath, sharing string) {
+		vm, err := client.GetVirtualMachine(ctx, vmPath)
+		if err != nil {
+			t.Fatalf("Failed to get VM: %v", err)
+		}
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get VM devices: %v", err)
+		}
+		controller, err := devices.FindDiskController("")
+		if err != nil {
+			t.Fatalf("Failed to find disk controller: %v", err)
+		}
+		disk := devices.CreateDisk(controller, ds.Reference(), fcdPath)
+		backing := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		backing.BackingObjectId = fcdID
+		backing.Sharing = sharing
+		if err := vm.AddDevice(ctx, disk); err != nil {
+			t.Fatalf("Failed to add disk device: %v", err)
+		}
+	}
+
+	attachments, err := fcdManager.CountFCDAttachments(ctx, "DC0", "/DC0/vm", fcdID)
+	if err != nil {
+		t.Fatalf("CountFCDAttachments failed: %v", err)
+	}
+	if len(attachments) != 0 {
+		t.Fatalf("Expected no attachments, got %v", attachments)
+	}
+
+	addDisk("/DC0/vm/DC0_H0_VM0", string(types.VirtualDiskSharingSharingNone))
+	attachments, err = fcdManager.CountFCDAttachments(ctx, "DC0", "/DC0/vm", fcdID)
+	if err != nil {
+		t.Fatalf("CountFCDAttachments failed: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0].VMName != "DC0_H0_VM0" || attachments[0].Shared() {
+		t.Fatalf("Expected a single unshared attachment to DC0_H0_VM0, got %+v", attachments)
+	}
+
+	addDisk("/DC0/vm/DC0_H0_VM1", string(types.VirtualDiskSharingSharingMultiWriter))
+	attachments, err = fcdManager.CountFCDAttachments(ctx, "DC0", "/DC0/vm", fcdID)
+	if err != nil {
+		t.Fatalf("CountFCDAttachments failed: %v", err)
+	}
+	if len(attachments) != 2 {
+		t.Fatalf("Expected the FCD to be attached to 2 VMs, got %+v", attachments)
+	}
+	if !slices.ContainsFunc(attachments, vsphere.FCDAttachment.Shared) {
+		t.Errorf("Expected the multi-writer attachment to be reported as shared, got %+v", attachments)
+	}
+}
+
+func TestFCDSnapshots(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm endpoint used by the FCD manager
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	objMgr := vslm.NewObjectManager(client.VimClient())
+	task, err := objMgr.CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "snapshot-test",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdID := result.Result.(types.VStorageObject).Config.Id.Id
+
+	snapshotID, err := fcdManager.CreateSnapshot(ctx, fcdID, "pre-migration")
+	if err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if snapshotID == "" {
+		t.Fatal("Expected a snapshot ID")
+	}
+
+	snapshots, err := fcdManager.ListSnapshots(ctx, fcdID)
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].ID != snapshotID {
+		t.Fatalf("Expected snapshot %s to be listed, got %+v", snapshotID, snapshots)
+	}
+
+	if err := fcdManager.DeleteSnapshot(ctx, fcdID, snapshotID); err != nil {
+		t.Fatalf("DeleteSnapshot failed: %v", err)
+	}
+
+	snapshots, err = fcdManager.ListSnapshots(ctx, fcdID)
+	if err != nil {
+		t.Fatalf("ListSnapshots failed: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Errorf("Expected no snapshots after delete, got %+v", snapshots)
+	}
+}
+
+func TestFCDBackingPath(t *testing.T) {
+	model := simulator.VPX()
+	model.Datastore = 2
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+
+	// The disk is on the second datastore, not the one a failure domain would name first
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_1")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	objMgr := vslm.NewObjectManager(client.VimClient())
+	task, err := objMgr.CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "backing-path-test",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdID := result.Result.(types.VStorageObject).Config.Id.Id
+
+	path, err := fcdManager.BackingPath(ctx, fcdID)
+	if err != nil {
+		t.Fatalf("BackingPath failed: %v", err)
+	}
+	datastore, _, err := vsphere.ParseDatastorePath(path)
+	if err != nil {
+		t.Fatalf("BackingPath returned an invalid datastore path %q: %v", path, err)
+	}
+	if datastore != "LocalDS_1" {
+		t.Errorf("Expected the FCD on LocalDS_1, got path %q", path)
+	}
+
+	// An FCD deleted out of band is told apart from a failed lookup
+	if _, err := fcdManager.BackingPath(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, vsphere.ErrFCDNotFound) {
+		t.Errorf("Expected ErrFCDNotFound for an unknown FCD, got %v", err)
+	}
+}
+
+func TestVolumeTags(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm and tagging endpoints
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	objMgr := vslm.NewObjectManager(client.VimClient())
+	task, err := objMgr.CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "tag-test",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdID := result.Result.(types.VStorageObject).Config.Id.Id
+
+	tags, err := fcdManager.ListVolumeTags(ctx, fcdID)
+	if err != nil {
+		t.Fatalf("ListVolumeTags failed: %v", err)
+	}
+	if len(tags) != 0 {
+		t.Fatalf("Expected a new FCD to have no tags, got %+v", tags)
+	}
+
+	// The category and tag do not exist yet and are created
+	tagID, err := client.EnsureTag(ctx, "cost-center", "cc-1234")
+	if err != nil {
+		t.Fatalf("EnsureTag failed: %v", err)
+	}
+	again, err := client.EnsureTag(ctx, "cost-center", "cc-1234")
+	if err != nil {
+		t.Fatalf("EnsureTag failed for an existing tag: %v", err)
+	}
+	if again != tagID {
+		t.Errorf("Expected the existing tag %s to be returned, got %s", tagID, again)
+	}
+
+	tag := vsphere.VolumeTag{Category: "cost-center", Name: "cc-1234"}
+	if err := fcdManager.AttachVolumeTag(ctx, fcdID, tag); err != nil {
+		t.Fatalf("AttachVolumeTag failed: %v", err)
+	}
+
+	tags, err = fcdManager.ListVolumeTags(ctx, fcdID)
+	if err != nil {
+		t.Fatalf("ListVolumeTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != tag {
+		t.Errorf("Expected the FCD to be tagged %+v, got %+v", tag, tags)
+	}
+}
+
+func TestFindTaggedResourcePool(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the tagging endpoints
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	tagID, err := client.EnsureTag(ctx, "ocp-placement", "workers")
+	if err != nil {
+		t.Fatalf("EnsureTag failed: %v", err)
+	}
+
+	// No compute resource has the tag yet
+	if _, err := client.FindTaggedResourcePool(ctx, "DC0", "ocp-placement", "workers"); err == nil {
+		t.Error("Expected an error when no cluster or resource pool has the tag")
+	}
+
+	cluster, err := client.GetCluster(ctx, "/DC0/host/DC0_C0")
+	if err != nil {
+		t.Fatalf("Failed to get cluster: %v", err)
+	}
+	if err := client.AttachTag(ctx, tagID, cluster); err != nil {
+		t.Fatalf("AttachTag failed: %v", err)
+	}
+
+	pool, err := client.FindTaggedResourcePool(ctx, "DC0", "ocp-placement", "workers")
+	if err != nil {
+		t.Fatalf("FindTaggedResourcePool failed: %v", err)
+	}
+	if pool != "/DC0/host/DC0_C0/Resources" {
+		t.Errorf("Expected the root resource pool of the tagged cluster, got %s", pool)
+	}
+
+	// A tagged compute resource in another datacenter is not a candidate
+	if _, err := client.FindTaggedResourcePool(ctx, "DC1", "ocp-placement", "workers"); err == nil {
+		t.Error("Expected an error when the tagged cluster is in another datacenter")
+	}
+
+	// Tagging a second placement makes the tag ambiguous
+	resourcePool, err := client.GetResourcePool(ctx, "/DC0/host/DC0_H0/Resources")
+	if err != nil {
+		t.Fatalf("Failed to get resource pool: %v", err)
+	}
+	if err := client.AttachTag(ctx, tagID, resourcePool); err != nil {
+		t.Fatalf("AttachTag failed: %v", err)
+	}
+	if _, err := client.FindTaggedResourcePool(ctx, "DC0", "ocp-placement", "workers"); err == nil || !strings.Contains(err.Error(), "expected one") {
+		t.Errorf("Expected an error for a tag matching several placements, got %v", err)
+	}
+}
+
+func TestParseCSIVolumeHandle(t *testing.T) {
+	const id = "6c9a2f4e-1b3d-4e5f-8a7b-9c0d1e2f3a4b"
+	tests := []struct {
+		name    string
+		handle  string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare UUID", handle: id, want: id},
+		{name: "uppercase UUID", handle: strings.ToUpper(id), want: strings.ToUpper(id)},
+		{name: "file URI", handle: "file://" + id, want: id},
+		{name: "built handle", handle: vsphere.BuildCSIVolumeHandle(id), want: id},
+		{name: "datastore URL qualified", handle: "ds:///vmfs/volumes/vsan:52a1b2c3d4e5f6a7-b8c9d0e1f2a3b4c5/" + id, want: id},
+		{name: "namespace qualified", handle: "vmware-system-csi:" + id, want: id},
+		{name: "surrounding whitespace", handle: " " + id + "\n", want: id},
+		{name: "empty", handle: "", wantErr: true},
+		{name: "vSAN file share", handle: "file:" + id, wantErr: true},
+		{name: "Supervisor PVC", handle: "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d-" + id, wantErr: true},
+		{name: "not a UUID", handle: "vol-12345", wantErr: true},
+		{name: "file URI without UUID", handle: "file://fcd-1", wantErr: true},
+		{name: "truncated UUID", handle: id[:35], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := vsphere.ParseCSIVolumeHandle(tt.handle)
+			if tt.wantErr {
+				if !errors.Is(err, vsphere.ErrUnrecognizedVolumeHandle) {
+					t.Fatalf("ParseCSIVolumeHandle(%q) error = %v, want ErrUnrecognizedVolumeHandle", tt.handle, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCSIVolumeHandle(%q) unexpected error: %v", tt.handle, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCSIVolumeHandle(%q) = %q, want %q", tt.handle, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/health"
+)
+
+func TestHealthChecker_Probes(t *testing.T) {
+	checker := health.NewChecker()
+	server := httptest.NewServer(checker.Handler())
+	defer server.Close()
+
+	probe := func(path string) int {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Failed to probe %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := probe("/healthz"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected healthz to fail before informers start, got %d", code)
+	}
+
+	checker.SetInformersStarted()
+	if code := probe("/healthz"); code != http.StatusOK {
+		t.Errorf("Expected healthz to pass once informers start, got %d", code)
+	}
+
+	// A standby instance with a synced cache is live but not ready
+	checker.SetCacheSynced(true)
+	if code := probe("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected readyz to fail without the leader lease, got %d", code)
+	}
+
+	checker.SetLeading(true)
+	if code := probe("/readyz"); code != http.StatusOK {
+		t.Errorf("Expected readyz to pass for the synced leader, got %d", code)
+	}
+
+	checker.SetLeading(false)
+	if code := probe("/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected readyz to fail after losing leadership, got %d", code)
+	}
+}
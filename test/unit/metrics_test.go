@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/metrics"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+func TestVCenterAPIMetrics(t *testing.T) {
+	registry, err := metrics.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	server := httptest.NewServer(metrics.Handler(registry))
+	defer server.Close()
+
+	ctx := context.Background()
+	soapLogger := vsphere.NewSOAPLogger("metrics.example.com")
+	soapLogger.LogSOAPCall(ctx, "RelocateVM_Task", nil, nil, 2*time.Second, nil)
+	soapLogger.LogSOAPCall(ctx, "RelocateVM_Task", nil, nil, time.Second, errors.New("fault"))
+
+	// Object IDs are dropped from REST operations so every tag attach is counted together
+	restLogger := vsphere.NewRESTLogger("metrics.example.com")
+	for _, id := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodPost,
+			"/rest/com/vmware/cis/tagging/tag-association/id:urn:vmomi:InventoryServiceTag:"+id+"?~action=attach", nil)
+		restLogger.ObserveCall(req, http.StatusInternalServerError, 100*time.Millisecond, nil)
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+
+	for _, want := range []string{
+		`vcf_migration_vcenter_api_call_duration_seconds_count{api="soap",operation="RelocateVM_Task",vcenter="metrics.example.com"} 2`,
+		`vcf_migration_vcenter_api_call_errors_total{api="soap",operation="RelocateVM_Task",vcenter="metrics.example.com"} 1`,
+		`vcf_migration_vcenter_api_call_duration_seconds_count{api="rest",operation="POST /rest/com/vmware/cis/tagging/tag-association/{id}?~action=attach",vcenter="metrics.example.com"} 2`,
+		`vcf_migration_vcenter_api_call_errors_total{api="rest",operation="POST /rest/com/vmware/cis/tagging/tag-association/{id}?~action=attach",vcenter="metrics.example.com"} 2`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+}
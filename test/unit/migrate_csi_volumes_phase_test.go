@@ -2,14 +2,29 @@ package unit
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vslm"
+	_ "github.com/vmware/govmomi/vslm/simulator"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
 
 	configv1 "github.com/openshift/api/config/v1"
 	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
@@ -18,6 +33,8 @@ import (
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 func TestMigrateCSIVolumesPhase_Name(t *testing.T) {
@@ -231,7 +248,14 @@ func TestMigrateCSIVolumesPhase_VolumeDiscovery(t *testing.T) {
 		},
 	}
 
-	kubeClient := kubefake.NewSimpleClientset(pv1)
+	// A Released PV whose claim was deleted
+	pv2 := pv1.DeepCopy()
+	pv2.Name = "pv-csi-2"
+	pv2.Spec.CSI.VolumeHandle = "fcd-67890"
+	pv2.Spec.ClaimRef.Name = "deleted-pvc"
+	pv2.Status.Phase = corev1.VolumeReleased
+
+	kubeClient := kubefake.NewSimpleClientset(pv1, pv2)
 	configClient := configfake.NewSimpleClientset(infra)
 	scheme := runtime.NewScheme()
 
@@ -265,6 +289,9 @@ func TestMigrateCSIVolumesPhase_VolumeDiscovery(t *testing.T) {
 					},
 				},
 			},
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{
+				MigrationStrategy: migrationv1alpha1.PVCStrategyInPlaceHandleSwap,
+			},
 		},
 	}
 
@@ -277,12 +304,12 @@ func TestMigrateCSIVolumesPhase_VolumeDiscovery(t *testing.T) {
 		t.Fatal("CSIVolumeMigration status should be initialized")
 	}
 
-	if migration.Status.CSIVolumeMigration.TotalVolumes != 1 {
-		t.Errorf("expected 1 total volume, got %d", migration.Status.CSIVolumeMigration.TotalVolumes)
+	if migration.Status.CSIVolumeMigration.TotalVolumes != 2 {
+		t.Errorf("expected 2 total volumes, got %d", migration.Status.CSIVolumeMigration.TotalVolumes)
 	}
 
-	if len(migration.Status.CSIVolumeMigration.Volumes) != 1 {
-		t.Fatalf("expected 1 volume state, got %d", len(migration.Status.CSIVolumeMigration.Volumes))
+	if len(migration.Status.CSIVolumeMigration.Volumes) != 2 {
+		t.Fatalf("expected 2 volume states, got %d", len(migration.Status.CSIVolumeMigration.Volumes))
 	}
 
 	volState := migration.Status.CSIVolumeMigration.Volumes[0]
@@ -297,6 +324,21 @@ func TestMigrateCSIVolumesPhase_VolumeDiscovery(t *testing.T) {
 	if volState.PVCNamespace != "default" {
 		t.Errorf("expected PVC namespace 'default', got '%s'", volState.PVCNamespace)
 	}
+	if !volState.InPlaceHandleSwap {
+		t.Error("expected the bound volume to keep its PVC with the InPlaceHandleSwap strategy")
+	}
+
+	// The stale claimRef of the Released PV is not followed, so it has no PVC to quiesce or delete
+	released := migration.Status.CSIVolumeMigration.Volumes[1]
+	if released.PVPhase != string(corev1.VolumeReleased) || !phases.UnboundVolume(&released) {
+		t.Errorf("expected pv-csi-2 to be recorded as an unbound Released volume, got phase %q", released.PVPhase)
+	}
+	if released.PVCName != "" || released.PVCNamespace != "" || released.WorkloadGroup != "" || released.InPlaceHandleSwap {
+		t.Errorf("expected no claim for the Released PV, got %s/%s group %q", released.PVCNamespace, released.PVCName, released.WorkloadGroup)
+	}
+	if phases.UnboundVolume(&volState) {
+		t.Error("expected the PV without a recorded phase to be treated as bound")
+	}
 }
 
 func TestCSIVolumeMigrationStatus_Initialization(t *testing.T) {
@@ -465,6 +507,7 @@ func TestPVMigrationStatusConstants(t *testing.T) {
 		phases.PVStatusRegistered,
 		phases.PVStatusComplete,
 		phases.PVStatusFailed,
+		phases.PVStatusSkipped,
 	}
 
 	for _, status := range statuses {
@@ -484,3 +527,1114 @@ func TestPVMigrationStatusConstants(t *testing.T) {
 		t.Errorf("expected PVStatusFailed to be 'Failed', got '%s'", phases.PVStatusFailed)
 	}
 }
+
+func TestEstimateRemainingVolumeTime(t *testing.T) {
+	status := &migrationv1alpha1.CSIVolumeMigrationStatus{
+		Volumes: []migrationv1alpha1.PVMigrationState{
+			{PVName: "pv-1", Status: phases.PVStatusComplete, Duration: &metav1.Duration{Duration: 2 * time.Minute}},
+			{PVName: "pv-2", Status: phases.PVStatusComplete, Duration: &metav1.Duration{Duration: 4 * time.Minute}},
+			// Failed volumes neither count towards the average nor the remaining work
+			{PVName: "pv-3", Status: phases.PVStatusFailed, Duration: &metav1.Duration{Duration: time.Second}},
+			{PVName: "pv-4", Status: phases.PVStatusRelocated},
+			{PVName: "pv-5", Status: phases.PVStatusPending},
+		},
+	}
+
+	eta, average, ok := phases.EstimateRemainingVolumeTime(status)
+	if !ok {
+		t.Fatal("Expected an estimate once volumes have completed")
+	}
+	if average != 3*time.Minute {
+		t.Errorf("Expected average of 3m, got %s", average)
+	}
+	if eta != 6*time.Minute {
+		t.Errorf("Expected ETA of 6m, got %s", eta)
+	}
+
+	// No estimate until at least one volume has completed
+	status.Volumes[0].Status = phases.PVStatusPending
+	status.Volumes[1].Status = phases.PVStatusPending
+	if _, _, ok := phases.EstimateRemainingVolumeTime(status); ok {
+		t.Error("Expected no estimate without completed volumes")
+	}
+}
+
+func TestRelocateBatchSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *migrationv1alpha1.CSIVolumeMigrationConfig
+		expected int
+	}{
+		{name: "unset relocates one volume at a time", expected: 1},
+		{name: "zero relocates one volume at a time", config: &migrationv1alpha1.CSIVolumeMigrationConfig{}, expected: 1},
+		{name: "configured batch size", config: &migrationv1alpha1.CSIVolumeMigrationConfig{BatchSize: 8}, expected: 8},
+		{name: "capped at dummy VM disk capacity", config: &migrationv1alpha1.CSIVolumeMigrationConfig{BatchSize: 500}, expected: 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+				Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{CSIVolumeMigration: tt.config},
+			}
+			if got := phases.RelocateBatchSize(migration); got != tt.expected {
+				t.Errorf("Expected batch size %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNextRelocateBatch(t *testing.T) {
+	status := &migrationv1alpha1.CSIVolumeMigrationStatus{
+		Volumes: []migrationv1alpha1.PVMigrationState{
+			{PVName: "pv-1", Status: phases.PVStatusPVCDeleted},
+			{PVName: "pv-2", Status: phases.PVStatusQuiesced},
+			{PVName: "pv-3", Status: phases.PVStatusPVCDeleted},
+			{PVName: "pv-4", Status: phases.PVStatusFailed},
+			{PVName: "pv-5", Status: phases.PVStatusPVCDeleted},
+		},
+	}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+				{Name: "fd-1", Topology: configv1.VSpherePlatformTopology{Datastore: "/DC1/datastore/ds1"}},
+			},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{CSIVolumeMigration: status},
+	}
+
+	batch := phases.NextRelocateBatch(migration, 2)
+	if len(batch) != 2 || batch[0].PVName != "pv-1" || batch[1].PVName != "pv-3" {
+		t.Fatalf("Expected batch [pv-1 pv-3], got %v", batch)
+	}
+
+	// The batch refers to the status entries so per-volume progress is recorded in place
+	batch[0].Status = phases.PVStatusRelocated
+	if status.Volumes[0].Status != phases.PVStatusRelocated {
+		t.Error("Expected batch entries to point into the migration status")
+	}
+
+	batch = phases.NextRelocateBatch(migration, 10)
+	if len(batch) != 2 || batch[0].PVName != "pv-3" || batch[1].PVName != "pv-5" {
+		t.Errorf("Expected batch [pv-3 pv-5], got %v", batch)
+	}
+
+	// One vMotion moves all disks of a batch to one datastore, so a volume with another target
+	// datastore waits for a batch of its own
+	migration.Spec.CSIVolumeMigration = &migrationv1alpha1.CSIVolumeMigrationConfig{
+		VolumeDatastoreOverrides: map[string]string{"pv-3": "/DC1/datastore/fast"},
+	}
+	batch = phases.NextRelocateBatch(migration, 10)
+	if len(batch) != 1 || batch[0].PVName != "pv-3" {
+		t.Errorf("Expected batch [pv-3], got %v", batch)
+	}
+	batch[0].Status = phases.PVStatusRelocated
+	batch = phases.NextRelocateBatch(migration, 10)
+	if len(batch) != 1 || batch[0].PVName != "pv-5" {
+		t.Errorf("Expected batch [pv-5], got %v", batch)
+	}
+}
+
+func TestVolumeTargetDatastore(t *testing.T) {
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+				{Name: "fd-1", Topology: configv1.VSpherePlatformTopology{Datastore: "/DC1/datastore/ds1"}},
+			},
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{
+				VolumeDatastoreOverrides: map[string]string{
+					"pv-fast":  "/DC1/datastore/fast",
+					"app/data": "/DC1/datastore/large",
+					"app/logs": "/DC1/datastore/large",
+					"pv-logs":  "/DC1/datastore/fast",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pvState migrationv1alpha1.PVMigrationState
+		want    string
+	}{
+		{name: "no override", pvState: migrationv1alpha1.PVMigrationState{PVName: "pv-other", PVCName: "other", PVCNamespace: "app"}, want: "/DC1/datastore/ds1"},
+		{name: "PV override", pvState: migrationv1alpha1.PVMigrationState{PVName: "pv-fast"}, want: "/DC1/datastore/fast"},
+		{name: "PVC override", pvState: migrationv1alpha1.PVMigrationState{PVName: "pv-data", PVCName: "data", PVCNamespace: "app"}, want: "/DC1/datastore/large"},
+		{name: "PV override wins over PVC", pvState: migrationv1alpha1.PVMigrationState{PVName: "pv-logs", PVCName: "logs", PVCNamespace: "app"}, want: "/DC1/datastore/fast"},
+		{name: "PVC in another namespace", pvState: migrationv1alpha1.PVMigrationState{PVName: "pv-data2", PVCName: "data", PVCNamespace: "other"}, want: "/DC1/datastore/ds1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := phases.VolumeTargetDatastore(migration, &tt.pvState); got != tt.want {
+				t.Errorf("expected datastore %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// A datastore cluster replaces the failure domain's datastore, but not the overrides
+	migration.Spec.CSIVolumeMigration.TargetDatastoreCluster = "/DC1/datastore/pod1"
+	other := &migrationv1alpha1.PVMigrationState{PVName: "pv-other"}
+	if got := phases.VolumeTargetDatastore(migration, other); got != "/DC1/datastore/pod1" || !phases.TargetsDatastoreCluster(migration, other) {
+		t.Errorf("expected volume without override relocated to the datastore cluster, got %s", got)
+	}
+	fast := &migrationv1alpha1.PVMigrationState{PVName: "pv-fast"}
+	if got := phases.VolumeTargetDatastore(migration, fast); got != "/DC1/datastore/fast" || phases.TargetsDatastoreCluster(migration, fast) {
+		t.Errorf("expected overridden volume relocated to its override, got %s", got)
+	}
+}
+
+func TestFailVolume(t *testing.T) {
+	status := &migrationv1alpha1.CSIVolumeMigrationStatus{
+		Volumes: []migrationv1alpha1.PVMigrationState{
+			{
+				PVName:       "pv-1",
+				PVCName:      "data",
+				PVCNamespace: "app",
+				Status:       phases.PVStatusRelocating,
+				DummyVMName:  "csi-migration-pv-1",
+				ScaledDownResources: []migrationv1alpha1.ScaledResource{
+					{Kind: "Deployment", Namespace: "app", Name: "web", OriginalReplicas: 2},
+				},
+			},
+		},
+	}
+	pvState := &status.Volumes[0]
+
+	phases.FailVolume(status, pvState, "relocation failed")
+
+	if pvState.Status != phases.PVStatusFailed {
+		t.Errorf("Expected volume status %s, got %s", phases.PVStatusFailed, pvState.Status)
+	}
+	if status.FailedVolumes != 1 {
+		t.Errorf("Expected 1 failed volume, got %d", status.FailedVolumes)
+	}
+	if len(status.ManualInterventionRequired) != 1 {
+		t.Fatalf("Expected 1 intervention, got %d", len(status.ManualInterventionRequired))
+	}
+
+	intervention := status.ManualInterventionRequired[0]
+	if intervention.FailedStep != phases.PVStatusRelocating {
+		t.Errorf("Expected failed step %s, got %s", phases.PVStatusRelocating, intervention.FailedStep)
+	}
+	if intervention.PVCNamespace != "app" || intervention.PVCName != "data" || intervention.Error != "relocation failed" {
+		t.Errorf("Unexpected intervention %+v", intervention)
+	}
+	if len(intervention.ScaledDownResources) != 1 {
+		t.Errorf("Expected the scaled-down workloads to be listed, got %v", intervention.ScaledDownResources)
+	}
+	if !strings.Contains(intervention.Hint, "csi-migration-pv-1") {
+		t.Errorf("Expected the hint to name the dummy VM, got %q", intervention.Hint)
+	}
+
+	// Failing the same volume again replaces its entry
+	pvState.Status = phases.PVStatusPVUpdated
+	phases.FailVolume(status, pvState, "restore failed")
+	if len(status.ManualInterventionRequired) != 1 {
+		t.Fatalf("Expected the intervention to be replaced, got %d entries", len(status.ManualInterventionRequired))
+	}
+	if status.ManualInterventionRequired[0].FailedStep != phases.PVStatusPVUpdated {
+		t.Errorf("Expected failed step %s, got %s", phases.PVStatusPVUpdated, status.ManualInterventionRequired[0].FailedStep)
+	}
+}
+
+func TestWorkloadGroupVolumes(t *testing.T) {
+	const group = "StatefulSet/db/postgres"
+	newStatus := func(statuses ...string) *migrationv1alpha1.CSIVolumeMigrationStatus {
+		status := &migrationv1alpha1.CSIVolumeMigrationStatus{
+			Volumes: []migrationv1alpha1.PVMigrationState{
+				{PVName: "pv-other", WorkloadGroup: "StatefulSet/db/other", Status: phases.PVStatusRelocating},
+			},
+		}
+		for i, s := range statuses {
+			status.Volumes = append(status.Volumes, migrationv1alpha1.PVMigrationState{
+				PVName:        fmt.Sprintf("pv-%d", i),
+				PVCName:       fmt.Sprintf("data-postgres-%d", i),
+				PVCNamespace:  "db",
+				WorkloadGroup: group,
+				Status:        s,
+				Message:       "relocation failed",
+			})
+		}
+		return status
+	}
+
+	t.Run("waits while a replica volume is migrating", func(t *testing.T) {
+		settled, _, _ := phases.WorkloadGroupVolumes(newStatus(phases.PVStatusPVUpdated, phases.PVStatusRelocating), group)
+		if settled {
+			t.Error("Expected the group not to be settled")
+		}
+	})
+
+	t.Run("all replica volumes migrated", func(t *testing.T) {
+		settled, migrated, unmigrated := phases.WorkloadGroupVolumes(newStatus(phases.PVStatusPVUpdated, phases.PVStatusPVUpdated), group)
+		if !settled || len(migrated) != 2 || len(unmigrated) != 0 {
+			t.Errorf("Expected 2 migrated volumes, got settled=%v migrated=%d unmigrated=%v", settled, len(migrated), unmigrated)
+		}
+	})
+
+	t.Run("failed and skipped replica volumes are reported", func(t *testing.T) {
+		settled, migrated, unmigrated := phases.WorkloadGroupVolumes(
+			newStatus(phases.PVStatusPVUpdated, phases.PVStatusFailed, phases.PVStatusSkipped), group)
+		if !settled || len(migrated) != 1 {
+			t.Fatalf("Expected 1 migrated volume, got settled=%v migrated=%d", settled, len(migrated))
+		}
+		if len(unmigrated) != 2 {
+			t.Fatalf("Expected 2 unmigrated volumes, got %v", unmigrated)
+		}
+		if !strings.Contains(unmigrated[0], "PV pv-1 (PVC db/data-postgres-1) failed") {
+			t.Errorf("Unexpected description %q", unmigrated[0])
+		}
+		if !strings.Contains(unmigrated[1], "pv-2") {
+			t.Errorf("Unexpected description %q", unmigrated[1])
+		}
+	})
+}
+
+func TestWorkloadGroups(t *testing.T) {
+	groups := phases.WorkloadGroups(map[string][]string{
+		// A Deployment mounting two volumes
+		"pv-web-data":  {"Deployment/app/web"},
+		"pv-web-cache": {"Deployment/app/web"},
+		// Replica claims of a StatefulSet whose pods also mount a PVC shared with a Deployment
+		"pv-db-0":     {"StatefulSet/db/postgres"},
+		"pv-db-1":     {"StatefulSet/db/postgres"},
+		"pv-db-share": {"Deployment/db/backup", "StatefulSet/db/postgres"},
+		"pv-backups":  {"Deployment/db/backup"},
+		// Volumes used alone
+		"pv-solo":   {"Deployment/app/solo"},
+		"pv-unused": nil,
+	})
+
+	want := map[string]string{
+		"pv-web-data":  "Deployment/app/web",
+		"pv-web-cache": "Deployment/app/web",
+		"pv-db-0":      "StatefulSet/db/postgres",
+		"pv-db-1":      "StatefulSet/db/postgres",
+		"pv-db-share":  "StatefulSet/db/postgres",
+		"pv-backups":   "StatefulSet/db/postgres",
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Expected groups %v, got %v", want, groups)
+	}
+}
+
+func TestRestoreReclaimPolicy(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := kubefake.NewSimpleClientset(&corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+		},
+	})
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+
+	reclaimPolicy := func() corev1.PersistentVolumeReclaimPolicy {
+		pv, err := kubeClient.CoreV1().PersistentVolumes().Get(ctx, "pv-1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get PV: %v", err)
+		}
+		return pv.Spec.PersistentVolumeReclaimPolicy
+	}
+
+	// Without a recorded policy the PV is left on Retain
+	if err := phases.RestoreReclaimPolicy(ctx, pvManager, &migrationv1alpha1.PVMigrationState{PVName: "pv-1"}); err != nil {
+		t.Fatalf("RestoreReclaimPolicy failed: %v", err)
+	}
+	if policy := reclaimPolicy(); policy != corev1.PersistentVolumeReclaimRetain {
+		t.Errorf("Expected reclaim policy Retain to be kept, got %s", policy)
+	}
+
+	pvState := &migrationv1alpha1.PVMigrationState{
+		PVName:                "pv-1",
+		OriginalReclaimPolicy: string(corev1.PersistentVolumeReclaimDelete),
+	}
+	if err := phases.RestoreReclaimPolicy(ctx, pvManager, pvState); err != nil {
+		t.Fatalf("RestoreReclaimPolicy failed: %v", err)
+	}
+	if policy := reclaimPolicy(); policy != corev1.PersistentVolumeReclaimDelete {
+		t.Errorf("Expected reclaim policy Delete to be restored, got %s", policy)
+	}
+
+	// A missing PV is reported
+	pvState.PVName = "pv-missing"
+	if err := phases.RestoreReclaimPolicy(ctx, pvManager, pvState); err == nil {
+		t.Error("Expected an error for a missing PV")
+	}
+}
+
+func TestSharedVolumeReason(t *testing.T) {
+	block := corev1.PersistentVolumeBlock
+	filesystem := corev1.PersistentVolumeFilesystem
+	newPV := func(mode *corev1.PersistentVolumeMode, accessModes ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-shared"},
+			Spec:       corev1.PersistentVolumeSpec{VolumeMode: mode, AccessModes: accessModes},
+		}
+	}
+	single := []vsphere.FCDAttachment{{VMName: "worker-0"}}
+
+	tests := []struct {
+		name        string
+		pv          *corev1.PersistentVolume
+		attachments []vsphere.FCDAttachment
+		shared      string
+	}{
+		{name: "detached volume", pv: newPV(&filesystem, corev1.ReadWriteOnce)},
+		{name: "single attachment", pv: newPV(&filesystem, corev1.ReadWriteOnce), attachments: single},
+		{name: "RWX filesystem volume", pv: newPV(&filesystem, corev1.ReadWriteMany), attachments: single},
+		{name: "RWX block volume", pv: newPV(&block, corev1.ReadWriteMany), attachments: single, shared: "ReadWriteMany block"},
+		{
+			name:        "attached to several VMs",
+			pv:          newPV(nil, corev1.ReadWriteOnce),
+			attachments: []vsphere.FCDAttachment{{VMName: "worker-0"}, {VMName: "worker-1"}},
+			shared:      "attached to 2 VMs (worker-0, worker-1)",
+		},
+		{
+			name:        "multi-writer disk",
+			pv:          newPV(nil, corev1.ReadWriteOnce),
+			attachments: []vsphere.FCDAttachment{{VMName: "worker-0", Sharing: string(types.VirtualDiskSharingSharingMultiWriter)}},
+			shared:      "shared disk",
+		},
+		{
+			name:        "disk on a shared SCSI bus",
+			pv:          newPV(nil, corev1.ReadWriteOnce),
+			attachments: []vsphere.FCDAttachment{{VMName: "worker-0", SharedBus: string(types.VirtualSCSISharingPhysicalSharing)}},
+			shared:      "shared disk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := phases.SharedVolumeReason(tt.pv, tt.attachments)
+			if tt.shared == "" {
+				if reason != "" {
+					t.Errorf("Expected volume not to be shared, got %q", reason)
+				}
+				return
+			}
+			if !strings.Contains(reason, tt.shared) {
+				t.Errorf("Expected reason to mention %q, got %q", tt.shared, reason)
+			}
+		})
+	}
+}
+
+func TestMigrateCSIVolumesPhase_RollbackSkipsDataSafetyFailures(t *testing.T) {
+	ctx := context.Background()
+
+	replicas := int32(0)
+	deployment := func(name string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		}
+	}
+	pv := func(name string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.PersistentVolumeSpec{PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(deployment("safe"), deployment("unsafe"), pv("pv-safe"), pv("pv-unsafe"))
+
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubeClient,
+		configfake.NewSimpleClientset(),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+
+	status := &migrationv1alpha1.CSIVolumeMigrationStatus{
+		Volumes: []migrationv1alpha1.PVMigrationState{
+			{
+				PVName:                "pv-safe",
+				Status:                phases.PVStatusRetainSet,
+				OriginalReclaimPolicy: string(corev1.PersistentVolumeReclaimDelete),
+				ScaledDownResources:   []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Namespace: "app", Name: "safe", OriginalReplicas: 2}},
+			},
+			{
+				PVName:                "pv-unsafe",
+				Status:                phases.PVStatusRelocating,
+				OriginalReclaimPolicy: string(corev1.PersistentVolumeReclaimDelete),
+				ScaledDownResources:   []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Namespace: "app", Name: "unsafe", OriginalReplicas: 2}},
+			},
+		},
+	}
+	phases.FailVolume(status, &status.Volumes[0], "quiesce failed")
+	phases.FailVolumeError(status, &status.Volumes[1], "Failed to relocate volume", phaseerrors.DataSafety(errors.New("task lost")))
+	if status.Volumes[1].ErrorClass != phaseerrors.ClassDataSafety {
+		t.Fatalf("Expected error class %s, got %q", phaseerrors.ClassDataSafety, status.Volumes[1].ErrorClass)
+	}
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{CSIVolumeMigration: status},
+	}
+	if err := phases.NewMigrateCSIVolumesPhase(executor).Rollback(ctx, migration); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	for name, expected := range map[string]int32{"safe": 2, "unsafe": 0} {
+		d, err := kubeClient.AppsV1().Deployments("app").Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get deployment %s: %v", name, err)
+		}
+		if *d.Spec.Replicas != expected {
+			t.Errorf("Expected deployment %s to have %d replicas, got %d", name, expected, *d.Spec.Replicas)
+		}
+	}
+	for name, expected := range map[string]corev1.PersistentVolumeReclaimPolicy{"pv-safe": corev1.PersistentVolumeReclaimDelete, "pv-unsafe": corev1.PersistentVolumeReclaimRetain} {
+		p, err := kubeClient.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get PV %s: %v", name, err)
+		}
+		if p.Spec.PersistentVolumeReclaimPolicy != expected {
+			t.Errorf("Expected PV %s reclaim policy %s, got %s", name, expected, p.Spec.PersistentVolumeReclaimPolicy)
+		}
+	}
+}
+
+// volumeChecksums is an integrity checker returning a fixed checksum per FCD ID
+type volumeChecksums map[string]string
+
+func (c volumeChecksums) Checksum(ctx context.Context, volume phases.IntegrityVolume) (string, error) {
+	return c[volume.VolumeID], nil
+}
+
+func TestVolumeIntegrity(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+	phase := phases.NewMigrateCSIVolumesPhase(executor)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{VerifyIntegrity: true},
+		},
+	}
+	newVolume := func(targetID string) *migrationv1alpha1.PVMigrationState {
+		return &migrationv1alpha1.PVMigrationState{PVName: "pv-data", SourceVolumeID: "source-fcd", TargetVolumeID: targetID}
+	}
+
+	// The default checker computes nothing, so volumes migrate unchecked
+	pvState := newVolume("target-same")
+	if err := phase.RecordSourceChecksum(ctx, nil, migration, pvState); err != nil {
+		t.Fatalf("RecordSourceChecksum failed: %v", err)
+	}
+	if err := phase.VerifyTargetChecksum(ctx, nil, migration, pvState); err != nil || pvState.SourceChecksum != "" || pvState.TargetChecksum != "" {
+		t.Fatalf("Expected no checksums without a checker, got %q/%q, err %v", pvState.SourceChecksum, pvState.TargetChecksum, err)
+	}
+
+	executor.SetIntegrityChecker(volumeChecksums{"source-fcd": "abc", "target-same": "abc", "target-changed": "def"})
+
+	pvState = newVolume("target-same")
+	if err := phase.RecordSourceChecksum(ctx, nil, migration, pvState); err != nil {
+		t.Fatalf("RecordSourceChecksum failed: %v", err)
+	}
+	if pvState.SourceChecksum != "abc" {
+		t.Fatalf("Expected source checksum abc, got %q", pvState.SourceChecksum)
+	}
+	if err := phase.VerifyTargetChecksum(ctx, nil, migration, pvState); err != nil {
+		t.Errorf("Expected matching checksums to pass, got %v", err)
+	}
+
+	pvState = newVolume("target-changed")
+	if err := phase.RecordSourceChecksum(ctx, nil, migration, pvState); err != nil {
+		t.Fatalf("RecordSourceChecksum failed: %v", err)
+	}
+	err := phase.VerifyTargetChecksum(ctx, nil, migration, pvState)
+	if err == nil || !strings.Contains(err.Error(), "checksum changed") {
+		t.Fatalf("Expected a checksum mismatch, got %v", err)
+	}
+	if pvState.TargetChecksum != "def" {
+		t.Errorf("Expected the target checksum to be recorded, got %q", pvState.TargetChecksum)
+	}
+
+	// A target the checker cannot fingerprint fails a volume that has a source checksum
+	pvState = newVolume("target-unknown")
+	pvState.SourceChecksum = "abc"
+	if err := phase.VerifyTargetChecksum(ctx, nil, migration, pvState); err == nil {
+		t.Error("Expected an error when the target checksum cannot be computed")
+	}
+
+	// Nothing is checked unless the migration asks for it
+	migration.Spec.CSIVolumeMigration.VerifyIntegrity = false
+	pvState = newVolume("target-changed")
+	pvState.SourceChecksum = "abc"
+	if err := phase.VerifyTargetChecksum(ctx, nil, migration, pvState); err != nil {
+		t.Errorf("Expected no check without verifyIntegrity, got %v", err)
+	}
+}
+
+func TestRetainedSourceVolumes(t *testing.T) {
+	now := time.Now()
+	past := metav1.NewTime(now.Add(-time.Hour))
+	future := metav1.NewTime(now.Add(time.Hour))
+	status := &migrationv1alpha1.CSIVolumeMigrationStatus{
+		Volumes: []migrationv1alpha1.PVMigrationState{
+			{PVName: "pv-expired", Status: phases.PVStatusComplete, SourceRetainedUntil: &past},
+			{PVName: "pv-retained", Status: phases.PVStatusComplete, SourceRetainedUntil: &future},
+			{PVName: "pv-moved", Status: phases.PVStatusComplete},
+			// The source of a volume that did not complete is its only good copy
+			{PVName: "pv-failed", Status: phases.PVStatusFailed, SourceRetainedUntil: &past},
+		},
+	}
+
+	expired, retained := phases.RetainedSourceVolumes(status, now)
+	if len(expired) != 1 || expired[0].PVName != "pv-expired" {
+		t.Errorf("Expected only pv-expired to have expired, got %v", expired)
+	}
+	if len(retained) != 1 || retained[0].PVName != "pv-retained" {
+		t.Errorf("Expected only pv-retained to be retained, got %v", retained)
+	}
+
+	// The returned states point into the status so the caller can clear the retention
+	expired[0].SourceRetainedUntil = nil
+	if status.Volumes[0].SourceRetainedUntil != nil {
+		t.Error("Expected the expired volume to be updated in place")
+	}
+
+	if expired, retained := phases.RetainedSourceVolumes(nil, now); expired != nil || retained != nil {
+		t.Error("Expected no volumes without a CSI migration status")
+	}
+}
+
+func TestPreviewCSIVolumes(t *testing.T) {
+	ctx := context.Background()
+	pv := func(name, handle, namespace string, mutate func(*corev1.PersistentVolume)) *corev1.PersistentVolume {
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: openshift.VSphereCSIDriver, VolumeHandle: handle},
+				},
+				ClaimRef: &corev1.ObjectReference{Name: "data", Namespace: namespace},
+			},
+		}
+		if mutate != nil {
+			mutate(pv)
+		}
+		return pv
+	}
+	pvc := func(namespace string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: namespace}}
+	}
+	replicas := int32(2)
+	deployment := func(namespace string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: namespace},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes: []corev1.Volume{{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+							},
+						}},
+					},
+				},
+			},
+		}
+	}
+	block := corev1.PersistentVolumeBlock
+
+	// A second volume mounted by the pods of the web Deployment
+	web := deployment("web")
+	web.Spec.Template.Spec.Volumes = append(web.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "cache",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache"},
+		},
+	})
+	webCache := pv("pv-web-cache", "fcd-5", "web", func(pv *corev1.PersistentVolume) { pv.Spec.ClaimRef.Name = "cache" })
+
+	kubeClient := kubefake.NewSimpleClientset(
+		pv("pv-app", "fcd-1", "app", nil), pvc("app"), deployment("app"),
+		// Released by an earlier claim of the same name, which the current one must not be confused with
+		pv("pv-released", "fcd-6", "app", func(pv *corev1.PersistentVolume) { pv.Status.Phase = corev1.VolumeReleased }),
+		pv("pv-web-data", "fcd-4", "web", nil), pvc("web"), web, webCache,
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "web"}},
+		pv("pv-protected", "fcd-2", "protected", nil), pvc("protected"), deployment("protected"),
+		pv("pv-file", "file:5f3c0d2e-file-share", "files", nil),
+		pv("pv-shared", "fcd-3", "shared", func(pv *corev1.PersistentVolume) {
+			pv.Spec.VolumeMode = &block
+			pv.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+		}),
+	)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{
+				QuiesceExcludeNamespaces: []string{"protected"},
+			},
+		},
+	}
+
+	previews, err := phases.PreviewCSIVolumes(ctx, kubeClient, migration)
+	if err != nil {
+		t.Fatalf("PreviewCSIVolumes failed: %v", err)
+	}
+	byName := make(map[string]phases.CSIVolumePreview)
+	for _, preview := range previews {
+		byName[preview.PVName] = preview
+	}
+	if len(byName) != 7 {
+		t.Fatalf("Expected 7 previewed volumes, got %+v", previews)
+	}
+
+	if app := byName["pv-app"]; app.SkipReason != "" || len(app.Workloads) != 1 || app.Workloads[0] != "Deployment/db" {
+		t.Errorf("Expected pv-app to be migrated with Deployment/db scaled down, got %+v", app)
+	}
+	if app := byName["pv-app"]; app.WorkloadGroup != "" {
+		t.Errorf("Expected pv-app to be migrated on its own, got group %q", app.WorkloadGroup)
+	}
+	if released := byName["pv-released"]; released.PVCName != "" || len(released.Workloads) != 0 || released.SkipReason != "" {
+		t.Errorf("Expected the Released pv-released to be migrated without a claim or workloads, got %+v", released)
+	}
+	for _, name := range []string{"pv-web-data", "pv-web-cache"} {
+		if group := byName[name].WorkloadGroup; group != "Deployment/web/db" {
+			t.Errorf("Expected %s to be migrated with the other volume of Deployment/web/db, got group %q", name, group)
+		}
+	}
+	for name, want := range map[string]string{
+		"pv-protected": "excluded from quiesce",
+		"pv-file":      "file share",
+		"pv-shared":    "ReadWriteMany block",
+	} {
+		if reason := byName[name].SkipReason; !strings.Contains(reason, want) {
+			t.Errorf("Expected %s to be skipped with %q, got %q", name, want, reason)
+		}
+	}
+
+	// The preview must not scale anything down
+	deploy, err := kubeClient.AppsV1().Deployments("app").Get(ctx, "db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deploy.Spec.Replicas != replicas {
+		t.Errorf("Expected the deployment to keep %d replicas, got %d", replicas, *deploy.Spec.Replicas)
+	}
+}
+
+func TestMigrateCSIVolumesPhase_ResumeFromTarget(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	username := simulator.DefaultLogin.Username()
+	password, _ := simulator.DefaultLogin.Password()
+	targetClient, err := vsphere.NewClient(ctx,
+		vsphere.Config{Server: server.URL.String(), Insecure: true},
+		vsphere.Credentials{Username: username, Password: password})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer targetClient.Logout(ctx)
+
+	// The FCD the lost vMotion moved is on the target
+	ds, err := targetClient.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	task, err := vslm.NewObjectManager(targetClient.VimClient()).CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "moved-volume",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{Datastore: ds.Reference()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	movedID := result.Result.(types.VStorageObject).Config.Id.Id
+	const goneID = "3c1b6a2e-7f4d-4b8e-9a21-5d6e7f8a9b0c"
+
+	scaledDown := []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Name: "app", Namespace: "app", OriginalReplicas: 1}}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{PVName: "pv-moved", SourceVolumePath: vsphere.BuildCSIVolumeHandle(movedID), Status: phases.PVStatusPVCDeleted, ScaledDownResources: scaledDown},
+					{PVName: "pv-gone", SourceVolumePath: vsphere.BuildCSIVolumeHandle(goneID), Status: phases.PVStatusPVCDeleted, ScaledDownResources: scaledDown},
+				},
+			},
+		},
+	}
+	status := migration.Status.CSIVolumeMigration
+	phase := phases.NewMigrateCSIVolumesPhase(phases.NewPhaseExecutor(kubefake.NewSimpleClientset(), nil, nil, nil, nil, nil, nil))
+	notFound := fmt.Errorf("failed to get FCD info: %w", vsphere.ErrFCDNotFound)
+
+	moved := &status.Volumes[0]
+	phase.ResumeFromTarget(ctx, targetClient, migration, moved, notFound)
+	if moved.Status != phases.PVStatusRelocated || moved.TargetVolumeID != movedID {
+		t.Errorf("Expected the volume on the target to resume at %s with target %s, got %s with %q (%s)",
+			phases.PVStatusRelocated, movedID, moved.Status, moved.TargetVolumeID, moved.Message)
+	}
+
+	gone := &status.Volumes[1]
+	phase.ResumeFromTarget(ctx, targetClient, migration, gone, notFound)
+	if gone.Status != phases.PVStatusFailed {
+		t.Errorf("Expected the volume on neither vCenter to fail, got %s", gone.Status)
+	}
+	if len(gone.ScaledDownResources) != 1 {
+		t.Errorf("Expected the workloads of the failed volume to stay scaled down, got %v", gone.ScaledDownResources)
+	}
+	if len(status.MissingSourceVolumes) != 0 {
+		t.Errorf("Expected no volume to be reported missing after its workloads were scaled down, got %v", status.MissingSourceVolumes)
+	}
+}
+
+func TestMigrateCSIVolumesPhase_RetainDummyVMOnFailure(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm endpoint used by the FCD manager, and serve TLS since the cleanup
+	// connects by host name as it does to a real vCenter
+	model.Service.RegisterEndpoints = true
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	username := simulator.DefaultLogin.Username()
+	password, _ := simulator.DefaultLogin.Password()
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{Server: server.URL.String(), Insecure: true},
+		vsphere.Credentials{Username: username, Password: password})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	task, err := vslm.NewObjectManager(client.VimClient()).CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "customer-volume",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdID := result.Result.(types.VStorageObject).Config.Id.Id
+
+	// The source and target failure domains are on the one simulated vCenter
+	host := server.URL.Host
+	topology := configv1.VSpherePlatformTopology{
+		Datacenter:     "DC0",
+		ComputeCluster: "/DC0/host/DC0_C0",
+		Datastore:      "/DC0/datastore/LocalDS_0",
+		Folder:         "/DC0/vm",
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			PlatformSpec: configv1.PlatformSpec{
+				Type: configv1.VSpherePlatformType,
+				VSphere: &configv1.VSpherePlatformSpec{
+					VCenters:       []configv1.VSpherePlatformVCenterSpec{{Server: host, Datacenters: []string{"DC0"}}},
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{Name: "source-fd", Server: host, Topology: topology}},
+				},
+			},
+		},
+		Status: configv1.InfrastructureStatus{InfrastructureName: "test-cluster"},
+	}
+	creds := map[string][]byte{host + ".username": []byte(username), host + ".password": []byte(password)}
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: openshift.VSphereCredsSecretName, Namespace: openshift.VSphereCredsSecretNamespace}, Data: creds},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "target-creds", Namespace: "vmware-cloud-foundation-migration"}, Data: creds})
+
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubeClient,
+		configfake.NewSimpleClientset(infra),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+	phase := phases.NewMigrateCSIVolumesPhase(executor)
+
+	// Reserving every SCSI unit fails the batch once the attach of the volume is attempted
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains:                 []configv1.VSpherePlatformFailureDomainSpec{{Name: "target-fd", Server: host, Topology: topology}},
+			TargetVCenterCredentialsSecret: migrationv1alpha1.SecretReference{Name: "target-creds"},
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{
+				RetainDummyVMOnFailure: true,
+				ReservedSCSIUnits:      []int32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+			},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				Volumes: []migrationv1alpha1.PVMigrationState{{
+					PVName:           "pv-data",
+					SourceVolumePath: vsphere.BuildCSIVolumeHandle(fcdID),
+					Status:           phases.PVStatusPVCDeleted,
+				}},
+			},
+		},
+	}
+	pvState := &migration.Status.CSIVolumeMigration.Volumes[0]
+	const dummyVMName = "csi-migration-test-cluster-pv-data"
+
+	errs := phase.RelocateVolumes(ctx, client, client, migration, []*migrationv1alpha1.PVMigrationState{pvState})
+	if errs["pv-data"] == nil {
+		t.Fatal("Expected the relocation to fail without a free SCSI unit")
+	}
+	retainedServer, retainedName, _ := strings.Cut(pvState.RetainedDummyVM, "/")
+	if retainedServer != host || !strings.HasPrefix(retainedName, dummyVMName+"-failed-") {
+		t.Fatalf("Expected the renamed dummy VM to be recorded, got %q", pvState.RetainedDummyVM)
+	}
+	if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/"+retainedName); err != nil {
+		t.Fatalf("Expected the dummy VM to be kept as %s, got %v", retainedName, err)
+	}
+	if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/"+dummyVMName); !vsphere.IsNotFound(err) {
+		t.Fatalf("Expected the original dummy VM name to be free, got %v", err)
+	}
+
+	// A retry creates its dummy VM under the original name and keeps tracking the retained VM.
+	// It is failed by a VolumeAttachment, after the dummy VM is created, so it is not retained.
+	pvName := "pv-data"
+	if _, err := kubeClient.StorageV1().VolumeAttachments().Create(ctx, &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-pv-data"},
+		Spec: storagev1.VolumeAttachmentSpec{
+			NodeName: "worker-0",
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+		},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create VolumeAttachment: %v", err)
+	}
+	pvState.Status = phases.PVStatusPVCDeleted
+	errs = phase.RelocateVolumes(ctx, client, client, migration, []*migrationv1alpha1.PVMigrationState{pvState})
+	if err := errs["pv-data"]; err == nil || !strings.Contains(err.Error(), "VolumeAttachment") {
+		t.Fatalf("Expected the retry to get past creating its dummy VM, got %v", err)
+	}
+	if pvState.RetainedDummyVM != host+"/"+retainedName {
+		t.Errorf("Expected the retry to keep tracking %s, got %q", retainedName, pvState.RetainedDummyVM)
+	}
+
+	// Cancellation and startup cleanup leave the retained VM alone
+	cleanup, err := executor.CleanupLeftoverDummyVMs(ctx, migration)
+	if err != nil {
+		t.Fatalf("CleanupLeftoverDummyVMs failed: %v", err)
+	}
+	if _, ok := cleanup.Retained[retainedName]; !ok || pvState.RetainedDummyVM == "" {
+		t.Errorf("Expected %s to be kept and tracked, got %+v", retainedName, cleanup)
+	}
+
+	// The Cleanup phase deletes it and only then forgets it
+	cleanup, err = executor.CleanupAllDummyVMs(ctx, migration)
+	if err != nil {
+		t.Fatalf("CleanupAllDummyVMs failed: %v", err)
+	}
+	if !slices.Contains(cleanup.Deleted, retainedName) {
+		t.Errorf("Expected %s to be deleted, got %+v", retainedName, cleanup)
+	}
+	if pvState.RetainedDummyVM != "" {
+		t.Errorf("Expected the deleted VM to be forgotten, got %q", pvState.RetainedDummyVM)
+	}
+}
+
+func TestMigrateCSIVolumesPhase_AwaitRestoredWorkloads(t *testing.T) {
+	ctx := context.Background()
+	api := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"}}
+	db := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"}}
+	kubeClient := kubefake.NewSimpleClientset(api, db)
+	workloadManager := openshift.NewWorkloadManager(kubeClient)
+
+	past := metav1.NewTime(time.Now().Add(-time.Minute))
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationConfig{
+				WorkloadReadinessTimeout: &metav1.Duration{Duration: 10 * time.Minute},
+			},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{PVName: "pv-api", Status: phases.PVStatusPVUpdated,
+						ScaledDownResources: []migrationv1alpha1.ScaledResource{{Kind: "Deployment", Name: "api", Namespace: "app", OriginalReplicas: 1}}},
+					// The StatefulSet was scaled down by its first replica's volume
+					{PVName: "pv-db-0", Status: phases.PVStatusWorkloadsRestored, WorkloadGroup: "StatefulSet/app/db", WorkloadsReadyDeadline: &past,
+						ScaledDownResources: []migrationv1alpha1.ScaledResource{{Kind: "StatefulSet", Name: "db", Namespace: "app", OriginalReplicas: 2}}},
+					{PVName: "pv-db-1", Status: phases.PVStatusWorkloadsRestored, WorkloadGroup: "StatefulSet/app/db", WorkloadsReadyDeadline: &past},
+				},
+			},
+		},
+	}
+	status := migration.Status.CSIVolumeMigration
+	phase := phases.NewMigrateCSIVolumesPhase(phases.NewPhaseExecutor(kubeClient, nil, nil, nil, nil, nil, nil))
+
+	// A volume whose workloads are not ready yet is left waiting instead of blocking the reconcile
+	start := time.Now()
+	phase.AwaitRestoredWorkloads(ctx, workloadManager, nil, migration, &status.Volumes[0])
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the readiness check not to block, took %s", elapsed)
+	}
+	if status.Volumes[0].Status != phases.PVStatusWorkloadsRestored || status.Volumes[0].WorkloadsReadyDeadline == nil {
+		t.Fatalf("Expected pv-api to wait with a deadline, got status %s", status.Volumes[0].Status)
+	}
+	if remaining := time.Until(status.Volumes[0].WorkloadsReadyDeadline.Time); remaining <= 9*time.Minute || remaining > 10*time.Minute {
+		t.Errorf("Expected the deadline about 10m away, got %s", remaining)
+	}
+	if status.MigratedVolumes != 0 {
+		t.Errorf("Expected no migrated volume yet, got %d", status.MigratedVolumes)
+	}
+
+	// Once ready, the volume completes on the next reconcile
+	api.Status.ReadyReplicas = 1
+	if _, err := kubeClient.AppsV1().Deployments("app").UpdateStatus(ctx, api, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update Deployment status: %v", err)
+	}
+	phase.AwaitRestoredWorkloads(ctx, workloadManager, nil, migration, &status.Volumes[0])
+	if status.Volumes[0].Status != phases.PVStatusComplete || len(status.Volumes[0].UnreadyWorkloads) != 0 {
+		t.Errorf("Expected pv-api to complete with its workloads ready, got %s %v", status.Volumes[0].Status, status.Volumes[0].UnreadyWorkloads)
+	}
+
+	// Past the deadline, each volume of the group completes listing the group's unready StatefulSet
+	for i := 1; i < 3; i++ {
+		phase.AwaitRestoredWorkloads(ctx, workloadManager, nil, migration, &status.Volumes[i])
+		if status.Volumes[i].Status != phases.PVStatusComplete {
+			t.Errorf("Expected %s to complete after its deadline, got %s", status.Volumes[i].PVName, status.Volumes[i].Status)
+		}
+		if want := []string{"StatefulSet/app/db"}; !reflect.DeepEqual(status.Volumes[i].UnreadyWorkloads, want) {
+			t.Errorf("Expected %s to list %v as unready, got %v", status.Volumes[i].PVName, want, status.Volumes[i].UnreadyWorkloads)
+		}
+	}
+	if status.MigratedVolumes != 3 {
+		t.Errorf("Expected 3 migrated volumes, got %d", status.MigratedVolumes)
+	}
+
+	// Without a readiness timeout a volume completes as soon as its workloads are restored
+	migration.Spec.CSIVolumeMigration.WorkloadReadinessTimeout = nil
+	pvState := &migrationv1alpha1.PVMigrationState{PVName: "pv-now", Status: phases.PVStatusPVUpdated, ScaledDownResources: status.Volumes[1].ScaledDownResources}
+	phase.AwaitRestoredWorkloads(ctx, workloadManager, nil, migration, pvState)
+	if pvState.Status != phases.PVStatusComplete || pvState.WorkloadsReadyDeadline != nil {
+		t.Errorf("Expected pv-now to complete without waiting, got %s", pvState.Status)
+	}
+}
+
+func TestMigrateCSIVolumesPhase_DiscardClone(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	username := simulator.DefaultLogin.Username()
+	password, _ := simulator.DefaultLogin.Password()
+	targetClient, err := vsphere.NewClient(ctx,
+		vsphere.Config{Server: server.URL.String(), Insecure: true},
+		vsphere.Credentials{Username: username, Password: password})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer targetClient.Logout(ctx)
+
+	// A VM with its disk removed stands in for the clone, and an FCD for a copy already registered
+	cloneVM, err := targetClient.GetVirtualMachine(ctx, "/DC0/vm/DC0_H0_VM0")
+	if err != nil {
+		t.Fatalf("Failed to get VM: %v", err)
+	}
+	copies, err := vsphere.NewVMRelocator(targetClient, targetClient).RemoveDisks(ctx, cloneVM)
+	if err != nil || len(copies) == 0 {
+		t.Fatalf("Failed to remove disks from VM: %v (%d disks)", err, len(copies))
+	}
+	ds, err := targetClient.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	task, err := vslm.NewObjectManager(targetClient.VimClient()).CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "registered-copy",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{Datastore: ds.Reference()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	registered := result.Result.(types.VStorageObject).Config
+	fcdManager, err := vsphere.NewFCDManager(ctx, targetClient)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+	fcd, err := fcdManager.GetFCDByID(ctx, registered.Id.Id)
+	if err != nil {
+		t.Fatalf("Failed to look up FCD: %v", err)
+	}
+	copies = append(copies, vsphere.AttachedDisk{FCDID: fcd.ID, FileName: fcd.Path})
+	dc, err := targetClient.GetDatacenter(ctx, "DC0")
+	if err != nil {
+		t.Fatalf("Failed to get datacenter: %v", err)
+	}
+	diskManager := object.NewVirtualDiskManager(targetClient.VimClient())
+	if _, err := diskManager.QueryVirtualDiskUuid(ctx, copies[0].FileName, dc); err != nil {
+		t.Fatalf("Expected the removed disk %s to be kept: %v", copies[0].FileName, err)
+	}
+
+	cause := errors.New("failed to register cloned disk")
+	phase := phases.NewMigrateCSIVolumesPhase(phases.NewPhaseExecutor(kubefake.NewSimpleClientset(), nil, nil, nil, nil, nil, nil))
+	if err := phase.DiscardClone(ctx, targetClient, "DC0", cloneVM, copies, cause); err != cause {
+		t.Fatalf("Expected the cause to be returned once the clone is discarded, got %v", err)
+	}
+
+	if _, err := targetClient.GetVirtualMachine(ctx, "/DC0/vm/DC0_H0_VM0"); err == nil {
+		t.Error("Expected the clone VM to be deleted")
+	}
+	if _, err := fcdManager.GetFCDByID(ctx, fcd.ID); !errors.Is(err, vsphere.ErrFCDNotFound) {
+		t.Errorf("Expected the registered copy to be deleted, got %v", err)
+	}
+	if _, err := diskManager.QueryVirtualDiskUuid(ctx, copies[0].FileName, dc); err == nil {
+		t.Errorf("Expected the unregistered copy %s to be deleted", copies[0].FileName)
+	}
+}
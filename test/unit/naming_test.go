@@ -0,0 +1,163 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+)
+
+func TestDummyVMName(t *testing.T) {
+	params := util.NameParams{InfraID: "cluster-x7x2g", PVName: "pvc-4e0f1b2c-9d3a-4f5e-8a7b-6c5d4e3f2a1b"}
+
+	t.Run("default template keeps the full PV name", func(t *testing.T) {
+		name, err := util.DummyVMName(nil, params)
+		if err != nil {
+			t.Fatalf("DummyVMName failed: %v", err)
+		}
+		if name != "csi-migration-cluster-x7x2g-pvc-4e0f1b2c-9d3a-4f5e-8a7b-6c5d4e3f2a1b" {
+			t.Errorf("Unexpected name %s", name)
+		}
+	})
+
+	t.Run("PVs sharing a prefix get distinct names", func(t *testing.T) {
+		first, err := util.DummyVMName(nil, util.NameParams{InfraID: "cluster-x7x2g", PVName: "pvc-4e0f1b2c-a"})
+		if err != nil {
+			t.Fatalf("DummyVMName failed: %v", err)
+		}
+		second, err := util.DummyVMName(nil, util.NameParams{InfraID: "cluster-x7x2g", PVName: "pvc-4e0f1b2c-b"})
+		if err != nil {
+			t.Fatalf("DummyVMName failed: %v", err)
+		}
+		if first == second {
+			t.Errorf("Expected distinct names, both were %s", first)
+		}
+	})
+
+	t.Run("custom template", func(t *testing.T) {
+		naming := &migrationv1alpha1.NamingTemplate{DummyVM: "mig-{{.PVName}}"}
+		name, err := util.DummyVMName(naming, params)
+		if err != nil {
+			t.Fatalf("DummyVMName failed: %v", err)
+		}
+		if name != "mig-"+params.PVName {
+			t.Errorf("Unexpected name %s", name)
+		}
+	})
+
+	t.Run("long names are truncated with a hash", func(t *testing.T) {
+		long := util.NameParams{InfraID: strings.Repeat("a", 50), PVName: strings.Repeat("b", 50)}
+		name, err := util.DummyVMName(nil, long)
+		if err != nil {
+			t.Fatalf("DummyVMName failed: %v", err)
+		}
+		if len(name) > util.MaxVSphereVMNameLength {
+			t.Errorf("Expected at most %d characters, got %d", util.MaxVSphereVMNameLength, len(name))
+		}
+
+		other, err := util.DummyVMName(nil, util.NameParams{InfraID: long.InfraID, PVName: strings.Repeat("b", 49) + "c"})
+		if err != nil {
+			t.Fatalf("DummyVMName failed: %v", err)
+		}
+		if name == other {
+			t.Errorf("Expected truncated names to differ, both were %s", name)
+		}
+	})
+
+	t.Run("invalid templates are rejected", func(t *testing.T) {
+		for _, tmpl := range []string{"{{.PVName", "{{.Unknown}}", "vms/{{.PVName}}"} {
+			naming := &migrationv1alpha1.NamingTemplate{DummyVM: tmpl}
+			if _, err := util.DummyVMName(naming, params); err == nil {
+				t.Errorf("Expected template %q to be rejected", tmpl)
+			}
+		}
+	})
+}
+
+func TestWorkerMachineSetName(t *testing.T) {
+	params := util.NameParams{InfraID: "cluster-x7x2g", FailureDomain: "us-east-1a"}
+
+	name, err := util.WorkerMachineSetName(nil, params)
+	if err != nil {
+		t.Fatalf("WorkerMachineSetName failed: %v", err)
+	}
+	if name != "cluster-x7x2g-worker-us-east-1a" {
+		t.Errorf("Unexpected default name %s", name)
+	}
+
+	naming := &migrationv1alpha1.NamingTemplate{WorkerMachineSet: "{{.InfraID}}-vcf-{{.FailureDomain}}"}
+	name, err = util.WorkerMachineSetName(naming, params)
+	if err != nil {
+		t.Fatalf("WorkerMachineSetName failed: %v", err)
+	}
+	if name != "cluster-x7x2g-vcf-us-east-1a" {
+		t.Errorf("Unexpected templated name %s", name)
+	}
+
+	long := util.NameParams{InfraID: strings.Repeat("a", 40), FailureDomain: strings.Repeat("z", 40)}
+	name, err = util.WorkerMachineSetName(nil, long)
+	if err != nil {
+		t.Fatalf("WorkerMachineSetName failed: %v", err)
+	}
+	if len(name) > util.MaxMachineSetNameLength {
+		t.Errorf("Expected at most %d characters, got %d", util.MaxMachineSetNameLength, len(name))
+	}
+
+	// Uppercase failure domain names are not valid Kubernetes object names
+	if _, err := util.WorkerMachineSetName(nil, util.NameParams{InfraID: "cluster", FailureDomain: "Zone-A"}); err == nil {
+		t.Error("Expected an invalid MachineSet name to be rejected")
+	}
+}
+
+func TestDummyVMNamePrefix(t *testing.T) {
+	prefix, err := util.DummyVMNamePrefix(nil, "cluster-x7x2g")
+	if err != nil {
+		t.Fatalf("DummyVMNamePrefix failed: %v", err)
+	}
+	if prefix != "csi-migration-cluster-x7x2g-" {
+		t.Errorf("Unexpected prefix %s", prefix)
+	}
+
+	name, err := util.DummyVMName(nil, util.NameParams{InfraID: strings.Repeat("a", 70), PVName: "pvc-1"})
+	if err != nil {
+		t.Fatalf("DummyVMName failed: %v", err)
+	}
+	prefix, err = util.DummyVMNamePrefix(nil, strings.Repeat("a", 70))
+	if err != nil {
+		t.Fatalf("DummyVMNamePrefix failed: %v", err)
+	}
+	if !strings.HasPrefix(name, prefix) {
+		t.Errorf("Expected truncated name %s to start with prefix %s", name, prefix)
+	}
+
+	// A template starting with the PV name would match every VM
+	if _, err := util.DummyVMNamePrefix(&migrationv1alpha1.NamingTemplate{DummyVM: "{{.PVName}}-mig"}, "cluster"); err == nil {
+		t.Error("Expected a template without a fixed prefix to be rejected")
+	}
+}
+
+func TestRetainedDummyVMName(t *testing.T) {
+	failedAt := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+	if name := util.RetainedDummyVMName("csi-migration-cluster-pvc-1", failedAt); name != "csi-migration-cluster-pvc-1-failed-20260304-040607" {
+		t.Errorf("Unexpected retained name %s", name)
+	}
+
+	// A long name is truncated but still starts with the dummy VM name prefix
+	name, err := util.DummyVMName(nil, util.NameParams{InfraID: strings.Repeat("a", 70), PVName: "pvc-1"})
+	if err != nil {
+		t.Fatalf("DummyVMName failed: %v", err)
+	}
+	prefix, err := util.DummyVMNamePrefix(nil, strings.Repeat("a", 70))
+	if err != nil {
+		t.Fatalf("DummyVMNamePrefix failed: %v", err)
+	}
+	retained := util.RetainedDummyVMName(name, failedAt)
+	if err := util.ValidateVSphereVMName(retained); err != nil {
+		t.Errorf("Invalid retained name: %v", err)
+	}
+	if !strings.HasPrefix(retained, prefix) || retained == name {
+		t.Errorf("Expected retained name %s to differ from %s and start with prefix %s", retained, name, prefix)
+	}
+}
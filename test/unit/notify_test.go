@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/notify"
+)
+
+func TestWebhookNotifier_RetriesOnce(t *testing.T) {
+	var attempts atomic.Int32
+	var down atomic.Bool
+	received := make(chan notify.Notification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 || down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON payload, got content type %q", r.Header.Get("Content-Type"))
+		}
+		var notification notify.Notification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			t.Errorf("Failed to decode notification: %v", err)
+		}
+		received <- notification
+	}))
+	defer server.Close()
+
+	webhook, err := notify.NewWebhookNotifier(server.URL)
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier failed: %v", err)
+	}
+	webhook.SetRetryDelay(10 * time.Millisecond)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-migration", Namespace: "openshift-config"},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{TotalVolumes: 3, MigratedVolumes: 2, FailedVolumes: 1},
+		},
+	}
+	notification := notify.NewNotification(notify.EventFailed, migration, migrationv1alpha1.PhaseMigrateCSIVolumes,
+		migrationv1alpha1.PhaseStatusFailed, "1 volume failed")
+	if err := webhook.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	got := <-received
+	if got.Event != notify.EventFailed || got.Migration != "my-migration" || got.Phase != "MigrateCSIVolumes" || got.Message != "1 volume failed" {
+		t.Errorf("Unexpected notification payload: %+v", got)
+	}
+	if got.Volumes == nil || got.Volumes.Total != 3 || got.Volumes.Failed != 1 {
+		t.Errorf("Expected the volume summary in the payload, got %+v", got.Volumes)
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected one retry, got %d attempts", attempts.Load())
+	}
+
+	// A webhook that keeps failing gives up after the retry
+	down.Store(true)
+	attempts.Store(0)
+	if err := webhook.Send(context.Background(), notification); err == nil {
+		t.Error("Expected Send to fail when the webhook keeps failing")
+	}
+	if attempts.Load() != 2 {
+		t.Errorf("Expected two attempts for a failing webhook, got %d", attempts.Load())
+	}
+}
+
+func TestNewWebhookNotifier_InvalidURL(t *testing.T) {
+	for _, webhookURL := range []string{"hooks.example.com/notify", "ftp://hooks.example.com", "https://"} {
+		if _, err := notify.NewWebhookNotifier(webhookURL); err == nil {
+			t.Errorf("Expected %q to be rejected", webhookURL)
+		}
+	}
+}
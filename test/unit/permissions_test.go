@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+func TestMissingPermissions(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		// The service account may do everything but touch PersistentVolumes and read secrets outside its namespace
+		switch {
+		case attributes.Resource == "persistentvolumes":
+			review.Status.Reason = "no RBAC policy matched"
+		case attributes.Resource == "secrets" && attributes.Verb == "get" && attributes.Namespace != "vmware-cloud-foundation-migration":
+		default:
+			review.Status.Allowed = true
+		}
+		return true, review, nil
+	})
+
+	ctx := context.Background()
+	missing, err := openshift.MissingPermissions(ctx, kubeClient,
+		openshift.RequiredPermissions("vmware-cloud-foundation-migration", "vmware-cloud-foundation-migration"))
+	if err != nil {
+		t.Fatalf("MissingPermissions failed: %v", err)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("expected the 2 PersistentVolume permissions to be missing, got %v", missing)
+	}
+	for _, verb := range []string{"update", "delete"} {
+		want := verb + " persistentvolumes (no RBAC policy matched)"
+		found := false
+		for _, description := range missing {
+			if description == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %q among missing permissions %v", want, missing)
+		}
+	}
+
+	missing, err = openshift.MissingPermissions(ctx, kubeClient, openshift.RequiredPermissions("credentials", ""))
+	if err != nil {
+		t.Fatalf("MissingPermissions failed: %v", err)
+	}
+	if len(missing) != 3 || !strings.Contains(strings.Join(missing, ";"), "get secrets in namespace credentials") {
+		t.Errorf("expected the secrets of namespace credentials to be reported, got %v", missing)
+	}
+	for _, description := range missing {
+		if strings.Contains(description, "leases") {
+			t.Errorf("lease permissions should not be checked without leader election, got %q", description)
+		}
+	}
+}
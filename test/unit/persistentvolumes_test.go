@@ -2,13 +2,24 @@ package unit
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
 
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
 )
 
@@ -133,7 +144,7 @@ func TestListVSphereCSIVolumes(t *testing.T) {
 
 			pvManager := openshift.NewPersistentVolumeManager(kubeClient)
 
-			csiPVs, err := pvManager.ListVSphereCSIVolumes(context.Background())
+			csiPVs, err := pvManager.ListVSphereCSIVolumes(context.Background(), nil, "openshift-config/vcf")
 			if err != nil {
 				t.Fatalf("ListVSphereCSIVolumes failed: %v", err)
 			}
@@ -145,6 +156,209 @@ func TestListVSphereCSIVolumes(t *testing.T) {
 	}
 }
 
+func TestListVSphereCSIVolumes_VolumeSelector(t *testing.T) {
+	newPV := func(name, pvcNamespace, pvcName string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{
+						Driver:       openshift.VSphereCSIDriver,
+						VolumeHandle: "file://" + name,
+					},
+				},
+				ClaimRef: &corev1.ObjectReference{Namespace: pvcNamespace, Name: pvcName},
+			},
+		}
+	}
+	newPVC := func(namespace, name string, labels map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		}
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(
+		newPV("pv-app-1", "app", "data-1"),
+		newPV("pv-app-2", "app", "data-2"),
+		newPV("pv-db-1", "db", "data-1"),
+		newPVC("app", "data-1", map[string]string{"migrate": "canary"}),
+		newPVC("app", "data-2", nil),
+		newPVC("db", "data-1", map[string]string{"migrate": "canary"}),
+	)
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+
+	tests := []struct {
+		name          string
+		selector      *migrationv1alpha1.VolumeSelector
+		expectedNames []string
+	}{
+		{
+			name:          "nil selector selects all volumes",
+			selector:      nil,
+			expectedNames: []string{"pv-app-1", "pv-app-2", "pv-db-1"},
+		},
+		{
+			name:          "namespace allowlist",
+			selector:      &migrationv1alpha1.VolumeSelector{Namespaces: []string{"app"}},
+			expectedNames: []string{"pv-app-1", "pv-app-2"},
+		},
+		{
+			name:          "PV name list",
+			selector:      &migrationv1alpha1.VolumeSelector{PVNames: []string{"pv-db-1"}},
+			expectedNames: []string{"pv-db-1"},
+		},
+		{
+			name: "PVC label selector",
+			selector: &migrationv1alpha1.VolumeSelector{
+				PVCLabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"migrate": "canary"}},
+			},
+			expectedNames: []string{"pv-app-1", "pv-db-1"},
+		},
+		{
+			name: "criteria are combined",
+			selector: &migrationv1alpha1.VolumeSelector{
+				Namespaces:       []string{"app"},
+				PVCLabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"migrate": "canary"}},
+			},
+			expectedNames: []string{"pv-app-1"},
+		},
+		{
+			name:          "no matches",
+			selector:      &migrationv1alpha1.VolumeSelector{Namespaces: []string{"other"}},
+			expectedNames: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			csiPVs, err := pvManager.ListVSphereCSIVolumes(context.Background(), tt.selector, "openshift-config/vcf")
+			if err != nil {
+				t.Fatalf("ListVSphereCSIVolumes failed: %v", err)
+			}
+
+			var names []string
+			for _, pv := range csiPVs {
+				names = append(names, pv.Name)
+			}
+			sort.Strings(names)
+
+			if !reflect.DeepEqual(names, tt.expectedNames) {
+				t.Errorf("expected volumes %v, got %v", tt.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestListInTreeVSphereVolumes(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "in-tree-pv"},
+			Spec: corev1.PersistentVolumeSpec{
+				StorageClassName: "thin",
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					VsphereVolume: &corev1.VsphereVirtualDiskVolumeSource{VolumePath: "[ds1] kubevols/disk.vmdk"},
+				},
+				ClaimRef: &corev1.ObjectReference{Namespace: "default", Name: "data"},
+			},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "csi-pv"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{
+						Driver:       openshift.VSphereCSIDriver,
+						VolumeHandle: "fcd-1",
+					},
+				},
+			},
+		},
+	)
+
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+	inTreePVs, err := pvManager.ListInTreeVSphereVolumes(context.Background())
+	if err != nil {
+		t.Fatalf("ListInTreeVSphereVolumes failed: %v", err)
+	}
+
+	if len(inTreePVs) != 1 {
+		t.Fatalf("Expected 1 in-tree volume, got %d", len(inTreePVs))
+	}
+	if inTreePVs[0].Name != "in-tree-pv" || inTreePVs[0].VolumePath != "[ds1] kubevols/disk.vmdk" {
+		t.Errorf("Unexpected in-tree volume: %+v", inTreePVs[0])
+	}
+	if inTreePVs[0].ClaimRef == nil || inTreePVs[0].ClaimRef.Name != "data" {
+		t.Errorf("Expected claimRef to be preserved, got %+v", inTreePVs[0].ClaimRef)
+	}
+}
+
+func TestGetVSphereCSIDriverStatus(t *testing.T) {
+	nodes := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-0"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		&storagev1.CSINode{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-0"},
+			Spec: storagev1.CSINodeSpec{
+				Drivers: []storagev1.CSINodeDriver{{Name: openshift.VSphereCSIDriver, NodeID: "worker-0"}},
+			},
+		},
+		&storagev1.CSINode{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+	}
+
+	t.Run("driver not installed", func(t *testing.T) {
+		pvManager := openshift.NewPersistentVolumeManager(kubefake.NewSimpleClientset(nodes...))
+		status, err := pvManager.GetVSphereCSIDriverStatus(context.Background())
+		if err != nil {
+			t.Fatalf("GetVSphereCSIDriverStatus failed: %v", err)
+		}
+		if status.Installed {
+			t.Error("Expected driver to be reported as not installed")
+		}
+	})
+
+	t.Run("driver installed", func(t *testing.T) {
+		objects := append([]runtime.Object{
+			&storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: openshift.VSphereCSIDriver}},
+		}, nodes...)
+		pvManager := openshift.NewPersistentVolumeManager(kubefake.NewSimpleClientset(objects...))
+		status, err := pvManager.GetVSphereCSIDriverStatus(context.Background())
+		if err != nil {
+			t.Fatalf("GetVSphereCSIDriverStatus failed: %v", err)
+		}
+		if !status.Installed {
+			t.Fatal("Expected driver to be reported as installed")
+		}
+		if !reflect.DeepEqual(status.RegisteredNodes, []string{"worker-0"}) {
+			t.Errorf("Expected registered nodes [worker-0], got %v", status.RegisteredNodes)
+		}
+		if !reflect.DeepEqual(status.UnregisteredNodes, []string{"worker-1"}) {
+			t.Errorf("Expected unregistered nodes [worker-1], got %v", status.UnregisteredNodes)
+		}
+	})
+}
+
+func TestGetStorageClass(t *testing.T) {
+	kubeClient := kubefake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "thin-csi"}, Provisioner: openshift.VSphereCSIDriver},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "thin"}, Provisioner: openshift.VSphereInTreeProvisioner},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "nfs"}, Provisioner: "example.com/nfs"},
+	)
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+
+	for name, expected := range map[string]bool{"thin-csi": true, "thin": true, "nfs": false} {
+		sc, err := pvManager.GetStorageClass(context.Background(), name)
+		if err != nil {
+			t.Fatalf("GetStorageClass(%s) failed: %v", name, err)
+		}
+		if got := openshift.IsVSphereStorageClass(sc); got != expected {
+			t.Errorf("IsVSphereStorageClass(%s) = %v, expected %v", name, got, expected)
+		}
+	}
+
+	if _, err := pvManager.GetStorageClass(context.Background(), "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected NotFound for a missing StorageClass, got: %v", err)
+	}
+}
+
 func TestUpdatePVVolumeHandle(t *testing.T) {
 	// Create PV with CSI source
 	pv := &corev1.PersistentVolume{
@@ -163,7 +377,9 @@ func TestUpdatePVVolumeHandle(t *testing.T) {
 	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
 
 	newHandle := "file://new-id-67890"
-	err := pvManager.UpdatePVVolumeHandle(context.Background(), "pv-test", newHandle)
+	migratedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	provenance := &openshift.MigrationProvenance{MigrationName: "openshift-config/vcf", MigratedAt: migratedAt}
+	err := pvManager.UpdatePVVolumeHandle(context.Background(), "pv-test", newHandle, provenance)
 	if err != nil {
 		t.Fatalf("UpdatePVVolumeHandle failed: %v", err)
 	}
@@ -177,6 +393,51 @@ func TestUpdatePVVolumeHandle(t *testing.T) {
 	if updatedPV.Spec.CSI.VolumeHandle != newHandle {
 		t.Errorf("expected volumeHandle %s, got %s", newHandle, updatedPV.Spec.CSI.VolumeHandle)
 	}
+	want := map[string]string{
+		openshift.MigratedFromHandleAnnotation: "file://old-id-12345",
+		openshift.MigratedAtAnnotation:         "2026-03-01T12:00:00Z",
+		openshift.MigrationNameAnnotation:      "openshift-config/vcf",
+	}
+	if !reflect.DeepEqual(updatedPV.Annotations, want) {
+		t.Errorf("expected provenance annotations %v, got %v", want, updatedPV.Annotations)
+	}
+
+	// A retried update keeps the original source handle
+	if err := pvManager.UpdatePVVolumeHandle(context.Background(), "pv-test", newHandle, provenance); err != nil {
+		t.Fatalf("UpdatePVVolumeHandle retry failed: %v", err)
+	}
+	updatedPV, err = kubeClient.CoreV1().PersistentVolumes().Get(context.Background(), "pv-test", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get PV: %v", err)
+	}
+	if got := updatedPV.Annotations[openshift.MigratedFromHandleAnnotation]; got != "file://old-id-12345" {
+		t.Errorf("expected the source handle to be kept on retry, got %s", got)
+	}
+
+	// The migrated PV is no longer listed for migration
+	csiPVs, err := pvManager.ListVSphereCSIVolumes(context.Background(), nil, "openshift-config/vcf")
+	if err != nil {
+		t.Fatalf("ListVSphereCSIVolumes failed: %v", err)
+	}
+	if len(csiPVs) != 0 {
+		t.Errorf("expected the migrated PV to be left out, got %v", csiPVs)
+	}
+	all, err := pvManager.ListAllVSphereCSIVolumes(context.Background())
+	if err != nil {
+		t.Fatalf("ListAllVSphereCSIVolumes failed: %v", err)
+	}
+	if len(all) != 1 {
+		t.Errorf("expected the migrated PV to be listed with all volumes, got %v", all)
+	}
+
+	// A later migration, such as one away from the vCenter the PV was moved to, migrates it again
+	csiPVs, err = pvManager.ListVSphereCSIVolumes(context.Background(), nil, "openshift-config/vcf-next")
+	if err != nil {
+		t.Fatalf("ListVSphereCSIVolumes failed: %v", err)
+	}
+	if len(csiPVs) != 1 {
+		t.Errorf("expected the PV migrated by another migration to be listed, got %v", csiPVs)
+	}
 }
 
 func TestFindPodsUsingPVC(t *testing.T) {
@@ -245,6 +506,159 @@ func TestFindPodsUsingPVC(t *testing.T) {
 	}
 }
 
+func TestWaitForPVAvailable(t *testing.T) {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-released"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+	}
+	kubeClient := kubefake.NewSimpleClientset(pv)
+	gets := 0
+	kubeClient.PrependReactor("get", "persistentvolumes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+	ctx := context.Background()
+
+	// A PV that never becomes Available times out after polling at the interval, not in a busy loop
+	start := time.Now()
+	err := pvManager.WaitForPVAvailable(ctx, "pv-released", 50*time.Millisecond, 300*time.Millisecond)
+	elapsed := time.Since(start)
+	if err == nil || !strings.Contains(err.Error(), "timeout") || !strings.Contains(err.Error(), string(corev1.VolumeReleased)) {
+		t.Fatalf("Expected a timeout error naming the Released phase, got %v", err)
+	}
+	if elapsed < 250*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected the wait to last about the 300ms timeout, took %s", elapsed)
+	}
+	if gets < 2 || gets > 10 {
+		t.Errorf("Expected the PV to be polled about every 50ms, got %d gets", gets)
+	}
+
+	// A cancelled context stops the wait
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := pvManager.WaitForPVAvailable(cancelled, "pv-released", 50*time.Millisecond, time.Minute); err == nil {
+		t.Error("Expected an error once the context is cancelled")
+	}
+
+	pv.Status.Phase = corev1.VolumeAvailable
+	if _, err := kubeClient.CoreV1().PersistentVolumes().UpdateStatus(ctx, pv, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update PV status: %v", err)
+	}
+	if err := pvManager.WaitForPVAvailable(ctx, "pv-released", 50*time.Millisecond, time.Second); err != nil {
+		t.Errorf("Expected an Available PV to end the wait, got %v", err)
+	}
+
+	if err := pvManager.WaitForPVAvailable(ctx, "missing", 50*time.Millisecond, time.Second); err == nil {
+		t.Error("Expected an error for a missing PV")
+	}
+}
+
+func TestWaitForPVCDeleted_BlockedByPods(t *testing.T) {
+	now := metav1.Now()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "data",
+			Namespace:         "app",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{openshift.PVCProtectionFinalizer},
+		},
+	}
+	usingPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				NodeName: "worker-0",
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	kubeClient := kubefake.NewSimpleClientset(pvc, usingPod("app-0", corev1.PodRunning), usingPod("job-0", corev1.PodSucceeded))
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+	ctx := context.Background()
+
+	// Only the pod that has not terminated keeps the PVC from finalizing
+	err := pvManager.WaitForPVCDeleted(ctx, "app", "data", 200*time.Millisecond)
+	var blocked *openshift.PVCDeletionBlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("Expected a PVCDeletionBlockedError, got %v", err)
+	}
+	if len(blocked.Pods) != 1 || blocked.Pods[0].Name != "app-0" {
+		t.Fatalf("Expected only pod app-0 to block the PVC, got %v", blocked.Pods)
+	}
+	if !strings.Contains(err.Error(), "app-0 (Running on node worker-0)") {
+		t.Errorf("Expected the error to describe the blocking pod, got %v", err)
+	}
+
+	if err := openshift.NewPodManager(kubeClient).ForceDeletePods(ctx, blocked.Pods); err != nil {
+		t.Fatalf("ForceDeletePods failed: %v", err)
+	}
+	if _, err := kubeClient.CoreV1().Pods("app").Get(ctx, "app-0", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected pod app-0 to be deleted, got %v", err)
+	}
+
+	// Without pods left the timeout reports the finalizers still on the PVC
+	err = pvManager.WaitForPVCDeleted(ctx, "app", "data", 200*time.Millisecond)
+	if errors.As(err, &blocked) || err == nil || !strings.Contains(err.Error(), openshift.PVCProtectionFinalizer) {
+		t.Errorf("Expected a timeout naming the finalizer, got %v", err)
+	}
+
+	if err := kubeClient.CoreV1().PersistentVolumeClaims("app").Delete(ctx, "data", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete PVC: %v", err)
+	}
+	if err := pvManager.WaitForPVCDeleted(ctx, "app", "data", time.Second); err != nil {
+		t.Errorf("Expected a deleted PVC to end the wait, got %v", err)
+	}
+}
+
+func TestCheckVolumeHandleSwap(t *testing.T) {
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-bound"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: openshift.VSphereCSIDriver, VolumeHandle: "fcd-1"},
+			},
+			ClaimRef: &corev1.ObjectReference{Namespace: "app", Name: "data"},
+		},
+	}
+	kubeClient := kubefake.NewSimpleClientset(pv)
+	var rejected bool
+	kubeClient.PrependReactor("update", "persistentvolumes", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		update := action.(clienttesting.UpdateActionImpl)
+		if len(update.UpdateOptions.DryRun) == 0 {
+			t.Error("Expected the volumeHandle change to be a dry run")
+		}
+		if rejected {
+			return true, nil, apierrors.NewForbidden(corev1.Resource("persistentvolumes"), "pv-bound", errors.New("volumeHandle is immutable"))
+		}
+		return true, update.GetObject(), nil
+	})
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+	ctx := context.Background()
+
+	if err := pvManager.CheckVolumeHandleSwap(ctx, "pv-bound"); err != nil {
+		t.Fatalf("Expected the volumeHandle change to be accepted, got %v", err)
+	}
+	stored, err := pvManager.GetPV(ctx, "pv-bound")
+	if err != nil {
+		t.Fatalf("Failed to get PV: %v", err)
+	}
+	if stored.Spec.CSI.VolumeHandle != "fcd-1" {
+		t.Errorf("Expected the PV to be left unchanged, got volumeHandle %s", stored.Spec.CSI.VolumeHandle)
+	}
+
+	rejected = true
+	if err := pvManager.CheckVolumeHandleSwap(ctx, "pv-bound"); err == nil || !strings.Contains(err.Error(), "volumeHandle is immutable") {
+		t.Errorf("Expected the rejection to be reported, got %v", err)
+	}
+}
+
 func TestParseVSphereVolumeHandle(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -286,3 +700,205 @@ func TestBuildVSphereVolumeHandle(t *testing.T) {
 		t.Errorf("expected handle %s, got %s", expected, handle)
 	}
 }
+
+func TestGetVolumeResizeInProgress(t *testing.T) {
+	storage := func(q string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(q)}
+	}
+
+	tests := []struct {
+		name       string
+		pvCapacity string
+		pvc        corev1.PersistentVolumeClaimSpec
+		status     corev1.PersistentVolumeClaimStatus
+		expectSkip bool
+	}{
+		{
+			name:       "no resize",
+			pvCapacity: "10Gi",
+			pvc:        corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{Requests: storage("10Gi")}},
+			status:     corev1.PersistentVolumeClaimStatus{Capacity: storage("10Gi")},
+		},
+		{
+			name:       "filesystem resize pending",
+			pvCapacity: "20Gi",
+			pvc:        corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{Requests: storage("20Gi")}},
+			status: corev1.PersistentVolumeClaimStatus{
+				Capacity: storage("10Gi"),
+				Conditions: []corev1.PersistentVolumeClaimCondition{
+					{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+				},
+			},
+			expectSkip: true,
+		},
+		{
+			name:       "allocated resource status reports resize",
+			pvCapacity: "10Gi",
+			pvc:        corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{Requests: storage("20Gi")}},
+			status: corev1.PersistentVolumeClaimStatus{
+				Capacity: storage("10Gi"),
+				AllocatedResourceStatuses: map[corev1.ResourceName]corev1.ClaimResourceStatus{
+					corev1.ResourceStorage: corev1.PersistentVolumeClaimControllerResizeInProgress,
+				},
+			},
+			expectSkip: true,
+		},
+		{
+			name:       "expansion requested but not started",
+			pvCapacity: "10Gi",
+			pvc:        corev1.PersistentVolumeClaimSpec{Resources: corev1.VolumeResourceRequirements{Requests: storage("20Gi")}},
+			status:     corev1.PersistentVolumeClaimStatus{Capacity: storage("10Gi")},
+			expectSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv-1"},
+				Spec:       corev1.PersistentVolumeSpec{Capacity: storage(tt.pvCapacity)},
+			}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"},
+				Spec:       tt.pvc,
+				Status:     tt.status,
+			}
+
+			pvManager := openshift.NewPersistentVolumeManager(kubefake.NewSimpleClientset(pv, pvc))
+			resize, err := pvManager.GetVolumeResizeInProgress(context.Background(), "pv-1", "app", "data")
+			if err != nil {
+				t.Fatalf("GetVolumeResizeInProgress failed: %v", err)
+			}
+			if (resize != "") != tt.expectSkip {
+				t.Errorf("Expected resize in progress=%v, got %q", tt.expectSkip, resize)
+			}
+		})
+	}
+}
+
+func TestRestorePVC_DropsDataSource(t *testing.T) {
+	ctx := context.Background()
+	storageClass := "thin-csi"
+	kubeClient := kubefake.NewSimpleClientset(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "restored-db",
+			Namespace:   "default",
+			Annotations: map[string]string{"owner": "dba"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To("snapshot.storage.k8s.io"),
+				Kind:     "VolumeSnapshot",
+				Name:     "nightly",
+			},
+			DataSourceRef: &corev1.TypedObjectReference{
+				APIGroup: ptr.To("snapshot.storage.k8s.io"),
+				Kind:     "VolumeSnapshot",
+				Name:     "nightly",
+			},
+		},
+	})
+	pvManager := openshift.NewPersistentVolumeManager(kubeClient)
+
+	backup, err := pvManager.BackupPVCSpec(ctx, "default", "restored-db")
+	if err != nil {
+		t.Fatalf("BackupPVCSpec failed: %v", err)
+	}
+
+	decoded, err := openshift.DecodePVCBackup(backup)
+	if err != nil {
+		t.Fatalf("DecodePVCBackup failed: %v", err)
+	}
+	if decoded.DataSourceRef == nil || decoded.DataSourceRef.Name != "nightly" {
+		t.Errorf("Expected the data source to be captured in the backup, got %+v", decoded.DataSourceRef)
+	}
+
+	if err := pvManager.DeletePVC(ctx, "default", "restored-db"); err != nil {
+		t.Fatalf("DeletePVC failed: %v", err)
+	}
+	provenance := &openshift.MigrationProvenance{SourceVolumeHandle: "file://old-id", MigrationName: "openshift-config/vcf", MigratedAt: time.Now()}
+	if err := pvManager.RestorePVC(ctx, backup, "pv-1", provenance); err != nil {
+		t.Fatalf("RestorePVC failed: %v", err)
+	}
+
+	pvc, err := pvManager.GetPVC(ctx, "default", "restored-db")
+	if err != nil {
+		t.Fatalf("Failed to get restored PVC: %v", err)
+	}
+	if pvc.Spec.DataSource != nil || pvc.Spec.DataSourceRef != nil {
+		t.Errorf("Expected the data source to be dropped, got %+v / %+v", pvc.Spec.DataSource, pvc.Spec.DataSourceRef)
+	}
+	if pvc.Spec.VolumeName != "pv-1" {
+		t.Errorf("Expected the PVC to bind to pv-1, got %s", pvc.Spec.VolumeName)
+	}
+	if got := pvc.Annotations[openshift.OriginalDataSourceAnnotation]; got != "VolumeSnapshot/nightly" {
+		t.Errorf("Expected the original data source annotation, got %q", got)
+	}
+	if pvc.Annotations["owner"] != "dba" {
+		t.Errorf("Expected existing annotations to be kept, got %v", pvc.Annotations)
+	}
+	if pvc.Annotations[openshift.MigratedFromHandleAnnotation] != "file://old-id" || pvc.Annotations[openshift.MigrationNameAnnotation] != "openshift-config/vcf" {
+		t.Errorf("Expected the migration provenance annotations, got %v", pvc.Annotations)
+	}
+}
+
+func TestRebindPVC(t *testing.T) {
+	ctx := context.Background()
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-data-web-0"},
+		Spec: corev1.PersistentVolumeSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Capacity:         corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			StorageClassName: "thin-csi",
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "csi.vsphere.vmware.com", VolumeHandle: "target-fcd"},
+			},
+		},
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data-web-0", Namespace: "app", UID: "pvc-uid"},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data-web-0"},
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(pv, pvc)
+	manager := openshift.NewPersistentVolumeManager(kubeClient)
+	provenance := &openshift.MigrationProvenance{SourceVolumeHandle: "source-fcd", MigrationName: "ns/m", MigratedAt: time.Now()}
+
+	// A cleared claimRef is reset to the kept PVC
+	if err := manager.RebindPVC(ctx, "app", "data-web-0", "pv-data-web-0", provenance); err != nil {
+		t.Fatalf("RebindPVC failed: %v", err)
+	}
+	updatedPV, _ := kubeClient.CoreV1().PersistentVolumes().Get(ctx, "pv-data-web-0", metav1.GetOptions{})
+	if ref := updatedPV.Spec.ClaimRef; ref == nil || ref.Name != "data-web-0" || ref.Namespace != "app" || ref.UID != "pvc-uid" {
+		t.Errorf("Expected claimRef to app/data-web-0 (pvc-uid), got %+v", ref)
+	}
+	updatedPVC, _ := kubeClient.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data-web-0", metav1.GetOptions{})
+	if updatedPVC.Annotations[openshift.MigratedFromHandleAnnotation] != "source-fcd" {
+		t.Errorf("Expected provenance on the kept PVC, got %v", updatedPVC.Annotations)
+	}
+
+	// A PVC bound elsewhere is not rebound
+	if err := manager.RebindPVC(ctx, "app", "data-web-0", "other-pv", nil); err == nil {
+		t.Error("Expected an error rebinding a PVC bound to another PV")
+	}
+
+	// A deleted PVC is created bound to the PV from the PV's spec
+	if err := kubeClient.CoreV1().PersistentVolumeClaims("app").Delete(ctx, "data-web-0", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete PVC: %v", err)
+	}
+	if err := manager.RebindPVC(ctx, "app", "data-web-0", "pv-data-web-0", nil); err != nil {
+		t.Fatalf("RebindPVC failed: %v", err)
+	}
+	created, err := kubeClient.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data-web-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the PVC to be created: %v", err)
+	}
+	if created.Spec.VolumeName != "pv-data-web-0" || ptr.Deref(created.Spec.StorageClassName, "") != "thin-csi" {
+		t.Errorf("Expected PVC bound to pv-data-web-0 with class thin-csi, got %+v", created.Spec)
+	}
+	if size := created.Spec.Resources.Requests[corev1.ResourceStorage]; size.String() != "10Gi" {
+		t.Errorf("Expected a 10Gi request, got %s", size.String())
+	}
+}
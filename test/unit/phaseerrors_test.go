@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
+)
+
+func TestPhaseErrorClass(t *testing.T) {
+	base := errors.New("vMotion lost track of the disk")
+
+	err := phaseerrors.DataSafety(base)
+	if err.Error() != base.Error() {
+		t.Errorf("Expected the message to be kept, got %q", err.Error())
+	}
+	if !errors.Is(err, base) {
+		t.Error("Expected the classified error to wrap the original")
+	}
+
+	// Wrapping keeps the classification
+	wrapped := fmt.Errorf("failed to relocate volume: %w", err)
+	if phaseerrors.Class(wrapped) != phaseerrors.ErrDataSafety {
+		t.Errorf("Expected a data safety error, got %v", phaseerrors.Class(wrapped))
+	}
+
+	// The most cautious classification wins
+	both := phaseerrors.DataSafety(phaseerrors.Transient(base))
+	if phaseerrors.Class(both) != phaseerrors.ErrDataSafety {
+		t.Errorf("Expected data safety to take precedence over transient, got %v", phaseerrors.Class(both))
+	}
+	if phaseerrors.ClassName(both) != phaseerrors.ClassDataSafety {
+		t.Errorf("Expected class name %s, got %s", phaseerrors.ClassDataSafety, phaseerrors.ClassName(both))
+	}
+
+	if phaseerrors.Class(base) != nil || phaseerrors.ClassName(base) != "" {
+		t.Errorf("Expected an unclassified error, got %v", phaseerrors.Class(base))
+	}
+	if phaseerrors.Validation(nil) != nil {
+		t.Error("Expected classifying nil to return nil")
+	}
+}
+
+func TestClassifyVSphereError(t *testing.T) {
+	if phaseerrors.Class(vsphere.ClassifyError(fmt.Errorf("read: %w", io.ErrUnexpectedEOF))) != phaseerrors.ErrTransient {
+		t.Error("Expected a dropped connection to be transient")
+	}
+	if phaseerrors.Class(vsphere.ClassifyError(errors.New("insufficient disk space"))) != nil {
+		t.Error("Expected other vCenter errors to stay unclassified")
+	}
+	if vsphere.ClassifyError(nil) != nil {
+		t.Error("Expected nil to stay nil")
+	}
+}
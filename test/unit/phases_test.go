@@ -2,21 +2,37 @@ package unit
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/vmware/govmomi/simulator"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
 
 	configv1 "github.com/openshift/api/config/v1"
+	machinev1beta1 "github.com/openshift/api/machine/v1beta1"
 	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
 	machinefake "github.com/openshift/client-go/machine/clientset/versioned/fake"
 	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/backup"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
 func TestPreflightPhase_Validate(t *testing.T) {
@@ -93,6 +109,29 @@ func TestPreflightPhase_Validate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "source vCenter is also a target (intra-vCenter migration)",
+			migration: &migrationv1alpha1.VmwareCloudFoundationMigration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-migration",
+					Namespace: "vmware-cloud-foundation-migration",
+				},
+				Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+					TargetVCenterCredentialsSecret: migrationv1alpha1.SecretReference{
+						Name:      "target-vcenter-creds",
+						Namespace: "kube-system",
+					},
+					SourceVCenterServer: "new-vcenter.example.com",
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+						{
+							Name:   "fd1",
+							Server: "new-vcenter.example.com",
+						},
+					},
+				},
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -125,6 +164,178 @@ func TestPreflightPhase_Validate(t *testing.T) {
 	}
 }
 
+func TestGetSourceVCenter(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			PlatformSpec: configv1.PlatformSpec{
+				Type: configv1.VSpherePlatformType,
+				VSphere: &configv1.VSpherePlatformSpec{
+					VCenters: []configv1.VSpherePlatformVCenterSpec{
+						{Server: "vcenter-a.example.com", Datacenters: []string{"DC1"}},
+						{Server: "vcenter-b.example.com", Datacenters: []string{"DC2"}},
+					},
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+						{Name: "fd-a", Server: "vcenter-a.example.com", Topology: configv1.VSpherePlatformTopology{Datacenter: "DC1"}},
+						{Name: "fd-b", Server: "vcenter-b.example.com", Topology: configv1.VSpherePlatformTopology{Datacenter: "DC2"}},
+					},
+				},
+			},
+		},
+	}
+	infraManager := openshift.NewInfrastructureManager(configfake.NewSimpleClientset(infra))
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		server         string
+		expectedServer string
+		expectedFD     string
+		expectError    bool
+	}{
+		{name: "defaults to the first vCenter", expectedServer: "vcenter-a.example.com", expectedFD: "fd-a"},
+		{name: "named vCenter", server: "vcenter-b.example.com", expectedServer: "vcenter-b.example.com", expectedFD: "fd-b"},
+		{name: "unknown vCenter", server: "vcenter-c.example.com", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vc, err := infraManager.GetSourceVCenter(ctx, tt.server)
+			fd, fdErr := infraManager.GetSourceFailureDomain(ctx, tt.server)
+			if tt.expectError {
+				if err == nil || fdErr == nil {
+					t.Fatalf("expected errors, got %v and %v", err, fdErr)
+				}
+				return
+			}
+			if err != nil || fdErr != nil {
+				t.Fatalf("unexpected errors: %v, %v", err, fdErr)
+			}
+			if vc.Server != tt.expectedServer {
+				t.Errorf("expected vCenter %s, got %s", tt.expectedServer, vc.Server)
+			}
+			if fd.Name != tt.expectedFD {
+				t.Errorf("expected failure domain %s, got %s", tt.expectedFD, fd.Name)
+			}
+		})
+	}
+}
+
+func TestAddTargetVCenter_Idempotent(t *testing.T) {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			PlatformSpec: configv1.PlatformSpec{
+				Type: configv1.VSpherePlatformType,
+				VSphere: &configv1.VSpherePlatformSpec{
+					VCenters: []configv1.VSpherePlatformVCenterSpec{
+						{Server: "old-vcenter.example.com", Datacenters: []string{"DC1"}},
+					},
+					FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+						{Name: "source", Server: "old-vcenter.example.com", Topology: configv1.VSpherePlatformTopology{Datacenter: "DC1"}},
+					},
+				},
+			},
+		},
+	}
+	infraManager := openshift.NewInfrastructureManager(configfake.NewSimpleClientset(infra))
+	ctx := context.Background()
+
+	failureDomain := func(name, datastore string) configv1.VSpherePlatformFailureDomainSpec {
+		return configv1.VSpherePlatformFailureDomainSpec{
+			Name:   name,
+			Server: "new-vcenter.example.com",
+			Topology: configv1.VSpherePlatformTopology{
+				Datacenter:     "DC2",
+				ComputeCluster: "/DC2/host/cluster",
+				Datastore:      datastore,
+			},
+		}
+	}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+				failureDomain("fd-a", "/DC2/datastore/ds1"),
+				failureDomain("fd-b", "/DC2/datastore/ds1"),
+			},
+		},
+	}
+
+	updated, err := infraManager.AddTargetVCenter(ctx, infra, migration)
+	if err != nil {
+		t.Fatalf("AddTargetVCenter failed: %v", err)
+	}
+
+	// A re-run, e.g. after a failure later in the phase, updates the failure domains in place
+	migration.Spec.FailureDomains[1].Topology.Datastore = "/DC2/datastore/ds2"
+	updated, err = infraManager.AddTargetVCenter(ctx, updated, migration)
+	if err != nil {
+		t.Fatalf("second AddTargetVCenter failed: %v", err)
+	}
+
+	if len(updated.Spec.PlatformSpec.VSphere.VCenters) != 2 {
+		t.Errorf("expected 2 vCenters, got %d", len(updated.Spec.PlatformSpec.VSphere.VCenters))
+	}
+	counts := make(map[string]int)
+	for _, fd := range updated.Spec.PlatformSpec.VSphere.FailureDomains {
+		counts[fd.Name]++
+		if fd.Name == "fd-b" && fd.Topology.Datastore != "/DC2/datastore/ds2" {
+			t.Errorf("expected fd-b to be updated in place, got datastore %s", fd.Topology.Datastore)
+		}
+	}
+	expected := map[string]int{"source": 1, "fd-a": 1, "fd-b": 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("expected exactly one failure domain per name %v, got %v", expected, counts)
+	}
+}
+
+func TestPhaseExecutor_VCenterConnection(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	// The vCenter is named without its port, which comes from the controller's connection settings
+	hostname := server.URL.Hostname()
+	port, _ := strconv.Atoi(server.URL.Port())
+	username := simulator.DefaultLogin.Username()
+	password, _ := simulator.DefaultLogin.Password()
+	creds := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: openshift.VSphereCredsSecretName, Namespace: openshift.VSphereCredsSecretNamespace},
+		Data:       map[string][]byte{hostname + ".username": []byte(username), hostname + ".password": []byte(password)},
+	}
+	newExecutor := func(connection vsphere.Config) *phases.PhaseExecutor {
+		executor := phases.NewPhaseExecutor(kubefake.NewSimpleClientset(creds), configfake.NewSimpleClientset(),
+			apiextensionsfake.NewSimpleClientset(), machinefake.NewSimpleClientset(),
+			dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), backup.NewBackupManager(runtime.NewScheme()), nil)
+		executor.SetVCenterConnection(connection)
+		return executor
+	}
+	ctx := context.Background()
+
+	client, err := newExecutor(vsphere.Config{Port: port, DialTimeout: 7 * time.Second}).GetVSphereClient(ctx, hostname)
+	if err != nil {
+		t.Fatalf("GetVSphereClient failed: %v", err)
+	}
+	defer client.Logout(ctx)
+	if !strings.Contains(client.SDKURL(), server.URL.Host) {
+		t.Errorf("expected the client to connect to %s, got %s", server.URL.Host, client.SDKURL())
+	}
+	if client.DialTimeout() != 7*time.Second {
+		t.Errorf("expected dial timeout 7s, got %s", client.DialTimeout())
+	}
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+	_, err = newExecutor(vsphere.Config{Port: port, ProxyURL: "ftp://proxy.example.com"}).GetVSphereClientFromMigration(ctx, migration, hostname)
+	if err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Errorf("expected the proxy URL to be used, got %v", err)
+	}
+}
+
 func TestBackupPhase_Name(t *testing.T) {
 	kubeClient := kubefake.NewSimpleClientset()
 	configClient := configfake.NewSimpleClientset()
@@ -196,6 +407,63 @@ func TestDisableCVOPhase_Execute(t *testing.T) {
 	}
 }
 
+func TestDisableCVOPhase_RollbackRestoresReplicas(t *testing.T) {
+	ctx := context.Background()
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cluster-version-operator",
+			Namespace: "openshift-cluster-version",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(2)),
+		},
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(deployment)
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(kubeClient, configfake.NewSimpleClientset(), apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(), dynamicfake.NewSimpleDynamicClient(scheme), backup.NewBackupManager(scheme), nil)
+	phase := phases.NewDisableCVOPhase(executor)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+	}
+
+	if !phases.AutoReEnableCVO(migration) {
+		t.Error("expected the CVO to be re-enabled at completion by default")
+	}
+
+	if _, err := phase.Execute(ctx, migration); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if ptr.Deref(migration.Status.CVOReplicas, 0) != 2 {
+		t.Fatalf("expected 2 CVO replicas recorded, got %v", migration.Status.CVOReplicas)
+	}
+
+	// A re-run finds the CVO scaled down and keeps the recorded count
+	if _, err := phase.Execute(ctx, migration); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if ptr.Deref(migration.Status.CVOReplicas, 0) != 2 {
+		t.Fatalf("expected the recorded CVO replicas to be kept, got %v", migration.Status.CVOReplicas)
+	}
+
+	if err := phase.Rollback(ctx, migration); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	updated, err := kubeClient.AppsV1().Deployments("openshift-cluster-version").Get(ctx, "cluster-version-operator", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *updated.Spec.Replicas != 2 {
+		t.Errorf("expected CVO restored to 2 replicas, got %d", *updated.Spec.Replicas)
+	}
+
+	// A CVO that is already running is left alone
+	if replicas, err := executor.EnableCVO(ctx, migration); err != nil || replicas != 0 {
+		t.Errorf("expected a running CVO to be left alone, got %d, %v", replicas, err)
+	}
+}
+
 func TestUpdateSecretsPhase_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -451,3 +719,1090 @@ func TestUpdateInfrastructurePhase_Execute(t *testing.T) {
 		t.Errorf("expected 1 failure domain, got %d", len(updatedInfra.Spec.PlatformSpec.VSphere.FailureDomains))
 	}
 }
+
+func TestMonitorHealthPhase_Execute(t *testing.T) {
+	clusterOperator := func(name string, available, degraded configv1.ConditionStatus) *configv1.ClusterOperator {
+		return &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: configv1.ClusterOperatorStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorAvailable, Status: available},
+					{Type: configv1.OperatorDegraded, Status: degraded, Message: name + " is degraded"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		operators      []runtime.Object
+		healthCheck    *migrationv1alpha1.HealthCheckConfig
+		phaseStarted   time.Duration
+		expectedStatus migrationv1alpha1.PhaseStatus
+		expectErr      bool
+	}{
+		{
+			name: "all operators healthy",
+			operators: []runtime.Object{
+				clusterOperator("storage", configv1.ConditionTrue, configv1.ConditionFalse),
+			},
+			expectedStatus: migrationv1alpha1.PhaseStatusCompleted,
+		},
+		{
+			name: "degraded operator requeues",
+			operators: []runtime.Object{
+				clusterOperator("storage", configv1.ConditionTrue, configv1.ConditionTrue),
+			},
+			phaseStarted:   time.Minute,
+			expectedStatus: migrationv1alpha1.PhaseStatusRunning,
+		},
+		{
+			name: "unavailable operator requeues",
+			operators: []runtime.Object{
+				clusterOperator("storage", configv1.ConditionFalse, configv1.ConditionFalse),
+			},
+			expectedStatus: migrationv1alpha1.PhaseStatusRunning,
+		},
+		{
+			name: "tolerated degraded operator does not block",
+			operators: []runtime.Object{
+				clusterOperator("storage", configv1.ConditionTrue, configv1.ConditionTrue),
+			},
+			healthCheck: &migrationv1alpha1.HealthCheckConfig{
+				ToleratedOperators: []string{"storage"},
+			},
+			expectedStatus: migrationv1alpha1.PhaseStatusCompleted,
+		},
+		{
+			name: "degraded operator past timeout fails",
+			operators: []runtime.Object{
+				clusterOperator("storage", configv1.ConditionTrue, configv1.ConditionTrue),
+			},
+			healthCheck: &migrationv1alpha1.HealthCheckConfig{
+				Timeout: &metav1.Duration{Duration: 5 * time.Minute},
+			},
+			phaseStarted:   10 * time.Minute,
+			expectedStatus: migrationv1alpha1.PhaseStatusFailed,
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			executor := phases.NewPhaseExecutor(
+				kubefake.NewSimpleClientset(),
+				configfake.NewSimpleClientset(tt.operators...),
+				apiextensionsfake.NewSimpleClientset(),
+				machinefake.NewSimpleClientset(),
+				dynamicfake.NewSimpleDynamicClient(scheme),
+				backup.NewBackupManager(scheme),
+				nil)
+
+			migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-migration",
+					Namespace: "vmware-cloud-foundation-migration",
+				},
+				Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+					HealthCheck: tt.healthCheck,
+				},
+			}
+			if tt.phaseStarted > 0 {
+				startTime := metav1.NewTime(time.Now().Add(-tt.phaseStarted))
+				migration.Status.CurrentPhaseState = &migrationv1alpha1.PhaseState{
+					Name:      migrationv1alpha1.PhaseMonitorHealth,
+					Status:    migrationv1alpha1.PhaseStatusRunning,
+					StartTime: &startTime,
+				}
+			}
+
+			result, err := phases.NewMonitorHealthPhase(executor).Execute(context.Background(), migration)
+			if tt.expectErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if result.Status != tt.expectedStatus {
+				t.Errorf("expected status %s, got %s (%s)", tt.expectedStatus, result.Status, result.Message)
+			}
+		})
+	}
+}
+
+func TestUnsettledOperators(t *testing.T) {
+	clusterOperator := func(name string, available, progressing configv1.ConditionStatus) *configv1.ClusterOperator {
+		return &configv1.ClusterOperator{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: configv1.ClusterOperatorStatus{
+				Conditions: []configv1.ClusterOperatorStatusCondition{
+					{Type: configv1.OperatorAvailable, Status: available},
+					{Type: configv1.OperatorProgressing, Status: progressing},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		operators []runtime.Object
+		expected  []string
+	}{
+		{
+			name: "available and not progressing",
+			operators: []runtime.Object{
+				clusterOperator("etcd", configv1.ConditionTrue, configv1.ConditionFalse),
+				clusterOperator("kube-apiserver", configv1.ConditionTrue, configv1.ConditionFalse),
+			},
+		},
+		{
+			name: "progressing operator is unsettled",
+			operators: []runtime.Object{
+				clusterOperator("etcd", configv1.ConditionTrue, configv1.ConditionTrue),
+				clusterOperator("kube-apiserver", configv1.ConditionTrue, configv1.ConditionFalse),
+			},
+			expected: []string{"etcd (available=true, progressing=true)"},
+		},
+		{
+			name: "unavailable and missing operators are unsettled",
+			operators: []runtime.Object{
+				clusterOperator("etcd", configv1.ConditionFalse, configv1.ConditionFalse),
+			},
+			expected: []string{"etcd (available=false, progressing=false)", "kube-apiserver (not found)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := openshift.NewOperatorManager(configfake.NewSimpleClientset(tt.operators...))
+			unsettled, err := manager.UnsettledOperators(context.Background(), openshift.ControlPlaneOperators)
+			if err != nil {
+				t.Fatalf("UnsettledOperators failed: %v", err)
+			}
+			if !reflect.DeepEqual(unsettled, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, unsettled)
+			}
+		})
+	}
+}
+
+func TestWorkerPlacements(t *testing.T) {
+	replicas := func(n int32) *int32 { return &n }
+
+	tests := []struct {
+		name      string
+		config    migrationv1alpha1.MachineSetConfig
+		expected  []openshift.WorkerPlacement
+		expectErr bool
+	}{
+		{
+			name:     "single failure domain",
+			config:   migrationv1alpha1.MachineSetConfig{Replicas: 3, FailureDomain: "fd-a"},
+			expected: []openshift.WorkerPlacement{{FailureDomain: "fd-a", Replicas: 3}},
+		},
+		{
+			name: "total spread evenly with remainder first",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas: 5,
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{
+					{Name: "fd-a"}, {Name: "fd-b"}, {Name: "fd-c"},
+				},
+			},
+			expected: []openshift.WorkerPlacement{
+				{FailureDomain: "fd-a", Replicas: 2},
+				{FailureDomain: "fd-b", Replicas: 2},
+				{FailureDomain: "fd-c", Replicas: 1},
+			},
+		},
+		{
+			name: "explicit counts with remainder shared",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas: 6,
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{
+					{Name: "fd-a", Replicas: replicas(4)}, {Name: "fd-b"},
+				},
+			},
+			expected: []openshift.WorkerPlacement{
+				{FailureDomain: "fd-a", Replicas: 4},
+				{FailureDomain: "fd-b", Replicas: 2},
+			},
+		},
+		{
+			name: "explicit counts without total",
+			config: migrationv1alpha1.MachineSetConfig{
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{
+					{Name: "fd-a", Replicas: replicas(1)}, {Name: "fd-b", Replicas: replicas(2)},
+				},
+			},
+			expected: []openshift.WorkerPlacement{
+				{FailureDomain: "fd-a", Replicas: 1},
+				{FailureDomain: "fd-b", Replicas: 2},
+			},
+		},
+		{
+			name: "explicit counts disagree with total",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas: 4,
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{
+					{Name: "fd-a", Replicas: replicas(1)}, {Name: "fd-b", Replicas: replicas(2)},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "too few replicas to spread",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas:       1,
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{{Name: "fd-a"}, {Name: "fd-b"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "duplicate failure domain",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas:       2,
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{{Name: "fd-a"}, {Name: "fd-a"}},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "no failure domain",
+			config:    migrationv1alpha1.MachineSetConfig{Replicas: 3},
+			expectErr: true,
+		},
+		{
+			name: "custom labels and annotations",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas:              3,
+				FailureDomain:         "fd-a",
+				MachineSetLabels:      map[string]string{"team": "storage"},
+				MachineSetAnnotations: map[string]string{"machine.openshift.io/cluster-api-autoscaler-node-group-max-size": "6"},
+			},
+			expected: []openshift.WorkerPlacement{{FailureDomain: "fd-a", Replicas: 3}},
+		},
+		{
+			name: "reserved label",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas:         3,
+				FailureDomain:    "fd-a",
+				MachineSetLabels: map[string]string{openshift.MachineRoleLabel: "infra"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "reserved annotation",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas:              3,
+				FailureDomain:         "fd-a",
+				MachineSetAnnotations: map[string]string{openshift.FailureDomainLabel: "fd-b"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid label value",
+			config: migrationv1alpha1.MachineSetConfig{
+				Replicas:         3,
+				FailureDomain:    "fd-a",
+				MachineSetLabels: map[string]string{"team": "storage and backup"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placements, err := openshift.WorkerPlacements(tt.config)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("expected error, got placements %+v", placements)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("WorkerPlacements failed: %v", err)
+			}
+			if !reflect.DeepEqual(placements, tt.expected) {
+				t.Errorf("expected placements %+v, got %+v", tt.expected, placements)
+			}
+		})
+	}
+}
+
+func TestSurgePlacements(t *testing.T) {
+	zero := int32(0)
+
+	placements, err := openshift.SurgePlacements(migrationv1alpha1.MachineSetConfig{
+		Replicas:      4,
+		SurgeReplicas: 3,
+		FailureDomains: []migrationv1alpha1.WorkerFailureDomain{
+			{Name: "fd-a"}, {Name: "fd-b", Replicas: &zero}, {Name: "fd-c"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SurgePlacements failed: %v", err)
+	}
+	// The failure domain without workers gets no surge
+	expected := []openshift.WorkerPlacement{
+		{FailureDomain: "fd-a", Replicas: 4},
+		{FailureDomain: "fd-b", Replicas: 0},
+		{FailureDomain: "fd-c", Replicas: 3},
+	}
+	if !reflect.DeepEqual(placements, expected) {
+		t.Errorf("expected placements %+v, got %+v", expected, placements)
+	}
+
+	if _, err := openshift.SurgePlacements(migrationv1alpha1.MachineSetConfig{Replicas: 3, FailureDomain: "fd-a", SurgeReplicas: -1}); err == nil {
+		t.Error("expected negative surge replicas to be rejected")
+	}
+}
+
+func TestCreateWorkersPhase_MultipleFailureDomains(t *testing.T) {
+	ctx := context.Background()
+
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{InfrastructureName: "test-infra"},
+	}
+	template := &machinev1beta1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-infra-worker-0",
+			Namespace: openshift.MachineAPINamespace,
+		},
+		Spec: machinev1beta1.MachineSetSpec{
+			Template: machinev1beta1.MachineTemplateSpec{
+				Spec: machinev1beta1.MachineSpec{
+					ProviderSpec: machinev1beta1.ProviderSpec{
+						Value: &runtime.RawExtension{Raw: []byte(`{"workspace":{"server":"old-vcenter.example.com"},"network":{"devices":[{"networkName":"old"}]}}`)},
+					},
+				},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	machineClient := machinefake.NewSimpleClientset(template)
+	kubeClient := kubefake.NewSimpleClientset()
+	executor := phases.NewPhaseExecutor(
+		kubeClient,
+		configfake.NewSimpleClientset(infra),
+		apiextensionsfake.NewSimpleClientset(),
+		machineClient,
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+
+	failureDomain := func(name string) configv1.VSpherePlatformFailureDomainSpec {
+		return configv1.VSpherePlatformFailureDomainSpec{
+			Name:   name,
+			Server: "new-vcenter.example.com",
+			Topology: configv1.VSpherePlatformTopology{
+				Datacenter:     "DC1",
+				ComputeCluster: "/DC1/host/" + name,
+				Datastore:      "/DC1/datastore/ds1",
+				Networks:       []string{"VM Network"},
+				Template:       "/DC1/vm/rhcos",
+			},
+		}
+	}
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-migration",
+			Namespace: "vmware-cloud-foundation-migration",
+		},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{failureDomain("fd-a"), failureDomain("fd-b")},
+			MachineSetConfig: migrationv1alpha1.MachineSetConfig{
+				Replicas: 3,
+				FailureDomains: []migrationv1alpha1.WorkerFailureDomain{
+					{Name: "fd-a"}, {Name: "fd-b"},
+				},
+				MachineSetLabels:      map[string]string{"team": "storage"},
+				MachineSetAnnotations: map[string]string{"cost-center": "1234"},
+			},
+		},
+	}
+
+	phase := phases.NewCreateWorkersPhase(executor)
+	if err := phase.Validate(ctx, migration); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	result, err := phase.Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusRunning {
+		t.Errorf("expected status %s, got %s (%s)", migrationv1alpha1.PhaseStatusRunning, result.Status, result.Message)
+	}
+
+	for name, expectedReplicas := range map[string]int32{"test-infra-worker-fd-a": 2, "test-infra-worker-fd-b": 1} {
+		ms, err := machineClient.MachineV1beta1().MachineSets(openshift.MachineAPINamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected MachineSet %s to be created: %v", name, err)
+		}
+		if ms.Spec.Replicas == nil || *ms.Spec.Replicas != expectedReplicas {
+			t.Errorf("expected MachineSet %s to have %d replicas, got %v", name, expectedReplicas, ms.Spec.Replicas)
+		}
+		if ms.Labels["team"] != "storage" || ms.Spec.Template.Labels["team"] != "storage" {
+			t.Errorf("expected MachineSet %s and its machine template to carry the custom label, got %v and %v",
+				name, ms.Labels, ms.Spec.Template.Labels)
+		}
+		if ms.Annotations["cost-center"] != "1234" {
+			t.Errorf("expected MachineSet %s to carry the custom annotation, got %v", name, ms.Annotations)
+		}
+	}
+
+	// Readiness is summed across both MachineSets and broken down by failure domain
+	addReadyMachine := func(name, machineSet string) {
+		t.Helper()
+		_, err := machineClient.MachineV1beta1().Machines(openshift.MachineAPINamespace).Create(ctx, &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: openshift.MachineAPINamespace,
+				Labels:    map[string]string{openshift.MachineSetLabel: machineSet},
+			},
+			Status: machinev1beta1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: name},
+				Phase:   ptr.To("Running"),
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create machine %s: %v", name, err)
+		}
+		_, err = kubeClient.CoreV1().Nodes().Create(ctx, &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			t.Fatalf("Failed to create node %s: %v", name, err)
+		}
+	}
+	addReadyMachine("worker-a-0", "test-infra-worker-fd-a")
+	addReadyMachine("worker-a-1", "test-infra-worker-fd-a")
+
+	result, err = phase.Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusRunning {
+		t.Errorf("expected status %s while fd-b has no machines, got %s (%s)",
+			migrationv1alpha1.PhaseStatusRunning, result.Status, result.Message)
+	}
+	if !strings.Contains(result.Message, "2/3 ready") || !strings.Contains(result.Message, "fd-a 2/2, fd-b 0/1") {
+		t.Errorf("expected aggregate and per failure domain machine counts, got %q", result.Message)
+	}
+	if result.Progress != 33 {
+		t.Errorf("expected progress 33, got %d", result.Progress)
+	}
+
+	addReadyMachine("worker-b-0", "test-infra-worker-fd-b")
+
+	result, err = phase.Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusCompleted {
+		t.Errorf("expected status %s once every failure domain is ready, got %s (%s)",
+			migrationv1alpha1.PhaseStatusCompleted, result.Status, result.Message)
+	}
+
+	if err := phase.Rollback(ctx, migration); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	list, err := machineClient.MachineV1beta1().MachineSets(openshift.MachineAPINamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list MachineSets: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != template.Name {
+		t.Errorf("expected only the template MachineSet to remain after rollback, got %d MachineSets", len(list.Items))
+	}
+}
+
+func TestScaleOldMachinesPhase_ChecksNewWorkers(t *testing.T) {
+	ctx := context.Background()
+
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			PlatformSpec: configv1.PlatformSpec{
+				Type: configv1.VSpherePlatformType,
+				VSphere: &configv1.VSpherePlatformSpec{
+					VCenters: []configv1.VSpherePlatformVCenterSpec{{Server: "old-vcenter.example.com"}},
+				},
+			},
+		},
+		Status: configv1.InfrastructureStatus{InfrastructureName: "test-infra"},
+	}
+	machineSet := func(name, server string, taints []corev1.Taint) *machinev1beta1.MachineSet {
+		return &machinev1beta1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: openshift.MachineAPINamespace},
+			Spec: machinev1beta1.MachineSetSpec{
+				Replicas: ptr.To[int32](1),
+				Template: machinev1beta1.MachineTemplateSpec{
+					Spec: machinev1beta1.MachineSpec{
+						Taints: taints,
+						ProviderSpec: machinev1beta1.ProviderSpec{
+							Value: &runtime.RawExtension{Raw: []byte(`{"workspace":{"server":"` + server + `"}}`)},
+						},
+					},
+				},
+			},
+		}
+	}
+	machine := func(name, machineSet string) *machinev1beta1.Machine {
+		return &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: openshift.MachineAPINamespace,
+				Labels:    map[string]string{openshift.MachineSetLabel: machineSet},
+			},
+			Status: machinev1beta1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: name}},
+		}
+	}
+	node := func(name, cpu string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("16Gi"),
+				},
+			},
+		}
+	}
+	pod := func(name, nodeName, cpu string, owner *metav1.OwnerReference) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{
+					Name:      "app",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		if owner != nil {
+			p.OwnerReferences = []metav1.OwnerReference{*owner}
+		}
+		return p
+	}
+
+	// Infra nodes keep the taint their MachineSet applies on purpose
+	infraTaint := corev1.Taint{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}
+	machineClient := machinefake.NewSimpleClientset(
+		machineSet("test-infra-worker-0", "old-vcenter.example.com", nil),
+		machineSet("test-infra-worker-fd-a", "new-vcenter.example.com", []corev1.Taint{infraTaint}),
+		machine("old-0", "test-infra-worker-0"),
+		machine("new-0", "test-infra-worker-fd-a"))
+	kubeClient := kubefake.NewSimpleClientset(
+		node("old-0", "4"),
+		node("new-0", "4", infraTaint, corev1.Taint{Key: "example.com/no-cni", Effect: corev1.TaintEffectNoSchedule}),
+		pod("app-0", "old-0", "3", nil),
+		// DaemonSet pods go away with their node and are not moved
+		pod("agent-0", "old-0", "8", &metav1.OwnerReference{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "agent", Controller: ptr.To(true)}))
+	executor := phases.NewPhaseExecutor(
+		kubeClient,
+		configfake.NewSimpleClientset(infra),
+		apiextensionsfake.NewSimpleClientset(),
+		machineClient,
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		backup.NewBackupManager(runtime.NewScheme()),
+		nil)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			MachineSetConfig: migrationv1alpha1.MachineSetConfig{Replicas: 1, FailureDomain: "fd-a"},
+		},
+	}
+	phase := phases.NewScaleOldMachinesPhase(executor)
+
+	expectRefused := func(reason string) {
+		t.Helper()
+		result, err := phase.Execute(ctx, migration)
+		if !errors.Is(err, phaseerrors.ErrValidation) {
+			t.Fatalf("Expected a validation error, got %v", err)
+		}
+		if result.Status != migrationv1alpha1.PhaseStatusFailed || !strings.Contains(result.Message, reason) {
+			t.Errorf("Expected the phase to fail with %q, got %s: %s", reason, result.Status, result.Message)
+		}
+		old, err := machineClient.MachineV1beta1().MachineSets(openshift.MachineAPINamespace).Get(ctx, "test-infra-worker-0", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get old MachineSet: %v", err)
+		}
+		if *old.Spec.Replicas != 1 {
+			t.Errorf("Expected the old MachineSet to keep its replicas, got %d", *old.Spec.Replicas)
+		}
+	}
+
+	// The unexpected taint keeps pods off the new node
+	expectRefused("node new-0 is unschedulable: node has taint example.com/no-cni:NoSchedule")
+
+	// Usable, but too small for the pods of the old node
+	if _, err := kubeClient.CoreV1().Nodes().Update(ctx, node("new-0", "2", infraTaint), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+	// The tainted new node holds no general pods, so an untainted one is needed for capacity
+	if _, err := kubeClient.CoreV1().Nodes().Create(ctx, node("spare-0", "2"), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	expectRefused("insufficient capacity without the 1 old worker nodes: pods request 3 CPU but schedulable nodes allocate 2")
+
+	if _, err := kubeClient.CoreV1().Nodes().Update(ctx, node("spare-0", "4"), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update node: %v", err)
+	}
+	result, err := phase.Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusRunning {
+		t.Errorf("Expected the old machines to be scaled down, got %s: %s", result.Status, result.Message)
+	}
+	old, err := machineClient.MachineV1beta1().MachineSets(openshift.MachineAPINamespace).Get(ctx, "test-infra-worker-0", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get old MachineSet: %v", err)
+	}
+	if *old.Spec.Replicas != 0 {
+		t.Errorf("Expected the old MachineSet to be scaled to 0, got %d", *old.Spec.Replicas)
+	}
+}
+
+func TestScaleOldMachinesPhase_SettlesSurge(t *testing.T) {
+	ctx := context.Background()
+
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: configv1.InfrastructureSpec{
+			PlatformSpec: configv1.PlatformSpec{
+				Type: configv1.VSpherePlatformType,
+				VSphere: &configv1.VSpherePlatformSpec{
+					VCenters: []configv1.VSpherePlatformVCenterSpec{{Server: "old-vcenter.example.com"}},
+				},
+			},
+		},
+		Status: configv1.InfrastructureStatus{InfrastructureName: "test-infra"},
+	}
+	machine := func(name string) *machinev1beta1.Machine {
+		return &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: openshift.MachineAPINamespace,
+				Labels:    map[string]string{openshift.MachineSetLabel: "test-infra-worker-fd-a"},
+			},
+		}
+	}
+
+	// The new MachineSet was created with one surge machine and the old machines are gone
+	machineClient := machinefake.NewSimpleClientset(
+		&machinev1beta1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-infra-worker-fd-a", Namespace: openshift.MachineAPINamespace},
+			Spec: machinev1beta1.MachineSetSpec{
+				Replicas: ptr.To[int32](3),
+				Template: machinev1beta1.MachineTemplateSpec{
+					Spec: machinev1beta1.MachineSpec{
+						ProviderSpec: machinev1beta1.ProviderSpec{
+							Value: &runtime.RawExtension{Raw: []byte(`{"workspace":{"server":"new-vcenter.example.com"}}`)},
+						},
+					},
+				},
+			},
+		},
+		machine("new-0"), machine("new-1"), machine("new-2"))
+	executor := phases.NewPhaseExecutor(
+		kubefake.NewSimpleClientset(),
+		configfake.NewSimpleClientset(infra),
+		apiextensionsfake.NewSimpleClientset(),
+		machineClient,
+		dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+		backup.NewBackupManager(runtime.NewScheme()),
+		nil)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			MachineSetConfig: migrationv1alpha1.MachineSetConfig{Replicas: 2, SurgeReplicas: 1, FailureDomain: "fd-a"},
+		},
+	}
+	phase := phases.NewScaleOldMachinesPhase(executor)
+
+	result, err := phase.Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusRunning || !strings.Contains(result.Message, "waiting for 1 surge machines") {
+		t.Errorf("Expected the phase to wait for the surge machine, got %s: %s", result.Status, result.Message)
+	}
+	ms, err := machineClient.MachineV1beta1().MachineSets(openshift.MachineAPINamespace).Get(ctx, "test-infra-worker-fd-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get new MachineSet: %v", err)
+	}
+	if *ms.Spec.Replicas != 2 {
+		t.Errorf("Expected the new MachineSet to be settled to 2 replicas, got %d", *ms.Spec.Replicas)
+	}
+
+	// The machine API removes the surge machine
+	if err := machineClient.MachineV1beta1().Machines(openshift.MachineAPINamespace).Delete(ctx, "new-2", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete machine: %v", err)
+	}
+	migration.Status.CurrentPhaseState = &migrationv1alpha1.PhaseState{
+		Name:      migrationv1alpha1.PhaseScaleOldMachines,
+		Status:    migrationv1alpha1.PhaseStatusRunning,
+		StartTime: &metav1.Time{Time: time.Now()},
+	}
+	result, err = phase.Execute(ctx, migration)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Status != migrationv1alpha1.PhaseStatusCompleted {
+		t.Errorf("Expected the phase to complete once the surge machine is gone, got %s: %s", result.Status, result.Message)
+	}
+}
+
+func TestVerifyPhase_MigratedVolumePods(t *testing.T) {
+	ctx := context.Background()
+
+	pod := func(name string, unschedulableFor time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				Conditions: []corev1.PodCondition{{
+					Type:               corev1.PodScheduled,
+					Status:             corev1.ConditionFalse,
+					Reason:             corev1.PodReasonUnschedulable,
+					Message:            "0/3 nodes are available: pod has unbound immediate PersistentVolumeClaims.",
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-unschedulableFor)),
+				}},
+			},
+		}
+	}
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+	}
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubefake.NewSimpleClientset(
+			&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "pv-migrated",
+					Annotations: map[string]string{openshift.MigrationNameAnnotation: "vmware-cloud-foundation-migration/test-migration"},
+				},
+				Spec: corev1.PersistentVolumeSpec{ClaimRef: &corev1.ObjectReference{Namespace: "app", Name: "data"}},
+			},
+			&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"},
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+			},
+			// Rescheduled a moment ago, its PVC may still bind
+			pod("web-0", 10*time.Second),
+			pod("web-1", phases.PendingPodGracePeriod+time.Minute)),
+		configfake.NewSimpleClientset(),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+
+	stuck, settling, err := phases.NewVerifyPhase(executor).MigratedVolumePods(ctx, migration)
+	if err != nil {
+		t.Fatalf("MigratedVolumePods failed: %v", err)
+	}
+	if len(stuck) != 1 || stuck[0].Name != "web-1" {
+		t.Errorf("Expected only web-1 to be stuck, got %+v", stuck)
+	}
+	if len(settling) != 1 || settling[0].Name != "web-0" {
+		t.Errorf("Expected web-0 to be given time to be scheduled, got %+v", settling)
+	}
+}
+
+func TestVerifyPhase_CSIControllerRestartTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	scheme := runtime.NewScheme()
+	executor := phases.NewPhaseExecutor(
+		kubefake.NewSimpleClientset(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: openshift.CSIDriverConfigSecretName, Namespace: openshift.CSIDriverNamespace},
+				Data: map[string][]byte{openshift.CSIDriverConfigKey: []byte(`[Global]
+cluster-id = "test"
+
+[VirtualCenter "target.example.com"]
+datacenters = "DC1"
+`)},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "vmware-vsphere-csi-driver-controller-1",
+					Namespace:         openshift.CSIDriverNamespace,
+					Labels:            map[string]string{"app": "vmware-vsphere-csi-driver-controller"},
+					CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+				},
+			},
+		),
+		configfake.NewSimpleClientset(),
+		apiextensionsfake.NewSimpleClientset(),
+		machinefake.NewSimpleClientset(),
+		dynamicfake.NewSimpleDynamicClient(scheme),
+		backup.NewBackupManager(scheme),
+		nil)
+	phase := phases.NewVerifyPhase(executor)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{Name: "fd1", Server: "target.example.com"}},
+		},
+	}
+
+	// The controller pod predates the update, so Verify waits for it to restart
+	migration.Status.CSIDriverConfigUpdateTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	result, err := phase.VerifyCSIDriverConfig(ctx, migration)
+	if err != nil {
+		t.Fatalf("VerifyCSIDriverConfig failed: %v", err)
+	}
+	if result == nil || result.Status != migrationv1alpha1.PhaseStatusRunning {
+		t.Fatalf("Expected Verify to wait for the controller restart, got %+v", result)
+	}
+
+	// It gives up once the restart is overdue rather than waiting forever
+	migration.Status.CSIDriverConfigUpdateTime = &metav1.Time{Time: time.Now().Add(-phases.CSIControllerRestartTimeout - time.Minute)}
+	result, err = phase.VerifyCSIDriverConfig(ctx, migration)
+	if err == nil || result == nil || result.Status != migrationv1alpha1.PhaseStatusFailed {
+		t.Fatalf("Expected Verify to fail once the restart timed out, got %+v, %v", result, err)
+	}
+	if !strings.Contains(result.Message, "predates") {
+		t.Errorf("Expected the failure to say what is pending, got: %s", result.Message)
+	}
+
+	// Without a config update there is nothing to wait for
+	migration.Status.CSIDriverConfigUpdateTime = nil
+	if result, err := phase.VerifyCSIDriverConfig(ctx, migration); result != nil || err != nil {
+		t.Errorf("Expected no wait without a config update, got %+v, %v", result, err)
+	}
+}
+
+func TestVerifyPhase_VerifyMachines(t *testing.T) {
+	ctx := context.Background()
+
+	providerSpec := func(server, datacenter string) machinev1beta1.ProviderSpec {
+		return machinev1beta1.ProviderSpec{
+			Value: &runtime.RawExtension{Raw: []byte(`{"workspace":{"server":"` + server + `","datacenter":"` + datacenter + `"}}`)},
+		}
+	}
+	machineSet := func(name, server string, replicas int32) *machinev1beta1.MachineSet {
+		return &machinev1beta1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: openshift.MachineAPINamespace},
+			Spec: machinev1beta1.MachineSetSpec{
+				Replicas: &replicas,
+				Template: machinev1beta1.MachineTemplateSpec{
+					Spec: machinev1beta1.MachineSpec{ProviderSpec: providerSpec(server, "DC1")},
+				},
+			},
+		}
+	}
+	machine := func(name, role, machineSetName, server, datacenter, nodeName string) *machinev1beta1.Machine {
+		m := &machinev1beta1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: openshift.MachineAPINamespace,
+				Labels:    map[string]string{openshift.MachineRoleLabel: role},
+			},
+			Spec: machinev1beta1.MachineSpec{ProviderSpec: providerSpec(server, datacenter)},
+		}
+		if machineSetName != "" {
+			m.Labels[openshift.MachineSetLabel] = machineSetName
+		}
+		if nodeName != "" {
+			m.Status.NodeRef = &corev1.ObjectReference{Name: nodeName}
+		}
+		return m
+	}
+	node := func(name string, ready corev1.ConditionStatus) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	cpms := func(replicas, updated, ready int64) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "machine.openshift.io/v1",
+			"kind":       "ControlPlaneMachineSet",
+			"metadata":   map[string]interface{}{"name": "cluster", "namespace": openshift.MachineAPINamespace},
+			"status":     map[string]interface{}{"replicas": replicas, "updatedReplicas": updated, "readyReplicas": ready},
+		}}
+	}
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-migration", Namespace: "vmware-cloud-foundation-migration"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{{
+				Name:     "fd-a",
+				Server:   "new-vcenter.example.com",
+				Topology: configv1.VSpherePlatformTopology{Datacenter: "DC1"},
+			}},
+			ControlPlaneMachineSetConfig: migrationv1alpha1.ControlPlaneMachineSetConfig{FailureDomain: "fd-a"},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{SourceVCenter: "old-vcenter.example.com"},
+	}
+
+	newPhase := func(kubeObjects []runtime.Object, machineObjects []runtime.Object, cpmsObject *unstructured.Unstructured) *phases.VerifyPhase {
+		scheme := runtime.NewScheme()
+		executor := phases.NewPhaseExecutor(
+			kubefake.NewSimpleClientset(kubeObjects...),
+			configfake.NewSimpleClientset(),
+			apiextensionsfake.NewSimpleClientset(),
+			machinefake.NewSimpleClientset(machineObjects...),
+			dynamicfake.NewSimpleDynamicClient(scheme, cpmsObject),
+			backup.NewBackupManager(scheme),
+			nil)
+		return phases.NewVerifyPhase(executor)
+	}
+
+	t.Run("migrated cluster has no discrepancies", func(t *testing.T) {
+		phase := newPhase(
+			[]runtime.Object{node("worker-0", corev1.ConditionTrue), node("master-0", corev1.ConditionTrue)},
+			[]runtime.Object{
+				machineSet("old-worker", "old-vcenter.example.com", 0),
+				machineSet("new-worker", "new-vcenter.example.com", 1),
+				machine("new-worker-0", "worker", "new-worker", "new-vcenter.example.com", "DC1", "worker-0"),
+				machine("master-0", "master", "", "new-vcenter.example.com", "DC1", "master-0"),
+			},
+			cpms(1, 1, 1))
+
+		discrepancies, err := phase.VerifyMachines(ctx, migration)
+		if err != nil {
+			t.Fatalf("VerifyMachines failed: %v", err)
+		}
+		if len(discrepancies) != 0 {
+			t.Errorf("Expected no discrepancies, got %v", discrepancies)
+		}
+	})
+
+	t.Run("leftover and unready machines are reported", func(t *testing.T) {
+		phase := newPhase(
+			[]runtime.Object{node("worker-0", corev1.ConditionTrue), node("worker-1", corev1.ConditionFalse), node("master-0", corev1.ConditionTrue)},
+			[]runtime.Object{
+				machineSet("old-worker", "old-vcenter.example.com", 1),
+				machine("old-worker-0", "worker", "old-worker", "old-vcenter.example.com", "DC0", ""),
+				machineSet("new-worker", "new-vcenter.example.com", 3),
+				machine("new-worker-0", "worker", "new-worker", "new-vcenter.example.com", "DC1", "worker-0"),
+				machine("new-worker-1", "worker", "new-worker", "new-vcenter.example.com", "DC1", "worker-1"),
+				machine("new-worker-2", "worker", "new-worker", "new-vcenter.example.com", "DC1", ""),
+				machine("master-0", "master", "", "new-vcenter.example.com", "DC2", "master-0"),
+			},
+			cpms(3, 1, 3))
+
+		discrepancies, err := phase.VerifyMachines(ctx, migration)
+		if err != nil {
+			t.Fatalf("VerifyMachines failed: %v", err)
+		}
+
+		joined := strings.Join(discrepancies, "\n")
+		for _, want := range []string{
+			"MachineSet old-worker on source vCenter old-vcenter.example.com still has 1 replicas",
+			"Machine old-worker-0 still references source vCenter",
+			"MachineSet new-worker has 1 of 3 worker nodes Ready: new-worker-1 (node worker-1 not Ready), new-worker-2 (no node)",
+			"Control plane rollout incomplete",
+			"Control plane machine master-0 is in new-vcenter.example.com/DC2",
+		} {
+			if !strings.Contains(joined, want) {
+				t.Errorf("Expected a discrepancy containing %q, got:\n%s", want, joined)
+			}
+		}
+		if len(discrepancies) != 5 {
+			t.Errorf("Expected 5 discrepancies, got %d:\n%s", len(discrepancies), joined)
+		}
+	})
+}
+
+func TestRestoreVCenterValidations(t *testing.T) {
+	vcenters := apiextensionsv1.JSONSchemaProps{
+		Type: "array",
+		XValidations: apiextensionsv1.ValidationRules{
+			{Rule: "size(self) != 2 || self[0].server != self[1].server", Message: "vcenters must be unique"},
+		},
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: openshift.InfrastructureCRDName},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name: "v1",
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+						Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"spec": {Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"platformSpec": {Properties: map[string]apiextensionsv1.JSONSchemaProps{
+									"vsphere": {Properties: map[string]apiextensionsv1.JSONSchemaProps{
+										"vcenters": vcenters,
+									}},
+								}},
+							}},
+						},
+					},
+				},
+			}},
+		},
+	}
+	ctx := context.Background()
+	infraManager := openshift.NewInfrastructureManagerWithClients(configfake.NewSimpleClientset(), kubefake.NewSimpleClientset(),
+		apiextensionsfake.NewSimpleClientset(crd))
+
+	recorded, err := infraManager.VCenterValidations(ctx)
+	if err != nil {
+		t.Fatalf("VCenterValidations failed: %v", err)
+	}
+	if len(recorded) != 1 || recorded["v1"] == "" {
+		t.Fatalf("Expected validations recorded for v1, got %v", recorded)
+	}
+
+	// Simulate a controller that stopped while UpdateInfrastructure had the validations removed
+	if err := infraManager.ModifyInfrastructureCRDToAllowVCenterChanges(ctx); err != nil {
+		t.Fatalf("Failed to remove validations: %v", err)
+	}
+	missing, err := infraManager.MissingVCenterValidations(ctx, recorded)
+	if err != nil {
+		t.Fatalf("MissingVCenterValidations failed: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"v1"}) {
+		t.Fatalf("Expected v1 to be missing its validations, got %v", missing)
+	}
+
+	restored, err := infraManager.RestoreVCenterValidations(ctx, recorded)
+	if err != nil {
+		t.Fatalf("RestoreVCenterValidations failed: %v", err)
+	}
+	if !reflect.DeepEqual(restored, []string{"v1"}) {
+		t.Errorf("Expected v1 to be restored, got %v", restored)
+	}
+	current, err := infraManager.VCenterValidations(ctx)
+	if err != nil {
+		t.Fatalf("VCenterValidations failed: %v", err)
+	}
+	if !reflect.DeepEqual(current, recorded) {
+		t.Errorf("Expected restored validations %v, got %v", recorded, current)
+	}
+
+	// Nothing is restored once the validations are back
+	if restored, err := infraManager.RestoreVCenterValidations(ctx, recorded); err != nil || len(restored) != 0 {
+		t.Errorf("Expected no versions restored, got %v, %v", restored, err)
+	}
+}
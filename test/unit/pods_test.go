@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+func TestPendingPodsOnMigratedVolumes(t *testing.T) {
+	ctx := context.Background()
+
+	pod := func(name, claim string, phase corev1.PodPhase, message string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase: phase,
+				Conditions: []corev1.PodCondition{{
+					Type:    corev1.PodScheduled,
+					Status:  corev1.ConditionFalse,
+					Reason:  corev1.PodReasonUnschedulable,
+					Message: message,
+				}},
+			},
+		}
+	}
+	unbound := "0/3 nodes are available: pod has unbound immediate PersistentVolumeClaims."
+
+	// The migrated PV still claims the PVC that was deleted, so the restored PVC cannot bind
+	client := kubefake.NewSimpleClientset(
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pv-migrated",
+				Annotations: map[string]string{openshift.MigrationNameAnnotation: "ns/mig"},
+			},
+			Spec: corev1.PersistentVolumeSpec{
+				ClaimRef: &corev1.ObjectReference{Namespace: "app", Name: "data", UID: "old-uid"},
+			},
+			Status: corev1.PersistentVolumeStatus{Phase: corev1.VolumeReleased},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app", UID: "new-uid"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "app"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		pod("web-0", "data", corev1.PodPending, unbound),
+		// Pending for a reason unrelated to volumes
+		pod("web-1", "data", corev1.PodPending, "0/3 nodes are available: 3 Insufficient cpu."),
+		// Pending on a volume this migration did not move
+		pod("other-0", "unrelated", corev1.PodPending, unbound),
+		pod("web-2", "data", corev1.PodRunning, ""))
+
+	pending, err := openshift.NewPodManager(client).PendingPodsOnMigratedVolumes(ctx, "ns/mig")
+	if err != nil {
+		t.Fatalf("PendingPodsOnMigratedVolumes failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Name != "web-0" {
+		t.Fatalf("Expected only pod web-0 to be reported, got %+v", pending)
+	}
+	want := "PVC app/data Pending with no volume, PV pv-migrated Released claiming it for the earlier PVC uid old-uid"
+	if len(pending[0].Volumes) != 1 || pending[0].Volumes[0] != want {
+		t.Errorf("Expected volume state %q, got %v", want, pending[0].Volumes)
+	}
+	if pending[0].Reason != unbound {
+		t.Errorf("Expected the scheduler message as reason, got %q", pending[0].Reason)
+	}
+
+	if pending, err := openshift.NewPodManager(client).PendingPodsOnMigratedVolumes(ctx, "ns/other"); err != nil || len(pending) != 0 {
+		t.Errorf("Expected no pods for another migration, got %+v, %v", pending, err)
+	}
+}
@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+)
+
+func TestBackoff_Presets(t *testing.T) {
+	for name, backoff := range map[string]wait.Backoff{
+		"fast":    util.FastBackoff,
+		"default": util.DefaultBackoff,
+		"slow":    util.SlowBackoff,
+	} {
+		if backoff.Jitter <= 0 {
+			t.Errorf("%s backoff should use jitter", name)
+		}
+
+		// The first poll after the immediate check must come quickly, later polls back off up to
+		// the cap and keep polling at it
+		backoff.Jitter = 0
+		next := backoff.DelayFunc()
+		if first := next(); first > 5*time.Second {
+			t.Errorf("%s backoff first interval %s is too slow", name, first)
+		}
+		var interval time.Duration
+		for range 20 {
+			interval = next()
+		}
+		if interval != backoff.Cap {
+			t.Errorf("%s backoff should keep polling at its cap %s, got %s", name, backoff.Cap, interval)
+		}
+	}
+}
+
+func TestPollUntil(t *testing.T) {
+	ctx := context.Background()
+	fast := wait.Backoff{Duration: 10 * time.Millisecond, Factor: 2, Jitter: 0.5, Steps: math.MaxInt32, Cap: 40 * time.Millisecond}
+
+	t.Run("checks immediately", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := util.PollUntil(ctx, util.SlowBackoff, time.Minute, func(ctx context.Context) (bool, error) {
+			calls++
+			return true, nil
+		})
+		if err != nil {
+			t.Fatalf("Expected success, got: %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected an immediate return, took %s", elapsed)
+		}
+	})
+
+	t.Run("waits between polls within jitter bounds", func(t *testing.T) {
+		var times []time.Time
+		err := util.PollUntil(ctx, fast, time.Minute, func(ctx context.Context) (bool, error) {
+			times = append(times, time.Now())
+			return len(times) == 5, nil
+		})
+		if err != nil {
+			t.Fatalf("Expected success, got: %v", err)
+		}
+
+		minWait := fast.Duration
+		for i := 1; i < len(times); i++ {
+			gap := times[i].Sub(times[i-1])
+			if gap < minWait {
+				t.Errorf("Poll %d came after %s, expected at least %s", i, gap, minWait)
+			}
+			minWait = min(time.Duration(float64(minWait)*fast.Factor), fast.Cap)
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		calls := 0
+		err := util.PollUntil(ctx, fast, 100*time.Millisecond, func(ctx context.Context) (bool, error) {
+			calls++
+			return false, nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+		}
+		if calls < 2 {
+			t.Errorf("Expected several polls before timing out, got %d", calls)
+		}
+	})
+
+	t.Run("zero timeout checks once", func(t *testing.T) {
+		calls := 0
+		err := util.PollUntil(ctx, fast, 0, func(ctx context.Context) (bool, error) {
+			calls++
+			return false, nil
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got: %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("condition error aborts", func(t *testing.T) {
+		conditionErr := errors.New("boom")
+		calls := 0
+		err := util.PollUntil(ctx, fast, time.Minute, func(ctx context.Context) (bool, error) {
+			calls++
+			return false, conditionErr
+		})
+		if !errors.Is(err, conditionErr) {
+			t.Fatalf("Expected condition error, got: %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("context cancellation", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		err := util.PollUntil(cancelCtx, fast, time.Minute, func(ctx context.Context) (bool, error) {
+			return false, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got: %v", err)
+		}
+	})
+}
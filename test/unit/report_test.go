@@ -0,0 +1,135 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/report"
+)
+
+func TestGenerateReport(t *testing.T) {
+	start := metav1.NewTime(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	preflightDone := metav1.NewTime(start.Add(90 * time.Second))
+	failedAt := metav1.NewTime(start.Add(time.Hour))
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-migration", Namespace: "openshift-config"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			FailureDomains: []configv1.VSpherePlatformFailureDomainSpec{
+				{
+					Name:   "us-east-1a",
+					Server: "vcenter-new.example.com",
+					Topology: configv1.VSpherePlatformTopology{
+						Datacenter:     "DC2",
+						ComputeCluster: "/DC2/host/cluster1",
+						Datastore:      "/DC2/datastore/ds1",
+					},
+				},
+			},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase:          migrationv1alpha1.PhaseFailed,
+			SourceVCenter:  "vcenter-old.example.com",
+			StartTime:      &start,
+			CompletionTime: &failedAt,
+			PhaseHistory: []migrationv1alpha1.PhaseHistoryEntry{
+				{
+					Phase:          migrationv1alpha1.PhasePreflight,
+					Status:         migrationv1alpha1.PhaseStatusCompleted,
+					StartTime:      start,
+					CompletionTime: &preflightDone,
+				},
+				{
+					Phase:          migrationv1alpha1.PhaseMigrateCSIVolumes,
+					Status:         migrationv1alpha1.PhaseStatusFailed,
+					StartTime:      preflightDone,
+					CompletionTime: &failedAt,
+					Message:        "1 volume failed",
+				},
+			},
+			CSIVolumeMigration: &migrationv1alpha1.CSIVolumeMigrationStatus{
+				TotalVolumes:    2,
+				MigratedVolumes: 1,
+				FailedVolumes:   1,
+				Volumes: []migrationv1alpha1.PVMigrationState{
+					{
+						PVName:           "pvc-1",
+						PVCName:          "data",
+						PVCNamespace:     "app",
+						SourceVolumePath: "11111111-2222-3333-4444-555555555555",
+						TargetVolumeID:   "66666666-7777-8888-9999-000000000000",
+						Status:           "Complete",
+						Duration:         &metav1.Duration{Duration: 5 * time.Minute},
+					},
+					{
+						PVName:           "pvc-2",
+						SourceVolumePath: "aaaaaaaa-bbbb-cccc-dddd-eeeeeeeeeeee",
+						Status:           "Failed",
+						Message:          "Failed to relocate volume",
+					},
+				},
+				ManualInterventionRequired: []migrationv1alpha1.VolumeIntervention{
+					{
+						PVName:     "pvc-2",
+						FailedStep: "PVCDeleted",
+						ScaledDownResources: []migrationv1alpha1.ScaledResource{
+							{Kind: "Deployment", Namespace: "app", Name: "db"},
+						},
+						Hint: "Check the volume on the source vCenter",
+					},
+				},
+				MissingSourceVolumes: []migrationv1alpha1.VolumeIntervention{
+					{
+						PVName:     "pvc-3",
+						FailedStep: "Pending",
+						Error:      "FCD not found",
+						Hint:       "Delete the PV once its data is known to be gone",
+					},
+				},
+			},
+		},
+	}
+
+	data := report.Generate(migration)
+
+	expectLine := func(key string, fields ...string) {
+		t.Helper()
+		for _, line := range strings.Split(data[key], "\n") {
+			matches := true
+			for _, field := range fields {
+				if !strings.Contains(line, field) {
+					matches = false
+					break
+				}
+			}
+			if matches {
+				return
+			}
+		}
+		t.Errorf("Expected a %s line with %v, got:\n%s", key, fields, data[key])
+	}
+
+	expectLine(report.KeySummary, "migration=openshift-config/my-migration", "result=Failed",
+		"failedPhase=MigrateCSIVolumes", `message="1 volume failed"`, "duration=1h0m0s", "failedVolumes=1")
+	expectLine(report.KeyVCenters, "role=source", "server=vcenter-old.example.com")
+	expectLine(report.KeyVCenters, "role=target", "failureDomain=us-east-1a", "server=vcenter-new.example.com", "datacenter=DC2")
+	expectLine(report.KeyPhases, "phase=Preflight", "status=Completed", "start=2026-03-01T10:00:00Z", "duration=1m30s")
+	expectLine(report.KeyPhases, "phase=MigrateCSIVolumes", "status=Failed")
+	expectLine(report.KeyVolumes, "pv=pvc-1", "pvc=app/data", "status=Complete",
+		"sourceVolumeHandle=11111111-2222-3333-4444-555555555555", "targetVolumeID=66666666-7777-8888-9999-000000000000", "duration=5m0s")
+	expectLine(report.KeyVolumes, "pv=pvc-2", `pvc=""`, "status=Failed")
+	expectLine(report.KeyManualIntervention, "pv=pvc-2", "failedStep=PVCDeleted", "scaledDown=Deployment/app/db")
+	expectLine(report.KeyManualIntervention, "pv=pvc-3", "sourceMissing=true", "foundAtStep=Pending")
+
+	if got := strings.Count(data[report.KeyPhases], "\n") + 1; got != 2 {
+		t.Errorf("Expected one line per phase, got %d", got)
+	}
+	if !report.IsTerminal(migrationv1alpha1.PhaseCompleted) || report.IsTerminal(migrationv1alpha1.PhaseMigrateCSIVolumes) {
+		t.Error("Expected only Completed and Failed to be terminal")
+	}
+}
@@ -0,0 +1,464 @@
+package unit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/state"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/notify"
+)
+
+func TestShouldExecutePhase_ApprovalGates(t *testing.T) {
+	tests := []struct {
+		name         string
+		approvalMode migrationv1alpha1.ApprovalMode
+		gated        []migrationv1alpha1.MigrationPhase
+		phase        migrationv1alpha1.MigrationPhase
+		phaseState   *migrationv1alpha1.PhaseState
+		expected     bool
+	}{
+		{
+			name:         "automatic mode runs ungated phase",
+			approvalMode: migrationv1alpha1.ApprovalModeAutomatic,
+			gated:        []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseCleanup},
+			phase:        migrationv1alpha1.PhaseBackup,
+			expected:     true,
+		},
+		{
+			name:         "automatic mode stops before gated phase",
+			approvalMode: migrationv1alpha1.ApprovalModeAutomatic,
+			gated:        []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseCleanup},
+			phase:        migrationv1alpha1.PhaseCleanup,
+			expected:     false,
+		},
+		{
+			name:         "gated phase runs once approved",
+			approvalMode: migrationv1alpha1.ApprovalModeAutomatic,
+			gated:        []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseCleanup},
+			phase:        migrationv1alpha1.PhaseCleanup,
+			phaseState: &migrationv1alpha1.PhaseState{
+				Name:             migrationv1alpha1.PhaseCleanup,
+				RequiresApproval: true,
+				Approved:         true,
+			},
+			expected: true,
+		},
+		{
+			name:         "approval of another phase does not open the gate",
+			approvalMode: migrationv1alpha1.ApprovalModeAutomatic,
+			gated:        []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseCleanup},
+			phase:        migrationv1alpha1.PhaseCleanup,
+			phaseState: &migrationv1alpha1.PhaseState{
+				Name:             migrationv1alpha1.PhaseScaleOldMachines,
+				RequiresApproval: true,
+				Approved:         true,
+			},
+			expected: false,
+		},
+		{
+			name:         "manual mode stops before every phase",
+			approvalMode: migrationv1alpha1.ApprovalModeManual,
+			phase:        migrationv1alpha1.PhaseBackup,
+			expected:     false,
+		},
+	}
+
+	sm := state.NewStateMachine(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+				Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+					State:                 migrationv1alpha1.MigrationStateRunning,
+					ApprovalMode:          tt.approvalMode,
+					RequireApprovalBefore: tt.gated,
+				},
+				Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+					Phase:             tt.phase,
+					CurrentPhaseState: tt.phaseState,
+				},
+			}
+
+			if got := sm.ShouldExecutePhase(migration, tt.phase); got != tt.expected {
+				t.Errorf("ShouldExecutePhase() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApprovePhase_TargetedGate(t *testing.T) {
+	sm := state.NewStateMachine(nil)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			State:                 migrationv1alpha1.MigrationStateRunning,
+			ApprovalMode:          migrationv1alpha1.ApprovalModeAutomatic,
+			RequireApprovalBefore: []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhaseUpdateInfrastructure},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhaseUpdateInfrastructure,
+		},
+	}
+
+	// Approval is rejected until the phase is waiting at its gate
+	if err := sm.ApprovePhase(migration, migrationv1alpha1.PhaseUpdateInfrastructure); err == nil {
+		t.Fatal("Expected approval to fail before the phase is waiting")
+	}
+
+	sm.MarkPhaseForApproval(migration, migrationv1alpha1.PhaseUpdateInfrastructure, "Waiting for approval")
+	if sm.ShouldExecutePhase(migration, migrationv1alpha1.PhaseUpdateInfrastructure) {
+		t.Fatal("Expected phase to wait for approval")
+	}
+
+	if err := sm.ApprovePhase(migration, migrationv1alpha1.PhaseUpdateInfrastructure); err != nil {
+		t.Fatalf("ApprovePhase failed: %v", err)
+	}
+	if !sm.ShouldExecutePhase(migration, migrationv1alpha1.PhaseUpdateInfrastructure) {
+		t.Error("Expected approved phase to run")
+	}
+}
+
+func TestRetryFailedPhase(t *testing.T) {
+	sm := state.NewStateMachine(nil)
+	newFailed := func() *migrationv1alpha1.VmwareCloudFoundationMigration {
+		return &migrationv1alpha1.VmwareCloudFoundationMigration{
+			Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+				Phase: migrationv1alpha1.PhaseFailed,
+				CurrentPhaseState: &migrationv1alpha1.PhaseState{
+					Name:   migrationv1alpha1.PhaseMigrateCSIVolumes,
+					Status: migrationv1alpha1.PhaseStatusFailed,
+				},
+				PhaseHistory: []migrationv1alpha1.PhaseHistoryEntry{
+					{Phase: migrationv1alpha1.PhaseBackup, Status: migrationv1alpha1.PhaseStatusCompleted},
+					{Phase: migrationv1alpha1.PhaseMigrateCSIVolumes, Status: migrationv1alpha1.PhaseStatusFailed},
+				},
+			},
+		}
+	}
+
+	t.Run("failed phase is reported", func(t *testing.T) {
+		if phase := sm.FailedPhase(newFailed()); phase != migrationv1alpha1.PhaseMigrateCSIVolumes {
+			t.Errorf("Expected failed phase %s, got %s", migrationv1alpha1.PhaseMigrateCSIVolumes, phase)
+		}
+	})
+
+	t.Run("another phase is rejected", func(t *testing.T) {
+		migration := newFailed()
+		if err := sm.RetryFailedPhase(migration, migrationv1alpha1.PhaseBackup); err == nil {
+			t.Fatal("Expected retry of a completed phase to be rejected")
+		}
+		if migration.Status.Phase != migrationv1alpha1.PhaseFailed {
+			t.Errorf("Expected phase to stay Failed, got %s", migration.Status.Phase)
+		}
+	})
+
+	t.Run("migration that has not failed is rejected", func(t *testing.T) {
+		migration := newFailed()
+		migration.Status.Phase = migrationv1alpha1.PhaseRollingBack
+		if err := sm.RetryFailedPhase(migration, migrationv1alpha1.PhaseMigrateCSIVolumes); err == nil {
+			t.Fatal("Expected retry of a migration that has not failed to be rejected")
+		}
+	})
+
+	t.Run("failed phase is re-entered", func(t *testing.T) {
+		migration := newFailed()
+		if err := sm.RetryFailedPhase(migration, migrationv1alpha1.PhaseMigrateCSIVolumes); err != nil {
+			t.Fatalf("RetryFailedPhase failed: %v", err)
+		}
+		if migration.Status.Phase != migrationv1alpha1.PhaseMigrateCSIVolumes {
+			t.Errorf("Expected phase %s, got %s", migrationv1alpha1.PhaseMigrateCSIVolumes, migration.Status.Phase)
+		}
+		if migration.Status.CurrentPhaseState != nil {
+			t.Error("Expected CurrentPhaseState to be reset")
+		}
+		if len(migration.Status.PhaseHistory) != 2 {
+			t.Error("Expected phase history to be kept")
+		}
+	})
+}
+
+func TestUpdateOverallProgress(t *testing.T) {
+	sm := state.NewStateMachine(nil)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhasePreflight,
+		},
+	}
+
+	sm.UpdateOverallProgress(migration)
+	if migration.Status.OverallProgress != 0 {
+		t.Errorf("Expected no progress at the first phase, got %d", migration.Status.OverallProgress)
+	}
+
+	// The short phases before CreateWorkers are a small share of the migration
+	migration.Status.Phase = migrationv1alpha1.PhaseCreateWorkers
+	sm.UpdateOverallProgress(migration)
+	beforeWorkers := migration.Status.OverallProgress
+	if beforeWorkers <= 0 || beforeWorkers >= 50 {
+		t.Errorf("Expected progress between 0 and 50 before CreateWorkers, got %d", beforeWorkers)
+	}
+
+	// Progress within the current phase counts by its weight
+	migration.Status.CurrentPhaseState = &migrationv1alpha1.PhaseState{
+		Name:     migrationv1alpha1.PhaseCreateWorkers,
+		Status:   migrationv1alpha1.PhaseStatusRunning,
+		Progress: 50,
+	}
+	sm.UpdateOverallProgress(migration)
+	halfway := migration.Status.OverallProgress
+	if halfway <= beforeWorkers {
+		t.Errorf("Expected progress to grow past %d, got %d", beforeWorkers, halfway)
+	}
+
+	// Re-entering an earlier point does not move progress backwards
+	migration.Status.CurrentPhaseState = nil
+	sm.UpdateOverallProgress(migration)
+	if migration.Status.OverallProgress != halfway {
+		t.Errorf("Expected progress to stay at %d, got %d", halfway, migration.Status.OverallProgress)
+	}
+
+	// Weights can be overridden so a phase counts for more or nothing at all
+	weighted := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			PhaseWeights: map[migrationv1alpha1.MigrationPhase]int32{
+				migrationv1alpha1.PhaseCreateWorkers: 0,
+			},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{
+			Phase: migrationv1alpha1.PhaseCreateWorkers,
+			CurrentPhaseState: &migrationv1alpha1.PhaseState{
+				Name:     migrationv1alpha1.PhaseCreateWorkers,
+				Progress: 50,
+			},
+		},
+	}
+	sm.UpdateOverallProgress(weighted)
+	if weighted.Status.OverallProgress <= beforeWorkers {
+		t.Errorf("Expected dropping CreateWorkers' weight to raise the share of earlier phases above %d, got %d",
+			beforeWorkers, weighted.Status.OverallProgress)
+	}
+
+	migration.Status.Phase = migrationv1alpha1.PhaseCompleted
+	sm.UpdateOverallProgress(migration)
+	if migration.Status.OverallProgress != 100 {
+		t.Errorf("Expected 100 once completed, got %d", migration.Status.OverallProgress)
+	}
+}
+
+func TestRecordPhaseCompletion_LogRetention(t *testing.T) {
+	sm := state.NewStateMachine(nil)
+	logsOf := func(levels ...migrationv1alpha1.LogLevel) []migrationv1alpha1.LogEntry {
+		var logs []migrationv1alpha1.LogEntry
+		for i, level := range levels {
+			logs = append(logs, migrationv1alpha1.LogEntry{Level: level, Message: fmt.Sprintf("%d", i)})
+		}
+		return logs
+	}
+	messages := func(logs []migrationv1alpha1.LogEntry) string {
+		var msgs []string
+		for _, log := range logs {
+			msgs = append(msgs, log.Message)
+		}
+		return strings.Join(msgs, ",")
+	}
+	info, warn, errLevel := migrationv1alpha1.LogLevelInfo, migrationv1alpha1.LogLevelWarning, migrationv1alpha1.LogLevelError
+
+	t.Run("per-phase cap keeps errors and the most recent entries", func(t *testing.T) {
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+			Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+				LogRetention: &migrationv1alpha1.LogRetentionConfig{MaxEntriesPerPhase: 4},
+			},
+		}
+		result := &phases.PhaseResult{
+			Status: migrationv1alpha1.PhaseStatusCompleted,
+			Logs:   logsOf(errLevel, info, warn, info, info, info),
+		}
+		sm.RecordPhaseCompletion(migration, migrationv1alpha1.PhaseBackup, result)
+
+		if got := messages(migration.Status.PhaseHistory[0].Logs); got != "0,2,4,5" {
+			t.Errorf("Expected entries 0,2,4,5 to be kept, got %s", got)
+		}
+	})
+
+	t.Run("total cap trims the oldest phases first", func(t *testing.T) {
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+			Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+				LogRetention: &migrationv1alpha1.LogRetentionConfig{MaxTotalEntries: 5},
+			},
+		}
+		sm.RecordPhaseCompletion(migration, migrationv1alpha1.PhaseBackup, &phases.PhaseResult{
+			Status: migrationv1alpha1.PhaseStatusCompleted,
+			Logs:   logsOf(info, errLevel, info),
+		})
+		sm.RecordPhaseCompletion(migration, migrationv1alpha1.PhaseDisableCVO, &phases.PhaseResult{
+			Status: migrationv1alpha1.PhaseStatusCompleted,
+			Logs:   logsOf(info, info, info),
+		})
+
+		history := migration.Status.PhaseHistory
+		if got := messages(history[0].Logs); got != "1,2" {
+			t.Errorf("Expected entries 1,2 of the first phase to be kept, got %s", got)
+		}
+		if got := messages(history[1].Logs); got != "0,1,2" {
+			t.Errorf("Expected every entry of the last phase to be kept, got %s", got)
+		}
+	})
+
+	t.Run("default cap", func(t *testing.T) {
+		migration := &migrationv1alpha1.VmwareCloudFoundationMigration{}
+		sm.RecordPhaseCompletion(migration, migrationv1alpha1.PhaseMigrateCSIVolumes, &phases.PhaseResult{
+			Status: migrationv1alpha1.PhaseStatusCompleted,
+			Logs:   logsOf(slices.Repeat([]migrationv1alpha1.LogLevel{info}, 500)...),
+		})
+		if n := len(migration.Status.PhaseHistory[0].Logs); n != phases.MaxPhaseLogEntries(migration) {
+			t.Errorf("Expected %d entries, got %d", phases.MaxPhaseLogEntries(migration), n)
+		}
+	})
+}
+
+func TestShouldRequeue_Interval(t *testing.T) {
+	sm := state.NewStateMachine(nil)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec:   migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{Phase: migrationv1alpha1.PhaseBackup},
+	}
+
+	if _, after := sm.ShouldRequeue(migration, nil); after != state.DefaultRequeueInterval {
+		t.Errorf("Expected the default interval %s, got %s", state.DefaultRequeueInterval, after)
+	}
+
+	sm.SetRequeueInterval(2 * time.Second)
+	if _, after := sm.ShouldRequeue(migration, nil); after != 2*time.Second {
+		t.Errorf("Expected the controller interval 2s, got %s", after)
+	}
+
+	migration.Spec.RequeueInterval = &metav1.Duration{Duration: time.Minute}
+	if _, after := sm.ShouldRequeue(migration, nil); after != time.Minute {
+		t.Errorf("Expected the spec interval 1m, got %s", after)
+	}
+
+	// A delay requested by the phase always wins
+	if _, after := sm.ShouldRequeue(migration, &phases.PhaseResult{RequeueAfter: 5 * time.Second}); after != 5*time.Second {
+		t.Errorf("Expected the phase delay 5s, got %s", after)
+	}
+}
+
+func TestPauseAfterPhase(t *testing.T) {
+	sm := state.NewStateMachine(nil)
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{State: migrationv1alpha1.MigrationStateRunning},
+	}
+	result := &phases.PhaseResult{
+		Status:  migrationv1alpha1.PhaseStatusCompleted,
+		Message: "All preflight checks passed",
+		Logs: []migrationv1alpha1.LogEntry{
+			{Level: migrationv1alpha1.LogLevelInfo, Message: "Connected to source vCenter"},
+			{Level: migrationv1alpha1.LogLevelWarning, Message: "Folder /DC2/vm/ocp not found - will be created"},
+		},
+	}
+
+	// Without the flag the migration carries on
+	if _, paused := sm.PauseAfterPhase(migration, migrationv1alpha1.PhasePreflight, result); paused {
+		t.Fatal("Expected no pause when PauseAfterPreflight is unset")
+	}
+
+	migration.Spec.PauseAfterPreflight = true
+	if _, paused := sm.PauseAfterPhase(migration, migrationv1alpha1.PhaseBackup, result); paused {
+		t.Fatal("Expected no pause after a phase other than Preflight")
+	}
+	if migration.Spec.State != migrationv1alpha1.MigrationStateRunning {
+		t.Fatalf("Expected state to stay Running, got %s", migration.Spec.State)
+	}
+
+	summary, paused := sm.PauseAfterPhase(migration, migrationv1alpha1.PhasePreflight, result)
+	if !paused {
+		t.Fatal("Expected the migration to pause after Preflight")
+	}
+	if migration.Spec.State != migrationv1alpha1.MigrationStatePaused {
+		t.Errorf("Expected state Paused, got %s", migration.Spec.State)
+	}
+	for _, want := range []string{"All preflight checks passed", "1 warning(s)", "Folder /DC2/vm/ocp not found"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got %q", want, summary)
+		}
+	}
+	if strings.Contains(summary, "Connected to source vCenter") {
+		t.Errorf("Expected info logs to be left out of the summary, got %q", summary)
+	}
+}
+
+// recordingNotifier collects the notifications sent by the state machine
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (r *recordingNotifier) Notify(notification notify.Notification) {
+	r.notifications = append(r.notifications, notification)
+}
+
+func (r *recordingNotifier) events() []notify.Event {
+	events := make([]notify.Event, 0, len(r.notifications))
+	for _, notification := range r.notifications {
+		events = append(events, notification.Event)
+	}
+	return events
+}
+
+func TestStateMachineNotifications(t *testing.T) {
+	notifier := &recordingNotifier{}
+	sm := state.NewStateMachine(nil)
+	sm.SetNotifier(notifier)
+
+	migration := &migrationv1alpha1.VmwareCloudFoundationMigration{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-migration", Namespace: "openshift-config"},
+		Spec: migrationv1alpha1.VmwareCloudFoundationMigrationSpec{
+			State:                 migrationv1alpha1.MigrationStateRunning,
+			RequireApprovalBefore: []migrationv1alpha1.MigrationPhase{migrationv1alpha1.PhasePreflight},
+		},
+		Status: migrationv1alpha1.VmwareCloudFoundationMigrationStatus{Phase: migrationv1alpha1.PhasePreflight},
+	}
+
+	// Waiting at the approval gate is reported once, not on every reconcile
+	sm.MarkPhaseForApproval(migration, migrationv1alpha1.PhasePreflight, "Waiting for approval")
+	sm.MarkPhaseForApproval(migration, migrationv1alpha1.PhasePreflight, "Waiting for approval")
+	if err := sm.ApprovePhase(migration, migrationv1alpha1.PhasePreflight); err != nil {
+		t.Fatalf("ApprovePhase failed: %v", err)
+	}
+
+	// Only the first execution of the first phase starts the migration
+	sm.RecordPhaseStart(migration, migrationv1alpha1.PhasePreflight)
+	migration.Status.CurrentPhaseState.Status = migrationv1alpha1.PhaseStatusRunning
+	sm.RecordPhaseStart(migration, migrationv1alpha1.PhasePreflight)
+
+	sm.RecordPhaseCompletion(migration, migrationv1alpha1.PhasePreflight, &phases.PhaseResult{
+		Status: migrationv1alpha1.PhaseStatusCompleted, Message: "All preflight checks passed"})
+	sm.RecordPhaseStart(migration, migrationv1alpha1.PhaseBackup)
+	sm.RecordPhaseCompletion(migration, migrationv1alpha1.PhaseBackup, &phases.PhaseResult{
+		Status: migrationv1alpha1.PhaseStatusFailed, Message: "Backup failed"})
+	sm.CompleteMigration(migration)
+
+	want := []notify.Event{
+		notify.EventApprovalRequired,
+		notify.EventStarted,
+		notify.EventPhaseCompleted,
+		notify.EventFailed,
+		notify.EventCompleted,
+	}
+	if got := notifier.events(); !slices.Equal(got, want) {
+		t.Fatalf("Expected events %v, got %v", want, got)
+	}
+
+	failed := notifier.notifications[3]
+	if failed.Migration != "my-migration" || failed.Namespace != "openshift-config" ||
+		failed.Phase != string(migrationv1alpha1.PhaseBackup) || failed.Message != "Backup failed" {
+		t.Errorf("Unexpected failure notification: %+v", failed)
+	}
+	if migration.Status.Phase != migrationv1alpha1.PhaseCompleted || migration.Status.CompletionTime == nil {
+		t.Errorf("Expected CompleteMigration to mark the migration completed, got phase %s", migration.Status.Phase)
+	}
+}
@@ -0,0 +1,158 @@
+package unit
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/util"
+)
+
+func TestNormalizeInventoryPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		folder   string
+		path     string
+		expected string
+	}{
+		{name: "bare cluster name", folder: util.HostFolder, path: "cluster1", expected: "/DC2/host/cluster1"},
+		{name: "absolute path kept", folder: util.HostFolder, path: "/DC2/host/folder/cluster1", expected: "/DC2/host/folder/cluster1"},
+		{name: "absolute path cleaned", folder: util.DatastoreFolder, path: "/DC2/datastore/ds1/", expected: "/DC2/datastore/ds1"},
+		{name: "relative to datacenter", folder: util.DatastoreFolder, path: "datastore/ds1", expected: "/DC2/datastore/ds1"},
+		{name: "datacenter without leading slash", folder: util.HostFolder, path: "DC2/host/cluster1", expected: "/DC2/host/cluster1"},
+		{name: "nested relative path", folder: util.VMFolder, path: "parent/child", expected: "/DC2/vm/parent/child"},
+		{name: "surrounding whitespace", folder: util.DatastoreFolder, path: " ds1 ", expected: "/DC2/datastore/ds1"},
+		{name: "empty stays empty", folder: util.VMFolder, path: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.NormalizeInventoryPath("DC2", tt.folder, tt.path); got != tt.expected {
+				t.Errorf("NormalizeInventoryPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeResourcePoolPath(t *testing.T) {
+	cluster := "/DC2/host/cluster1"
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "pool1", expected: "/DC2/host/cluster1/Resources/pool1"},
+		{path: "Resources", expected: "/DC2/host/cluster1/Resources"},
+		{path: "Resources/pool1/child", expected: "/DC2/host/cluster1/Resources/pool1/child"},
+		{path: "host/cluster2/Resources", expected: "/DC2/host/cluster2/Resources"},
+		{path: "/DC2/host/cluster1/Resources/pool1", expected: "/DC2/host/cluster1/Resources/pool1"},
+		{path: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if got := util.NormalizeResourcePoolPath("DC2", cluster, tt.path); got != tt.expected {
+			t.Errorf("NormalizeResourcePoolPath(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestNormalizeFailureDomains(t *testing.T) {
+	failureDomains := []configv1.VSpherePlatformFailureDomainSpec{{
+		Name: "fd1",
+		Topology: configv1.VSpherePlatformTopology{
+			Datacenter:     "DC2",
+			ComputeCluster: "cluster1",
+			Datastore:      "ds1",
+			ResourcePool:   "pool1",
+			Networks:       []string{"VM Network"},
+		},
+	}}
+
+	util.NormalizeFailureDomains(failureDomains)
+
+	topology := failureDomains[0].Topology
+	if topology.ComputeCluster != "/DC2/host/cluster1" {
+		t.Errorf("Unexpected compute cluster %s", topology.ComputeCluster)
+	}
+	if topology.Datastore != "/DC2/datastore/ds1" {
+		t.Errorf("Unexpected datastore %s", topology.Datastore)
+	}
+	if topology.ResourcePool != "/DC2/host/cluster1/Resources/pool1" {
+		t.Errorf("Unexpected resource pool %s", topology.ResourcePool)
+	}
+	if topology.Folder != "" {
+		t.Errorf("Expected unset folder to stay unset, got %s", topology.Folder)
+	}
+	if topology.Networks[0] != "VM Network" {
+		t.Errorf("Expected networks to be left as given, got %v", topology.Networks)
+	}
+
+	// Normalizing again leaves the canonical paths unchanged
+	again := util.NormalizeTopology(topology)
+	if again.ComputeCluster != topology.ComputeCluster || again.ResourcePool != topology.ResourcePool {
+		t.Errorf("Expected normalization to be idempotent, got %+v", again)
+	}
+}
+
+func TestFailureDomainPlacement(t *testing.T) {
+	tests := []struct {
+		name         string
+		topology     configv1.VSpherePlatformTopology
+		folder       string
+		resourcePool string
+		wantErr      bool
+	}{
+		{
+			name:         "defaults",
+			topology:     configv1.VSpherePlatformTopology{Datacenter: "DC2", ComputeCluster: "cluster1"},
+			folder:       "/DC2/vm/cluster-x7x2g",
+			resourcePool: "/DC2/host/cluster1/Resources",
+		},
+		{
+			name: "nested folder and child pool",
+			topology: configv1.VSpherePlatformTopology{
+				Datacenter:     "DC2",
+				ComputeCluster: "/DC2/host/cluster1",
+				Folder:         "org/team/ocp",
+				ResourcePool:   "tier1/ocp",
+			},
+			folder:       "/DC2/vm/org/team/ocp",
+			resourcePool: "/DC2/host/cluster1/Resources/tier1/ocp",
+		},
+		{
+			name: "folder outside the VM folder",
+			topology: configv1.VSpherePlatformTopology{
+				Datacenter:     "DC2",
+				ComputeCluster: "cluster1",
+				Folder:         "/DC2/host/ocp",
+			},
+			folder:       "/DC2/host/ocp",
+			resourcePool: "/DC2/host/cluster1/Resources",
+			wantErr:      true,
+		},
+		{
+			name: "pool of another cluster",
+			topology: configv1.VSpherePlatformTopology{
+				Datacenter:     "DC2",
+				ComputeCluster: "cluster1",
+				ResourcePool:   "/DC2/host/cluster2/Resources/ocp",
+			},
+			folder:       "/DC2/vm/cluster-x7x2g",
+			resourcePool: "/DC2/host/cluster2/Resources/ocp",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.FailureDomainFolder(tt.topology, "cluster-x7x2g"); got != tt.folder {
+				t.Errorf("FailureDomainFolder() = %q, expected %q", got, tt.folder)
+			}
+			if got := util.FailureDomainResourcePool(tt.topology); got != tt.resourcePool {
+				t.Errorf("FailureDomainResourcePool() = %q, expected %q", got, tt.resourcePool)
+			}
+			if err := util.ValidatePlacementPaths(tt.topology); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePlacementPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
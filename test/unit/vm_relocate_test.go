@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"fmt"
 	"math/big"
 	"net"
@@ -15,6 +16,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vslm"
+	"k8s.io/klog/v2"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/phaseerrors"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
@@ -25,7 +38,7 @@ func TestGetServerThumbprint(t *testing.T) {
 
 	// Get the thumbprint of the test server's certificate
 	ctx := context.Background()
-	thumbprint, err := vsphere.GetServerThumbprint(ctx, server.URL)
+	thumbprint, err := vsphere.GetServerThumbprint(ctx, server.URL, "", 0)
 	if err != nil {
 		t.Fatalf("GetServerThumbprint failed: %v", err)
 	}
@@ -53,11 +66,32 @@ func TestGetServerThumbprint(t *testing.T) {
 	}
 }
 
+func TestGetServerThumbprint_HostAndPort(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	ctx := context.Background()
+	expected, err := vsphere.GetServerThumbprint(ctx, server.URL, "", 0)
+	if err != nil {
+		t.Fatalf("GetServerThumbprint failed: %v", err)
+	}
+
+	// A server given as host:port, the way failure domains name a vCenter on a custom port
+	hostPort := strings.TrimPrefix(server.URL, "https://")
+	thumbprint, err := vsphere.GetServerThumbprint(ctx, hostPort, "", 0)
+	if err != nil {
+		t.Fatalf("GetServerThumbprint of %s failed: %v", hostPort, err)
+	}
+	if thumbprint != expected {
+		t.Errorf("Expected thumbprint %s, got %s", expected, thumbprint)
+	}
+}
+
 func TestGetServerThumbprint_InvalidURL(t *testing.T) {
 	ctx := context.Background()
 
 	// Test with an invalid URL
-	_, err := vsphere.GetServerThumbprint(ctx, "not-a-valid-url")
+	_, err := vsphere.GetServerThumbprint(ctx, "not-a-valid-url", "", 0)
 	if err == nil {
 		t.Error("Expected error for invalid URL, got nil")
 	}
@@ -67,12 +101,40 @@ func TestGetServerThumbprint_ConnectionRefused(t *testing.T) {
 	ctx := context.Background()
 
 	// Test with a port that should refuse connections
-	_, err := vsphere.GetServerThumbprint(ctx, "https://127.0.0.1:65534/sdk")
+	_, err := vsphere.GetServerThumbprint(ctx, "https://127.0.0.1:65534/sdk", "", 0)
 	if err == nil {
 		t.Error("Expected error for connection refused, got nil")
 	}
 }
 
+func TestGetServerThumbprint_ThroughProxy(t *testing.T) {
+	server := httptest.NewTLSServer(nil)
+	defer server.Close()
+
+	proxy, tunnels := newConnectProxy(t)
+	defer proxy.Close()
+
+	ctx := context.Background()
+	direct, err := vsphere.GetServerThumbprint(ctx, server.URL, "", 0)
+	if err != nil {
+		t.Fatalf("GetServerThumbprint failed: %v", err)
+	}
+
+	proxied, err := vsphere.GetServerThumbprint(ctx, server.URL, proxy.URL, 0)
+	if err != nil {
+		t.Fatalf("GetServerThumbprint through proxy failed: %v", err)
+	}
+
+	// The tunnel must not alter the certificate, otherwise the ServiceLocator
+	// handed to the source vCenter would carry the wrong thumbprint
+	if proxied != direct {
+		t.Errorf("Expected thumbprint through proxy %s to match direct thumbprint %s", proxied, direct)
+	}
+	if tunnels.Load() != 1 {
+		t.Errorf("Expected 1 proxy tunnel, got %d", tunnels.Load())
+	}
+}
+
 func TestThumbprintCalculation(t *testing.T) {
 	// Create a test certificate
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
@@ -147,3 +209,1007 @@ func TestRelocateConfig_ThumbprintField(t *testing.T) {
 		t.Error("Expected thumbprint to have 32 parts")
 	}
 }
+
+func TestRelocateVM_SameVCenter(t *testing.T) {
+	model := simulator.VPX()
+	model.Datastore = 2
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	const folder = "/DC0/vm/cluster-x7x2g"
+	if _, err := client.EnsureFolder(ctx, "DC0", folder); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+		Name:         "csi-migration-cluster-x7x2g-pvc-1",
+		Datacenter:   "DC0",
+		Datastore:    "LocalDS_0",
+		Folder:       folder,
+		ResourcePool: "/DC0/host/DC0_C0/Resources",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create dummy VM: %v", err)
+	}
+
+	// Neither a thumbprint nor an instance UUID is set, a cross-vCenter relocate would be rejected
+	config := vsphere.RelocateConfig{
+		TargetVCenterURL:   client.SDKURL(),
+		SameVCenter:        true,
+		TargetDatacenter:   "DC0",
+		TargetDatastore:    "/DC0/datastore/LocalDS_1",
+		TargetFolder:       folder,
+		TargetResourcePool: "/DC0/host/DC0_C0/Resources",
+	}
+	if err := relocator.RelocateVM(ctx, vm, config); err != nil {
+		t.Fatalf("RelocateVM failed: %v", err)
+	}
+
+	target, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_1")
+	if err != nil {
+		t.Fatalf("Failed to get target datastore: %v", err)
+	}
+	var moVM mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"datastore"}, &moVM); err != nil {
+		t.Fatalf("Failed to read VM datastores: %v", err)
+	}
+	if len(moVM.Datastore) != 1 || moVM.Datastore[0] != target.Reference() {
+		t.Errorf("Expected the VM to be on %s, got %v", target.Reference(), moVM.Datastore)
+	}
+
+	config.SameVCenter = false
+	if err := relocator.RelocateVM(ctx, vm, config); err == nil {
+		t.Error("Expected a cross-vCenter relocate without a thumbprint to be rejected")
+	}
+}
+
+func TestRelocateVM_MaxTaskDuration(t *testing.T) {
+	model := simulator.VPX()
+	model.Datastore = 2
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+		Name:         "csi-migration-cluster-x7x2g-pvc-1",
+		Datacenter:   "DC0",
+		Datastore:    "LocalDS_0",
+		Folder:       "/DC0/vm",
+		ResourcePool: "/DC0/host/DC0_C0/Resources",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create dummy VM: %v", err)
+	}
+
+	// Hold the relocate task running well past its maximum duration
+	simulator.TaskDelay.MethodDelay = map[string]int{"RelocateVm": 2000, "LockHandoff": 0}
+	defer func() { simulator.TaskDelay.MethodDelay = nil }()
+
+	err = relocator.RelocateVM(ctx, vm, vsphere.RelocateConfig{
+		TargetVCenterURL:   client.SDKURL(),
+		SameVCenter:        true,
+		TargetDatacenter:   "DC0",
+		TargetDatastore:    "/DC0/datastore/LocalDS_1",
+		TargetFolder:       "/DC0/vm",
+		TargetResourcePool: "/DC0/host/DC0_C0/Resources",
+		MaxTaskDuration:    200 * time.Millisecond,
+	})
+	if !errors.Is(err, vsphere.ErrRelocateTaskTimedOut) {
+		t.Fatalf("Expected a relocate timeout, got %v", err)
+	}
+	if phaseerrors.Class(err) != phaseerrors.ErrTransient {
+		t.Errorf("Expected a cancelled relocate to be retryable, got %v", phaseerrors.Class(err))
+	}
+
+	pc := property.DefaultCollector(client.VimClient())
+	var tasks mo.TaskManager
+	if err := pc.RetrieveOne(ctx, *client.VimClient().ServiceContent.TaskManager, []string{"recentTask"}, &tasks); err != nil {
+		t.Fatalf("Failed to read recent tasks: %v", err)
+	}
+	var cancelled bool
+	for _, ref := range tasks.RecentTask {
+		var task mo.Task
+		if err := pc.RetrieveOne(ctx, ref, []string{"info"}, &task); err != nil {
+			t.Fatalf("Failed to read task: %v", err)
+		}
+		if task.Info.Name == "RelocateVm" {
+			cancelled = task.Info.Cancelled
+		}
+	}
+	if !cancelled {
+		t.Error("Expected the relocate task to be cancelled")
+	}
+}
+
+// vMotionDisabledChecker answers relocation checks the way vCenter does when vMotion is disabled
+type vMotionDisabledChecker struct {
+	*simulator.VmProvisioningChecker
+}
+
+func (c *vMotionDisabledChecker) CheckRelocateTask(ctx *simulator.Context, r *types.CheckRelocate_Task) soap.HasFault {
+	task := simulator.CreateTask(c, "checkRelocate", func(*simulator.Task) (types.AnyType, types.BaseMethodFault) {
+		return types.ArrayOfCheckResult{CheckResult: []types.CheckResult{{
+			Vm: &r.Vm,
+			Error: []types.LocalizedMethodFault{{
+				Fault:            &types.MigrationDisabled{},
+				LocalizedMessage: "vMotion is disabled on the host",
+			}},
+			Warning: []types.LocalizedMethodFault{{Fault: &types.MigrationFeatureNotSupported{}}},
+		}}}, nil
+	})
+	return &methods.CheckRelocate_TaskBody{
+		Res: &types.CheckRelocate_TaskResponse{Returnval: task.Run(ctx)},
+	}
+}
+
+func TestCheckRelocate(t *testing.T) {
+	model := simulator.VPX()
+	model.Datastore = 2
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+		Name:         "csi-migration-cluster-x7x2g-vmotion-probe",
+		Datacenter:   "DC0",
+		Datastore:    "LocalDS_0",
+		Folder:       "/DC0/vm",
+		ResourcePool: "/DC0/host/DC0_C0/Resources",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create dummy VM: %v", err)
+	}
+
+	config := vsphere.RelocateConfig{
+		TargetVCenterURL:          client.SDKURL(),
+		TargetVCenterUser:         "administrator@vsphere.local",
+		TargetVCenterPassword:     "password",
+		TargetVCenterThumbprint:   "AA:BB:CC",
+		TargetVCenterInstanceUUID: client.GetInstanceUUID(),
+		TargetDatacenter:          "DC0",
+		TargetDatastore:           "/DC0/datastore/LocalDS_1",
+		TargetFolder:              "/DC0/vm",
+		TargetResourcePool:        "/DC0/host/DC0_C0/Resources",
+	}
+	warnings, err := relocator.CheckRelocate(ctx, vm, config)
+	if err != nil {
+		t.Fatalf("CheckRelocate failed: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	checker := model.Map().VmProvisioningChecker()
+	model.Map().Put(&vMotionDisabledChecker{checker})
+	defer model.Map().Put(checker)
+
+	warnings, err = relocator.CheckRelocate(ctx, vm, config)
+	if !errors.Is(err, vsphere.ErrRelocateNotViable) {
+		t.Fatalf("Expected the relocation to be reported as not viable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "vMotion is disabled on the host") {
+		t.Errorf("Expected the check error to carry vCenter's message, got %v", err)
+	}
+	if len(warnings) != 1 || warnings[0] != "MigrationFeatureNotSupported" {
+		t.Errorf("Expected the warning to be described by its fault type, got %v", warnings)
+	}
+
+	// The VM is only checked, never moved
+	var moVM mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"datastore"}, &moVM); err != nil {
+		t.Fatalf("Failed to read VM datastores: %v", err)
+	}
+	source, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get source datastore: %v", err)
+	}
+	if len(moVM.Datastore) != 1 || moVM.Datastore[0] != source.Reference() {
+		t.Errorf("Expected the VM to stay on %s, got %v", source.Reference(), moVM.Datastore)
+	}
+}
+
+func TestCheckRelocateHost(t *testing.T) {
+	model := simulator.VPX()
+	model.Host = 1
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	const cluster = "/DC0/host/DC0_C0"
+	if err := client.CheckRelocateHost(ctx, "/DC0/host/DC0_C0/DC0_C0_H0", cluster); err != nil {
+		t.Errorf("Expected a connected cluster host to be accepted, got %v", err)
+	}
+
+	// The standalone host is not in the cluster
+	if err := client.CheckRelocateHost(ctx, "/DC0/host/DC0_H0/DC0_H0", cluster); err == nil || !strings.Contains(err.Error(), "not in compute cluster") {
+		t.Errorf("Expected a host outside the cluster to be rejected, got %v", err)
+	}
+	if err := client.CheckRelocateHost(ctx, "/DC0/host/DC0_C0/missing", cluster); err == nil {
+		t.Error("Expected a missing host to be rejected")
+	}
+
+	host, err := client.GetHost(ctx, "/DC0/host/DC0_C0/DC0_C0_H1")
+	if err != nil {
+		t.Fatalf("Failed to get host: %v", err)
+	}
+	task, err := host.EnterMaintenanceMode(ctx, 0, false, nil)
+	if err != nil {
+		t.Fatalf("Failed to enter maintenance mode: %v", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("Failed to enter maintenance mode: %v", err)
+	}
+	if err := client.CheckRelocateHost(ctx, "/DC0/host/DC0_C0/DC0_C0_H1", cluster); err == nil || !strings.Contains(err.Error(), "maintenance mode") {
+		t.Errorf("Expected a host in maintenance mode to be rejected, got %v", err)
+	}
+}
+
+func TestCheckDatastoreAccessible(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	const path = "/DC0/datastore/LocalDS_0"
+	if err := client.CheckDatastoreAccessible(ctx, path); err != nil {
+		t.Errorf("Expected an accessible datastore to be accepted, got %v", err)
+	}
+	if err := client.CheckDatastoreAccessible(ctx, "/DC0/datastore/missing"); err == nil {
+		t.Error("Expected a missing datastore to be rejected")
+	}
+
+	ds, err := client.GetDatastore(ctx, path)
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	simDS := model.Map().Get(ds.Reference()).(*simulator.Datastore)
+
+	simDS.Summary.MaintenanceMode = string(types.DatastoreSummaryMaintenanceModeStateInMaintenance)
+	if err := client.CheckDatastoreAccessible(ctx, path); err == nil || !strings.Contains(err.Error(), "maintenance mode") {
+		t.Errorf("Expected a datastore in maintenance mode to be rejected, got %v", err)
+	}
+
+	simDS.Summary.MaintenanceMode = string(types.DatastoreSummaryMaintenanceModeStateNormal)
+	simDS.Summary.Accessible = false
+	if err := client.CheckDatastoreAccessible(ctx, path); err == nil || !strings.Contains(err.Error(), "not accessible") {
+		t.Errorf("Expected an inaccessible datastore to be rejected, got %v", err)
+	}
+}
+
+func TestCheckVMotionCompatibility(t *testing.T) {
+	about := func(version, build string) types.AboutInfo {
+		return types.AboutInfo{Version: version, Build: build}
+	}
+
+	tests := []struct {
+		name      string
+		source    types.AboutInfo
+		target    types.AboutInfo
+		expectErr bool
+	}{
+		{name: "same version and build", source: about("8.0.3", "24322831"), target: about("8.0.3", "24322831")},
+		{name: "newer update on target", source: about("8.0.2", "22617221"), target: about("8.0.3", "24322831")},
+		{name: "next major release", source: about("8.0.3", "24322831"), target: about("9.0.0", "24755230")},
+		{name: "older major release", source: about("8.0.3", "24322831"), target: about("7.0.3", "21477706"), expectErr: true},
+		{name: "older update within release", source: about("8.0.3", "24322831"), target: about("8.0.1", "21560480"), expectErr: true},
+		{name: "older build within update", source: about("8.0.3", "24322831"), target: about("8.0.3", "24026615"), expectErr: true},
+		{name: "outside compatibility window", source: about("6.7.0", "19299595"), target: about("9.0.0", "24755230"), expectErr: true},
+		{name: "unknown source release", source: about("5.5.0", "1000000"), target: about("8.0.3", "24322831"), expectErr: true},
+		{name: "unparseable version", source: about("", ""), target: about("8.0.3", "24322831"), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := vsphere.CheckVMotionCompatibility(tt.source, tt.target)
+			if tt.expectErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+// scsiDevices builds a device list with a PVSCSI controller per bus and the given number of disks on each
+func scsiDevices(disksPerBus map[int32]int) object.VirtualDeviceList {
+	var devices object.VirtualDeviceList
+	for bus, disks := range disksPerBus {
+		controllerKey := 1000 + bus
+		devices = append(devices, &types.ParaVirtualSCSIController{
+			VirtualSCSIController: types.VirtualSCSIController{
+				VirtualController: types.VirtualController{
+					BusNumber:     bus,
+					VirtualDevice: types.VirtualDevice{Key: controllerKey},
+				},
+			},
+		})
+
+		unit := int32(0)
+		for i := 0; i < disks; i++ {
+			if unit == 7 {
+				unit++
+			}
+			devices = append(devices, &types.VirtualDisk{
+				VirtualDevice: types.VirtualDevice{
+					Key:           2000 + bus*100 + unit,
+					ControllerKey: controllerKey,
+					UnitNumber:    types.NewInt32(unit),
+				},
+			})
+			unit++
+		}
+	}
+	return devices
+}
+
+func TestFindFreeDiskSlot(t *testing.T) {
+	tests := []struct {
+		name        string
+		disksPerBus map[int32]int
+		reserved    []int32
+		expected    *vsphere.DiskSlot
+	}{
+		{
+			name:        "empty controller",
+			disksPerBus: map[int32]int{0: 0},
+			expected:    &vsphere.DiskSlot{ControllerKey: 1000, UnitNumber: 0},
+		},
+		{
+			name:        "skips the controller unit",
+			disksPerBus: map[int32]int{0: 7},
+			expected:    &vsphere.DiskSlot{ControllerKey: 1000, UnitNumber: 8},
+		},
+		{
+			name:        "spills to the next controller",
+			disksPerBus: map[int32]int{0: 15, 1: 3},
+			expected:    &vsphere.DiskSlot{ControllerKey: 1001, UnitNumber: 3},
+		},
+		{
+			name:        "fills lower buses first",
+			disksPerBus: map[int32]int{2: 0, 0: 4},
+			expected:    &vsphere.DiskSlot{ControllerKey: 1000, UnitNumber: 4},
+		},
+		{
+			name:        "skips reserved units",
+			disksPerBus: map[int32]int{0: 1},
+			reserved:    []int32{1, 2},
+			expected:    &vsphere.DiskSlot{ControllerKey: 1000, UnitNumber: 3},
+		},
+		{
+			name:        "reserved units apply to every controller",
+			disksPerBus: map[int32]int{0: 14, 1: 0},
+			reserved:    []int32{0, 15},
+			expected:    &vsphere.DiskSlot{ControllerKey: 1001, UnitNumber: 1},
+		},
+		{
+			name:        "all controllers full",
+			disksPerBus: map[int32]int{0: 15, 1: 15},
+		},
+		{
+			name: "no controller",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot := vsphere.FindFreeDiskSlot(scsiDevices(tt.disksPerBus), tt.reserved...)
+			if tt.expected == nil {
+				if slot != nil {
+					t.Errorf("Expected no free slot, got %+v", *slot)
+				}
+				return
+			}
+			if slot == nil {
+				t.Fatalf("Expected slot %+v, got none", *tt.expected)
+			}
+			if *slot != *tt.expected {
+				t.Errorf("Expected slot %+v, got %+v", *tt.expected, *slot)
+			}
+		})
+	}
+}
+
+func TestNextDiskSlot_OccupiedAndReservedUnits(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	const folder = "/DC0/vm/cluster-x7x2g"
+	if _, err := client.EnsureFolder(ctx, "DC0", folder); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+		Name:         "csi-migration-cluster-x7x2g-pvc-1",
+		Datacenter:   "DC0",
+		Datastore:    "LocalDS_0",
+		Folder:       folder,
+		ResourcePool: "/DC0/host/DC0_C0/Resources",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create dummy VM: %v", err)
+	}
+
+	// Give the dummy VM a boot disk at unit 0, as a dummy VM template could
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get devices: %v", err)
+	}
+	controller, err := devices.FindSCSIController("")
+	if err != nil {
+		t.Fatalf("Failed to find SCSI controller: %v", err)
+	}
+	bootDisk := devices.CreateDisk(controller, ds.Reference(), "")
+	bootDisk.CapacityInKB = 1024
+	bootDisk.UnitNumber = types.NewInt32(0)
+	if err := vm.AddDevice(ctx, bootDisk); err != nil {
+		t.Fatalf("Failed to add boot disk: %v", err)
+	}
+
+	unit, err := relocator.GetNextFreeUnitNumber(ctx, vm, controller.Key)
+	if err != nil {
+		t.Fatalf("GetNextFreeUnitNumber failed: %v", err)
+	}
+	if unit != 1 {
+		t.Errorf("Expected unit 1 next to the boot disk, got %d", unit)
+	}
+
+	unit, err = relocator.GetNextFreeUnitNumber(ctx, vm, controller.Key, 1, 2)
+	if err != nil {
+		t.Fatalf("GetNextFreeUnitNumber failed: %v", err)
+	}
+	if unit != 3 {
+		t.Errorf("Expected unit 3 past the reserved units, got %d", unit)
+	}
+
+	slot, err := relocator.NextDiskSlot(ctx, vm, 1)
+	if err != nil {
+		t.Fatalf("NextDiskSlot failed: %v", err)
+	}
+	if slot.ControllerKey != controller.Key || slot.UnitNumber != 2 {
+		t.Errorf("Expected unit 2 on controller %d, got %+v", controller.Key, slot)
+	}
+}
+
+func TestNextSCSIBusNumber(t *testing.T) {
+	bus, err := vsphere.NextSCSIBusNumber(scsiDevices(map[int32]int{0: 15}))
+	if err != nil {
+		t.Fatalf("NextSCSIBusNumber failed: %v", err)
+	}
+	if bus != 1 {
+		t.Errorf("Expected bus 1, got %d", bus)
+	}
+
+	bus, err = vsphere.NextSCSIBusNumber(scsiDevices(map[int32]int{0: 15, 2: 15}))
+	if err != nil {
+		t.Fatalf("NextSCSIBusNumber failed: %v", err)
+	}
+	if bus != 1 {
+		t.Errorf("Expected the gap at bus 1, got %d", bus)
+	}
+
+	full := scsiDevices(map[int32]int{0: 15, 1: 15, 2: 15, 3: 15})
+	if _, err := vsphere.NextSCSIBusNumber(full); err == nil {
+		t.Errorf("Expected an error once %d controllers are present", vsphere.MaxSCSIControllers)
+	}
+	if slot := vsphere.FindFreeDiskSlot(full); slot != nil {
+		t.Errorf("Expected no free slot on a full VM, got %+v", *slot)
+	}
+}
+
+func TestCleanupDummyVMs(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm endpoint used by the FCD manager
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	const folder = "/DC0/vm/cluster-x7x2g"
+	if _, err := client.EnsureFolder(ctx, "DC0", folder); err != nil {
+		t.Fatalf("Failed to create folder: %v", err)
+	}
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	createVM := func(name string) *object.VirtualMachine {
+		vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+			Name:         name,
+			Datacenter:   "DC0",
+			Datastore:    "LocalDS_0",
+			Folder:       folder,
+			ResourcePool: "/DC0/host/DC0_C0/Resources",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create VM %s: %v", name, err)
+		}
+		return vm
+	}
+	addDisk := func(vm *object.VirtualMachine, fileName, fcdID string) {
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get VM devices: %v", err)
+		}
+		controller, err := devices.FindDiskController("")
+		if err != nil {
+			t.Fatalf("Failed to find disk controller: %v", err)
+		}
+		disk := devices.CreateDisk(controller, ds.Reference(), fileName)
+		if fileName == "" {
+			disk.CapacityInKB = 1024
+		}
+		disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).BackingObjectId = fcdID
+		if err := vm.AddDevice(ctx, disk); err != nil {
+			t.Fatalf("Failed to add disk device: %v", err)
+		}
+	}
+
+	const prefix = "csi-migration-cluster-x7x2g-"
+	createVM(prefix + "empty")
+	createVM(prefix + "in-use")
+	createVM("customer-vm")
+	addDisk(createVM(prefix+"unknown-disk"), "", "")
+
+	// vcsim does not remove the device on detach, so the read-back still finds the FCD
+	objMgr := vslm.NewObjectManager(client.VimClient())
+	task, err := objMgr.CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "leftover-volume",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdObject := result.Result.(types.VStorageObject)
+	fcdID := fcdObject.Config.Id.Id
+	attached := createVM(prefix + "known-disk")
+	if err := attached.AttachDisk(ctx, fcdID, ds, 0, nil); err != nil {
+		t.Fatalf("Failed to attach FCD: %v", err)
+	}
+	addDisk(attached, fcdObject.Config.Backing.(*types.BaseConfigInfoDiskFileBackingInfo).FilePath, fcdID)
+
+	cleanup := &phases.DummyVMCleanup{Retained: make(map[string]string)}
+	err = phases.CleanupDummyVMs(ctx, client, "DC0", folder, prefix,
+		map[string]bool{fcdID: true},
+		map[string]string{prefix + "in-use": "used by volume pv-1 (relocating)"},
+		cleanup)
+	if err != nil {
+		t.Fatalf("CleanupDummyVMs failed: %v", err)
+	}
+
+	if len(cleanup.Deleted) != 1 || cleanup.Deleted[0] != prefix+"empty" {
+		t.Errorf("Expected only the VM without disks to be deleted, got %v", cleanup.Deleted)
+	}
+	if len(cleanup.Detached) != 1 || cleanup.Detached[0] != fcdID {
+		t.Errorf("Expected the known FCD to be detached, got %v", cleanup.Detached)
+	}
+	for _, name := range []string{"in-use", "unknown-disk", "known-disk"} {
+		if _, ok := cleanup.Retained[prefix+name]; !ok {
+			t.Errorf("Expected %s to be retained, got %v", prefix+name, cleanup.Retained)
+		}
+	}
+	if _, ok := cleanup.Retained["customer-vm"]; ok {
+		t.Error("Expected VMs without the dummy VM prefix to be ignored")
+	}
+
+	remaining, err := client.ListVirtualMachinesInFolder(ctx, "DC0", folder)
+	if err != nil {
+		t.Fatalf("Failed to list VMs: %v", err)
+	}
+	if len(remaining) != 4 {
+		t.Errorf("Expected 4 VMs to remain, got %d", len(remaining))
+	}
+}
+
+func TestValidateAttachedDisks(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+		Name:         "csi-migration-validate",
+		Datacenter:   "DC0",
+		Datastore:    "LocalDS_0",
+		Folder:       "/DC0/vm",
+		ResourcePool: "/DC0/host/DC0_C0/Resources",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create dummy VM: %v", err)
+	}
+
+	if err := relocator.ValidateAttachedDisks(ctx, vm); err != nil {
+		t.Fatalf("ValidateAttachedDisks failed: %v", err)
+	}
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get power state: %v", err)
+	}
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		t.Errorf("Expected dummy VM to be powered off after validation, got %s", state)
+	}
+
+	// Powering off a VM that is already off is a no-op
+	if err := relocator.PowerOffVM(ctx, vm); err != nil {
+		t.Errorf("PowerOffVM of a powered off VM failed: %v", err)
+	}
+}
+
+func TestDeleteDummyVM_DetachesTrackedFCDs(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm endpoint used by the FCD manager
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	task, err := vslm.NewObjectManager(client.VimClient()).CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "customer-volume",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdObject := result.Result.(types.VStorageObject)
+	fcdID := fcdObject.Config.Id.Id
+
+	relocator := vsphere.NewVMRelocator(client, client)
+	createVM := func(name string) *object.VirtualMachine {
+		vm, err := relocator.CreateDummyVM(ctx, vsphere.DummyVMConfig{
+			Name:         name,
+			Datacenter:   "DC0",
+			Datastore:    "LocalDS_0",
+			Folder:       "/DC0/vm",
+			ResourcePool: "/DC0/host/DC0_C0/Resources",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create VM %s: %v", name, err)
+		}
+		return vm
+	}
+
+	// A tracked FCD that never made it onto the VM does not block the delete
+	empty := createVM("csi-migration-empty")
+	if err := relocator.DeleteDummyVM(ctx, empty, fcdID); err != nil {
+		t.Fatalf("DeleteDummyVM of a VM without the FCD failed: %v", err)
+	}
+	if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/csi-migration-empty"); !vsphere.IsNotFound(err) {
+		t.Errorf("Expected the VM without the FCD to be destroyed, got %v", err)
+	}
+
+	// A retained VM is released from its volumes the same way but renamed instead of destroyed
+	retained := createVM("csi-migration-retained")
+	if err := relocator.RetainDummyVM(ctx, retained, "csi-migration-retained-failed", fcdID); err != nil {
+		t.Fatalf("RetainDummyVM of a VM without the FCD failed: %v", err)
+	}
+	if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/csi-migration-retained-failed"); err != nil {
+		t.Errorf("Expected the retained VM to be kept under its new name, got %v", err)
+	}
+	if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/csi-migration-retained"); !vsphere.IsNotFound(err) {
+		t.Errorf("Expected the original name to be free, got %v", err)
+	}
+
+	// vcsim does not remove the device on detach, so the read-back still finds the FCD and the
+	// VM must be kept rather than destroyed with the volume
+	attached := createVM("csi-migration-attached")
+	devices, err := attached.Device(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get VM devices: %v", err)
+	}
+	controller, err := devices.FindDiskController("")
+	if err != nil {
+		t.Fatalf("Failed to find disk controller: %v", err)
+	}
+	disk := devices.CreateDisk(controller, ds.Reference(), fcdObject.Config.Backing.(*types.BaseConfigInfoDiskFileBackingInfo).FilePath)
+	disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).BackingObjectId = fcdID
+	if err := attached.AddDevice(ctx, disk); err != nil {
+		t.Fatalf("Failed to add disk device: %v", err)
+	}
+
+	if err := relocator.DeleteDummyVM(ctx, attached, fcdID); err == nil {
+		t.Fatal("Expected DeleteDummyVM to refuse to destroy a VM the FCD is still attached to")
+	}
+	if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/csi-migration-attached"); err != nil {
+		t.Errorf("Expected the VM with the FCD attached to be kept, got %v", err)
+	}
+	if err := relocator.RetainDummyVM(ctx, attached, "csi-migration-attached-failed", fcdID); err == nil {
+		t.Error("Expected RetainDummyVM to report the FCD still attached to the VM")
+	}
+}
+
+func TestStoragePodPlacement(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	// Move the datastore into a new datastore cluster
+	datastoreFolder, err := client.GetFolder(ctx, "/DC0/datastore")
+	if err != nil {
+		t.Fatalf("Failed to get datastore folder: %v", err)
+	}
+	pod, err := datastoreFolder.CreateStoragePod(ctx, "pod1")
+	if err != nil {
+		t.Fatalf("Failed to create datastore cluster: %v", err)
+	}
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+	task, err := pod.MoveInto(ctx, []types.ManagedObjectReference{ds.Reference()})
+	if err != nil {
+		t.Fatalf("Failed to move datastore into cluster: %v", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("Failed to move datastore into cluster: %v", err)
+	}
+
+	resolved, err := client.ResolveInventoryPath(ctx, "DC0", vsphere.InventoryDatastoreCluster, "pod1")
+	if err != nil || resolved != "/DC0/datastore/pod1" {
+		t.Fatalf("Expected datastore cluster to resolve to /DC0/datastore/pod1, got %q, %v", resolved, err)
+	}
+	if err := client.CheckStoragePod(ctx, resolved); err != nil {
+		t.Errorf("Expected a datastore cluster with an accessible member to be accepted, got %v", err)
+	}
+
+	// Storage DRS places the disks of a VM on the cluster's member
+	vm, err := client.GetVirtualMachine(ctx, "/DC0/vm/DC0_H0_VM0")
+	if err != nil {
+		t.Fatalf("Failed to get VM: %v", err)
+	}
+	pool, err := client.GetResourcePool(ctx, "/DC0/host/DC0_H0/Resources")
+	if err != nil {
+		t.Fatalf("Failed to get resource pool: %v", err)
+	}
+	vmFolder, err := client.GetFolder(ctx, "/DC0/vm")
+	if err != nil {
+		t.Fatalf("Failed to get VM folder: %v", err)
+	}
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get VM devices: %v", err)
+	}
+	var vmDisks []*types.VirtualDisk
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		vmDisks = append(vmDisks, device.(*types.VirtualDisk))
+	}
+	recommended, err := client.RecommendPodDatastore(ctx, pod, pool.Reference(), vmFolder.Reference(), "dummy", vmDisks)
+	if err != nil {
+		t.Fatalf("RecommendPodDatastore failed: %v", err)
+	}
+	if recommended.Reference() != ds.Reference() {
+		t.Errorf("Expected member datastore %s, got %s", ds.Reference().Value, recommended.Reference().Value)
+	}
+
+	// A cluster whose only member is in maintenance mode cannot receive volumes
+	simDS := model.Map().Get(ds.Reference()).(*simulator.Datastore)
+	simDS.Summary.MaintenanceMode = string(types.DatastoreSummaryMaintenanceModeStateInMaintenance)
+	if err := client.CheckStoragePod(ctx, resolved); err == nil || !strings.Contains(err.Error(), "maintenance mode") {
+		t.Errorf("Expected a datastore cluster without usable member to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+func volumeAttachment(name, pvName, node string, attached bool) storagev1.VolumeAttachment {
+	return storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: openshift.VSphereCSIDriver,
+			NodeName: node,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+		},
+		Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+	}
+}
+
+func TestAttachmentTransition(t *testing.T) {
+	deleting := volumeAttachment("va-1", "pv-1", "worker-0", true)
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	deletingStuck := volumeAttachment("va-1", "pv-1", "worker-0", true)
+	deletingStuck.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	failing := volumeAttachment("va-1", "pv-1", "worker-0", false)
+	failing.Status.AttachError = &storagev1.VolumeError{Message: "disk is locked"}
+
+	tests := []struct {
+		name        string
+		attachments []storagev1.VolumeAttachment
+		want        string
+	}{
+		{"no attachment", nil, ""},
+		{"attached", []storagev1.VolumeAttachment{volumeAttachment("va-1", "pv-1", "worker-0", true)}, ""},
+		{"attaching", []storagev1.VolumeAttachment{volumeAttachment("va-1", "pv-1", "worker-0", false)}, "attaching to node worker-0"},
+		// Detaches are left to the detach wait and its remediation once the PVC is deleted
+		{"detaching", []storagev1.VolumeAttachment{deleting}, ""},
+		{"stuck detaching", []storagev1.VolumeAttachment{deletingStuck}, ""},
+		{"detaching while attaching elsewhere", []storagev1.VolumeAttachment{
+			deleting,
+			volumeAttachment("va-2", "pv-1", "worker-1", false),
+		}, "attaching to node worker-1"},
+		{"attach error", []storagev1.VolumeAttachment{failing}, "disk is locked"},
+		{"moving between nodes", []storagev1.VolumeAttachment{
+			volumeAttachment("va-1", "pv-1", "worker-0", true),
+			volumeAttachment("va-2", "pv-1", "worker-1", false),
+		}, "worker-0, worker-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := openshift.AttachmentTransition(tt.attachments)
+			if tt.want == "" && got != "" {
+				t.Errorf("Expected a stable state, got %q", got)
+			}
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("Expected transition containing %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWaitForStableAttachmentState(t *testing.T) {
+	ctx := context.Background()
+	attached := volumeAttachment("va-1", "pv-1", "worker-0", true)
+	attaching := volumeAttachment("va-2", "pv-2", "worker-1", false)
+	detaching := volumeAttachment("va-4", "pv-4", "worker-2", true)
+	detaching.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	detaching.Finalizers = []string{"external-attacher/csi-vsphere-vmware-com"}
+	kubeClient := kubefake.NewSimpleClientset(&attached, &attaching, &detaching)
+	vaManager := openshift.NewVolumeAttachmentManager(kubeClient)
+
+	if err := vaManager.WaitForStableAttachmentState(ctx, "pv-1", time.Second); err != nil {
+		t.Errorf("Expected an attached volume to be stable, got %v", err)
+	}
+	if err := vaManager.WaitForStableAttachmentState(ctx, "pv-3", time.Second); err != nil {
+		t.Errorf("Expected a volume without attachments to be stable, got %v", err)
+	}
+
+	// A detach does not hold up the PVC deletion, which waits for it afterwards
+	start := time.Now()
+	if err := vaManager.WaitForStableAttachmentState(ctx, "pv-4", time.Minute); err != nil {
+		t.Errorf("Expected a detaching volume not to be waited for, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected no wait for a detaching volume, waited %s", elapsed)
+	}
+
+	err := vaManager.WaitForStableAttachmentState(ctx, "pv-2", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "attaching to node worker-1") {
+		t.Errorf("Expected the in-flight attach to time out, got %v", err)
+	}
+}
+
+func TestHasVolumeAttachment(t *testing.T) {
+	ctx := context.Background()
+	attached := volumeAttachment("va-1", "pv-1", "worker-0", true)
+	vaManager := openshift.NewVolumeAttachmentManager(kubefake.NewSimpleClientset(&attached))
+
+	if has, err := vaManager.HasVolumeAttachment(ctx, "pv-1"); err != nil || !has {
+		t.Errorf("Expected pv-1 to have a VolumeAttachment, got %v, %v", has, err)
+	}
+	if has, err := vaManager.HasVolumeAttachment(ctx, "pv-2"); err != nil || has {
+		t.Errorf("Expected a never attached volume to have no VolumeAttachment, got %v, %v", has, err)
+	}
+}
+
+func TestWaitForVolumeDetached(t *testing.T) {
+	attached := volumeAttachment("va-1", "pv-1", "worker-0", true)
+	vaManager := openshift.NewVolumeAttachmentManager(kubefake.NewSimpleClientset(&attached))
+
+	start := time.Now()
+	if err := vaManager.WaitForVolumeDetached(context.Background(), "pv-2", time.Minute); err != nil {
+		t.Errorf("Expected a volume without VolumeAttachment to be detached, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected a volume without VolumeAttachment to return immediately, took %s", elapsed)
+	}
+
+	err := vaManager.WaitForVolumeDetached(context.Background(), "pv-1", 100*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "pv-1") {
+		t.Errorf("Expected a poll timeout naming the PV, got %v", err)
+	}
+
+	// A cancelled wait is not reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = vaManager.WaitForVolumeDetached(ctx, "pv-1", time.Minute)
+	if !errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to be cancelled, got %v", err)
+	}
+}
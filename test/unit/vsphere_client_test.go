@@ -2,11 +2,31 @@ package unit
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vslm"
+	_ "github.com/vmware/govmomi/vslm/simulator"
 	"k8s.io/klog/v2"
 
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/controller/phases"
 	"github.com/openshift/vmware-cloud-foundation-migration/pkg/vsphere"
 )
 
@@ -46,6 +66,112 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestParseServerURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   string
+		port     int
+		expected string
+		wantErr  bool
+	}{
+		{name: "host name", server: "vcenter.example.com", expected: "https://vcenter.example.com/sdk"},
+		{name: "host with port", server: "vcenter.example.com:8443", expected: "https://vcenter.example.com:8443/sdk"},
+		{name: "configured port", server: "vcenter.example.com", port: 8443, expected: "https://vcenter.example.com:8443/sdk"},
+		{name: "matching ports", server: "vcenter.example.com:8443", port: 8443, expected: "https://vcenter.example.com:8443/sdk"},
+		{name: "URL with port", server: "https://vcenter.example.com:8443", expected: "https://vcenter.example.com:8443/sdk"},
+		{name: "URL with sdk path", server: "https://vcenter.example.com:8443/sdk", expected: "https://vcenter.example.com:8443/sdk"},
+		{name: "IPv6 with port", server: "[fd00::10]:8443", expected: "https://[fd00::10]:8443/sdk"},
+		{name: "IPv6 with configured port", server: "fd00::10", port: 8443, wantErr: true},
+		{name: "conflicting ports", server: "vcenter.example.com:8443", port: 9443, wantErr: true},
+		{name: "port out of range", server: "vcenter.example.com", port: 70000, wantErr: true},
+		{name: "empty", server: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverURL, err := vsphere.ParseServerURL(tt.server, tt.port)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %s", serverURL)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseServerURL failed: %v", err)
+			}
+			if serverURL.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, serverURL)
+			}
+		})
+	}
+}
+
+func TestSameServer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"vcenter.example.com", "vcenter.example.com", true},
+		{"vcenter.example.com", "VCenter.Example.com", true},
+		{"vcenter.example.com", "https://vcenter.example.com/sdk", true},
+		{"vcenter.example.com", "vcenter.example.com:443", true},
+		{"vcenter.example.com", "vcenter.example.com:8443", false},
+		{"vcenter.example.com", "vcenter2.example.com", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := vsphere.SameServer(tt.a, tt.b); got != tt.expected {
+			t.Errorf("SameServer(%q, %q) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
+
+func TestNewClient_CustomPort(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	password, _ := simulator.DefaultLogin.Password()
+	creds := vsphere.Credentials{Username: simulator.DefaultLogin.Username(), Password: password}
+	host, port := server.URL.Hostname(), server.URL.Port()
+
+	t.Run("port in the server string", func(t *testing.T) {
+		client, err := vsphere.NewClient(ctx, vsphere.Config{Server: host + ":" + port, Insecure: true}, creds)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		defer client.Logout(ctx)
+
+		if expected := "https://" + host + ":" + port + "/sdk"; client.SDKURL() != expected {
+			t.Errorf("Expected SDK URL %s, got %s", expected, client.SDKURL())
+		}
+	})
+
+	t.Run("port in the config", func(t *testing.T) {
+		portNumber, _ := strconv.Atoi(port)
+		client, err := vsphere.NewClient(ctx, vsphere.Config{Server: host, Port: portNumber, Insecure: true}, creds)
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		defer client.Logout(ctx)
+
+		if expected := "https://" + host + ":" + port + "/sdk"; client.SDKURL() != expected {
+			t.Errorf("Expected SDK URL %s, got %s", expected, client.SDKURL())
+		}
+		if strings.Contains(client.SDKURL(), password) {
+			t.Error("Expected the SDK URL to leave out credentials")
+		}
+	})
+}
+
 func TestGetDatacenter(t *testing.T) {
 	// Start vcsim
 	model := simulator.VPX()
@@ -199,6 +325,62 @@ func TestCreateVMFolder(t *testing.T) {
 	}
 }
 
+func TestEnsureFolder(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	// Neither the intermediate folder nor the leaf exist yet
+	folder, err := client.EnsureFolder(ctx, "DC0", "/DC0/vm/openshift/cluster-x7x2g")
+	if err != nil {
+		t.Fatalf("EnsureFolder failed: %v", err)
+	}
+	if folder.InventoryPath != "/DC0/vm/openshift/cluster-x7x2g" {
+		t.Errorf("Unexpected inventory path %s", folder.InventoryPath)
+	}
+	if _, err := client.GetFolder(ctx, "/DC0/vm/openshift"); err != nil {
+		t.Errorf("Expected the intermediate folder to be created: %v", err)
+	}
+
+	// Ensuring it again, by a relative path, reuses the existing folder
+	again, err := client.EnsureFolder(ctx, "DC0", "openshift/cluster-x7x2g")
+	if err != nil {
+		t.Fatalf("EnsureFolder of an existing folder failed: %v", err)
+	}
+	if again.Reference() != folder.Reference() {
+		t.Errorf("Expected existing folder %s to be reused, got %s", folder.Reference(), again.Reference())
+	}
+
+	// A sibling under the now existing intermediate folder
+	if _, err := client.EnsureFolder(ctx, "DC0", "/DC0/vm/openshift/cluster-other"); err != nil {
+		t.Fatalf("EnsureFolder of a sibling failed: %v", err)
+	}
+
+	if _, err := client.EnsureFolder(ctx, "DC0", "/DC0/vm"); err == nil {
+		t.Error("Expected the datacenter VM folder itself to be rejected")
+	}
+}
+
 func TestSOAPLogging(t *testing.T) {
 	// Start vcsim
 	model := simulator.VPX()
@@ -258,3 +440,655 @@ func TestSOAPLogging(t *testing.T) {
 		t.Fatal("No SOAP logs with method name found")
 	}
 }
+
+// newConnectProxy starts an HTTP CONNECT proxy that counts the tunnels it opens
+func newConnectProxy(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var tunnels atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			upstream.Close()
+			http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		client, _, err := hijacker.Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		tunnels.Add(1)
+
+		go func() {
+			defer upstream.Close()
+			defer client.Close()
+			go io.Copy(upstream, client)
+			io.Copy(client, upstream)
+		}()
+	}))
+
+	return proxy, &tunnels
+}
+
+func TestNewClient_ThroughProxy(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Serve TLS so the client tunnels through the proxy with CONNECT, as it would to a real vCenter
+	model.Service.TLS = new(tls.Config)
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	proxy, tunnels := newConnectProxy(t)
+	defer proxy.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+			ProxyURL: proxy.URL,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client through proxy: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	if _, err := client.GetDatacenter(ctx, "DC0"); err != nil {
+		t.Fatalf("Failed to get datacenter through proxy: %v", err)
+	}
+
+	if tunnels.Load() == 0 {
+		t.Error("Expected vCenter traffic to be tunneled through the proxy")
+	}
+}
+
+func TestNewClient_InvalidProxyURL(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   "vcenter.example.com",
+			Insecure: true,
+			ProxyURL: "ftp://proxy.example.com:21",
+		},
+		vsphere.Credentials{Username: "user", Password: "pass"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported proxy scheme") {
+		t.Errorf("Expected unsupported proxy scheme error, got: %v", err)
+	}
+}
+
+func TestGetRecentSOAPFaults(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	password, _ := simulator.DefaultLogin.Password()
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: password,
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	// Credentials sent on Login must never be captured
+	for _, entry := range client.GetSOAPLogs() {
+		if entry.Method == "Login" && !strings.Contains(entry.RequestBody, "<password>REDACTED</password>") {
+			t.Fatalf("Login request body was logged with the password: %s", entry.RequestBody)
+		}
+	}
+
+	if faults := client.GetRecentSOAPFaults(5); len(faults) != 0 {
+		t.Fatalf("Expected no faults after a clean login, got %d", len(faults))
+	}
+
+	// Power on a VM that does not exist to provoke a ManagedObjectNotFound fault
+	vm := object.NewVirtualMachine(client.VimClient(), types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-missing"})
+	if _, err := vm.PowerOn(ctx); err == nil {
+		t.Fatal("Expected PowerOn of a missing VM to fail")
+	}
+
+	faults := client.GetRecentSOAPFaults(5)
+	if len(faults) != 1 {
+		t.Fatalf("Expected 1 SOAP fault, got %d", len(faults))
+	}
+	if faults[0].Method != "PowerOnVM_Task" {
+		t.Errorf("Expected fault method PowerOnVM_Task, got %s", faults[0].Method)
+	}
+	if !strings.Contains(faults[0].RequestBody, "vm-missing") {
+		t.Errorf("Expected fault request body to reference the VM, got: %s", faults[0].RequestBody)
+	}
+
+	// vcsim has no REST endpoint here, so the failed REST login is exported alongside the SOAP fault
+	logs := phases.AddVSphereFaultLogs(nil, client, "vcenter.example.com", "MigrateCSIVolumes")
+	var soapLogs []migrationv1alpha1.LogEntry
+	for _, entry := range logs {
+		if entry.Fields["api"] == "SOAP" {
+			soapLogs = append(soapLogs, entry)
+		}
+	}
+	if len(soapLogs) != 1 {
+		t.Fatalf("Expected 1 exported SOAP fault log entry, got %d", len(soapLogs))
+	}
+	if soapLogs[0].Level != migrationv1alpha1.LogLevelError || soapLogs[0].Fields["method"] != "PowerOnVM_Task" {
+		t.Errorf("Unexpected exported fault log entry: %+v", soapLogs[0])
+	}
+}
+
+func TestClient_ReconnectsAfterSessionLoss(t *testing.T) {
+	// Start vcsim
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	// Register the vslm endpoint used by the FCD manager
+	model.Service.RegisterEndpoints = true
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	fcdManager, err := vsphere.NewFCDManager(ctx, client)
+	if err != nil {
+		t.Fatalf("Failed to create FCD manager: %v", err)
+	}
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	task, err := vslm.NewObjectManager(client.VimClient()).CreateDisk(ctx, types.VslmCreateSpec{
+		Name:         "reconnect-test",
+		CapacityInMB: 10,
+		BackingSpec: &types.VslmCreateSpecDiskFileBackingSpec{
+			VslmCreateSpecBackingSpec: types.VslmCreateSpecBackingSpec{
+				Datastore: ds.Reference(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCD: %v", err)
+	}
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		t.Fatalf("Failed to wait for FCD creation: %v", err)
+	}
+	fcdID := result.Result.(types.VStorageObject).Config.Id.Id
+
+	logins := func() int {
+		count := 0
+		for _, entry := range client.GetSOAPLogs() {
+			if entry.Method == "Login" {
+				count++
+			}
+		}
+		return count
+	}
+
+	// Terminate the session server side, as a vCenter restart or session timeout would
+	expire := func() {
+		if err := session.NewManager(client.VimClient()).Logout(ctx); err != nil {
+			t.Fatalf("Failed to terminate session: %v", err)
+		}
+	}
+
+	t.Run("finder call", func(t *testing.T) {
+		expire()
+		before := logins()
+
+		if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/DC0_H0_VM0"); err != nil {
+			t.Fatalf("Expected GetVirtualMachine to succeed after reconnecting, got: %v", err)
+		}
+		if got := logins() - before; got != 1 {
+			t.Errorf("Expected exactly 1 re-login, got %d", got)
+		}
+	})
+
+	t.Run("FCD call", func(t *testing.T) {
+		expire()
+		before := logins()
+
+		info, err := fcdManager.GetFCDByID(ctx, fcdID)
+		if err != nil {
+			t.Fatalf("Expected GetFCDByID to succeed after reconnecting, got: %v", err)
+		}
+		if info.ID != fcdID {
+			t.Errorf("Expected FCD %s, got %s", fcdID, info.ID)
+		}
+		if got := logins() - before; got != 1 {
+			t.Errorf("Expected exactly 1 re-login, got %d", got)
+		}
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		before := logins()
+
+		if _, err := client.GetVirtualMachine(ctx, "/DC0/vm/missing"); err == nil {
+			t.Fatal("Expected GetVirtualMachine to fail for a missing VM")
+		}
+		if got := logins() - before; got != 0 {
+			t.Errorf("Expected no re-login, got %d", got)
+		}
+	})
+}
+
+func TestResolveInventoryPath(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	t.Run("canonical paths resolve", func(t *testing.T) {
+		for kind, path := range map[vsphere.InventoryKind]string{
+			vsphere.InventoryComputeCluster: "/DC0/host/DC0_C0",
+			vsphere.InventoryDatastore:      "/DC0/datastore/LocalDS_0",
+			vsphere.InventoryResourcePool:   "/DC0/host/DC0_C0/Resources",
+			vsphere.InventoryFolder:         "/DC0/vm",
+		} {
+			resolved, err := client.ResolveInventoryPath(ctx, "DC0", kind, path)
+			if err != nil {
+				t.Errorf("Failed to resolve %s %s: %v", kind, path, err)
+				continue
+			}
+			if resolved != path {
+				t.Errorf("Expected %s to resolve to itself, got %s", path, resolved)
+			}
+		}
+	})
+
+	t.Run("nested object found by name", func(t *testing.T) {
+		dc, err := client.GetDatacenter(ctx, "DC0")
+		if err != nil {
+			t.Fatalf("Failed to get datacenter: %v", err)
+		}
+		folders, err := dc.Folders(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get datacenter folders: %v", err)
+		}
+		parent, err := folders.VmFolder.CreateFolder(ctx, "parent")
+		if err != nil {
+			t.Fatalf("Failed to create folder: %v", err)
+		}
+		if _, err := parent.CreateFolder(ctx, "child"); err != nil {
+			t.Fatalf("Failed to create nested folder: %v", err)
+		}
+
+		// A bare folder name normalizes to a path directly under the VM folder
+		resolved, err := client.ResolveInventoryPath(ctx, "DC0", vsphere.InventoryFolder, "/DC0/vm/child")
+		if err != nil {
+			t.Fatalf("Expected nested folder to be found, got: %v", err)
+		}
+		if resolved != "/DC0/vm/parent/child" {
+			t.Errorf("Expected /DC0/vm/parent/child, got %s", resolved)
+		}
+	})
+
+	t.Run("missing object reports what was tried", func(t *testing.T) {
+		_, err := client.ResolveInventoryPath(ctx, "DC0", vsphere.InventoryDatastore, "/DC0/datastore/missing")
+		if err == nil {
+			t.Fatal("Expected an error for a missing datastore")
+		}
+		if !strings.Contains(err.Error(), "/DC0/datastore/missing") || !strings.Contains(err.Error(), "/DC0/datastore/...") {
+			t.Errorf("Expected the error to name the paths tried, got: %v", err)
+		}
+	})
+}
+
+// newStalledListener accepts TCP connections but never answers, like a hung vCenter
+func newStalledListener(t *testing.T) net.Listener {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	return listener
+}
+
+func TestNewClient_StalledServerTimesOut(t *testing.T) {
+	listener := newStalledListener(t)
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	start := time.Now()
+	_, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:      "https://" + listener.Addr().String(),
+			Insecure:    true,
+			DialTimeout: 200 * time.Millisecond,
+		},
+		vsphere.Credentials{Username: "user", Password: "pass"})
+	if err == nil {
+		t.Fatal("Expected connecting to a stalled server to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the dial timeout to apply, NewClient took %s", elapsed)
+	}
+}
+
+func TestGetServerThumbprint_StalledServerTimesOut(t *testing.T) {
+	listener := newStalledListener(t)
+	ctx := context.Background()
+
+	start := time.Now()
+	_, err := vsphere.GetServerThumbprint(ctx, "https://"+listener.Addr().String(), "", 200*time.Millisecond)
+	if err == nil {
+		t.Fatal("Expected fetching the thumbprint of a stalled server to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the dial timeout to apply, GetServerThumbprint took %s", elapsed)
+	}
+}
+
+func TestClient_OperationTimeout(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:           server.URL.String(),
+			Insecure:         true,
+			OperationTimeout: 200 * time.Millisecond,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	// Every call now takes longer than the operation timeout
+	model.DelayConfig.Delay = 2000
+	defer func() { model.DelayConfig.Delay = 0 }()
+
+	start := time.Now()
+	_, err = client.GetDatacenter(ctx, "DC0")
+	if err == nil {
+		t.Fatal("Expected a slow inventory lookup to time out")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1500*time.Millisecond {
+		t.Errorf("Expected the lookup to give up after the operation timeout without retrying, took %s", elapsed)
+	}
+}
+
+// denyingAuthorizationManager grants every privilege except the denied ones
+type denyingAuthorizationManager struct {
+	*simulator.AuthorizationManager
+	denied []string
+}
+
+func (m *denyingAuthorizationManager) HasPrivilegeOnEntities(req *types.HasPrivilegeOnEntities) soap.HasFault {
+	var result []types.EntityPrivilege
+	for _, entity := range req.Entity {
+		entityPrivilege := types.EntityPrivilege{Entity: entity}
+		for _, id := range req.PrivId {
+			entityPrivilege.PrivAvailability = append(entityPrivilege.PrivAvailability, types.PrivilegeAvailability{
+				PrivId:    id,
+				IsGranted: !slices.Contains(m.denied, id),
+			})
+		}
+		result = append(result, entityPrivilege)
+	}
+	return &methods.HasPrivilegeOnEntitiesBody{
+		Res: &types.HasPrivilegeOnEntitiesResponse{Returnval: result},
+	}
+}
+
+func TestCheckPrivileges(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+
+	err := model.Create()
+	if err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	ds, err := client.GetDatastore(ctx, "/DC0/datastore/LocalDS_0")
+	if err != nil {
+		t.Fatalf("Failed to get datastore: %v", err)
+	}
+
+	missing, err := client.CheckPrivileges(ctx, ds.Reference(), vsphere.DatastorePrivileges)
+	if err != nil {
+		t.Fatalf("CheckPrivileges failed: %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("Expected the administrator to hold every privilege, missing %v", missing)
+	}
+
+	// Deny one privilege on the simulated vCenter
+	authRef := *client.VimClient().ServiceContent.AuthorizationManager
+	authManager := model.Map().Get(authRef).(*simulator.AuthorizationManager)
+	model.Map().Put(&denyingAuthorizationManager{AuthorizationManager: authManager, denied: []string{"Datastore.AllocateSpace"}})
+
+	missing, err = client.CheckPrivileges(ctx, ds.Reference(), vsphere.DatastorePrivileges)
+	if err != nil {
+		t.Fatalf("CheckPrivileges failed: %v", err)
+	}
+	if !slices.Equal(missing, []string{"Datastore.AllocateSpace"}) {
+		t.Errorf("Expected Datastore.AllocateSpace to be missing, got %v", missing)
+	}
+}
+
+func TestPrepareVMFolder(t *testing.T) {
+	model := simulator.VPX()
+	defer model.Remove()
+	if err := model.Create(); err != nil {
+		t.Fatalf("Failed to create simulator model: %v", err)
+	}
+
+	server := model.Service.NewServer()
+	defer server.Close()
+
+	ctx := klog.NewContext(context.Background(), klog.NewKlogr())
+	client, err := vsphere.NewClient(ctx,
+		vsphere.Config{
+			Server:   server.URL.String(),
+			Insecure: true,
+		},
+		vsphere.Credentials{
+			Username: simulator.DefaultLogin.Username(),
+			Password: func() string { pwd, _ := simulator.DefaultLogin.Password(); return pwd }(),
+		})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Logout(ctx)
+
+	authRef := *client.VimClient().ServiceContent.AuthorizationManager
+	authManager := model.Map().Get(authRef).(*simulator.AuthorizationManager)
+	deny := func(privileges ...string) {
+		model.Map().Put(&denyingAuthorizationManager{AuthorizationManager: authManager, denied: privileges})
+	}
+
+	t.Run("missing folder is created", func(t *testing.T) {
+		folder, existed, err := phases.PrepareVMFolder(ctx, client, "DC0", "/DC0/vm/openshift/cluster-x7x2g")
+		if err != nil {
+			t.Fatalf("PrepareVMFolder failed: %v", err)
+		}
+		if existed {
+			t.Error("Expected the folder to be reported as created")
+		}
+		if folder.InventoryPath != "/DC0/vm/openshift/cluster-x7x2g" {
+			t.Errorf("Unexpected inventory path %s", folder.InventoryPath)
+		}
+	})
+
+	t.Run("folder exists", func(t *testing.T) {
+		// An existing folder is reused even without the privilege to create folders
+		deny("Folder.Create")
+		defer deny()
+
+		folder, existed, err := phases.PrepareVMFolder(ctx, client, "DC0", "openshift/cluster-x7x2g")
+		if err != nil {
+			t.Fatalf("PrepareVMFolder of an existing folder failed: %v", err)
+		}
+		if !existed {
+			t.Error("Expected the folder to be reported as existing")
+		}
+		if folder.InventoryPath != "/DC0/vm/openshift/cluster-x7x2g" {
+			t.Errorf("Unexpected inventory path %s", folder.InventoryPath)
+		}
+	})
+
+	t.Run("no create privilege", func(t *testing.T) {
+		deny("Folder.Create")
+		defer deny()
+
+		_, _, err := phases.PrepareVMFolder(ctx, client, "DC0", "/DC0/vm/openshift/other/cluster")
+		if err == nil {
+			t.Fatal("Expected a missing Folder.Create privilege to fail")
+		}
+		if !strings.Contains(err.Error(), "Folder.Create") || !strings.Contains(err.Error(), "/DC0/vm/openshift") {
+			t.Errorf("Expected the error to name the privilege and the parent folder, got %v", err)
+		}
+		if _, err := client.GetFolder(ctx, "/DC0/vm/openshift/other"); err == nil {
+			t.Error("Expected no folder to be created without the privilege")
+		}
+	})
+
+	t.Run("no VM create privilege", func(t *testing.T) {
+		deny("VirtualMachine.Inventory.Create")
+		defer deny()
+
+		_, _, err := phases.PrepareVMFolder(ctx, client, "DC0", "/DC0/vm/openshift/cluster-x7x2g")
+		if err == nil {
+			t.Fatal("Expected a missing VirtualMachine.Inventory.Create privilege to fail")
+		}
+		if !strings.Contains(err.Error(), "VirtualMachine.Inventory.Create") {
+			t.Errorf("Expected the error to name the missing privilege, got %v", err)
+		}
+	})
+}
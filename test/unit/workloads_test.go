@@ -0,0 +1,259 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	migrationv1alpha1 "github.com/openshift/vmware-cloud-foundation-migration/pkg/apis/migration/v1alpha1"
+	"github.com/openshift/vmware-cloud-foundation-migration/pkg/openshift"
+)
+
+func TestFindStatefulSetForPVC(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(int32(3)),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		pvcName  string
+		expected bool
+	}{
+		{pvcName: "data-web-0", expected: true},
+		{pvcName: "data-web-12", expected: true},
+		{pvcName: "data", expected: false},
+		{pvcName: "data-web-", expected: false},
+		{pvcName: "data-web-primary", expected: false},
+		{pvcName: "data-webserver-0", expected: false},
+	}
+
+	workloadManager := openshift.NewWorkloadManager(kubefake.NewSimpleClientset(sts))
+	for _, tt := range tests {
+		t.Run(tt.pvcName, func(t *testing.T) {
+			found, err := workloadManager.FindStatefulSetForPVC(context.Background(), "app", tt.pvcName)
+			if err != nil {
+				t.Fatalf("FindStatefulSetForPVC failed: %v", err)
+			}
+			if (found != nil) != tt.expected {
+				t.Errorf("Expected match=%v for PVC %s, got %v", tt.expected, tt.pvcName, found)
+			}
+		})
+	}
+}
+
+func TestScaleDownForPV_StatefulSetReplicaClaims(t *testing.T) {
+	ctx := context.Background()
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(int32(3)),
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(sts)
+	workloadManager := openshift.NewWorkloadManager(kubeClient)
+
+	// The first replica's claim scales the whole StatefulSet down
+	scaled, err := workloadManager.ScaleDownForPV(ctx, "app", "data-web-0")
+	if err != nil {
+		t.Fatalf("ScaleDownForPV failed: %v", err)
+	}
+	if len(scaled) != 1 || scaled[0].Kind != "StatefulSet" || scaled[0].OriginalReplicas != 3 {
+		t.Fatalf("Expected StatefulSet web scaled down from 3 replicas, got %+v", scaled)
+	}
+
+	// Later replicas' claims find it already scaled down and record nothing to restore
+	scaled, err = workloadManager.ScaleDownForPV(ctx, "app", "data-web-1")
+	if err != nil {
+		t.Fatalf("ScaleDownForPV failed: %v", err)
+	}
+	if len(scaled) != 0 {
+		t.Errorf("Expected no additional scale down, got %+v", scaled)
+	}
+
+	updated, err := kubeClient.AppsV1().StatefulSets("app").Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get StatefulSet: %v", err)
+	}
+	if *updated.Spec.Replicas != 0 {
+		t.Errorf("Expected StatefulSet scaled to 0, got %d", *updated.Spec.Replicas)
+	}
+}
+
+func TestScaleDownForPV_ExcludedNamespace(t *testing.T) {
+	ctx := context.Background()
+	deployment := func(namespace string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: namespace},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(2)),
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Volumes: []corev1.Volume{{
+							Name: "data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+							},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	kubeClient := kubefake.NewSimpleClientset(deployment("protected"), deployment("app"))
+	workloadManager := openshift.NewWorkloadManager(kubeClient)
+	workloadManager.SetQuiesceExcludeNamespaces([]string{"protected"})
+
+	_, err := workloadManager.ScaleDownForPV(ctx, "protected", "data")
+	if !errors.Is(err, openshift.ErrQuiesceExcluded) {
+		t.Fatalf("Expected ErrQuiesceExcluded, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Deployment/db") {
+		t.Errorf("Expected the error to name the protected workload, got %q", err.Error())
+	}
+
+	// A PVC in an excluded namespace that no workload uses can still be migrated
+	if _, err := workloadManager.ScaleDownForPV(ctx, "protected", "unused"); err != nil {
+		t.Errorf("Expected an unused PVC to be allowed, got %v", err)
+	}
+
+	scaled, err := workloadManager.ScaleDownForPV(ctx, "app", "data")
+	if err != nil {
+		t.Fatalf("ScaleDownForPV failed: %v", err)
+	}
+	if len(scaled) != 1 {
+		t.Errorf("Expected the workload outside the excluded namespace to be scaled down, got %+v", scaled)
+	}
+
+	protected, err := kubeClient.AppsV1().Deployments("protected").Get(ctx, "db", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get Deployment: %v", err)
+	}
+	if *protected.Spec.Replicas != 2 {
+		t.Errorf("Expected the protected Deployment to keep 2 replicas, got %d", *protected.Spec.Replicas)
+	}
+}
+
+func TestUnreadyWorkloads(t *testing.T) {
+	ready := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "app"},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	notMounted := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"},
+		Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+	}
+	workloadManager := openshift.NewWorkloadManager(kubefake.NewSimpleClientset(ready, notMounted))
+
+	resources := []migrationv1alpha1.ScaledResource{
+		{Kind: "Deployment", Name: "api", Namespace: "app", OriginalReplicas: 2},
+		{Kind: "StatefulSet", Name: "db", Namespace: "app", OriginalReplicas: 3},
+		{Kind: "Deployment", Name: "deleted", Namespace: "app", OriginalReplicas: 1},
+	}
+	got := workloadManager.UnreadyWorkloads(context.Background(), resources)
+	want := []string{"StatefulSet/app/db", "Deployment/app/deleted"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("UnreadyWorkloads() = %v, expected %v", got, want)
+	}
+
+	if err := workloadManager.WaitForWorkloadsReady(context.Background(), resources[:1], time.Second); err != nil {
+		t.Errorf("WaitForWorkloadsReady of a ready workload failed: %v", err)
+	}
+}
+
+func TestReplacePlaceholderPods_OnDeleteStatefulSet(t *testing.T) {
+	ctx := context.Background()
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To(int32(2)),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.OnDeleteStatefulSetStrategyType,
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			},
+		},
+	}
+
+	stsPod := func(name string, phase corev1.PodPhase, created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "app",
+				CreationTimestamp: metav1.NewTime(created),
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1",
+					Kind:       "StatefulSet",
+					Name:       "web",
+					Controller: ptr.To(true),
+				}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	restoreStart := time.Now()
+	kubeClient := kubefake.NewSimpleClientset(sts,
+		stsPod("web-0", corev1.PodPending, restoreStart.Add(-time.Hour)),
+		stsPod("web-1", corev1.PodRunning, restoreStart.Add(-time.Hour)),
+	)
+	workloadManager := openshift.NewWorkloadManager(kubeClient)
+
+	scaled, err := workloadManager.ScaleDownForPV(ctx, "app", "data-web-0")
+	if err != nil {
+		t.Fatalf("ScaleDownForPV failed: %v", err)
+	}
+	if len(scaled) != 1 || scaled[0].UpdateStrategy != string(appsv1.OnDeleteStatefulSetStrategyType) {
+		t.Fatalf("Expected the OnDelete update strategy to be recorded, got %+v", scaled)
+	}
+
+	// A pod created after the restore started belongs to the scaled-up StatefulSet and is kept
+	if _, err := kubeClient.CoreV1().Pods("app").Create(ctx, stsPod("web-2", corev1.PodPending, restoreStart.Add(time.Minute)), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+
+	deleted, err := workloadManager.ReplacePlaceholderPods(ctx, scaled[0], restoreStart)
+	if err != nil {
+		t.Fatalf("ReplacePlaceholderPods failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "web-0" {
+		t.Fatalf("Expected only the Pending placeholder pod web-0 to be deleted, got %v", deleted)
+	}
+	pods, err := kubeClient.CoreV1().Pods("app").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("Expected 2 pods to remain, got %d", len(pods.Items))
+	}
+
+	// A rolling update without a partition replaces pods itself
+	rolling := migrationv1alpha1.ScaledResource{Kind: "StatefulSet", Name: "web", Namespace: "app",
+		UpdateStrategy: string(appsv1.RollingUpdateStatefulSetStrategyType)}
+	if deleted, err := workloadManager.ReplacePlaceholderPods(ctx, rolling, time.Now().Add(time.Hour)); err != nil || len(deleted) != 0 {
+		t.Errorf("Expected no pods deleted for a rolling update, got %v, %v", deleted, err)
+	}
+
+	// Below the partition the controller leaves pods in place
+	rolling.Partition = ptr.To(int32(3))
+	if deleted, err := workloadManager.ReplacePlaceholderPods(ctx, rolling, time.Now().Add(time.Hour)); err != nil || len(deleted) != 1 || deleted[0] != "web-2" {
+		t.Errorf("Expected web-2 below the partition to be deleted, got %v, %v", deleted, err)
+	}
+}